@@ -1,5 +1,6 @@
 package io.treeverse.jpebble
 
+import com.github.luben.zstd.Zstd
 import org.xerial.snappy.Snappy
 
 import java.io.IOException
@@ -116,6 +117,7 @@ object BlockParser {
 
   val COMPRESSION_BLOCK_TYPE_NONE = 0
   val COMPRESSION_BLOCK_TYPE_SNAPPY = 1
+  val COMPRESSION_BLOCK_TYPE_ZSTD = 7
 
   val INDEX_TYPE_KEY = "rocksdb.block.based.table.index.type".getBytes
   val INDEX_TYPE_TWO_LEVEL = 2
@@ -244,6 +246,23 @@ object BlockParser {
             throw new BadFileFormatException(s"Bad Snappy-compressed data", e)
         }
       }
+      case COMPRESSION_BLOCK_TYPE_ZSTD => {
+        // Pebble prefixes zstd-compressed blocks with their uncompressed length
+        val prefixLength = data.iterator.indexWhere(b => (b & 0x80) == 0) + 1
+        val uncompressedLength = readUnsignedVarLong(data.iterator).toInt
+        val uncompressed = new Array[Byte](uncompressedLength)
+        val size = Zstd.decompressByteArray(uncompressed,
+                                            0,
+                                            uncompressedLength,
+                                            data.bytes,
+                                            data.from + prefixLength,
+                                            data.size - prefixLength
+                                           )
+        if (Zstd.isError(size) || size != uncompressedLength) {
+          throw new BadFileFormatException(s"Bad zstd-compressed data (decompressed $size)")
+        }
+        IndexedBytes.create(uncompressed)
+      }
       case _ => throw new BadFileFormatException(s"Unknown compression type $compressionType")
     }
   }
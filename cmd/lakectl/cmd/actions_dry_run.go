@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-openapi/swag"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+const actionTestResultTemplate = `{{ .Run | table -}}
+{{ if not .Matched }}The action does not run on the event
+{{ end }}{{ $r := . }}{{ range $idx, $val := .Hooks }}{{ index $r.HooksTable $idx | table -}}{{ if $val.Error }}Error: {{ $val.Error }}
+{{ end }}{{ $val.Output }}{{ end }}`
+
+var actionsTestCmd = &cobra.Command{
+	Use:   "test <path>",
+	Short: "Run an action file on a synthetic event",
+	Long: `Run the hooks of the action file on the server, as they would run on the event, and show their results and output.
+The run is not saved in the repository. Lua hooks may read the repository and its storage, but cannot change them.
+Hooks reaching external systems are skipped, unless --external-hooks is set.`,
+	Example: "lakectl actions test <path> --repo lakefs://<repository> --event pre-commit --branch main",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRepoURI("repository", Must(cmd.Flags().GetString("repo")))
+		event := Must(cmd.Flags().GetString("event"))
+		branch := Must(cmd.Flags().GetString("branch"))
+		commitID := Must(cmd.Flags().GetString("commit-id"))
+		externalHooks := Must(cmd.Flags().GetBool("external-hooks"))
+		if branch == "" && commitID == "" {
+			Die("branch or commit-id is required", 1)
+		}
+		reader := Must(OpenByPath(args[0]))
+		defer func() { _ = reader.Close() }()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			DieErr(err)
+		}
+
+		body := api.TestActionJSONRequestBody{
+			Content:       string(content),
+			EventType:     event,
+			ExternalHooks: swag.Bool(externalHooks),
+		}
+		if branch != "" {
+			body.Branch = swag.String(branch)
+		}
+		if commitID != "" {
+			body.CommitId = swag.String(commitID)
+		}
+		client := getClient()
+		resp, err := client.TestActionWithResponse(cmd.Context(), u.Repository, body)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+		result := resp.JSON200
+		Write(actionTestResultTemplate, struct {
+			Run        *Table
+			Matched    bool
+			Hooks      []api.ActionTestHookResult
+			HooksTable []*Table
+		}{
+			Run:        convertActionTestResultTable(result),
+			Matched:    result.Matched,
+			Hooks:      result.Hooks,
+			HooksTable: convertActionTestHooksTables(result.Hooks),
+		})
+		if result.Status != "completed" {
+			Die("Action test failed", 1)
+		}
+	},
+}
+
+func convertActionTestResultTable(r *api.ActionTestResult) *Table {
+	statusColor := text.FgRed
+	if r.Status == "completed" {
+		statusColor = text.FgGreen
+	}
+	return &Table{
+		Headers: []interface{}{"Run ID", "Event", "Branch", "Commit ID", "Matched", "Status"},
+		Rows: [][]interface{}{
+			{text.FgYellow.Sprint(r.RunId), r.EventType, swag.StringValue(r.Branch), swag.StringValue(r.CommitId), r.Matched, statusColor.Sprint(r.Status)},
+		},
+	}
+}
+
+func convertActionTestHooksTables(results []api.ActionTestHookResult) []*Table {
+	tables := make([]*Table, len(results))
+	for i, r := range results {
+		statusColor := text.FgRed
+		switch r.Status {
+		case "completed":
+			statusColor = text.FgGreen
+		case "skipped":
+			statusColor = text.FgYellow
+		}
+		tables[i] = &Table{
+			Headers: []interface{}{"Hook Run ID", "Hook ID", "Start Time", "End Time", "Action", "Status"},
+			Rows: [][]interface{}{
+				{text.FgYellow.Sprint(r.HookRunId), r.HookId, r.StartTime, r.EndTime, r.Action, statusColor.Sprint(r.Status)},
+			},
+		}
+	}
+	return tables
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsTestCmd.Flags().String("repo", "", "repository to run the action on (lakefs://<repository>)")
+	actionsTestCmd.Flags().String("event", "", "event type to run the action on, for example pre-commit")
+	actionsTestCmd.Flags().String("branch", "", "branch of the event")
+	actionsTestCmd.Flags().String("commit-id", "", "commit of the event, its details are passed to the hooks")
+	actionsTestCmd.Flags().Bool("external-hooks", false, "run the hooks reaching external systems, they may change them")
+	_ = actionsTestCmd.MarkFlagRequired("repo")
+	_ = actionsTestCmd.MarkFlagRequired("event")
+	actionsCmd.AddCommand(actionsTestCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var actionsGlobalCmd = &cobra.Command{
+	Use:   "global",
+	Short: "Manage global actions, run on every repository",
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsCmd.AddCommand(actionsGlobalCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var actionsGlobalDeleteCmd = &cobra.Command{
+	Use:     "delete <action name>",
+	Short:   "Delete a global action",
+	Example: "lakectl actions global delete <action name>",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		confirmation, err := Confirm(cmd.Flags(), "Are you sure you want to delete global action")
+		if err != nil || !confirmation {
+			Die("Delete global action aborted", 1)
+		}
+		client := getClient()
+		resp, err := client.DeleteGlobalActionWithResponse(cmd.Context(), args[0])
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusNoContent)
+		fmt.Printf("Global action '%s' deleted\n", args[0])
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	AssignAutoConfirmFlag(actionsGlobalDeleteCmd.Flags())
+	actionsGlobalCmd.AddCommand(actionsGlobalDeleteCmd)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var actionsGlobalGetCmd = &cobra.Command{
+	Use:     "get <action name>",
+	Short:   "Print the action file of a global action",
+	Example: "lakectl actions global get <action name>",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		resp, err := client.GetGlobalActionWithResponse(cmd.Context(), args[0])
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+		fmt.Print(resp.JSON200.Content)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsGlobalCmd.AddCommand(actionsGlobalGetCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+const actionsGlobalListTemplate = `{{.GlobalActionsTable | table -}}
+`
+
+var actionsGlobalListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List global actions",
+	Example: "lakectl actions global list",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		resp, err := client.ListGlobalActionsWithResponse(cmd.Context())
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		results := resp.JSON200.Results
+		rows := make([][]interface{}, len(results))
+		for i, row := range results {
+			rows[i] = []interface{}{
+				row.Name,
+				row.Enforced,
+				row.Configured,
+				row.CreationDate,
+			}
+		}
+		Write(actionsGlobalListTemplate, struct {
+			GlobalActionsTable *Table
+		}{
+			GlobalActionsTable: &Table{
+				Headers: []interface{}{
+					"Name",
+					"Enforced",
+					"Configured",
+					"Creation Date",
+				},
+				Rows: rows,
+			},
+		})
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsGlobalCmd.AddCommand(actionsGlobalListCmd)
+}
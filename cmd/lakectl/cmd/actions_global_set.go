@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+var actionsGlobalSetCmd = &cobra.Command{
+	Use:     "set <path>",
+	Short:   "Create or replace a global action",
+	Long:    `Set the action file as a global action, replacing the global action with the same name`,
+	Example: "lakectl actions global set <path> [--enforced]",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		enforced := Must(cmd.Flags().GetBool("enforced"))
+		reader := Must(OpenByPath(args[0]))
+		defer func() { _ = reader.Close() }()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			DieErr(err)
+		}
+		action, err := actions.ParseAction(content)
+		if err != nil {
+			DieErr(err)
+		}
+
+		client := getClient()
+		resp, err := client.SetGlobalActionWithResponse(cmd.Context(), action.Name, api.SetGlobalActionJSONRequestBody{
+			Content:  string(content),
+			Enforced: &enforced,
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		fmt.Printf("Global action '%s' set\n", action.Name)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsGlobalSetCmd.Flags().Bool("enforced", false, "prevent repository actions with the same name from overriding the action")
+	actionsGlobalCmd.AddCommand(actionsGlobalSetCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+const runsRetryRequiredArgs = 2
+
+var actionsRunsRetryCmd = &cobra.Command{
+	Use:               "retry",
+	Short:             "Retry a failed post event run",
+	Long:              `Run the failed hooks of a post event run again, including runs that exhausted their retry attempts`,
+	Example:           "lakectl actions runs retry lakefs://<repository> <run_id>",
+	Args:              cobra.ExactArgs(runsRetryRequiredArgs),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRepoURI("repository", args[0])
+		runID := args[1]
+
+		client := getClient()
+		resp, err := client.RetryRunWithResponse(cmd.Context(), u.Repository, runID)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusAccepted)
+		fmt.Printf("Run %s scheduled for retry\n", runID)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	actionsRunsCmd.AddCommand(actionsRunsRetryCmd)
+}
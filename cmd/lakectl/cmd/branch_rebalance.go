@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+// lakectl branch rebalance lakefs://myrepo/main
+var branchRebalanceCmd = &cobra.Command{
+	Use:   "rebalance <branch uri>",
+	Short: "Rewrite the ranges of the branch head into balanced ranges",
+	Long: `Rewrite the metarange of the branch head commit, splitting its entries into ranges by the current range size settings.
+The result is recorded as a new commit with the same content. The rebalance runs on the server, the command waits for
+it to complete. It fails if the branch head moves before the rebalance completes.`,
+	Example: "lakectl branch rebalance lakefs://example-repo/main",
+	Args:    cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return validRepositoryToComplete(cmd.Context(), toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseBranchURI("branch", args[0])
+		fmt.Println("Branch:", u)
+		body := api.RebalanceBranchJSONRequestBody{}
+		if cmd.Flags().Changed(messageFlagName) {
+			message := Must(cmd.Flags().GetString(messageFlagName))
+			body.Message = &message
+		}
+		clt := getClient()
+		resp, err := clt.RebalanceBranchWithResponse(cmd.Context(), u.Repository, u.Ref, body)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusAccepted)
+		if resp.JSON202 == nil {
+			Die("Bad response from server", 1)
+		}
+		rebalanceID := resp.JSON202.Id
+
+		const statusPollInterval = 2 * time.Second
+		var status *api.RebalanceStatusResp
+		ticker := time.NewTicker(statusPollInterval)
+		defer ticker.Stop()
+		for status == nil || !status.Completed {
+			<-ticker.C
+			statusResp, err := clt.RebalanceStatusWithResponse(cmd.Context(), u.Repository, u.Ref, &api.RebalanceStatusParams{Id: rebalanceID})
+			DieOnErrorOrUnexpectedStatusCode(statusResp, err, http.StatusOK)
+			status = statusResp.JSON200
+			if status == nil {
+				Die("Bad response from server", 1)
+			}
+			if status.Error != nil {
+				DieFmt("Rebalance failed: %s", status.Error.Message)
+			}
+		}
+		Write(commitCreateTemplate, struct {
+			Branch *uri.URI
+			Commit *api.Commit
+		}{Branch: u, Commit: status.Commit})
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	branchRebalanceCmd.Flags().StringP(messageFlagName, "m", "", "commit message")
+
+	branchCmd.AddCommand(branchRebalanceCmd)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+const exportSummaryTemplate = `Export of {{ .Objects | yellow }} object(s) from "{{.Ref}}" completed.
+Commit ID: {{.CommitID|yellow}}
+{{- if .PreviousCommitID }}
+Previous Commit ID: {{.PreviousCommitID|yellow}}
+{{- end }}
+Destination: {{.Destination}}
+Manifest: {{.Manifest}}
+`
+
+var exportCmd = &cobra.Command{
+	Use:   "export --from <lakeFS ref URI> --to <object store URI>",
+	Short: "Export the committed content of a reference to an object store location",
+	Long: `Export the committed content of a reference to an object store location.
+Objects are copied by lakeFS using the object store server side copy, keeping their path in the repository.
+A manifest of the exported objects and a success marker are written to the destination when the export completes.`,
+	Example: `lakectl export --from lakefs://example-repo/main --to s3://example-bucket/exports/latest/ --incremental`,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		noProgress := Must(flags.GetBool("no-progress"))
+		from := Must(flags.GetString("from"))
+		fromURI := MustParseRefURI("from", from)
+		to := Must(flags.GetString("to"))
+		incremental := Must(flags.GetBool("incremental"))
+
+		ctx := cmd.Context()
+		client := getClient()
+		verifyLocationMatchConfiguredStorage(ctx, client, "export destination", to)
+
+		bar := newExportProgressBar(!noProgress)
+		exportResp, err := client.ExportStartWithResponse(ctx, fromURI.Repository, fromURI.Ref, api.ExportStartJSONRequestBody{
+			Destination: to,
+			Incremental: swag.Bool(incremental),
+		})
+		DieOnErrorOrUnexpectedStatusCode(exportResp, err, http.StatusAccepted)
+		if exportResp.JSON202 == nil {
+			Die("Bad response from server", 1)
+		}
+		exportID := exportResp.JSON202.Id
+		// Handle interrupts
+		sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		const statusPollInterval = 2 * time.Second
+		var status *api.ExportStatusResp
+		ticker := time.NewTicker(statusPollInterval)
+		defer ticker.Stop()
+		for status == nil || !status.Completed {
+			select {
+			case <-sigCtx.Done():
+				fmt.Println()
+				fmt.Println("Canceling export")
+				resp, err := client.ExportCancelWithResponse(ctx, fromURI.Repository, fromURI.Ref, &api.ExportCancelParams{Id: exportID})
+				DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusNoContent)
+				Die("Export Canceled", 1)
+			case <-ticker.C:
+				statusResp, err := client.ExportStatusWithResponse(ctx, fromURI.Repository, fromURI.Ref, &api.ExportStatusParams{Id: exportID})
+				DieOnErrorOrUnexpectedStatusCode(statusResp, err, http.StatusOK)
+				status = statusResp.JSON200
+				if status == nil {
+					Die("Bad response from server", 1)
+				}
+				if status.Error != nil {
+					DieFmt("Export failed: %s", status.Error.Message)
+				}
+				_ = bar.Set64(api.Int64Value(status.ExportedObjects))
+			}
+		}
+		_ = bar.Clear()
+
+		Write(exportSummaryTemplate, struct {
+			Objects          int64
+			Ref              string
+			CommitID         string
+			PreviousCommitID string
+			Destination      string
+			Manifest         string
+		}{
+			Objects:          api.Int64Value(status.ExportedObjects),
+			Ref:              fromURI.Ref,
+			CommitID:         status.CommitId,
+			PreviousCommitID: api.StringValue(status.PreviousCommitId),
+			Destination:      status.Destination,
+			Manifest:         api.StringValue(status.Manifest),
+		})
+	},
+}
+
+func newExportProgressBar(visible bool) *progressbar.ProgressBar {
+	const (
+		barSpinnerType = 14
+		barWidth       = 10
+		barThrottle    = 65 * time.Millisecond
+	)
+	bar := progressbar.NewOptions64(
+		-1,
+		progressbar.OptionSetDescription("Exporting"),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetWidth(barWidth),
+		progressbar.OptionThrottle(barThrottle),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetItsString("object"),
+		progressbar.OptionOnCompletion(func() {
+			_, _ = fmt.Fprint(os.Stderr, "\n")
+		}),
+		progressbar.OptionSpinnerType(barSpinnerType),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionSetVisibility(visible),
+	)
+	_ = bar.RenderBlank()
+	return bar
+}
+
+//nolint:gochecknoinits
+func init() {
+	exportCmd.Flags().String("from", "", "lakeFS reference to export (e.g. \"lakefs://repo/main\")")
+	_ = exportCmd.MarkFlagRequired("from")
+	exportCmd.Flags().String("to", "", "object store location to export to (e.g. \"s3://bucket/sub/path/\")")
+	_ = exportCmd.MarkFlagRequired("to")
+	exportCmd.Flags().Bool("incremental", false, "export only the changes since the last export to the same destination")
+	exportCmd.Flags().Bool("no-progress", false, "switch off the progress output")
+	rootCmd.AddCommand(exportCmd)
+}
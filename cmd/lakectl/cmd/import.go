@@ -33,6 +33,10 @@ var importCmd = &cobra.Command{
 		to := Must(flags.GetString("to"))
 		toURI := MustParsePathURI("to", to)
 		message := Must(flags.GetString("message"))
+		include := Must(flags.GetStringArray("include"))
+		exclude := Must(flags.GetStringArray("exclude"))
+		remapPattern := Must(flags.GetString("remap-pattern"))
+		remapReplacement := Must(flags.GetString("remap-replacement"))
 		metadata, err := getKV(cmd, "meta")
 		if err != nil {
 			DieErr(err)
@@ -40,7 +44,7 @@ var importCmd = &cobra.Command{
 
 		ctx := cmd.Context()
 		client := getClient()
-		verifySourceMatchConfiguredStorage(ctx, client, from)
+		verifyLocationMatchConfiguredStorage(ctx, client, "import source", from)
 
 		// verify target branch exists before we try to create and import into the associated imported branch
 		if err, ok := branchExists(ctx, client, toURI.Repository, toURI.Ref); err != nil {
@@ -63,6 +67,18 @@ var importCmd = &cobra.Command{
 				},
 			},
 		}
+		if len(include) > 0 {
+			body.Paths[0].Include = &include
+		}
+		if len(exclude) > 0 {
+			body.Paths[0].Exclude = &exclude
+		}
+		if remapPattern != "" {
+			body.Paths[0].Remap = &api.ImportRemap{
+				Pattern:     remapPattern,
+				Replacement: remapReplacement,
+			}
+		}
 		if len(metadata) > 0 {
 			body.Commit.Metadata = &api.CommitCreation_Metadata{AdditionalProperties: metadata}
 		}
@@ -162,7 +178,7 @@ func newImportProgressBar(visible bool) *progressbar.ProgressBar {
 	return bar
 }
 
-func verifySourceMatchConfiguredStorage(ctx context.Context, client *api.ClientWithResponses, source string) {
+func verifyLocationMatchConfiguredStorage(ctx context.Context, client *api.ClientWithResponses, name, location string) {
 	storageConfResp, err := client.GetStorageConfigWithResponse(ctx)
 	DieOnErrorOrUnexpectedStatusCode(storageConfResp, err, http.StatusOK)
 	storageConfig := storageConfResp.JSON200
@@ -172,12 +188,12 @@ func verifySourceMatchConfiguredStorage(ctx context.Context, client *api.ClientW
 	if storageConfig.BlockstoreNamespaceValidityRegex == "" {
 		return
 	}
-	matched, err := regexp.MatchString(storageConfig.BlockstoreNamespaceValidityRegex, source)
+	matched, err := regexp.MatchString(storageConfig.BlockstoreNamespaceValidityRegex, location)
 	if err != nil {
 		DieErr(err)
 	}
 	if !matched {
-		DieFmt("import source '%s' doesn't match current configured storage '%s'", source, storageConfig.BlockstoreType)
+		DieFmt("%s '%s' doesn't match current configured storage '%s'", name, location, storageConfig.BlockstoreType)
 	}
 }
 
@@ -206,5 +222,9 @@ func init() {
 	importCmd.Flags().Bool("no-progress", false, "switch off the progress output")
 	importCmd.Flags().StringP("message", "m", "Import objects", "commit message")
 	importCmd.Flags().StringSlice("meta", []string{}, "key value pair in the form of key=value")
+	importCmd.Flags().StringArray("include", nil, "glob pattern of keys (relative to --from) to import, can be repeated (e.g. \"**/*.parquet\")")
+	importCmd.Flags().StringArray("exclude", nil, "glob pattern of keys (relative to --from) to skip, can be repeated (e.g. \"_temporary/**\")")
+	importCmd.Flags().String("remap-pattern", "", "regular expression matched against keys (relative to --from) to rewrite before placing under --to")
+	importCmd.Flags().String("remap-replacement", "", "replacement for --remap-pattern match, supports capture groups ($1, ${name})")
 	rootCmd.AddCommand(importCmd)
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+const repoForkCmdArgs = 2
+
+// repoForkCmd represents the fork repo command
+// lakectl repo fork lakefs://myrepo lakefs://myfork --storage-namespace s3://my-bucket/myfork
+var repoForkCmd = &cobra.Command{
+	Use:   "fork <source repository uri> <repository uri>",
+	Short: "Create a new repository with the committed history of another repository",
+	Long: `Create a new repository with the commits, branches and tags of the source repository.
+Committed objects are not copied - the fork references them in the source repository storage namespace.
+Uncommitted changes of the source repository are not part of the fork.
+The fork runs on the server, the command waits for it to complete. A fork that fails is deleted.`,
+	Example:           "lakectl repo fork lakefs://some-repo-name lakefs://some-fork-name --storage-namespace s3://some-bucket-name/some-fork-name",
+	Args:              cobra.ExactArgs(repoForkCmdArgs),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		clt := getClient()
+		source := MustParseRepoURI("source repository", args[0])
+		u := MustParseRepoURI("repository", args[1])
+		storageNamespace := Must(cmd.Flags().GetString("storage-namespace"))
+		fmt.Println("Source Repository:", source)
+		fmt.Println("Repository:", u)
+		resp, err := clt.ForkRepositoryWithResponse(cmd.Context(), source.Repository, api.ForkRepositoryJSONRequestBody{
+			Name:             u.Repository,
+			StorageNamespace: storageNamespace,
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusAccepted)
+		if resp.JSON202 == nil {
+			Die("Bad response from server", 1)
+		}
+		forkID := resp.JSON202.Id
+
+		const statusPollInterval = 2 * time.Second
+		var status *api.RepositoryForkStatus
+		ticker := time.NewTicker(statusPollInterval)
+		defer ticker.Stop()
+		for status == nil || !status.Completed {
+			<-ticker.C
+			statusResp, err := clt.ForkRepositoryStatusWithResponse(cmd.Context(), source.Repository, &api.ForkRepositoryStatusParams{Id: forkID})
+			DieOnErrorOrUnexpectedStatusCode(statusResp, err, http.StatusOK)
+			status = statusResp.JSON200
+			if status == nil {
+				Die("Bad response from server", 1)
+			}
+			if status.Error != nil {
+				DieFmt("Fork failed: %s", status.Error.Message)
+			}
+		}
+		repo := status.Repository
+		if repo == nil {
+			Die("Bad response from server", 1)
+		}
+		fmt.Printf("Repository '%s' forked from '%s':\nstorage namespace: %s\ndefault branch: %s\ntimestamp: %d\n", repo.Id, source.Repository, repo.StorageNamespace, repo.DefaultBranch, repo.CreationDate)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	repoForkCmd.Flags().String("storage-namespace", "", "the storage namespace of the new repository")
+	_ = repoForkCmd.MarkFlagRequired("storage-namespace")
+
+	repoCmd.AddCommand(repoForkCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+const rangeStatsTemplate = `MetaRange ID: {{.MetaRangeId|yellow}}
+Ranges: {{.RangeCount}}
+Records: {{.RecordCount}}
+
+{{ .Table | table -}}
+`
+
+// showRangeStatsCmd represents the show range-stats command
+var showRangeStatsCmd = &cobra.Command{
+	Use:               "range-stats <ref uri>",
+	Short:             "See the size distribution of the ranges of a commit",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		commitURI := MustParseRefURI("ref uri", args[0])
+
+		client := getClient()
+		resp, err := client.GetCommitRangeStatsWithResponse(cmd.Context(), commitURI.Repository, commitURI.Ref)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		stats := resp.JSON200
+		Write(rangeStatsTemplate, struct {
+			MetaRangeId string
+			RangeCount  int
+			RecordCount int64
+			Table       *Table
+		}{
+			MetaRangeId: stats.MetaRangeId,
+			RangeCount:  stats.RangeCount,
+			RecordCount: stats.RecordCount,
+			Table: &Table{
+				Headers: []interface{}{"Per range", "Min", "Mean", "P50", "P90", "P99", "Max"},
+				Rows: [][]interface{}{
+					distributionRow("size (bytes)", stats.SizeBytes),
+					distributionRow("records", stats.Records),
+				},
+			},
+		})
+	},
+}
+
+func distributionRow(name string, d api.Distribution) []interface{} {
+	return []interface{}{name, d.Min, d.Mean, d.P50, d.P90, d.P99, d.Max}
+}
+
+//nolint:gochecknoinits
+func init() {
+	showCmd.AddCommand(showRangeStatsCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+const CommittedRewriteCmdNumArgs = 2
+
+var errRewriteNotForced = errors.New("rewrite overwrites ranges in place, run with --force to confirm")
+
+var committedCmd = &cobra.Command{
+	Use:    "committed",
+	Short:  "Manage lakeFS' committed metadata",
+	Hidden: true,
+}
+
+var committedRewriteCmd = &cobra.Command{
+	Use:   "rewrite <repository> <ref>",
+	Short: "Rewrite the ranges of a commit in the configured sstable format",
+	Long: `Rewrite the ranges and the metarange of a commit in the sstable format configured by 'committed.sstable'.
+Range IDs do not depend on their format, so the commit and all other commits sharing its ranges read the rewritten
+ranges. lakefs servers read ranges of all formats, and may keep serving ranges of the previous format from their
+local cache.
+
+Ranges are overwritten in place, requiring --force:
+  - Ranges written with zstd compression have a newer format version, lakeFS versions before it cannot read them.
+    Do not rewrite in zstd until no server of an older version reads the repository, there is no downgrade.
+  - On object stores without atomic overwrites, servers reading a range while it is overwritten may fail to read it.
+    Run the rewrite when the repository is not in use.`,
+	Args: cobra.ExactArgs(CommittedRewriteCmdNumArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			return errRewriteNotForced
+		}
+		cfg := loadConfig()
+		ctx := cmd.Context()
+		kvStore, err := openKVStore(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer kvStore.Close()
+
+		c, err := catalog.New(ctx, catalog.Config{
+			Config:       cfg,
+			KVStore:      kvStore,
+			PathProvider: upload.DefaultPathProvider,
+		})
+		if err != nil {
+			return fmt.Errorf("create catalog: %w", err)
+		}
+		defer func() { _ = c.Close() }()
+
+		count, err := c.RewriteCommitRanges(ctx, args[0], args[1])
+		if err != nil {
+			return fmt.Errorf("rewrite failed after %d ranges: %w", count, err)
+		}
+		fmt.Printf("Rewrote %d ranges and the metarange of %s in %s\n", count, args[1], args[0])
+		return nil
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(committedCmd)
+	committedCmd.AddCommand(committedRewriteCmd)
+	_ = committedRewriteCmd.Flags().Bool("force", false, "overwrite the ranges in place")
+}
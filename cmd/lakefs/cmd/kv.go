@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,7 +13,9 @@ import (
 	_ "github.com/treeverse/lakefs/pkg/graveler"
 
 	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/config"
 	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvmigrate"
 	"github.com/treeverse/lakefs/pkg/kv/kvparams"
 )
 
@@ -156,6 +159,103 @@ var kvScanCmd = &cobra.Command{
 	},
 }
 
+var kvMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the Key-Value Store to another database while lakeFS is running",
+	Long: `Migrate the Key-Value Store to another database while lakeFS is running.
+All lakeFS servers must first run with 'database.migrate_to' set to the target configuration file, to mirror writes to
+the target database. The migration copies all partitions, verifies them and switches the servers to the target
+database, freezing writes only for the final verification.
+An embedded local or bolt database is locked by the lakeFS server: the server migrates it when 'database.migrate_to'
+is set, or stop the server before running the migration.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+		workers, err := cmd.Flags().GetInt("workers")
+		if err != nil {
+			return err
+		}
+		rounds, err := cmd.Flags().GetInt("rounds")
+		if err != nil {
+			return err
+		}
+		freezeGrace, err := cmd.Flags().GetDuration("freeze-grace")
+		if err != nil {
+			return err
+		}
+		abort, err := cmd.Flags().GetBool("abort")
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		var source kv.Store
+		if from == "" {
+			source, err = openKVStore(ctx, loadConfig())
+		} else {
+			source, err = openKVStoreFromFile(ctx, from)
+		}
+		if err != nil {
+			return fmt.Errorf("source: %w", err)
+		}
+		defer source.Close()
+		target, err := openKVStoreFromFile(ctx, to)
+		if err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+		defer target.Close()
+
+		migrator := kvmigrate.NewMigrator(source, target, kvmigrate.Config{
+			Workers:     workers,
+			Rounds:      rounds,
+			FreezeGrace: freezeGrace,
+		})
+		if abort {
+			if err := migrator.Abort(ctx); err != nil {
+				return fmt.Errorf("abort migration: %w", err)
+			}
+			fmt.Println("Migration aborted")
+			return nil
+		}
+		report, err := migrator.Run(ctx)
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		fmt.Printf("Migrated %d partitions: %d entries copied, %d entries reconciled, writes frozen for %s\n",
+			report.Partitions, report.Copied, report.Reconciled, report.Frozen)
+		fmt.Println("lakeFS servers now use the target database. Update their configuration to the target database and remove 'database.migrate_to'.")
+		return nil
+	},
+}
+
+func openKVStore(ctx context.Context, cfg *config.Config) (kv.Store, error) {
+	kvParams, err := kvparams.NewConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("KV params: %w", err)
+	}
+	kvStore, err := kv.Open(ctx, kvParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open KV store: %w", err)
+	}
+	return kvStore, nil
+}
+
+// openKVStoreFromFile opens the KV store configured by the database section of the lakeFS configuration file
+func openKVStoreFromFile(ctx context.Context, filename string) (kv.Store, error) {
+	cfg, err := config.NewConfigFromFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return openKVStore(ctx, cfg)
+}
+
 //nolint:gochecknoinits,gomnd
 func init() {
 	rootCmd.AddCommand(kvCmd)
@@ -165,4 +265,12 @@ func init() {
 	kvScanCmd.Flags().Int("limit", 0, "maximal number of results to return. By default, all results are returned")
 	kvScanCmd.Flags().String("until", "", "last prefix to scan. If this prefix is reached or exceeded, scan will stop")
 	kvScanCmd.Flags().Bool("pretty", false, "print indented output")
+	kvCmd.AddCommand(kvMigrateCmd)
+	kvMigrateCmd.Flags().String("from", "", "lakeFS configuration file of the source database (default is the lakeFS configuration)")
+	kvMigrateCmd.Flags().String("to", "", "lakeFS configuration file of the target database")
+	_ = kvMigrateCmd.MarkFlagRequired("to")
+	kvMigrateCmd.Flags().Int("workers", kvmigrate.DefaultWorkers, "number of partitions migrated in parallel")
+	kvMigrateCmd.Flags().Int("rounds", kvmigrate.DefaultRounds, "maximal number of verify and reconcile rounds before freezing writes")
+	kvMigrateCmd.Flags().Duration("freeze-grace", kvmigrate.DefaultFreezeGrace, "time to wait after freezing writes for lakeFS servers to complete in-flight writes, at least twice their poll interval of the migration state")
+	kvMigrateCmd.Flags().Bool("abort", false, "abort a migration that did not switch yet, unfreezing writes")
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/lua/lakefs"
 	"github.com/treeverse/lakefs/pkg/api"
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/auth/crypt"
@@ -35,8 +36,10 @@ import (
 	"github.com/treeverse/lakefs/pkg/graveler/ref"
 	"github.com/treeverse/lakefs/pkg/httputil"
 	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/bolt"
 	_ "github.com/treeverse/lakefs/pkg/kv/cosmosdb"
 	_ "github.com/treeverse/lakefs/pkg/kv/dynamodb"
+	"github.com/treeverse/lakefs/pkg/kv/kvmigrate"
 	"github.com/treeverse/lakefs/pkg/kv/kvparams"
 	"github.com/treeverse/lakefs/pkg/kv/local"
 	"github.com/treeverse/lakefs/pkg/kv/mem"
@@ -101,7 +104,19 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			logger.WithError(err).Fatal("Failed to open KV store")
 		}
+		var stopKVMigration func()
+		if cfg.Database.MigrateTo != "" {
+			dualWriteStore := mustOpenDualWriteStore(ctx, kvStore, cfg.Database.MigrateTo)
+			kvStore = dualWriteStore
+			// an embedded database is locked by the server, the server migrates it
+			if kvParams.Type == local.DriverName || kvParams.Type == bolt.DriverName {
+				stopKVMigration = startKVMigration(ctx, dualWriteStore)
+			}
+		}
 		defer kvStore.Close()
+		if stopKVMigration != nil {
+			defer stopKVMigration()
+		}
 
 		_, err = kv.ValidateSchemaVersion(ctx, kvStore)
 		if err != nil && !errors.Is(err, kv.ErrNotFound) {
@@ -143,13 +158,15 @@ var runCmd = &cobra.Command{
 				logger.WithError(err).Fatal("failed to create authentication service")
 			}
 		} else {
-			authService = auth.NewAuthService(
+			kvAuthService := auth.NewAuthService(
 				kvStore,
 				crypt.NewSecretStore([]byte(cfg.Auth.Encrypt.SecretKey)),
 				emailer,
 				authparams.ServiceCache(cfg.Auth.Cache),
 				logger.WithField("service", "auth_service"),
 			)
+			kvAuthService.WatchChanges(ctx)
+			authService = kvAuthService
 		}
 
 		cloudMetadataProvider := stats.BuildMetadataProvider(logger, cfg)
@@ -191,7 +208,7 @@ var runCmd = &cobra.Command{
 
 		// initial setup - support only when a local database is configured.
 		// local database lock will make sure that only one instance will run the setup.
-		if (kvParams.Type == local.DriverName || kvParams.Type == mem.DriverName) &&
+		if (kvParams.Type == local.DriverName || kvParams.Type == bolt.DriverName || kvParams.Type == mem.DriverName) &&
 			cfg.Installation.UserName != "" && cfg.Installation.AccessKeyID.SecureValue() != "" && cfg.Installation.SecretAccessKey.SecureValue() != "" {
 			setupCreds, err := setupLakeFS(ctx, cfg, authMetadataManager, authService, cfg.Installation.UserName,
 				cfg.Installation.AccessKeyID.SecureValue(), cfg.Installation.SecretAccessKey.SecureValue())
@@ -205,7 +222,7 @@ var runCmd = &cobra.Command{
 
 		templater := templater.NewService(templates.Content, cfg, authService)
 
-		actionsService := actions.NewService(
+		actionsService, err := actions.NewService(
 			ctx,
 			actionsStore,
 			catalog.NewActionsSource(c),
@@ -214,6 +231,9 @@ var runCmd = &cobra.Command{
 			bufferedCollector,
 			actions.Config(cfg.Actions),
 		)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to create actions service")
+		}
 
 		// wire actions into entry catalog
 		defer actionsService.Stop()
@@ -344,6 +364,16 @@ var runCmd = &cobra.Command{
 		}
 
 		actionsService.SetEndpoint(server)
+		if cfg.Actions.Lua.StoragePrefix != "" {
+			if err := lakefs.ValidateStoragePrefix(cfg.Actions.Lua.StoragePrefix, cfg.Committed.BlockStoragePrefix, upload.DefaultDataPrefix); err != nil {
+				logger.WithError(err).Fatal("Invalid actions.lua.storage_prefix")
+			}
+			actionsService.SetStorage(&lakefs.Storage{
+				Adapter:    blockStore,
+				Authorizer: authService,
+				Prefix:     cfg.Actions.Lua.StoragePrefix,
+			})
+		}
 
 		go func() {
 			var err error
@@ -559,6 +589,61 @@ func gracefulShutdown(ctx context.Context, services ...Shutter) {
 	}
 }
 
+// mustOpenDualWriteStore wraps the KV store with a store mirroring writes to the database configured by the target
+// configuration file, until the migration switches to it
+func mustOpenDualWriteStore(ctx context.Context, kvStore kv.Store, targetConfigFile string) *kvmigrate.DualWriteStore {
+	logger := logging.FromContext(ctx).WithField("migrate_to", targetConfigFile)
+	targetCfg, err := config.NewConfigFromFile(targetConfigFile)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load migration target configuration")
+	}
+	targetParams, err := kvparams.NewConfig(targetCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("Get migration target KV params")
+	}
+	targetStore, err := kv.Open(ctx, enableKVParamsMetrics(targetParams))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open migration target KV store")
+	}
+	dualWriteStore, err := kvmigrate.NewDualWriteStore(ctx, kvStore, targetStore, kvmigrate.DefaultPollInterval)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to start KV migration")
+	}
+	logger.WithField("phase", dualWriteStore.Phase()).Info("Mirroring KV writes for migration")
+	return dualWriteStore
+}
+
+// startKVMigration runs the migration of the dual-write store in the background. Returns a function that stops the
+// migration and waits for it to end.
+func startKVMigration(ctx context.Context, dualWriteStore *kvmigrate.DualWriteStore) func() {
+	logger := logging.FromContext(ctx).WithField("service_name", "kv_migrate")
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report, err := dualWriteStore.NewMigrator(kvmigrate.Config{}).Run(ctx)
+		switch {
+		case errors.Is(err, kvmigrate.ErrAlreadySwitched):
+			logger.Warn("KV migration already switched to the target database. Update the configuration to the target database and remove 'database.migrate_to'")
+		case errors.Is(err, context.Canceled):
+			logger.Info("KV migration stopped")
+		case err != nil:
+			logger.WithError(err).Error("KV migration failed, restart lakeFS to retry")
+		default:
+			logger.WithFields(logging.Fields{
+				"partitions": report.Partitions,
+				"copied":     report.Copied,
+				"reconciled": report.Reconciled,
+				"frozen":     report.Frozen,
+			}).Info("KV migration switched to the target database. Update the configuration to the target database and remove 'database.migrate_to'")
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
 // enableKVParamsMetrics returns a copy of params.KV with postgres metrics enabled.
 func enableKVParamsMetrics(p kvparams.Config) kvparams.Config {
 	if p.Postgres == nil || p.Postgres.Metrics {
@@ -7,6 +7,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/treeverse/lakefs/pkg/graveler"
@@ -18,10 +19,21 @@ type Action struct {
 	Description string                           `yaml:"description"`
 	On          map[graveler.EventType]*ActionOn `yaml:"on"`
 	Hooks       []ActionHook                     `yaml:"hooks"`
+	// ChangedPaths are the paths changed by the event that match the path filters of the action.
+	// Set only when the action has path filters.
+	ChangedPaths []string `yaml:"-"`
 }
 
 type ActionOn struct {
-	Branches []string `yaml:"branches"`
+	Branches []string       `yaml:"branches"`
+	Paths    *ActionOnPaths `yaml:"paths"`
+}
+
+// ActionOnPaths filters events by the paths changed by the operation, the action triggers only if at least one
+// changed path matches an include pattern (if any are set) and doesn't match any exclude pattern.
+type ActionOnPaths struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
 }
 
 var (
@@ -55,9 +67,21 @@ type ActionHook struct {
 	Type        HookType   `yaml:"type"`
 	Description string     `yaml:"description"`
 	If          string     `yaml:"if"`
+	Retry       *HookRetry `yaml:"retry"`
 	Properties  Properties `yaml:"properties"`
 }
 
+// HookRetry is the retry policy of a failed hook of a post event.
+// Unset fields use the lakeFS configuration defaults.
+type HookRetry struct {
+	// Attempts is the maximal number of times the hook's action will run
+	Attempts int `yaml:"attempts"`
+	// Backoff is the time to wait before the first retry, doubled on each following retry
+	Backoff time.Duration `yaml:"backoff"`
+	// MaxBackoff limits the time to wait between retries
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
 type MatchSpec struct {
 	EventType graveler.EventType
 	BranchID  graveler.BranchID
@@ -84,7 +108,21 @@ func isEventSupported(event graveler.EventType) bool {
 		graveler.EventTypePreCreateTag,
 		graveler.EventTypePostCreateTag,
 		graveler.EventTypePreDeleteTag,
-		graveler.EventTypePostDeleteTag:
+		graveler.EventTypePostDeleteTag,
+		graveler.EventTypePreRevert,
+		graveler.EventTypePostRevert,
+		graveler.EventTypePreCherryPick,
+		graveler.EventTypePostCherryPick,
+		graveler.EventTypePreReset,
+		graveler.EventTypePostReset,
+		graveler.EventTypePreImport,
+		graveler.EventTypePostImport,
+		graveler.EventTypePreCreateRepository,
+		graveler.EventTypePostCreateRepository,
+		graveler.EventTypePreDeleteRepository,
+		graveler.EventTypePostDeleteRepository,
+		graveler.EventTypePrePutObject,
+		graveler.EventTypePreDeleteObject:
 		return true
 	}
 	return false
@@ -118,6 +156,9 @@ func (a *Action) Validate() error {
 		if _, found := hooks[hook.Type]; !found {
 			return fmt.Errorf("hook[%d] type '%s' unknown: %w", i, hook.ID, ErrInvalidAction)
 		}
+		if hook.Retry != nil && (hook.Retry.Attempts < 0 || hook.Retry.Backoff < 0 || hook.Retry.MaxBackoff < 0) {
+			return fmt.Errorf("hook[%d] '%s' retry values must not be negative: %w", i, hook.ID, ErrInvalidAction)
+		}
 	}
 	return nil
 }
@@ -126,15 +167,19 @@ func validateEvent(event graveler.EventType, on *ActionOn) error {
 	if !isEventSupported(event) {
 		return fmt.Errorf("event '%s' is not supported: %w", event, ErrInvalidAction)
 	}
-	if on != nil {
-		switch {
-		// Add a case for any additional field added to ActionOn struct
-		case len(on.Branches) > 0:
-			if strings.HasSuffix(string(event), "-tag") {
-				return fmt.Errorf("'branches' is not supported in tag event types. %w", ErrInvalidEventParameter)
-			}
-		default:
-			// Nothing to do
+	if on == nil {
+		return nil
+	}
+	// Add a check for any additional field added to ActionOn struct
+	if len(on.Branches) > 0 && strings.HasSuffix(string(event), "-tag") {
+		return fmt.Errorf("'branches' is not supported in tag event types. %w", ErrInvalidEventParameter)
+	}
+	if on.Paths != nil {
+		if !isPathsEventSupported(event) {
+			return fmt.Errorf("'paths' is not supported in '%s' event type. %w", event, ErrInvalidEventParameter)
+		}
+		if _, err := newPathsFilter(on.Paths); err != nil {
+			return err
 		}
 	}
 	return nil
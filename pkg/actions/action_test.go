@@ -24,6 +24,7 @@ func TestAction_ReadAction(t *testing.T) {
 		validate func(*testing.T, *actions.Action)
 	}{
 		{name: "full", filename: "action_full.yaml", validate: validateActionFull},
+		{name: "object events", filename: "action_object_events.yaml", validate: validateActionObjectEvents},
 		{name: "secrets", filename: "action_secrets.yaml"},
 		{name: "required", filename: "action_required.yaml"},
 		{name: "duplicate id", filename: "action_duplicate_id.yaml", errStr: "duplicate ID"},
@@ -32,6 +33,9 @@ func TestAction_ReadAction(t *testing.T) {
 		{name: "invalid event type", filename: "action_invalid_event.yaml", errStr: "event 'not-a-valid-event' is not supported: invalid action"},
 		{name: "invalid yaml", filename: "action_invalid_yaml.yaml", errStr: "yaml: unmarshal errors"},
 		{name: "invalid parameter in tag event", filename: "action_invalid_param_tag_actions.yaml", errStr: "'branches' is not supported in tag event types"},
+		{name: "paths", filename: "action_paths.yaml", validate: validateActionPaths},
+		{name: "invalid paths in tag event", filename: "action_invalid_paths_event.yaml", errStr: "'paths' is not supported in 'pre-create-tag' event type"},
+		{name: "invalid path pattern", filename: "action_invalid_paths_pattern.yaml", errStr: "path pattern 'tables/[orders'"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -62,6 +66,29 @@ func validateActionFull(t *testing.T, act *actions.Action) {
 	require.NotContains(t, act.On, graveler.EventTypePostMerge)
 }
 
+func validateActionPaths(t *testing.T, act *actions.Action) {
+	t.Helper()
+	require.Contains(t, act.On, graveler.EventTypePreCommit)
+	paths := act.On[graveler.EventTypePreCommit].Paths
+	require.NotNil(t, paths)
+	require.Equal(t, []string{"tables/orders/**"}, paths.Include)
+	require.Equal(t, []string{"tables/orders/_temporary/**"}, paths.Exclude)
+	require.Nil(t, act.On[graveler.EventTypePreMerge])
+}
+
+func validateActionObjectEvents(t *testing.T, act *actions.Action) {
+	t.Helper()
+	require.Contains(t, act.On, graveler.EventTypePrePutObject)
+	require.Equal(t, []string{"main"}, act.On[graveler.EventTypePrePutObject].Branches)
+	require.Contains(t, act.On, graveler.EventTypePreDeleteObject)
+	require.Contains(t, act.On, graveler.EventTypePreRevert)
+	require.Contains(t, act.On, graveler.EventTypePostCherryPick)
+	require.Contains(t, act.On, graveler.EventTypePreReset)
+	require.Contains(t, act.On, graveler.EventTypePostImport)
+	require.Contains(t, act.On, graveler.EventTypePreDeleteRepository)
+	require.Contains(t, act.On, graveler.EventTypePostCreateRepository)
+}
+
 func TestAction_Match(t *testing.T) {
 	tests := []struct {
 		name    string
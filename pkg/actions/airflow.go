@@ -58,10 +58,11 @@ var (
 func NewAirflowHook(h ActionHook, action *Action, cfg Config, endpoint *http.Server) (Hook, error) {
 	airflowHook := Airflow{
 		HookBase: HookBase{
-			ID:         h.ID,
-			ActionName: action.Name,
-			Config:     cfg,
-			Endpoint:   endpoint,
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     endpoint,
+			ChangedPaths: action.ChangedPaths,
 		},
 		DAGConf: map[string]interface{}{},
 		Timeout: airflowDefaultTimeout,
@@ -126,7 +127,7 @@ func (a *Airflow) Run(ctx context.Context, record graveler.HookRecord, buf *byte
 		WithField("event_type", record.EventType).
 		Debug("hook action executing")
 
-	eventData, err := marshalEventInformation(a.ActionName, a.ID, record)
+	eventData, err := marshalEventInformation(a.ActionName, a.ID, a.ChangedPaths, record)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,152 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// CloudEventsContentMode is how an event is carried by a message, see the CloudEvents protocol bindings
+type CloudEventsContentMode string
+
+const (
+	// CloudEventsContentModeStructured carries the whole event, attributes and data, as the message body
+	CloudEventsContentModeStructured CloudEventsContentMode = "structured"
+	// CloudEventsContentModeBinary carries the event data as the message body and the attributes as headers
+	CloudEventsContentModeBinary CloudEventsContentMode = "binary"
+)
+
+const (
+	CloudEventsSpecVersion    = "1.0"
+	CloudEventsTypePrefix     = "io.lakefs."
+	CloudEventsContentType    = "application/cloudevents+json"
+	cloudEventsDataType       = "application/json"
+	cloudEventsSourcePrefix   = "/repositories/"
+	contentModePropertyKey    = "content_mode"
+	sourcePropertyKey         = "source"
+	cloudEventsContentTypeKey = "content-type"
+)
+
+var errCloudEventsWrongFormat = errors.New("cloudevents wrong format")
+
+// CloudEvent is a CloudEvents 1.0 envelope of the event information
+type CloudEvent struct {
+	SpecVersion     string     `json:"specversion"`
+	ID              string     `json:"id"`
+	Source          string     `json:"source"`
+	Type            string     `json:"type"`
+	Subject         string     `json:"subject,omitempty"`
+	Time            string     `json:"time"`
+	DataContentType string     `json:"datacontenttype"`
+	Data            *EventInfo `json:"data"`
+}
+
+// cloudEventsProperties are the properties common to hooks publishing CloudEvents
+type cloudEventsProperties struct {
+	ContentMode CloudEventsContentMode
+	// Source overrides the event source, which is the repository by default
+	Source string
+}
+
+func extractCloudEventsProperties(props Properties) (cloudEventsProperties, error) {
+	p := cloudEventsProperties{ContentMode: CloudEventsContentModeStructured}
+	if v, ok := props[contentModePropertyKey]; ok {
+		mode, ok := v.(string)
+		if !ok {
+			return p, fmt.Errorf("content mode must be a string: %w", errCloudEventsWrongFormat)
+		}
+		switch CloudEventsContentMode(mode) {
+		case CloudEventsContentModeStructured, CloudEventsContentModeBinary:
+			p.ContentMode = CloudEventsContentMode(mode)
+		default:
+			return p, fmt.Errorf("content mode '%s': %w", mode, errCloudEventsWrongFormat)
+		}
+	}
+	if v, ok := props[sourcePropertyKey]; ok {
+		source, ok := v.(string)
+		if !ok || source == "" {
+			return p, fmt.Errorf("source must be a non empty string: %w", errCloudEventsWrongFormat)
+		}
+		p.Source = source
+	}
+	return p, nil
+}
+
+func newCloudEvent(source string, info *EventInfo, record graveler.HookRecord) *CloudEvent {
+	if source == "" {
+		source = cloudEventsSourcePrefix + info.RepositoryID
+	}
+	subject := info.BranchID
+	if subject == "" {
+		subject = info.TagID
+	}
+	return &CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		// the same event is published with the same id when a hook run is retried
+		ID:              record.RunID + "/" + info.ActionName + "/" + info.HookID,
+		Source:          source,
+		Type:            CloudEventsTypePrefix + info.EventType,
+		Subject:         subject,
+		Time:            info.EventTime,
+		DataContentType: cloudEventsDataType,
+		Data:            info,
+	}
+}
+
+// attributes returns the event context attributes, as carried by headers in binary content mode
+func (e *CloudEvent) attributes() []messaging.Header {
+	attrs := []messaging.Header{
+		{Key: "specversion", Value: []byte(e.SpecVersion)},
+		{Key: "id", Value: []byte(e.ID)},
+		{Key: "source", Value: []byte(e.Source)},
+		{Key: "type", Value: []byte(e.Type)},
+	}
+	if e.Subject != "" {
+		attrs = append(attrs, messaging.Header{Key: "subject", Value: []byte(e.Subject)})
+	}
+	return append(attrs, messaging.Header{Key: "time", Value: []byte(e.Time)})
+}
+
+// encode returns the message body and headers carrying the event in the content mode. Attribute headers are named
+// using the prefix of the protocol binding.
+func (e *CloudEvent) encode(mode CloudEventsContentMode, headerPrefix string) ([]byte, []messaging.Header, error) {
+	if mode == CloudEventsContentModeBinary {
+		body, err := json.Marshal(e.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		headers := []messaging.Header{{Key: cloudEventsContentTypeKey, Value: []byte(e.DataContentType)}}
+		for _, attr := range e.attributes() {
+			headers = append(headers, messaging.Header{Key: headerPrefix + attr.Key, Value: attr.Value})
+		}
+		return body, headers, nil
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, []messaging.Header{{Key: cloudEventsContentTypeKey, Value: []byte(CloudEventsContentType)}}, nil
+}
+
+// parseEventTemplate parses a template property executed on the event information, like a message key
+func parseEventTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func executeEventTemplate(tmpl *template.Template, info *EventInfo) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, info); err != nil {
+		return "", fmt.Errorf("%s template: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
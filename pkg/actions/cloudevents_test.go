@@ -0,0 +1,200 @@
+package actions_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/treeverse/lakefs/pkg/actions/messaging/messagingtest"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func newCloudEventsTestRecord() graveler.HookRecord {
+	return graveler.HookRecord{
+		RunID:            "run1",
+		EventType:        graveler.EventTypePostCommit,
+		RepositoryID:     "repo1",
+		StorageNamespace: "mem://repo1",
+		BranchID:         "main",
+		SourceRef:        "main",
+		CommitID:         "c1",
+		Commit: graveler.Commit{
+			Message:   "commit message",
+			Committer: "committer",
+		},
+	}
+}
+
+func newCloudEventsTestHook(t *testing.T, hookType actions.HookType, properties actions.Properties) actions.Hook {
+	t.Helper()
+	hook, err := actions.NewHook(actions.ActionHook{
+		ID:         "publish",
+		Type:       hookType,
+		Properties: properties,
+	}, &actions.Action{Name: "events"}, actions.Config{}, nil)
+	require.NoError(t, err)
+	return hook
+}
+
+func headerValue(headers []messaging.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestKafkaHook(t *testing.T) {
+	ctx := context.Background()
+	broker := messagingtest.NewKafkaBroker(t, map[string]int{"lakefs-events": 3})
+	record := newCloudEventsTestRecord()
+
+	hook := newCloudEventsTestHook(t, actions.HookTypeKafka, actions.Properties{
+		"brokers": []interface{}{broker.Addr()},
+		"topic":   broker.Topic("lakefs-events"),
+		"key":     "{{ .RepositoryID }}/{{ .BranchID }}",
+		"headers": actions.Properties{"team": "data"},
+	})
+	var buf bytes.Buffer
+	require.NoError(t, hook.Run(ctx, record, &buf))
+
+	records := broker.Records("lakefs-events")
+	require.Len(t, records, 1)
+	msg := records[0]
+	require.Equal(t, "repo1/main", string(msg.Key))
+	require.Equal(t, actions.CloudEventsContentType, headerValue(msg.Headers, "content-type"))
+	require.Equal(t, "data", headerValue(msg.Headers, "team"))
+
+	var event actions.CloudEvent
+	require.NoError(t, json.Unmarshal(msg.Value, &event))
+	require.Equal(t, actions.CloudEventsSpecVersion, event.SpecVersion)
+	require.Equal(t, "run1/events/publish", event.ID)
+	require.Equal(t, "/repositories/repo1", event.Source)
+	require.Equal(t, "io.lakefs.post-commit", event.Type)
+	require.Equal(t, "main", event.Subject)
+	require.Equal(t, "application/json", event.DataContentType)
+	require.NotNil(t, event.Data)
+	require.Equal(t, "c1", event.Data.CommitID)
+	require.Equal(t, "commit message", event.Data.CommitMessage)
+	require.Contains(t, buf.String(), fmt.Sprintf("Partition: %d\nOffset: 0", msg.Partition))
+
+	t.Run("unknown topic", func(t *testing.T) {
+		hook := newCloudEventsTestHook(t, actions.HookTypeKafka, actions.Properties{
+			"brokers": broker.Addr(),
+			"topic":   "no-such-topic",
+			"retries": 0,
+		})
+		require.Error(t, hook.Run(ctx, record, &bytes.Buffer{}))
+	})
+}
+
+func TestNATSHook(t *testing.T) {
+	ctx := context.Background()
+	server := messagingtest.NewNATSServer(t, "", map[string]string{"lakefs.repo1.post-commit": "LAKEFS"})
+	record := newCloudEventsTestRecord()
+
+	hook := newCloudEventsTestHook(t, actions.HookTypeNATS, actions.Properties{
+		"url":          server.URL(),
+		"subject":      "lakefs.{{ .RepositoryID }}.{{ .EventType }}",
+		"jetstream":    true,
+		"content_mode": "binary",
+		"source":       "lakefs-test",
+	})
+	var buf bytes.Buffer
+	require.NoError(t, hook.Run(ctx, record, &buf))
+	require.Contains(t, buf.String(), "Sequence: 1")
+
+	// a retried run publishes the same message id
+	buf.Reset()
+	require.NoError(t, hook.Run(ctx, record, &buf))
+	require.Contains(t, buf.String(), "Duplicate: true")
+
+	messages := server.StreamMessages("LAKEFS")
+	require.Len(t, messages, 1)
+	msg := messages[0]
+	require.Equal(t, "lakefs.repo1.post-commit", msg.Subject)
+	require.Equal(t, "application/json", headerValue(msg.Headers, "content-type"))
+	require.Equal(t, "1.0", headerValue(msg.Headers, "ce-specversion"))
+	require.Equal(t, "run1/events/publish", headerValue(msg.Headers, "ce-id"))
+	require.Equal(t, "lakefs-test", headerValue(msg.Headers, "ce-source"))
+	require.Equal(t, "io.lakefs.post-commit", headerValue(msg.Headers, "ce-type"))
+	require.Equal(t, "run1/events/publish", headerValue(msg.Headers, messaging.NATSMsgIDHeader))
+
+	var info actions.EventInfo
+	require.NoError(t, json.Unmarshal(msg.Data, &info))
+	require.Equal(t, "post-commit", info.EventType)
+	require.Equal(t, "repo1", info.RepositoryID)
+}
+
+func TestHTTPEventHook(t *testing.T) {
+	ctx := context.Background()
+	record := newCloudEventsTestRecord()
+
+	var (
+		requestHeaders http.Header
+		requestBody    []byte
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestHeaders = r.Header.Clone()
+		requestBody, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	t.Run("structured", func(t *testing.T) {
+		hook := newCloudEventsTestHook(t, actions.HookTypeHTTPEvent, actions.Properties{"url": ts.URL})
+		require.NoError(t, hook.Run(ctx, record, &bytes.Buffer{}))
+		require.Equal(t, actions.CloudEventsContentType, requestHeaders.Get("Content-Type"))
+		var event actions.CloudEvent
+		require.NoError(t, json.Unmarshal(requestBody, &event))
+		require.Equal(t, "io.lakefs.post-commit", event.Type)
+		require.Equal(t, "repo1", event.Data.RepositoryID)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		hook := newCloudEventsTestHook(t, actions.HookTypeHTTPEvent, actions.Properties{"url": ts.URL, "content_mode": "binary"})
+		require.NoError(t, hook.Run(ctx, record, &bytes.Buffer{}))
+		require.Equal(t, "application/json", requestHeaders.Get("Content-Type"))
+		require.Equal(t, "1.0", requestHeaders.Get("ce-specversion"))
+		require.Equal(t, "io.lakefs.post-commit", requestHeaders.Get("ce-type"))
+		require.Equal(t, "main", requestHeaders.Get("ce-subject"))
+		var info actions.EventInfo
+		require.NoError(t, json.Unmarshal(requestBody, &info))
+		require.Equal(t, "repo1", info.RepositoryID)
+	})
+}
+
+func TestNewCloudEventsHookProperties(t *testing.T) {
+	tests := []struct {
+		name       string
+		hookType   actions.HookType
+		properties actions.Properties
+	}{
+		{name: "kafka missing brokers", hookType: actions.HookTypeKafka, properties: actions.Properties{"topic": "t"}},
+		{name: "kafka missing topic", hookType: actions.HookTypeKafka, properties: actions.Properties{"brokers": "localhost:9092"}},
+		{name: "kafka invalid acks", hookType: actions.HookTypeKafka, properties: actions.Properties{"brokers": "localhost:9092", "topic": "t", "acks": "some"}},
+		{name: "kafka invalid key", hookType: actions.HookTypeKafka, properties: actions.Properties{"brokers": "localhost:9092", "topic": "t", "key": "{{ .RepositoryID"}},
+		{name: "kafka missing password", hookType: actions.HookTypeKafka, properties: actions.Properties{"brokers": "localhost:9092", "topic": "t", "username": "u"}},
+		{name: "nats missing subject", hookType: actions.HookTypeNATS, properties: actions.Properties{"url": "nats://localhost:4222"}},
+		{name: "nats invalid content mode", hookType: actions.HookTypeNATS, properties: actions.Properties{"url": "nats://localhost:4222", "subject": "s", "content_mode": "batched"}},
+		{name: "http event missing url", hookType: actions.HookTypeHTTPEvent, properties: actions.Properties{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := actions.NewHook(actions.ActionHook{
+				ID:         "publish",
+				Type:       tt.hookType,
+				Properties: tt.properties,
+			}, &actions.Action{Name: "events"}, actions.Config{}, nil)
+			require.Error(t, err)
+		})
+	}
+}
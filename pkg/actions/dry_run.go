@@ -0,0 +1,201 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+var ErrActionsDisabled = errors.New("actions are disabled")
+
+// DryRunOptions configure what the hooks of a dry run may do
+type DryRunOptions struct {
+	// ExternalHooks runs the hooks that reach systems outside lakeFS: webhook, airflow, exec, kafka, nats and
+	// http_event hooks, and the net/http module of lua hooks. Otherwise these hooks are skipped.
+	ExternalHooks bool
+}
+
+// DryRunResult is the result of running an action on a synthetic event
+type DryRunResult struct {
+	RunResult
+	// Matched is false when the action does not run on the event, then no hook runs
+	Matched bool
+	Hooks   []DryRunHookResult
+}
+
+// DryRunHookResult is the result of a hook of a dry run, including its output
+type DryRunHookResult struct {
+	TaskResult
+	// Skipped is set on hooks that did not run, as their 'if' expression evaluated to false or as they reach
+	// external systems
+	Skipped bool
+	Error   string
+	Output  []byte
+}
+
+// memoryOutputWriter keeps the hooks output in memory by name
+type memoryOutputWriter struct {
+	mu      sync.Mutex
+	outputs map[string][]byte
+}
+
+func (w *memoryOutputWriter) OutputWrite(_ context.Context, _, name string, reader io.Reader, _ int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.outputs[name] = data
+	return nil
+}
+
+func (w *memoryOutputWriter) output(name string) []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.outputs[name]
+}
+
+// DryRun runs the hooks of the action content on the event record, as the action would run if it was part of the
+// repository. Nothing is kept of the run: the results and the hooks output are returned instead of saved.
+// Lua hooks read the repository and storage but cannot change them. Hooks reaching external systems are skipped
+// unless opts.ExternalHooks is set.
+func (s *StoreService) DryRun(ctx context.Context, record graveler.HookRecord, content []byte, opts DryRunOptions) (*DryRunResult, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrActionsDisabled
+	}
+	action, err := ParseAction(content)
+	if err != nil && !errors.Is(err, ErrInvalidAction) {
+		// yaml errors
+		return nil, fmt.Errorf("%s: %w", err, ErrInvalidAction)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isEventSupported(record.EventType) {
+		return nil, fmt.Errorf("event '%s' is not supported: %w", record.EventType, ErrInvalidEventParameter)
+	}
+	if record.RunID == "" {
+		record.RunID = s.NewRunID()
+	}
+
+	result := &DryRunResult{
+		RunResult: RunResult{
+			RunID:     record.RunID,
+			BranchID:  record.BranchID.String(),
+			SourceRef: record.SourceRef.String(),
+			EventType: string(record.EventType),
+			CommitID:  record.CommitID.String(),
+		},
+	}
+	matched, err := MatchedActions([]*Action{action}, MatchSpec{EventType: record.EventType, BranchID: record.BranchID})
+	if err != nil {
+		return nil, err
+	}
+	matched, err = s.filterActionsByPaths(ctx, record, matched)
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) == 0 {
+		return result, nil
+	}
+	result.Matched = true
+
+	tasks, err := s.allocateTasks(record.RunID, matched)
+	if err != nil {
+		return nil, err
+	}
+	skipped := dryRunHooks(tasks, opts)
+	writer := &memoryOutputWriter{outputs: make(map[string][]byte)}
+	// hooks errors are reported by the tasks results
+	_ = s.runTasks(ctx, record, tasks, writer)
+
+	manifest := buildRunManifestFromTasks(record, tasks)
+	result.RunResult = manifest.Run
+	idx := 0
+	for _, actionTasks := range tasks {
+		for _, task := range actionTasks {
+			hook := DryRunHookResult{
+				TaskResult: manifest.HooksRun[idx],
+				Skipped:    (task.StartTime.IsZero() && task.Err == nil) || skipped[task],
+				Output:     writer.output(FormatHookOutputPath(task.RunID, task.HookRunID)),
+			}
+			if task.Err != nil {
+				hook.Error = task.Err.Error()
+			}
+			result.Hooks = append(result.Hooks, hook)
+			idx++
+		}
+	}
+	return result, nil
+}
+
+// dryRunHooks replaces the hooks of tasks with their dry run version: lua hooks cannot change the repository or
+// storage, and hooks reaching external systems are replaced unless opts.ExternalHooks is set. Returns the tasks of
+// replaced hooks.
+func dryRunHooks(tasks [][]*Task, opts DryRunOptions) map[*Task]bool {
+	skipped := make(map[*Task]bool)
+	for _, actionTasks := range tasks {
+		for i, task := range actionTasks {
+			hookType := task.Action.Hooks[i].Type
+			switch h := task.Hook.(type) {
+			case *LuaHook:
+				readOnly := *h
+				readOnly.Endpoint = readOnlyEndpoint(h.Endpoint)
+				if h.Storage != nil {
+					storage := *h.Storage
+					storage.ReadOnly = true
+					readOnly.Storage = &storage
+				}
+				if !opts.ExternalHooks {
+					readOnly.Config.Lua.NetHTTPEnabled = false
+				}
+				task.Hook = &readOnly
+			default:
+				if !opts.ExternalHooks {
+					task.Hook = &skippedHook{hookType: hookType}
+					skipped[task] = true
+				}
+			}
+		}
+	}
+	return skipped
+}
+
+// skippedHook replaces a hook reaching an external system on a dry run
+type skippedHook struct {
+	hookType HookType
+}
+
+func (h *skippedHook) Run(_ context.Context, _ graveler.HookRecord, buf *bytes.Buffer) error {
+	_, err := fmt.Fprintf(buf, "Dry run: %s hook not run, it reaches an external system\n", h.hookType)
+	return err
+}
+
+// readOnlyEndpoint returns an endpoint serving only the read requests of endpoint
+func readOnlyEndpoint(endpoint *http.Server) *http.Server {
+	if endpoint == nil {
+		return nil
+	}
+	handler := endpoint.Handler
+	return &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"message": fmt.Sprintf("dry run is read-only: %s %s is not allowed", r.Method, r.URL.Path),
+				})
+				return
+			}
+			handler.ServeHTTP(w, r)
+		}),
+	}
+}
@@ -0,0 +1,167 @@
+package actions_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/lua/lakefs"
+	"github.com/treeverse/lakefs/pkg/actions/mock"
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/stats"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := auth.WithUser(context.Background(), &model.User{Username: "user1"})
+	ctrl := gomock.NewController(t)
+	// dry runs read no repository actions and write no output
+	testSource := mock.NewMockSource(ctrl)
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+
+	const actionContent = `name: check
+on:
+  pre-commit:
+    branches: [main]
+hooks:
+  - id: hello
+    type: lua
+    properties:
+      script: print("hello " .. action.branch_id)
+  - id: fail
+    type: lua
+    properties:
+      script: error("boom")
+  - id: skipped
+    type: lua
+    properties:
+      script: print("not reached")
+`
+	kvStore := kvtest.GetStore(ctx, t)
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), testSource, testOutputWriter, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, actions.Config{Enabled: true})
+	require.NoError(t, err)
+	defer actionsService.Stop()
+
+	record := graveler.HookRecord{
+		EventType:        graveler.EventTypePreCommit,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		SourceRef:        "main",
+		BranchID:         "main",
+	}
+	result, err := actionsService.DryRun(ctx, record, []byte(actionContent), actions.DryRunOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Matched)
+	require.False(t, result.Passed)
+	require.NotEmpty(t, result.RunID)
+	require.Len(t, result.Hooks, 3)
+
+	require.Equal(t, "hello", result.Hooks[0].HookID)
+	require.True(t, result.Hooks[0].Passed)
+	require.Equal(t, "hello main\n", string(result.Hooks[0].Output))
+	require.False(t, result.Hooks[1].Passed)
+	require.False(t, result.Hooks[1].Skipped)
+	require.Contains(t, result.Hooks[1].Error, "boom")
+	require.Contains(t, string(result.Hooks[1].Output), "boom")
+	require.True(t, result.Hooks[2].Skipped)
+	require.Empty(t, result.Hooks[2].Output)
+
+	// nothing is kept of the run
+	it, err := actionsService.ListRunResults(ctx, "repoID", "", "", "")
+	require.NoError(t, err)
+	defer it.Close()
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+
+	// the action does not run on other branches
+	record.BranchID = "dev"
+	result, err = actionsService.DryRun(ctx, record, []byte(actionContent), actions.DryRunOptions{})
+	require.NoError(t, err)
+	require.False(t, result.Matched)
+	require.Empty(t, result.Hooks)
+
+	_, err = actionsService.DryRun(ctx, record, []byte("name: no hooks"), actions.DryRunOptions{})
+	require.ErrorIs(t, err, actions.ErrInvalidAction)
+	_, err = actionsService.DryRun(ctx, record, []byte(":"), actions.DryRunOptions{})
+	require.ErrorIs(t, err, actions.ErrInvalidAction)
+	record.EventType = "pre-something"
+	_, err = actionsService.DryRun(ctx, record, []byte(actionContent), actions.DryRunOptions{})
+	require.ErrorIs(t, err, actions.ErrInvalidEventParameter)
+}
+
+func TestDryRunReadOnly(t *testing.T) {
+	ctx := auth.WithUser(context.Background(), &model.User{Username: "user1"})
+	ctrl := gomock.NewController(t)
+
+	var webhookCalls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&webhookCalls, 1)
+	}))
+	defer ts.Close()
+
+	const actionTemplate = `name: check
+on:
+  pre-commit:
+hooks:
+  - id: webhook
+    type: webhook
+    properties:
+      url: "%s/webhook"
+  - id: lua
+    type: lua
+    properties:
+      script: |
+        local lakefs = require("lakefs")
+        local code = lakefs.create_tag("repoID", "main", "v1")
+        print("create_tag " .. code)
+        code = lakefs.list_objects("repoID", "main")
+        print("list_objects " .. code)
+        local ok = pcall(require("lakefs/storage").put_object, "repoID", "key", "data")
+        print("put_object " .. tostring(ok))
+`
+	actionContent := []byte(fmt.Sprintf(actionTemplate, ts.URL))
+
+	kvStore := kvtest.GetStore(ctx, t)
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), mock.NewMockSource(ctrl), mock.NewMockOutputWriter(ctrl), &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, actions.Config{Enabled: true})
+	require.NoError(t, err)
+	defer actionsService.Stop()
+	var methods []string
+	actionsService.SetEndpoint(&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	})})
+	actionsService.SetStorage(&lakefs.Storage{Prefix: "hooks"})
+
+	record := graveler.HookRecord{
+		EventType:        graveler.EventTypePreCommit,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		SourceRef:        "main",
+		BranchID:         "main",
+	}
+	result, err := actionsService.DryRun(ctx, record, actionContent, actions.DryRunOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Passed)
+	require.Len(t, result.Hooks, 2)
+	require.True(t, result.Hooks[0].Skipped, "external hooks are skipped")
+	require.Contains(t, string(result.Hooks[0].Output), "webhook hook not run")
+	require.Zero(t, atomic.LoadInt32(&webhookCalls))
+	require.False(t, result.Hooks[1].Skipped)
+	require.Equal(t, "create_tag 403\nlist_objects 200\nput_object false\n", string(result.Hooks[1].Output))
+	require.Equal(t, []string{http.MethodGet}, methods, "only read requests reach the endpoint")
+
+	result, err = actionsService.DryRun(ctx, record, actionContent, actions.DryRunOptions{ExternalHooks: true})
+	require.NoError(t, err)
+	require.False(t, result.Hooks[0].Skipped)
+	require.True(t, result.Hooks[0].Passed)
+	require.EqualValues(t, 1, atomic.LoadInt32(&webhookCalls))
+}
@@ -20,11 +20,19 @@ type EventInfo struct {
 	CommitMessage  string            `json:"commit_message,omitempty"`
 	Committer      string            `json:"committer,omitempty"`
 	CommitMetadata map[string]string `json:"commit_metadata,omitempty"`
+	OriginCommitID string            `json:"origin_commit_id,omitempty"`
+	ObjectKey      string            `json:"object_key,omitempty"`
+	ChangedPaths   []string          `json:"changed_paths,omitempty"`
 }
 
-func marshalEventInformation(actionName, hookID string, record graveler.HookRecord) ([]byte, error) {
+func marshalEventInformation(actionName, hookID string, changedPaths []string, record graveler.HookRecord) ([]byte, error) {
+	info := newEventInfo(actionName, hookID, changedPaths, record)
+	return json.Marshal(info)
+}
+
+func newEventInfo(actionName, hookID string, changedPaths []string, record graveler.HookRecord) EventInfo {
 	now := time.Now()
-	info := EventInfo{
+	return EventInfo{
 		EventType:      string(record.EventType),
 		EventTime:      now.UTC().Format(time.RFC3339),
 		ActionName:     actionName,
@@ -37,6 +45,8 @@ func marshalEventInformation(actionName, hookID string, record graveler.HookReco
 		CommitMessage:  record.Commit.Message,
 		Committer:      record.Commit.Committer,
 		CommitMetadata: record.Commit.Metadata,
+		OriginCommitID: record.OriginCommitID.String(),
+		ObjectKey:      record.ObjectKey.String(),
+		ChangedPaths:   changedPaths,
 	}
-	return json.Marshal(info)
 }
@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// ExecHook runs a local command allowed by the server configuration. The event information is written as JSON to
+// the command's stdin, and a zero exit code passes the hook.
+type ExecHook struct {
+	HookBase
+	CommandName   string
+	Path          string
+	Args          []SecureString
+	Env           []string
+	Dir           string
+	Timeout       time.Duration
+	MaxOutputSize int
+	Limits        ExecLimits
+}
+
+// ExecLimits are the resource limits applied to the command of an exec hook. Zero values are not limited.
+type ExecLimits struct {
+	CPUTime   time.Duration
+	Memory    int64
+	OpenFiles int
+}
+
+func (l ExecLimits) isSet() bool {
+	return l.CPUTime > 0 || l.Memory > 0 || l.OpenFiles > 0
+}
+
+const (
+	execDefaultTimeout       = 1 * time.Minute
+	execDefaultMaxOutputSize = 1024 * 1024
+
+	execCommandPropertyKey = "command"
+	execArgsPropertyKey    = "args"
+	execTimeoutPropertyKey = "timeout"
+)
+
+var (
+	errExecHookWrongFormat    = errors.New("exec hook wrong format")
+	errExecHookNotAllowed     = errors.New("exec hook command not allowed")
+	errExecHookFailed         = errors.New("exec hook failed")
+	errExecLimitsNotSupported = errors.New("exec hook resource limits are not supported on this platform")
+)
+
+func NewExecHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook, error) {
+	commandName, err := h.Properties.getRequiredProperty(execCommandPropertyKey)
+	if err != nil {
+		return nil, fmt.Errorf("exec hook command property: %w", err)
+	}
+	command, ok := cfg.Exec.Commands[commandName]
+	if !ok {
+		return nil, fmt.Errorf("command '%s': %w", commandName, errExecHookNotAllowed)
+	}
+
+	args := make([]SecureString, 0, len(command.Args))
+	for _, arg := range command.Args {
+		args = append(args, SecureString{val: arg})
+	}
+	if rawArgs, ok := h.Properties[execArgsPropertyKey]; ok {
+		if !command.AllowArgs {
+			return nil, fmt.Errorf("command '%s' does not allow args: %w", commandName, errExecHookNotAllowed)
+		}
+		actionArgs, err := extractExecArgs(rawArgs)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, actionArgs...)
+	}
+
+	maxTimeout := cfg.Exec.Timeout
+	if maxTimeout <= 0 {
+		maxTimeout = execDefaultTimeout
+	}
+	timeout := maxTimeout
+	if v, ok := h.Properties[execTimeoutPropertyKey].(string); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("exec hook timeout property: %w", err)
+		}
+		// the configured timeout is the maximal run time of any exec hook
+		if d < maxTimeout {
+			timeout = d
+		}
+	}
+
+	maxOutputSize := cfg.Exec.MaxOutputSize
+	if maxOutputSize <= 0 {
+		maxOutputSize = execDefaultMaxOutputSize
+	}
+
+	limits := ExecLimits(cfg.Exec.Limits)
+	if limits.isSet() && !resourceLimitsSupported {
+		return nil, fmt.Errorf("command '%s': %w", commandName, errExecLimitsNotSupported)
+	}
+
+	return &ExecHook{
+		HookBase: HookBase{
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     e,
+			ChangedPaths: action.ChangedPaths,
+		},
+		CommandName:   commandName,
+		Path:          command.Path,
+		Args:          args,
+		Env:           command.Env,
+		Dir:           command.Dir,
+		Timeout:       timeout,
+		MaxOutputSize: maxOutputSize,
+		Limits:        limits,
+	}, nil
+}
+
+func extractExecArgs(rawArgs interface{}) ([]SecureString, error) {
+	argList, ok := rawArgs.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("exec hook args must be a list: %w", errExecHookWrongFormat)
+	}
+	args := make([]SecureString, 0, len(argList))
+	for i, rawArg := range argList {
+		arg, ok := rawArg.(string)
+		if !ok {
+			return nil, fmt.Errorf("exec hook arg[%d] must be a string: %w", i, errExecHookWrongFormat)
+		}
+		secureArg, err := NewSecureString(arg)
+		if err != nil {
+			return nil, fmt.Errorf("exec hook arg[%d]: %w", i, err)
+		}
+		args = append(args, secureArg)
+	}
+	return args, nil
+}
+
+func (h *ExecHook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) error {
+	logging.FromContext(ctx).
+		WithField("hook_type", "exec").
+		WithField("event_type", record.EventType).
+		WithField("command", h.CommandName).
+		Debug("hook action executing")
+
+	eventData, err := marshalEventInformation(h.ActionName, h.ID, h.ChangedPaths, record)
+	if err != nil {
+		return err
+	}
+
+	args := make([]string, 0, len(h.Args))
+	printArgs := make([]string, 0, len(h.Args))
+	for _, arg := range h.Args {
+		args = append(args, arg.val)
+		printArgs = append(printArgs, arg.String())
+	}
+	_, _ = fmt.Fprintf(buf, "Command:\n%s %s\n", h.Path, strings.Join(printArgs, " "))
+
+	runCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	cmd, err := limitedCommand(h.Path, args, h.Limits)
+	if err != nil {
+		return fmt.Errorf("command '%s': %w", h.CommandName, err)
+	}
+	setProcessGroup(cmd)
+	// the command gets only the configured environment and not the lakeFS environment
+	cmd.Env = append([]string{}, h.Env...)
+	cmd.Dir = h.Dir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("command '%s': %w", h.CommandName, err)
+	}
+	stdout := &limitedBuffer{limit: h.MaxOutputSize}
+	stderr := &limitedBuffer{limit: h.MaxOutputSize}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("command '%s': %w", h.CommandName, err)
+	}
+	go func() {
+		// the command may exit without reading its input
+		_, _ = stdin.Write(eventData)
+		_ = stdin.Close()
+	}()
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-runCtx.Done():
+			// kill the command and its children, which may keep its output open
+			_ = killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	err = cmd.Wait()
+	close(done)
+	elapsed := time.Since(start)
+
+	_, _ = fmt.Fprintf(buf, "Exit Code: %d\nElapsed: %s\n", cmd.ProcessState.ExitCode(), elapsed)
+	_, _ = fmt.Fprintf(buf, "Stdout:\n%s\n", stdout)
+	_, _ = fmt.Fprintf(buf, "Stderr:\n%s\n", stderr)
+
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command '%s' timed out after %s: %w", h.CommandName, h.Timeout, errExecHookFailed)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// a command exceeding its CPU time limit is killed by a signal
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return fmt.Errorf("command '%s' killed by signal %s: %w", h.CommandName, status.Signal(), errExecHookFailed)
+		}
+		return fmt.Errorf("command '%s' exit code %d: %w", h.CommandName, exitErr.ExitCode(), errExecHookFailed)
+	}
+	if err != nil {
+		return fmt.Errorf("command '%s': %w", h.CommandName, err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps up to limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		p = p[:remaining]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+	// report the full write, so the command is not failed on a short write
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n[output truncated]"
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,89 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const resourceLimitsSupported = true
+
+// execLimitsWrapper is the name the lakeFS executable runs with to set the resource limits of an exec hook command,
+// before executing the command in its place. The command and its children never run without the limits.
+const execLimitsWrapper = "lakefs-exec-limits"
+
+//nolint:gochecknoinits
+func init() {
+	if len(os.Args) > 0 && os.Args[0] == execLimitsWrapper {
+		os.Exit(runExecLimitsWrapper(os.Args[1:]))
+	}
+}
+
+// limitedCommand returns the command running path with args. When limits are set, the command runs the lakeFS
+// executable as a wrapper that sets the limits and then executes path.
+func limitedCommand(path string, args []string, limits ExecLimits) (*exec.Cmd, error) {
+	cmd := exec.Command(path, args...) //nolint:gosec
+	if cmd.Err != nil || !limits.isSet() {
+		return cmd, cmd.Err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("resource limits wrapper: %w", err)
+	}
+	wrapperArgs := []string{
+		strconv.FormatInt(int64((limits.CPUTime+time.Second-1)/time.Second), 10),
+		strconv.Itoa(limits.OpenFiles),
+		strconv.FormatInt(limits.Memory, 10),
+		cmd.Path,
+	}
+	wrapper := exec.Command(self, append(wrapperArgs, args...)...) //nolint:gosec
+	wrapper.Args[0] = execLimitsWrapper
+	return wrapper, nil
+}
+
+// runExecLimitsWrapper sets the limits passed by limitedCommand and executes the command. It returns only on failure,
+// with the exit code of a command that could not run.
+func runExecLimitsWrapper(args []string) int {
+	const cannotExecute = 126
+	// the limits by their order in the arguments, followed by the command path and its arguments. The memory limit is
+	// set last, the wrapper barely allocates until the command replaces it.
+	rlimits := []struct {
+		name     string
+		resource int
+		value    uint64
+	}{
+		{name: "cpu time", resource: syscall.RLIMIT_CPU},
+		{name: "open files", resource: syscall.RLIMIT_NOFILE},
+		{name: "memory", resource: syscall.RLIMIT_AS},
+	}
+	if len(args) <= len(rlimits) {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: missing arguments\n", execLimitsWrapper)
+		return cannotExecute
+	}
+	for i := range rlimits {
+		v, err := strconv.ParseUint(args[i], 10, 64)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %s limit: %s\n", execLimitsWrapper, rlimits[i].name, err)
+			return cannotExecute
+		}
+		rlimits[i].value = v
+	}
+	command := args[len(rlimits):]
+	env := os.Environ()
+	for _, l := range rlimits {
+		if l.value == 0 {
+			continue
+		}
+		// soft and hard limits are both set, so the command can't raise them
+		if err := syscall.Setrlimit(l.resource, &syscall.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %s limit: %s\n", execLimitsWrapper, l.name, err)
+			return cannotExecute
+		}
+	}
+	err := syscall.Exec(command[0], command, env)
+	_, _ = fmt.Fprintf(os.Stderr, "%s: exec %s: %s\n", execLimitsWrapper, command[0], err)
+	return cannotExecute
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package actions
+
+import "os/exec"
+
+const resourceLimitsSupported = false
+
+func limitedCommand(path string, args []string, _ ExecLimits) (*exec.Cmd, error) {
+	cmd := exec.Command(path, args...) //nolint:gosec
+	return cmd, cmd.Err
+}
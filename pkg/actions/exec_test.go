@@ -0,0 +1,190 @@
+package actions_test
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func newExecTestConfig(t *testing.T) actions.Config {
+	t.Helper()
+	shPath, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is required to run exec hooks tests")
+	}
+	var cfg actions.Config
+	cfg.Exec.Commands = map[string]struct {
+		Path      string
+		Args      []string
+		Env       []string
+		Dir       string
+		AllowArgs bool
+	}{
+		"echo_event": {Path: shPath, Args: []string{"-c", "cat; echo \"$GREETING\" >&2"}, Env: []string{"GREETING=hello"}},
+		"exit_code":  {Path: shPath, Args: []string{"-c", "exit 3"}},
+		"sleep":      {Path: shPath, Args: []string{"-c", "sleep 5"}},
+		"script":     {Path: shPath, Args: []string{"-c"}, AllowArgs: true},
+		"limits":     {Path: shPath, Args: []string{"-c", "cat >/dev/null; cat /proc/self/limits"}},
+		"fork":       {Path: shPath, Args: []string{"-c", "cat /proc/self/limits & wait"}},
+		"spin":       {Path: shPath, Args: []string{"-c", "cat >/dev/null; while :; do :; done"}},
+	}
+	return cfg
+}
+
+func TestExecHook(t *testing.T) {
+	cfg := newExecTestConfig(t)
+	record := graveler.HookRecord{
+		RunID:        "run1",
+		EventType:    graveler.EventTypePreCommit,
+		RepositoryID: "repo1",
+		BranchID:     "main",
+		SourceRef:    "main",
+	}
+
+	tests := []struct {
+		name       string
+		properties actions.Properties
+		newErr     bool
+		runErr     bool
+		output     []string
+	}{
+		{
+			name:       "pass",
+			properties: actions.Properties{"command": "echo_event"},
+			output:     []string{`"event_type":"pre-commit"`, `"repository_id":"repo1"`, "hello", "Exit Code: 0"},
+		},
+		{
+			name:       "exit code",
+			properties: actions.Properties{"command": "exit_code"},
+			runErr:     true,
+			output:     []string{"Exit Code: 3"},
+		},
+		{
+			name:       "timeout",
+			properties: actions.Properties{"command": "sleep", "timeout": "100ms"},
+			runErr:     true,
+		},
+		{
+			name:       "args",
+			properties: actions.Properties{"command": "script", "args": []interface{}{"echo from action"}},
+			output:     []string{"from action"},
+		},
+		{
+			name:       "args not allowed",
+			properties: actions.Properties{"command": "exit_code", "args": []interface{}{"echo"}},
+			newErr:     true,
+		},
+		{
+			name:       "command not allowed",
+			properties: actions.Properties{"command": "rm"},
+			newErr:     true,
+		},
+		{
+			name:       "missing command",
+			properties: actions.Properties{},
+			newErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := actions.NewExecHook(actions.ActionHook{
+				ID:         "exec_hook",
+				Type:       actions.HookTypeExec,
+				Properties: tt.properties,
+			}, &actions.Action{Name: "exec action"}, cfg, nil)
+			if tt.newErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = hook.Run(context.Background(), record, &buf)
+			if tt.runErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, s := range tt.output {
+				require.Contains(t, buf.String(), s)
+			}
+		})
+	}
+}
+
+func TestExecHookLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("exec hook resource limits are supported only on linux")
+	}
+	cfg := newExecTestConfig(t)
+	cfg.Exec.Limits.CPUTime = 1500 * time.Millisecond
+	cfg.Exec.Limits.Memory = 1 << 30
+	cfg.Exec.Limits.OpenFiles = 64
+	record := graveler.HookRecord{
+		RunID:        "run1",
+		EventType:    graveler.EventTypePreCommit,
+		RepositoryID: "repo1",
+		BranchID:     "main",
+	}
+
+	t.Run("applied", func(t *testing.T) {
+		hook, err := actions.NewExecHook(actions.ActionHook{
+			ID:         "exec_hook",
+			Type:       actions.HookTypeExec,
+			Properties: actions.Properties{"command": "limits"},
+		}, &actions.Action{Name: "exec action"}, cfg, nil)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, hook.Run(context.Background(), record, &buf))
+		// soft and hard limits are both set
+		for _, re := range []string{
+			`Max cpu time\s+2\s+2\s`,
+			`Max address space\s+1073741824\s+1073741824\s`,
+			`Max open files\s+64\s+64\s`,
+		} {
+			require.Regexp(t, regexp.MustCompile(re), buf.String())
+		}
+	})
+
+	t.Run("forked child", func(t *testing.T) {
+		// the command forks before reading its input, the child runs limited from its start
+		hook, err := actions.NewExecHook(actions.ActionHook{
+			ID:         "exec_hook",
+			Type:       actions.HookTypeExec,
+			Properties: actions.Properties{"command": "fork"},
+		}, &actions.Action{Name: "exec action"}, cfg, nil)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, hook.Run(context.Background(), record, &buf))
+		for _, re := range []string{
+			`Max cpu time\s+2\s+2\s`,
+			`Max address space\s+1073741824\s+1073741824\s`,
+			`Max open files\s+64\s+64\s`,
+		} {
+			require.Regexp(t, regexp.MustCompile(re), buf.String())
+		}
+	})
+
+	t.Run("cpu time exceeded", func(t *testing.T) {
+		hook, err := actions.NewExecHook(actions.ActionHook{
+			ID:         "exec_hook",
+			Type:       actions.HookTypeExec,
+			Properties: actions.Properties{"command": "spin"},
+		}, &actions.Action{Name: "exec action"}, cfg, nil)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = hook.Run(context.Background(), record, &buf)
+		require.ErrorContains(t, err, "killed by signal")
+	})
+}
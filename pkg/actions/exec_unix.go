@@ -0,0 +1,17 @@
+//go:build !windows
+
+package actions
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs the command in a new process group, so it can be killed together with its children
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
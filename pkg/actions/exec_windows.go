@@ -0,0 +1,9 @@
+package actions
+
+import "os/exec"
+
+func setProcessGroup(_ *exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,266 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	globalActionsPrefix = "global_actions"
+
+	globalActionsCacheKey    = "global_actions"
+	globalActionsCacheExpiry = 5 * time.Second
+	globalActionsCacheJitter = globalActionsCacheExpiry / 2
+)
+
+var ErrGlobalActionConfigured = errors.New("global action is set by the lakeFS configuration")
+
+// GlobalAction is an action run on every repository, in addition to the actions of the repository
+type GlobalAction struct {
+	Name    string
+	Content []byte
+	// Enforced prevents a repository action with the same name from overriding the global action
+	Enforced bool
+	// Configured is set on global actions loaded from the lakeFS configuration, these cannot be changed by the API
+	Configured   bool
+	CreationDate time.Time
+}
+
+func GlobalActionPath(name string) []byte {
+	return []byte(kv.FormatPath(globalActionsPrefix, name))
+}
+
+func globalActionFromProto(m *GlobalActionData) *GlobalAction {
+	return &GlobalAction{
+		Name:         m.Name,
+		Content:      m.Content,
+		Enforced:     m.Enforced,
+		CreationDate: m.CreationDate.AsTime(),
+	}
+}
+
+// loadConfiguredGlobalActions reads and validates the action files of the configured paths. The action files of a
+// directory path are its .yaml and .yml files.
+func loadConfiguredGlobalActions(paths []string, enforced bool) ([]*GlobalAction, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("global actions path: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("global actions path: %w", err)
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			files = append(files, filepath.Join(p, entry.Name()))
+		}
+	}
+
+	actions := make([]*GlobalAction, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("global action file: %w", err)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("global action file: %w", err)
+		}
+		action, err := ParseAction(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing global action file %s: %w", file, err)
+		}
+		actions = append(actions, &GlobalAction{
+			Name:         action.Name,
+			Content:      content,
+			Enforced:     enforced,
+			Configured:   true,
+			CreationDate: info.ModTime(),
+		})
+	}
+	if err := validateGlobalActions(actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// ListGlobalActions returns the global actions of the configuration and the global actions set by the API, ordered by name
+func (s *StoreService) ListGlobalActions(ctx context.Context) ([]*GlobalAction, error) {
+	stored, err := s.storedGlobalActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*GlobalAction, 0, len(s.globalActions)+len(stored))
+	actions = append(actions, s.globalActions...)
+	for _, action := range stored {
+		// the configuration takes precedence over an action set before the configuration changed
+		if s.configuredGlobalAction(action.Name) != nil {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Name < actions[j].Name
+	})
+	return actions, nil
+}
+
+// storedGlobalActions returns the global actions set by the API. The list is cached, and dropped from the cache when
+// a global action is set or deleted by this lakeFS instance - changes made by other instances are seen once the
+// cached list expires.
+func (s *StoreService) storedGlobalActions(ctx context.Context) ([]*GlobalAction, error) {
+	actions, err := s.globalActionsCache.GetOrSet(globalActionsCacheKey, func() (interface{}, error) {
+		stored, err := s.Store.listGlobalActions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		actions := make([]*GlobalAction, 0, len(stored))
+		for _, m := range stored {
+			actions = append(actions, globalActionFromProto(m))
+		}
+		return actions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return actions.([]*GlobalAction), nil
+}
+
+func validateGlobalActions(actions []*GlobalAction) error {
+	names := make(map[string]struct{}, len(actions))
+	for _, action := range actions {
+		if _, found := names[action.Name]; found {
+			return fmt.Errorf("global action name '%s' already loaded: %w", action.Name, ErrInvalidAction)
+		}
+		names[action.Name] = struct{}{}
+	}
+	return nil
+}
+
+// configuredGlobalAction returns the global action of the configuration with the name, nil if there is none
+func (s *StoreService) configuredGlobalAction(name string) *GlobalAction {
+	for _, action := range s.globalActions {
+		if action.Name == name {
+			return action
+		}
+	}
+	return nil
+}
+
+func (s *StoreService) GetGlobalAction(ctx context.Context, name string) (*GlobalAction, error) {
+	if action := s.configuredGlobalAction(name); action != nil {
+		return action, nil
+	}
+	m, err := s.Store.getGlobalAction(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return globalActionFromProto(m), nil
+}
+
+// SetGlobalAction parses and saves a global action, replacing the global action with the same name
+func (s *StoreService) SetGlobalAction(ctx context.Context, content []byte, enforced bool) (*GlobalAction, error) {
+	action, err := ParseAction(content)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidAction) {
+			err = fmt.Errorf("%s: %w", err, ErrInvalidAction)
+		}
+		return nil, err
+	}
+	if s.configuredGlobalAction(action.Name) != nil {
+		return nil, fmt.Errorf("global action %s: %w", action.Name, ErrGlobalActionConfigured)
+	}
+	m := &GlobalActionData{
+		Name:         action.Name,
+		Content:      content,
+		Enforced:     enforced,
+		CreationDate: timestamppb.Now(),
+	}
+	err = s.Store.setGlobalAction(ctx, m)
+	s.globalActionsCache.Remove(globalActionsCacheKey)
+	if err != nil {
+		return nil, err
+	}
+	return globalActionFromProto(m), nil
+}
+
+func (s *StoreService) DeleteGlobalAction(ctx context.Context, name string) error {
+	if s.configuredGlobalAction(name) != nil {
+		return fmt.Errorf("global action %s: %w", name, ErrGlobalActionConfigured)
+	}
+	if _, err := s.Store.getGlobalAction(ctx, name); err != nil {
+		return err
+	}
+	err := s.Store.deleteGlobalAction(ctx, name)
+	s.globalActionsCache.Remove(globalActionsCacheKey)
+	return err
+}
+
+// loadActions returns the actions of the repository merged with the global actions
+func (s *StoreService) loadActions(ctx context.Context, record graveler.HookRecord) ([]*Action, error) {
+	actions, err := LoadActions(ctx, s.Source, record)
+	if err != nil {
+		return nil, err
+	}
+	global, err := s.ListGlobalActions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list global actions: %w", err)
+	}
+	return mergeGlobalActions(ctx, global, actions)
+}
+
+// mergeGlobalActions returns the global actions and the repository actions. A repository action overrides the global
+// action with the same name, unless the global action is enforced - then the repository action is ignored.
+func mergeGlobalActions(ctx context.Context, global []*GlobalAction, actions []*Action) ([]*Action, error) {
+	if len(global) == 0 {
+		return actions, nil
+	}
+	repoActions := make(map[string]struct{}, len(actions))
+	for _, action := range actions {
+		repoActions[action.Name] = struct{}{}
+	}
+	merged := make([]*Action, 0, len(global)+len(actions))
+	ignored := make(map[string]struct{})
+	for _, g := range global {
+		_, overridden := repoActions[g.Name]
+		if overridden && !g.Enforced {
+			continue
+		}
+		// parsed on each run, as the loaded actions keep the changed paths of the run
+		action, err := ParseAction(g.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing global action %s: %w", g.Name, err)
+		}
+		if overridden {
+			logging.FromContext(ctx).WithField("action", g.Name).Warn("Repository action ignored, overrides an enforced global action")
+			ignored[g.Name] = struct{}{}
+		}
+		merged = append(merged, action)
+	}
+	for _, action := range actions {
+		if _, found := ignored[action.Name]; !found {
+			merged = append(merged, action)
+		}
+	}
+	return merged, nil
+}
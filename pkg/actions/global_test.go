@@ -0,0 +1,200 @@
+package actions_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/mock"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/stats"
+)
+
+func TestGlobalActions(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	const actionTemplate = `name: %s
+on:
+  pre-commit: {}
+hooks:
+  - id: call
+    type: webhook
+    properties:
+      url: "%s/%s"
+`
+	configDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "policy.yaml"), []byte(fmt.Sprintf(actionTemplate, "policy", ts.URL, "global-policy")), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "README.md"), []byte("not an action"), 0o600))
+
+	record := graveler.HookRecord{
+		RunID:            graveler.NewRunID(),
+		EventType:        graveler.EventTypePreCommit,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		SourceRef:        "main",
+		BranchID:         "main",
+	}
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+	testOutputWriter.EXPECT().OutputWrite(ctx, "storageNamespace", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	testSource := mock.NewMockSource(ctrl)
+	testSource.EXPECT().List(ctx, record).Return([]string{"policy.yaml", "notify.yaml", "repo.yaml"}, nil)
+	testSource.EXPECT().Load(ctx, record, "policy.yaml").Return([]byte(fmt.Sprintf(actionTemplate, "policy", ts.URL, "repo-policy")), nil)
+	testSource.EXPECT().Load(ctx, record, "notify.yaml").Return([]byte(fmt.Sprintf(actionTemplate, "notify", ts.URL, "repo-notify")), nil)
+	testSource.EXPECT().Load(ctx, record, "repo.yaml").Return([]byte(fmt.Sprintf(actionTemplate, "repo", ts.URL, "repo")), nil)
+
+	cfg := actions.Config{Enabled: true}
+	cfg.Global.Paths = []string{configDir}
+	cfg.Global.Enforced = true
+	kvStore := kvtest.GetStore(ctx, t)
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), testSource, testOutputWriter, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, cfg)
+	require.NoError(t, err)
+	defer actionsService.Stop()
+
+	// set global actions by the API
+	_, err = actionsService.SetGlobalAction(ctx, []byte(fmt.Sprintf(actionTemplate, "notify", ts.URL, "global-notify")), false)
+	require.NoError(t, err)
+	_, err = actionsService.SetGlobalAction(ctx, []byte(fmt.Sprintf(actionTemplate, "audit", ts.URL, "global-audit")), true)
+	require.NoError(t, err)
+	_, err = actionsService.SetGlobalAction(ctx, []byte(fmt.Sprintf(actionTemplate, "policy", ts.URL, "other")), false)
+	require.ErrorIs(t, err, actions.ErrGlobalActionConfigured)
+	_, err = actionsService.SetGlobalAction(ctx, []byte("name: no hooks"), false)
+	require.ErrorIs(t, err, actions.ErrInvalidAction)
+
+	globalActions, err := actionsService.ListGlobalActions(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, a := range globalActions {
+		names = append(names, a.Name)
+	}
+	require.Equal(t, []string{"audit", "notify", "policy"}, names)
+	require.True(t, globalActions[0].Enforced)
+	require.False(t, globalActions[0].Configured)
+	require.False(t, globalActions[1].Enforced)
+	require.True(t, globalActions[2].Enforced)
+	require.True(t, globalActions[2].Configured)
+
+	// the enforced global policy ignores the repository policy, the repository notify overrides the global notify
+	require.NoError(t, actionsService.Run(ctx, record))
+	sort.Strings(calls)
+	require.Equal(t, []string{"/global-audit", "/global-policy", "/repo", "/repo-notify"}, calls)
+
+	require.ErrorIs(t, actionsService.DeleteGlobalAction(ctx, "policy"), actions.ErrGlobalActionConfigured)
+	require.NoError(t, actionsService.DeleteGlobalAction(ctx, "notify"))
+	require.ErrorIs(t, actionsService.DeleteGlobalAction(ctx, "notify"), actions.ErrNotFound)
+	_, err = actionsService.GetGlobalAction(ctx, "notify")
+	require.ErrorIs(t, err, actions.ErrNotFound)
+	policy, err := actionsService.GetGlobalAction(ctx, "policy")
+	require.NoError(t, err)
+	require.True(t, policy.Configured)
+}
+
+func TestGlobalActionsRepositoryEvents(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	configDir := t.TempDir()
+	content := fmt.Sprintf(`name: repositories
+on:
+  pre-create-repository: {}
+hooks:
+  - id: call
+    type: webhook
+    properties:
+      url: "%s/create"
+`, ts.URL)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "repositories.yaml"), []byte(content), 0o600))
+
+	// the repository doesn't exist yet, so there is no reference to read its actions from
+	record := graveler.HookRecord{
+		RunID:            graveler.NewRunID(),
+		EventType:        graveler.EventTypePreCreateRepository,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		BranchID:         "main",
+	}
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+	testOutputWriter.EXPECT().OutputWrite(ctx, "storageNamespace", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	testSource := mock.NewMockSource(ctrl)
+	testSource.EXPECT().List(ctx, record).Return(nil, nil)
+
+	cfg := actions.Config{Enabled: true}
+	cfg.Global.Paths = []string{configDir}
+	kvStore := kvtest.GetStore(ctx, t)
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), testSource, testOutputWriter, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, cfg)
+	require.NoError(t, err)
+	defer actionsService.Stop()
+
+	require.NoError(t, actionsService.PreCreateRepositoryHook(ctx, record))
+	require.Equal(t, []string{"/create"}, calls)
+}
+
+func TestGlobalActionsInvalidConfiguration(t *testing.T) {
+	ctx := context.Background()
+	const action = `name: %s
+on:
+  pre-commit: {}
+hooks:
+  - id: call
+    type: webhook
+    properties:
+      url: "http://localhost/%s"
+`
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "invalid",
+			files: map[string]string{"invalid.yaml": "name: no hooks"},
+		},
+		{
+			name: "duplicate",
+			files: map[string]string{
+				"first.yaml":  fmt.Sprintf(action, "policy", "first"),
+				"second.yaml": fmt.Sprintf(action, "policy", "second"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(configDir, name), []byte(content), 0o600))
+			}
+			cfg := actions.Config{Enabled: true}
+			cfg.Global.Paths = []string{configDir}
+			kvStore := kvtest.GetStore(ctx, t)
+			// the configuration is validated once, instead of failing the hooks of every event
+			_, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), nil, nil, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, cfg)
+			require.ErrorIs(t, err, actions.ErrInvalidAction)
+		})
+	}
+}
@@ -16,6 +16,11 @@ const (
 	HookTypeWebhook HookType = "webhook"
 	HookTypeAirflow HookType = "airflow"
 	HookTypeLua     HookType = "lua"
+	HookTypeExec    HookType = "exec"
+	// HookTypeKafka, HookTypeNATS and HookTypeHTTPEvent publish the event as a CloudEvent
+	HookTypeKafka     HookType = "kafka"
+	HookTypeNATS      HookType = "nats"
+	HookTypeHTTPEvent HookType = "http_event"
 )
 
 // Hook is the abstraction of the basic user-configured runnable building-stone
@@ -30,12 +35,18 @@ type HookBase struct {
 	ActionName string
 	Config     Config
 	Endpoint   *http.Server
+	// ChangedPaths are the paths changed by the event that matched the action's path filters
+	ChangedPaths []string
 }
 
 var hooks = map[HookType]NewHookFunc{
-	HookTypeWebhook: NewWebhook,
-	HookTypeAirflow: NewAirflowHook,
-	HookTypeLua:     NewLuaHook,
+	HookTypeWebhook:   NewWebhook,
+	HookTypeAirflow:   NewAirflowHook,
+	HookTypeLua:       NewLuaHook,
+	HookTypeExec:      NewExecHook,
+	HookTypeKafka:     NewKafkaHook,
+	HookTypeNATS:      NewNATSHook,
+	HookTypeHTTPEvent: NewHTTPEventHook,
 }
 
 var ErrUnknownHookType = errors.New("unknown hook type")
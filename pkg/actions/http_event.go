@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// HTTPEventHook posts the event as a CloudEvent to an HTTP endpoint, using the CloudEvents HTTP protocol binding
+type HTTPEventHook struct {
+	*Webhook
+	cloudEventsProperties
+}
+
+const cloudEventsHTTPHeaderPrefix = "ce-"
+
+func NewHTTPEventHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook, error) {
+	webhook, err := NewWebhook(h, action, cfg, e)
+	if err != nil {
+		return nil, err
+	}
+	props, err := extractCloudEventsProperties(h.Properties)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPEventHook{
+		Webhook:               webhook.(*Webhook),
+		cloudEventsProperties: props,
+	}, nil
+}
+
+func (h *HTTPEventHook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) error {
+	logging.FromContext(ctx).
+		WithField("hook_type", "http_event").
+		WithField("event_type", record.EventType).
+		Debug("hook action executing")
+
+	info := newEventInfo(h.ActionName, h.ID, h.ChangedPaths, record)
+	body, attrs, err := newCloudEvent(h.Source, &info, record).encode(h.ContentMode, cloudEventsHTTPHeaderPrefix)
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		headers[attr.Key] = string(attr.Value)
+	}
+	return h.post(ctx, body, headers, buf)
+}
@@ -0,0 +1,245 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// KafkaHook publishes the event as a CloudEvent to a Kafka topic, using the CloudEvents Kafka protocol binding
+type KafkaHook struct {
+	HookBase
+	cloudEventsProperties
+	Brokers  []string
+	Topic    string
+	Key      *template.Template
+	Headers  map[string]SecureString
+	Acks     messaging.KafkaAcks
+	Retries  int
+	TLS      bool
+	Username string
+	Password SecureString
+	Timeout  time.Duration
+	// Messaging keeps the producers of the service running the hook
+	Messaging *messaging.Clients
+}
+
+const (
+	kafkaDefaultTimeout = 1 * time.Minute
+	kafkaDefaultRetries = 3
+	kafkaDefaultKey     = "{{ .RepositoryID }}"
+
+	kafkaBrokersPropertyKey  = "brokers"
+	kafkaTopicPropertyKey    = "topic"
+	kafkaKeyPropertyKey      = "key"
+	kafkaAcksPropertyKey     = "acks"
+	kafkaRetriesPropertyKey  = "retries"
+	kafkaTLSPropertyKey      = "tls"
+	kafkaUsernamePropertyKey = "username"
+	kafkaPasswordPropertyKey = "password"
+	kafkaTimeoutPropertyKey  = "timeout"
+
+	cloudEventsKafkaHeaderPrefix = "ce_"
+)
+
+var (
+	errKafkaHookWrongFormat = errors.New("kafka hook wrong format")
+
+	kafkaAcks = map[string]messaging.KafkaAcks{
+		"none":   messaging.KafkaAcksNone,
+		"leader": messaging.KafkaAcksLeader,
+		"all":    messaging.KafkaAcksAll,
+	}
+)
+
+func NewKafkaHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook, error) {
+	kafkaHook := &KafkaHook{
+		HookBase: HookBase{
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     e,
+			ChangedPaths: action.ChangedPaths,
+		},
+		Acks:    messaging.KafkaAcksAll,
+		Retries: kafkaDefaultRetries,
+		Timeout: kafkaDefaultTimeout,
+	}
+
+	var err error
+	kafkaHook.Brokers, err = extractStringList(h.Properties, kafkaBrokersPropertyKey)
+	if err != nil {
+		return nil, fmt.Errorf("kafka hook brokers property: %w", err)
+	}
+	if len(kafkaHook.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka hook brokers property: %w", errMissingKey)
+	}
+	kafkaHook.Topic, err = h.Properties.getRequiredProperty(kafkaTopicPropertyKey)
+	if err != nil {
+		return nil, fmt.Errorf("kafka hook topic property: %w", err)
+	}
+
+	key := kafkaDefaultKey
+	if v, ok := h.Properties[kafkaKeyPropertyKey].(string); ok {
+		key = v
+	}
+	kafkaHook.Key, err = parseEventTemplate(kafkaKeyPropertyKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("kafka hook key property: %w", err)
+	}
+
+	kafkaHook.Headers, err = extractHeaders(h.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("kafka hook headers property: %w", err)
+	}
+
+	if v, ok := h.Properties[kafkaAcksPropertyKey]; ok {
+		acks, ok := kafkaAcks[fmt.Sprint(v)]
+		if !ok {
+			return nil, fmt.Errorf("kafka hook acks '%v' must be one of none, leader or all: %w", v, errKafkaHookWrongFormat)
+		}
+		kafkaHook.Acks = acks
+	}
+	if v, ok := h.Properties[kafkaRetriesPropertyKey]; ok {
+		retries, ok := v.(int)
+		if !ok || retries < 0 {
+			return nil, fmt.Errorf("kafka hook retries must be a non negative integer: %w", errKafkaHookWrongFormat)
+		}
+		kafkaHook.Retries = retries
+	}
+	if v, ok := h.Properties[kafkaTLSPropertyKey].(bool); ok {
+		kafkaHook.TLS = v
+	}
+	if v, ok := h.Properties[kafkaUsernamePropertyKey].(string); ok {
+		kafkaHook.Username = v
+		rawPass, err := h.Properties.getRequiredProperty(kafkaPasswordPropertyKey)
+		if err != nil {
+			return nil, fmt.Errorf("kafka hook password property: %w", err)
+		}
+		kafkaHook.Password, err = NewSecureString(rawPass)
+		if err != nil {
+			return nil, fmt.Errorf("kafka hook password property: %w", err)
+		}
+	}
+	if v, ok := h.Properties[kafkaTimeoutPropertyKey].(string); ok && v != "" {
+		kafkaHook.Timeout, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("kafka hook timeout property: %w", err)
+		}
+	}
+
+	kafkaHook.cloudEventsProperties, err = extractCloudEventsProperties(h.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("kafka hook: %w", err)
+	}
+	return kafkaHook, nil
+}
+
+func (k *KafkaHook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) error {
+	logging.FromContext(ctx).
+		WithField("hook_type", "kafka").
+		WithField("event_type", record.EventType).
+		Debug("hook action executing")
+
+	info := newEventInfo(k.ActionName, k.ID, k.ChangedPaths, record)
+	key, err := executeEventTemplate(k.Key, &info)
+	if err != nil {
+		return err
+	}
+	body, headers, err := newCloudEvent(k.Source, &info, record).encode(k.ContentMode, cloudEventsKafkaHeaderPrefix)
+	if err != nil {
+		return err
+	}
+	msg := messaging.Message{Key: []byte(key), Value: body, Headers: headers}
+
+	_, _ = fmt.Fprintf(buf, "Brokers: %s\nTopic: %s\nKey: %s\n", strings.Join(k.Brokers, ","), k.Topic, key)
+	buf.WriteString("Headers:\n")
+	msg.Headers = appendSecureHeaders(msg.Headers, k.Headers, buf)
+	_, _ = fmt.Fprintf(buf, "Message Body:\n%s\n\n", body)
+
+	cfg := messaging.KafkaConfig{
+		Brokers:  k.Brokers,
+		Username: k.Username,
+		Password: k.Password.val,
+		Acks:     k.Acks,
+		Timeout:  k.Timeout,
+		Retries:  k.Retries,
+		TLS:      k.TLS,
+	}
+	clients, closeClients := publishClients(k.Messaging)
+	defer closeClients()
+	start := time.Now()
+	res, err := clients.KafkaProduce(ctx, cfg, k.Topic, msg)
+	_, _ = fmt.Fprintf(buf, "Produce duration: %s\n", time.Since(start))
+	if err != nil {
+		return fmt.Errorf("kafka produce to %s: %w", k.Topic, err)
+	}
+	_, _ = fmt.Fprintf(buf, "Partition: %d\nOffset: %d\n", res.Partition, res.Offset)
+	return nil
+}
+
+// publishClients returns the clients of the service running a hook, or clients for a single message, closed by the
+// returned func, when the hook runs without a service
+func publishClients(clients *messaging.Clients) (*messaging.Clients, func()) {
+	if clients != nil {
+		return clients, func() {}
+	}
+	clients = messaging.NewClients()
+	return clients, clients.Close
+}
+
+// appendSecureHeaders appends the headers to the message headers ordered by name, and logs them to the buffer
+func appendSecureHeaders(headers []messaging.Header, secureHeaders map[string]SecureString, buf *bytes.Buffer) []messaging.Header {
+	for _, h := range headers {
+		_, _ = fmt.Fprintf(buf, "%s: %s\n", h.Key, h.Value)
+	}
+	names := make([]string, 0, len(secureHeaders))
+	for name := range secureHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := secureHeaders[name]
+		headers = append(headers, messaging.Header{Key: name, Value: []byte(v.val)})
+		_, _ = fmt.Fprintf(buf, "%s: %s\n", name, v.String())
+	}
+	return headers
+}
+
+// extractStringList returns a property which is a list of strings, or a comma separated string
+func extractStringList(props Properties, key string) ([]string, error) {
+	switch v := props[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var list []string
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		return list, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("value of %s must be a list of strings: %w", key, errWrongValueType)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("value of %s must be a list of strings: %w", key, errWrongValueType)
+	}
+}
@@ -25,9 +25,11 @@ type LuaHook struct {
 	Script     string
 	ScriptPath string
 	Args       map[string]interface{}
+	// Storage is the access of the lakefs/storage module to the storage namespaces of repositories
+	Storage *lakefs.Storage
 }
 
-func applyRecord(l *lua.State, actionName, hookID string, record graveler.HookRecord) {
+func applyRecord(l *lua.State, actionName, hookID string, changedPaths []string, record graveler.HookRecord) {
 	parents := make([]string, len(record.Commit.Parents))
 	for i := 0; i < len(record.Commit.Parents); i++ {
 		parents[i] = string(record.Commit.Parents[i])
@@ -36,7 +38,7 @@ func applyRecord(l *lua.State, actionName, hookID string, record graveler.HookRe
 	for k, v := range record.Commit.Metadata {
 		metadata[k] = v
 	}
-	luautil.DeepPush(l, map[string]interface{}{
+	action := map[string]interface{}{
 		"action_name":       actionName,
 		"hook_id":           hookID,
 		"run_id":            record.RunID,
@@ -48,6 +50,8 @@ func applyRecord(l *lua.State, actionName, hookID string, record graveler.HookRe
 		"tag_id":            record.TagID.String(),
 		"repository_id":     record.RepositoryID.String(),
 		"storage_namespace": record.StorageNamespace.String(),
+		"origin_commit_id":  record.OriginCommitID.String(),
+		"object_key":        record.ObjectKey.String(),
 		"commit": map[string]interface{}{
 			"message":       record.Commit.Message,
 			"meta_range_id": record.Commit.MetaRangeID.String(),
@@ -56,16 +60,22 @@ func applyRecord(l *lua.State, actionName, hookID string, record graveler.HookRe
 			"metadata":      metadata,
 			"parents":       parents,
 		},
-	})
+	}
+	if changedPaths != nil {
+		// set only when the action has path filters
+		action["changed_paths"] = changedPaths
+	}
+	luautil.DeepPush(l, action)
 	l.SetGlobal("action")
 }
 
-func injectHookContext(l *lua.State, ctx context.Context, user *model.User, endpoint *http.Server, args map[string]interface{}) {
+func injectHookContext(l *lua.State, ctx context.Context, user *model.User, endpoint *http.Server, storage *lakefs.Storage, args map[string]interface{}) {
 	l.PushString(user.Username)
 	l.SetGlobal("username")
 	luautil.DeepPush(l, args)
 	l.SetGlobal("args")
 	lakefs.OpenClient(l, ctx, user, endpoint)
+	lakefs.OpenStorage(l, ctx, user, endpoint, storage)
 }
 
 type loggingBuffer struct {
@@ -84,9 +94,9 @@ func (h *LuaHook) Run(ctx context.Context, record graveler.HookRecord, buf *byte
 		return err
 	}
 	l := lua.NewState()
-	lualibs.OpenSafe(l, ctx, h.Config.Lua, &loggingBuffer{buf: buf, ctx: ctx})
-	injectHookContext(l, ctx, user, h.Endpoint, h.Args)
-	applyRecord(l, h.ActionName, h.ID, record)
+	lualibs.OpenSafe(l, ctx, lualibs.OpenSafeConfig{NetHTTPEnabled: h.Config.Lua.NetHTTPEnabled}, &loggingBuffer{buf: buf, ctx: ctx})
+	injectHookContext(l, ctx, user, h.Endpoint, h.Storage, h.Args)
+	applyRecord(l, h.ActionName, h.ID, h.ChangedPaths, record)
 
 	// determine if this is an object to load
 	code := h.Script
@@ -196,10 +206,11 @@ func NewLuaHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook,
 	if err == nil {
 		return &LuaHook{
 			HookBase: HookBase{
-				ID:         h.ID,
-				ActionName: action.Name,
-				Config:     cfg,
-				Endpoint:   e,
+				ID:           h.ID,
+				ActionName:   action.Name,
+				Config:       cfg,
+				Endpoint:     e,
+				ChangedPaths: action.ChangedPaths,
 			},
 			Script: script,
 			Args:   args,
@@ -219,10 +230,11 @@ func NewLuaHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook,
 
 	return &LuaHook{
 		HookBase: HookBase{
-			ID:         h.ID,
-			ActionName: action.Name,
-			Config:     cfg,
-			Endpoint:   e,
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     e,
+			ChangedPaths: action.ChangedPaths,
 		},
 		ScriptPath: scriptFile,
 		Args:       args,
@@ -0,0 +1,74 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+func Open(l *lua.State) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, library)
+		return 1
+	}
+	lua.Require(l, "encoding/avro", open, false)
+	l.Pop(1)
+}
+
+var library = []lua.RegistryFunction{
+	{Name: "read", Function: read},
+	{Name: "get_schema", Function: getSchema},
+}
+
+func check(l *lua.State, err error) {
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+}
+
+// ReadRecords reads the records of an avro object container file
+func ReadRecords(src util.ReaderAtSize, opts util.ReadOptions) ([]interface{}, error) {
+	r, err := NewReader(util.NewSectionReader(src))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Skip(opts.Offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return []interface{}{}, nil
+		}
+		return nil, err
+	}
+	records := make([]interface{}, 0)
+	for opts.Limit < 0 || int64(len(records)) < opts.Limit {
+		record, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func read(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	records, err := ReadRecords(src, util.CheckReadOptions(l, 2))
+	check(l, err)
+	return util.DeepPush(l, util.ExactNumbers(records))
+}
+
+// getSchema returns the schema of an avro object container file, as decoded from its JSON representation
+func getSchema(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	r, err := NewReader(util.NewSectionReader(src))
+	check(l, err)
+	var schema interface{}
+	check(l, json.Unmarshal([]byte(r.Metadata()[schemaMetadataKey]), &schema))
+	return util.DeepPush(l, schema)
+}
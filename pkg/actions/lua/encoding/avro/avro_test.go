@@ -0,0 +1,159 @@
+package avro_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	"github.com/go-test/deep"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro/avrotest"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+const testSchema = `{
+	"type": "record",
+	"name": "Event",
+	"namespace": "io.lakefs",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["CREATE", "DELETE"]}},
+		{"name": "path", "type": ["null", "string"]},
+		{"name": "ok", "type": "boolean"},
+		{"name": "score", "type": "double"},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "metadata", "type": {"type": "map", "values": "int"}},
+		{"name": "checksum", "type": {"type": "fixed", "name": "MD5", "size": 4}},
+		{"name": "parent", "type": ["null", "Event"]}
+	]
+}`
+
+func testRecords(n int) []interface{} {
+	records := make([]interface{}, n)
+	for i := range records {
+		var parent interface{}
+		if i%2 == 1 {
+			parent = map[string]interface{}{
+				"id": int64(i - 1), "kind": "CREATE", "path": nil, "ok": false, "score": 0.0,
+				"tags": []interface{}{}, "metadata": map[string]interface{}{}, "checksum": "abcd", "parent": nil,
+			}
+		}
+		records[i] = map[string]interface{}{
+			"id":       int64(i) + 1<<60,
+			"kind":     "DELETE",
+			"path":     "data/file",
+			"ok":       true,
+			"score":    float64(i) / 2,
+			"tags":     []interface{}{"a", "b"},
+			"metadata": map[string]interface{}{"size": int64(i)},
+			"checksum": "efgh",
+			"parent":   parent,
+		}
+	}
+	return records
+}
+
+func TestReadRecords(t *testing.T) {
+	records := testRecords(10)
+	for _, codec := range []string{avro.CodecNull, avro.CodecDeflate, avro.CodecSnappy, avro.CodecZstandard} {
+		t.Run(codec, func(t *testing.T) {
+			data := avrotest.WriteOCF(t, testSchema, codec, nil, 3, records)
+			got, err := avro.ReadRecords(strings.NewReader(string(data)), util.ReadOptions{Limit: -1})
+			if err != nil {
+				t.Fatalf("read records: %s", err)
+			}
+			if diff := deep.Equal(got, records); diff != nil {
+				t.Fatalf("unexpected records: %s", diff)
+			}
+
+			got, err = avro.ReadRecords(strings.NewReader(string(data)), util.ReadOptions{Offset: 4, Limit: 3})
+			if err != nil {
+				t.Fatalf("read records with offset: %s", err)
+			}
+			if diff := deep.Equal(got, records[4:7]); diff != nil {
+				t.Fatalf("unexpected records: %s", diff)
+			}
+
+			got, err = avro.ReadRecords(strings.NewReader(string(data)), util.ReadOptions{Offset: 20, Limit: -1})
+			if err != nil || len(got) != 0 {
+				t.Fatalf("read records after the last: %v, %s", got, err)
+			}
+		})
+	}
+}
+
+func TestReadLogicalTypes(t *testing.T) {
+	const schema = `{
+		"type": "record",
+		"name": "Partition",
+		"fields": [
+			{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+			{"name": "ts", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}]},
+			{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}},
+			{"name": "value", "type": ["null", "int", "string"]}
+		]
+	}`
+	records := []interface{}{
+		map[string]interface{}{"day": int64(19000), "ts": int64(1641600000123), "amount": "\x30\x39", "value": int64(7)},
+		map[string]interface{}{"day": int64(0), "ts": nil, "amount": "\x00", "value": nil},
+	}
+	data := avrotest.WriteOCF(t, schema, avro.CodecNull, nil, 10, records)
+	got, err := avro.ReadRecords(bytes.NewReader(data), util.ReadOptions{Limit: -1})
+	if err != nil {
+		t.Fatalf("read records: %s", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"day": int64(19000), "ts": int64(1641600000123), "amount": "123.45", "value": int64(7)},
+		map[string]interface{}{"day": int64(0), "ts": nil, "amount": "0.00", "value": nil},
+	}
+	if diff := deep.Equal(got, expected); diff != nil {
+		t.Fatalf("unexpected records: %s", diff)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	data := avrotest.WriteOCF(t, testSchema, avro.CodecNull, nil, 3, testRecords(5))
+	if _, err := avro.NewReader(strings.NewReader("PAR1")); !errors.Is(err, avro.ErrInvalidFile) {
+		t.Errorf("expected invalid file error, got %v", err)
+	}
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	if _, err := avro.ReadRecords(bytes.NewReader(corrupted), util.ReadOptions{Limit: -1}); !errors.Is(err, avro.ErrInvalidData) {
+		t.Errorf("expected invalid data error, got %v", err)
+	}
+	if _, err := avro.ReadRecords(bytes.NewReader(data[:len(data)-20]), util.ReadOptions{Limit: -1}); err == nil {
+		t.Error("expected error reading a truncated file")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+	l.PushString(string(avrotest.WriteOCF(t, testSchema, avro.CodecDeflate, nil, 2, testRecords(3))))
+	l.SetGlobal("avro_content")
+
+	err := lua.DoString(l, `
+avro = require("encoding/avro")
+schema = avro.get_schema(avro_content)
+print(schema.name .. " " .. #schema.fields)
+for _, r in ipairs(avro.read(avro_content, {offset = 1})) do
+	print(r.id .. " " .. r.kind .. " " .. r.tags[2] .. " " .. r.metadata.size .. " " .. tostring(r.parent and r.parent.id))
+end
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the writer stores the canonical form of the schema, with the full record name
+	const expected = `io.lakefs.Event 9
+1152921504606846977 DELETE b 1 0
+1152921504606846978 DELETE b 2 nil
+`
+	if printed := out.String(); printed != expected {
+		t.Fatalf("got unexpected output:\n%s", printed)
+	}
+}
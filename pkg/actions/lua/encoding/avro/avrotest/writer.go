@@ -0,0 +1,109 @@
+// Package avrotest writes avro object container files for tests
+package avrotest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	hamba "github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+)
+
+// WriteOCF returns an object container file of the records, with blockSize records in each block and the metadata in
+// its header. Records are encoded by the value types returned by the avro reader.
+func WriteOCF(t *testing.T, schemaJSON, codec string, metadata map[string]string, blockSize int, records []interface{}) []byte {
+	t.Helper()
+	schema, err := hamba.ParseWithCache(schemaJSON, "", &hamba.SchemaCache{})
+	if err != nil {
+		t.Fatalf("parse schema: %s", err)
+	}
+	meta := make(map[string][]byte, len(metadata))
+	for k, v := range metadata {
+		meta[k] = []byte(v)
+	}
+
+	var buf bytes.Buffer
+	enc, err := ocf.NewEncoder(schemaJSON, &buf,
+		ocf.WithCodec(ocf.CodecName(codec)),
+		ocf.WithMetadata(meta),
+		ocf.WithBlockLength(blockSize))
+	if err != nil {
+		t.Fatalf("new encoder: %s", err)
+	}
+	for i, record := range records {
+		if err := enc.Encode(encodable(schema, record)); err != nil {
+			t.Fatalf("encode record %d: %s", i, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close encoder: %s", err)
+	}
+	return buf.Bytes()
+}
+
+// encodable converts a value returned by the avro reader to the value hamba/avro encodes
+func encodable(s hamba.Schema, v interface{}) interface{} {
+	switch s := s.(type) {
+	case *hamba.RefSchema:
+		return encodable(s.Schema(), v)
+	case *hamba.RecordSchema:
+		m := v.(map[string]interface{})
+		out := make(map[string]interface{}, len(m))
+		for _, f := range s.Fields() {
+			out[f.Name()] = encodable(f.Type(), m[f.Name()])
+		}
+		return out
+	case *hamba.ArraySchema:
+		items := v.([]interface{})
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = encodable(s.Items(), item)
+		}
+		return out
+	case *hamba.MapSchema:
+		values := v.(map[string]interface{})
+		out := make(map[string]interface{}, len(values))
+		for k, item := range values {
+			out[k] = encodable(s.Values(), item)
+		}
+		return out
+	case *hamba.UnionSchema:
+		// the first branch matching the value type, null for a nil value
+		if v == nil {
+			return nil
+		}
+		for _, branch := range s.Types() {
+			if branch.Type() != hamba.Null {
+				return map[string]interface{}{typeName(branch): encodable(branch, v)}
+			}
+		}
+		return v
+	case *hamba.FixedSchema:
+		arr := reflect.New(reflect.ArrayOf(s.Size(), reflect.TypeOf(byte(0)))).Elem()
+		reflect.Copy(arr, reflect.ValueOf([]byte(v.(string))))
+		return arr.Interface()
+	case *hamba.PrimitiveSchema:
+		switch s.Type() {
+		case hamba.Int:
+			return int(v.(int64))
+		case hamba.Bytes:
+			return []byte(v.(string))
+		}
+	}
+	return v
+}
+
+func typeName(s hamba.Schema) string {
+	if ref, ok := s.(*hamba.RefSchema); ok {
+		s = ref.Schema()
+	}
+	if n, ok := s.(hamba.NamedSchema); ok {
+		return n.FullName()
+	}
+	name := string(s.Type())
+	if l, ok := s.(hamba.LogicalTypeSchema); ok && l.Logical() != nil {
+		name += "." + string(l.Logical().Type())
+	}
+	return name
+}
@@ -0,0 +1,262 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/golang/snappy"
+	hamba "github.com/hamba/avro/v2"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CodecNull      = "null"
+	CodecDeflate   = "deflate"
+	CodecSnappy    = "snappy"
+	CodecZstandard = "zstandard"
+
+	schemaMetadataKey = "avro.schema"
+	codecMetadataKey  = "avro.codec"
+
+	syncMarkerSize = 16
+)
+
+var (
+	ErrInvalidFile      = errors.New("not an avro object container file")
+	ErrInvalidSchema    = errors.New("invalid avro schema")
+	ErrInvalidData      = errors.New("invalid avro data")
+	ErrUnsupportedCodec = errors.New("unsupported avro codec")
+
+	magic = []byte{'O', 'b', 'j', 1}
+)
+
+// maxBlockLength bounds the lengths read from the data before allocating, a corrupted length fails instead of
+// allocating a huge buffer
+const maxBlockLength = 1 << 30
+
+// Reader reads the records of an avro object container file, one block at a time. The container is read here, so
+// whole blocks can be skipped and a truncated file fails, while the records are decoded by hamba/avro.
+type Reader struct {
+	r        *bufio.Reader
+	schema   hamba.Schema
+	metadata map[string]string
+	codec    string
+	sync     [syncMarkerSize]byte
+
+	block          *hamba.Decoder
+	blockRemaining int64
+}
+
+// NewReader reads the header of an avro object container file
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header, magic) {
+		return nil, ErrInvalidFile
+	}
+
+	metadata := make(map[string]string)
+	err := readBlocks(br, func() error {
+		k, err := readBytes(br)
+		if err != nil {
+			return err
+		}
+		v, err := readBytes(br)
+		metadata[string(k)] = string(v)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read avro header: %w", err)
+	}
+
+	reader := &Reader{r: br, metadata: metadata, codec: metadata[codecMetadataKey]}
+	if reader.codec == "" {
+		reader.codec = CodecNull
+	}
+	switch reader.codec {
+	case CodecNull, CodecDeflate, CodecSnappy, CodecZstandard:
+	default:
+		return nil, fmt.Errorf("%s: %w", reader.codec, ErrUnsupportedCodec)
+	}
+	// a schema cache per file, named types of different files may differ
+	reader.schema, err = hamba.ParseWithCache(metadata[schemaMetadataKey], "", &hamba.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+	if _, err := io.ReadFull(br, reader.sync[:]); err != nil {
+		return nil, fmt.Errorf("read avro sync marker: %w", unexpectedEOF(err))
+	}
+	return reader, nil
+}
+
+func (r *Reader) Schema() hamba.Schema {
+	return r.schema
+}
+
+// Metadata returns the metadata of the file header, e.g. the JSON schema under "avro.schema"
+func (r *Reader) Metadata() map[string]string {
+	return r.metadata
+}
+
+// Next returns the next record, or io.EOF after the last record
+func (r *Reader) Next() (interface{}, error) {
+	for r.blockRemaining == 0 {
+		if err := r.readBlock(); err != nil {
+			return nil, err
+		}
+	}
+	var v interface{}
+	if err := r.block.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+	r.blockRemaining--
+	return normalize(r.schema, v), nil
+}
+
+// Skip skips n records, decoding only the blocks that contain skipped records partially
+func (r *Reader) Skip(n int64) error {
+	for n > 0 {
+		for r.blockRemaining == 0 {
+			if err := r.readBlock(); err != nil {
+				return err
+			}
+		}
+		if n >= r.blockRemaining {
+			n -= r.blockRemaining
+			r.blockRemaining = 0
+			continue
+		}
+		if _, err := r.Next(); err != nil {
+			return err
+		}
+		n--
+	}
+	return nil
+}
+
+func (r *Reader) readBlock() error {
+	count, err := binary.ReadVarint(r.r)
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+	if count < 0 {
+		return fmt.Errorf("block count %d: %w", count, ErrInvalidData)
+	}
+	data, err := readBytes(r.r)
+	if err != nil {
+		return fmt.Errorf("read avro block: %w", err)
+	}
+	var sync [syncMarkerSize]byte
+	if _, err := io.ReadFull(r.r, sync[:]); err != nil {
+		return fmt.Errorf("read avro sync marker: %w", unexpectedEOF(err))
+	}
+	if sync != r.sync {
+		return fmt.Errorf("sync marker mismatch: %w", ErrInvalidData)
+	}
+	data, err = r.decompress(data)
+	if err != nil {
+		return fmt.Errorf("decompress avro block: %w", err)
+	}
+	r.block = hamba.NewDecoderForSchema(r.schema, bytes.NewReader(data))
+	r.blockRemaining = count
+	return nil
+}
+
+func (r *Reader) decompress(data []byte) ([]byte, error) {
+	switch r.codec {
+	case CodecDeflate:
+		return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	case CodecSnappy:
+		// the snappy block is followed by the big-endian CRC32 checksum of the uncompressed data
+		if len(data) < crc32.Size {
+			return nil, ErrInvalidData
+		}
+		decoded, err := snappy.Decode(nil, data[:len(data)-crc32.Size])
+		if err != nil {
+			return nil, err
+		}
+		if crc32.ChecksumIEEE(decoded) != binary.BigEndian.Uint32(data[len(data)-crc32.Size:]) {
+			return nil, fmt.Errorf("snappy checksum mismatch: %w", ErrInvalidData)
+		}
+		return decoded, nil
+	case CodecZstandard:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(data, nil)
+	default:
+		return data, nil
+	}
+}
+
+// byteReader is the input of the container reader, bufio.Reader implements it
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// readBlocks calls readItem for each item of the blocks of a map, used by the file header metadata
+func readBlocks(r byteReader, readItem func() error) error {
+	for {
+		count, err := readLong(r)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// a negative count is followed by the size of the block in bytes
+			count = -count
+			if _, err := readLong(r); err != nil {
+				return err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			if err := readItem(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readLong(r io.ByteReader) (int64, error) {
+	v, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return v, nil
+}
+
+func readBytes(r byteReader) ([]byte, error) {
+	n, err := readLong(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxBlockLength {
+		return nil, fmt.Errorf("length %d: %w", n, ErrInvalidData)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package avro
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+
+	hamba "github.com/hamba/avro/v2"
+)
+
+// normalize converts a value decoded by hamba/avro to the values returned by the reader: int and long as int64,
+// bytes and fixed as strings, union values unwrapped from their {type name: value} map, and logical types as their
+// underlying value - decimals as their decimal string.
+func normalize(s hamba.Schema, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch s := s.(type) {
+	case *hamba.RefSchema:
+		return normalize(s.Schema(), v)
+	case *hamba.RecordSchema:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for _, f := range s.Fields() {
+			m[f.Name()] = normalize(f.Type(), m[f.Name()])
+		}
+		return m
+	case *hamba.ArraySchema:
+		items, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		for i := range items {
+			items[i] = normalize(s.Items(), items[i])
+		}
+		return items
+	case *hamba.MapSchema:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for k := range values {
+			values[k] = normalize(s.Values(), values[k])
+		}
+		return values
+	case *hamba.UnionSchema:
+		return normalizeUnion(s, v)
+	case *hamba.FixedSchema:
+		if s.Logical() != nil && s.Logical().Type() == hamba.Decimal {
+			return decimalString(s.Logical(), v)
+		}
+		return fixedString(v)
+	case *hamba.PrimitiveSchema:
+		return normalizePrimitive(s, v)
+	default:
+		return v
+	}
+}
+
+// normalizeUnion unwraps a union value. hamba/avro wraps the value in a map keyed by its type name unless all the
+// union types resolve to Go types, in which case the plain value is matched to the first type it normalizes as.
+func normalizeUnion(s *hamba.UnionSchema, v interface{}) interface{} {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 1 {
+		for name, value := range m {
+			for _, t := range s.Types() {
+				if unionTypeName(t) == name {
+					return normalize(t, value)
+				}
+			}
+		}
+	}
+	for _, t := range s.Types() {
+		if p, ok := t.(*hamba.PrimitiveSchema); ok && p.Type() != hamba.Null && matchesPrimitive(p, v) {
+			return normalizePrimitive(p, v)
+		}
+	}
+	return v
+}
+
+// unionTypeName is the key hamba/avro uses for a union value: the full name of named types, or the type name
+// followed by the logical type
+func unionTypeName(s hamba.Schema) string {
+	if ref, ok := s.(*hamba.RefSchema); ok {
+		s = ref.Schema()
+	}
+	if n, ok := s.(hamba.NamedSchema); ok {
+		return n.FullName()
+	}
+	name := string(s.Type())
+	if l, ok := s.(hamba.LogicalTypeSchema); ok && l.Logical() != nil {
+		name += "." + string(l.Logical().Type())
+	}
+	return name
+}
+
+func matchesPrimitive(s *hamba.PrimitiveSchema, v interface{}) bool {
+	switch v.(type) {
+	case bool:
+		return s.Type() == hamba.Boolean
+	case int:
+		return s.Type() == hamba.Int
+	case int64:
+		return s.Type() == hamba.Long
+	case float32:
+		return s.Type() == hamba.Float
+	case float64:
+		return s.Type() == hamba.Double
+	case string:
+		return s.Type() == hamba.String
+	case []byte, *big.Rat:
+		return s.Type() == hamba.Bytes
+	case time.Time:
+		return s.Type() == hamba.Int || s.Type() == hamba.Long
+	case time.Duration:
+		return s.Type() == hamba.Int || s.Type() == hamba.Long
+	default:
+		return false
+	}
+}
+
+func normalizePrimitive(s *hamba.PrimitiveSchema, v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case []byte:
+		return string(v)
+	case *big.Rat:
+		return decimalString(s.Logical(), v)
+	case time.Time:
+		if s.Logical() == nil {
+			return v
+		}
+		switch s.Logical().Type() {
+		case hamba.Date:
+			return v.Unix() / int64(24*time.Hour/time.Second)
+		case hamba.TimestampMillis:
+			return v.UnixMilli()
+		case hamba.TimestampMicros:
+			return v.UnixMicro()
+		}
+	case time.Duration:
+		if s.Logical() != nil && s.Logical().Type() == hamba.TimeMillis {
+			return v.Milliseconds()
+		}
+		return v.Microseconds()
+	}
+	return v
+}
+
+func decimalString(l hamba.LogicalSchema, v interface{}) interface{} {
+	r, ok := v.(*big.Rat)
+	if !ok {
+		return v
+	}
+	scale := 0
+	if d, ok := l.(*hamba.DecimalLogicalSchema); ok {
+		scale = d.Scale()
+	}
+	return r.FloatString(scale)
+}
+
+// fixedString converts a fixed value, decoded as a byte array, to a string
+func fixedString(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Array || rv.Type().Elem().Kind() != reflect.Uint8 {
+		return v
+	}
+	b := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(b), rv)
+	return string(b)
+}
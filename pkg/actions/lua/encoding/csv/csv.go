@@ -0,0 +1,86 @@
+package csv
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+func Open(l *lua.State) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, library)
+		return 1
+	}
+	lua.Require(l, "encoding/csv", open, false)
+	l.Pop(1)
+}
+
+var library = []lua.RegistryFunction{
+	{Name: "read", Function: read},
+}
+
+func check(l *lua.State, err error) {
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+}
+
+// read returns the records of a csv source. Records are maps of the header fields to values, or arrays of values
+// when the source has no header.
+func read(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	opts := util.CheckReadOptions(l, 2)
+	delimiter := util.OptStringField(l, 2, "delimiter", ",")
+	withHeader := util.OptBooleanField(l, 2, "header", true)
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) {
+		lua.ArgumentError(l, 2, "delimiter must be a single character")
+	}
+
+	r := csv.NewReader(bufio.NewReader(util.NewSectionReader(src)))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	var header []string
+	if withHeader {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return util.DeepPush(l, []interface{}{})
+		}
+		check(l, err)
+		header = record
+	}
+
+	records := make([]interface{}, 0)
+	for n := int64(0); opts.Limit < 0 || n < opts.Offset+opts.Limit; n++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		check(l, err)
+		if n < opts.Offset {
+			continue
+		}
+		if header == nil {
+			records = append(records, record)
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, field := range record {
+			if i >= len(header) {
+				line, _ := r.FieldPos(i)
+				check(l, fmt.Errorf("line %d has more fields than the header: %w", line, csv.ErrFieldCount))
+			}
+			row[header[i]] = field
+		}
+		records = append(records, row)
+	}
+	return util.DeepPush(l, records)
+}
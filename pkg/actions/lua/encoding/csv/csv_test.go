@@ -0,0 +1,88 @@
+package csv_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		script   string
+		expected string
+	}{
+		{
+			name:    "header",
+			content: "name,population\nTel Aviv,460613\n\"Haifa, Carmel\",285316\nEilat,52299\n",
+			script: `for _, r in ipairs(csv.read(content)) do
+	print(r.name .. "|" .. r.population)
+end`,
+			expected: "Tel Aviv|460613\nHaifa, Carmel|285316\nEilat|52299\n",
+		},
+		{
+			name:    "offset and limit",
+			content: "name,population\nTel Aviv,460613\nHaifa,285316\nEilat,52299\n",
+			script: `for _, r in ipairs(csv.read(content, {offset = 1, limit = 1})) do
+	print(r.name)
+end`,
+			expected: "Haifa\n",
+		},
+		{
+			name:    "no header",
+			content: "a;1\nb;2\n",
+			script: `for _, r in ipairs(csv.read(content, {header = false, delimiter = ";"})) do
+	print(r[1] .. "=" .. r[2])
+end`,
+			expected: "a=1\nb=2\n",
+		},
+		{
+			name:     "default limit",
+			content:  "n\n" + strings.Repeat("1\n", util.DefaultReadLimit+1),
+			script:   `print(#csv.read(content) .. " " .. #csv.read(content, {offset = 10000}))`,
+			expected: "10000 1\n",
+		},
+		{
+			name:     "empty",
+			content:  "",
+			script:   `print(#csv.read(content))`,
+			expected: "0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			l := lua.NewState()
+			lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+			l.PushString(tt.content)
+			l.SetGlobal("content")
+			if err := lua.DoString(l, `csv = require("encoding/csv")`+"\n"+tt.script); err != nil {
+				t.Fatal(err)
+			}
+			if printed := out.String(); printed != tt.expected {
+				t.Fatalf("got %q, expected %q", printed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &bytes.Buffer{})
+	for _, script := range []string{
+		`csv.read("a,b\n1,2,3\n")`,
+		`csv.read("a,b\n", {delimiter = ";;"})`,
+		`csv.read(42)`,
+		`csv.read("a,b\n", {limit = -1})`,
+	} {
+		if err := lua.DoString(l, `csv = require("encoding/csv")`+"\n"+script); err == nil {
+			t.Errorf("expected error running %s", script)
+		}
+	}
+}
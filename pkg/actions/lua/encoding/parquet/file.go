@@ -0,0 +1,65 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+var ErrReadOnly = errors.New("parquet source is read only")
+
+// readerFile is a parquet source reading a util.ReaderAtSize. Each column is read by its own cursor, opened on the
+// same reader, so a parquet file is read by the ranges of its footer and selected column chunks.
+type readerFile struct {
+	r      util.ReaderAtSize
+	offset int64
+}
+
+func newReaderFile(r util.ReaderAtSize) *readerFile {
+	return &readerFile{r: r}
+}
+
+func (f *readerFile) Read(p []byte) (int, error) {
+	n, err := f.r.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *readerFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.r.Size()
+	default:
+		return 0, fmt.Errorf("seek whence %d: %w", whence, io.ErrUnexpectedEOF)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek to negative offset %d: %w", offset, io.ErrUnexpectedEOF)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *readerFile) Write([]byte) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (f *readerFile) Close() error {
+	return nil
+}
+
+func (f *readerFile) Open(string) (source.ParquetFile, error) {
+	return newReaderFile(f.r), nil
+}
+
+func (f *readerFile) Create(string) (source.ParquetFile, error) {
+	return nil, ErrReadOnly
+}
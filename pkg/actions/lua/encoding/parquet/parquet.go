@@ -3,7 +3,6 @@ package parquet
 import (
 	"github.com/Shopify/go-lua"
 	"github.com/treeverse/lakefs/pkg/actions/lua/util"
-	"github.com/xitongsys/parquet-go-source/buffer"
 	"github.com/xitongsys/parquet-go/reader"
 )
 
@@ -18,6 +17,7 @@ func Open(l *lua.State) {
 
 var parquetLibrary = []lua.RegistryFunction{
 	{Name: "get_schema", Function: getSchema},
+	{Name: "read_rows", Function: readRows},
 }
 
 func check(l *lua.State, err error) {
@@ -28,13 +28,8 @@ func check(l *lua.State, err error) {
 }
 
 func getSchema(l *lua.State) int {
-	payload := lua.CheckString(l, 1)
-	bin := []byte(payload)
-	t := struct{}{}
-	fp, err := buffer.NewBufferFile(bin)
-	check(l, err)
-	r, err := reader.NewParquetReader(fp, &t, 1)
-	check(l, err)
+	r := &reader.ParquetReader{PFile: newReaderFile(util.CheckReader(l, 1))}
+	check(l, r.ReadFooter())
 	output := make([]map[string]string, 0)
 	for i, elem := range r.Footer.GetSchema() {
 		if i == 0 {
@@ -48,3 +43,15 @@ func getSchema(l *lua.State) int {
 	}
 	return util.DeepPush(l, output)
 }
+
+func readRows(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	readOpts := util.CheckReadOptions(l, 2)
+	rows, err := ReadRows(src, ReadRowsOptions{
+		Columns: util.OptStringsField(l, 2, "columns"),
+		Offset:  readOpts.Offset,
+		Limit:   readOpts.Limit,
+	})
+	check(l, err)
+	return util.DeepPush(l, util.ExactNumbers(rows))
+}
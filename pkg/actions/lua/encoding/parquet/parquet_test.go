@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/Shopify/go-lua"
@@ -60,3 +61,45 @@ func TestOpen(t *testing.T) {
 		t.Fatalf("got unexpected schema: %s", printed)
 	}
 }
+
+const parquetReadRows = `
+parquet = require("encoding/parquet")
+rows = parquet.read_rows(parquet_content, {columns = {"name", "population"}, offset = 2, limit = 3})
+for _, row in ipairs(rows) do
+	print(row.name .. "\t" .. row.population .. "\t" .. tostring(row.timezone))
+end
+print(#parquet.read_rows(parquet_content))
+print(#parquet.read_rows(parquet_content, {offset = 280}))
+`
+
+const expectedRows = `Kailua	38635	nil
+Kaneohe	34597	nil
+Mākaha	8278	nil
+284
+4
+`
+
+func TestReadRows(t *testing.T) {
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+
+	parquetBytes, err := os.ReadFile("testdata/000.snappy.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.PushString(string(parquetBytes))
+	l.SetGlobal("parquet_content")
+
+	if err := lua.DoString(l, parquetReadRows); err != nil {
+		t.Fatal(err)
+	}
+	if printed := out.String(); printed != expectedRows {
+		t.Fatalf("got unexpected rows: %s", printed)
+	}
+
+	err = lua.DoString(l, `parquet.read_rows(parquet_content, {columns = {"no_such_column"}})`)
+	if err == nil || !strings.Contains(err.Error(), parquet.ErrUnknownColumn.Error()) {
+		t.Fatalf("expected unknown column error, got: %v", err)
+	}
+}
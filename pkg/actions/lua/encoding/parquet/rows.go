@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// readRowsBatchSize is the number of rows decoded at once, bounding the memory used by the column pages of a batch
+const readRowsBatchSize = 1000
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+type ReadRowsOptions struct {
+	// Columns are the top-level columns to read, all columns are read when empty
+	Columns []string
+	Offset  int64
+	// Limit is the maximal number of rows to read, all rows are read when negative
+	Limit int64
+}
+
+// ReadRows reads the rows of a parquet file as maps of column name to value. Only the column chunks of the selected
+// columns are read.
+func ReadRows(r util.ReaderAtSize, opts ReadRowsOptions) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0)
+	err := EachRow(r, opts, func(row map[string]interface{}) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// EachRow calls fn with the rows of a parquet file, decoding a batch of rows at a time, so the memory used doesn't
+// depend on the number of rows. Reading stops at the first error returned by fn.
+func EachRow(r util.ReaderAtSize, opts ReadRowsOptions, fn func(row map[string]interface{}) error) error {
+	pf := newReaderFile(r)
+	footerReader := &reader.ParquetReader{PFile: pf}
+	if err := footerReader.ReadFooter(); err != nil {
+		return fmt.Errorf("read parquet footer: %w", err)
+	}
+	schemaList, err := projectSchema(footerReader.Footer.GetSchema(), opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	pr, err := reader.NewParquetReader(pf, schemaList, 1)
+	if err != nil {
+		return fmt.Errorf("open parquet reader: %w", err)
+	}
+	defer pr.ReadStop()
+
+	numRows := pr.GetNumRows()
+	if opts.Offset >= numRows {
+		return nil
+	}
+	remaining := numRows - opts.Offset
+	if opts.Limit >= 0 && opts.Limit < remaining {
+		remaining = opts.Limit
+	}
+	if err := pr.SkipRows(opts.Offset); err != nil {
+		return fmt.Errorf("skip parquet rows: %w", err)
+	}
+
+	names := make(map[string]string, len(pr.SchemaHandler.Infos))
+	for _, info := range pr.SchemaHandler.Infos {
+		names[info.InName] = info.ExName
+	}
+	for remaining > 0 {
+		batch := remaining
+		if batch > readRowsBatchSize {
+			batch = readRowsBatchSize
+		}
+		values, err := pr.ReadByNumber(int(batch))
+		if err != nil {
+			return fmt.Errorf("read parquet rows: %w", err)
+		}
+		if len(values) == 0 {
+			break
+		}
+		for _, v := range values {
+			row, _ := toValue(reflect.ValueOf(v), names).(map[string]interface{})
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		remaining -= int64(len(values))
+	}
+	return nil
+}
+
+// projectSchema returns the schema list of the selected top-level columns
+func projectSchema(schema []*parquet.SchemaElement, columns []string) ([]*parquet.SchemaElement, error) {
+	if len(columns) == 0 || len(schema) == 0 {
+		return schema, nil
+	}
+	selected := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		selected[c] = false
+	}
+
+	root := *schema[0]
+	projected := []*parquet.SchemaElement{&root}
+	numChildren := int32(0)
+	pos := 1
+	for pos < len(schema) {
+		end := subtreeEnd(schema, pos)
+		if _, ok := selected[schema[pos].GetName()]; ok {
+			selected[schema[pos].GetName()] = true
+			projected = append(projected, schema[pos:end]...)
+			numChildren++
+		}
+		pos = end
+	}
+	for _, c := range columns {
+		if !selected[c] {
+			return nil, fmt.Errorf("column %s: %w", c, ErrUnknownColumn)
+		}
+	}
+	root.NumChildren = &numChildren
+	return projected, nil
+}
+
+// subtreeEnd returns the position after the schema element at pos and all its descendants
+func subtreeEnd(schema []*parquet.SchemaElement, pos int) int {
+	pending := 1
+	for pending > 0 && pos < len(schema) {
+		pending += int(schema[pos].GetNumChildren()) - 1
+		pos++
+	}
+	return pos
+}
+
+// toValue converts a row decoded by the parquet reader to maps, slices and primitive values. Struct fields are
+// named by the parquet reader after the column names, the names map returns the column name of a field.
+func toValue(v reflect.Value, names map[string]string) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toValue(v.Elem(), names)
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := names[t.Field(i).Name]
+			if !ok {
+				name = t.Field(i).Name
+			}
+			m[name] = toValue(v.Field(i), names)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = toValue(v.Index(i), names)
+		}
+		return s
+	case reflect.Map:
+		m := make(map[interface{}]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[toValue(iter.Key(), names)] = toValue(iter.Value(), names)
+		}
+		return m
+	default:
+		return v.Interface()
+	}
+}
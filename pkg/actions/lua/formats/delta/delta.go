@@ -0,0 +1,212 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/parquet"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+const (
+	// maxActionLineSize is the maximal size of a line of a commit file, each line is a single action
+	maxActionLineSize = 64 * 1024 * 1024
+
+	actionAdd      = "add"
+	actionRemove   = "remove"
+	actionMetadata = "metaData"
+	actionProtocol = "protocol"
+)
+
+var parquetMagic = []byte("PAR1")
+
+func Open(l *lua.State) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, library)
+		return 1
+	}
+	lua.Require(l, "formats/delta", open, false)
+	l.Pop(1)
+}
+
+var library = []lua.RegistryFunction{
+	{Name: "read_log", Function: readLog},
+	{Name: "table_state", Function: tableState},
+}
+
+func check(l *lua.State, err error) {
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+}
+
+// ReadActions reads the actions of a _delta_log file: a JSON commit file, or a parquet checkpoint file. Skips the
+// first offset actions and reads up to limit actions.
+func ReadActions(src util.ReaderAtSize, opts util.ReadOptions) ([]map[string]interface{}, error) {
+	actions := make([]map[string]interface{}, 0)
+	var n int64
+	err := EachAction(src, func(action map[string]interface{}) error {
+		n++
+		if n <= opts.Offset {
+			return nil
+		}
+		if opts.Limit >= 0 && int64(len(actions)) >= opts.Limit {
+			return errStopReading
+		}
+		actions = append(actions, action)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopReading) {
+		return nil, err
+	}
+	return actions, nil
+}
+
+var errStopReading = errors.New("stop reading")
+
+// EachAction calls fn with the actions of a _delta_log file one at a time, so the memory used doesn't depend on the
+// size of the log file. Reading stops at the first error returned by fn.
+func EachAction(src util.ReaderAtSize, fn func(action map[string]interface{}) error) error {
+	header := make([]byte, len(parquetMagic))
+	if n, _ := src.ReadAt(header, 0); n == len(header) && bytes.Equal(header, parquetMagic) {
+		return eachCheckpointAction(src, fn)
+	}
+
+	scanner := bufio.NewScanner(util.NewSectionReader(src))
+	scanner.Buffer(nil, maxActionLineSize)
+	n := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var action map[string]interface{}
+		if err := dec.Decode(&action); err != nil {
+			return fmt.Errorf("delta commit action %d: %w", n, err)
+		}
+		if err := fn(action); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read delta commit: %w", err)
+	}
+	return nil
+}
+
+// eachCheckpointAction calls fn with the actions of a checkpoint. Each row of a checkpoint holds a single action, in
+// the column of its action type.
+func eachCheckpointAction(src util.ReaderAtSize, fn func(action map[string]interface{}) error) error {
+	var fnErr error
+	err := parquet.EachRow(src, parquet.ReadRowsOptions{Limit: -1}, func(row map[string]interface{}) error {
+		action := make(map[string]interface{}, 1)
+		for k, v := range row {
+			if v != nil {
+				action[k] = v
+			}
+		}
+		if len(action) == 0 {
+			return nil
+		}
+		fnErr = fn(action)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return fmt.Errorf("read delta checkpoint: %w", err)
+	}
+	return err
+}
+
+// TableState is the state of a table after replaying the actions of its log
+type TableState struct {
+	Protocol interface{}
+	Metadata interface{}
+	// Files are the add actions of the files of the table, by path
+	Files map[string]interface{}
+}
+
+// Apply updates the state by an action of a log file, log files are applied in version order
+func (s *TableState) Apply(action map[string]interface{}) error {
+	if v, ok := action[actionProtocol]; ok {
+		s.Protocol = v
+	}
+	if v, ok := action[actionMetadata]; ok {
+		s.Metadata = v
+	}
+	if v, ok := action[actionAdd]; ok {
+		path, err := actionPath(v)
+		if err != nil {
+			return err
+		}
+		s.Files[path] = v
+	}
+	if v, ok := action[actionRemove]; ok {
+		path, err := actionPath(v)
+		if err != nil {
+			return err
+		}
+		delete(s.Files, path)
+	}
+	return nil
+}
+
+var errMissingPath = errors.New("delta action without a path")
+
+func actionPath(v interface{}) (string, error) {
+	m, _ := v.(map[string]interface{})
+	path, ok := m["path"].(string)
+	if !ok {
+		return "", errMissingPath
+	}
+	return path, nil
+}
+
+func readLog(l *lua.State) int {
+	actions, err := ReadActions(util.CheckReader(l, 1), util.CheckReadOptions(l, 2))
+	check(l, err)
+	return util.DeepPush(l, util.ExactNumbers(actions))
+}
+
+// tableState replays the log files of an array, ordered by version and starting at a checkpoint or at the first
+// commit, and returns the protocol, the metadata and the files of the table
+func tableState(l *lua.State) int {
+	lua.CheckType(l, 1, lua.TypeTable)
+	state := &TableState{Files: make(map[string]interface{})}
+	n := l.RawLength(1)
+	for i := 1; i <= n; i++ {
+		l.RawGetInt(1, i)
+		src, ok := util.ToReader(l, -1)
+		l.Pop(1)
+		if !ok {
+			lua.ArgumentError(l, 1, "array of strings or objects expected")
+		}
+		// the actions are applied as they are read, only the table state is kept in memory
+		if err := EachAction(src, state.Apply); err != nil {
+			check(l, fmt.Errorf("log file %d: %w", i, err))
+		}
+	}
+
+	paths := make([]string, 0, len(state.Files))
+	for path := range state.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	files := make([]interface{}, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, state.Files[path])
+	}
+	return util.DeepPush(l, util.ExactNumbers(map[string]interface{}{
+		"protocol": state.Protocol,
+		"metadata": state.Metadata,
+		"files":    files,
+	}))
+}
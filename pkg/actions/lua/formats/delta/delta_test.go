@@ -0,0 +1,121 @@
+package delta_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type checkpointProtocol struct {
+	MinReaderVersion int32 `parquet:"name=minReaderVersion, type=INT32"`
+	MinWriterVersion int32 `parquet:"name=minWriterVersion, type=INT32"`
+}
+
+type checkpointMetadata struct {
+	ID           string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SchemaString string `parquet:"name=schemaString, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type checkpointAdd struct {
+	Path             string            `parquet:"name=path, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PartitionValues  map[string]string `parquet:"name=partitionValues, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Size             int64             `parquet:"name=size, type=INT64"`
+	ModificationTime int64             `parquet:"name=modificationTime, type=INT64"`
+	DataChange       bool              `parquet:"name=dataChange, type=BOOLEAN"`
+}
+
+type checkpointRow struct {
+	Protocol *checkpointProtocol `parquet:"name=protocol"`
+	MetaData *checkpointMetadata `parquet:"name=metaData"`
+	Add      *checkpointAdd      `parquet:"name=add"`
+}
+
+func writeCheckpoint(t *testing.T, rows []*checkpointRow) []byte {
+	t.Helper()
+	f, err := buffer.NewBufferFile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := writer.NewParquetWriter(f, new(checkpointRow), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+	return f.(buffer.BufferFile).Bytes()
+}
+
+const commit11 = `{"commitInfo":{"timestamp":1690000000000,"operation":"WRITE"}}
+{"remove":{"path":"date=2023-07-01/part-0.parquet","deletionTimestamp":1690000000000,"dataChange":true}}
+{"add":{"path":"date=2023-07-02/part-0.parquet","partitionValues":{"date":"2023-07-02"},"size":300,"modificationTime":1690000000000,"dataChange":true}}
+`
+
+const commit12 = `{"metaData":{"id":"table-1","schemaString":"{\"type\":\"struct\",\"fields\":[]}","partitionColumns":["date"]}}
+{"add":{"path":"date=2023-07-03/part-0.parquet","partitionValues":{"date":"2023-07-03"},"size":400,"modificationTime":1690000001000,"dataChange":true}}
+`
+
+func TestOpen(t *testing.T) {
+	checkpoint := writeCheckpoint(t, []*checkpointRow{
+		{Protocol: &checkpointProtocol{MinReaderVersion: 1, MinWriterVersion: 2}},
+		{MetaData: &checkpointMetadata{ID: "table-1", SchemaString: `{"type":"struct","fields":[]}`}},
+		{Add: &checkpointAdd{Path: "date=2023-07-01/part-0.parquet", PartitionValues: map[string]string{"date": "2023-07-01"}, Size: 100}},
+		{Add: &checkpointAdd{Path: "date=2023-07-01/part-1.parquet", PartitionValues: map[string]string{"date": "2023-07-01"}, Size: 200}},
+	})
+
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+	for name, content := range map[string]string{
+		"checkpoint": string(checkpoint),
+		"commit11":   commit11,
+		"commit12":   commit12,
+	} {
+		l.PushString(content)
+		l.SetGlobal(name)
+	}
+
+	err := lua.DoString(l, `
+delta = require("formats/delta")
+actions = delta.read_log(checkpoint)
+print(#actions .. " " .. actions[1].protocol.minWriterVersion .. " " .. actions[3].add.partitionValues.date)
+actions = delta.read_log(commit11)
+print(#actions .. " " .. actions[1].commitInfo.operation .. " " .. actions[2].remove.path)
+actions = delta.read_log(checkpoint, {offset = 2, limit = 1})
+print(#actions .. " " .. actions[1].add.path)
+
+state = delta.table_state({checkpoint, commit11, commit12})
+print(state.protocol.minReaderVersion .. " " .. state.metadata.id .. " " .. state.metadata.partitionColumns[1])
+for _, f in ipairs(state.files) do
+	print(f.path .. " " .. f.size)
+end
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `4 2 2023-07-01
+3 WRITE date=2023-07-01/part-0.parquet
+1 date=2023-07-01/part-0.parquet
+1 table-1 date
+date=2023-07-01/part-1.parquet 200
+date=2023-07-02/part-0.parquet 300
+date=2023-07-03/part-0.parquet 400
+`
+	if printed := out.String(); printed != expected {
+		t.Fatalf("got unexpected output:\n%s", printed)
+	}
+
+	if err := lua.DoString(l, `delta.table_state({checkpoint, 42})`); err == nil {
+		t.Fatal("expected error replaying an invalid log file")
+	}
+}
@@ -0,0 +1,81 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+func Open(l *lua.State) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, library)
+		return 1
+	}
+	lua.Require(l, "formats/iceberg", open, false)
+	l.Pop(1)
+}
+
+var library = []lua.RegistryFunction{
+	{Name: "read_metadata", Function: readMetadata},
+	{Name: "read_manifest_list", Function: readManifestList},
+	{Name: "read_manifest", Function: readManifest},
+}
+
+func check(l *lua.State, err error) {
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+}
+
+// readMetadata returns the table metadata of a metadata JSON file, with the current snapshot resolved under
+// "current-snapshot"
+func readMetadata(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	dec := json.NewDecoder(util.NewSectionReader(src))
+	dec.UseNumber()
+	var metadata map[string]interface{}
+	check(l, dec.Decode(&metadata))
+
+	if currentID, ok := metadata["current-snapshot-id"].(json.Number); ok {
+		snapshots, _ := metadata["snapshots"].([]interface{})
+		for _, s := range snapshots {
+			snapshot, _ := s.(map[string]interface{})
+			if id, ok := snapshot["snapshot-id"].(json.Number); ok && id == currentID {
+				metadata["current-snapshot"] = snapshot
+				break
+			}
+		}
+	}
+	return util.DeepPush(l, util.ExactNumbers(metadata))
+}
+
+// readManifestList returns the manifest files of a snapshot manifest list
+func readManifestList(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	manifests, err := avro.ReadRecords(src, util.CheckReadOptions(l, 2))
+	check(l, err)
+	return util.DeepPush(l, util.ExactNumbers(manifests))
+}
+
+// readManifest returns the entries of a manifest file, and the manifest metadata: the table schema, the partition
+// spec, the format version and the manifest content type
+func readManifest(l *lua.State) int {
+	src := util.CheckReader(l, 1)
+	r, err := avro.NewReader(util.NewSectionReader(src))
+	check(l, err)
+	metadata := make(map[string]string)
+	for k, v := range r.Metadata() {
+		if !strings.HasPrefix(k, "avro.") {
+			metadata[k] = v
+		}
+	}
+	entries, err := avro.ReadRecords(src, util.CheckReadOptions(l, 2))
+	check(l, err)
+	util.DeepPush(l, util.ExactNumbers(entries))
+	util.DeepPush(l, metadata)
+	return 2
+}
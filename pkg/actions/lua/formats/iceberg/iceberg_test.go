@@ -0,0 +1,117 @@
+package iceberg_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro/avrotest"
+)
+
+const tableMetadata = `{
+	"format-version": 2,
+	"table-uuid": "9c12d441-03fe-4693-9a96-a0705ddf69c1",
+	"location": "s3://bucket/db/events",
+	"current-snapshot-id": 3055729675574597004,
+	"snapshots": [
+		{"snapshot-id": 3051729675574597004, "timestamp-ms": 1515100955770, "manifest-list": "s3://bucket/db/events/metadata/snap-1.avro"},
+		{"snapshot-id": 3055729675574597004, "parent-snapshot-id": 3051729675574597004, "timestamp-ms": 1555100955770, "manifest-list": "s3://bucket/db/events/metadata/snap-2.avro"}
+	]
+}`
+
+const manifestListSchema = `{
+	"type": "record",
+	"name": "manifest_file",
+	"fields": [
+		{"name": "manifest_path", "type": "string"},
+		{"name": "manifest_length", "type": "long"},
+		{"name": "added_snapshot_id", "type": "long"},
+		{"name": "added_files_count", "type": ["null", "int"]}
+	]
+}`
+
+const manifestSchema = `{
+	"type": "record",
+	"name": "manifest_entry",
+	"fields": [
+		{"name": "status", "type": "int"},
+		{"name": "snapshot_id", "type": ["null", "long"]},
+		{"name": "data_file", "type": {
+			"type": "record",
+			"name": "r2",
+			"fields": [
+				{"name": "file_path", "type": "string"},
+				{"name": "file_format", "type": "string"},
+				{"name": "record_count", "type": "long"}
+			]
+		}}
+	]
+}`
+
+func TestOpen(t *testing.T) {
+	manifestList := avrotest.WriteOCF(t, manifestListSchema, avro.CodecDeflate, nil, 10, []interface{}{
+		map[string]interface{}{
+			"manifest_path":     "s3://bucket/db/events/metadata/m1.avro",
+			"manifest_length":   int64(6000),
+			"added_snapshot_id": int64(3055729675574597004),
+			"added_files_count": int64(2),
+		},
+	})
+	manifest := avrotest.WriteOCF(t, manifestSchema, avro.CodecNull, map[string]string{
+		"format-version": "2",
+		"content":        "data",
+	}, 10, []interface{}{
+		map[string]interface{}{
+			"status":      int64(1),
+			"snapshot_id": int64(3055729675574597004),
+			"data_file":   map[string]interface{}{"file_path": "s3://bucket/db/events/data/a.parquet", "file_format": "PARQUET", "record_count": int64(100)},
+		},
+		map[string]interface{}{
+			"status":      int64(2),
+			"snapshot_id": nil,
+			"data_file":   map[string]interface{}{"file_path": "s3://bucket/db/events/data/b.parquet", "file_format": "PARQUET", "record_count": int64(5)},
+		},
+	})
+
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+	for name, content := range map[string]string{
+		"metadata_content":      tableMetadata,
+		"manifest_list_content": string(manifestList),
+		"manifest_content":      string(manifest),
+	} {
+		l.PushString(content)
+		l.SetGlobal(name)
+	}
+
+	err := lua.DoString(l, `
+iceberg = require("formats/iceberg")
+metadata = iceberg.read_metadata(metadata_content)
+snapshot = metadata["current-snapshot"]
+print(metadata["format-version"] .. " " .. snapshot["snapshot-id"] .. " " .. snapshot["manifest-list"])
+for _, m in ipairs(iceberg.read_manifest_list(manifest_list_content)) do
+	print(m.manifest_path .. " " .. m.added_snapshot_id .. " " .. m.added_files_count)
+end
+entries, manifest_metadata = iceberg.read_manifest(manifest_content)
+print(manifest_metadata["format-version"] .. " " .. manifest_metadata.content)
+for _, e in ipairs(entries) do
+	print(e.status .. " " .. e.data_file.file_path .. " " .. e.data_file.record_count)
+end
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `2 3055729675574597004 s3://bucket/db/events/metadata/snap-2.avro
+s3://bucket/db/events/metadata/m1.avro 3055729675574597004 2
+2 data
+1 s3://bucket/db/events/data/a.parquet 100
+2 s3://bucket/db/events/data/b.parquet 5
+`
+	if printed := out.String(); printed != expected {
+		t.Fatalf("got unexpected output:\n%s", printed)
+	}
+}
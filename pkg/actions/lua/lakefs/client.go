@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/Shopify/go-lua"
@@ -19,6 +21,13 @@ import (
 	"github.com/treeverse/lakefs/pkg/version"
 )
 
+const (
+	// commitOptionsIndex is the stack index of the optional metadata table of commit
+	commitOptionsIndex = 4
+	// mergeOptionsIndex is the stack index of the optional options table of merge
+	mergeOptionsIndex = 4
+)
+
 // LuaClientUserAgent is the default user agent that will be sent to the lakeFS server instance
 var LuaClientUserAgent = "lakefs-lua/" + version.Version
 
@@ -37,17 +46,13 @@ func newLakeFSRequest(ctx context.Context, user *model.User, method, url string,
 			url = fmt.Sprintf("/api/v1/%s", url)
 		}
 	}
-	var body io.Reader
-	if data == nil {
-		body = bytes.NewReader(data)
-	}
 
 	// Chi stores its routing information on the request context which breaks this sub-request's routing.
 	// We explicitly nullify any existing routing information before creating the new request
 	ctx = context.WithValue(ctx, chi.RouteCtxKey, nil)
 	// Add user to the request context
 	ctx = auth.WithUser(ctx, user)
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +72,16 @@ func newLakeFSJSONRequest(ctx context.Context, user *model.User, method, url str
 func getLakeFSJSONResponse(l *lua.State, server *http.Server, request *http.Request) int {
 	rr := httptest.NewRecorder()
 	server.Handler.ServeHTTP(rr, request)
+	return pushLakeFSJSONResponse(l, rr)
+}
+
+func pushLakeFSJSONResponse(l *lua.State, rr *httptest.ResponseRecorder) int {
 	l.PushInteger(rr.Code)
+	if rr.Body.Len() == 0 {
+		// no content, e.g. a successful delete
+		l.PushNil()
+		return 2
+	}
 
 	var output interface{}
 	check(l, json.Unmarshal(rr.Body.Bytes(), &output))
@@ -170,6 +184,9 @@ func OpenClient(l *lua.State, ctx context.Context, user *model.User, server *htt
 				l.PushString(rr.Body.String())
 				return 2
 			}},
+			{Name: "open_object", Function: func(state *lua.State) int {
+				return openObject(l, ctx, user, server)
+			}},
 			{Name: "diff_branch", Function: func(state *lua.State) int {
 				repo := lua.CheckString(l, 1)
 				branch := lua.CheckString(l, 2)
@@ -195,6 +212,192 @@ func OpenClient(l *lua.State, ctx context.Context, user *model.User, server *htt
 				req.URL.RawQuery = q.Encode()
 				return getLakeFSJSONResponse(l, server, req)
 			}},
+			{Name: "stat_object", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				ref := lua.CheckString(l, 2)
+				reqURL := fmt.Sprintf("/repositories/%s/refs/%s/objects/stat", url.PathEscape(repo), url.PathEscape(ref))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				// query params
+				q := req.URL.Query()
+				q.Add("path", lua.CheckString(l, 3))
+				if !l.IsNone(4) {
+					withUserMetadata := "false"
+					if l.ToBoolean(4) {
+						withUserMetadata = "true"
+					}
+					q.Add("user_metadata", withUserMetadata)
+				}
+				req.URL.RawQuery = q.Encode()
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "upload_object", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				branch := lua.CheckString(l, 2)
+				objPath := lua.CheckString(l, 3)
+				content := lua.CheckString(l, 4)
+
+				var body bytes.Buffer
+				mw := multipart.NewWriter(&body)
+				w, err := mw.CreateFormFile("content", path.Base(objPath))
+				if err != nil {
+					check(l, err)
+				}
+				if _, err := io.WriteString(w, content); err != nil {
+					check(l, err)
+				}
+				if err := mw.Close(); err != nil {
+					check(l, err)
+				}
+
+				reqURL := fmt.Sprintf("/repositories/%s/branches/%s/objects", url.PathEscape(repo), url.PathEscape(branch))
+				req, err := newLakeFSRequest(ctx, user, http.MethodPost, reqURL, body.Bytes())
+				if err != nil {
+					check(l, err)
+				}
+				req.Header.Set("Content-Type", mw.FormDataContentType())
+				q := req.URL.Query()
+				q.Add("path", objPath)
+				req.URL.RawQuery = q.Encode()
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "log_commits", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				ref := lua.CheckString(l, 2)
+				reqURL := fmt.Sprintf("/repositories/%s/refs/%s/commits", url.PathEscape(repo), url.PathEscape(ref))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				// query params
+				q := req.URL.Query()
+				if !l.IsNone(3) {
+					q.Add("after", lua.CheckString(l, 3))
+				}
+				if !l.IsNone(4) {
+					q.Add("amount", fmt.Sprintf("%d", lua.CheckInteger(l, 4)))
+				}
+				if !l.IsNone(5) {
+					firstParent := "false"
+					if l.ToBoolean(5) {
+						firstParent = "true"
+					}
+					q.Add("first_parent", firstParent)
+				}
+				req.URL.RawQuery = q.Encode()
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "get_commit", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				commitID := lua.CheckString(l, 2)
+				reqURL := fmt.Sprintf("/repositories/%s/commits/%s", url.PathEscape(repo), url.PathEscape(commitID))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "create_branch", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				data, err := json.Marshal(map[string]string{
+					"name":   lua.CheckString(l, 2),
+					"source": lua.CheckString(l, 3),
+				})
+				if err != nil {
+					check(l, err)
+				}
+				reqURL := fmt.Sprintf("/repositories/%s/branches", url.PathEscape(repo))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodPost, reqURL, data)
+				if err != nil {
+					check(l, err)
+				}
+				rr := httptest.NewRecorder()
+				server.Handler.ServeHTTP(rr, req)
+				// the response body of a created branch is its commit ID, as plain text
+				l.PushInteger(rr.Code)
+				l.PushString(rr.Body.String())
+				return 2
+			}},
+			{Name: "delete_branch", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				branch := lua.CheckString(l, 2)
+				reqURL := fmt.Sprintf("/repositories/%s/branches/%s", url.PathEscape(repo), url.PathEscape(branch))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodDelete, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "commit", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				branch := lua.CheckString(l, 2)
+				commit := map[string]interface{}{
+					"message": lua.CheckString(l, 3),
+				}
+				if !l.IsNone(commitOptionsIndex) {
+					lua.CheckType(l, commitOptionsIndex, lua.TypeTable)
+					metadata, err := util.PullStringTable(l, commitOptionsIndex)
+					if err != nil {
+						check(l, err)
+					}
+					commit["metadata"] = metadata
+				}
+				data, err := json.Marshal(commit)
+				if err != nil {
+					check(l, err)
+				}
+				reqURL := fmt.Sprintf("/repositories/%s/branches/%s/commits", url.PathEscape(repo), url.PathEscape(branch))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodPost, reqURL, data)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "merge", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				sourceRef := lua.CheckString(l, 2)
+				destinationBranch := lua.CheckString(l, 3)
+				// options: message, metadata and strategy of the merge
+				var options interface{} = map[string]interface{}{}
+				if !l.IsNone(mergeOptionsIndex) {
+					lua.CheckType(l, mergeOptionsIndex, lua.TypeTable)
+					var err error
+					options, err = util.PullTable(l, mergeOptionsIndex)
+					if err != nil {
+						check(l, err)
+					}
+				}
+				data, err := json.Marshal(options)
+				if err != nil {
+					check(l, err)
+				}
+				reqURL := fmt.Sprintf("/repositories/%s/refs/%s/merge/%s", url.PathEscape(repo), url.PathEscape(sourceRef), url.PathEscape(destinationBranch))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodPost, reqURL, data)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "get_repository", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				reqURL := fmt.Sprintf("/repositories/%s", url.PathEscape(repo))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
+			{Name: "get_repository_metadata", Function: func(state *lua.State) int {
+				repo := lua.CheckString(l, 1)
+				reqURL := fmt.Sprintf("/repositories/%s/metadata", url.PathEscape(repo))
+				req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+				if err != nil {
+					check(l, err)
+				}
+				return getLakeFSJSONResponse(l, server, req)
+			}},
 		})
 		return 1
 	}
@@ -0,0 +1,161 @@
+package lakefs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/auth/model"
+)
+
+const (
+	objectReaderMetaTable = "lakefs.object"
+	// objectReaderBlockSize is the minimal range read from the server, small sequential reads are served from the last block
+	objectReaderBlockSize = 1024 * 1024
+)
+
+// ObjectReader reads ranges of a lakeFS object, it is passed to the format libraries as a util.ReaderAtSize
+type ObjectReader struct {
+	ctx        context.Context
+	user       *model.User
+	server     *http.Server
+	repository string
+	ref        string
+	path       string
+	size       int64
+
+	mu          sync.Mutex
+	blockOffset int64
+	block       []byte
+}
+
+func (o *ObjectReader) Size() int64 {
+	return o.size
+}
+
+func (o *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("read object %s: negative offset", o.path)
+	}
+	if off >= o.size {
+		return 0, io.EOF
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for n < len(p) && off < o.size {
+		if off < o.blockOffset || off >= o.blockOffset+int64(len(o.block)) {
+			if err := o.readBlock(off, int64(len(p)-n)); err != nil {
+				return n, err
+			}
+		}
+		copied := copy(p[n:], o.block[off-o.blockOffset:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readBlock reads the range of at least objectReaderBlockSize bytes starting at off
+func (o *ObjectReader) readBlock(off, length int64) error {
+	if length < objectReaderBlockSize {
+		length = objectReaderBlockSize
+	}
+	end := off + length - 1
+	if end >= o.size {
+		end = o.size - 1
+	}
+	reqURL := fmt.Sprintf("/repositories/%s/refs/%s/objects", url.PathEscape(o.repository), url.PathEscape(o.ref))
+	req, err := newLakeFSRequest(o.ctx, o.user, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("path", o.path)
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, end))
+
+	rr := httptest.NewRecorder()
+	o.server.Handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusPartialContent && rr.Code != http.StatusOK {
+		return fmt.Errorf("read object %s range %d-%d: status %d: %s", o.path, off, end, rr.Code, rr.Body.String())
+	}
+	o.block = rr.Body.Bytes()
+	o.blockOffset = off
+	if rr.Code == http.StatusOK {
+		// the server ignored the range and returned the whole object
+		o.blockOffset = 0
+	}
+	if off >= o.blockOffset+int64(len(o.block)) {
+		return fmt.Errorf("read object %s range %d-%d: %w", o.path, off, end, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+func openObjectReaderMetaTable(l *lua.State) {
+	if !lua.NewMetaTable(l, objectReaderMetaTable) {
+		l.Pop(1)
+		return
+	}
+	l.NewTable()
+	lua.SetFunctions(l, []lua.RegistryFunction{
+		{Name: "size", Function: func(l *lua.State) int {
+			o := lua.CheckUserData(l, 1, objectReaderMetaTable).(*ObjectReader)
+			l.PushInteger(int(o.size))
+			return 1
+		}},
+	}, 0)
+	l.SetField(-2, "__index")
+	l.Pop(1)
+}
+
+// openObject pushes the status code of the object stat, and a reader of the object or the error response
+func openObject(l *lua.State, ctx context.Context, user *model.User, server *http.Server) int {
+	repo := lua.CheckString(l, 1)
+	ref := lua.CheckString(l, 2)
+	objPath := lua.CheckString(l, 3)
+	reqURL := fmt.Sprintf("/repositories/%s/refs/%s/objects/stat", url.PathEscape(repo), url.PathEscape(ref))
+	req, err := newLakeFSJSONRequest(ctx, user, http.MethodGet, reqURL, nil)
+	check(l, err)
+	q := req.URL.Query()
+	q.Add("path", objPath)
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	server.Handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		return pushLakeFSJSONResponse(l, rr)
+	}
+	var stat struct {
+		SizeBytes *int64 `json:"size_bytes"`
+	}
+	check(l, json.Unmarshal(rr.Body.Bytes(), &stat))
+	if stat.SizeBytes == nil {
+		lua.Errorf(l, "open object %s: missing object size", objPath)
+		panic("unreachable")
+	}
+
+	l.PushInteger(rr.Code)
+	l.PushUserData(&ObjectReader{
+		ctx:        ctx,
+		user:       user,
+		server:     server,
+		repository: repo,
+		ref:        ref,
+		path:       objPath,
+		size:       *stat.SizeBytes,
+	})
+	openObjectReaderMetaTable(l)
+	lua.SetMetaTableNamed(l, objectReaderMetaTable)
+	return 2
+}
@@ -0,0 +1,206 @@
+package lakefs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+var (
+	ErrStorageNotConfigured = errors.New("lakefs storage is not configured")
+	ErrStorageAccessDenied  = errors.New("insufficient permissions")
+	ErrStorageInvalidKey    = errors.New("invalid key")
+	ErrStorageInvalidPrefix = errors.New("invalid storage prefix")
+	ErrStorageReadOnly      = errors.New("lakefs storage is read-only")
+)
+
+// Storage gives hooks access to a prefix of the storage namespaces of repositories through the block adapter. Access
+// to a key is authorized by the hook storage actions on the hook storage resource of the key, and not as access to
+// repository objects, which are not stored under the prefix.
+type Storage struct {
+	Adapter    block.Adapter
+	Authorizer auth.Authorizer
+	// Prefix is the prefix of the storage namespace that hooks access, keys are relative to it. lakeFS keeps none of
+	// the data it manages under it, so hooks cannot change the data of committed or staged objects.
+	Prefix string
+	// ReadOnly fails put_object and delete_object
+	ReadOnly bool
+}
+
+// ValidateStoragePrefix checks that prefix is a path in the storage namespace that is not under any of the prefixes
+// lakeFS manages
+func ValidateStoragePrefix(prefix string, managedPrefixes ...string) error {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" || !isCleanKey(prefix) {
+		return fmt.Errorf("'%s': %w", prefix, ErrStorageInvalidPrefix)
+	}
+	for _, managed := range managedPrefixes {
+		managed = strings.Trim(managed, "/")
+		if prefix == managed || strings.HasPrefix(prefix, managed+"/") || strings.HasPrefix(managed, prefix+"/") {
+			return fmt.Errorf("'%s' overlaps '%s' managed by lakeFS: %w", prefix, managed, ErrStorageInvalidPrefix)
+		}
+	}
+	return nil
+}
+
+// isCleanKey returns true if key has no empty, '.' or '..' path elements
+func isCleanKey(key string) bool {
+	for _, elem := range strings.Split(key, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+type storageClient struct {
+	ctx     context.Context
+	user    *model.User
+	server  *http.Server
+	storage *Storage
+}
+
+// OpenStorage registers the lakefs/storage module. Storage may be nil, then the module functions fail.
+func OpenStorage(l *lua.State, ctx context.Context, user *model.User, server *http.Server, storage *Storage) {
+	c := &storageClient{ctx: ctx, user: user, server: server, storage: storage}
+	storageOpen := func(l *lua.State) int {
+		lua.NewLibrary(l, []lua.RegistryFunction{
+			{Name: "get_object", Function: c.getObject},
+			{Name: "put_object", Function: c.putObject},
+			{Name: "delete_object", Function: c.deleteObject},
+			{Name: "get_properties", Function: c.getProperties},
+		})
+		return 1
+	}
+	lua.Require(l, "lakefs/storage", storageOpen, false)
+	l.Pop(1)
+}
+
+// objectPointer authorizes the action on key of the repository at index 1 of the stack, and returns the pointer of
+// the key under the storage prefix of the repository's storage namespace
+func (c *storageClient) objectPointer(l *lua.State, action string) block.ObjectPointer {
+	if c.storage == nil || c.storage.Prefix == "" {
+		check(l, ErrStorageNotConfigured)
+	}
+	repo := lua.CheckString(l, 1)
+	key := lua.CheckString(l, 2)
+	key = strings.TrimPrefix(key, "/")
+	if !isCleanKey(key) {
+		check(l, fmt.Errorf("'%s': %w", key, ErrStorageInvalidKey))
+	}
+
+	resp, err := c.storage.Authorizer.Authorize(c.ctx, &auth.AuthorizationRequest{
+		Username: c.user.Username,
+		RequiredPermissions: permissions.Node{
+			Permission: permissions.Permission{
+				Action:   action,
+				Resource: permissions.HookStorageArn(repo, key),
+			},
+		},
+	})
+	check(l, err)
+	if resp.Error != nil {
+		check(l, resp.Error)
+	}
+	if !resp.Allowed {
+		check(l, fmt.Errorf("%s %s/%s: %w", action, repo, key, ErrStorageAccessDenied))
+	}
+
+	return block.ObjectPointer{
+		StorageNamespace: c.storageNamespace(l, repo),
+		Identifier:       strings.Trim(c.storage.Prefix, "/") + "/" + key,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+}
+
+// storageNamespace returns the storage namespace of the repository, read by the lakeFS API as the hook user
+func (c *storageClient) storageNamespace(l *lua.State, repo string) string {
+	reqURL := fmt.Sprintf("/repositories/%s", url.PathEscape(repo))
+	req, err := newLakeFSJSONRequest(c.ctx, c.user, http.MethodGet, reqURL, nil)
+	check(l, err)
+	rr := httptest.NewRecorder()
+	c.server.Handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		check(l, fmt.Errorf("get repository %s: status %d: %s", repo, rr.Code, rr.Body.String()))
+	}
+	var repository struct {
+		StorageNamespace string `json:"storage_namespace"`
+	}
+	check(l, json.Unmarshal(rr.Body.Bytes(), &repository))
+	return repository.StorageNamespace
+}
+
+func (c *storageClient) checkWritable(l *lua.State) {
+	if c.storage != nil && c.storage.ReadOnly {
+		check(l, ErrStorageReadOnly)
+	}
+}
+
+func (c *storageClient) getObject(l *lua.State) int {
+	obj := c.objectPointer(l, permissions.ReadHookStorageAction)
+	r, err := c.storage.Adapter.Get(c.ctx, obj, -1)
+	if errors.Is(err, block.ErrDataNotFound) {
+		l.PushString("")
+		l.PushBoolean(false) // exists
+		return 2
+	}
+	check(l, err)
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	check(l, err)
+	l.PushString(string(data))
+	l.PushBoolean(true) // exists
+	return 2
+}
+
+func (c *storageClient) putObject(l *lua.State) int {
+	c.checkWritable(l)
+	obj := c.objectPointer(l, permissions.WriteHookStorageAction)
+	data := lua.CheckString(l, 3)
+	check(l, c.storage.Adapter.Put(c.ctx, obj, int64(len(data)), strings.NewReader(data), block.PutOpts{}))
+	return 0
+}
+
+func (c *storageClient) deleteObject(l *lua.State) int {
+	c.checkWritable(l)
+	obj := c.objectPointer(l, permissions.WriteHookStorageAction)
+	err := c.storage.Adapter.Remove(c.ctx, obj)
+	if err != nil && !errors.Is(err, block.ErrDataNotFound) {
+		check(l, err)
+	}
+	return 0
+}
+
+// getProperties returns whether the key exists, and its storage class
+func (c *storageClient) getProperties(l *lua.State) int {
+	obj := c.objectPointer(l, permissions.ReadHookStorageAction)
+	exists, err := c.storage.Adapter.Exists(c.ctx, obj)
+	check(l, err)
+	if !exists {
+		l.PushNil()
+		l.PushBoolean(false) // exists
+		return 2
+	}
+	props, err := c.storage.Adapter.GetProperties(c.ctx, obj)
+	check(l, err)
+	properties := map[string]interface{}{}
+	if props.StorageClass != nil {
+		properties["storage_class"] = *props.StorageClass
+	}
+	util.DeepPush(l, properties)
+	l.PushBoolean(true) // exists
+	return 2
+}
@@ -7,14 +7,20 @@ import (
 	"github.com/treeverse/lakefs/pkg/actions/lua/crypto/aes"
 	"github.com/treeverse/lakefs/pkg/actions/lua/crypto/hmac"
 	"github.com/treeverse/lakefs/pkg/actions/lua/crypto/sha256"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/avro"
 	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/base64"
+	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/csv"
 	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/hex"
 	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/json"
 	"github.com/treeverse/lakefs/pkg/actions/lua/encoding/parquet"
+	"github.com/treeverse/lakefs/pkg/actions/lua/formats/delta"
+	"github.com/treeverse/lakefs/pkg/actions/lua/formats/iceberg"
 	"github.com/treeverse/lakefs/pkg/actions/lua/net/http"
 	"github.com/treeverse/lakefs/pkg/actions/lua/path"
 	"github.com/treeverse/lakefs/pkg/actions/lua/regexp"
 	"github.com/treeverse/lakefs/pkg/actions/lua/storage/aws"
+	"github.com/treeverse/lakefs/pkg/actions/lua/storage/azure"
+	"github.com/treeverse/lakefs/pkg/actions/lua/storage/gcloud"
 	"github.com/treeverse/lakefs/pkg/actions/lua/strings"
 	"github.com/treeverse/lakefs/pkg/actions/lua/time"
 	"github.com/treeverse/lakefs/pkg/actions/lua/uuid"
@@ -35,8 +41,14 @@ func Open(l *lua.State, ctx context.Context, cfg OpenSafeConfig) {
 	sha256.Open(l)
 	aes.Open(l)
 	parquet.Open(l)
+	csv.Open(l)
+	avro.Open(l)
+	delta.Open(l)
+	iceberg.Open(l)
 	path.Open(l)
 	aws.Open(l, ctx)
+	gcloud.Open(l, ctx)
+	azure.Open(l, ctx)
 	if cfg.NetHTTPEnabled {
 		http.Open(l)
 	}
@@ -0,0 +1,223 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+)
+
+func Open(l *lua.State, ctx context.Context) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, []lua.RegistryFunction{
+			{Name: "blob_client", Function: newBlobClient(ctx)},
+		})
+		return 1
+	}
+	lua.Require(l, "azure", open, false)
+	l.Pop(1)
+}
+
+func newBlobClient(ctx context.Context) lua.Function {
+	return func(l *lua.State) int {
+		storageAccount := lua.CheckString(l, 1)
+		accessKey := lua.CheckString(l, 2)
+		var endpoint string
+		if !l.IsNone(3) {
+			endpoint = lua.CheckString(l, 3)
+		}
+		c := &BlobClient{
+			StorageAccount: storageAccount,
+			AccessKey:      accessKey,
+			Endpoint:       endpoint,
+			ctx:            ctx,
+		}
+
+		l.NewTable()
+		for name, goFn := range functions {
+			// -1: tbl
+			l.PushGoFunction(goFn(c))
+			// -1: fn, -2:tbl
+			l.SetField(-2, name)
+		}
+
+		return 1
+	}
+}
+
+type BlobClient struct {
+	StorageAccount string
+	AccessKey      string
+	// Endpoint is the blob service URL, defaults to the storage account's Azure endpoint
+	Endpoint string
+	ctx      context.Context
+}
+
+func (c *BlobClient) client(l *lua.State) *azblob.Client {
+	serviceURL := c.Endpoint
+	if serviceURL == "" {
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", c.StorageAccount)
+	}
+	cred, err := azblob.NewSharedKeyCredential(c.StorageAccount, c.AccessKey)
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+	client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+	return client
+}
+
+var functions = map[string]func(client *BlobClient) lua.Function{
+	"get_object":       getObject,
+	"put_object":       putObject,
+	"list_objects":     listObjects,
+	"delete_object":    deleteObject,
+	"delete_recursive": deleteRecursive,
+}
+
+func getObject(c *BlobClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		containerName := lua.CheckString(l, 1)
+		key := lua.CheckString(l, 2)
+		resp, err := client.DownloadStream(c.ctx, containerName, key, nil)
+		if err != nil {
+			if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+				l.PushString("")
+				l.PushBoolean(false) // exists
+				return 2
+			}
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		l.PushString(string(data))
+		l.PushBoolean(true) // exists
+		return 2
+	}
+}
+
+func putObject(c *BlobClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		_, err := client.UploadBuffer(c.ctx, lua.CheckString(l, 1), lua.CheckString(l, 2), []byte(lua.CheckString(l, 3)), nil)
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		return 0
+	}
+}
+
+func deleteObject(c *BlobClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		_, err := client.DeleteBlob(c.ctx, lua.CheckString(l, 1), lua.CheckString(l, 2), nil)
+		if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		return 0
+	}
+}
+
+func deleteRecursive(c *BlobClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		containerName := lua.CheckString(l, 1)
+		prefix := lua.CheckString(l, 2)
+		pager := client.NewListBlobsFlatPager(containerName, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+		for pager.More() {
+			resp, err := pager.NextPage(c.ctx)
+			if err != nil {
+				lua.Errorf(l, err.Error())
+				panic("unreachable")
+			}
+			for _, item := range resp.Segment.BlobItems {
+				_, err := client.DeleteBlob(c.ctx, containerName, *item.Name, nil)
+				if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+					lua.Errorf(l, err.Error())
+					panic("unreachable")
+				}
+			}
+		}
+		return 0
+	}
+}
+
+func listObjects(c *BlobClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		containerName := lua.CheckString(l, 1)
+
+		opts := &container.ListBlobsHierarchyOptions{}
+		if !l.IsNone(2) {
+			prefix := lua.CheckString(l, 2)
+			opts.Prefix = &prefix
+		}
+		if !l.IsNone(3) {
+			marker := lua.CheckString(l, 3)
+			opts.Marker = &marker
+		}
+		delimiter := "/"
+		if !l.IsNone(4) {
+			delimiter = lua.CheckString(l, 4)
+		}
+
+		pager := client.ServiceClient().NewContainerClient(containerName).NewListBlobsHierarchyPager(delimiter, opts)
+		resp, err := pager.NextPage(c.ctx)
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		results := make([]map[string]interface{}, 0)
+		for _, prefix := range resp.Segment.BlobPrefixes {
+			results = append(results, map[string]interface{}{
+				"key":  *prefix.Name,
+				"type": "prefix",
+			})
+		}
+		for _, item := range resp.Segment.BlobItems {
+			results = append(results, map[string]interface{}{
+				"key":           *item.Name,
+				"type":          "object",
+				"etag":          string(*item.Properties.ETag),
+				"size":          *item.Properties.ContentLength,
+				"last_modified": item.Properties.LastModified.Format(time.RFC3339),
+			})
+		}
+
+		// sort it
+		sort.Slice(results, func(i, j int) bool {
+			return results[i]["key"].(string) > results[j]["key"].(string)
+		})
+
+		var nextMarker string
+		if resp.NextMarker != nil {
+			nextMarker = *resp.NextMarker
+		}
+		response := map[string]interface{}{
+			"is_truncated":            nextMarker != "",
+			"next_continuation_token": nextMarker,
+			"results":                 results,
+		}
+
+		return util.DeepPush(l, response)
+	}
+}
@@ -0,0 +1,229 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+)
+
+const lastModified = "Mon, 02 Jan 2023 03:04:05 GMT"
+
+// fakeBlobService serves the parts of the Azure Blob REST API used by the azure module
+type fakeBlobService struct {
+	mu    sync.Mutex
+	blobs map[string][]byte // container/name -> data
+}
+
+type blobProperties struct {
+	LastModified  string `xml:"Last-Modified"`
+	Etag          string `xml:"Etag"`
+	ContentLength int    `xml:"Content-Length"`
+	BlobType      string `xml:"BlobType"`
+}
+
+type blobItem struct {
+	XMLName    xml.Name       `xml:"Blob"`
+	Name       string         `xml:"Name"`
+	Properties blobProperties `xml:"Properties"`
+}
+
+type blobPrefix struct {
+	XMLName xml.Name `xml:"BlobPrefix"`
+	Name    string   `xml:"Name"`
+}
+
+type enumerationResults struct {
+	XMLName       xml.Name      `xml:"EnumerationResults"`
+	ContainerName string        `xml:"ContainerName,attr"`
+	Prefix        string        `xml:"Prefix"`
+	Delimiter     string        `xml:"Delimiter,omitempty"`
+	Blobs         []interface{} `xml:"Blobs>Blob_or_prefix"`
+	NextMarker    string        `xml:"NextMarker"`
+}
+
+func (f *fakeBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey account:") {
+		writeError(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+	containerName, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	key := containerName + "/" + name
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("comp") == "list":
+		f.list(w, r, containerName)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			writeError(w, http.StatusBadRequest, "InvalidHeaderValue")
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidInput")
+			return
+		}
+		f.blobs[key] = data
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet:
+		data, ok := f.blobs[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("x-ms-blob-type", "BlockBlob")
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[key]; !ok {
+			writeError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		delete(f.blobs, key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeBlobService) list(w http.ResponseWriter, r *http.Request, containerName string) {
+	prefix := r.URL.Query().Get("prefix")
+	delimiter := r.URL.Query().Get("delimiter")
+	var names []string
+	for key := range f.blobs {
+		if name, ok := strings.CutPrefix(key, containerName+"/"); ok && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	res := enumerationResults{ContainerName: containerName, Prefix: prefix, Delimiter: delimiter}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if delimiter != "" {
+			if i := strings.Index(name[len(prefix):], delimiter); i >= 0 {
+				p := name[:len(prefix)+i+len(delimiter)]
+				if !seen[p] {
+					seen[p] = true
+					res.Blobs = append(res.Blobs, blobPrefix{Name: p})
+				}
+				continue
+			}
+		}
+		res.Blobs = append(res.Blobs, blobItem{
+			Name: name,
+			Properties: blobProperties{
+				LastModified:  lastModified,
+				Etag:          "0x1",
+				ContentLength: len(f.blobs[containerName+"/"+name]),
+				BlobType:      "BlockBlob",
+			},
+		})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(res)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("x-ms-error-code", code)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message></Error>", xml.Header, code, code)
+}
+
+func runBlobScript(t *testing.T, endpoint, script string) (string, error) {
+	t.Helper()
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+	l.PushString(base64.StdEncoding.EncodeToString([]byte("access-key")))
+	l.SetGlobal("access_key")
+	l.PushString(endpoint)
+	l.SetGlobal("endpoint")
+	err := lua.DoString(l, `local azure = require("azure")
+client = azure.blob_client("account", access_key, endpoint)
+`+script)
+	return out.String(), err
+}
+
+func TestBlobClient(t *testing.T) {
+	fake := &fakeBlobService{blobs: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	printed, err := runBlobScript(t, server.URL+"/", `
+client.put_object("container", "exports/a.csv", "a,b\n1,2\n")
+client.put_object("container", "exports/nested/b.csv", "b")
+client.put_object("container", "other", "c")
+
+local data, exists = client.get_object("container", "exports/a.csv")
+print(tostring(exists) .. " " .. data)
+data, exists = client.get_object("container", "missing")
+print(tostring(exists) .. " '" .. data .. "'")
+
+local res = client.list_objects("container", "exports/")
+print(tostring(res.is_truncated))
+for _, r in ipairs(res.results) do
+    if r.type == "object" then
+        print(r.type .. " " .. r.key .. " " .. r.size .. " " .. r.last_modified)
+    else
+        print(r.type .. " " .. r.key)
+    end
+end
+
+client.delete_object("container", "other")
+client.delete_object("container", "missing")
+client.delete_recursive("container", "exports/")
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `true a,b
+1,2
+
+false ''
+false
+prefix exports/nested/
+object exports/a.csv 8 2023-01-02T03:04:05Z
+`
+	if printed != expected {
+		t.Fatalf("got %q, expected %q", printed, expected)
+	}
+	if len(fake.blobs) != 0 {
+		t.Fatalf("blobs left after delete: %v", fake.blobs)
+	}
+}
+
+func TestBlobClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusForbidden, "AuthenticationFailed")
+	}))
+	defer server.Close()
+
+	for _, script := range []string{
+		`client.get_object("container", "key")`,
+		`client.put_object("container", "key", "data")`,
+		`client.delete_object("container", "key")`,
+		`client.list_objects("container")`,
+	} {
+		if _, err := runBlobScript(t, server.URL+"/", script); err == nil {
+			t.Errorf("expected error running %s", script)
+		}
+	}
+}
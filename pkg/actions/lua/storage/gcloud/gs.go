@@ -0,0 +1,218 @@
+package gcloud
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Shopify/go-lua"
+	"github.com/treeverse/lakefs/pkg/actions/lua/util"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// listObjectsPageSize is the maximal number of results of a single list_objects call
+const listObjectsPageSize = 1000
+
+func Open(l *lua.State, ctx context.Context) {
+	open := func(l *lua.State) int {
+		lua.NewLibrary(l, []lua.RegistryFunction{
+			{Name: "gs_client", Function: newGSClient(ctx)},
+		})
+		return 1
+	}
+	lua.Require(l, "gcloud", open, false)
+	l.Pop(1)
+}
+
+func newGSClient(ctx context.Context) lua.Function {
+	return func(l *lua.State) int {
+		credentialsJSON := lua.CheckString(l, 1)
+		var endpoint string
+		if !l.IsNone(2) {
+			endpoint = lua.CheckString(l, 2)
+		}
+		c := &GSClient{
+			CredentialsJSON: credentialsJSON,
+			Endpoint:        endpoint,
+			ctx:             ctx,
+		}
+
+		l.NewTable()
+		for name, goFn := range functions {
+			// -1: tbl
+			l.PushGoFunction(goFn(c))
+			// -1: fn, -2:tbl
+			l.SetField(-2, name)
+		}
+
+		return 1
+	}
+}
+
+type GSClient struct {
+	// CredentialsJSON is the content of a service account key file
+	CredentialsJSON string
+	Endpoint        string
+	ctx             context.Context
+}
+
+func (c *GSClient) client(l *lua.State) *storage.Client {
+	opts := []option.ClientOption{option.WithCredentialsJSON([]byte(c.CredentialsJSON))}
+	if c.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(c.Endpoint))
+	}
+	client, err := storage.NewClient(c.ctx, opts...)
+	if err != nil {
+		lua.Errorf(l, err.Error())
+		panic("unreachable")
+	}
+	return client
+}
+
+var functions = map[string]func(client *GSClient) lua.Function{
+	"get_object":       getObject,
+	"put_object":       putObject,
+	"list_objects":     listObjects,
+	"delete_object":    deleteObject,
+	"delete_recursive": deleteRecursive,
+}
+
+func getObject(c *GSClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		defer func() { _ = client.Close() }()
+		r, err := client.Bucket(lua.CheckString(l, 1)).Object(lua.CheckString(l, 2)).NewReader(c.ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+				l.PushString("")
+				l.PushBoolean(false) // exists
+				return 2
+			}
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		defer func() { _ = r.Close() }()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		l.PushString(string(data))
+		l.PushBoolean(true) // exists
+		return 2
+	}
+}
+
+func putObject(c *GSClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		defer func() { _ = client.Close() }()
+		w := client.Bucket(lua.CheckString(l, 1)).Object(lua.CheckString(l, 2)).NewWriter(c.ctx)
+		_, err := io.WriteString(w, lua.CheckString(l, 3))
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		return 0
+	}
+}
+
+func deleteObject(c *GSClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		defer func() { _ = client.Close() }()
+		err := client.Bucket(lua.CheckString(l, 1)).Object(lua.CheckString(l, 2)).Delete(c.ctx)
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		return 0
+	}
+}
+
+func deleteRecursive(c *GSClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		defer func() { _ = client.Close() }()
+		bucket := client.Bucket(lua.CheckString(l, 1))
+		it := bucket.Objects(c.ctx, &storage.Query{Prefix: lua.CheckString(l, 2)})
+		for {
+			attrs, err := it.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err == nil {
+				err = bucket.Object(attrs.Name).Delete(c.ctx)
+			}
+			if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+				lua.Errorf(l, err.Error())
+				panic("unreachable")
+			}
+		}
+		return 0
+	}
+}
+
+func listObjects(c *GSClient) lua.Function {
+	return func(l *lua.State) int {
+		client := c.client(l)
+		defer func() { _ = client.Close() }()
+
+		query := &storage.Query{Delimiter: "/"}
+		if !l.IsNone(2) {
+			query.Prefix = lua.CheckString(l, 2)
+		}
+		var continuationToken string
+		if !l.IsNone(3) {
+			continuationToken = lua.CheckString(l, 3)
+		}
+		if !l.IsNone(4) {
+			query.Delimiter = lua.CheckString(l, 4)
+		}
+
+		it := client.Bucket(lua.CheckString(l, 1)).Objects(c.ctx, query)
+		var page []*storage.ObjectAttrs
+		nextToken, err := iterator.NewPager(it, listObjectsPageSize, continuationToken).NextPage(&page)
+		if err != nil {
+			lua.Errorf(l, err.Error())
+			panic("unreachable")
+		}
+		results := make([]map[string]interface{}, 0, len(page))
+		for _, attrs := range page {
+			if attrs.Prefix != "" {
+				results = append(results, map[string]interface{}{
+					"key":  attrs.Prefix,
+					"type": "prefix",
+				})
+				continue
+			}
+			results = append(results, map[string]interface{}{
+				"key":           attrs.Name,
+				"type":          "object",
+				"etag":          attrs.Etag,
+				"size":          attrs.Size,
+				"last_modified": attrs.Updated.Format(time.RFC3339),
+			})
+		}
+
+		// sort it
+		sort.Slice(results, func(i, j int) bool {
+			return results[i]["key"].(string) > results[j]["key"].(string)
+		})
+
+		response := map[string]interface{}{
+			"is_truncated":            nextToken != "",
+			"next_continuation_token": nextToken,
+			"results":                 results,
+		}
+
+		return util.DeepPush(l, response)
+	}
+}
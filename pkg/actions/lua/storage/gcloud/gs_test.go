@@ -0,0 +1,247 @@
+package gcloud_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/go-lua"
+	lualibs "github.com/treeverse/lakefs/pkg/actions/lua"
+)
+
+// fakeGCS serves the parts of the GCS JSON and XML APIs used by the gcloud module, and a token endpoint for the
+// service account credentials
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string][]byte // bucket/name -> data
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.URL.Path == "/token":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+		return
+	case r.Header.Get("Authorization") != "Bearer token":
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/"):
+		f.upload(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/upload/storage/v1/b/"), "/o"))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/") && strings.HasSuffix(r.URL.Path, "/o"):
+		f.list(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o"))
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/storage/v1/b/"):
+		bucket, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o/")
+		if _, ok := f.objects[bucket+"/"+name]; !ok {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		delete(f.objects, bucket+"/"+name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet:
+		// XML API read: /bucket/name
+		data, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeGCS) upload(w http.ResponseWriter, r *http.Request, bucket string) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || r.URL.Query().Get("uploadType") != "multipart" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	var attrs struct {
+		Name string `json:"name"`
+	}
+	metadata, err := mr.NextPart()
+	if err == nil {
+		err = json.NewDecoder(metadata).Decode(&attrs)
+	}
+	var data []byte
+	if err == nil {
+		var media *multipart.Part
+		media, err = mr.NextPart()
+		if err == nil {
+			data, err = io.ReadAll(media)
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.objects[bucket+"/"+attrs.Name] = data
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(objectResource(bucket, attrs.Name, data))
+}
+
+func (f *fakeGCS) list(w http.ResponseWriter, r *http.Request, bucket string) {
+	prefix := r.URL.Query().Get("prefix")
+	delimiter := r.URL.Query().Get("delimiter")
+	var names []string
+	for key := range f.objects {
+		if name, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	items := make([]interface{}, 0)
+	prefixes := make([]string, 0)
+	seen := map[string]bool{}
+	for _, name := range names {
+		if delimiter != "" {
+			if i := strings.Index(name[len(prefix):], delimiter); i >= 0 {
+				p := name[:len(prefix)+i+len(delimiter)]
+				if !seen[p] {
+					seen[p] = true
+					prefixes = append(prefixes, p)
+				}
+				continue
+			}
+		}
+		items = append(items, objectResource(bucket, name, f.objects[bucket+"/"+name]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind":     "storage#objects",
+		"items":    items,
+		"prefixes": prefixes,
+	})
+}
+
+func objectResource(bucket, name string, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":    "storage#object",
+		"bucket":  bucket,
+		"name":    name,
+		"size":    fmt.Sprint(len(data)),
+		"etag":    "etag-" + name,
+		"updated": time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).Format(time.RFC3339),
+	}
+}
+
+// serviceAccountJSON returns service account credentials that get their tokens from tokenURL
+func serviceAccountJSON(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	creds, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "project",
+		"private_key_id": "key-id",
+		"private_key":    string(keyPEM),
+		"client_email":   "hooks@project.iam.gserviceaccount.com",
+		"client_id":      "1",
+		"token_uri":      tokenURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(creds)
+}
+
+func TestGSClient(t *testing.T) {
+	fake := &fakeGCS{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	out := bytes.Buffer{}
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &out)
+	l.PushString(serviceAccountJSON(t, server.URL+"/token"))
+	l.SetGlobal("credentials")
+	l.PushString(server.URL + "/storage/v1/")
+	l.SetGlobal("endpoint")
+	script := `
+local gcloud = require("gcloud")
+local client = gcloud.gs_client(credentials, endpoint)
+
+client.put_object("bucket", "exports/a.csv", "a,b\n1,2\n")
+client.put_object("bucket", "exports/nested/b.csv", "b")
+client.put_object("bucket", "other", "c")
+
+local data, exists = client.get_object("bucket", "exports/a.csv")
+print(tostring(exists) .. " " .. data)
+data, exists = client.get_object("bucket", "missing")
+print(tostring(exists) .. " '" .. data .. "'")
+
+local res = client.list_objects("bucket", "exports/")
+print(tostring(res.is_truncated))
+for _, r in ipairs(res.results) do
+    print(r.type .. " " .. r.key)
+end
+
+client.delete_object("bucket", "other")
+client.delete_object("bucket", "missing")
+client.delete_recursive("bucket", "exports/")
+res = client.list_objects("bucket", "", "", "")
+print(#res.results)
+`
+	if err := lua.DoString(l, script); err != nil {
+		t.Fatal(err)
+	}
+	expected := `true a,b
+1,2
+
+false ''
+false
+prefix exports/nested/
+object exports/a.csv
+0
+`
+	if printed := out.String(); printed != expected {
+		t.Fatalf("got %q, expected %q", printed, expected)
+	}
+	if len(fake.objects) != 0 {
+		t.Fatalf("objects left after delete: %v", fake.objects)
+	}
+}
+
+func TestGSClientUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	l := lua.NewState()
+	lualibs.OpenSafe(l, context.Background(), lualibs.OpenSafeConfig{}, &bytes.Buffer{})
+	l.PushString(serviceAccountJSON(t, server.URL+"/token"))
+	l.SetGlobal("credentials")
+	l.PushString(server.URL + "/storage/v1/")
+	l.SetGlobal("endpoint")
+	err := lua.DoString(l, `require("gcloud").gs_client(credentials, endpoint).put_object("bucket", "key", "data")`)
+	if err == nil {
+		t.Fatal("expected an error without a token")
+	}
+}
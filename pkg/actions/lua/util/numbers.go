@@ -0,0 +1,51 @@
+package util
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// maxExactInteger is the largest integer a lua number, a float64, represents exactly
+const maxExactInteger = 1 << 53
+
+// ExactNumbers returns v with the integers a lua number cannot represent exactly, e.g. snapshot ids, replaced by their
+// decimal string. JSON numbers decoded with json.Decoder.UseNumber are converted to float64 or to such a string.
+func ExactNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		if v > maxExactInteger || v < -maxExactInteger {
+			return strconv.FormatInt(v, 10)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return ExactNumbers(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = ExactNumbers(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			v[k] = ExactNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = ExactNumbers(item)
+		}
+		return v
+	case []map[string]interface{}:
+		for _, item := range v {
+			ExactNumbers(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
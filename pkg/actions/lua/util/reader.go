@@ -0,0 +1,149 @@
+package util
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Shopify/go-lua"
+)
+
+// ReaderAtSize is the source read by the format libraries, a lua string or an object opened by lakefs.open_object.
+// Readers are read by offset, so formats are decoded without loading whole objects into memory.
+type ReaderAtSize interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// CheckReader returns the source at index idx of the stack: a lua string, or a userdata implementing ReaderAtSize
+func CheckReader(l *lua.State, idx int) ReaderAtSize {
+	r, ok := ToReader(l, idx)
+	if !ok {
+		lua.ArgumentError(l, idx, "string or object expected")
+		panic("unreachable")
+	}
+	return r
+}
+
+// ToReader returns the source at index idx of the stack, if it is a lua string or a userdata implementing ReaderAtSize
+func ToReader(l *lua.State, idx int) (ReaderAtSize, bool) {
+	if l.TypeOf(idx) == lua.TypeString {
+		s, _ := l.ToString(idx)
+		return strings.NewReader(s), true
+	}
+	r, ok := l.ToUserData(idx).(ReaderAtSize)
+	return r, ok
+}
+
+// NewSectionReader returns a reader of the whole source, for formats decoded sequentially
+func NewSectionReader(r ReaderAtSize) *io.SectionReader {
+	return io.NewSectionReader(r, 0, r.Size())
+}
+
+// DefaultReadLimit is the number of rows returned to a script that doesn't set a limit, larger sources are read
+// page by page using the offset
+const DefaultReadLimit = 10000
+
+// ReadOptions are the options of the readers returning rows: skip the first offset rows and read up to limit rows
+type ReadOptions struct {
+	Offset int64
+	// Limit is negative when all rows are read, scripts always read a bounded number of rows
+	Limit int64
+}
+
+// CheckReadOptions returns the offset and limit fields of the optional options table at index idx. The limit
+// defaults to DefaultReadLimit.
+func CheckReadOptions(l *lua.State, idx int) ReadOptions {
+	opts := ReadOptions{
+		Offset: int64(OptIntegerField(l, idx, "offset", 0)),
+		Limit:  int64(OptIntegerField(l, idx, "limit", DefaultReadLimit)),
+	}
+	if opts.Offset < 0 {
+		lua.ArgumentError(l, idx, "offset must not be negative")
+	}
+	if opts.Limit < 0 {
+		lua.ArgumentError(l, idx, "limit must not be negative")
+	}
+	return opts
+}
+
+func checkOptionsTable(l *lua.State, idx int) bool {
+	if l.IsNoneOrNil(idx) {
+		return false
+	}
+	lua.CheckType(l, idx, lua.TypeTable)
+	return true
+}
+
+// OptIntegerField returns the integer field name of the optional options table at index idx, or def if missing
+func OptIntegerField(l *lua.State, idx int, name string, def int) int {
+	if !checkOptionsTable(l, idx) {
+		return def
+	}
+	l.Field(idx, name)
+	defer l.Pop(1)
+	if l.IsNil(-1) {
+		return def
+	}
+	v, ok := l.ToInteger(-1)
+	if !ok {
+		lua.ArgumentError(l, idx, name+" must be an integer")
+	}
+	return v
+}
+
+// OptStringField returns the string field name of the optional options table at index idx, or def if missing
+func OptStringField(l *lua.State, idx int, name string, def string) string {
+	if !checkOptionsTable(l, idx) {
+		return def
+	}
+	l.Field(idx, name)
+	defer l.Pop(1)
+	if l.IsNil(-1) {
+		return def
+	}
+	if l.TypeOf(-1) != lua.TypeString {
+		lua.ArgumentError(l, idx, name+" must be a string")
+	}
+	v, _ := l.ToString(-1)
+	return v
+}
+
+// OptBooleanField returns the boolean field name of the optional options table at index idx, or def if missing
+func OptBooleanField(l *lua.State, idx int, name string, def bool) bool {
+	if !checkOptionsTable(l, idx) {
+		return def
+	}
+	l.Field(idx, name)
+	defer l.Pop(1)
+	if l.IsNil(-1) {
+		return def
+	}
+	return l.ToBoolean(-1)
+}
+
+// OptStringsField returns the array of strings field name of the optional options table at index idx
+func OptStringsField(l *lua.State, idx int, name string) []string {
+	if !checkOptionsTable(l, idx) {
+		return nil
+	}
+	l.Field(idx, name)
+	defer l.Pop(1)
+	if l.IsNil(-1) {
+		return nil
+	}
+	if !l.IsTable(-1) {
+		lua.ArgumentError(l, idx, name+" must be an array of strings")
+	}
+	n := l.RawLength(-1)
+	values := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		l.RawGetInt(-1, i)
+		v, ok := l.ToString(-1)
+		l.Pop(1)
+		if !ok {
+			lua.ArgumentError(l, idx, name+" must be an array of strings")
+		}
+		values = append(values, v)
+	}
+	return values
+}
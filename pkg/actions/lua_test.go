@@ -3,6 +3,8 @@ package actions_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,11 +15,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-test/deep"
 	nanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/lua/lakefs"
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/mem"
 	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/permissions"
 	"github.com/treeverse/lakefs/pkg/testutil"
 )
 
@@ -41,6 +48,7 @@ func TestNewLuaHook(t *testing.T) {
 			Enabled: true,
 			Lua: struct {
 				NetHTTPEnabled bool
+				StoragePrefix  string
 			}{
 				NetHTTPEnabled: true,
 			},
@@ -71,6 +79,7 @@ func TestLuaRun(t *testing.T) {
 			Enabled: true,
 			Lua: struct {
 				NetHTTPEnabled bool
+				StoragePrefix  string
 			}{
 				NetHTTPEnabled: true,
 			},
@@ -273,6 +282,7 @@ print(code .. " " .. body .. " " .. status)
 					Enabled: true,
 					Lua: struct {
 						NetHTTPEnabled bool
+						StoragePrefix  string
 					}{
 						NetHTTPEnabled: true,
 					},
@@ -386,6 +396,7 @@ func TestLuaRunTable(t *testing.T) {
 					Enabled: true,
 					Lua: struct {
 						NetHTTPEnabled bool
+						StoragePrefix  string
 					}{
 						NetHTTPEnabled: true,
 					},
@@ -437,6 +448,276 @@ func TestLuaRunTable(t *testing.T) {
 	}
 }
 
+// newLakeFSEchoServer returns a server standing for the lakeFS API, which responds with the request information
+func newLakeFSEchoServer(t *testing.T) *http.Server {
+	t.Helper()
+	return &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			user, err := auth.GetUser(r.Context())
+			if err != nil {
+				t.Errorf("request without user: %s", err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body interface{}
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+				f, header, err := r.FormFile("content")
+				if err != nil {
+					t.Errorf("upload without content: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				content, _ := io.ReadAll(f)
+				body = map[string]string{"filename": header.Filename, "content": string(content)}
+			} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+				t.Errorf("request body: %s", err)
+			}
+			status := http.StatusOK
+			if r.Method == http.MethodPost && !strings.Contains(r.URL.Path, "/merge/") {
+				status = http.StatusCreated
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"method": r.Method,
+				"path":   r.URL.Path,
+				"query":  r.URL.Query().Encode(),
+				"user":   user.Username,
+				"body":   body,
+			})
+		}),
+		ReadHeaderTimeout: time.Minute,
+	}
+}
+
+func TestLuaRunLakeFSClient(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output string
+	}{
+		{Name: "get_object", Input: "testdata/lua/lakefs_get_object.lua", Output: "testdata/lua/lakefs_get_object.output"},
+		{Name: "stat_object", Input: "testdata/lua/lakefs_stat_object.lua", Output: "testdata/lua/lakefs_stat_object.output"},
+		{Name: "upload_object", Input: "testdata/lua/lakefs_upload_object.lua", Output: "testdata/lua/lakefs_upload_object.output"},
+		{Name: "log_commits", Input: "testdata/lua/lakefs_log_commits.lua", Output: "testdata/lua/lakefs_log_commits.output"},
+		{Name: "get_commit", Input: "testdata/lua/lakefs_get_commit.lua", Output: "testdata/lua/lakefs_get_commit.output"},
+		{Name: "create_branch", Input: "testdata/lua/lakefs_create_branch.lua", Output: "testdata/lua/lakefs_create_branch.output"},
+		{Name: "delete_branch", Input: "testdata/lua/lakefs_delete_branch.lua", Output: "testdata/lua/lakefs_delete_branch.output"},
+		{Name: "commit", Input: "testdata/lua/lakefs_commit.lua", Output: "testdata/lua/lakefs_commit.output"},
+		{Name: "merge", Input: "testdata/lua/lakefs_merge.lua", Output: "testdata/lua/lakefs_merge.output"},
+		{Name: "get_repository", Input: "testdata/lua/lakefs_get_repository.lua", Output: "testdata/lua/lakefs_get_repository.output"},
+		{Name: "get_repository_metadata", Input: "testdata/lua/lakefs_get_repository_metadata.lua", Output: "testdata/lua/lakefs_get_repository_metadata.output"},
+	}
+	server := newLakeFSEchoServer(t)
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			script, err := os.ReadFile(testCase.Input)
+			if err != nil {
+				t.Fatalf("could not load fixture %s: %v", testCase.Input, err)
+			}
+			expectedOut, err := os.ReadFile(testCase.Output)
+			if err != nil {
+				t.Fatalf("could not load fixture %s: %v", testCase.Output, err)
+			}
+			h, err := actions.NewLuaHook(
+				actions.ActionHook{
+					ID:         "myHook",
+					Type:       actions.HookTypeLua,
+					Properties: map[string]interface{}{"script": string(script)},
+				},
+				&actions.Action{Name: "lakefsClient"},
+				actions.Config{Enabled: true},
+				server)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ctx := auth.WithUser(context.Background(), &model.User{Username: "user1"})
+			out := &bytes.Buffer{}
+			err = h.Run(ctx, graveler.HookRecord{
+				RunID:        "abc123",
+				EventType:    graveler.EventTypePreCommit,
+				RepositoryID: "example-repo",
+				BranchID:     "main",
+			}, out)
+			if err != nil {
+				t.Fatalf("unexpected error running hook: %v\n%s", err, out.String())
+			}
+			if !strings.Contains(out.String(), string(expectedOut)) {
+				t.Errorf("expected output\n%s\n------- got\n%s-------", expectedOut, out.String())
+			}
+		})
+	}
+}
+
+func TestLuaRunLakeFSOpenObject(t *testing.T) {
+	const content = "name,population\nTel Aviv,460613\nHaifa,285316\nEilat,52299\n"
+	var ranges []string
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "data/cities.csv" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"message": "not found"}`)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "/objects/stat") {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"path": "data/cities.csv", "size_bytes": %d}`, len(content))
+				return
+			}
+			ranges = append(ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, "cities.csv", time.Time{}, strings.NewReader(content))
+		}),
+		ReadHeaderTimeout: time.Minute,
+	}
+	script, err := os.ReadFile("testdata/lua/lakefs_open_object.lua")
+	if err != nil {
+		t.Fatalf("could not load fixture: %v", err)
+	}
+	expectedOut, err := os.ReadFile("testdata/lua/lakefs_open_object.output")
+	if err != nil {
+		t.Fatalf("could not load fixture: %v", err)
+	}
+	h, err := actions.NewLuaHook(
+		actions.ActionHook{
+			ID:         "myHook",
+			Type:       actions.HookTypeLua,
+			Properties: map[string]interface{}{"script": string(script)},
+		},
+		&actions.Action{Name: "lakefsClient"},
+		actions.Config{Enabled: true},
+		server)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := auth.WithUser(context.Background(), &model.User{Username: "user1"})
+	out := &bytes.Buffer{}
+	err = h.Run(ctx, graveler.HookRecord{
+		RunID:        "abc123",
+		EventType:    graveler.EventTypePreCommit,
+		RepositoryID: "example-repo",
+		BranchID:     "main",
+	}, out)
+	if err != nil {
+		t.Fatalf("unexpected error running hook: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), string(expectedOut)) {
+		t.Errorf("expected output\n%s\n------- got\n%s-------", expectedOut, out.String())
+	}
+	expectedRanges := []string{fmt.Sprintf("bytes=0-%d", len(content)-1)}
+	if diff := deep.Equal(ranges, expectedRanges); diff != nil {
+		t.Errorf("unexpected ranges read: %s", diff)
+	}
+}
+
+// prefixAuthorizer allows access to the hook storage keys of the allowed prefix
+type prefixAuthorizer struct {
+	allowed string
+}
+
+func (a *prefixAuthorizer) Authorize(_ context.Context, req *auth.AuthorizationRequest) (*auth.AuthorizationResponse, error) {
+	permission := req.RequiredPermissions.Permission
+	allowed := (permission.Action == permissions.ReadHookStorageAction || permission.Action == permissions.WriteHookStorageAction) &&
+		strings.HasPrefix(permission.Resource, permissions.HookStorageArn("example-repo", a.allowed))
+	return &auth.AuthorizationResponse{Allowed: allowed}, nil
+}
+
+func TestLuaRunLakeFSStorage(t *testing.T) {
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/repositories/example-repo" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"id": "example-repo", "storage_namespace": "mem://example-repo"}`)
+		}),
+		ReadHeaderTimeout: time.Minute,
+	}
+	script, err := os.ReadFile("testdata/lua/lakefs_storage.lua")
+	if err != nil {
+		t.Fatalf("could not load fixture: %v", err)
+	}
+	expectedOut, err := os.ReadFile("testdata/lua/lakefs_storage.output")
+	if err != nil {
+		t.Fatalf("could not load fixture: %v", err)
+	}
+	h, err := actions.NewLuaHook(
+		actions.ActionHook{
+			ID:         "myHook",
+			Type:       actions.HookTypeLua,
+			Properties: map[string]interface{}{"script": string(script)},
+		},
+		&actions.Action{Name: "lakefsStorage"},
+		actions.Config{Enabled: true},
+		server)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := auth.WithUser(context.Background(), &model.User{Username: "user1"})
+	adapter := mem.New(ctx)
+	h.(*actions.LuaHook).Storage = &lakefs.Storage{
+		Adapter:    adapter,
+		Authorizer: &prefixAuthorizer{allowed: "exports/"},
+		Prefix:     "_hooks",
+	}
+	out := &bytes.Buffer{}
+	err = h.Run(ctx, graveler.HookRecord{
+		RunID:        "abc123",
+		EventType:    graveler.EventTypePreCommit,
+		RepositoryID: "example-repo",
+		BranchID:     "main",
+	}, out)
+	if err != nil {
+		t.Fatalf("unexpected error running hook: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), string(expectedOut)) {
+		t.Errorf("expected output\n%s\n------- got\n%s-------", expectedOut, out.String())
+	}
+	// keys are written under the storage prefix
+	exists, err := adapter.Exists(ctx, block.ObjectPointer{
+		StorageNamespace: "mem://example-repo",
+		Identifier:       "_hooks/exports/kept",
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+	if err != nil || !exists {
+		t.Errorf("expected key under the storage prefix, exists=%t err=%v", exists, err)
+	}
+}
+
+func TestValidateStoragePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{prefix: "_hooks", valid: true},
+		{prefix: "/_hooks/scratch/", valid: true},
+		{prefix: "_lakefs_hooks", valid: true},
+		{prefix: "", valid: false},
+		{prefix: "/", valid: false},
+		{prefix: "_lakefs", valid: false},
+		{prefix: "_lakefs/hooks", valid: false},
+		{prefix: "data", valid: false},
+		{prefix: "data/hooks", valid: false},
+		{prefix: "hooks/../data", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			err := lakefs.ValidateStoragePrefix(tt.prefix, "_lakefs", "data")
+			if tt.valid && err != nil {
+				t.Errorf("ValidateStoragePrefix(%s) unexpected error: %v", tt.prefix, err)
+			}
+			if !tt.valid && !errors.Is(err, lakefs.ErrStorageInvalidPrefix) {
+				t.Errorf("ValidateStoragePrefix(%s) err=%v, expected %v", tt.prefix, err, lakefs.ErrStorageInvalidPrefix)
+			}
+		})
+	}
+}
+
 func TestDescendArgs(t *testing.T) {
 	t.Run("valid secrets", func(t *testing.T) {
 		testutil.WithEnvironmentVariable(t, "magic_environ123123", "magic_environ_value")
@@ -0,0 +1,113 @@
+package messaging
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+// KafkaAcks is the number of acknowledgments the leader must receive before responding to a produce request
+type KafkaAcks int16
+
+const (
+	// KafkaAcksNone doesn't wait for any acknowledgment: at most once delivery
+	KafkaAcksNone KafkaAcks = 0
+	// KafkaAcksLeader waits for the leader to write the message
+	KafkaAcksLeader KafkaAcks = 1
+	// KafkaAcksAll waits for all the in-sync replicas to write the message. Only produce with all acknowledgments is
+	// idempotent: retries do not duplicate the message.
+	KafkaAcksAll KafkaAcks = -1
+)
+
+const kafkaDefaultClientID = "lakefs"
+
+var ErrKafkaNoBrokers = errors.New("no kafka brokers")
+
+type KafkaConfig struct {
+	Brokers  []string
+	ClientID string
+	// TLS connects to the brokers using TLS
+	TLS bool
+	// Username and Password authenticate using SASL/PLAIN when Username is set
+	Username string
+	Password string
+	Acks     KafkaAcks
+	Timeout  time.Duration
+	// Retries is the number of times a produce failing with a retriable error is retried
+	Retries int
+}
+
+type KafkaProduceResult struct {
+	Partition int32
+	// Offset of the message, -1 when not waiting for acknowledgments
+	Offset int64
+}
+
+func newKafkaClient(cfg KafkaConfig) (*kgo.Client, error) {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = kafkaDefaultClientID
+	}
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ClientID(clientID),
+		kgo.RecordRetries(cfg.Retries),
+	}
+	switch cfg.Acks {
+	case KafkaAcksNone:
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()), kgo.DisableIdempotentWrite())
+	case KafkaAcksLeader:
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite())
+	default:
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	}
+	if cfg.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if cfg.Username != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()))
+	}
+	return kgo.NewClient(opts...)
+}
+
+// KafkaProduce writes the message to the topic and waits for its acknowledgment. The partition is selected by the
+// key, or by the producer when there is no key.
+func (c *Clients) KafkaProduce(ctx context.Context, cfg KafkaConfig, topic string, msg Message) (*KafkaProduceResult, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, ErrKafkaNoBrokers
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	// the timeout applies to each message, not to the producer
+	clientCfg := cfg
+	clientCfg.Timeout = 0
+	client, err := c.kafka.get(clientCfg, func() (*kgo.Client, error) {
+		return newKafkaClient(clientCfg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka client: %w", err)
+	}
+
+	record := &kgo.Record{Topic: topic, Key: msg.Key, Value: msg.Value}
+	for _, h := range msg.Headers {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	produced, err := client.ProduceSync(ctx, record).First()
+	if err != nil {
+		return nil, err
+	}
+	res := &KafkaProduceResult{Partition: produced.Partition, Offset: produced.Offset}
+	if cfg.Acks == KafkaAcksNone {
+		res.Offset = -1
+	}
+	return res, nil
+}
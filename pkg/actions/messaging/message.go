@@ -0,0 +1,136 @@
+// Package messaging publishes messages to message buses, using the franz-go Kafka client and the NATS client.
+// Clients keeps the clients by their configuration, reused by the messages published with it.
+package messaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Header is a message header. Headers are ordered and keys may repeat.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a message to publish
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers []Header
+}
+
+const (
+	defaultTimeout = 30 * time.Second
+	// clientIdleTimeout is the time a client is kept unused before it is closed
+	clientIdleTimeout = 10 * time.Minute
+)
+
+type cachedClient[T any] struct {
+	client   T
+	lastUsed time.Time
+}
+
+// clientCache keeps clients by configuration. Clients unused for clientIdleTimeout are closed when a client is
+// fetched.
+type clientCache[T any] struct {
+	closeClient func(T)
+
+	mu      sync.Mutex
+	clients map[string]*cachedClient[T]
+}
+
+func newClientCache[T any](closeClient func(T)) *clientCache[T] {
+	return &clientCache[T]{
+		closeClient: closeClient,
+		clients:     make(map[string]*cachedClient[T]),
+	}
+}
+
+// get returns the client of the configuration, creating it with newClient when it is not kept
+func (c *clientCache[T]) get(cfg interface{}, newClient func() (T, error)) (T, error) {
+	key, err := configKey(cfg)
+	if err != nil {
+		var client T
+		return client, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, cached := range c.clients {
+		if k != key && now.Sub(cached.lastUsed) > clientIdleTimeout {
+			c.closeClient(cached.client)
+			delete(c.clients, k)
+		}
+	}
+	if cached, ok := c.clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+	client, err := newClient()
+	if err != nil {
+		return client, err
+	}
+	c.clients[key] = &cachedClient[T]{client: client, lastUsed: now}
+	return client, nil
+}
+
+// remove closes the client of the configuration, so the next message uses a new client
+func (c *clientCache[T]) remove(cfg interface{}) {
+	key, err := configKey(cfg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[key]; ok {
+		c.closeClient(cached.client)
+		delete(c.clients, key)
+	}
+}
+
+func (c *clientCache[T]) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, cached := range c.clients {
+		c.closeClient(cached.client)
+		delete(c.clients, k)
+	}
+}
+
+// configKey identifies a client configuration without keeping its secrets
+func configKey(cfg interface{}) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Clients keeps the Kafka producers and NATS connections used to publish messages, until closed
+type Clients struct {
+	kafka *clientCache[*kgo.Client]
+	nats  *clientCache[*nats.Conn]
+}
+
+func NewClients() *Clients {
+	return &Clients{
+		// each producer keeps its connections to the brokers
+		kafka: newClientCache(func(client *kgo.Client) { client.Close() }),
+		// a connection reconnects to the server by itself, until closed
+		nats: newClientCache(func(conn *nats.Conn) { conn.Close() }),
+	}
+}
+
+// Close closes the clients kept for publishing messages
+func (c *Clients) Close() {
+	c.kafka.closeAll()
+	c.nats.closeAll()
+}
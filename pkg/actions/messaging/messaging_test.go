@@ -0,0 +1,157 @@
+package messaging_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/treeverse/lakefs/pkg/actions/messaging/messagingtest"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestKafkaProduce(t *testing.T) {
+	ctx := context.Background()
+	broker := messagingtest.NewKafkaBroker(t, map[string]int{"events": 4})
+	clients := messaging.NewClients()
+	t.Cleanup(clients.Close)
+	topic := broker.Topic("events")
+	cfg := messaging.KafkaConfig{
+		Brokers: []string{broker.Addr()},
+		Acks:    messaging.KafkaAcksAll,
+		Timeout: 30 * time.Second,
+		Retries: 3,
+	}
+	msg := messaging.Message{
+		Key:     []byte("repo1"),
+		Value:   []byte(`{"event_type":"post-commit"}`),
+		Headers: []messaging.Header{{Key: "ce_type", Value: []byte("io.lakefs.post-commit")}},
+	}
+
+	first, err := clients.KafkaProduce(ctx, cfg, topic, msg)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), first.Offset)
+
+	// the same key goes to the same partition
+	res, err := clients.KafkaProduce(ctx, cfg, topic, msg)
+	require.NoError(t, err)
+	require.Equal(t, first.Partition, res.Partition)
+	require.Equal(t, int64(1), res.Offset)
+
+	records := broker.Records("events")
+	require.Len(t, records, 2)
+	require.Equal(t, msg, records[0].Message)
+	require.Equal(t, first.Partition, records[0].Partition)
+
+	t.Run("leader acks", func(t *testing.T) {
+		cfg := cfg
+		cfg.Acks = messaging.KafkaAcksLeader
+		res, err := clients.KafkaProduce(ctx, cfg, topic, msg)
+		require.NoError(t, err)
+		require.Equal(t, first.Partition, res.Partition)
+		require.Equal(t, int64(2), res.Offset)
+	})
+
+	t.Run("no acks", func(t *testing.T) {
+		cfg := cfg
+		cfg.Acks = messaging.KafkaAcksNone
+		res, err := clients.KafkaProduce(ctx, cfg, topic, messaging.Message{Value: []byte("fire and forget")})
+		require.NoError(t, err)
+		require.Equal(t, int64(-1), res.Offset)
+		require.Eventually(t, func() bool {
+			return len(broker.Records("events")) == 4
+		}, 30*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		_, err := clients.KafkaProduce(ctx, cfg, "no-such-topic", msg)
+		require.ErrorIs(t, err, kerr.UnknownTopicOrPartition)
+	})
+}
+
+func TestNATSPublish(t *testing.T) {
+	ctx := context.Background()
+	server := messagingtest.NewNATSServer(t, "token", map[string]string{"lakefs.stream": "EVENTS"})
+	cfg := messaging.NATSConfig{URL: server.URL(), Token: "token", Timeout: 5 * time.Second}
+	clients := messaging.NewClients()
+	t.Cleanup(clients.Close)
+
+	t.Run("core", func(t *testing.T) {
+		msg := messaging.Message{Value: []byte("hello")}
+		_, err := clients.NATSPublish(ctx, cfg, "lakefs.events", msg)
+		require.NoError(t, err)
+		messages := server.Messages()
+		require.Len(t, messages, 1)
+		require.Equal(t, "lakefs.events", messages[0].Subject)
+		require.Equal(t, []byte("hello"), messages[0].Data)
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		msg := messaging.Message{
+			Value:   []byte("with headers"),
+			Headers: []messaging.Header{{Key: "ce-type", Value: []byte("io.lakefs.post-commit")}},
+		}
+		_, err := clients.NATSPublish(ctx, cfg, "lakefs.events", msg)
+		require.NoError(t, err)
+		messages := server.Messages()
+		require.Len(t, messages, 2)
+		require.Equal(t, msg.Headers, messages[1].Headers)
+		require.Equal(t, msg.Value, messages[1].Data)
+	})
+
+	t.Run("jetstream", func(t *testing.T) {
+		cfg := cfg
+		cfg.JetStream = true
+		msg := messaging.Message{
+			Value:   []byte("stream message"),
+			Headers: []messaging.Header{{Key: messaging.NATSMsgIDHeader, Value: []byte("run1/hook1")}},
+		}
+		res, err := clients.NATSPublish(ctx, cfg, "lakefs.stream", msg)
+		require.NoError(t, err)
+		require.Equal(t, &messaging.NATSPublishResult{Stream: "EVENTS", Sequence: 1}, res)
+
+		// republishing the same message id is detected as duplicate
+		res, err = clients.NATSPublish(ctx, cfg, "lakefs.stream", msg)
+		require.NoError(t, err)
+		require.Equal(t, &messaging.NATSPublishResult{Stream: "EVENTS", Sequence: 1, Duplicate: true}, res)
+		require.Len(t, server.StreamMessages("EVENTS"), 1)
+
+		_, err = clients.NATSPublish(ctx, cfg, "unrecorded.events", msg)
+		require.ErrorIs(t, err, messaging.ErrNATSNoResponders)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		cfg := cfg
+		cfg.Token = "wrong"
+		_, err := clients.NATSPublish(ctx, cfg, "lakefs.events", messaging.Message{Value: []byte("v")})
+		require.ErrorIs(t, err, nats.ErrAuthorization)
+	})
+
+	t.Run("reuse connection", func(t *testing.T) {
+		before := server.Connections()
+		for i := 0; i < 3; i++ {
+			_, err := clients.NATSPublish(ctx, cfg, "lakefs.events", messaging.Message{Value: []byte("v")})
+			require.NoError(t, err)
+		}
+		require.Equal(t, before, server.Connections())
+	})
+
+	t.Run("close other clients", func(t *testing.T) {
+		before := server.Connections()
+		other := messaging.NewClients()
+		_, err := other.NATSPublish(ctx, cfg, "lakefs.events", messaging.Message{Value: []byte("v")})
+		require.NoError(t, err)
+		require.Equal(t, before+1, server.Connections())
+		other.Close()
+		require.Eventually(t, func() bool {
+			return server.Connections() == before
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// closing other clients keeps the connection of these clients
+		_, err = clients.NATSPublish(ctx, cfg, "lakefs.events", messaging.Message{Value: []byte("v")})
+		require.NoError(t, err)
+		require.Equal(t, before, server.Connections())
+	})
+}
@@ -0,0 +1,99 @@
+package messagingtest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	// kafkaReadIdle is the time reading records waits for more records
+	kafkaReadIdle = 2 * time.Second
+)
+
+// KafkaRecord is a message written to a partition of the broker
+type KafkaRecord struct {
+	messaging.Message
+	Partition int32
+	Offset    int64
+}
+
+// KafkaBroker is an in-process fake Kafka cluster with a single broker
+type KafkaBroker struct {
+	t       *testing.T
+	cluster *kfake.Cluster
+}
+
+// NewKafkaBroker starts a broker with the topics, by number of partitions. It is shut down at the end of the test.
+func NewKafkaBroker(t *testing.T, topics map[string]int) *KafkaBroker {
+	t.Helper()
+	opts := []kfake.Opt{kfake.NumBrokers(1)}
+	for name, partitions := range topics {
+		opts = append(opts, kfake.SeedTopics(int32(partitions), name))
+	}
+	cluster, err := kfake.NewCluster(opts...)
+	if err != nil {
+		t.Fatalf("kafka cluster: %s", err)
+	}
+	t.Cleanup(cluster.Close)
+	return &KafkaBroker{t: t, cluster: cluster}
+}
+
+// Addr returns the address of the broker
+func (b *KafkaBroker) Addr() string {
+	return b.cluster.ListenAddrs()[0]
+}
+
+// Topic returns the name of the topic on the broker
+func (b *KafkaBroker) Topic(name string) string {
+	return name
+}
+
+// Records returns the records written to the topic, ordered by partition and offset
+func (b *KafkaBroker) Records(name string) []KafkaRecord {
+	b.t.Helper()
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(b.cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(b.Topic(name)),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		b.t.Fatalf("kafka consumer: %s", err)
+	}
+	defer consumer.Close()
+
+	var records []KafkaRecord
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), kafkaReadIdle)
+		fetches := consumer.PollFetches(ctx)
+		cancel()
+		fetches.EachError(func(topic string, partition int32, err error) {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				b.t.Fatalf("kafka fetch %s/%d: %s", topic, partition, err)
+			}
+		})
+		if fetches.NumRecords() == 0 {
+			break
+		}
+		fetches.EachRecord(func(r *kgo.Record) {
+			msg := messaging.Message{Key: r.Key, Value: r.Value}
+			for _, h := range r.Headers {
+				msg.Headers = append(msg.Headers, messaging.Header{Key: h.Key, Value: h.Value})
+			}
+			records = append(records, KafkaRecord{Message: msg, Partition: r.Partition, Offset: r.Offset})
+		})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Partition != records[j].Partition {
+			return records[i].Partition < records[j].Partition
+		}
+		return records[i].Offset < records[j].Offset
+	})
+	return records
+}
@@ -0,0 +1,160 @@
+// Package messagingtest runs in-process message brokers for testing publishers: an embedded NATS server, and a fake
+// Kafka cluster.
+package messagingtest
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+)
+
+const (
+	natsReadyTimeout = 10 * time.Second
+	// NATSRecordedSubjects are the subjects of the messages recorded by the server. Publishing to other subjects has no
+	// subscribers.
+	NATSRecordedSubjects = "lakefs.>"
+)
+
+// NATSMessage is a message published to the server
+type NATSMessage struct {
+	Subject string
+	Headers []messaging.Header
+	Data    []byte
+}
+
+// NATSServer is an embedded NATS server with JetStream. Messages published to NATSRecordedSubjects are recorded by a
+// subscription.
+type NATSServer struct {
+	t      *testing.T
+	server *server.Server
+	conn   *nats.Conn
+
+	mu       sync.Mutex
+	messages []NATSMessage
+}
+
+// NewNATSServer starts a server requiring the token when set, with streams by subject. It is shut down at the end of
+// the test.
+func NewNATSServer(t *testing.T, token string, streams map[string]string) *NATSServer {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:          "127.0.0.1",
+		Port:          server.RANDOM_PORT,
+		NoLog:         true,
+		NoSigs:        true,
+		JetStream:     true,
+		StoreDir:      t.TempDir(),
+		Authorization: token,
+	})
+	if err != nil {
+		t.Fatalf("nats server: %s", err)
+	}
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	if !srv.ReadyForConnections(natsReadyTimeout) {
+		t.Fatal("nats server not ready")
+	}
+	conn, err := nats.Connect(srv.ClientURL(), nats.Token(token))
+	if err != nil {
+		t.Fatalf("nats connect: %s", err)
+	}
+	t.Cleanup(conn.Close)
+	s := &NATSServer{t: t, server: srv, conn: conn}
+
+	subjects := make(map[string][]string)
+	for subject, stream := range streams {
+		subjects[stream] = append(subjects[stream], subject)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		t.Fatalf("nats jetstream: %s", err)
+	}
+	for stream, streamSubjects := range subjects {
+		if _, err := js.AddStream(&nats.StreamConfig{Name: stream, Subjects: streamSubjects}); err != nil {
+			t.Fatalf("nats add stream %s: %s", stream, err)
+		}
+	}
+
+	if _, err := conn.Subscribe(NATSRecordedSubjects, s.record); err != nil {
+		t.Fatalf("nats subscribe: %s", err)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("nats flush: %s", err)
+	}
+	return s
+}
+
+func (s *NATSServer) record(msg *nats.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, NATSMessage{
+		Subject: msg.Subject,
+		Headers: natsHeaders(msg.Header),
+		Data:    msg.Data,
+	})
+}
+
+// natsHeaders returns the headers ordered by key
+func natsHeaders(header nats.Header) []messaging.Header {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var headers []messaging.Header
+	for _, k := range keys {
+		for _, v := range header[k] {
+			headers = append(headers, messaging.Header{Key: k, Value: []byte(v)})
+		}
+	}
+	return headers
+}
+
+// URL returns the URL of the server
+func (s *NATSServer) URL() string {
+	return s.server.ClientURL()
+}
+
+// Connections returns the number of clients connected to the server, including the recording connection
+func (s *NATSServer) Connections() int {
+	return s.server.NumClients()
+}
+
+// Messages returns the messages published to the server, in the order received
+func (s *NATSServer) Messages() []NATSMessage {
+	s.t.Helper()
+	// messages published before the flush were delivered to the subscription
+	if err := s.conn.Flush(); err != nil {
+		s.t.Fatalf("nats flush: %s", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]NATSMessage(nil), s.messages...)
+}
+
+// StreamMessages returns the messages kept by the stream
+func (s *NATSServer) StreamMessages(stream string) []NATSMessage {
+	s.t.Helper()
+	js, err := s.conn.JetStream()
+	if err != nil {
+		s.t.Fatalf("nats jetstream: %s", err)
+	}
+	info, err := js.StreamInfo(stream)
+	if err != nil {
+		s.t.Fatalf("nats stream %s: %s", stream, err)
+	}
+	var messages []NATSMessage
+	for seq := info.State.FirstSeq; seq > 0 && seq <= info.State.LastSeq; seq++ {
+		msg, err := js.GetMsg(stream, seq)
+		if err != nil {
+			s.t.Fatalf("nats stream %s message %d: %s", stream, seq, err)
+		}
+		messages = append(messages, NATSMessage{Subject: msg.Subject, Headers: natsHeaders(msg.Header), Data: msg.Data})
+	}
+	return messages
+}
@@ -0,0 +1,115 @@
+package messaging
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	natsClientName = "lakefs"
+
+	// NATSMsgIDHeader is the header used by JetStream to detect duplicate messages
+	NATSMsgIDHeader = nats.MsgIdHdr
+)
+
+var ErrNATSNoResponders = errors.New("nats no responders")
+
+type NATSConfig struct {
+	// URL of the server, nats://host:port or tls://host:port
+	URL      string
+	Username string
+	Password string
+	Token    string
+	// TLS connects to the server using TLS, as does a tls URL
+	TLS     bool
+	Timeout time.Duration
+	// JetStream waits for the stream to acknowledge the message, instead of only for the server to receive it
+	JetStream bool
+}
+
+type NATSPublishResult struct {
+	// Stream, Sequence and Duplicate are set by JetStream acknowledgments
+	Stream    string
+	Sequence  uint64
+	Duplicate bool
+}
+
+func newNATSConn(cfg NATSConfig, timeout time.Duration) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Name(natsClientName),
+		nats.Timeout(timeout),
+	}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+	if cfg.TLS {
+		opts = append(opts, nats.Secure(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	return nats.Connect(cfg.URL, opts...)
+}
+
+// NATSPublish publishes the message to the subject. The message key is not used by NATS.
+func (c *Clients) NATSPublish(ctx context.Context, cfg NATSConfig, subject string, msg Message) (*NATSPublishResult, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	// the timeout and the publish mode apply to each message, not to the connection
+	connCfg := cfg
+	connCfg.Timeout = 0
+	connCfg.JetStream = false
+	conn, err := c.nats.get(connCfg, func() (*nats.Conn, error) {
+		return newNATSConn(connCfg, timeout)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats connect %s: %w", cfg.URL, err)
+	}
+	if conn.IsClosed() {
+		// closed by the server, e.g. on an authorization error, the next message connects again
+		c.nats.remove(connCfg)
+		if err := conn.LastError(); err != nil {
+			return nil, fmt.Errorf("nats connection %s: %w", cfg.URL, err)
+		}
+		return nil, fmt.Errorf("nats connection %s: %w", cfg.URL, nats.ErrConnectionClosed)
+	}
+
+	natsMsg := nats.NewMsg(subject)
+	natsMsg.Data = msg.Value
+	for _, h := range msg.Headers {
+		natsMsg.Header.Add(h.Key, string(h.Value))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if !cfg.JetStream {
+		if err := conn.PublishMsg(natsMsg); err != nil {
+			return nil, err
+		}
+		// the server processes the operations of a connection in order, a flush means the message was published
+		if err := conn.FlushWithContext(ctx); err != nil {
+			return nil, err
+		}
+		return &NATSPublishResult{}, nil
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	ack, err := js.PublishMsg(natsMsg, nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoResponders) || errors.Is(err, nats.ErrNoStreamResponse) {
+		// no stream is listening on the subject
+		return nil, fmt.Errorf("%w: subject %s", ErrNATSNoResponders, subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &NATSPublishResult{Stream: ack.Stream, Sequence: ack.Sequence, Duplicate: ack.Duplicate}, nil
+}
@@ -66,6 +66,20 @@ func (mr *MockSourceMockRecorder) Load(arg0, arg1, arg2 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockSource)(nil).Load), arg0, arg1, arg2)
 }
 
+// WalkChangedPaths mocks base method.
+func (m *MockSource) WalkChangedPaths(arg0 context.Context, arg1 graveler.HookRecord, arg2 func(string) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalkChangedPaths", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalkChangedPaths indicates an expected call of WalkChangedPaths.
+func (mr *MockSourceMockRecorder) WalkChangedPaths(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalkChangedPaths", reflect.TypeOf((*MockSource)(nil).WalkChangedPaths), arg0, arg1, arg2)
+}
+
 // MockOutputWriter is a mock of OutputWriter interface.
 type MockOutputWriter struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// NATSHook publishes the event as a CloudEvent to a NATS subject, using the CloudEvents NATS protocol binding
+type NATSHook struct {
+	HookBase
+	cloudEventsProperties
+	URL       string
+	Subject   *template.Template
+	Headers   map[string]SecureString
+	JetStream bool
+	Username  string
+	Password  SecureString
+	Token     SecureString
+	Timeout   time.Duration
+	// Messaging keeps the connections of the service running the hook
+	Messaging *messaging.Clients
+}
+
+const (
+	natsDefaultTimeout = 1 * time.Minute
+
+	natsURLPropertyKey       = "url"
+	natsSubjectPropertyKey   = "subject"
+	natsJetStreamPropertyKey = "jetstream"
+	natsUsernamePropertyKey  = "username"
+	natsPasswordPropertyKey  = "password"
+	natsTokenPropertyKey     = "token"
+	natsTimeoutPropertyKey   = "timeout"
+
+	cloudEventsNATSHeaderPrefix = "ce-"
+)
+
+var errNATSHookWrongFormat = errors.New("nats hook wrong format")
+
+func NewNATSHook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook, error) {
+	natsHook := &NATSHook{
+		HookBase: HookBase{
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     e,
+			ChangedPaths: action.ChangedPaths,
+		},
+		Timeout: natsDefaultTimeout,
+	}
+
+	var err error
+	natsHook.URL, err = h.Properties.getRequiredProperty(natsURLPropertyKey)
+	if err != nil {
+		return nil, fmt.Errorf("nats hook url property: %w", err)
+	}
+	subject, err := h.Properties.getRequiredProperty(natsSubjectPropertyKey)
+	if err != nil {
+		return nil, fmt.Errorf("nats hook subject property: %w", err)
+	}
+	natsHook.Subject, err = parseEventTemplate(natsSubjectPropertyKey, subject)
+	if err != nil {
+		return nil, fmt.Errorf("nats hook subject property: %w", err)
+	}
+	natsHook.Headers, err = extractHeaders(h.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("nats hook headers property: %w", err)
+	}
+	if v, ok := h.Properties[natsJetStreamPropertyKey]; ok {
+		natsHook.JetStream, ok = v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("nats hook jetstream must be a boolean: %w", errNATSHookWrongFormat)
+		}
+	}
+	if v, ok := h.Properties[natsUsernamePropertyKey].(string); ok {
+		natsHook.Username = v
+		rawPass, err := h.Properties.getRequiredProperty(natsPasswordPropertyKey)
+		if err != nil {
+			return nil, fmt.Errorf("nats hook password property: %w", err)
+		}
+		natsHook.Password, err = NewSecureString(rawPass)
+		if err != nil {
+			return nil, fmt.Errorf("nats hook password property: %w", err)
+		}
+	}
+	if v, ok := h.Properties[natsTokenPropertyKey].(string); ok {
+		natsHook.Token, err = NewSecureString(v)
+		if err != nil {
+			return nil, fmt.Errorf("nats hook token property: %w", err)
+		}
+	}
+	if v, ok := h.Properties[natsTimeoutPropertyKey].(string); ok && v != "" {
+		natsHook.Timeout, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("nats hook timeout property: %w", err)
+		}
+	}
+
+	natsHook.cloudEventsProperties, err = extractCloudEventsProperties(h.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("nats hook: %w", err)
+	}
+	return natsHook, nil
+}
+
+func (n *NATSHook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) error {
+	logging.FromContext(ctx).
+		WithField("hook_type", "nats").
+		WithField("event_type", record.EventType).
+		Debug("hook action executing")
+
+	info := newEventInfo(n.ActionName, n.ID, n.ChangedPaths, record)
+	subject, err := executeEventTemplate(n.Subject, &info)
+	if err != nil {
+		return err
+	}
+	event := newCloudEvent(n.Source, &info, record)
+	body, headers, err := event.encode(n.ContentMode, cloudEventsNATSHeaderPrefix)
+	if err != nil {
+		return err
+	}
+	if n.JetStream {
+		// JetStream discards a message published again with the same id, when a hook run is retried
+		headers = append(headers, messaging.Header{Key: messaging.NATSMsgIDHeader, Value: []byte(event.ID)})
+	}
+
+	_, _ = fmt.Fprintf(buf, "URL: %s\nSubject: %s\n", n.URL, subject)
+	buf.WriteString("Headers:\n")
+	headers = appendSecureHeaders(headers, n.Headers, buf)
+	_, _ = fmt.Fprintf(buf, "Message Body:\n%s\n\n", body)
+
+	cfg := messaging.NATSConfig{
+		URL:       n.URL,
+		Username:  n.Username,
+		Password:  n.Password.val,
+		Token:     n.Token.val,
+		Timeout:   n.Timeout,
+		JetStream: n.JetStream,
+	}
+	clients, closeClients := publishClients(n.Messaging)
+	defer closeClients()
+	start := time.Now()
+	res, err := clients.NATSPublish(ctx, cfg, subject, messaging.Message{Value: body, Headers: headers})
+	_, _ = fmt.Fprintf(buf, "Publish duration: %s\n", time.Since(start))
+	if err != nil {
+		return fmt.Errorf("nats publish to %s: %w", subject, err)
+	}
+	if n.JetStream {
+		_, _ = fmt.Fprintf(buf, "Stream: %s\nSequence: %d\nDuplicate: %t\n", res.Stream, res.Sequence, res.Duplicate)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gobwas/glob"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+const (
+	// pathSeparator is the separator used by path patterns: '*' will not match it while '**' will.
+	pathSeparator = '/'
+
+	// maxChangedPaths is the maximal number of matched changed paths passed to the hooks of an action
+	maxChangedPaths = 1000
+)
+
+// errStopWalk stops walking the changed paths once all the actions collected their changed paths
+var errStopWalk = errors.New("stop walk")
+
+// isPathsEventSupported returns true if the changed paths of the event can be listed, that is for events that
+// create a commit and for object events
+func isPathsEventSupported(event graveler.EventType) bool {
+	switch event {
+	case graveler.EventTypePreCommit,
+		graveler.EventTypePostCommit,
+		graveler.EventTypePreMerge,
+		graveler.EventTypePostMerge,
+		graveler.EventTypePreRevert,
+		graveler.EventTypePostRevert,
+		graveler.EventTypePreCherryPick,
+		graveler.EventTypePostCherryPick,
+		graveler.EventTypePreImport,
+		graveler.EventTypePostImport,
+		graveler.EventTypePrePutObject,
+		graveler.EventTypePreDeleteObject:
+		return true
+	}
+	return false
+}
+
+type pathsFilter struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func newPathsFilter(paths *ActionOnPaths) (*pathsFilter, error) {
+	include, err := compilePathGlobs(paths.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePathGlobs(paths.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &pathsFilter{include: include, exclude: exclude}, nil
+}
+
+func compilePathGlobs(patterns []string) ([]glob.Glob, error) {
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, pathSeparator)
+		if err != nil {
+			return nil, fmt.Errorf("path pattern '%s': %w: %s", pattern, ErrInvalidEventParameter, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+func (f *pathsFilter) Match(p string) bool {
+	if len(f.include) > 0 && !matchAnyGlob(f.include, p) {
+		return false
+	}
+	return !matchAnyGlob(f.exclude, p)
+}
+
+func matchAnyGlob(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterActionsByPaths returns the actions without path filters, and the actions whose path filters match at least
+// one path changed by the operation of the record. The matched paths are kept in the action's ChangedPaths.
+// The changed paths are walked once, and only if any of the actions has path filters.
+func (s *StoreService) filterActionsByPaths(ctx context.Context, record graveler.HookRecord, actions []*Action) ([]*Action, error) {
+	filters := make(map[*Action]*pathsFilter)
+	for _, action := range actions {
+		on := action.On[record.EventType]
+		if on == nil || on.Paths == nil {
+			continue
+		}
+		f, err := newPathsFilter(on.Paths)
+		if err != nil {
+			return nil, err
+		}
+		filters[action] = f
+		action.ChangedPaths = []string{}
+	}
+	if len(filters) == 0 {
+		return actions, nil
+	}
+
+	err := s.Source.WalkChangedPaths(ctx, record, func(p string) error {
+		done := true
+		for action, f := range filters {
+			if len(action.ChangedPaths) >= maxChangedPaths {
+				continue
+			}
+			if f.Match(p) {
+				action.ChangedPaths = append(action.ChangedPaths, p)
+			}
+			done = done && len(action.ChangedPaths) >= maxChangedPaths
+		}
+		if done {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, fmt.Errorf("list changed paths: %w", err)
+	}
+
+	matched := make([]*Action, 0, len(actions))
+	for _, action := range actions {
+		if _, ok := filters[action]; ok && len(action.ChangedPaths) == 0 {
+			continue
+		}
+		matched = append(matched, action)
+	}
+	return matched, nil
+}
@@ -0,0 +1,475 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	postHooksPrefix = "post_hooks"
+	// postHooksDuePrefix indexes the post event jobs by the time of their next attempt
+	postHooksDuePrefix = "due_post_hooks"
+
+	defaultPostHooksPollInterval = 10 * time.Second
+	// postHookJobLease is the time a running job is owned by the lakeFS instance running it. A job of an instance
+	// that stopped while running it, is picked up again after the lease expires.
+	postHookJobLease = 15 * time.Minute
+)
+
+var (
+	ErrRunInProgress   = errors.New("run in progress")
+	ErrRunNotRetryable = errors.New("run cannot be retried")
+
+	errInvalidPostHookJobDueKey = errors.New("invalid post hooks job index key")
+)
+
+func PostHookJobPath(repoID, runID string) []byte {
+	return []byte(kv.FormatPath(postHooksPrefix, repoID, runID))
+}
+
+// PostHookJobDuePath returns the index key of a job due at the given time. Keys are sorted by the due time.
+func PostHookJobDuePath(repoID, runID string, due time.Time) []byte {
+	return []byte(kv.FormatPath(postHooksDuePrefix, fmt.Sprintf("%020d", due.UnixNano()), repoID, runID))
+}
+
+// retryPolicy returns the retry policy of a hook, unset values are taken from the configuration
+func (s *StoreService) retryPolicy(hook *ActionHook) HookRetry {
+	policy := HookRetry{
+		Attempts:   s.cfg.PostHooks.Retry.Attempts,
+		Backoff:    s.cfg.PostHooks.Retry.Backoff,
+		MaxBackoff: s.cfg.PostHooks.Retry.MaxBackoff,
+	}
+	if hook != nil && hook.Retry != nil {
+		if hook.Retry.Attempts > 0 {
+			policy.Attempts = hook.Retry.Attempts
+		}
+		if hook.Retry.Backoff > 0 {
+			policy.Backoff = hook.Retry.Backoff
+		}
+		if hook.Retry.MaxBackoff > 0 {
+			policy.MaxBackoff = hook.Retry.MaxBackoff
+		}
+	}
+	return policy
+}
+
+// nextAttempt returns the time to retry after the given number of attempts, or false if no attempts are left
+func (p HookRetry) nextAttempt(now time.Time, attempts int) (time.Time, bool) {
+	if attempts >= p.Attempts {
+		return time.Time{}, false
+	}
+	backoff := p.Backoff
+	for i := 1; i < attempts && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return now.Add(backoff), true
+}
+
+func newPostHookJob(ctx context.Context, record graveler.HookRecord) (*PostHookJobData, error) {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("encode record: %w", err)
+	}
+	var userJSON []byte
+	user, err := auth.GetUser(ctx)
+	if err == nil {
+		u := *user
+		// not required to run hooks, keep it out of the job
+		u.EncryptedPassword = nil
+		userJSON, err = json.Marshal(u)
+		if err != nil {
+			return nil, fmt.Errorf("encode user: %w", err)
+		}
+	} else if !errors.Is(err, auth.ErrUserNotFound) {
+		logging.FromContext(ctx).WithError(err).WithField("record", record).
+			Info("Failed getting user from context")
+	}
+	now := timestamppb.Now()
+	return &PostHookJobData{
+		RunId:        record.RunID,
+		RepositoryId: record.RepositoryID.String(),
+		Record:       recordJSON,
+		User:         userJSON,
+		Status:       PostHookJobData_PENDING,
+		NextAttempt:  now,
+		CreationDate: now,
+	}, nil
+}
+
+// enqueuePostHooks saves the post event as a job and runs it in the background. The job is kept until all the
+// matched actions complete successfully, failed actions are retried based on the retry policy of the failing hook.
+// Events that match no action are not saved.
+func (s *StoreService) enqueuePostHooks(ctx context.Context, record graveler.HookRecord) {
+	if !s.cfg.Enabled {
+		logging.FromContext(ctx).WithField("record", record).Debug("Hooks are disabled, skipping hooks execution")
+		return
+	}
+	actions, err := s.loadMatchedActions(ctx, record, MatchSpec{
+		EventType: record.EventType,
+		BranchID:  record.BranchID,
+	})
+	if err == nil && len(actions) == 0 {
+		return
+	}
+	if err != nil {
+		// the job loads the actions again, and retries on failure
+		logging.FromContext(ctx).WithError(err).WithField("record", record).Debug("Failed to load post hooks actions")
+	}
+	job, err := newPostHookJob(ctx, record)
+	if err == nil {
+		err = s.Store.setPostHookJobIf(ctx, job, nil)
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("record", record).
+			Warn("Failed to save post hooks job, running hooks without retries")
+		s.asyncRun(ctx, record)
+		return
+	}
+	s.runPostHookJobAsync(job.RepositoryId, job.RunId)
+}
+
+func (s *StoreService) runPostHookJobAsync(repositoryID, runID string) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		// passing the global context for cancelling all runs when lakeFS shuts down
+		if err := s.runPostHookJob(s.ctx, repositoryID, runID); err != nil {
+			logging.FromContext(s.ctx).WithError(err).WithFields(logging.Fields{
+				"repository": repositoryID,
+				"run_id":     runID,
+			}).Info("Async run of hook failed")
+		}
+	}()
+}
+
+// runPostHookJob claims a job that is due and runs the actions that did not complete yet
+func (s *StoreService) runPostHookJob(ctx context.Context, repositoryID, runID string) error {
+	job, predicate, err := s.Store.getPostHookJob(ctx, repositoryID, runID)
+	if errors.Is(err, ErrNotFound) {
+		// completed by another instance
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if job.Status == PostHookJobData_DEAD_LETTER || job.NextAttempt.AsTime().After(now) {
+		return nil
+	}
+	job.Status = PostHookJobData_RUNNING
+	job.NextAttempt = timestamppb.New(now.Add(postHookJobLease))
+	err = s.Store.setPostHookJobIf(ctx, job, predicate)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		// claimed by another instance
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("claim post hooks job: %w", err)
+	}
+	// results are saved by the predicate of the claimed job, so they are dropped once another instance claims the job
+	claimed, predicate, err := s.Store.getPostHookJob(ctx, repositoryID, runID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("claim post hooks job: %w", err)
+	}
+	if !proto.Equal(claimed, job) {
+		// claimed by another instance
+		return nil
+	}
+
+	record, runCtx, err := decodePostHookJob(ctx, job)
+	if err != nil {
+		// cannot be retried
+		job.Attempts++
+		s.updatePostHookJob(job, err, now, time.Time{}, false)
+		_ = s.savePostHookJobResult(ctx, job, predicate, false)
+		return err
+	}
+
+	done, runErr := s.runPostHookJobAttempt(runCtx, job, record)
+	if ctx.Err() != nil {
+		// lakeFS is shutting down - release the job without counting the attempt, so it will run again
+		job.Status = PostHookJobData_PENDING
+		job.NextAttempt = timestamppb.New(now)
+		job.Attempts--
+		return s.savePostHookJobResult(context.Background(), job, predicate, false)
+	}
+	if err := s.savePostHookJobResult(ctx, job, predicate, done); err != nil {
+		return fmt.Errorf("save post hooks job: %w", err)
+	}
+	return runErr
+}
+
+// savePostHookJobResult saves the job after a run, or deletes it when done. The result is dropped when the job was
+// updated since it was claimed, as another instance claimed it after the lease expired or it was retried.
+func (s *StoreService) savePostHookJobResult(ctx context.Context, job *PostHookJobData, predicate kv.Predicate, done bool) error {
+	var err error
+	if done {
+		err = s.Store.deletePostHookJobIf(ctx, job.RepositoryId, job.RunId, predicate)
+	} else {
+		err = s.Store.setPostHookJobIf(ctx, job, predicate)
+	}
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"repository": job.RepositoryId,
+			"run_id":     job.RunId,
+		}).Warn("Post hooks job updated since claimed, dropping run result")
+		return nil
+	}
+	return err
+}
+
+// decodePostHookJob returns the job's event record, and a context with the user that triggered the event
+func decodePostHookJob(ctx context.Context, job *PostHookJobData) (graveler.HookRecord, context.Context, error) {
+	var record graveler.HookRecord
+	if err := json.Unmarshal(job.Record, &record); err != nil {
+		return record, nil, fmt.Errorf("decode post hooks job record: %w", err)
+	}
+	if len(job.User) == 0 {
+		return record, ctx, nil
+	}
+	var user model.User
+	if err := json.Unmarshal(job.User, &user); err != nil {
+		return record, nil, fmt.Errorf("decode post hooks job user: %w", err)
+	}
+	return record, auth.WithUser(ctx, &user), nil
+}
+
+// runPostHookJobAttempt runs the job's actions that did not complete yet and updates the job and the run results.
+// Returns true when all the actions completed successfully.
+func (s *StoreService) runPostHookJobAttempt(ctx context.Context, job *PostHookJobData, record graveler.HookRecord) (bool, error) {
+	job.Attempts++
+	now := time.Now()
+
+	actions, err := s.loadMatchedActions(ctx, record, MatchSpec{
+		EventType: record.EventType,
+		BranchID:  record.BranchID,
+	})
+	if err == nil {
+		var tasks [][]*Task
+		tasks, err = s.allocateTasks(record.RunID, actions)
+		if err == nil {
+			return s.runPendingTasks(ctx, job, record, tasks, now)
+		}
+	}
+	// failed before running any hook, retry based on the default policy
+	nextAttempt, retry := s.retryPolicy(nil).nextAttempt(now, int(job.Attempts))
+	s.updatePostHookJob(job, err, now, nextAttempt, retry)
+	return false, err
+}
+
+// runPendingTasks runs the tasks of the actions that did not complete on previous attempts
+func (s *StoreService) runPendingTasks(ctx context.Context, job *PostHookJobData, record graveler.HookRecord, tasks [][]*Task, now time.Time) (bool, error) {
+	completed := make(map[string]struct{}, len(job.CompletedActions))
+	for _, name := range job.CompletedActions {
+		completed[name] = struct{}{}
+	}
+	var pending [][]*Task
+	for _, actionTasks := range tasks {
+		if _, ok := completed[actionTasks[0].Action.Name]; !ok {
+			pending = append(pending, actionTasks)
+		}
+	}
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	runErr := s.runTasks(ctx, record, pending, s.Writer)
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	// update the job with the results of each action
+	if job.ActionAttempts == nil {
+		job.ActionAttempts = make(map[string]int32)
+	}
+	var (
+		nextAttempt time.Time
+		retry       bool
+		failed      bool
+	)
+	for _, actionTasks := range pending {
+		action := actionTasks[0].Action
+		failedTask := firstFailedTask(actionTasks)
+		if failedTask == nil {
+			job.CompletedActions = append(job.CompletedActions, action.Name)
+			continue
+		}
+		failed = true
+		job.ActionAttempts[action.Name]++
+		policy := s.retryPolicy(actionHook(action, failedTask.HookID))
+		if at, ok := policy.nextAttempt(now, int(job.ActionAttempts[action.Name])); ok && (!retry || at.Before(nextAttempt)) {
+			nextAttempt = at
+			retry = true
+		}
+	}
+	if failed {
+		s.updatePostHookJob(job, runErr, now, nextAttempt, retry)
+	}
+
+	if err := s.savePostHookRunInformation(ctx, job, record, pending); err != nil {
+		return false, err
+	}
+	return !failed, runErr
+}
+
+func (s *StoreService) updatePostHookJob(job *PostHookJobData, err error, now time.Time, nextAttempt time.Time, retry bool) {
+	if err != nil {
+		job.LastError = err.Error()
+	}
+	if retry {
+		job.Status = PostHookJobData_PENDING
+		job.NextAttempt = timestamppb.New(nextAttempt)
+		return
+	}
+	job.Status = PostHookJobData_DEAD_LETTER
+	job.NextAttempt = timestamppb.New(now)
+}
+
+func firstFailedTask(tasks []*Task) *Task {
+	for _, task := range tasks {
+		if task.Err != nil {
+			return task
+		}
+	}
+	return nil
+}
+
+func actionHook(action *Action, hookID string) *ActionHook {
+	for i := range action.Hooks {
+		if action.Hooks[i].ID == hookID {
+			return &action.Hooks[i]
+		}
+	}
+	return nil
+}
+
+// savePostHookRunInformation saves the run results of the tasks that ran in this attempt, together with the results
+// of the actions that completed on previous attempts
+func (s *StoreService) savePostHookRunInformation(ctx context.Context, job *PostHookJobData, record graveler.HookRecord, tasks [][]*Task) error {
+	manifest := buildRunManifestFromTasks(record, tasks)
+	if job.Attempts > 1 {
+		ran := make(map[string]struct{}, len(manifest.HooksRun))
+		for _, hookRun := range manifest.HooksRun {
+			ran[hookRun.HookRunID] = struct{}{}
+		}
+		it, err := s.Store.ListRunTaskResults(ctx, record.RepositoryID.String(), record.RunID, "")
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for it.Next() {
+			prev := it.Value()
+			if _, ok := ran[prev.HookRunID]; ok {
+				continue
+			}
+			manifest.HooksRun = append(manifest.HooksRun, *prev)
+			if !prev.StartTime.IsZero() && prev.StartTime.Before(manifest.Run.StartTime) {
+				manifest.Run.StartTime = prev.StartTime
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	manifest.Run.Attempts = int(job.Attempts)
+	if job.Status == PostHookJobData_DEAD_LETTER {
+		manifest.Run.DeadLetter = true
+	} else if !manifest.Run.Passed {
+		nextAttempt := job.NextAttempt.AsTime()
+		manifest.Run.NextAttempt = &nextAttempt
+	}
+
+	if err := s.saveRunManifestDB(ctx, record.RepositoryID, manifest); err != nil {
+		return fmt.Errorf("insert run information: %w", err)
+	}
+	return s.saveRunManifestObjectStore(ctx, manifest, record.StorageNamespace.String(), record.RunID)
+}
+
+// pollPostHookJobs periodically runs the jobs that are due, until the service is stopped
+func (s *StoreService) pollPostHookJobs() {
+	defer s.wg.Done()
+	interval := s.cfg.PostHooks.PollInterval
+	if interval <= 0 {
+		interval = defaultPostHooksPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.runDuePostHookJobs(); err != nil && s.ctx.Err() == nil {
+			logging.FromContext(s.ctx).WithError(err).Warn("Failed to scan post hooks jobs")
+		}
+	}
+}
+
+// runDuePostHookJobs runs the jobs due by now. Only the index entries that are due are read, entries of jobs that
+// were updated since they were indexed are deleted.
+func (s *StoreService) runDuePostHookJobs() error {
+	return s.Store.walkDuePostHookJobs(s.ctx, time.Now(), func(repositoryID, runID string, due time.Time) error {
+		job, _, err := s.Store.getPostHookJob(s.ctx, repositoryID, runID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return err
+		case job.Status != PostHookJobData_DEAD_LETTER && job.NextAttempt.AsTime().Equal(due):
+			// claimed by runPostHookJob, which indexes the job again by its lease
+			s.runPostHookJobAsync(repositoryID, runID)
+			return nil
+		}
+		return s.Store.deletePostHookJobDue(s.ctx, repositoryID, runID, due)
+	})
+}
+
+// RetryRun schedules an immediate retry of the failed actions of a post event run. The retry policy of the failed
+// actions starts over.
+func (s *StoreService) RetryRun(ctx context.Context, repositoryID string, runID string) error {
+	if !s.cfg.Enabled {
+		return fmt.Errorf("hooks are disabled: %w", ErrRunNotRetryable)
+	}
+	job, predicate, err := s.Store.getPostHookJob(ctx, repositoryID, runID)
+	if errors.Is(err, ErrNotFound) {
+		if _, err := s.Store.GetRunResult(ctx, repositoryID, runID); err != nil {
+			return err
+		}
+		return fmt.Errorf("run %s has no failed post hooks: %w", runID, ErrRunNotRetryable)
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status == PostHookJobData_RUNNING && job.NextAttempt.AsTime().After(time.Now()) {
+		return fmt.Errorf("run %s: %w", runID, ErrRunInProgress)
+	}
+	job.Status = PostHookJobData_PENDING
+	job.NextAttempt = timestamppb.Now()
+	job.ActionAttempts = nil
+	err = s.Store.setPostHookJobIf(ctx, job, predicate)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		return fmt.Errorf("run %s: %w", runID, ErrRunInProgress)
+	}
+	if err != nil {
+		return err
+	}
+	s.runPostHookJobAsync(repositoryID, runID)
+	return nil
+}
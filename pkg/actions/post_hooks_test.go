@@ -0,0 +1,209 @@
+package actions_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/mock"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/stats"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	postHookWaitFor = 10 * time.Second
+	postHookTick    = 10 * time.Millisecond
+)
+
+func newPostHookTestService(t *testing.T, ctx context.Context, webhookURL string) (actions.Service, kv.Store) {
+	t.Helper()
+	actionContent := `name: notify
+on:
+  post-create-tag: {}
+hooks:
+  - id: notify
+    type: webhook
+    retry:
+      attempts: 2
+      backoff: 10ms
+    properties:
+      url: "` + webhookURL + `"
+`
+	ctrl := gomock.NewController(t)
+	testSource := mock.NewMockSource(ctrl)
+	testSource.EXPECT().List(gomock.Any(), gomock.Any()).Return([]string{"act.yaml"}, nil).AnyTimes()
+	testSource.EXPECT().Load(gomock.Any(), gomock.Any(), "act.yaml").Return([]byte(actionContent), nil).AnyTimes()
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+	testOutputWriter.EXPECT().OutputWrite(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	kvStore := kvtest.GetStore(ctx, t)
+	cfg := actions.Config{Enabled: true}
+	cfg.PostHooks.PollInterval = postHookTick
+	cfg.PostHooks.Retry.Attempts = 1
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), testSource, testOutputWriter, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, cfg)
+	require.NoError(t, err)
+	t.Cleanup(actionsService.Stop)
+	return actionsService, kvStore
+}
+
+func newPostHookTestRecord() graveler.HookRecord {
+	return graveler.HookRecord{
+		RunID:            graveler.NewRunID(),
+		EventType:        graveler.EventTypePostCreateTag,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		SourceRef:        "sourceRef",
+		TagID:            "tag",
+		CommitID:         "commitID",
+	}
+}
+
+func TestPostHooksRetry(t *testing.T) {
+	ctx := context.Background()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first call
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	actionsService, _ := newPostHookTestService(t, ctx, ts.URL)
+	record := newPostHookTestRecord()
+	actionsService.PostCreateTagHook(ctx, record)
+
+	var runResult *actions.RunResult
+	require.Eventually(t, func() bool {
+		var err error
+		runResult, err = actionsService.GetRunResult(ctx, record.RepositoryID.String(), record.RunID)
+		return err == nil && runResult.Passed
+	}, postHookWaitFor, postHookTick)
+	require.Equal(t, 2, runResult.Attempts)
+	require.False(t, runResult.DeadLetter)
+	require.Nil(t, runResult.NextAttempt)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// completed run has nothing to retry
+	err := actionsService.RetryRun(ctx, record.RepositoryID.String(), record.RunID)
+	require.ErrorIs(t, err, actions.ErrRunNotRetryable)
+	err = actionsService.RetryRun(ctx, record.RepositoryID.String(), "no-such-run")
+	require.ErrorIs(t, err, actions.ErrNotFound)
+}
+
+func TestPostHooksDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	var (
+		calls   int32
+		healthy atomic.Bool
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	actionsService, _ := newPostHookTestService(t, ctx, ts.URL)
+	record := newPostHookTestRecord()
+	actionsService.PostCreateTagHook(ctx, record)
+
+	// the hook fails on all attempts
+	var runResult *actions.RunResult
+	require.Eventually(t, func() bool {
+		var err error
+		runResult, err = actionsService.GetRunResult(ctx, record.RepositoryID.String(), record.RunID)
+		return err == nil && runResult.DeadLetter
+	}, postHookWaitFor, postHookTick)
+	require.False(t, runResult.Passed)
+	require.Equal(t, 2, runResult.Attempts)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// dead letter runs are not retried automatically
+	time.Sleep(10 * postHookTick)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// retry re-drives the run
+	healthy.Store(true)
+	require.NoError(t, actionsService.RetryRun(ctx, record.RepositoryID.String(), record.RunID))
+	require.Eventually(t, func() bool {
+		var err error
+		runResult, err = actionsService.GetRunResult(ctx, record.RepositoryID.String(), record.RunID)
+		return err == nil && runResult.Passed
+	}, postHookWaitFor, postHookTick)
+	require.False(t, runResult.DeadLetter)
+	require.Equal(t, 3, runResult.Attempts)
+	require.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestPostHooksNoMatchedActions(t *testing.T) {
+	ctx := context.Background()
+	actionsService, kvStore := newPostHookTestService(t, ctx, "http://localhost:1")
+	record := newPostHookTestRecord()
+	record.EventType = graveler.EventTypePostCommit
+	actionsService.PostCommitHook(ctx, record)
+
+	// events that match no action are not saved as jobs
+	it, err := kv.ScanPrefix(ctx, kvStore, []byte(actions.PartitionKey), []byte(""), nil)
+	require.NoError(t, err)
+	defer it.Close()
+	for it.Next() {
+		t.Errorf("unexpected key %s", it.Entry().Key)
+	}
+	require.NoError(t, it.Err())
+}
+
+func TestPostHooksResultDroppedWhenReclaimed(t *testing.T) {
+	ctx := context.Background()
+	var calls int32
+	called := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(called)
+			<-release
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	actionsService, kvStore := newPostHookTestService(t, ctx, ts.URL)
+	record := newPostHookTestRecord()
+	actionsService.PostCreateTagHook(ctx, record)
+
+	// update the job while its hook runs, as another instance does once the lease expires
+	<-called
+	jobKey := actions.PostHookJobPath(record.RepositoryID.String(), record.RunID)
+	job := &actions.PostHookJobData{}
+	_, err := kv.GetMsg(ctx, kvStore, actions.PartitionKey, jobKey, job)
+	require.NoError(t, err)
+	require.Equal(t, actions.PostHookJobData_RUNNING, job.Status)
+	job.NextAttempt = timestamppb.New(time.Now().Add(time.Hour))
+	job.LastError = "claimed by another instance"
+	require.NoError(t, kv.SetMsg(ctx, kvStore, actions.PartitionKey, jobKey, job))
+	close(release)
+
+	require.Eventually(t, func() bool {
+		runResult, err := actionsService.GetRunResult(ctx, record.RepositoryID.String(), record.RunID)
+		return err == nil && runResult.Attempts == 1
+	}, postHookWaitFor, postHookTick)
+	time.Sleep(10 * postHookTick)
+
+	// the result of the run did not overwrite the job
+	saved := &actions.PostHookJobData{}
+	_, err = kv.GetMsg(ctx, kvStore, actions.PartitionKey, jobKey, saved)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(job, saved), "job overwritten by the run result: %s", saved)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
@@ -9,12 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/antonmedv/expr"
 	"github.com/hashicorp/go-multierror"
+	"github.com/treeverse/lakefs/pkg/actions/lua/lakefs"
+	"github.com/treeverse/lakefs/pkg/actions/messaging"
 	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/cache"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/kv"
 	"github.com/treeverse/lakefs/pkg/logging"
@@ -41,6 +45,38 @@ type Config struct {
 	Enabled bool
 	Lua     struct {
 		NetHTTPEnabled bool
+		StoragePrefix  string
+	}
+	PostHooks struct {
+		PollInterval time.Duration
+		Retry        struct {
+			Attempts   int
+			Backoff    time.Duration
+			MaxBackoff time.Duration
+		}
+	}
+	ObjectHooks struct {
+		Prefixes []string
+	}
+	Exec struct {
+		Commands map[string]struct {
+			Path      string
+			Args      []string
+			Env       []string
+			Dir       string
+			AllowArgs bool
+		}
+		Timeout       time.Duration
+		MaxOutputSize int
+		Limits        struct {
+			CPUTime   time.Duration
+			Memory    int64
+			OpenFiles int
+		}
+	}
+	Global struct {
+		Paths    []string
+		Enforced bool
 	}
 }
 
@@ -58,6 +94,12 @@ type StoreService struct {
 	stats    stats.Collector
 	cfg      Config
 	endpoint *http.Server
+	storage  *lakefs.Storage
+	// messaging keeps the clients of the hooks publishing to message buses, closed when the service stops
+	messaging *messaging.Clients
+	// globalActions are the global actions of the configuration, loaded once
+	globalActions      []*GlobalAction
+	globalActionsCache cache.Cache
 }
 
 type Task struct {
@@ -81,6 +123,12 @@ type RunResult struct {
 	StartTime time.Time `db:"start_time" json:"start_time"`
 	EndTime   time.Time `db:"end_time" json:"end_time"`
 	Passed    bool      `db:"passed" json:"passed"`
+	// Attempts is the number of times a post event run was executed
+	Attempts int `db:"attempts" json:"attempts,omitempty"`
+	// DeadLetter is set on a failed post event run that will not be retried automatically
+	DeadLetter bool `db:"dead_letter" json:"dead_letter,omitempty"`
+	// NextAttempt is the time of the next automatic retry of a failed post event run
+	NextAttempt *time.Time `db:"next_attempt" json:"next_attempt,omitempty"`
 }
 
 type TaskResult struct {
@@ -103,29 +151,42 @@ func (r *TaskResult) LogPath() string {
 }
 
 func RunResultFromProto(pb *RunResultData) *RunResult {
-	return &RunResult{
-		RunID:     pb.RunId,
-		BranchID:  pb.BranchId,
-		SourceRef: pb.SourceRef,
-		EventType: pb.EventType,
-		CommitID:  pb.CommitId,
-		StartTime: pb.StartTime.AsTime(),
-		EndTime:   pb.EndTime.AsTime(),
-		Passed:    pb.Passed,
+	res := &RunResult{
+		RunID:      pb.RunId,
+		BranchID:   pb.BranchId,
+		SourceRef:  pb.SourceRef,
+		EventType:  pb.EventType,
+		CommitID:   pb.CommitId,
+		StartTime:  pb.StartTime.AsTime(),
+		EndTime:    pb.EndTime.AsTime(),
+		Passed:     pb.Passed,
+		Attempts:   int(pb.Attempts),
+		DeadLetter: pb.DeadLetter,
+	}
+	if pb.NextAttempt != nil {
+		nextAttempt := pb.NextAttempt.AsTime()
+		res.NextAttempt = &nextAttempt
 	}
+	return res
 }
 
 func protoFromRunResult(m *RunResult) *RunResultData {
-	return &RunResultData{
-		RunId:     m.RunID,
-		BranchId:  m.BranchID,
-		CommitId:  m.CommitID,
-		SourceRef: m.SourceRef,
-		EventType: m.EventType,
-		StartTime: timestamppb.New(m.StartTime),
-		EndTime:   timestamppb.New(m.EndTime),
-		Passed:    m.Passed,
+	pb := &RunResultData{
+		RunId:      m.RunID,
+		BranchId:   m.BranchID,
+		CommitId:   m.CommitID,
+		SourceRef:  m.SourceRef,
+		EventType:  m.EventType,
+		StartTime:  timestamppb.New(m.StartTime),
+		EndTime:    timestamppb.New(m.EndTime),
+		Passed:     m.Passed,
+		Attempts:   int32(m.Attempts),
+		DeadLetter: m.DeadLetter,
+	}
+	if m.NextAttempt != nil {
+		pb.NextAttempt = timestamppb.New(*m.NextAttempt)
 	}
+	return pb
 }
 
 func taskResultFromProto(pb *TaskResultData) *TaskResult {
@@ -158,6 +219,9 @@ func init() {
 	kv.MustRegisterType("*", kv.FormatPath("repos", "*", "runs"), (&RunResultData{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", kv.FormatPath("repos", "*", "branches"), (&kv.SecondaryIndex{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", kv.FormatPath("repos", "*", "commits"), (&kv.SecondaryIndex{}).ProtoReflect().Type())
+	kv.MustRegisterType("*", postHooksPrefix, (&PostHookJobData{}).ProtoReflect().Type())
+	kv.MustRegisterType("*", postHooksDuePrefix, (&kv.SecondaryIndex{}).ProtoReflect().Type())
+	kv.MustRegisterType("*", globalActionsPrefix, (&GlobalActionData{}).ProtoReflect().Type())
 }
 
 func baseActionsPath(repoID string) string {
@@ -210,33 +274,54 @@ type Service interface {
 	GetTaskResult(ctx context.Context, repositoryID string, runID string, hookRunID string) (*TaskResult, error)
 	ListRunResults(ctx context.Context, repositoryID string, branchID, commitID string, after string) (RunResultIterator, error)
 	ListRunTaskResults(ctx context.Context, repositoryID string, runID string, after string) (TaskResultIterator, error)
+	RetryRun(ctx context.Context, repositoryID string, runID string) error
 	graveler.HooksHandler
 }
 
-func NewService(ctx context.Context, store Store, source Source, writer OutputWriter, idGen IDGenerator, stats stats.Collector, cfg Config) *StoreService {
+// NewService returns a service running the actions of repositories and the global actions. It fails when the global
+// actions of the configuration cannot be loaded.
+func NewService(ctx context.Context, store Store, source Source, writer OutputWriter, idGen IDGenerator, stats stats.Collector, cfg Config) (*StoreService, error) {
+	globalActions, err := loadConfiguredGlobalActions(cfg.Global.Paths, cfg.Global.Enforced)
+	if err != nil {
+		return nil, err
+	}
 	ctx, cancel := context.WithCancel(ctx)
-	return &StoreService{
-		Store:  store,
-		Source: source,
-		Writer: writer,
-		ctx:    ctx,
-		idGen:  idGen,
-		cancel: cancel,
-		wg:     sync.WaitGroup{},
-		stats:  stats,
-		cfg:    cfg,
+	s := &StoreService{
+		Store:              store,
+		Source:             source,
+		Writer:             writer,
+		ctx:                ctx,
+		idGen:              idGen,
+		cancel:             cancel,
+		wg:                 sync.WaitGroup{},
+		stats:              stats,
+		cfg:                cfg,
+		globalActions:      globalActions,
+		globalActionsCache: cache.NewCache(1, globalActionsCacheExpiry, cache.NewJitterFn(globalActionsCacheJitter)),
+		messaging:          messaging.NewClients(),
+	}
+	if cfg.Enabled {
+		s.wg.Add(1)
+		go s.pollPostHookJobs()
 	}
+	return s, nil
 }
 
 func (s *StoreService) Stop() {
 	s.cancel()
 	s.wg.Wait()
+	s.messaging.Close()
 }
 
 func (s *StoreService) SetEndpoint(h *http.Server) {
 	s.endpoint = h
 }
 
+// SetStorage sets the access of lua hooks to the storage namespaces of repositories
+func (s *StoreService) SetStorage(storage *lakefs.Storage) {
+	s.storage = storage
+}
+
 func (s *StoreService) asyncRun(ctx context.Context, record graveler.HookRecord) {
 	s.wg.Add(1)
 	go func() {
@@ -286,7 +371,7 @@ func (s *StoreService) Run(ctx context.Context, record graveler.HookRecord) erro
 		return err
 	}
 
-	runErr := s.runTasks(ctx, record, tasks)
+	runErr := s.runTasks(ctx, record, tasks, s.Writer)
 
 	// keep results before returning an error (if any)
 	err = s.saveRunInformation(ctx, record, tasks)
@@ -298,11 +383,15 @@ func (s *StoreService) Run(ctx context.Context, record graveler.HookRecord) erro
 }
 
 func (s *StoreService) loadMatchedActions(ctx context.Context, record graveler.HookRecord, spec MatchSpec) ([]*Action, error) {
-	actions, err := LoadActions(ctx, s.Source, record)
+	actions, err := s.loadActions(ctx, record)
 	if err != nil {
 		return nil, err
 	}
-	return MatchedActions(actions, spec)
+	matched, err := MatchedActions(actions, spec)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterActionsByPaths(ctx, record, matched)
 }
 
 func (s *StoreService) allocateTasks(runID string, actions []*Action) ([][]*Task, error) {
@@ -314,6 +403,14 @@ func (s *StoreService) allocateTasks(runID string, actions []*Action) ([][]*Task
 			if err != nil {
 				return nil, err
 			}
+			switch hook := h.(type) {
+			case *LuaHook:
+				hook.Storage = s.storage
+			case *KafkaHook:
+				hook.Messaging = s.messaging
+			case *NATSHook:
+				hook.Messaging = s.messaging
+			}
 			task := &Task{
 				RunID:     runID,
 				HookRunID: NewHookRunID(actionIdx, hookIdx),
@@ -332,7 +429,9 @@ func (s *StoreService) allocateTasks(runID string, actions []*Action) ([][]*Task
 	return tasks, nil
 }
 
-func (s *StoreService) runTasks(ctx context.Context, record graveler.HookRecord, tasks [][]*Task) error {
+// runTasks runs the tasks of each action in order, the actions run concurrently. The output of each hook is written
+// by writer.
+func (s *StoreService) runTasks(ctx context.Context, record graveler.HookRecord, tasks [][]*Task, writer OutputWriter) error {
 	var g multierror.Group
 	for _, actionTasks := range tasks {
 		actionTasks := actionTasks // pin
@@ -340,7 +439,7 @@ func (s *StoreService) runTasks(ctx context.Context, record graveler.HookRecord,
 			var actionErr error
 			for _, task := range actionTasks {
 				hookOutputWriter := &HookOutputWriter{
-					Writer:           s.Writer,
+					Writer:           writer,
 					StorageNamespace: record.StorageNamespace.String(),
 					RunID:            task.RunID,
 					HookRunID:        task.HookRunID,
@@ -524,7 +623,7 @@ func (s *StoreService) PostCommitHook(ctx context.Context, record graveler.HookR
 		return err
 	}
 
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
 	return nil
 }
 
@@ -539,7 +638,7 @@ func (s *StoreService) PostMergeHook(ctx context.Context, record graveler.HookRe
 		return err
 	}
 
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
 	return nil
 }
 
@@ -548,7 +647,7 @@ func (s *StoreService) PreCreateTagHook(ctx context.Context, record graveler.Hoo
 }
 
 func (s *StoreService) PostCreateTagHook(ctx context.Context, record graveler.HookRecord) {
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
 }
 
 func (s *StoreService) PreDeleteTagHook(ctx context.Context, record graveler.HookRecord) error {
@@ -556,7 +655,7 @@ func (s *StoreService) PreDeleteTagHook(ctx context.Context, record graveler.Hoo
 }
 
 func (s *StoreService) PostDeleteTagHook(ctx context.Context, record graveler.HookRecord) {
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
 }
 
 func (s *StoreService) PreCreateBranchHook(ctx context.Context, record graveler.HookRecord) error {
@@ -564,7 +663,7 @@ func (s *StoreService) PreCreateBranchHook(ctx context.Context, record graveler.
 }
 
 func (s *StoreService) PostCreateBranchHook(ctx context.Context, record graveler.HookRecord) {
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
 }
 
 func (s *StoreService) PreDeleteBranchHook(ctx context.Context, record graveler.HookRecord) error {
@@ -572,7 +671,100 @@ func (s *StoreService) PreDeleteBranchHook(ctx context.Context, record graveler.
 }
 
 func (s *StoreService) PostDeleteBranchHook(ctx context.Context, record graveler.HookRecord) {
-	s.asyncRun(ctx, record)
+	s.enqueuePostHooks(ctx, record)
+}
+
+func (s *StoreService) PreRevertHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostRevertHook(ctx context.Context, record graveler.HookRecord) error {
+	// update pre-revert with commit ID if needed
+	err := s.UpdateCommitID(ctx, record.RepositoryID.String(), record.StorageNamespace.String(), record.PreRunID, record.CommitID.String())
+	if err != nil {
+		return err
+	}
+
+	s.enqueuePostHooks(ctx, record)
+	return nil
+}
+
+func (s *StoreService) PreCherryPickHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostCherryPickHook(ctx context.Context, record graveler.HookRecord) error {
+	// update pre-cherry-pick with commit ID if needed
+	err := s.UpdateCommitID(ctx, record.RepositoryID.String(), record.StorageNamespace.String(), record.PreRunID, record.CommitID.String())
+	if err != nil {
+		return err
+	}
+
+	s.enqueuePostHooks(ctx, record)
+	return nil
+}
+
+func (s *StoreService) PreImportHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostImportHook(ctx context.Context, record graveler.HookRecord) error {
+	// update pre-import with commit ID if needed
+	err := s.UpdateCommitID(ctx, record.RepositoryID.String(), record.StorageNamespace.String(), record.PreRunID, record.CommitID.String())
+	if err != nil {
+		return err
+	}
+
+	s.enqueuePostHooks(ctx, record)
+	return nil
+}
+
+func (s *StoreService) PreResetHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostResetHook(ctx context.Context, record graveler.HookRecord) {
+	s.enqueuePostHooks(ctx, record)
+}
+
+func (s *StoreService) PreCreateRepositoryHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostCreateRepositoryHook(ctx context.Context, record graveler.HookRecord) {
+	s.enqueuePostHooks(ctx, record)
+}
+
+func (s *StoreService) PreDeleteRepositoryHook(ctx context.Context, record graveler.HookRecord) error {
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PostDeleteRepositoryHook(ctx context.Context, record graveler.HookRecord) {
+	s.enqueuePostHooks(ctx, record)
+}
+
+func (s *StoreService) PrePutObjectHook(ctx context.Context, record graveler.HookRecord) error {
+	if !s.isObjectHookKey(record.ObjectKey) {
+		return nil
+	}
+	return s.Run(ctx, record)
+}
+
+func (s *StoreService) PreDeleteObjectHook(ctx context.Context, record graveler.HookRecord) error {
+	if !s.isObjectHookKey(record.ObjectKey) {
+		return nil
+	}
+	return s.Run(ctx, record)
+}
+
+// isObjectHookKey returns true if object hooks run on key, that is if key is under one of the configured prefixes
+func (s *StoreService) isObjectHookKey(key graveler.Key) bool {
+	for _, prefix := range s.cfg.ObjectHooks.Prefixes {
+		if strings.HasPrefix(key.String(), prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *StoreService) NewRunID() string {
@@ -63,7 +63,9 @@ func GetKVService(t *testing.T, ctx context.Context, source actions.Source, writ
 	kvStore := kvtest.GetStore(ctx, t)
 	cfg := actions.Config{Enabled: runHooks}
 	cfg.Lua.NetHTTPEnabled = true
-	return actions.NewService(ctx, actions.NewActionsKVStore(kvStore), source, writer, &actions.DecreasingIDGenerator{}, stats, cfg)
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), source, writer, &actions.DecreasingIDGenerator{}, stats, cfg)
+	require.NoError(t, err)
+	return actionsService
 }
 
 func TestServiceRun(t *testing.T) {
@@ -293,6 +295,119 @@ func TestDisableHooksRun(t *testing.T) {
 	}
 }
 
+func TestObjectHooksPrefixes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+	testSource := mock.NewMockSource(ctrl)
+	// actions are loaded only for keys under a configured prefix
+	testSource.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+
+	kvStore := kvtest.GetStore(ctx, t)
+	cfg := actions.Config{Enabled: true}
+	cfg.ObjectHooks.Prefixes = []string{"tables/"}
+	actionsService, err := actions.NewService(ctx, actions.NewActionsKVStore(kvStore), testSource, testOutputWriter, &actions.DecreasingIDGenerator{}, &stats.NullCollector{}, cfg)
+	require.NoError(t, err)
+	defer actionsService.Stop()
+
+	for _, key := range []string{"tables/orders/part-0.parquet", "logs/app.log"} {
+		record := graveler.HookRecord{
+			RunID:            graveler.NewRunID(),
+			EventType:        graveler.EventTypePrePutObject,
+			StorageNamespace: "storageNamespace",
+			RepositoryID:     "repoID",
+			SourceRef:        "main",
+			BranchID:         "main",
+			ObjectKey:        graveler.Key(key),
+		}
+		require.NoError(t, actionsService.PrePutObjectHook(ctx, record))
+		record.EventType = graveler.EventTypePreDeleteObject
+		require.NoError(t, actionsService.PreDeleteObjectHook(ctx, record))
+	}
+}
+
+func TestPathsFilter(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	var changedPaths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { _ = r.Body.Close() }()
+		if r.URL.Path != "/orders" {
+			t.Errorf("Unexpected webhook call to %s", r.URL.Path)
+			return
+		}
+		var eventInfo actions.EventInfo
+		if err := json.NewDecoder(r.Body).Decode(&eventInfo); err != nil {
+			t.Error("Failed to decode webhook data", err)
+			return
+		}
+		changedPaths = eventInfo.ChangedPaths
+	}))
+	defer ts.Close()
+
+	const actionTemplate = `name: %s
+on:
+  pre-commit:
+    paths:
+      include:
+        - %s
+      exclude:
+        - "**/_temporary/**"
+hooks:
+  - id: check
+    type: webhook
+    properties:
+      url: "%s/%s"
+`
+	actionOrders := fmt.Sprintf(actionTemplate, "orders", "tables/orders/**", ts.URL, "orders")
+	actionLogs := fmt.Sprintf(actionTemplate, "logs", "logs/**", ts.URL, "logs")
+
+	record := graveler.HookRecord{
+		RunID:            graveler.NewRunID(),
+		EventType:        graveler.EventTypePreCommit,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		SourceRef:        "main",
+		BranchID:         "main",
+	}
+	testOutputWriter := mock.NewMockOutputWriter(ctrl)
+	testOutputWriter.EXPECT().OutputWrite(ctx, "storageNamespace", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	testSource := mock.NewMockSource(ctrl)
+	testSource.EXPECT().List(ctx, record).Return([]string{"orders.yaml", "logs.yaml"}, nil)
+	testSource.EXPECT().Load(ctx, record, "orders.yaml").Return([]byte(actionOrders), nil)
+	testSource.EXPECT().Load(ctx, record, "logs.yaml").Return([]byte(actionLogs), nil)
+	// changed paths are walked once for all the actions
+	testSource.EXPECT().WalkChangedPaths(ctx, record, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ graveler.HookRecord, walkFn func(p string) error) error {
+			for _, p := range []string{"tables/customers/part-0", "tables/orders/_temporary/part-0", "tables/orders/part-0", "tables/orders/part-1"} {
+				if err := walkFn(p); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Times(1)
+
+	actionsService := GetKVService(t, ctx, testSource, testOutputWriter, &stats.NullCollector{}, true)
+	defer actionsService.Stop()
+
+	require.NoError(t, actionsService.Run(ctx, record))
+	require.Equal(t, []string{"tables/orders/part-0", "tables/orders/part-1"}, changedPaths)
+
+	runResult, err := actionsService.GetRunResult(ctx, record.RepositoryID.String(), record.RunID)
+	require.NoError(t, err)
+	require.True(t, runResult.Passed)
+	tasks, err := actionsService.ListRunTaskResults(ctx, record.RepositoryID.String(), record.RunID, "")
+	require.NoError(t, err)
+	defer tasks.Close()
+	var actionNames []string
+	for tasks.Next() {
+		actionNames = append(actionNames, tasks.Value().ActionName)
+	}
+	require.NoError(t, tasks.Err())
+	require.Equal(t, []string{"orders"}, actionNames)
+}
+
 func TestMissingEnvVar(t *testing.T) {
 	ctx := context.Background()
 	testOutputWriter, ctrl, _, record := setupTest(t)
@@ -9,4 +9,7 @@ import (
 type Source interface {
 	List(ctx context.Context, record graveler.HookRecord) ([]string, error)
 	Load(ctx context.Context, record graveler.HookRecord, name string) ([]byte, error)
+	// WalkChangedPaths calls walkFn for each path changed by the operation of the record, in order.
+	// Used to match actions with path filters, it is called at most once per event.
+	WalkChangedPaths(ctx context.Context, record graveler.HookRecord, walkFn func(p string) error) error
 }
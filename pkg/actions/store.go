@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/kv"
@@ -21,6 +24,24 @@ type Store interface {
 	GetTaskResult(ctx context.Context, repositoryID string, runID string, hookRunID string) (*TaskResult, error)
 	ListRunResults(ctx context.Context, repositoryID string, branchID, commitID string, after string) (RunResultIterator, error)
 	ListRunTaskResults(ctx context.Context, repositoryID string, runID string, after string) (TaskResultIterator, error)
+
+	// getPostHookJob returns a post event job and a predicate used to update it
+	getPostHookJob(ctx context.Context, repositoryID string, runID string) (*PostHookJobData, kv.Predicate, error)
+	// setPostHookJobIf saves a post event job if it wasn't updated since read by predicate, a nil predicate creates a new job
+	setPostHookJobIf(ctx context.Context, job *PostHookJobData, predicate kv.Predicate) error
+	setPostHookJob(ctx context.Context, job *PostHookJobData) error
+	// deletePostHookJobIf deletes a post event job if it wasn't updated since read by predicate
+	deletePostHookJobIf(ctx context.Context, repositoryID string, runID string, predicate kv.Predicate) error
+	// walkDuePostHookJobs calls walkFn, in due time order, for each post event job due until the given time. Jobs are
+	// indexed by the time of their next attempt, an index entry is stale when the job was updated since.
+	walkDuePostHookJobs(ctx context.Context, until time.Time, walkFn func(repositoryID, runID string, due time.Time) error) error
+	// deletePostHookJobDue deletes the index entry of a post event job due at the given time
+	deletePostHookJobDue(ctx context.Context, repositoryID string, runID string, due time.Time) error
+
+	getGlobalAction(ctx context.Context, name string) (*GlobalActionData, error)
+	setGlobalAction(ctx context.Context, action *GlobalActionData) error
+	deleteGlobalAction(ctx context.Context, name string) error
+	listGlobalActions(ctx context.Context) ([]*GlobalActionData, error)
 }
 
 type kvStore struct {
@@ -119,7 +140,8 @@ func (s *kvStore) saveRunManifest(ctx context.Context, repositoryID graveler.Rep
 	for i := range manifest.HooksRun {
 		hookRun := manifest.HooksRun[i]
 		taskKey := []byte(kv.FormatPath(TasksPath(repositoryID.String(), manifest.Run.RunID), hookRun.HookRunID))
-		err := kv.SetMsgIf(ctx, s.store, PartitionKey, taskKey, protoFromTaskResult(&hookRun), nil)
+		// tasks of a post event run are saved again when the run is retried
+		err := kv.SetMsg(ctx, s.store, PartitionKey, taskKey, protoFromTaskResult(&hookRun))
 		if err != nil {
 			return fmt.Errorf("save task result (runID: %s taskKey %s): %w", manifest.Run.RunID, taskKey, err)
 		}
@@ -156,3 +178,121 @@ func (s *kvStore) storeRun(ctx context.Context, run *RunResultData, repoID strin
 
 	return nil
 }
+
+func (s *kvStore) getPostHookJob(ctx context.Context, repositoryID string, runID string) (*PostHookJobData, kv.Predicate, error) {
+	job := &PostHookJobData{}
+	predicate, err := kv.GetMsg(ctx, s.store, PartitionKey, PostHookJobPath(repositoryID, runID), job)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			err = fmt.Errorf("post hooks job %s: %w", runID, ErrNotFound)
+		}
+		return nil, nil, err
+	}
+	return job, predicate, nil
+}
+
+func (s *kvStore) setPostHookJobIf(ctx context.Context, job *PostHookJobData, predicate kv.Predicate) error {
+	if err := s.setPostHookJobDue(ctx, job); err != nil {
+		return err
+	}
+	return kv.SetMsgIf(ctx, s.store, PartitionKey, PostHookJobPath(job.RepositoryId, job.RunId), job, predicate)
+}
+
+func (s *kvStore) setPostHookJob(ctx context.Context, job *PostHookJobData) error {
+	if err := s.setPostHookJobDue(ctx, job); err != nil {
+		return err
+	}
+	return kv.SetMsg(ctx, s.store, PartitionKey, PostHookJobPath(job.RepositoryId, job.RunId), job)
+}
+
+// setPostHookJobDue indexes the job by the time of its next attempt. The index is saved before the job, so a saved
+// job is always found when it is due.
+func (s *kvStore) setPostHookJobDue(ctx context.Context, job *PostHookJobData) error {
+	if job.Status == PostHookJobData_DEAD_LETTER {
+		return nil
+	}
+	dueKey := PostHookJobDuePath(job.RepositoryId, job.RunId, job.NextAttempt.AsTime())
+	err := kv.SetMsg(ctx, s.store, PartitionKey, dueKey, &kv.SecondaryIndex{PrimaryKey: PostHookJobPath(job.RepositoryId, job.RunId)})
+	if err != nil {
+		return fmt.Errorf("save post hooks job index (key %s): %w", dueKey, err)
+	}
+	return nil
+}
+
+func (s *kvStore) deletePostHookJobIf(ctx context.Context, repositoryID string, runID string, predicate kv.Predicate) error {
+	return kv.WriteBatch(ctx, s.store, []kv.BatchOp{
+		kv.DeleteIfOp([]byte(PartitionKey), PostHookJobPath(repositoryID, runID), predicate),
+	})
+}
+
+func (s *kvStore) deletePostHookJobDue(ctx context.Context, repositoryID string, runID string, due time.Time) error {
+	return s.store.Delete(ctx, []byte(PartitionKey), PostHookJobDuePath(repositoryID, runID, due))
+}
+
+func (s *kvStore) walkDuePostHookJobs(ctx context.Context, until time.Time, walkFn func(repositoryID, runID string, due time.Time) error) error {
+	prefix := kv.FormatPath(postHooksDuePrefix, "")
+	it, err := kv.NewPrimaryIterator(ctx, s.store, (&kv.SecondaryIndex{}).ProtoReflect().Type(), PartitionKey,
+		[]byte(prefix), kv.IteratorOptionsFrom([]byte("")))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		// the key is the due time, repository and run of the job
+		parts := strings.Split(strings.TrimPrefix(string(it.Entry().Key), prefix), kv.PathDelimiter)
+		const dueKeyParts = 3
+		if len(parts) != dueKeyParts {
+			return fmt.Errorf("%w: %s", errInvalidPostHookJobDueKey, it.Entry().Key)
+		}
+		dueNanos, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", errInvalidPostHookJobDueKey, it.Entry().Key, err)
+		}
+		due := time.Unix(0, dueNanos)
+		if due.After(until) {
+			break
+		}
+		if err := walkFn(parts[1], parts[2], due); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (s *kvStore) getGlobalAction(ctx context.Context, name string) (*GlobalActionData, error) {
+	action := &GlobalActionData{}
+	_, err := kv.GetMsg(ctx, s.store, PartitionKey, GlobalActionPath(name), action)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			err = fmt.Errorf("global action %s: %w", name, ErrNotFound)
+		}
+		return nil, err
+	}
+	return action, nil
+}
+
+func (s *kvStore) setGlobalAction(ctx context.Context, action *GlobalActionData) error {
+	return kv.SetMsg(ctx, s.store, PartitionKey, GlobalActionPath(action.Name), action)
+}
+
+func (s *kvStore) deleteGlobalAction(ctx context.Context, name string) error {
+	return s.store.Delete(ctx, []byte(PartitionKey), GlobalActionPath(name))
+}
+
+func (s *kvStore) listGlobalActions(ctx context.Context) ([]*GlobalActionData, error) {
+	it, err := kv.NewPrimaryIterator(ctx, s.store, (&GlobalActionData{}).ProtoReflect().Type(), PartitionKey,
+		[]byte(kv.FormatPath(globalActionsPrefix, "")), kv.IteratorOptionsFrom([]byte("")))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var actions []*GlobalActionData
+	for it.Next() {
+		action, ok := it.Entry().Value.(*GlobalActionData)
+		if !ok {
+			return nil, ErrNilValue
+		}
+		actions = append(actions, action)
+	}
+	return actions, it.Err()
+}
@@ -0,0 +1,4 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.commit("example-repo", "main", "daily export", {source = "hook"})
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.user)
+print(resp.body.message .. " " .. resp.body.metadata.source)
@@ -0,0 +1,6 @@
+local lakefs = require("lakefs")
+local json = require("encoding/json")
+local code, body = lakefs.create_branch("example-repo", "feature", "main")
+local resp = json.unmarshal(body)
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.user)
+print(resp.body.name .. " from " .. resp.body.source)
@@ -0,0 +1,4 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.delete_branch("example-repo", "feature")
+print(code)
+print(resp == nil)
@@ -0,0 +1,3 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.get_commit("example-repo", "c1")
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.user)
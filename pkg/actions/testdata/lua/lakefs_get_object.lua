@@ -0,0 +1,5 @@
+local lakefs = require("lakefs")
+local json = require("encoding/json")
+local code, body = lakefs.get_object("example-repo", "main", "data/file.csv")
+local resp = json.unmarshal(body)
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.query .. " " .. resp.user)
@@ -0,0 +1,3 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.get_repository_metadata("example-repo")
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.user)
@@ -0,0 +1,3 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.log_commits("example-repo", "main", "c1", 10, true)
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.query .. " " .. resp.user)
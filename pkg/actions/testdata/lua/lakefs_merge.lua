@@ -0,0 +1,4 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.merge("example-repo", "feature", "main", {message = "merge feature", strategy = "source-wins"})
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.user)
+print(resp.body.message .. " " .. resp.body.strategy)
@@ -0,0 +1,12 @@
+local lakefs = require("lakefs")
+local csv = require("encoding/csv")
+
+local code, obj = lakefs.open_object("example-repo", "main", "data/cities.csv")
+print(code .. " " .. obj:size())
+local records = csv.read(obj, {offset = 1, limit = 2})
+for _, r in ipairs(records) do
+    print(r.name .. " " .. r.population)
+end
+
+code, resp = lakefs.open_object("example-repo", "main", "data/missing.csv")
+print(code .. " " .. resp.message)
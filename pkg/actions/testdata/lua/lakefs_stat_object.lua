@@ -0,0 +1,3 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.stat_object("example-repo", "main", "data/file.csv", true)
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.query .. " " .. resp.user)
@@ -0,0 +1,18 @@
+local storage = require("lakefs/storage")
+
+storage.put_object("example-repo", "exports/report.csv", "a,b\n1,2\n")
+local data, exists = storage.get_object("example-repo", "exports/report.csv")
+print(tostring(exists) .. " " .. data)
+local _, found = storage.get_properties("example-repo", "exports/report.csv")
+print("found " .. tostring(found))
+
+storage.delete_object("example-repo", "exports/report.csv")
+data, exists = storage.get_object("example-repo", "exports/report.csv")
+print(tostring(exists) .. " '" .. data .. "'")
+
+storage.put_object("example-repo", "exports/kept", "kept")
+
+local ok, err = pcall(storage.put_object, "example-repo", "exports/../../_lakefs/dummy", "x")
+print(tostring(ok) .. " " .. err)
+ok, err = pcall(storage.get_object, "example-repo", "private/data")
+print(tostring(ok) .. " " .. err)
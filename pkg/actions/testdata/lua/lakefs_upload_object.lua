@@ -0,0 +1,4 @@
+local lakefs = require("lakefs")
+local code, resp = lakefs.upload_object("example-repo", "main", "reports/summary.txt", "all good")
+print(code .. " " .. resp.method .. " " .. resp.path .. " " .. resp.query .. " " .. resp.user)
+print(resp.body.filename .. ": " .. resp.body.content)
@@ -68,10 +68,11 @@ func NewWebhook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook,
 
 	return &Webhook{
 		HookBase: HookBase{
-			ID:         h.ID,
-			ActionName: action.Name,
-			Config:     cfg,
-			Endpoint:   e,
+			ID:           h.ID,
+			ActionName:   action.Name,
+			Config:       cfg,
+			Endpoint:     e,
+			ChangedPaths: action.ChangedPaths,
 		},
 		Timeout:     requestTimeout,
 		URL:         webhookURL,
@@ -80,25 +81,28 @@ func NewWebhook(h ActionHook, action *Action, cfg Config, e *http.Server) (Hook,
 	}, nil
 }
 
-func (w *Webhook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) (err error) {
+func (w *Webhook) Run(ctx context.Context, record graveler.HookRecord, buf *bytes.Buffer) error {
 	// post event information as json to webhook endpoint
 	logging.FromContext(ctx).
 		WithField("hook_type", "webhook").
 		WithField("event_type", record.EventType).
 		Debug("hook action executing")
 
-	eventData, err := marshalEventInformation(w.ActionName, w.ID, record)
+	eventData, err := marshalEventInformation(w.ActionName, w.ID, w.ChangedPaths, record)
 	if err != nil {
 		return err
 	}
+	return w.post(ctx, eventData, map[string]string{"Content-Type": "application/json"}, buf)
+}
 
+// post sends the body to the webhook endpoint with the webhook's query params and headers, and the additional headers
+func (w *Webhook) post(ctx context.Context, body []byte, headers map[string]string, buf *bytes.Buffer) error {
 	_, _ = fmt.Fprintf(buf, "Request:\n%s %s\n", http.MethodPost, w.URL)
-	reqReader := bytes.NewReader(eventData)
+	reqReader := bytes.NewReader(body)
 	req, err := http.NewRequest(http.MethodPost, w.URL, reqReader)
 	if err != nil {
 		return err
 	}
-	w.Headers["Content-Type"] = SecureString{val: "application/json"}
 
 	buf.WriteString("Query Params:\n")
 	q := req.URL.Query()
@@ -114,9 +118,13 @@ func (w *Webhook) Run(ctx context.Context, record graveler.HookRecord, buf *byte
 		req.Header.Add(k, v.val)
 		_, _ = fmt.Fprintf(buf, "%s: %s\n", k, v.String())
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+		_, _ = fmt.Fprintf(buf, "%s: %s\n", k, v)
+	}
 	req.URL.RawQuery = q.Encode()
 
-	_, _ = fmt.Fprintf(buf, "Request Body:\n%s\n\n", eventData)
+	_, _ = fmt.Fprintf(buf, "Request Body:\n%s\n\n", body)
 
 	statusCode, err := doHTTPRequestWithLog(ctx, req, buf, w.Timeout)
 	if err != nil {
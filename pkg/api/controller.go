@@ -79,6 +79,12 @@ type actionsHandler interface {
 	GetTaskResult(ctx context.Context, repositoryID, runID, hookRunID string) (*actions.TaskResult, error)
 	ListRunResults(ctx context.Context, repositoryID, branchID, commitID, after string) (actions.RunResultIterator, error)
 	ListRunTaskResults(ctx context.Context, repositoryID, runID, after string) (actions.TaskResultIterator, error)
+	RetryRun(ctx context.Context, repositoryID, runID string) error
+	DryRun(ctx context.Context, record graveler.HookRecord, content []byte, opts actions.DryRunOptions) (*actions.DryRunResult, error)
+	ListGlobalActions(ctx context.Context) ([]*actions.GlobalAction, error)
+	GetGlobalAction(ctx context.Context, name string) (*actions.GlobalAction, error)
+	SetGlobalAction(ctx context.Context, content []byte, enforced bool) (*actions.GlobalAction, error)
+	DeleteGlobalAction(ctx context.Context, name string) error
 }
 
 type Migrator interface {
@@ -1721,6 +1727,100 @@ func (c *Controller) GetRepositoryMetadata(w http.ResponseWriter, r *http.Reques
 	writeResponse(w, r, http.StatusOK, RepositoryMetadata{AdditionalProperties: metadata})
 }
 
+func (c *Controller) ForkRepository(w http.ResponseWriter, r *http.Request, body ForkRepositoryJSONRequestBody, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ReadRepositoryAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ReadObjectAction,
+					Resource: permissions.ObjectArn(repository, "*"),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateRepositoryAction,
+					Resource: permissions.RepoArn(body.Name),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.AttachStorageNamespaceAction,
+					Resource: permissions.StorageNamespace(body.StorageNamespace),
+				},
+			},
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "fork_repo", r, repository, "", "")
+
+	if err := c.validateStorageNamespace(body.StorageNamespace); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := c.Catalog.GetRepository(ctx, repository); c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	if err := c.ensureStorageNamespace(ctx, body.StorageNamespace); err != nil {
+		c.Logger.
+			WithError(err).
+			WithField("storage_namespace", body.StorageNamespace).
+			Warn("Could not access storage namespace")
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("failed to fork repository: %w", err))
+		return
+	}
+
+	forkID, err := c.Catalog.ForkRepository(ctx, repository, body.Name, body.StorageNamespace)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusAccepted, RepositoryForkCreationResponse{Id: forkID})
+}
+
+func (c *Controller) ForkRepositoryStatus(w http.ResponseWriter, r *http.Request, repository string, params ForkRepositoryStatusParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	status, err := c.Catalog.GetForkStatus(ctx, repository, params.Id)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	resp := RepositoryForkStatus{
+		Completed:  status.Completed,
+		UpdateTime: status.UpdatedAt,
+	}
+	if status.Error != nil {
+		resp.Error = &Error{Message: status.Error.Error()}
+	}
+	if status.Completed {
+		fork, err := c.Catalog.GetRepository(ctx, status.Repository)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		resp.Repository = &Repository{
+			CreationDate:     fork.CreationDate.Unix(),
+			DefaultBranch:    fork.DefaultBranch,
+			Id:               fork.Name,
+			StorageNamespace: fork.StorageNamespace,
+		}
+	}
+	writeResponse(w, r, http.StatusOK, resp)
+}
+
 func (c *Controller) ListRepositoryRuns(w http.ResponseWriter, r *http.Request, repository string, params ListRepositoryRunsParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -1774,18 +1874,25 @@ func (c *Controller) ListRepositoryRuns(w http.ResponseWriter, r *http.Request,
 
 func runResultToActionRun(val *actions.RunResult) ActionRun {
 	runResult := ActionRun{
-		Branch:    val.BranchID,
-		CommitId:  val.CommitID,
-		RunId:     val.RunID,
-		StartTime: val.StartTime,
-		EndTime:   &val.EndTime,
-		EventType: val.EventType,
+		Branch:      val.BranchID,
+		CommitId:    val.CommitID,
+		RunId:       val.RunID,
+		StartTime:   val.StartTime,
+		EndTime:     &val.EndTime,
+		EventType:   val.EventType,
+		NextAttempt: val.NextAttempt,
 	}
 	if val.Passed {
 		runResult.Status = actionStatusCompleted
 	} else {
 		runResult.Status = actionStatusFailed
 	}
+	if val.Attempts > 0 {
+		runResult.Attempts = swag.Int(val.Attempts)
+	}
+	if val.DeadLetter {
+		runResult.DeadLetter = swag.Bool(true)
+	}
 	return runResult
 }
 
@@ -1810,20 +1917,133 @@ func (c *Controller) GetRun(w http.ResponseWriter, r *http.Request, repository,
 		return
 	}
 
-	var status string
-	if runResult.Passed {
-		status = actionStatusCompleted
-	} else {
-		status = actionStatusFailed
+	response := runResultToActionRun(runResult)
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func (c *Controller) RetryRun(w http.ResponseWriter, r *http.Request, repository, runID string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.RetryActionsRunAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "actions_retry_run", r, repository, "", "")
+	_, err := c.Catalog.GetRepository(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	err = c.Actions.RetryRun(ctx, repository, runID)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusAccepted, nil)
+}
+
+func (c *Controller) TestAction(w http.ResponseWriter, r *http.Request, body TestActionJSONRequestBody, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.TestActionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "actions_test", r, repository, swag.StringValue(body.Branch), "")
+	repo, err := c.Catalog.GetRepository(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	branch := swag.StringValue(body.Branch)
+	record := graveler.HookRecord{
+		EventType:        graveler.EventType(body.EventType),
+		RepositoryID:     graveler.RepositoryID(repo.Name),
+		StorageNamespace: graveler.StorageNamespace(repo.StorageNamespace),
+		BranchID:         graveler.BranchID(branch),
+		SourceRef:        graveler.Ref(branch),
+	}
+	if branch != "" {
+		_, err := c.Catalog.GetBranchReference(ctx, repository, branch)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+	}
+	if commitID := swag.StringValue(body.CommitId); commitID != "" {
+		commit, err := c.Catalog.GetCommit(ctx, repository, commitID)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		parents := make(graveler.CommitParents, 0, len(commit.Parents))
+		for _, p := range commit.Parents {
+			parents = append(parents, graveler.CommitID(p))
+		}
+		record.CommitID = graveler.CommitID(commit.Reference)
+		record.SourceRef = graveler.Ref(commit.Reference)
+		record.Commit = graveler.Commit{
+			Committer:    commit.Committer,
+			Message:      commit.Message,
+			MetaRangeID:  graveler.MetaRangeID(commit.MetaRangeID),
+			CreationDate: commit.CreationDate,
+			Parents:      parents,
+			Metadata:     graveler.Metadata(commit.Metadata),
+		}
+	}
+	if record.SourceRef == "" {
+		writeError(w, r, http.StatusBadRequest, "branch or commit_id is required")
+		return
+	}
+
+	result, err := c.Actions.DryRun(ctx, record, []byte(body.Content), actions.DryRunOptions{
+		ExternalHooks: swag.BoolValue(body.ExternalHooks),
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := ActionTestResult{
+		RunId:     result.RunID,
+		EventType: result.EventType,
+		Matched:   result.Matched,
+		Status:    actionStatusCompleted,
+		Hooks:     make([]ActionTestHookResult, 0, len(result.Hooks)),
 	}
-	response := ActionRun{
-		RunId:     runResult.RunID,
-		EventType: runResult.EventType,
-		StartTime: runResult.StartTime,
-		EndTime:   &runResult.EndTime,
-		Status:    status,
-		Branch:    runResult.BranchID,
-		CommitId:  runResult.CommitID,
+	if result.BranchID != "" {
+		response.Branch = swag.String(result.BranchID)
+	}
+	if result.CommitID != "" {
+		response.CommitId = swag.String(result.CommitID)
+	}
+	if !result.Passed && result.Matched {
+		response.Status = actionStatusFailed
+	}
+	for _, h := range result.Hooks {
+		hook := ActionTestHookResult{
+			HookRunId: h.HookRunID,
+			Action:    h.ActionName,
+			HookId:    h.HookID,
+			Output:    string(h.Output),
+		}
+		switch {
+		case h.Skipped:
+			hook.Status = actionStatusSkipped
+		case h.Passed:
+			hook.Status = actionStatusCompleted
+		default:
+			hook.Status = actionStatusFailed
+		}
+		if !h.StartTime.IsZero() {
+			hook.StartTime = swag.Time(h.StartTime)
+			hook.EndTime = swag.Time(h.EndTime)
+		}
+		if h.Error != "" {
+			hook.Error = swag.String(h.Error)
+		}
+		response.Hooks = append(response.Hooks, hook)
 	}
 	writeResponse(w, r, http.StatusOK, response)
 }
@@ -1942,6 +2162,107 @@ func (c *Controller) GetRunHookOutput(w http.ResponseWriter, r *http.Request, re
 	}
 }
 
+func serializeGlobalAction(a *actions.GlobalAction) GlobalAction {
+	return GlobalAction{
+		Name:         a.Name,
+		Content:      string(a.Content),
+		Enforced:     a.Enforced,
+		Configured:   a.Configured,
+		CreationDate: a.CreationDate,
+	}
+}
+
+func (c *Controller) ListGlobalActions(w http.ResponseWriter, r *http.Request) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ListGlobalActionsAction,
+			Resource: permissions.All,
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_global_actions", r, "", "", "")
+
+	globalActions, err := c.Actions.ListGlobalActions(ctx)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := GlobalActionList{
+		Results: make([]GlobalAction, 0, len(globalActions)),
+	}
+	for _, a := range globalActions {
+		response.Results = append(response.Results, serializeGlobalAction(a))
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func (c *Controller) GetGlobalAction(w http.ResponseWriter, r *http.Request, action string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.GetGlobalActionAction,
+			Resource: permissions.All,
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_global_action", r, "", "", "")
+
+	globalAction, err := c.Actions.GetGlobalAction(ctx, action)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, serializeGlobalAction(globalAction))
+}
+
+func (c *Controller) SetGlobalAction(w http.ResponseWriter, r *http.Request, body SetGlobalActionJSONRequestBody, action string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.SetGlobalActionAction,
+			Resource: permissions.All,
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "set_global_action", r, "", "", "")
+
+	parsed, err := actions.ParseAction([]byte(body.Content))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if parsed.Name != action {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("action name '%s' does not match the path", parsed.Name))
+		return
+	}
+	globalAction, err := c.Actions.SetGlobalAction(ctx, []byte(body.Content), swag.BoolValue(body.Enforced))
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, serializeGlobalAction(globalAction))
+}
+
+func (c *Controller) DeleteGlobalAction(w http.ResponseWriter, r *http.Request, action string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.DeleteGlobalActionAction,
+			Resource: permissions.All,
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "delete_global_action", r, "", "", "")
+
+	err := c.Actions.DeleteGlobalAction(ctx, action)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
 func (c *Controller) ListBranches(w http.ResponseWriter, r *http.Request, repository string, params ListBranchesParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -2073,6 +2394,10 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 		errors.Is(err, model.ErrValidationError),
 		errors.Is(err, graveler.ErrInvalidRef),
 		errors.Is(err, actions.ErrParamConflict),
+		errors.Is(err, actions.ErrRunNotRetryable),
+		errors.Is(err, actions.ErrInvalidAction),
+		errors.Is(err, actions.ErrInvalidEventParameter),
+		errors.Is(err, actions.ErrActionsDisabled),
 		errors.Is(err, graveler.ErrDereferenceCommitWithStaging),
 		errors.Is(err, graveler.ErrParentOutOfRange),
 		errors.Is(err, graveler.ErrCherryPickMergeNoParent),
@@ -2084,7 +2409,9 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 
 	case errors.Is(err, graveler.ErrNotUnique),
 		errors.Is(err, graveler.ErrConflictFound),
-		errors.Is(err, graveler.ErrRevertMergeNoParent):
+		errors.Is(err, graveler.ErrRevertMergeNoParent),
+		errors.Is(err, actions.ErrRunInProgress),
+		errors.Is(err, actions.ErrGlobalActionConfigured):
 		log.Debug("Conflict")
 		cb(w, r, http.StatusConflict, err)
 
@@ -2206,11 +2533,24 @@ func (c *Controller) ImportStart(w http.ResponseWriter, r *http.Request, body Im
 		if c.handleAPIError(ctx, w, r, err) {
 			return
 		}
-		paths = append(paths, catalog.ImportPath{
+		importPath := catalog.ImportPath{
 			Destination: p.Destination,
 			Path:        p.Path,
 			Type:        pathType,
-		})
+		}
+		if p.Include != nil {
+			importPath.Include = *p.Include
+		}
+		if p.Exclude != nil {
+			importPath.Exclude = *p.Exclude
+		}
+		if p.Remap != nil {
+			importPath.Remap = &catalog.ImportRemap{
+				Pattern:     p.Remap.Pattern,
+				Replacement: p.Remap.Replacement,
+			}
+		}
+		paths = append(paths, importPath)
 	}
 
 	committer := user.Username
@@ -2299,6 +2639,108 @@ func (c *Controller) ImportCancel(w http.ResponseWriter, r *http.Request, reposi
 	writeResponse(w, r, http.StatusNoContent, nil)
 }
 
+func (c *Controller) ExportStart(w http.ResponseWriter, r *http.Request, body ExportStartJSONRequestBody, repository, ref string) {
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ListObjectsAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ReadObjectAction,
+					Resource: permissions.ObjectArn(repository, "*"),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ExportToStorageAction,
+					Resource: permissions.StorageNamespace(body.Destination),
+				},
+			},
+		},
+	}) {
+		return
+	}
+
+	ctx := r.Context()
+	c.LogAction(ctx, "export", r, repository, ref, "")
+	if err := c.validateStorageNamespace(body.Destination); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	exportID, err := c.Catalog.Export(ctx, repository, catalog.ExportRequest{
+		Ref:         ref,
+		Destination: body.Destination,
+		Incremental: swag.BoolValue(body.Incremental),
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusAccepted, ExportCreationResponse{
+		Id: exportID,
+	})
+}
+
+func exportStatusToResponse(status *catalog.ExportStatus) ExportStatusResp {
+	resp := ExportStatusResp{
+		Completed:       status.Completed,
+		UpdateTime:      status.UpdatedAt,
+		CommitId:        status.CommitID,
+		Destination:     status.Destination,
+		ExportedObjects: &status.Progress,
+		Ref:             swag.String(status.Ref),
+	}
+	if status.PreviousCommitID != "" {
+		resp.PreviousCommitId = swag.String(status.PreviousCommitID)
+	}
+	if status.Manifest != "" {
+		resp.Manifest = swag.String(status.Manifest)
+	}
+	if status.Error != nil {
+		resp.Error = &Error{Message: status.Error.Error()}
+	}
+	return resp
+}
+
+func (c *Controller) ExportStatus(w http.ResponseWriter, r *http.Request, repository, ref string, params ExportStatusParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	status, err := c.Catalog.GetExportStatus(ctx, repository, params.Id)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, exportStatusToResponse(status))
+}
+
+func (c *Controller) ExportCancel(w http.ResponseWriter, r *http.Request, repository, ref string, params ExportCancelParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ExportCancelAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "cancel_export", r, repository, ref, "")
+	err := c.Catalog.CancelExport(ctx, repository, params.Id)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
 func (c *Controller) IngestRange(w http.ResponseWriter, r *http.Request, body IngestRangeJSONRequestBody, repository string) {
 	if !c.authorize(w, r, permissions.Node{
 		Type: permissions.NodeTypeAnd,
@@ -2454,6 +2896,78 @@ func commitResponse(w http.ResponseWriter, r *http.Request, newCommit *catalog.C
 	writeResponse(w, r, http.StatusCreated, response)
 }
 
+func (c *Controller) RebalanceBranch(w http.ResponseWriter, r *http.Request, body RebalanceBranchJSONRequestBody, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.CreateCommitAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "rebalance_branch", r, repository, branch, "")
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, "missing user")
+		return
+	}
+	message := "Rebalance ranges"
+	if body.Message != nil {
+		message = *body.Message
+	}
+	var metadata map[string]string
+	if body.Metadata != nil {
+		metadata = body.Metadata.AdditionalProperties
+	}
+	rebalanceID, err := c.Catalog.Rebalance(ctx, repository, branch, message, user.Username, metadata)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusAccepted, RebalanceCreationResponse{
+		Id: rebalanceID,
+	})
+}
+
+func (c *Controller) RebalanceStatus(w http.ResponseWriter, r *http.Request, repository, branch string, params RebalanceStatusParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadBranchAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	status, err := c.Catalog.GetRebalanceStatus(ctx, repository, params.Id)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	resp := RebalanceStatusResp{
+		Completed:  status.Completed,
+		UpdateTime: status.UpdatedAt,
+	}
+	if status.Error != nil {
+		resp.Error = &Error{Message: status.Error.Error()}
+	}
+	if status.CommitID != "" {
+		commitLog, err := c.Catalog.GetCommit(ctx, repository, status.CommitID)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		resp.Commit = &Commit{
+			Committer:    commitLog.Committer,
+			CreationDate: commitLog.CreationDate.Unix(),
+			Id:           commitLog.Reference,
+			Message:      commitLog.Message,
+			MetaRangeId:  commitLog.MetaRangeID,
+			Metadata:     &Commit_Metadata{AdditionalProperties: commitLog.Metadata},
+			Parents:      commitLog.Parents,
+		}
+	}
+	writeResponse(w, r, http.StatusOK, resp)
+}
+
 func (c *Controller) DiffBranch(w http.ResponseWriter, r *http.Request, repository, branch string, params DiffBranchParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -2934,6 +3448,42 @@ func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repositor
 	writeResponse(w, r, http.StatusOK, response)
 }
 
+func (c *Controller) GetCommitRangeStats(w http.ResponseWriter, r *http.Request, repository, commitID string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadCommitAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_commit_range_stats", r, repository, commitID, "")
+	stats, err := c.Catalog.GetRangeStats(ctx, repository, commitID)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := RangeStats{
+		MetaRangeId: stats.MetaRangeID,
+		RangeCount:  stats.RangeCount,
+		RecordCount: stats.RecordCount,
+		SizeBytes:   distributionResponse(stats.SizeBytes),
+		Records:     distributionResponse(stats.Records),
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func distributionResponse(d catalog.Distribution) Distribution {
+	return Distribution{
+		Min:  d.Min,
+		Max:  d.Max,
+		Mean: d.Mean,
+		P50:  d.P50,
+		P90:  d.P90,
+		P99:  d.P99,
+	}
+}
+
 func (c *Controller) GetGarbageCollectionRules(w http.ResponseWriter, r *http.Request, repository string) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -3122,6 +3672,159 @@ func (c *Controller) CreateBranchProtectionRule(w http.ResponseWriter, r *http.R
 	writeResponse(w, r, http.StatusNoContent, nil)
 }
 
+func branchReplicationToResponse(status *catalog.ReplicationStatus) BranchReplication {
+	resp := BranchReplication{
+		SourceBranch:      status.SourceBranch,
+		TargetRepository:  status.TargetRepository,
+		TargetBranch:      status.TargetBranch,
+		CreationDate:      status.CreatedAt,
+		SourceCommitId:    status.SourceCommitID,
+		ReplicatedCommits: status.ReplicatedCommits,
+		ReplicatedObjects: status.ReplicatedObjects,
+		LagSeconds:        status.Lag.Seconds(),
+		UpdateTime:        status.UpdatedAt,
+	}
+	if status.LastCommitID != "" {
+		resp.LastCommitId = swag.String(status.LastCommitID)
+		resp.LastReplicationTime = &status.LastReplicatedAt
+	}
+	if status.Error != nil {
+		resp.Error = &Error{Message: status.Error.Error()}
+	}
+	return resp
+}
+
+func (c *Controller) GetBranchReplication(w http.ResponseWriter, r *http.Request, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.GetBranchReplicationAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_branch_replication", r, repository, branch, "")
+	status, err := c.Catalog.GetBranchReplication(ctx, repository, branch)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, branchReplicationToResponse(status))
+}
+
+func (c *Controller) SetBranchReplication(w http.ResponseWriter, r *http.Request, body SetBranchReplicationJSONRequestBody, repository, branch string) {
+	// replication writes to the target repository, require permission on both repositories
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.SetBranchReplicationAction,
+					Resource: permissions.BranchArn(repository, branch),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.SetBranchReplicationAction,
+					Resource: permissions.RepoArn(body.TargetRepository),
+				},
+			},
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "set_branch_replication", r, repository, branch, "")
+	status, err := c.Catalog.SetBranchReplication(ctx, repository, branch, catalog.ReplicationTarget{
+		Repository: body.TargetRepository,
+		Branch:     swag.StringValue(body.TargetBranch),
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, branchReplicationToResponse(status))
+}
+
+func (c *Controller) DeleteBranchReplication(w http.ResponseWriter, r *http.Request, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.SetBranchReplicationAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "delete_branch_replication", r, repository, branch, "")
+	err := c.Catalog.DeleteBranchReplication(ctx, repository, branch)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
+func (c *Controller) GetBranchCompaction(w http.ResponseWriter, r *http.Request, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.GetBranchCompactionAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_branch_compaction", r, repository, branch, "")
+	compaction, err := c.Catalog.GetBranchCompaction(ctx, repository, branch)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, branchCompactionToResponse(compaction))
+}
+
+func (c *Controller) SetBranchCompaction(w http.ResponseWriter, r *http.Request, body SetBranchCompactionJSONRequestBody, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.SetBranchCompactionAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "set_branch_compaction", r, repository, branch, "")
+	compaction, err := c.Catalog.SetBranchCompaction(ctx, repository, branch, body.StagedWritesThreshold)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, branchCompactionToResponse(compaction))
+}
+
+func (c *Controller) DeleteBranchCompaction(w http.ResponseWriter, r *http.Request, repository, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.SetBranchCompactionAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "delete_branch_compaction", r, repository, branch, "")
+	err := c.Catalog.DeleteBranchCompaction(ctx, repository, branch)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
+func branchCompactionToResponse(compaction *catalog.BranchCompaction) BranchCompaction {
+	return BranchCompaction{
+		Enabled:               compaction.Enabled,
+		StagedWritesThreshold: compaction.StagedWritesThreshold,
+		Override:              compaction.Override,
+	}
+}
+
 func (c *Controller) GetMetaRange(w http.ResponseWriter, r *http.Request, repository, metaRange string) {
 	if !c.authorize(w, r, permissions.Node{
 		Type: permissions.NodeTypeAnd,
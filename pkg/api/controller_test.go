@@ -3,6 +3,7 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"text/template"
 	"time"
@@ -45,6 +47,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/testutil"
 	"github.com/treeverse/lakefs/pkg/upload"
 	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -1968,6 +1971,398 @@ func TestController_IngestRangeHandler(t *testing.T) {
 	})
 }
 
+func TestController_ExportHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+
+	commitObjects := func(t *testing.T, upload map[string]string, remove []string) {
+		t.Helper()
+		for p, content := range upload {
+			resp, err := uploadObjectHelper(t, ctx, clt, p, strings.NewReader(content), repo, "main")
+			verifyResponseOK(t, resp, err)
+		}
+		for _, p := range remove {
+			resp, err := clt.DeleteObjectWithResponse(ctx, repo, "main", &api.DeleteObjectParams{Path: p})
+			verifyResponseOK(t, resp, err)
+		}
+		resp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "commit"})
+		verifyResponseOK(t, resp, err)
+	}
+
+	destination := onBlock(deps, "export-"+repo)
+	export := func(t *testing.T, incremental bool) *api.ExportStatusResp {
+		t.Helper()
+		resp, err := clt.ExportStartWithResponse(ctx, repo, "main", api.ExportStartJSONRequestBody{
+			Destination: destination,
+			Incremental: swag.Bool(incremental),
+		})
+		verifyResponseOK(t, resp, err)
+		var status *api.ExportStatusResp
+		require.Eventually(t, func() bool {
+			statusResp, err := clt.ExportStatusWithResponse(ctx, repo, "main", &api.ExportStatusParams{Id: resp.JSON202.Id})
+			require.NoError(t, err)
+			require.NotNil(t, statusResp.JSON200)
+			require.Nil(t, statusResp.JSON200.Error)
+			status = statusResp.JSON200
+			return status.Completed
+		}, 10*time.Second, 100*time.Millisecond)
+		return status
+	}
+	readExported := func(t *testing.T, p string) (string, bool) {
+		t.Helper()
+		obj := block.ObjectPointer{StorageNamespace: destination, Identifier: p, IdentifierType: block.IdentifierTypeRelative}
+		exists, err := deps.blocks.Exists(ctx, obj)
+		require.NoError(t, err)
+		if !exists {
+			return "", false
+		}
+		reader, err := deps.blocks.Get(ctx, obj, -1)
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data), true
+	}
+
+	t.Run("full", func(t *testing.T) {
+		commitObjects(t, map[string]string{"a/one": "one", "a/two": "two", "a/_SUCCESS": ""}, nil)
+		status := export(t, true)
+		require.Nil(t, status.PreviousCommitId)
+		require.EqualValues(t, 3, swag.Int64Value(status.ExportedObjects))
+		require.NotNil(t, status.Manifest)
+		for p, expected := range map[string]string{"a/one": "one", "a/two": "two", "a/_SUCCESS": ""} {
+			data, ok := readExported(t, p)
+			require.True(t, ok, "exported object %s", p)
+			require.Equal(t, expected, data)
+		}
+		_, ok := readExported(t, *status.Manifest)
+		require.True(t, ok, "manifest")
+		_, ok = readExported(t, strings.TrimSuffix(*status.Manifest, "MANIFEST")+"SUCCESS")
+		require.True(t, ok, "success marker")
+	})
+
+	t.Run("incremental", func(t *testing.T) {
+		commitObjects(t, map[string]string{"a/three": "three", "a/one": "uno"}, []string{"a/two"})
+		status := export(t, true)
+		require.NotNil(t, status.PreviousCommitId)
+		require.EqualValues(t, 3, swag.Int64Value(status.ExportedObjects))
+		for p, expected := range map[string]string{"a/one": "uno", "a/three": "three"} {
+			data, ok := readExported(t, p)
+			require.True(t, ok, "exported object %s", p)
+			require.Equal(t, expected, data)
+		}
+		_, ok := readExported(t, "a/two")
+		require.False(t, ok, "removed object a/two")
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		resp, err := clt.ExportStartWithResponse(ctx, repo, "main", api.ExportStartJSONRequestBody{
+			Destination: "invalid://destination",
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("status not found", func(t *testing.T) {
+		resp, err := clt.ExportStatusWithResponse(ctx, repo, "main", &api.ExportStatusParams{Id: "not-exists"})
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON404)
+	})
+}
+
+func TestController_BranchReplicationHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+	replica := testUniqueRepoName()
+	_, err = deps.catalog.CreateRepository(ctx, replica, onBlock(deps, replica), "main")
+	testutil.Must(t, err)
+
+	commitObjects := func(t *testing.T, upload map[string]string) string {
+		t.Helper()
+		for p, content := range upload {
+			resp, err := uploadObjectHelper(t, ctx, clt, p, strings.NewReader(content), repo, "main")
+			verifyResponseOK(t, resp, err)
+		}
+		resp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "commit"})
+		verifyResponseOK(t, resp, err)
+		return resp.JSON201.Id
+	}
+	waitForReplication := func(t *testing.T, commitID string) *api.BranchReplication {
+		t.Helper()
+		var status *api.BranchReplication
+		require.Eventually(t, func() bool {
+			resp, err := clt.GetBranchReplicationWithResponse(ctx, repo, "main")
+			require.NoError(t, err)
+			require.NotNil(t, resp.JSON200)
+			require.Nil(t, resp.JSON200.Error)
+			status = resp.JSON200
+			return swag.StringValue(status.LastCommitId) == commitID
+		}, 10*time.Second, 100*time.Millisecond)
+		return status
+	}
+	verifyReplica := func(t *testing.T, commitID string, objects map[string]string) {
+		t.Helper()
+		branchResp, err := clt.GetBranchWithResponse(ctx, replica, "replica")
+		verifyResponseOK(t, branchResp, err)
+		require.Equal(t, commitID, branchResp.JSON200.CommitId)
+		for p, expected := range objects {
+			resp, err := clt.GetObjectWithResponse(ctx, replica, "replica", &api.GetObjectParams{Path: p})
+			verifyResponseOK(t, resp, err)
+			require.Equal(t, expected, string(resp.Body))
+		}
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		resp, err := clt.GetBranchReplicationWithResponse(ctx, repo, "main")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("same repository", func(t *testing.T) {
+		resp, err := clt.SetBranchReplicationWithResponse(ctx, repo, "main", api.SetBranchReplicationJSONRequestBody{
+			TargetRepository: repo,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	objects := map[string]string{"a/one": "one", "a/two": "two"}
+	t.Run("initial", func(t *testing.T) {
+		commitID := commitObjects(t, objects)
+		resp, err := clt.SetBranchReplicationWithResponse(ctx, repo, "main", api.SetBranchReplicationJSONRequestBody{
+			TargetRepository: replica,
+			TargetBranch:     swag.String("replica"),
+		})
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, replica, resp.JSON200.TargetRepository)
+		require.Equal(t, "replica", resp.JSON200.TargetBranch)
+		status := waitForReplication(t, commitID)
+		require.Equal(t, commitID, status.SourceCommitId)
+		require.Zero(t, status.LagSeconds)
+		require.EqualValues(t, 2, status.ReplicatedObjects)
+		verifyReplica(t, commitID, objects)
+	})
+
+	t.Run("on commit", func(t *testing.T) {
+		objects["a/two"] = "two updated"
+		objects["b/three"] = "three"
+		commitID := commitObjects(t, map[string]string{"a/two": "two updated", "b/three": "three"})
+		waitForReplication(t, commitID)
+		verifyReplica(t, commitID, objects)
+	})
+
+	t.Run("on merge", func(t *testing.T) {
+		branchResp, err := clt.CreateBranchWithResponse(ctx, repo, api.CreateBranchJSONRequestBody{Name: "feature", Source: "main"})
+		verifyResponseOK(t, branchResp, err)
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "c/four", strings.NewReader("four"), repo, "feature")
+		verifyResponseOK(t, uploadResp, err)
+		commitResp, err := clt.CommitWithResponse(ctx, repo, "feature", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "feature"})
+		verifyResponseOK(t, commitResp, err)
+		mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repo, "feature", "main", api.MergeIntoBranchJSONRequestBody{})
+		verifyResponseOK(t, mergeResp, err)
+		objects["c/four"] = "four"
+		waitForReplication(t, mergeResp.JSON200.Reference)
+		verifyReplica(t, mergeResp.JSON200.Reference, objects)
+	})
+
+	t.Run("on revert", func(t *testing.T) {
+		revertResp, err := clt.RevertBranchWithResponse(ctx, repo, "main", api.RevertBranchJSONRequestBody{Ref: "main", ParentNumber: 1})
+		verifyResponseOK(t, revertResp, err)
+		branchResp, err := clt.GetBranchWithResponse(ctx, repo, "main")
+		verifyResponseOK(t, branchResp, err)
+		delete(objects, "c/four")
+		waitForReplication(t, branchResp.JSON200.CommitId)
+		verifyReplica(t, branchResp.JSON200.CommitId, objects)
+	})
+
+	t.Run("on cherry-pick", func(t *testing.T) {
+		cherryPickResp, err := clt.CherryPickWithResponse(ctx, repo, "main", api.CherryPickJSONRequestBody{Ref: "feature"})
+		verifyResponseOK(t, cherryPickResp, err)
+		objects["c/four"] = "four"
+		waitForReplication(t, cherryPickResp.JSON201.Id)
+		verifyReplica(t, cherryPickResp.JSON201.Id, objects)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp, err := clt.DeleteBranchReplicationWithResponse(ctx, repo, "main")
+		verifyResponseOK(t, resp, err)
+		getResp, err := clt.GetBranchReplicationWithResponse(ctx, repo, "main")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, getResp.StatusCode())
+	})
+}
+
+func TestController_ForkRepositoryHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+	objects := map[string]string{"a/one": "one", "a/two": "two", "b/three": "three"}
+	for p, content := range objects {
+		resp, err := uploadObjectHelper(t, ctx, clt, p, strings.NewReader(content), repo, "main")
+		verifyResponseOK(t, resp, err)
+	}
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "objects"})
+	verifyResponseOK(t, commitResp, err)
+	tagResp, err := clt.CreateTagWithResponse(ctx, repo, api.CreateTagJSONRequestBody{Id: "v1", Ref: "main"})
+	verifyResponseOK(t, tagResp, err)
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repo, api.CreateBranchJSONRequestBody{Name: "feature", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+
+	// a commit that is not reachable from any branch of the source repository
+	danglingResp, err := clt.CreateBranchWithResponse(ctx, repo, api.CreateBranchJSONRequestBody{Name: "dangling", Source: "main"})
+	verifyResponseOK(t, danglingResp, err)
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "c/four", strings.NewReader("four"), repo, "dangling")
+	verifyResponseOK(t, uploadResp, err)
+	danglingCommitResp, err := clt.CommitWithResponse(ctx, repo, "dangling", &api.CommitParams{}, api.CommitJSONRequestBody{
+		Message: "dangling",
+		Date:    swag.Int64(time.Now().AddDate(0, 0, -7).Unix()),
+	})
+	verifyResponseOK(t, danglingCommitResp, err)
+	danglingCommitID := danglingCommitResp.JSON201.Id
+	deleteBranchResp, err := clt.DeleteBranchWithResponse(ctx, repo, "dangling")
+	verifyResponseOK(t, deleteBranchResp, err)
+
+	rulesResp, err := clt.SetGarbageCollectionRulesWithResponse(ctx, repo, api.SetGarbageCollectionRulesJSONRequestBody{
+		Branches: []api.GarbageCollectionRule{}, DefaultRetentionDays: 1,
+	})
+	verifyResponseOK(t, rulesResp, err)
+	// expiredCommits returns the expired commits found by garbage collection of the source repository
+	expiredCommits := func(t *testing.T) map[string]bool {
+		t.Helper()
+		resp, err := clt.PrepareGarbageCollectionCommitsWithResponse(ctx, repo, api.PrepareGarbageCollectionCommitsJSONRequestBody{})
+		verifyResponseOK(t, resp, err)
+		reader, err := deps.blocks.Get(ctx, block.ObjectPointer{
+			Identifier:     resp.JSON201.GcCommitsLocation,
+			IdentifierType: block.IdentifierTypeFull,
+		}, -1)
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+		records, err := csv.NewReader(reader).ReadAll()
+		require.NoError(t, err)
+		expired := make(map[string]bool)
+		for _, record := range records[1:] {
+			expired[record[0]] = record[1] == "true"
+		}
+		return expired
+	}
+	require.True(t, expiredCommits(t)[danglingCommitID], "dangling commit should be expired before fork")
+
+	fork := testUniqueRepoName()
+	t.Run("fork", func(t *testing.T) {
+		resp, err := clt.ForkRepositoryWithResponse(ctx, repo, api.ForkRepositoryJSONRequestBody{
+			Name:             fork,
+			StorageNamespace: onBlock(deps, fork),
+		})
+		verifyResponseOK(t, resp, err)
+		var status *api.RepositoryForkStatus
+		require.Eventually(t, func() bool {
+			statusResp, err := clt.ForkRepositoryStatusWithResponse(ctx, repo, &api.ForkRepositoryStatusParams{Id: resp.JSON202.Id})
+			require.NoError(t, err)
+			require.NotNil(t, statusResp.JSON200)
+			status = statusResp.JSON200
+			return status.Completed || status.Error != nil
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Nil(t, status.Error)
+		require.NotNil(t, status.Repository)
+		require.Equal(t, fork, status.Repository.Id)
+		require.Equal(t, "main", status.Repository.DefaultBranch)
+
+		for _, ref := range []string{"main", "feature", "v1"} {
+			for p, expected := range objects {
+				objResp, err := clt.GetObjectWithResponse(ctx, fork, ref, &api.GetObjectParams{Path: p})
+				verifyResponseOK(t, objResp, err)
+				require.Equal(t, expected, string(objResp.Body), "ref %s path %s", ref, p)
+			}
+		}
+		logResp, err := clt.LogCommitsWithResponse(ctx, fork, "main", &api.LogCommitsParams{})
+		verifyResponseOK(t, logResp, err)
+		require.Len(t, logResp.JSON200.Results, 2)
+		require.Equal(t, "objects", logResp.JSON200.Results[0].Message)
+	})
+
+	t.Run("existing repository", func(t *testing.T) {
+		resp, err := clt.ForkRepositoryWithResponse(ctx, repo, api.ForkRepositoryJSONRequestBody{
+			Name:             fork,
+			StorageNamespace: onBlock(deps, fork+"-other"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusConflict, resp.StatusCode())
+	})
+
+	t.Run("source not found", func(t *testing.T) {
+		other := testUniqueRepoName()
+		resp, err := clt.ForkRepositoryWithResponse(ctx, "no-such-repo", api.ForkRepositoryJSONRequestBody{
+			Name:             other,
+			StorageNamespace: onBlock(deps, other),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("status not found", func(t *testing.T) {
+		resp, err := clt.ForkRepositoryStatusWithResponse(ctx, repo, &api.ForkRepositoryStatusParams{Id: "not-exists"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("garbage collection", func(t *testing.T) {
+		require.False(t, expiredCommits(t)[danglingCommitID], "dangling commit should be retained by the fork")
+
+		resp, err := clt.DeleteRepositoryWithResponse(ctx, fork)
+		verifyResponseOK(t, resp, err)
+		require.True(t, expiredCommits(t)[danglingCommitID], "dangling commit should be expired after the fork is deleted")
+	})
+}
+
+func TestController_BranchCompaction(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+
+	getResp, err := clt.GetBranchCompactionWithResponse(ctx, repo, "main")
+	verifyResponseOK(t, getResp, err)
+	require.False(t, getResp.JSON200.Override)
+	defaultThreshold := getResp.JSON200.StagedWritesThreshold
+
+	setResp, err := clt.SetBranchCompactionWithResponse(ctx, repo, "main", api.SetBranchCompactionJSONRequestBody{StagedWritesThreshold: 10})
+	verifyResponseOK(t, setResp, err)
+	require.Equal(t, api.BranchCompaction{Enabled: getResp.JSON200.Enabled, StagedWritesThreshold: 10, Override: true}, *setResp.JSON200)
+	getResp, err = clt.GetBranchCompactionWithResponse(ctx, repo, "main")
+	verifyResponseOK(t, getResp, err)
+	require.Equal(t, *setResp.JSON200, *getResp.JSON200)
+
+	invalidResp, err := clt.SetBranchCompactionWithResponse(ctx, repo, "main", api.SetBranchCompactionJSONRequestBody{StagedWritesThreshold: -1})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, invalidResp.StatusCode())
+	notFoundResp, err := clt.SetBranchCompactionWithResponse(ctx, repo, "no-such-branch", api.SetBranchCompactionJSONRequestBody{StagedWritesThreshold: 10})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, notFoundResp.StatusCode())
+
+	deleteResp, err := clt.DeleteBranchCompactionWithResponse(ctx, repo, "main")
+	verifyResponseOK(t, deleteResp, err)
+	getResp, err = clt.GetBranchCompactionWithResponse(ctx, repo, "main")
+	verifyResponseOK(t, getResp, err)
+	require.False(t, getResp.JSON200.Override)
+	require.Equal(t, defaultThreshold, getResp.JSON200.StagedWritesThreshold)
+	deleteResp, err = clt.DeleteBranchCompactionWithResponse(ctx, repo, "main")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, deleteResp.StatusCode())
+}
+
 func TestController_WriteMetaRangeHandler(t *testing.T) {
 	ctx := context.Background()
 	clt, deps := setupClientWithAdmin(t)
@@ -3182,6 +3577,313 @@ func TestController_ListRepositoryRuns(t *testing.T) {
 	})
 }
 
+func TestController_RetryRun(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t)
+	ctx := context.Background()
+	var healthy atomic.Bool
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer httpServer.Close()
+
+	repo := testUniqueRepoName()
+	resp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repo,
+		StorageNamespace: "mem://" + repo,
+	})
+	verifyResponseOK(t, resp, err)
+	actionContent := `name: notify
+on:
+  post-commit:
+hooks:
+  - id: notify
+    type: webhook
+    properties:
+      url: "` + httpServer.URL + `"
+`
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "_lakefs_actions/post_commit.yaml", strings.NewReader(actionContent), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+	respCommit, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{
+		Message: "post-commit action",
+	})
+	verifyResponseOK(t, respCommit, err)
+
+	// the hook fails and is not retried by default
+	var run api.ActionRun
+	require.Eventually(t, func() bool {
+		respList, err := clt.ListRepositoryRunsWithResponse(ctx, repo, &api.ListRepositoryRunsParams{
+			Commit: api.StringPtr(respCommit.JSON201.Id),
+		})
+		if err != nil || respList.JSON200 == nil || len(respList.JSON200.Results) != 1 {
+			return false
+		}
+		run = respList.JSON200.Results[0]
+		return swag.BoolValue(run.DeadLetter)
+	}, 10*time.Second, 50*time.Millisecond)
+	require.Equal(t, "failed", run.Status)
+	require.Equal(t, 1, swag.IntValue(run.Attempts))
+
+	t.Run("retry", func(t *testing.T) {
+		healthy.Store(true)
+		retryResp, err := clt.RetryRunWithResponse(ctx, repo, run.RunId)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusAccepted, retryResp.StatusCode())
+		require.Eventually(t, func() bool {
+			runResp, err := clt.GetRunWithResponse(ctx, repo, run.RunId)
+			return err == nil && runResp.JSON200 != nil && runResp.JSON200.Status == "completed"
+		}, 10*time.Second, 50*time.Millisecond)
+
+		// nothing left to retry
+		retryResp, err = clt.RetryRunWithResponse(ctx, repo, run.RunId)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, retryResp.StatusCode())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		retryResp, err := clt.RetryRunWithResponse(ctx, repo, "no-such-run")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, retryResp.StatusCode())
+	})
+}
+
+func TestController_GlobalActions(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t)
+	ctx := context.Background()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer httpServer.Close()
+
+	repo := testUniqueRepoName()
+	resp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repo,
+		StorageNamespace: "mem://" + repo,
+	})
+	verifyResponseOK(t, resp, err)
+
+	actionContent := `name: policy
+on:
+  pre-commit:
+hooks:
+  - id: check
+    type: webhook
+    properties:
+      url: "` + httpServer.URL + `"
+`
+	t.Run("name mismatch", func(t *testing.T) {
+		setResp, err := clt.SetGlobalActionWithResponse(ctx, "other", api.SetGlobalActionJSONRequestBody{Content: actionContent})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, setResp.StatusCode())
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		setResp, err := clt.SetGlobalActionWithResponse(ctx, "policy", api.SetGlobalActionJSONRequestBody{Content: "name: policy"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, setResp.StatusCode())
+	})
+
+	setResp, err := clt.SetGlobalActionWithResponse(ctx, "policy", api.SetGlobalActionJSONRequestBody{
+		Content:  actionContent,
+		Enforced: swag.Bool(true),
+	})
+	verifyResponseOK(t, setResp, err)
+	require.Equal(t, "policy", setResp.JSON200.Name)
+	require.True(t, setResp.JSON200.Enforced)
+
+	listResp, err := clt.ListGlobalActionsWithResponse(ctx)
+	verifyResponseOK(t, listResp, err)
+	require.Len(t, listResp.JSON200.Results, 1)
+	require.Equal(t, actionContent, listResp.JSON200.Results[0].Content)
+
+	// the global action runs on the repository
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader("content"), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "blocked"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusPreconditionFailed, commitResp.StatusCode())
+
+	deleteResp, err := clt.DeleteGlobalActionWithResponse(ctx, "policy")
+	verifyResponseOK(t, deleteResp, err)
+	getResp, err := clt.GetGlobalActionWithResponse(ctx, "policy")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, getResp.StatusCode())
+
+	commitResp, err = clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "allowed"})
+	verifyResponseOK(t, commitResp, err)
+}
+
+func TestController_TestAction(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	resp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repo,
+		StorageNamespace: "mem://" + repo,
+	})
+	verifyResponseOK(t, resp, err)
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "tables/file1", strings.NewReader("content"), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "add table"})
+	verifyResponseOK(t, commitResp, err)
+	commitID := commitResp.JSON201.Id
+
+	const actionContent = `name: report
+on:
+  post-commit:
+    paths:
+      include: ["tables/**"]
+hooks:
+  - id: report
+    type: lua
+    properties:
+      script: |
+        print(action.commit.message .. ": " .. table.concat(action.changed_paths, ","))
+`
+	testResp, err := clt.TestActionWithResponse(ctx, repo, api.TestActionJSONRequestBody{
+		Content:   actionContent,
+		EventType: "post-commit",
+		Branch:    api.StringPtr("main"),
+		CommitId:  api.StringPtr(commitID),
+	})
+	verifyResponseOK(t, testResp, err)
+	result := testResp.JSON200
+	require.True(t, result.Matched)
+	require.Equal(t, "completed", result.Status)
+	require.Equal(t, commitID, swag.StringValue(result.CommitId))
+	require.Len(t, result.Hooks, 1)
+	require.Equal(t, "completed", result.Hooks[0].Status)
+	require.Equal(t, "add table: tables/file1\n", result.Hooks[0].Output)
+
+	// the run is not saved
+	runsResp, err := clt.ListRepositoryRunsWithResponse(ctx, repo, &api.ListRepositoryRunsParams{})
+	verifyResponseOK(t, runsResp, err)
+	require.Empty(t, runsResp.JSON200.Results)
+
+	t.Run("not matched", func(t *testing.T) {
+		testResp, err := clt.TestActionWithResponse(ctx, repo, api.TestActionJSONRequestBody{
+			Content:   actionContent,
+			EventType: "pre-commit",
+			Branch:    api.StringPtr("main"),
+		})
+		verifyResponseOK(t, testResp, err)
+		require.False(t, testResp.JSON200.Matched)
+		require.Empty(t, testResp.JSON200.Hooks)
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		testResp, err := clt.TestActionWithResponse(ctx, repo, api.TestActionJSONRequestBody{
+			Content:   actionContent,
+			EventType: "post-commit",
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, testResp.StatusCode())
+	})
+
+	t.Run("branch not found", func(t *testing.T) {
+		testResp, err := clt.TestActionWithResponse(ctx, repo, api.TestActionJSONRequestBody{
+			Content:   actionContent,
+			EventType: "post-commit",
+			Branch:    api.StringPtr("no-such-branch"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, testResp.StatusCode())
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		testResp, err := clt.TestActionWithResponse(ctx, repo, api.TestActionJSONRequestBody{
+			Content:   "name: report",
+			EventType: "post-commit",
+			Branch:    api.StringPtr("main"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, testResp.StatusCode())
+	})
+}
+
+func TestController_LuaLakeFSClient(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	resp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repo,
+		StorageNamespace: "mem://" + repo,
+	})
+	verifyResponseOK(t, resp, err)
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "data/file.txt", strings.NewReader("base"), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "base"})
+	verifyResponseOK(t, commitResp, err)
+	for _, branch := range []string{"feature", "work"} {
+		branchResp, err := clt.CreateBranchWithResponse(ctx, repo, api.CreateBranchJSONRequestBody{Name: branch, Source: "main"})
+		verifyResponseOK(t, branchResp, err)
+	}
+	// change the file on main, so the merge from feature conflicts unless the source wins
+	uploadResp, err = uploadObjectHelper(t, ctx, clt, "data/file.txt", strings.NewReader("main"), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err = clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "main"})
+	verifyResponseOK(t, commitResp, err)
+
+	const actionContent = `name: publish
+on:
+  pre-commit:
+    branches: ["work"]
+hooks:
+  - id: publish
+    type: lua
+    properties:
+      script: |
+        local lakefs = require("lakefs")
+        local repo = action.repository_id
+        local code, resp = lakefs.upload_object(repo, "feature", "data/file.txt", "from hook")
+        print("upload " .. code .. " " .. resp.path .. " " .. resp.size_bytes)
+        code, resp = lakefs.commit(repo, "feature", "hook commit", {source = "hook"})
+        print("commit " .. code .. " " .. resp.message .. " " .. resp.metadata.source)
+        code, resp = lakefs.merge(repo, "feature", "main", {message = "hook merge", metadata = {reviewer = "hook"}, strategy = "source-wins"})
+        print("merge " .. code .. " " .. tostring(resp.reference ~= nil))
+        code, resp = lakefs.stat_object(repo, "main", "data/file.txt")
+        print("stat " .. code .. " " .. resp.path .. " " .. resp.size_bytes)
+        code, resp = lakefs.get_object(repo, "main", "data/file.txt")
+        print("get " .. code .. " " .. resp)
+`
+	uploadResp, err = uploadObjectHelper(t, ctx, clt, "_lakefs_actions/publish.yaml", strings.NewReader(actionContent), repo, "work")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err = clt.CommitWithResponse(ctx, repo, "work", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "publish"})
+	verifyResponseOK(t, commitResp, err)
+
+	// the hook output holds the decoded responses
+	runsResp, err := clt.ListRepositoryRunsWithResponse(ctx, repo, &api.ListRepositoryRunsParams{Branch: api.StringPtr("work")})
+	verifyResponseOK(t, runsResp, err)
+	require.Len(t, runsResp.JSON200.Results, 1)
+	runID := runsResp.JSON200.Results[0].RunId
+	hooksResp, err := clt.ListRunHooksWithResponse(ctx, repo, runID, &api.ListRunHooksParams{})
+	verifyResponseOK(t, hooksResp, err)
+	require.Len(t, hooksResp.JSON200.Results, 1)
+	outputResp, err := clt.GetRunHookOutputWithResponse(ctx, repo, runID, hooksResp.JSON200.Results[0].HookRunId)
+	verifyResponseOK(t, outputResp, err)
+	require.Equal(t, `upload 201 data/file.txt 9
+commit 201 hook commit hook
+merge 200 true
+stat 200 data/file.txt 9
+get 200 from hook
+`, string(outputResp.Body))
+
+	// the merge took the options passed by the hook
+	logResp, err := clt.LogCommitsWithResponse(ctx, repo, "main", &api.LogCommitsParams{Amount: api.PaginationAmountPtr(1)})
+	verifyResponseOK(t, logResp, err)
+	require.Len(t, logResp.JSON200.Results, 1)
+	head := logResp.JSON200.Results[0]
+	require.Equal(t, "hook merge", head.Message)
+	require.Equal(t, "hook", head.Metadata.AdditionalProperties["reviewer"])
+}
+
 func TestController_MergeInvalidStrategy(t *testing.T) {
 	clt, _ := setupClientWithAdmin(t)
 	ctx := context.Background()
@@ -4714,3 +5416,98 @@ func TestController_GarbageCollectionRules(t *testing.T) {
 		})
 	}
 }
+
+func TestController_RebalanceBranch(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+
+	// commit a metarange of single entry ranges
+	const numObjects = 20
+	store := deps.catalog.(*catalog.Catalog).Store
+	repository, err := store.GetRepository(ctx, graveler.RepositoryID(repo))
+	require.NoError(t, err)
+	ranges := make([]*graveler.RangeInfo, 0, numObjects)
+	for i := 0; i < numObjects; i++ {
+		value, err := catalog.EntryToValue(&catalog.Entry{
+			Address:      fmt.Sprintf("address-%03d", i),
+			LastModified: timestamppb.Now(),
+			Size:         int64(i),
+		})
+		require.NoError(t, err)
+		records := []*graveler.ValueRecord{{Key: graveler.Key(fmt.Sprintf("obj-%03d", i)), Value: value}}
+		rangeInfo, err := store.WriteRange(ctx, repository, testutils.NewFakeValueIterator(records))
+		require.NoError(t, err)
+		ranges = append(ranges, rangeInfo)
+	}
+	metaRangeInfo, err := store.WriteMetaRange(ctx, repository, ranges)
+	require.NoError(t, err)
+	metaRangeID := string(metaRangeInfo.ID)
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &api.CommitParams{SourceMetarange: &metaRangeID}, api.CommitJSONRequestBody{Message: "small ranges"})
+	verifyResponseOK(t, commitResp, err)
+	skewedCommitID := commitResp.JSON201.Id
+
+	statsResp, err := clt.GetCommitRangeStatsWithResponse(ctx, repo, skewedCommitID)
+	verifyResponseOK(t, statsResp, err)
+	skewed := statsResp.JSON200
+	require.Equal(t, metaRangeID, skewed.MetaRangeId)
+	require.Equal(t, numObjects, skewed.RangeCount)
+	require.EqualValues(t, numObjects, skewed.RecordCount)
+	require.EqualValues(t, 1, skewed.Records.Max)
+
+	// rebalance waits for the rebalance to finish, and returns its status
+	rebalance := func(t *testing.T) *api.RebalanceStatusResp {
+		t.Helper()
+		resp, err := clt.RebalanceBranchWithResponse(ctx, repo, "main", api.RebalanceBranchJSONRequestBody{})
+		verifyResponseOK(t, resp, err)
+		var status *api.RebalanceStatusResp
+		require.Eventually(t, func() bool {
+			statusResp, err := clt.RebalanceStatusWithResponse(ctx, repo, "main", &api.RebalanceStatusParams{Id: resp.JSON202.Id})
+			require.NoError(t, err)
+			require.NotNil(t, statusResp.JSON200)
+			status = statusResp.JSON200
+			return status.Completed || status.Error != nil
+		}, 10*time.Second, 100*time.Millisecond)
+		return status
+	}
+
+	status := rebalance(t)
+	require.Nil(t, status.Error)
+	rebalanced := status.Commit
+	require.NotNil(t, rebalanced)
+	require.Equal(t, []string{skewedCommitID}, rebalanced.Parents)
+	require.Equal(t, "Rebalance ranges", rebalanced.Message)
+	require.NotEqual(t, metaRangeID, rebalanced.MetaRangeId)
+
+	statsResp, err = clt.GetCommitRangeStatsWithResponse(ctx, repo, "main")
+	verifyResponseOK(t, statsResp, err)
+	require.Equal(t, rebalanced.MetaRangeId, statsResp.JSON200.MetaRangeId)
+	require.Equal(t, skewed.RecordCount, statsResp.JSON200.RecordCount)
+	require.Equal(t, 1, statsResp.JSON200.RangeCount)
+
+	// the rebalanced commit has the same tree
+	diffResp, err := clt.DiffRefsWithResponse(ctx, repo, skewedCommitID, rebalanced.Id, &api.DiffRefsParams{})
+	verifyResponseOK(t, diffResp, err)
+	require.Empty(t, diffResp.JSON200.Results)
+
+	// ranges are balanced already
+	status = rebalance(t)
+	require.False(t, status.Completed)
+	require.NotNil(t, status.Error)
+	require.Contains(t, status.Error.Message, graveler.ErrNoChanges.Error())
+
+	rebalanceResp, err := clt.RebalanceBranchWithResponse(ctx, repo, "no-such-branch", api.RebalanceBranchJSONRequestBody{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, rebalanceResp.StatusCode())
+
+	rebalanceStatusResp, err := clt.RebalanceStatusWithResponse(ctx, repo, "main", &api.RebalanceStatusParams{Id: "not-exists"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, rebalanceStatusResp.StatusCode())
+
+	statsResp, err = clt.GetCommitRangeStatsWithResponse(ctx, repo, "no-such-ref")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, statsResp.StatusCode())
+}
@@ -164,7 +164,7 @@ func setupHandlerWithWalkerFactory(t testing.TB, factory catalog.WalkerFactory)
 	// wire actions
 	actionsConfig := actions.Config{Enabled: true}
 	actionsConfig.Lua.NetHTTPEnabled = true
-	actionsService := actions.NewService(
+	actionsService, err := actions.NewService(
 		ctx,
 		actionsStore,
 		catalog.NewActionsSource(c),
@@ -173,6 +173,7 @@ func setupHandlerWithWalkerFactory(t testing.TB, factory catalog.WalkerFactory)
 		collector,
 		actionsConfig,
 	)
+	testutil.MustDo(t, "build actions service", err)
 
 	c.SetHooksHandler(actionsService)
 
@@ -213,6 +214,8 @@ func setupHandlerWithWalkerFactory(t testing.TB, factory catalog.WalkerFactory)
 		upload.DefaultPathProvider,
 		otfDiffService,
 	)
+	// serve lakeFS API calls from Lua hooks, as the lakeFS server does
+	actionsService.SetEndpoint(&http.Server{Handler: handler, ReadHeaderTimeout: time.Minute})
 
 	return handler, &dependencies{
 		blocks:      c.BlockAdapter,
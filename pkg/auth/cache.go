@@ -22,6 +22,10 @@ type Cache interface {
 	GetCredential(accessKeyID string, setFn CredentialSetFn) (*model.Credential, error)
 	GetUser(key userKey, setFn UserSetFn) (*model.User, error)
 	GetUserPolicies(userID string, setFn UserPoliciesSetFn) ([]*model.Policy, error)
+	// Invalidate drops the cached values the change of the auth key affects
+	Invalidate(key []byte)
+	// Purge drops all cached values
+	Purge()
 }
 
 type LRUCache struct {
@@ -63,6 +67,29 @@ func (c *LRUCache) GetUserPolicies(userID string, setFn UserPoliciesSetFn) ([]*m
 	return v.([]*model.Policy), nil
 }
 
+func (c *LRUCache) Invalidate(key []byte) {
+	kind, ids := model.ParsePath(key)
+	switch kind {
+	case model.PathCredential:
+		c.credentialsCache.Remove(ids[1])
+	case model.PathUser:
+		// users are cached by name, email and external ID
+		c.userCache.Purge()
+		c.policyCache.Remove(ids[0])
+	case model.PathUserPolicy:
+		c.policyCache.Remove(ids[0])
+	case model.PathPolicy, model.PathGroup, model.PathGroupUser, model.PathGroupPolicy:
+		// effective policies of any user may change
+		c.policyCache.Purge()
+	}
+}
+
+func (c *LRUCache) Purge() {
+	c.credentialsCache.Purge()
+	c.userCache.Purge()
+	c.policyCache.Purge()
+}
+
 // DummyCache dummy cache that doesn't cache
 type DummyCache struct{}
 
@@ -77,3 +104,7 @@ func (d *DummyCache) GetUser(_ userKey, setFn UserSetFn) (*model.User, error) {
 func (d *DummyCache) GetUserPolicies(_ string, setFn UserPoliciesSetFn) ([]*model.Policy, error) {
 	return setFn()
 }
+
+func (d *DummyCache) Invalidate(_ []byte) {}
+
+func (d *DummyCache) Purge() {}
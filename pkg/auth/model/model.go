@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/swag"
@@ -87,6 +88,48 @@ func MetadataKeyPath(key string) string {
 	return kv.FormatPath(metadataPrefix, key)
 }
 
+// PathKind is the kind of entity stored under an auth key path
+type PathKind int
+
+const (
+	PathUnknown PathKind = iota
+	PathUser
+	PathCredential
+	PathPolicy
+	PathGroup
+	PathUserPolicy
+	PathGroupUser
+	PathGroupPolicy
+)
+
+// ParsePath returns the kind of entity stored under the key and the IDs in its path: the user name of a user, the
+// user name and access key ID of a credential, the group and user names of a group membership, etc.
+func ParsePath(key []byte) (PathKind, []string) {
+	parts := strings.Split(string(key), kv.PathDelimiter)
+	const (
+		entityParts   = 2
+		relationParts = 4
+	)
+	switch {
+	case len(parts) == entityParts && parts[0] == usersPrefix:
+		return PathUser, parts[1:]
+	case len(parts) == entityParts && parts[0] == policiesPrefix:
+		return PathPolicy, parts[1:]
+	case len(parts) == entityParts && parts[0] == groupsPrefix:
+		return PathGroup, parts[1:]
+	case len(parts) == relationParts && parts[0] == usersCredentialsPrefix && parts[2] == credentialsPrefix:
+		return PathCredential, []string{parts[1], parts[3]}
+	case len(parts) == relationParts && parts[0] == usersPoliciesPrefix && parts[2] == policiesPrefix:
+		return PathUserPolicy, []string{parts[1], parts[3]}
+	case len(parts) == relationParts && parts[0] == groupsUsersPrefix && parts[2] == usersPrefix:
+		return PathGroupUser, []string{parts[1], parts[3]}
+	case len(parts) == relationParts && parts[0] == groupsPoliciesPrefix && parts[2] == policiesPrefix:
+		return PathGroupPolicy, []string{parts[1], parts[3]}
+	default:
+		return PathUnknown, nil
+	}
+}
+
 var ErrInvalidStatementSrcFormat = errors.New("invalid statements src format")
 
 type PaginationParams struct {
@@ -235,21 +235,10 @@ func (s *AuthService) DeleteUser(ctx context.Context, username string) error {
 
 	// delete policy attached to user
 	policiesKey := model.UserPolicyPath(username, "")
-	it, err := kv.NewSecondaryIterator(ctx, s.store, (&model.PolicyData{}).ProtoReflect().Type(), model.PartitionKey, policiesKey, []byte(""))
+	indexKeys, err := s.listIndexKeys(ctx, policiesKey)
 	if err != nil {
 		return err
 	}
-	defer it.Close()
-	for it.Next() {
-		entry := it.Entry()
-		policy := entry.Value.(*model.PolicyData)
-		if err = s.DetachPolicyFromUserNoValidation(ctx, policy.DisplayName, username); err != nil {
-			return err
-		}
-	}
-	if err = it.Err(); err != nil {
-		return err
-	}
 
 	// delete user membership of group
 	groupKey := model.GroupPath("")
@@ -261,22 +250,108 @@ func (s *AuthService) DeleteUser(ctx context.Context, username string) error {
 	for itr.Next() {
 		entry := itr.Entry()
 		group := entry.Value.(*model.GroupData)
-		if err = s.removeUserFromGroupNoValidation(ctx, username, group.DisplayName); err != nil {
+		gu := model.GroupUserPath(group.DisplayName, username)
+		found, err := s.keyExists(ctx, gu)
+		if err != nil {
 			return err
 		}
+		if found {
+			indexKeys = append(indexKeys, gu)
+		}
 	}
 	if err = itr.Err(); err != nil {
 		return err
 	}
 
 	// delete user
-	err = s.store.Delete(ctx, []byte(model.PartitionKey), userPath)
+	err = s.deleteWithIndexes(ctx, userPath, indexKeys)
 	if err != nil {
 		return fmt.Errorf("delete user (userKey %s): %w", userPath, err)
 	}
 	return err
 }
 
+// listIndexKeys returns the keys of the secondary index entries under prefix
+func (s *AuthService) listIndexKeys(ctx context.Context, prefix []byte) ([][]byte, error) {
+	it, err := kv.NewPrimaryIterator(ctx, s.store, (&kv.SecondaryIndex{}).ProtoReflect().Type(), model.PartitionKey, prefix, kv.IteratorOptionsAfter([]byte("")))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, it.Entry().Key)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *AuthService) keyExists(ctx context.Context, key []byte) (bool, error) {
+	_, err := s.store.Get(ctx, []byte(model.PartitionKey), key)
+	if errors.Is(err, kv.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// deleteWithIndexes deletes the entity key together with the secondary index entries that point to it, atomically
+// when the store supports the batch. Entities with more index entries than the store writes in a batch are deleted
+// after their index entries, so a failure may leave the entity without some of its index entries.
+// Fails with ErrNotFound if the entity was deleted concurrently.
+func (s *AuthService) deleteWithIndexes(ctx context.Context, key []byte, indexKeys [][]byte) error {
+	ops := make([]kv.BatchOp, 0, len(indexKeys)+1)
+	for _, indexKey := range indexKeys {
+		ops = append(ops, kv.DeleteOp([]byte(model.PartitionKey), indexKey))
+	}
+	ops = append(ops, kv.DeleteIfOp([]byte(model.PartitionKey), key, kv.PrecondConditionalExists))
+	err := kv.WriteBatchBestEffort(ctx, s.store, ops)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		err = ErrNotFound
+	}
+	return err
+}
+
+// maxEntityWriteAttempts is the number of attempts to write a key that refers to entities updated concurrently
+const maxEntityWriteAttempts = 3
+
+// setIfEntitiesExist creates the key, only if it does not exist and the entities it refers to exist. The entities are
+// rewritten with their current value in the same atomic batch, so the key is not created for an entity deleted
+// concurrently. Fails with ErrAlreadyExists if the key exists, and with ErrNotFound if an entity does not exist.
+func (s *AuthService) setIfEntitiesExist(ctx context.Context, key []byte, msg protoreflect.ProtoMessage, entityKeys ...[]byte) error {
+	op, err := kv.SetMsgIfOp(model.PartitionKey, key, msg, nil)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		ops := []kv.BatchOp{op}
+		for _, entityKey := range entityKeys {
+			res, err := s.store.Get(ctx, []byte(model.PartitionKey), entityKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", entityKey, err)
+			}
+			// the predicate of the current value, so a concurrent update of the entity is not overwritten
+			ops = append(ops, kv.SetIfOp([]byte(model.PartitionKey), entityKey, res.Value, res.Predicate))
+		}
+		err := kv.WriteBatch(ctx, s.store, ops)
+		if !errors.Is(err, kv.ErrPredicateFailed) {
+			return err
+		}
+		found, err := s.keyExists(ctx, key)
+		if err != nil {
+			return err
+		}
+		if found {
+			return ErrAlreadyExists
+		}
+		// an entity was updated or deleted concurrently
+		if attempt == maxEntityWriteAttempts-1 {
+			return fmt.Errorf("entities of %s updated concurrently: %w", key, kv.ErrPredicateFailed)
+		}
+	}
+}
+
 type UserPredicate func(u *model.UserData) bool
 
 func (s *AuthService) getUserByPredicate(ctx context.Context, key userKey, predicate UserPredicate) (*model.User, error) {
@@ -372,11 +447,8 @@ func (s *AuthService) AttachPolicyToUser(ctx context.Context, policyDisplayName
 	policyKey := model.PolicyPath(policyDisplayName)
 	pu := model.UserPolicyPath(username, policyDisplayName)
 
-	err := kv.SetMsgIf(ctx, s.store, model.PartitionKey, pu, &kv.SecondaryIndex{PrimaryKey: policyKey}, nil)
+	err := s.setIfEntitiesExist(ctx, pu, &kv.SecondaryIndex{PrimaryKey: policyKey}, model.UserPath(username), policyKey)
 	if err != nil {
-		if errors.Is(err, kv.ErrPredicateFailed) {
-			err = ErrAlreadyExists
-		}
 		return fmt.Errorf("policy attachment to user: (key %s): %w", pu, err)
 	}
 	return nil
@@ -552,43 +624,22 @@ func (s *AuthService) DeleteGroup(ctx context.Context, groupDisplayName string)
 
 	// delete user membership to group
 	usersKey := model.GroupUserPath(groupDisplayName, "")
-	it, err := kv.NewSecondaryIterator(ctx, s.store, (&model.UserData{}).ProtoReflect().Type(), model.PartitionKey, usersKey, []byte(""))
+	indexKeys, err := s.listIndexKeys(ctx, usersKey)
 	if err != nil {
 		return err
 	}
-	defer it.Close()
-	for it.Next() {
-		entry := it.Entry()
-		user := entry.Value.(*model.UserData)
-		if err = s.removeUserFromGroupNoValidation(ctx, user.Username, groupDisplayName); err != nil {
-			return err
-		}
-	}
-	if err = it.Err(); err != nil {
-		return err
-	}
 
 	// delete policy attachment to group
 	policiesKey := model.GroupPolicyPath(groupDisplayName, "")
-	itr, err := kv.NewSecondaryIterator(ctx, s.store, (&model.PolicyData{}).ProtoReflect().Type(), model.PartitionKey, policiesKey, []byte(""))
+	policyKeys, err := s.listIndexKeys(ctx, policiesKey)
 	if err != nil {
 		return err
 	}
-	defer it.Close()
-	for itr.Next() {
-		entry := itr.Entry()
-		policy := entry.Value.(*model.PolicyData)
-		if err = s.DetachPolicyFromGroupNoValidation(ctx, policy.DisplayName, groupDisplayName); err != nil {
-			return err
-		}
-	}
-	if err = itr.Err(); err != nil {
-		return err
-	}
+	indexKeys = append(indexKeys, policyKeys...)
 
 	// delete group
 	groupPath := model.GroupPath(groupDisplayName)
-	err = s.store.Delete(ctx, []byte(model.PartitionKey), groupPath)
+	err = s.deleteWithIndexes(ctx, groupPath, indexKeys)
 	if err != nil {
 		return fmt.Errorf("delete user (userKey %s): %w", groupPath, err)
 	}
@@ -629,11 +680,8 @@ func (s *AuthService) AddUserToGroup(ctx context.Context, username, groupDisplay
 
 	userKey := model.UserPath(username)
 	gu := model.GroupUserPath(groupDisplayName, username)
-	err := kv.SetMsgIf(ctx, s.store, model.PartitionKey, gu, &kv.SecondaryIndex{PrimaryKey: userKey}, nil)
+	err := s.setIfEntitiesExist(ctx, gu, &kv.SecondaryIndex{PrimaryKey: userKey}, userKey, model.GroupPath(groupDisplayName))
 	if err != nil {
-		if errors.Is(err, kv.ErrPredicateFailed) {
-			err = ErrAlreadyExists
-		}
 		return fmt.Errorf("add user to group: (key %s): %w", gu, err)
 	}
 	return err
@@ -787,31 +835,48 @@ func (s *AuthService) DeletePolicy(ctx context.Context, policyDisplayName string
 		return err
 	}
 	defer it.Close()
+	var indexKeys [][]byte
 	for it.Next() {
 		entry := it.Entry()
 		user := entry.Value.(*model.UserData)
-		if err = s.DetachPolicyFromUserNoValidation(ctx, policyDisplayName, user.Username); err != nil {
+		pu := model.UserPolicyPath(user.Username, policyDisplayName)
+		found, err := s.keyExists(ctx, pu)
+		if err != nil {
 			return err
 		}
+		if found {
+			indexKeys = append(indexKeys, pu)
+		}
+	}
+	if err = it.Err(); err != nil {
+		return err
 	}
 
 	// delete policy attachment to group
 	groupKey := model.GroupPath("")
-	it, err = kv.NewPrimaryIterator(ctx, s.store, (&model.GroupData{}).ProtoReflect().Type(), model.PartitionKey, groupKey, kv.IteratorOptionsAfter([]byte("")))
+	itr, err := kv.NewPrimaryIterator(ctx, s.store, (&model.GroupData{}).ProtoReflect().Type(), model.PartitionKey, groupKey, kv.IteratorOptionsAfter([]byte("")))
 	if err != nil {
 		return err
 	}
-	defer it.Close()
-	for it.Next() {
-		entry := it.Entry()
+	defer itr.Close()
+	for itr.Next() {
+		entry := itr.Entry()
 		group := entry.Value.(*model.GroupData)
-		if err = s.DetachPolicyFromGroupNoValidation(ctx, policyDisplayName, group.DisplayName); err != nil {
+		pg := model.GroupPolicyPath(group.DisplayName, policyDisplayName)
+		found, err := s.keyExists(ctx, pg)
+		if err != nil {
 			return err
 		}
+		if found {
+			indexKeys = append(indexKeys, pg)
+		}
+	}
+	if err = itr.Err(); err != nil {
+		return err
 	}
 
 	// delete policy
-	err = s.store.Delete(ctx, []byte(model.PartitionKey), policyPath)
+	err = s.deleteWithIndexes(ctx, policyPath, indexKeys)
 	if err != nil {
 		return fmt.Errorf("delete policy (policyKey %s): %w", policyPath, err)
 	}
@@ -862,11 +927,8 @@ func (s *AuthService) AddCredentials(ctx context.Context, username, accessKeyID,
 		Username: user.Username,
 	}
 	credentialsKey := model.CredentialPath(user.Username, c.AccessKeyID)
-	err = kv.SetMsgIf(ctx, s.store, model.PartitionKey, credentialsKey, model.ProtoFromCredential(c), nil)
+	err = s.setIfEntitiesExist(ctx, credentialsKey, model.ProtoFromCredential(c), model.UserPath(user.Username))
 	if err != nil {
-		if errors.Is(err, kv.ErrPredicateFailed) {
-			err = ErrAlreadyExists
-		}
 		return nil, fmt.Errorf("save credentials (credentialsKey %s): %w", credentialsKey, err)
 	}
 
@@ -905,11 +967,8 @@ func (s *AuthService) AttachPolicyToGroup(ctx context.Context, policyDisplayName
 	policyKey := model.PolicyPath(policyDisplayName)
 	pg := model.GroupPolicyPath(groupDisplayName, policyDisplayName)
 
-	err := kv.SetMsgIf(ctx, s.store, model.PartitionKey, pg, &kv.SecondaryIndex{PrimaryKey: policyKey}, nil)
+	err := s.setIfEntitiesExist(ctx, pg, &kv.SecondaryIndex{PrimaryKey: policyKey}, model.GroupPath(groupDisplayName), policyKey)
 	if err != nil {
-		if errors.Is(err, kv.ErrPredicateFailed) {
-			err = ErrAlreadyExists
-		}
 		return fmt.Errorf("policy attachment to group: (key %s): %w", pg, err)
 	}
 	return err
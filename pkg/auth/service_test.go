@@ -274,6 +274,96 @@ func TestAuthService_DeleteUserWithRelations(t *testing.T) {
 	}
 }
 
+func TestAuthService_WatchChangesInvalidatesCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kvStore := kvtest.GetStore(ctx, t)
+	cachedService := auth.NewAuthService(kvStore, crypt.NewSecretStore(someSecret), nil, authparams.ServiceCache{
+		Enabled: true,
+		Size:    1024,
+		TTL:     time.Hour,
+		Jitter:  time.Minute,
+	}, logging.ContextUnavailable())
+	cachedService.WatchChanges(ctx)
+	otherService := auth.NewAuthService(kvStore, crypt.NewSecretStore(someSecret), nil, authparams.ServiceCache{
+		Enabled: false,
+	}, logging.ContextUnavailable())
+
+	const userName = "watched"
+	_, err := otherService.CreateUser(ctx, &model.User{Username: userName})
+	require.NoError(t, err)
+	_, err = cachedService.GetUser(ctx, userName)
+	require.NoError(t, err)
+
+	// delete the user through a service that does not share the cache
+	require.NoError(t, otherService.DeleteUser(ctx, userName))
+	require.Eventually(t, func() bool {
+		_, err := cachedService.GetUser(ctx, userName)
+		return errors.Is(err, auth.ErrNotFound)
+	}, 5*time.Second, 10*time.Millisecond, "cached user should be invalidated after delete")
+}
+
+func TestAuthService_DeletedUserWithMemberships(t *testing.T) {
+	ctx := context.Background()
+	kvStore := kvtest.GetStore(ctx, t)
+	// the cache of this service keeps the deleted user, so only the store guards against writes to the deleted user
+	cachedService := auth.NewAuthService(kvStore, crypt.NewSecretStore(someSecret), nil, authparams.ServiceCache{
+		Enabled: true,
+		Size:    1024,
+		TTL:     time.Hour,
+		Jitter:  time.Minute,
+	}, logging.ContextUnavailable())
+	otherService := auth.NewAuthService(kvStore, crypt.NewSecretStore(someSecret), nil, authparams.ServiceCache{
+		Enabled: false,
+	}, logging.ContextUnavailable())
+
+	const (
+		userName   = "member"
+		groupName  = "members"
+		policyName = "memberPolicy"
+	)
+	_, err := otherService.CreateUser(ctx, &model.User{Username: userName})
+	require.NoError(t, err)
+	require.NoError(t, otherService.CreateGroup(ctx, &model.Group{DisplayName: groupName}))
+	require.NoError(t, otherService.WritePolicy(ctx, &model.Policy{
+		DisplayName: policyName,
+		Statement: model.Statements{{
+			Action:   []string{"fs:ReadObject"},
+			Resource: "*",
+			Effect:   model.StatementEffectAllow,
+		}},
+	}, false))
+	require.NoError(t, otherService.AddUserToGroup(ctx, userName, groupName))
+	require.NoError(t, otherService.AttachPolicyToUser(ctx, policyName, userName))
+	_, err = cachedService.GetUser(ctx, userName)
+	require.NoError(t, err)
+
+	require.NoError(t, otherService.DeleteUser(ctx, userName))
+	require.ErrorIs(t, cachedService.AddUserToGroup(ctx, userName, groupName), auth.ErrNotFound)
+	require.ErrorIs(t, cachedService.AttachPolicyToUser(ctx, policyName, userName), auth.ErrNotFound)
+	_, err = cachedService.CreateCredentials(ctx, userName)
+	require.ErrorIs(t, err, auth.ErrNotFound)
+
+	// a user created with the same name does not inherit memberships of the deleted user
+	_, err = otherService.CreateUser(ctx, &model.User{Username: userName})
+	require.NoError(t, err)
+	groups, _, err := otherService.ListUserGroups(ctx, userName, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Empty(t, groups)
+	policies, _, err := otherService.ListUserPolicies(ctx, userName, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Empty(t, policies)
+	credentials, _, err := otherService.ListUserCredentials(ctx, userName, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+	users, _, err := otherService.ListGroupUsers(ctx, groupName, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	require.NoError(t, otherService.AddUserToGroup(ctx, userName, groupName))
+	require.ErrorIs(t, otherService.AddUserToGroup(ctx, userName, groupName), auth.ErrAlreadyExists)
+}
+
 func TestAuthService_DeleteGroupWithRelations(t *testing.T) {
 	userNames := []string{"first", "second", "third"}
 	groupNames := []string{"groupA", "groupB", "groupC"}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+// WatchChanges invalidates cached auth entities when they change, until ctx is done. Without change events from the
+// kv store, cached entities expire by their TTL.
+func (s *AuthService) WatchChanges(ctx context.Context) {
+	events, err := kv.Watch(ctx, s.store, []byte(model.PartitionKey), nil)
+	if errors.Is(err, kv.ErrWatchNotSupported) {
+		s.log.Info("KV store does not report changes, auth cache entries expire by TTL")
+		return
+	}
+	if err != nil {
+		s.log.WithError(err).Warn("Failed to watch auth changes, auth cache entries expire by TTL")
+		return
+	}
+	go func() {
+		for ev := range events {
+			if ev.Type == kv.EventReset {
+				s.cache.Purge()
+				continue
+			}
+			s.cache.Invalidate(ev.Key)
+		}
+	}()
+}
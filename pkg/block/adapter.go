@@ -35,6 +35,8 @@ const (
 	BlockstoreTypeLocal     = "local"
 	BlockstoreTypeMem       = "mem"
 	BlockstoreTypeTransient = "transient"
+	BlockstoreTypeSFTP      = "sftp"
+	BlockstoreTypeWebDAV    = "webdav"
 )
 
 const (
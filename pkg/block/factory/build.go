@@ -18,7 +18,9 @@ import (
 	"github.com/treeverse/lakefs/pkg/block/mem"
 	"github.com/treeverse/lakefs/pkg/block/params"
 	s3a "github.com/treeverse/lakefs/pkg/block/s3"
+	"github.com/treeverse/lakefs/pkg/block/sftp"
 	"github.com/treeverse/lakefs/pkg/block/transient"
+	"github.com/treeverse/lakefs/pkg/block/webdav"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"github.com/treeverse/lakefs/pkg/stats"
 	"golang.org/x/oauth2/google"
@@ -64,10 +66,50 @@ func BuildBlockAdapter(ctx context.Context, statsCollector stats.Collector, c pa
 			return nil, err
 		}
 		return azure.NewAdapter(ctx, p)
+	case block.BlockstoreTypeSFTP:
+		p, err := c.BlockstoreSFTPParams()
+		if err != nil {
+			return nil, err
+		}
+		return BuildSFTPAdapter(ctx, p)
+	case block.BlockstoreTypeWebDAV:
+		p, err := c.BlockstoreWebDAVParams()
+		if err != nil {
+			return nil, err
+		}
+		return BuildWebDAVAdapter(ctx, p)
 	default:
 		return nil, fmt.Errorf("%w '%s' please choose one of %s",
-			block.ErrInvalidAddress, blockstore, []string{block.BlockstoreTypeLocal, block.BlockstoreTypeS3, block.BlockstoreTypeAzure, block.BlockstoreTypeMem, block.BlockstoreTypeTransient, block.BlockstoreTypeGS})
+			block.ErrInvalidAddress, blockstore, []string{block.BlockstoreTypeLocal, block.BlockstoreTypeS3, block.BlockstoreTypeAzure, block.BlockstoreTypeMem, block.BlockstoreTypeTransient, block.BlockstoreTypeGS, block.BlockstoreTypeSFTP, block.BlockstoreTypeWebDAV})
+	}
+}
+
+func BuildSFTPAdapter(ctx context.Context, params params.SFTP) (*sftp.Adapter, error) {
+	adapter, err := sftp.NewAdapter(params,
+		sftp.WithImportEnabled(params.ImportEnabled),
+		sftp.WithImportChecksums(params.ImportChecksums),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("got error opening an sftp block adapter with host %s: %w", params.Host, err)
 	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"type": "sftp",
+		"host": params.Host,
+		"path": params.Path,
+	}).Info("initialized blockstore adapter")
+	return adapter, nil
+}
+
+func BuildWebDAVAdapter(ctx context.Context, params params.WebDAV) (*webdav.Adapter, error) {
+	adapter, err := webdav.NewAdapter(params, webdav.WithImportEnabled(params.ImportEnabled))
+	if err != nil {
+		return nil, fmt.Errorf("got error opening a webdav block adapter with endpoint %s: %w", params.Endpoint, err)
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"type":     "webdav",
+		"endpoint": params.Endpoint,
+	}).Info("initialized blockstore adapter")
+	return adapter, nil
 }
 
 func buildLocalAdapter(ctx context.Context, params params.Local) (*local.Adapter, error) {
@@ -73,12 +73,16 @@ func New(_ context.Context, opts ...func(a *Adapter)) *Adapter {
 	return a
 }
 
+// getKey returns the qualified key of the object, so the same object is found using either its relative or full address
 func getKey(obj block.ObjectPointer) string {
-	// TODO (niro): Fix mem storage path resolution
 	if obj.IdentifierType == block.IdentifierTypeFull {
 		return obj.Identifier
 	}
-	return fmt.Sprintf("%s:%s", obj.StorageNamespace, obj.Identifier)
+	qk, err := block.DefaultResolveNamespace(obj.StorageNamespace, obj.Identifier, obj.IdentifierType)
+	if err != nil {
+		return fmt.Sprintf("%s:%s", obj.StorageNamespace, obj.Identifier)
+	}
+	return qk.Format()
 }
 
 func (a *Adapter) Put(_ context.Context, obj block.ObjectPointer, _ int64, reader io.Reader, opts block.PutOpts) error {
@@ -14,6 +14,8 @@ const (
 	StorageTypeS3
 	StorageTypeGS
 	StorageTypeAzure
+	StorageTypeSFTP
+	StorageTypeWebDAV
 )
 
 func (s StorageType) BlockstoreType() string {
@@ -38,6 +40,10 @@ func (s StorageType) Scheme() string {
 		scheme = "s3"
 	case StorageTypeAzure:
 		scheme = "https"
+	case StorageTypeSFTP:
+		scheme = "sftp"
+	case StorageTypeWebDAV:
+		scheme = "webdav"
 	default:
 		panic("unknown storage type")
 	}
@@ -95,6 +101,10 @@ func GetStorageType(namespaceURL *url.URL) (StorageType, error) {
 		return StorageTypeGS, nil
 	case "http", "https":
 		return StorageTypeAzure, nil
+	case "sftp":
+		return StorageTypeSFTP, nil
+	case "webdav":
+		return StorageTypeWebDAV, nil
 	default:
 		return st, fmt.Errorf("invalid storage scheme %s: %w", namespaceURL.Scheme, ErrInvalidAddress)
 	}
@@ -13,6 +13,8 @@ type AdapterConfig interface {
 	BlockstoreS3Params() (S3, error)
 	BlockstoreGSParams() (GS, error)
 	BlockstoreAzureParams() (Azure, error)
+	BlockstoreSFTPParams() (SFTP, error)
+	BlockstoreWebDAVParams() (WebDAV, error)
 }
 
 type Mem struct{}
@@ -69,3 +71,27 @@ type Azure struct {
 	// TestEndpointURL - For testing purposes, provide a custom URL to override the default URL template
 	TestEndpointURL string
 }
+
+type SFTP struct {
+	// Host is the address of the SSH server, with an optional port (default 22)
+	Host           string
+	User           string
+	Password       string
+	PrivateKeyFile string
+	// KnownHostsFile is used to verify the server host key
+	KnownHostsFile        string
+	InsecureIgnoreHostKey bool
+	// Path is the directory on the server under which storage namespaces are stored
+	Path          string
+	ImportEnabled bool
+	// ImportChecksums computes the MD5 of imported files, by reading them while listing
+	ImportChecksums bool
+}
+
+type WebDAV struct {
+	// Endpoint is the URL of the WebDAV collection under which storage namespaces are stored
+	Endpoint      string
+	User          string
+	Password      string
+	ImportEnabled bool
+}
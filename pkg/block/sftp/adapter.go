@@ -0,0 +1,604 @@
+package sftp
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/sftp"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/params"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	DefaultNamespacePrefix = block.BlockstoreTypeSFTP + "://"
+
+	defaultPort = "22"
+	dialTimeout = 30 * time.Second
+	// multipartDirName is the directory, under the storage namespace, holding the parts of in-progress multipart uploads
+	multipartDirName = "_lakefs_multipart"
+	// tempFileSuffix is the suffix of the files written before they are renamed to the object path
+	tempFileSuffix = ".lakefs-tmp"
+	// posixRenameExtension renames over an existing file, the SFTP rename fails when the new path exists
+	posixRenameExtension = "posix-rename@openssh.com"
+)
+
+var (
+	ErrInventoryNotSupported = errors.New("inventory feature not implemented for sftp storage adapter")
+	ErrInvalidUploadIDFormat = errors.New("invalid upload id format")
+	ErrBadPath               = errors.New("bad path traversal blocked")
+	ErrMissingAuth           = errors.New("missing password or private key")
+)
+
+// Adapter stores objects as files on an SSH server, using the SFTP protocol.
+// Storage namespaces are directories relative to the configured path on the server: the storage namespace
+// 'sftp://repo1/' is stored under '<path>/repo1/'.
+type Adapter struct {
+	host          string
+	path          string
+	config        *ssh.ClientConfig
+	importEnabled bool
+	// importChecksums makes the walker read the files it lists to compute their MD5, see Walker
+	importChecksums bool
+
+	mu     sync.Mutex
+	client *sftp.Client
+}
+
+func WithImportEnabled(b bool) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.importEnabled = b
+	}
+}
+
+func WithImportChecksums(b bool) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.importChecksums = b
+	}
+}
+
+func NewAdapter(params params.SFTP, opts ...func(a *Adapter)) (*Adapter, error) {
+	config, err := clientConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	host := params.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, defaultPort)
+	}
+	a := &Adapter{
+		host:   host,
+		path:   path.Clean(params.Path),
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	// verify we can access the server
+	client, err := a.getClient()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.Stat(a.path); err != nil {
+		return nil, fmt.Errorf("sftp path %s: %w", a.path, err)
+	}
+	return a, nil
+}
+
+func clientConfig(params params.SFTP) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if params.PrivateKeyFile != "" {
+		key, err := os.ReadFile(params.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read private key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if params.Password != "" {
+		auth = append(auth, ssh.Password(params.Password))
+	}
+	if len(auth) == 0 {
+		return nil, ErrMissingAuth
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	if params.InsecureIgnoreHostKey {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec
+	} else {
+		var err error
+		hostKeyCallback, err = knownhosts.New(params.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("known hosts: %w", err)
+		}
+	}
+	return &ssh.ClientConfig{
+		User:            params.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	}, nil
+}
+
+// getClient returns the SFTP client, connecting to the server if there is no open connection
+func (a *Adapter) getClient() (*sftp.Client, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.client != nil {
+		return a.client, nil
+	}
+	conn, err := ssh.Dial("tcp", a.host, a.config)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", a.host, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sftp client: %w", err)
+	}
+	a.client = client
+	// reset the client when the connection is closed, so the next call will reconnect
+	go func() {
+		_ = client.Wait()
+		a.mu.Lock()
+		if a.client == client {
+			a.client = nil
+		}
+		a.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// filePath returns the path of the object on the server
+func (a *Adapter) filePath(obj block.ObjectPointer) (string, error) {
+	qk, err := a.resolve(obj.StorageNamespace, obj.Identifier, obj.IdentifierType)
+	if err != nil {
+		return "", err
+	}
+	return a.joinPath(qk.GetStorageNamespace(), qk.GetKey())
+}
+
+func (a *Adapter) resolve(storageNamespace, key string, identifierType block.IdentifierType) (block.CommonQualifiedKey, error) {
+	qk, err := block.DefaultResolveNamespace(storageNamespace, key, identifierType)
+	if err != nil {
+		return qk, err
+	}
+	if qk.GetStorageType() != block.StorageTypeSFTP {
+		return qk, fmt.Errorf("expected storage type %s: %w", block.BlockstoreTypeSFTP, block.ErrInvalidAddress)
+	}
+	return qk, nil
+}
+
+// joinPath joins the elements to the adapter path, verifying the result is under the adapter path
+func (a *Adapter) joinPath(elem ...string) (string, error) {
+	p := path.Join(append([]string{a.path}, elem...)...)
+	switch {
+	case a.path == ".":
+		if p == ".." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+			return "", fmt.Errorf("%s: %w", p, ErrBadPath)
+		}
+	case a.path == "/":
+	case p != a.path && !strings.HasPrefix(p, a.path+"/"):
+		return "", fmt.Errorf("%s: %w", p, ErrBadPath)
+	}
+	return p, nil
+}
+
+// relativeKey returns the key of a path on the server, relative to the adapter path
+func (a *Adapter) relativeKey(p string) string {
+	if a.path == "." {
+		return p
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(p, a.path), "/")
+}
+
+func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, _ int64, reader io.Reader, _ block.PutOpts) error {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return err
+	}
+	_, err = a.writeFile(ctx, p, reader)
+	return err
+}
+
+// writeFile writes the reader content to a file on the server, creating its parent directories. The content is written
+// to a temporary file in the same directory, renamed to the path once complete, so readers never see a partial file.
+func (a *Adapter) writeFile(ctx context.Context, p string, reader io.Reader) (int64, error) {
+	client, err := a.getClient()
+	if err != nil {
+		return 0, err
+	}
+	if err := client.MkdirAll(path.Dir(p)); err != nil {
+		return 0, err
+	}
+	tempPath := p + "." + uuid.NewString() + tempFileSuffix
+	f, err := client.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.ReadFrom(&contextReader{ctx: ctx, reader: reader})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = rename(client, tempPath, p)
+	}
+	if err != nil {
+		_ = client.Remove(tempPath)
+		return 0, err
+	}
+	return n, nil
+}
+
+// rename renames the file over the new path. Servers without the posix rename extension fail to rename over an
+// existing file, the existing file is removed first.
+func rename(client *sftp.Client, oldPath, newPath string) error {
+	if _, ok := client.HasExtension(posixRenameExtension); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+	err := client.Rename(oldPath, newPath)
+	if err == nil {
+		return nil
+	}
+	if _, statErr := client.Stat(newPath); statErr != nil {
+		return err
+	}
+	if err := client.Remove(newPath); err != nil {
+		return err
+	}
+	return client.Rename(oldPath, newPath)
+}
+
+// contextReader fails reading once the context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+func (a *Adapter) Get(_ context.Context, obj block.ObjectPointer, _ int64) (io.ReadCloser, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	f, err := a.open(p)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (a *Adapter) open(p string) (*sftp.File, error) {
+	client, err := a.getClient()
+	if err != nil {
+		return nil, err
+	}
+	f, err := client.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, block.ErrDataNotFound
+	}
+	return f, err
+}
+
+func (a *Adapter) GetRange(_ context.Context, obj block.ObjectPointer, startPosition int64, endPosition int64) (io.ReadCloser, error) {
+	if startPosition < 0 || endPosition < startPosition {
+		return nil, block.ErrBadIndex
+	}
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	f, err := a.open(p)
+	if err != nil {
+		return nil, err
+	}
+	return &struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.NewSectionReader(f, startPosition, endPosition-startPosition+1),
+		Closer: f,
+	}, nil
+}
+
+func (a *Adapter) GetWalker(uri *url.URL) (block.Walker, error) {
+	if err := block.ValidateStorageType(uri, block.StorageTypeSFTP); err != nil {
+		return nil, err
+	}
+	return NewWalker(a), nil
+}
+
+func (a *Adapter) GetPreSignedURL(_ context.Context, _ block.ObjectPointer, _ block.PreSignMode) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("sftp adapter presigned URL: %w", block.ErrOperationNotSupported)
+}
+
+func (a *Adapter) Exists(_ context.Context, obj block.ObjectPointer) (bool, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return false, err
+	}
+	client, err := a.getClient()
+	if err != nil {
+		return false, err
+	}
+	_, err = client.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *Adapter) GetProperties(_ context.Context, obj block.ObjectPointer) (block.Properties, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return block.Properties{}, err
+	}
+	client, err := a.getClient()
+	if err != nil {
+		return block.Properties{}, err
+	}
+	_, err = client.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return block.Properties{}, block.ErrDataNotFound
+	}
+	if err != nil {
+		return block.Properties{}, err
+	}
+	// No properties, just return that it exists
+	return block.Properties{}, nil
+}
+
+func (a *Adapter) Remove(_ context.Context, obj block.ObjectPointer) error {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return err
+	}
+	client, err := a.getClient()
+	if err != nil {
+		return err
+	}
+	if err := client.Remove(p); err != nil {
+		return err
+	}
+	// remove the directories left empty, up to the storage namespace
+	qk, err := a.resolve(obj.StorageNamespace, "", block.IdentifierTypeRelative)
+	if err != nil {
+		return nil
+	}
+	stopAt, err := a.joinPath(qk.GetStorageNamespace())
+	if err != nil {
+		return nil
+	}
+	for dir := path.Dir(p); dir != stopAt && strings.HasPrefix(dir, stopAt+"/"); dir = path.Dir(dir) {
+		// fails when the directory is not empty
+		if err := client.RemoveDirectory(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
+	source, err := a.filePath(sourceObj)
+	if err != nil {
+		return err
+	}
+	destination, err := a.filePath(destinationObj)
+	if err != nil {
+		return err
+	}
+	if source == destination {
+		// copy to itself, verify the object exists
+		_, err := a.GetProperties(ctx, sourceObj)
+		return err
+	}
+	f, err := a.open(source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = a.writeFile(ctx, destination, f)
+	return err
+}
+
+// partPath returns the path of a multipart upload part. Parts are stored under the storage namespace until the
+// upload is completed or aborted.
+func (a *Adapter) partPath(obj block.ObjectPointer, uploadID string, partNumber int) (string, error) {
+	if err := isValidUploadID(uploadID); err != nil {
+		return "", err
+	}
+	dir, err := a.filePath(block.ObjectPointer{
+		StorageNamespace: obj.StorageNamespace,
+		Identifier:       path.Join(multipartDirName, uploadID),
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+	if err != nil {
+		return "", err
+	}
+	if partNumber == 0 {
+		return dir, nil
+	}
+	return path.Join(dir, fmt.Sprintf("%05d", partNumber)), nil
+}
+
+func (a *Adapter) CreateMultiPartUpload(_ context.Context, _ block.ObjectPointer, _ *http.Request, _ block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	uidBytes := uuid.New()
+	uploadID := hex.EncodeToString(uidBytes[:])
+	return &block.CreateMultiPartUploadResponse{
+		UploadID: uploadID,
+	}, nil
+}
+
+func (a *Adapter) uploadPart(ctx context.Context, obj block.ObjectPointer, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	p, err := a.partPath(obj, uploadID, partNumber)
+	if err != nil {
+		return nil, err
+	}
+	md5Read := block.NewHashingReader(reader, block.HashFunctionMD5)
+	if _, err := a.writeFile(ctx, p, md5Read); err != nil {
+		return nil, err
+	}
+	return &block.UploadPartResponse{
+		ETag: hex.EncodeToString(md5Read.Md5.Sum(nil)),
+	}, nil
+}
+
+func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, _ int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	return a.uploadPart(ctx, obj, reader, uploadID, partNumber)
+}
+
+func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	r, err := a.Get(ctx, sourceObj, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return a.uploadPart(ctx, destinationObj, r, uploadID, partNumber)
+}
+
+func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	r, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return a.uploadPart(ctx, destinationObj, r, uploadID, partNumber)
+}
+
+func (a *Adapter) AbortMultiPartUpload(_ context.Context, obj block.ObjectPointer, uploadID string) error {
+	dir, err := a.partPath(obj, uploadID, 0)
+	if err != nil {
+		return err
+	}
+	client, err := a.getClient()
+	if err != nil {
+		return err
+	}
+	return client.RemoveAll(dir)
+}
+
+func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
+	dir, err := a.partPath(obj, uploadID, 0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]io.Reader, 0, len(multipartList.Part))
+	files := make([]*sftp.File, 0, len(multipartList.Part))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, part := range multipartList.Part {
+		partPath, err := a.partPath(obj, uploadID, part.PartNumber)
+		if err != nil {
+			return nil, err
+		}
+		f, err := a.open(partPath)
+		if err != nil {
+			return nil, fmt.Errorf("multipart upload %s part %d: %w", uploadID, part.PartNumber, err)
+		}
+		files = append(files, f)
+		parts = append(parts, f)
+	}
+	size, err := a.writeFile(ctx, p, io.MultiReader(parts...))
+	if err != nil {
+		return nil, fmt.Errorf("multipart upload unite for %s: %w", uploadID, err)
+	}
+	client, err := a.getClient()
+	if err != nil {
+		return nil, err
+	}
+	// If removal fails prefer to skip the error: "only" wasted space.
+	_ = client.RemoveAll(dir)
+	return &block.CompleteMultiPartUploadResponse{
+		ETag:          computeETag(multipartList.Part) + "-" + strconv.Itoa(len(multipartList.Part)),
+		ContentLength: size,
+	}, nil
+}
+
+func computeETag(parts []block.MultipartPart) string {
+	var etagHex []string
+	for _, p := range parts {
+		e := strings.Trim(p.ETag, `"`)
+		etagHex = append(etagHex, e)
+	}
+	s := strings.Join(etagHex, "")
+	b, _ := hex.DecodeString(s)
+	md5res := md5.Sum(b) //nolint:gosec
+	return hex.EncodeToString(md5res[:])
+}
+
+func isValidUploadID(uploadID string) error {
+	_, err := hex.DecodeString(uploadID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidUploadIDFormat, err)
+	}
+	return nil
+}
+
+func (a *Adapter) GenerateInventory(_ context.Context, _ logging.Logger, _ string, _ bool, _ []string) (block.Inventory, error) {
+	return nil, ErrInventoryNotSupported
+}
+
+func (a *Adapter) BlockstoreType() string {
+	return block.BlockstoreTypeSFTP
+}
+
+func (a *Adapter) GetStorageNamespaceInfo() block.StorageNamespaceInfo {
+	info := block.DefaultStorageNamespaceInfo(block.BlockstoreTypeSFTP)
+	info.PreSignSupport = false
+	info.DefaultNamespacePrefix = DefaultNamespacePrefix
+	info.ImportSupport = a.importEnabled
+	return info
+}
+
+func (a *Adapter) ResolveNamespace(storageNamespace, key string, identifierType block.IdentifierType) (block.QualifiedKey, error) {
+	qk, err := a.resolve(storageNamespace, key, identifierType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.joinPath(qk.GetStorageNamespace(), qk.GetKey()); err != nil {
+		return nil, err
+	}
+	return qk, nil
+}
+
+func (a *Adapter) RuntimeStats() map[string]string {
+	return nil
+}
@@ -0,0 +1,211 @@
+package sftp_test
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/blocktest"
+	"github.com/treeverse/lakefs/pkg/block/params"
+	"github.com/treeverse/lakefs/pkg/block/sftp"
+)
+
+const testStorageNamespace = "sftp://test"
+
+func getAdapter(t *testing.T, opts ...func(a *sftp.Adapter)) *sftp.Adapter {
+	t.Helper()
+	// the server serves the local filesystem
+	adapterPath := path.Join(serverPath, t.Name())
+	require.NoError(t, os.MkdirAll(adapterPath, 0o700))
+	adapter, err := sftp.NewAdapter(params.SFTP{
+		Host:                  serverAddr,
+		User:                  testUser,
+		Password:              testPassword,
+		InsecureIgnoreHostKey: true,
+		Path:                  adapterPath,
+	}, append([]func(a *sftp.Adapter){sftp.WithImportEnabled(true)}, opts...)...)
+	require.NoError(t, err)
+	return adapter
+}
+
+func TestSFTPAdapter(t *testing.T) {
+	adapter := getAdapter(t)
+	blocktest.AdapterTest(t, adapter, testStorageNamespace, block.BlockstoreTypeSFTP+"://external")
+}
+
+func TestAdapterFullAddress(t *testing.T) {
+	adapter := getAdapter(t)
+	ctx := context.Background()
+
+	const contents = "full address"
+	err := adapter.Put(ctx, block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "data/object",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+	require.NoError(t, err)
+
+	qk, err := adapter.ResolveNamespace(testStorageNamespace, "data/object", block.IdentifierTypeRelative)
+	require.NoError(t, err)
+	require.Equal(t, "sftp://test/data/object", qk.Format())
+	exists, err := adapter.Exists(ctx, block.ObjectPointer{
+		Identifier:     qk.Format(),
+		IdentifierType: block.IdentifierTypeFull,
+	})
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = adapter.ResolveNamespace(testStorageNamespace, "../../../etc/passwd", block.IdentifierTypeRelative)
+	require.ErrorIs(t, err, sftp.ErrBadPath)
+	_, err = adapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: "s3://bucket",
+		Identifier:       "data/object",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, -1)
+	require.ErrorIs(t, err, block.ErrInvalidAddress)
+}
+
+func TestAdapterBadCredentials(t *testing.T) {
+	_, err := sftp.NewAdapter(params.SFTP{
+		Host:                  serverAddr,
+		User:                  testUser,
+		Password:              "wrong",
+		InsecureIgnoreHostKey: true,
+		Path:                  serverPath,
+	})
+	require.Error(t, err)
+}
+
+func TestAdapterNamespace(t *testing.T) {
+	adapter := getAdapter(t)
+	expr, err := regexp.Compile(adapter.GetStorageNamespaceInfo().ValidityRegex)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name      string
+		Namespace string
+		Success   bool
+	}{
+		{
+			Name:      "valid_path",
+			Namespace: "sftp://test/path/to/repo1",
+			Success:   true,
+		},
+		{
+			Name:      "local",
+			Namespace: "local://test/path/to/repo1",
+			Success:   false,
+		},
+		{
+			Name:      "invalid_string",
+			Namespace: "this is a bad string",
+			Success:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Success, expr.MatchString(tt.Namespace))
+		})
+	}
+}
+
+func TestWalker(t *testing.T) {
+	ctx := context.Background()
+	// "data-1" sorts before "data/", and "data.txt" after it
+	keys := []string{"data/b", "data-1", "data/a/c", "data.txt", "data/a-b", "other"}
+	const contents = "walker"
+
+	for _, checksums := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checksums=%t", checksums), func(t *testing.T) {
+			adapter := getAdapter(t, sftp.WithImportChecksums(checksums))
+			for _, key := range keys {
+				err := adapter.Put(ctx, block.ObjectPointer{
+					StorageNamespace: testStorageNamespace,
+					Identifier:       key,
+					IdentifierType:   block.IdentifierTypeRelative,
+				}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+				require.NoError(t, err)
+			}
+			uri, err := url.Parse(testStorageNamespace + "/data")
+			require.NoError(t, err)
+
+			walk := func(after string) []string {
+				walker, err := adapter.GetWalker(uri)
+				require.NoError(t, err)
+				var walked []string
+				err = walker.Walk(ctx, uri, block.WalkOptions{After: after}, func(e block.ObjectStoreEntry) error {
+					walked = append(walked, e.RelativeKey)
+					if checksums {
+						sum := md5.Sum([]byte(contents)) //nolint:gosec
+						require.Equal(t, hex.EncodeToString(sum[:]), e.ETag)
+					} else {
+						require.NotEmpty(t, e.ETag)
+					}
+					return nil
+				})
+				require.NoError(t, err)
+				require.False(t, walker.Marker().HasMore)
+				return walked
+			}
+			require.Equal(t, []string{"data-1", "data.txt", "data/a-b", "data/a/c", "data/b"}, walk(""))
+			require.Equal(t, []string{"data/a/c", "data/b"}, walk("test/data/a-b"))
+			require.Equal(t, []string{"data/b"}, walk("test/data/a/c"))
+		})
+	}
+}
+
+func TestAdapterWrites(t *testing.T) {
+	ctx := context.Background()
+	adapter := getAdapter(t)
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "data/object",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	const contents = "contents"
+	require.NoError(t, adapter.Put(ctx, obj, int64(len(contents)), strings.NewReader(contents), block.PutOpts{}))
+	dir := path.Join(serverPath, t.Name(), "test", "data")
+	requireContents := func(t *testing.T) {
+		t.Helper()
+		r, err := adapter.Get(ctx, obj, 0)
+		require.NoError(t, err)
+		defer func() { _ = r.Close() }()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, contents, string(data))
+		// no temporary files are left behind
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	}
+
+	t.Run("copy_to_itself", func(t *testing.T) {
+		require.NoError(t, adapter.Copy(ctx, obj, obj))
+		requireContents(t)
+	})
+
+	t.Run("canceled_put", func(t *testing.T) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := adapter.Put(canceledCtx, obj, 3, strings.NewReader("new"), block.PutOpts{})
+		require.ErrorIs(t, err, context.Canceled)
+		requireContents(t)
+	})
+
+	t.Run("failed_put", func(t *testing.T) {
+		err := adapter.Put(ctx, obj, 3, io.MultiReader(strings.NewReader("new"), iotest.ErrReader(io.ErrUnexpectedEOF)), block.PutOpts{})
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		requireContents(t)
+	})
+}
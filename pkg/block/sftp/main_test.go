@@ -0,0 +1,121 @@
+package sftp_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testUser     = "lakefs"
+	testPassword = "secret"
+)
+
+var (
+	// serverAddr is the address of the in-process SFTP server
+	serverAddr string
+	// serverPath is the directory on the server used by the tests
+	serverPath string
+
+	errBadCredentials = errors.New("bad credentials")
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	serverPath, err = os.MkdirTemp("", "sftp-adapter-test")
+	if err != nil {
+		log.Fatalf("create server dir: %s", err)
+	}
+	listener, err := runServer()
+	if err != nil {
+		log.Fatalf("run sftp server: %s", err)
+	}
+	serverAddr = listener.Addr().String()
+
+	code := m.Run()
+	_ = listener.Close()
+	_ = os.RemoveAll(serverPath)
+	os.Exit(code)
+}
+
+// runServer runs an SSH server serving the local filesystem over the sftp subsystem
+func runServer() (net.Listener, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() != testUser || string(password) != testPassword {
+				return nil, errBadCredentials
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+	return listener, nil
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range channelRequests {
+				// subsystem request payload is the length prefixed subsystem name
+				const lenSize = 4
+				ok := req.Type == "subsystem" && len(req.Payload) > lenSize &&
+					int(binary.BigEndian.Uint32(req.Payload)) == len(req.Payload)-lenSize &&
+					string(req.Payload[lenSize:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					_ = channel.Close()
+					return
+				}
+				_ = server.Serve()
+				_ = server.Close()
+			}
+		}()
+	}
+}
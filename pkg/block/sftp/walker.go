@@ -0,0 +1,163 @@
+package sftp
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"github.com/treeverse/lakefs/pkg/block"
+)
+
+// Walker lists the files on the server with object store semantics: the storage URI is a key prefix, and entries
+// are listed in lexicographical order of their keys. Directories are read one at a time while walking, so only the
+// entries of the directories on the current path are kept in memory.
+type Walker struct {
+	adapter *Adapter
+	mark    block.Mark
+}
+
+func NewWalker(adapter *Adapter) *Walker {
+	return &Walker{
+		adapter: adapter,
+		mark:    block.Mark{HasMore: true},
+	}
+}
+
+func (w *Walker) Walk(ctx context.Context, storageURI *url.URL, op block.WalkOptions, walkFn func(e block.ObjectStoreEntry) error) error {
+	if err := block.ValidateStorageType(storageURI, block.StorageTypeSFTP); err != nil {
+		return err
+	}
+	prefix := strings.TrimPrefix(storageURI.Host+storageURI.Path, "/")
+	// basePath is the directory relative to which the walk is done, see the s3 walker
+	var basePath string
+	if idx := strings.LastIndex(prefix, "/"); idx != -1 {
+		basePath = prefix[:idx+1]
+	}
+	root, err := w.adapter.joinPath(basePath)
+	if err != nil {
+		return err
+	}
+	client, err := w.adapter.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.Stat(root)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// nothing to walk
+	case err != nil:
+		return err
+	default:
+		if err := w.walkDir(ctx, client, root, prefix, basePath, op, walkFn); err != nil {
+			return err
+		}
+	}
+	w.mark = block.Mark{}
+	return nil
+}
+
+// walkDir calls walkFn for the files under dir that have the prefix and come after op.After, in the order of their
+// keys. The entries of a directory are ordered by key, with "/" appended to the names of subdirectories, so walking
+// each subdirectory in its place lists all the keys in order.
+func (w *Walker) walkDir(ctx context.Context, client *sftp.Client, dir, prefix, basePath string, op block.WalkOptions, walkFn func(e block.ObjectStoreEntry) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	infos, err := client.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read dir %s: %w", dir, err)
+	}
+	type dirEntry struct {
+		key  string
+		info os.FileInfo
+	}
+	entries := make([]dirEntry, 0, len(infos))
+	for _, info := range infos {
+		key := w.adapter.relativeKey(path.Join(dir, info.Name()))
+		if info.IsDir() {
+			key += "/"
+		}
+		entries = append(entries, dirEntry{key: key, info: info})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	for _, ent := range entries {
+		if ent.info.IsDir() {
+			// skip directories that cannot hold keys with the prefix, that hold only keys up to op.After, and
+			// in-progress multipart uploads
+			dirKey := ent.key
+			if ent.info.Name() == multipartDirName ||
+				!strings.HasPrefix(dirKey, prefix) && !strings.HasPrefix(prefix, dirKey) ||
+				dirKey < op.After && !strings.HasPrefix(op.After, dirKey) {
+				continue
+			}
+			if err := w.walkDir(ctx, client, path.Join(dir, ent.info.Name()), prefix, basePath, op, walkFn); err != nil {
+				return err
+			}
+			continue
+		}
+		key := ent.key
+		// skip files being written
+		if !ent.info.Mode().IsRegular() || strings.HasSuffix(key, tempFileSuffix) || !strings.HasPrefix(key, prefix) || key <= op.After {
+			continue
+		}
+		etag, err := w.fileETag(key, ent.info)
+		if err != nil {
+			return err
+		}
+		w.mark.LastKey = key
+		err = walkFn(block.ObjectStoreEntry{
+			FullKey:     key,
+			RelativeKey: strings.TrimPrefix(key, basePath),
+			Address:     DefaultNamespacePrefix + key,
+			ETag:        etag,
+			Mtime:       ent.info.ModTime(),
+			Size:        ent.info.Size(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileETag returns the MD5 of the file content when the adapter computes import checksums. Otherwise, it returns an
+// ETag of the modification time and size of the file, which changes when the file is replaced without reading it.
+func (w *Walker) fileETag(key string, info os.FileInfo) (string, error) {
+	if !w.adapter.importChecksums {
+		return fmt.Sprintf("%x-%x", info.ModTime().Unix(), info.Size()), nil
+	}
+	p, err := w.adapter.joinPath(key)
+	if err != nil {
+		return "", err
+	}
+	f, err := w.adapter.open(path.Clean(p))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	hash := md5.New() //nolint:gosec
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (w *Walker) Marker() block.Mark {
+	return w.mark
+}
+
+func (w *Walker) GetSkippedEntries() []block.ObjectStoreEntry {
+	return nil
+}
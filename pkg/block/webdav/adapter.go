@@ -0,0 +1,401 @@
+package webdav
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/params"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+const (
+	DefaultNamespacePrefix = block.BlockstoreTypeWebDAV + "://"
+
+	// multipartDirName is the collection, under the storage namespace, holding the parts of in-progress multipart uploads
+	multipartDirName = "_lakefs_multipart"
+)
+
+var (
+	ErrInventoryNotSupported = errors.New("inventory feature not implemented for webdav storage adapter")
+	ErrInvalidUploadIDFormat = errors.New("invalid upload id format")
+	ErrBadPath               = errors.New("bad path traversal blocked")
+	ErrInvalidEndpoint       = errors.New("invalid endpoint")
+)
+
+// Adapter stores objects as files on a WebDAV server.
+// Storage namespaces are collections relative to the configured endpoint: the storage namespace
+// 'webdav://repo1/' is stored under '<endpoint>/repo1/'.
+type Adapter struct {
+	client        *client
+	importEnabled bool
+}
+
+func WithImportEnabled(b bool) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.importEnabled = b
+	}
+}
+
+func WithHTTPClient(c *http.Client) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.client.httpClient = c
+	}
+}
+
+func NewAdapter(params params.WebDAV, opts ...func(a *Adapter)) (*Adapter, error) {
+	endpoint, err := url.Parse(params.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("webdav endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("webdav endpoint %s: %w", params.Endpoint, ErrInvalidEndpoint)
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/")
+	a := &Adapter{
+		client: &client{
+			httpClient: http.DefaultClient,
+			endpoint:   endpoint,
+			user:       params.User,
+			password:   params.Password,
+		},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	// verify we can access the server
+	if _, err := a.client.stat(context.Background(), "/"); err != nil {
+		return nil, fmt.Errorf("webdav endpoint %s: %w", params.Endpoint, err)
+	}
+	return a, nil
+}
+
+// filePath returns the path of the object, relative to the endpoint
+func (a *Adapter) filePath(obj block.ObjectPointer) (string, error) {
+	qk, err := a.resolve(obj.StorageNamespace, obj.Identifier, obj.IdentifierType)
+	if err != nil {
+		return "", err
+	}
+	return joinPath(qk.GetStorageNamespace(), qk.GetKey())
+}
+
+func (a *Adapter) resolve(storageNamespace, key string, identifierType block.IdentifierType) (block.CommonQualifiedKey, error) {
+	qk, err := block.DefaultResolveNamespace(storageNamespace, key, identifierType)
+	if err != nil {
+		return qk, err
+	}
+	if qk.GetStorageType() != block.StorageTypeWebDAV {
+		return qk, fmt.Errorf("expected storage type %s: %w", block.BlockstoreTypeWebDAV, block.ErrInvalidAddress)
+	}
+	return qk, nil
+}
+
+// joinPath joins the elements to a path relative to the endpoint, verifying the result is under the endpoint
+func joinPath(elem ...string) (string, error) {
+	p := path.Join(elem...)
+	if p == ".." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+		return "", fmt.Errorf("%s: %w", p, ErrBadPath)
+	}
+	return p, nil
+}
+
+func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, _ block.PutOpts) error {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return err
+	}
+	return a.client.put(ctx, p, sizeBytes, reader)
+}
+
+func (a *Adapter) Get(ctx context.Context, obj block.ObjectPointer, _ int64) (io.ReadCloser, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.get(ctx, p, 0, 0)
+}
+
+func (a *Adapter) GetRange(ctx context.Context, obj block.ObjectPointer, startPosition int64, endPosition int64) (io.ReadCloser, error) {
+	if startPosition < 0 || endPosition < startPosition {
+		return nil, block.ErrBadIndex
+	}
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.get(ctx, p, startPosition, endPosition-startPosition+1)
+}
+
+func (a *Adapter) GetWalker(uri *url.URL) (block.Walker, error) {
+	if err := block.ValidateStorageType(uri, block.StorageTypeWebDAV); err != nil {
+		return nil, err
+	}
+	return NewWalker(a), nil
+}
+
+func (a *Adapter) GetPreSignedURL(_ context.Context, _ block.ObjectPointer, _ block.PreSignMode) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("webdav adapter presigned URL: %w", block.ErrOperationNotSupported)
+}
+
+func (a *Adapter) Exists(ctx context.Context, obj block.ObjectPointer) (bool, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return false, err
+	}
+	_, err = a.client.stat(ctx, p)
+	if errors.Is(err, block.ErrDataNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (block.Properties, error) {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return block.Properties{}, err
+	}
+	if _, err := a.client.stat(ctx, p); err != nil {
+		return block.Properties{}, err
+	}
+	// No properties, just return that it exists
+	return block.Properties{}, nil
+}
+
+func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
+	p, err := a.filePath(obj)
+	if err != nil {
+		return err
+	}
+	if err := a.client.remove(ctx, p); err != nil {
+		return err
+	}
+	// remove the collections left empty, up to the storage namespace
+	qk, err := a.resolve(obj.StorageNamespace, "", block.IdentifierTypeRelative)
+	if err != nil {
+		return nil
+	}
+	stopAt, err := joinPath(qk.GetStorageNamespace())
+	if err != nil {
+		return nil
+	}
+	for dir := path.Dir(p); dir != stopAt && strings.HasPrefix(dir, stopAt+"/"); dir = path.Dir(dir) {
+		members, err := a.client.readDir(ctx, dir)
+		if err != nil || len(members) > 0 {
+			break
+		}
+		if err := a.client.remove(ctx, dir+"/"); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
+	source, err := a.filePath(sourceObj)
+	if err != nil {
+		return err
+	}
+	destination, err := a.filePath(destinationObj)
+	if err != nil {
+		return err
+	}
+	return a.client.copy(ctx, source, destination)
+}
+
+// partPath returns the path of a multipart upload part. Parts are stored under the storage namespace until the
+// upload is completed or aborted.
+func (a *Adapter) partPath(obj block.ObjectPointer, uploadID string, partNumber int) (string, error) {
+	if err := isValidUploadID(uploadID); err != nil {
+		return "", err
+	}
+	dir, err := a.filePath(block.ObjectPointer{
+		StorageNamespace: obj.StorageNamespace,
+		Identifier:       path.Join(multipartDirName, uploadID),
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+	if err != nil {
+		return "", err
+	}
+	if partNumber == 0 {
+		return dir, nil
+	}
+	return path.Join(dir, fmt.Sprintf("%05d", partNumber)), nil
+}
+
+func (a *Adapter) CreateMultiPartUpload(_ context.Context, _ block.ObjectPointer, _ *http.Request, _ block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	uidBytes := uuid.New()
+	uploadID := hex.EncodeToString(uidBytes[:])
+	return &block.CreateMultiPartUploadResponse{
+		UploadID: uploadID,
+	}, nil
+}
+
+func (a *Adapter) uploadPart(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	p, err := a.partPath(obj, uploadID, partNumber)
+	if err != nil {
+		return nil, err
+	}
+	md5Read := block.NewHashingReader(reader, block.HashFunctionMD5)
+	if err := a.client.put(ctx, p, sizeBytes, md5Read); err != nil {
+		return nil, err
+	}
+	return &block.UploadPartResponse{
+		ETag: hex.EncodeToString(md5Read.Md5.Sum(nil)),
+	}, nil
+}
+
+func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	return a.uploadPart(ctx, obj, sizeBytes, reader, uploadID, partNumber)
+}
+
+func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	r, err := a.Get(ctx, sourceObj, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return a.uploadPart(ctx, destinationObj, -1, r, uploadID, partNumber)
+}
+
+func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	r, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return a.uploadPart(ctx, destinationObj, endPosition-startPosition+1, r, uploadID, partNumber)
+}
+
+func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string) error {
+	dir, err := a.partPath(obj, uploadID, 0)
+	if err != nil {
+		return err
+	}
+	return a.client.remove(ctx, dir+"/")
+}
+
+func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
+	dir, err := a.partPath(obj, uploadID, 0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := a.filePath(obj)
+	if err != nil {
+		return nil, err
+	}
+	partPaths := make([]string, 0, len(multipartList.Part))
+	for _, part := range multipartList.Part {
+		partPath, err := a.partPath(obj, uploadID, part.PartNumber)
+		if err != nil {
+			return nil, err
+		}
+		partPaths = append(partPaths, partPath)
+	}
+
+	// stream the parts, one after the other, into the object
+	pr, pw := io.Pipe()
+	go func() {
+		for i, partPath := range partPaths {
+			r, err := a.client.get(ctx, partPath, 0, 0)
+			if err != nil {
+				_ = pw.CloseWithError(fmt.Errorf("multipart upload %s part %d: %w", uploadID, multipartList.Part[i].PartNumber, err))
+				return
+			}
+			_, err = io.Copy(pw, r)
+			_ = r.Close()
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+	counter := &countingReader{Reader: pr}
+	err = a.client.put(ctx, p, -1, counter)
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, fmt.Errorf("multipart upload unite for %s: %w", uploadID, err)
+	}
+	// If removal fails prefer to skip the error: "only" wasted space.
+	_ = a.client.remove(ctx, dir+"/")
+	return &block.CompleteMultiPartUploadResponse{
+		ETag:          computeETag(multipartList.Part) + "-" + strconv.Itoa(len(multipartList.Part)),
+		ContentLength: counter.n,
+	}, nil
+}
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func computeETag(parts []block.MultipartPart) string {
+	var etagHex []string
+	for _, p := range parts {
+		e := strings.Trim(p.ETag, `"`)
+		etagHex = append(etagHex, e)
+	}
+	s := strings.Join(etagHex, "")
+	b, _ := hex.DecodeString(s)
+	md5res := md5.Sum(b) //nolint:gosec
+	return hex.EncodeToString(md5res[:])
+}
+
+func isValidUploadID(uploadID string) error {
+	_, err := hex.DecodeString(uploadID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidUploadIDFormat, err)
+	}
+	return nil
+}
+
+func (a *Adapter) GenerateInventory(_ context.Context, _ logging.Logger, _ string, _ bool, _ []string) (block.Inventory, error) {
+	return nil, ErrInventoryNotSupported
+}
+
+func (a *Adapter) BlockstoreType() string {
+	return block.BlockstoreTypeWebDAV
+}
+
+func (a *Adapter) GetStorageNamespaceInfo() block.StorageNamespaceInfo {
+	info := block.DefaultStorageNamespaceInfo(block.BlockstoreTypeWebDAV)
+	info.PreSignSupport = false
+	info.DefaultNamespacePrefix = DefaultNamespacePrefix
+	info.ImportSupport = a.importEnabled
+	return info
+}
+
+func (a *Adapter) ResolveNamespace(storageNamespace, key string, identifierType block.IdentifierType) (block.QualifiedKey, error) {
+	qk, err := a.resolve(storageNamespace, key, identifierType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := joinPath(qk.GetStorageNamespace(), qk.GetKey()); err != nil {
+		return nil, err
+	}
+	return qk, nil
+}
+
+func (a *Adapter) RuntimeStats() map[string]string {
+	return nil
+}
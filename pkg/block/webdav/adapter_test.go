@@ -0,0 +1,110 @@
+package webdav_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/blocktest"
+	"github.com/treeverse/lakefs/pkg/block/params"
+	"github.com/treeverse/lakefs/pkg/block/webdav"
+)
+
+const testStorageNamespace = "webdav://test"
+
+func getAdapter(t *testing.T) *webdav.Adapter {
+	t.Helper()
+	// each test uses its own collection on the server
+	require.NoError(t, os.MkdirAll(path.Join(serverPath, t.Name()), 0o700))
+	adapter, err := webdav.NewAdapter(params.WebDAV{
+		Endpoint: serverURL + "/" + t.Name(),
+		User:     testUser,
+		Password: testPassword,
+	}, webdav.WithImportEnabled(true))
+	require.NoError(t, err)
+	return adapter
+}
+
+func TestWebDAVAdapter(t *testing.T) {
+	adapter := getAdapter(t)
+	blocktest.AdapterTest(t, adapter, testStorageNamespace, block.BlockstoreTypeWebDAV+"://external")
+}
+
+func TestAdapterFullAddress(t *testing.T) {
+	adapter := getAdapter(t)
+	ctx := context.Background()
+
+	const contents = "full address"
+	err := adapter.Put(ctx, block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "data/object",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+	require.NoError(t, err)
+
+	qk, err := adapter.ResolveNamespace(testStorageNamespace, "data/object", block.IdentifierTypeRelative)
+	require.NoError(t, err)
+	require.Equal(t, "webdav://test/data/object", qk.Format())
+	exists, err := adapter.Exists(ctx, block.ObjectPointer{
+		Identifier:     qk.Format(),
+		IdentifierType: block.IdentifierTypeFull,
+	})
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, err = adapter.ResolveNamespace(testStorageNamespace, "../../../etc/passwd", block.IdentifierTypeRelative)
+	require.ErrorIs(t, err, webdav.ErrBadPath)
+	_, err = adapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: "s3://bucket",
+		Identifier:       "data/object",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, -1)
+	require.ErrorIs(t, err, block.ErrInvalidAddress)
+}
+
+func TestAdapterBadCredentials(t *testing.T) {
+	_, err := webdav.NewAdapter(params.WebDAV{
+		Endpoint: serverURL,
+		User:     testUser,
+		Password: "wrong",
+	})
+	require.ErrorIs(t, err, webdav.ErrUnexpectedStatus)
+}
+
+func TestAdapterNamespace(t *testing.T) {
+	adapter := getAdapter(t)
+	expr, err := regexp.Compile(adapter.GetStorageNamespaceInfo().ValidityRegex)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name      string
+		Namespace string
+		Success   bool
+	}{
+		{
+			Name:      "valid_path",
+			Namespace: "webdav://test/path/to/repo1",
+			Success:   true,
+		},
+		{
+			Name:      "local",
+			Namespace: "local://test/path/to/repo1",
+			Success:   false,
+		},
+		{
+			Name:      "invalid_string",
+			Namespace: "this is a bad string",
+			Success:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Success, expr.MatchString(tt.Namespace))
+		})
+	}
+}
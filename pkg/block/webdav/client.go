@@ -0,0 +1,279 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+)
+
+const (
+	methodPropfind = "PROPFIND"
+	methodMkcol    = "MKCOL"
+	methodCopy     = "COPY"
+
+	propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/><D:getcontentlength/><D:getlastmodified/><D:getetag/></D:prop></D:propfind>`
+)
+
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// client is a minimal WebDAV client (RFC 4918), supporting the methods required to store objects
+type client struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	user       string
+	password   string
+}
+
+// resource is a member of a collection, as returned by PROPFIND
+type resource struct {
+	// path of the resource, relative to the endpoint
+	path         string
+	isCollection bool
+	size         int64
+	lastModified time.Time
+	etag         string
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ETag          string `xml:"getetag"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// url returns the URL of a path relative to the endpoint
+func (c *client) url(p string) string {
+	u := *c.endpoint
+	u.Path = path.Join(c.endpoint.Path, p)
+	if strings.HasSuffix(p, "/") {
+		u.Path += "/"
+	}
+	return u.String()
+}
+
+func (c *client) do(ctx context.Context, method, p string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url(p), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	return c.httpClient.Do(req)
+}
+
+// doExpect performs the request and discards the response, returning an error if the status is not one of the expected statuses
+func (c *client) doExpect(ctx context.Context, method, p string, body io.Reader, header http.Header, expected ...int) (int, error) {
+	resp, err := c.do(ctx, method, p, body, header)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if err := checkStatus(method, p, resp.StatusCode, expected...); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+func checkStatus(method, p string, status int, expected ...int) error {
+	for _, s := range expected {
+		if status == s {
+			return nil
+		}
+	}
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, p, block.ErrDataNotFound)
+	}
+	return fmt.Errorf("%s %s: %w: %d", method, p, ErrUnexpectedStatus, status)
+}
+
+// get returns the content of a file. When length is positive, only length bytes starting at offset are returned.
+func (c *client) get(ctx context.Context, p string, offset, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	if length > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+	resp, err := c.do(ctx, http.MethodGet, p, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(http.MethodGet, p, resp.StatusCode, http.StatusOK, http.StatusPartialContent); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if length <= 0 || resp.StatusCode == http.StatusPartialContent {
+		return resp.Body, nil
+	}
+	// server ignored the range request
+	if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil && !errors.Is(err, io.EOF) {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return &struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(resp.Body, length),
+		Closer: resp.Body,
+	}, nil
+}
+
+// put writes a file, creating its parent collections
+func (c *client) put(ctx context.Context, p string, size int64, reader io.Reader) error {
+	if err := c.mkcolAll(ctx, path.Dir(p)); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.url(p), reader)
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return checkStatus(http.MethodPut, p, resp.StatusCode, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+}
+
+// mkcolAll creates a collection and its missing parents
+func (c *client) mkcolAll(ctx context.Context, p string) error {
+	p = strings.Trim(p, "/")
+	if p == "" || p == "." {
+		return nil
+	}
+	status, err := c.doExpect(ctx, methodMkcol, p+"/", nil, nil, http.StatusCreated, http.StatusMethodNotAllowed)
+	switch {
+	case err == nil:
+		// 405 - the collection already exists
+		return nil
+	case status == http.StatusConflict:
+		// 409 - a parent collection is missing
+		if err := c.mkcolAll(ctx, path.Dir(p)); err != nil {
+			return err
+		}
+		_, err = c.doExpect(ctx, methodMkcol, p+"/", nil, nil, http.StatusCreated, http.StatusMethodNotAllowed)
+		return err
+	default:
+		return err
+	}
+}
+
+func (c *client) stat(ctx context.Context, p string) (*resource, error) {
+	resources, err := c.propfind(ctx, p, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("%s %s: %w", methodPropfind, p, block.ErrDataNotFound)
+	}
+	return &resources[0], nil
+}
+
+// readDir returns the members of a collection
+func (c *client) readDir(ctx context.Context, p string) ([]resource, error) {
+	p = strings.TrimSuffix(p, "/") + "/"
+	resources, err := c.propfind(ctx, p, "1")
+	if err != nil {
+		return nil, err
+	}
+	members := make([]resource, 0, len(resources))
+	self := strings.Trim(p, "/")
+	for _, r := range resources {
+		if strings.Trim(r.path, "/") != self {
+			members = append(members, r)
+		}
+	}
+	return members, nil
+}
+
+func (c *client) propfind(ctx context.Context, p, depth string) ([]resource, error) {
+	header := http.Header{}
+	header.Set("Depth", depth)
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+	resp, err := c.do(ctx, methodPropfind, p, strings.NewReader(propfindBody), header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkStatus(methodPropfind, p, resp.StatusCode, http.StatusMultiStatus); err != nil {
+		return nil, err
+	}
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", methodPropfind, p, err)
+	}
+	resources := make([]resource, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		hrefURL, err := url.Parse(r.Href)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s href %s: %w", methodPropfind, p, r.Href, err)
+		}
+		res := resource{
+			path: strings.TrimPrefix(strings.TrimPrefix(hrefURL.Path, c.endpoint.Path), "/"),
+		}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			res.isCollection = ps.Prop.ResourceType.Collection != nil
+			if ps.Prop.ContentLength != "" {
+				res.size, _ = strconv.ParseInt(ps.Prop.ContentLength, 10, 64)
+			}
+			if ps.Prop.LastModified != "" {
+				res.lastModified, _ = http.ParseTime(ps.Prop.LastModified)
+			}
+			res.etag = strings.Trim(ps.Prop.ETag, `"`)
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+func (c *client) copy(ctx context.Context, source, destination string) error {
+	if err := c.mkcolAll(ctx, path.Dir(destination)); err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Destination", c.url(destination))
+	header.Set("Overwrite", "T")
+	_, err := c.doExpect(ctx, methodCopy, source, nil, header, http.StatusCreated, http.StatusNoContent)
+	return err
+}
+
+// remove deletes a file, or a collection and all its members
+func (c *client) remove(ctx context.Context, p string) error {
+	_, err := c.doExpect(ctx, http.MethodDelete, p, nil, nil, http.StatusOK, http.StatusNoContent)
+	return err
+}
@@ -0,0 +1,49 @@
+package webdav_test
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+const (
+	testUser     = "lakefs"
+	testPassword = "secret"
+)
+
+var (
+	// serverURL is the URL of the in-process WebDAV server
+	serverURL string
+	// serverPath is the directory served by the server
+	serverPath string
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	serverPath, err = os.MkdirTemp("", "webdav-adapter-test")
+	if err != nil {
+		log.Fatalf("create server dir: %s", err)
+	}
+	handler := &webdav.Handler{
+		FileSystem: webdav.Dir(serverPath),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != testUser || password != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	serverURL = server.URL
+
+	code := m.Run()
+	server.Close()
+	_ = os.RemoveAll(serverPath)
+	os.Exit(code)
+}
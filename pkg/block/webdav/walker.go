@@ -0,0 +1,121 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/treeverse/lakefs/pkg/block"
+)
+
+// Walker lists the files on the server with object store semantics: the storage URI is a key prefix, and entries
+// are listed in lexicographical order of their keys. Collections are read one at a time while walking, so only the
+// members of the collections on the current path are kept in memory.
+type Walker struct {
+	adapter *Adapter
+	mark    block.Mark
+}
+
+func NewWalker(adapter *Adapter) *Walker {
+	return &Walker{
+		adapter: adapter,
+		mark:    block.Mark{HasMore: true},
+	}
+}
+
+func (w *Walker) Walk(ctx context.Context, storageURI *url.URL, op block.WalkOptions, walkFn func(e block.ObjectStoreEntry) error) error {
+	if err := block.ValidateStorageType(storageURI, block.StorageTypeWebDAV); err != nil {
+		return err
+	}
+	prefix := strings.TrimPrefix(storageURI.Host+storageURI.Path, "/")
+	// basePath is the collection relative to which the walk is done, see the s3 walker
+	var basePath string
+	if idx := strings.LastIndex(prefix, "/"); idx != -1 {
+		basePath = prefix[:idx+1]
+	}
+	root, err := joinPath(basePath)
+	if err != nil {
+		return err
+	}
+	if root == "." {
+		root = ""
+	}
+	if err := w.walkCollection(ctx, root, prefix, basePath, op, walkFn); err != nil {
+		return err
+	}
+	w.mark = block.Mark{}
+	return nil
+}
+
+// walkCollection calls walkFn for the files under the collection that have the prefix and come after op.After, in the
+// order of their keys. The members of a collection are ordered by key, with "/" appended to the names of collections,
+// so walking each member collection in its place lists all the keys in order.
+func (w *Walker) walkCollection(ctx context.Context, dir, prefix, basePath string, op block.WalkOptions, walkFn func(e block.ObjectStoreEntry) error) error {
+	members, err := w.adapter.client.readDir(ctx, dir)
+	if err != nil {
+		if dir != "" && errors.Is(err, block.ErrDataNotFound) {
+			return nil
+		}
+		return err
+	}
+	type member struct {
+		key string
+		resource
+	}
+	entries := make([]member, 0, len(members))
+	for _, m := range members {
+		key := strings.Trim(m.path, "/")
+		if m.isCollection {
+			key += "/"
+		}
+		entries = append(entries, member{key: key, resource: m})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	for _, m := range entries {
+		key := m.key
+		if m.isCollection {
+			// skip collections that cannot hold keys with the prefix, that hold only keys up to op.After, and
+			// in-progress multipart uploads
+			name := strings.TrimSuffix(key, "/")
+			name = name[strings.LastIndex(name, "/")+1:]
+			if name == multipartDirName ||
+				!strings.HasPrefix(key, prefix) && !strings.HasPrefix(prefix, key) ||
+				key < op.After && !strings.HasPrefix(op.After, key) {
+				continue
+			}
+			if err := w.walkCollection(ctx, strings.TrimSuffix(key, "/"), prefix, basePath, op, walkFn); err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasPrefix(key, prefix) || key <= op.After {
+			continue
+		}
+		w.mark.LastKey = key
+		err := walkFn(block.ObjectStoreEntry{
+			FullKey:     key,
+			RelativeKey: strings.TrimPrefix(key, basePath),
+			Address:     DefaultNamespacePrefix + key,
+			ETag:        m.etag,
+			Mtime:       m.lastModified,
+			Size:        m.size,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) Marker() block.Mark {
+	return w.mark
+}
+
+func (w *Walker) GetSkippedEntries() []block.ObjectStoreEntry {
+	return nil
+}
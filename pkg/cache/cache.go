@@ -12,6 +12,10 @@ type SetFn func() (v interface{}, err error)
 
 type Cache interface {
 	GetOrSet(k interface{}, setFn SetFn) (v interface{}, err error)
+	// Remove drops the cached value of the key, if any
+	Remove(k interface{})
+	// Purge drops all cached values
+	Purge()
 }
 
 type GetSetCache struct {
@@ -45,6 +49,14 @@ func (c *GetSetCache) GetOrSet(k interface{}, setFn SetFn) (v interface{}, err e
 	})
 }
 
+func (c *GetSetCache) Remove(k interface{}) {
+	c.lru.Remove(k)
+}
+
+func (c *GetSetCache) Purge() {
+	c.lru.Purge()
+}
+
 func NewJitterFn(jitter time.Duration) JitterFn {
 	if jitter <= 0 {
 		return func() time.Duration {
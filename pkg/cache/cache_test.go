@@ -47,6 +47,34 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestCacheRemove(t *testing.T) {
+	c := cache.NewCache(10, time.Hour*12, cache.NewJitterFn(time.Millisecond))
+	numCalls := 0
+	get := func(k int) {
+		_, err := c.GetOrSet(k, func() (interface{}, error) {
+			numCalls++
+			return k, nil
+		})
+		if err != nil {
+			t.Fatal("GetOrSet")
+		}
+	}
+	get(1)
+	get(2)
+	c.Remove(1)
+	get(1)
+	get(2)
+	if numCalls != 3 {
+		t.Errorf("cache called refill %d times after remove instead of 3", numCalls)
+	}
+	c.Purge()
+	get(1)
+	get(2)
+	if numCalls != 5 {
+		t.Errorf("cache called refill %d times after purge instead of 5", numCalls)
+	}
+}
+
 func TestCacheRace(t *testing.T) {
 	const (
 		parallelism = 25
@@ -7,3 +7,7 @@ type noCache struct{}
 func (m *noCache) GetOrSet(_ interface{}, setFn SetFn) (v interface{}, err error) {
 	return setFn()
 }
+
+func (m *noCache) Remove(_ interface{}) {}
+
+func (m *noCache) Purge() {}
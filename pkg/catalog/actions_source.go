@@ -38,6 +38,10 @@ func NewActionsSource(catalog *Catalog) *ActionsSource {
 }
 
 func (s *ActionsSource) List(ctx context.Context, record graveler.HookRecord) ([]string, error) {
+	if record.SourceRef == "" {
+		// no repository reference to read actions from, e.g. before the repository was created
+		return nil, nil
+	}
 	key := fmt.Sprintf("%s:%s", record.RepositoryID.String(), record.SourceRef.String())
 	names, err := s.cache.GetOrSet(key, func() (interface{}, error) {
 		return s.list(ctx, record)
@@ -111,3 +115,47 @@ func (s *ActionsSource) load(ctx context.Context, record graveler.HookRecord, na
 	}
 	return bytes, nil
 }
+
+// WalkChangedPaths calls walkFn for each path changed by the operation of the record: the object key for object
+// events, the uncommitted changes for pre-commit, and the diff from the first parent for events with a commit.
+func (s *ActionsSource) WalkChangedPaths(ctx context.Context, record graveler.HookRecord, walkFn func(p string) error) error {
+	switch record.EventType {
+	case graveler.EventTypePrePutObject, graveler.EventTypePreDeleteObject:
+		return walkFn(record.ObjectKey.String())
+	}
+	if record.SourceRef == "" {
+		// no repository to diff, e.g. before the repository was created
+		return nil
+	}
+	repository, err := s.catalog.getRepository(ctx, record.RepositoryID.String())
+	if err != nil {
+		return err
+	}
+	var it graveler.DiffIterator
+	switch {
+	case record.EventType == graveler.EventTypePreCommit:
+		it, err = s.catalog.Store.DiffUncommitted(ctx, repository, record.BranchID)
+	case record.Commit.MetaRangeID != "":
+		var left graveler.MetaRangeID
+		if len(record.Commit.Parents) > 0 {
+			parent, err := s.catalog.Store.GetCommit(ctx, repository, record.Commit.Parents[0])
+			if err != nil {
+				return fmt.Errorf("get parent commit: %w", err)
+			}
+			left = parent.MetaRangeID
+		}
+		it, err = s.catalog.Store.DiffMetaRanges(ctx, repository, left, record.Commit.MetaRangeID)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	defer it.Close()
+	for it.Next() {
+		if err := walkFn(it.Value().Key.String()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
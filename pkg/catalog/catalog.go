@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alitto/pond"
@@ -146,6 +147,14 @@ type Catalog struct {
 	addressProvider       *ident.HexAddressProvider
 	UGCPrepareMaxFileSize int64
 	UGCPrepareInterval    time.Duration
+	metaRangeManager      committed.MetaRangeManager
+	rangeManager          committed.RangeManager
+	rangeRewriter         rangeRewriter
+	metaRangeRewriter     rangeRewriter
+	replicationWorkers    sync.Map
+	compactionSettings    *branch.CompactionSettingsManager
+	compactionEnabled     bool
+	compactionThreshold   int
 }
 
 const (
@@ -170,6 +179,21 @@ type ImportPath struct {
 	Path        string
 	Destination string
 	Type        ImportPathType
+	// Include and Exclude are glob patterns matched against the object key relative to Path.
+	// Only applicable to ImportPathTypePrefix.
+	Include []string
+	Exclude []string
+	// Remap optionally rewrites the relative key before placing it under Destination.
+	// Only applicable to ImportPathTypePrefix.
+	Remap *ImportRemap
+}
+
+// Filter returns the import filter for this path, nil if no filter is required
+func (p ImportPath) Filter() (*ImportFilter, error) {
+	if p.Type == ImportPathTypeObject && (len(p.Include) > 0 || len(p.Exclude) > 0 || p.Remap != nil) {
+		return nil, fmt.Errorf("filters on object import path %s: %w", p.Path, graveler.ErrInvalidValue)
+	}
+	return NewImportFilter(p.Include, p.Exclude, p.Remap)
 }
 
 func GetImportPathType(t string) (ImportPathType, error) {
@@ -241,14 +265,26 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 	pebbleSSTableCache := pebble.NewCache(tierFSParams.PebbleSSTableCacheSizeBytes)
 	defer pebbleSSTableCache.Unref()
 
-	sstableManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, rangeFS, hashAlg)
-	sstableMetaManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, metaRangeFS, hashAlg)
+	sstableFormat, err := sstable.NewFormatParams(cfg.Config.Committed.SSTable.Compression,
+		cfg.Config.Committed.SSTable.BlockSizeBytes, cfg.Config.Committed.SSTable.BlockRestartInterval)
+	if err != nil {
+		cancelFn()
+		return nil, fmt.Errorf("configure sstable format: %w", err)
+	}
+	var rangeOpts []sstable.RangeManagerOption
+	if filterCacheSize := cfg.Config.Committed.SSTable.FilterCacheSize; filterCacheSize > 0 {
+		rangeOpts = append(rangeOpts, sstable.WithFilterObjects(filterCacheSize))
+	}
+	sstableManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, rangeFS, hashAlg, sstableFormat, rangeOpts...)
+	sstableMetaManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, metaRangeFS, hashAlg, sstableFormat)
 
 	committedParams := committed.Params{
 		MinRangeSizeBytes:          cfg.Config.Committed.Permanent.MinRangeSizeBytes,
 		MaxRangeSizeBytes:          cfg.Config.Committed.Permanent.MaxRangeSizeBytes,
 		RangeSizeEntriesRaggedness: cfg.Config.Committed.Permanent.RangeRaggednessEntries,
 		MaxUploaders:               cfg.Config.Committed.LocalCache.MaxUploadersPerWriter,
+		DiffParallelism:            cfg.Config.Committed.Diff.Parallelism,
+		DiffRangesPerPartition:     cfg.Config.Committed.Diff.RangesPerPartition,
 	}
 	sstableMetaRangeManager, err := committed.NewMetaRangeManager(
 		committedParams,
@@ -279,6 +315,7 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 			RepositoryCacheConfig: ref.CacheConfig(cfg.Config.Graveler.RepositoryCache),
 			CommitCacheConfig:     ref.CacheConfig(cfg.Config.Graveler.CommitCache),
 		})
+	refManager.WatchChanges(ctx)
 	gcManager := retention.NewGarbageCollectionManager(tierFSParams.Adapter, refManager, cfg.Config.Committed.BlockStoragePrefix)
 	settingManager := settings.NewManager(refManager, cfg.KVStore)
 	if cfg.SettingsManagerOption != nil {
@@ -287,12 +324,19 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 
 	protectedBranchesManager := branch.NewProtectionManager(settingManager)
 	stagingManager := staging.NewManager(ctx, cfg.KVStore, storeLimiter, cfg.Config.Graveler.BatchDBIOTransactionMarkers, executor)
+	compactionSettingsManager := branch.NewCompactionSettingsManager(settingManager)
 	gStore := graveler.NewGraveler(committedManager, stagingManager, refManager, gcManager, protectedBranchesManager)
+	if cfg.Config.Graveler.Compaction.Enabled {
+		gStore.EnableCompaction(ctx, graveler.CompactionParams{
+			StagedWritesThreshold: cfg.Config.Graveler.Compaction.StagedWritesThreshold,
+			Settings:              compactionSettingsManager,
+		})
+	}
 
 	// The size of the workPool is determined by the number of workers and the number of desired pending tasks for each worker.
 	workPool := pond.New(sharedWorkers, sharedWorkers*pendingTasksPerWorker, pond.Context(ctx))
 
-	return &Catalog{
+	c := &Catalog{
 		BlockAdapter:          tierFSParams.Adapter,
 		Store:                 gStore,
 		UGCPrepareMaxFileSize: cfg.Config.UGC.PrepareMaxFileSize,
@@ -305,7 +349,20 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 		managers:              []io.Closer{sstableManager, sstableMetaManager, &ctxCloser{cancelFn}},
 		KVStoreLimited:        storeLimiter,
 		addressProvider:       addressProvider,
-	}, nil
+		metaRangeManager:      sstableMetaRangeManager,
+		rangeManager:          sstableManager,
+		rangeRewriter:         sstableManager,
+		metaRangeRewriter:     sstableMetaManager,
+		compactionSettings:    compactionSettingsManager,
+		compactionEnabled:     cfg.Config.Graveler.Compaction.Enabled,
+		compactionThreshold:   cfg.Config.Graveler.Compaction.StagedWritesThreshold,
+	}
+	if tierFSParams.RemoteCache.Cache != nil {
+		c.managers = append(c.managers, tierFSParams.RemoteCache.Cache)
+	}
+	gcManager.SetRetainedCommitsFunc(c.forkRetainedCommits)
+	c.SetHooksHandler(&graveler.HooksNoOp{})
+	return c, nil
 }
 
 func newLimiter(rateLimit int) ratelimit.Limiter {
@@ -318,8 +375,10 @@ func newLimiter(rateLimit int) ratelimit.Limiter {
 	return limiter
 }
 
+// SetHooksHandler sets the handler for graveler hooks. Post commit and merge hooks are also used to trigger
+// branch replication.
 func (c *Catalog) SetHooksHandler(hooks graveler.HooksHandler) {
-	c.Store.SetHooksHandler(hooks)
+	c.Store.SetHooksHandler(&replicationHooksHandler{HooksHandler: hooks, catalog: c})
 }
 
 func (c *Catalog) log(ctx context.Context) logging.Logger {
@@ -409,6 +468,13 @@ func (c *Catalog) DeleteRepository(ctx context.Context, repository string) error
 	}); err != nil {
 		return err
 	}
+	repo, err := c.getRepository(ctx, repository)
+	if err != nil {
+		return err
+	}
+	if err := c.deleteForkLink(ctx, repo); err != nil {
+		return fmt.Errorf("delete fork link: %w", err)
+	}
 	return c.Store.DeleteRepository(ctx, repositoryID)
 }
 
@@ -1827,19 +1893,27 @@ func (c *Catalog) GetRange(ctx context.Context, repositoryID, rangeID string) (g
 	return c.Store.GetRange(ctx, repository, graveler.RangeID(rangeID))
 }
 
-func (c *Catalog) importAsync(repository *graveler.RepositoryRecord, branchID, importID string, params ImportRequest, logger logging.Logger) error {
+func (c *Catalog) importAsync(repository *graveler.RepositoryRecord, branchID, importID string, params ImportRequest, filters []*ImportFilter, logger logging.Logger) error {
 	ctx, cancel := context.WithCancel(context.Background()) // Need a new context for the async operations
 	defer cancel()
 
-	importManager, err := NewImport(ctx, cancel, logger, c.KVStore, repository, importID)
+	// remapped keys may collide, with each other or with keys of other paths
+	detectCollisions := false
+	for _, filter := range filters {
+		if filter.Remaps() {
+			detectCollisions = true
+		}
+	}
+	importManager, err := NewImport(ctx, cancel, logger, c.KVStore, repository, importID, detectCollisions)
 	if err != nil {
 		return fmt.Errorf("creating import manager: %w", err)
 	}
 	defer importManager.Close()
 
 	wg, wgCtx := c.workPool.GroupContext(ctx)
-	for _, source := range params.Paths {
+	for i, source := range params.Paths {
 		src := source // Pinning
+		filter := filters[i]
 		wg.Submit(func() error {
 			// TODO (niro): Need to handle this at some point (use adapter GetWalker)
 			walker, err := c.walkerFactory.GetWalker(wgCtx, store.WalkerOptions{StorageURI: src.Path})
@@ -1847,7 +1921,7 @@ func (c *Catalog) importAsync(repository *graveler.RepositoryRecord, branchID, i
 				return fmt.Errorf("creating object-store walker on path %s: %w", source.Path, err)
 			}
 
-			it, err := NewWalkEntryIterator(wgCtx, walker, src.Type, src.Destination, "", "")
+			it, err := NewWalkEntryIterator(wgCtx, walker, src.Type, src.Destination, "", "", filter)
 			if err != nil {
 				return fmt.Errorf("creating walk iterator on path %s: %w", src.Path, err)
 			}
@@ -1942,11 +2016,20 @@ func (c *Catalog) Import(ctx context.Context, repositoryID, branchID string, par
 		return "", err
 	}
 
+	// Compile filters before starting, so invalid patterns fail the request
+	filters := make([]*ImportFilter, len(params.Paths))
+	for i, p := range params.Paths {
+		filters[i], err = p.Filter()
+		if err != nil {
+			return "", err
+		}
+	}
+
 	id := xid.New().String()
 	// Run import
 	go func() {
 		logger := c.log(ctx).WithField("import_id", id)
-		err = c.importAsync(repository, branchID, id, params, logger)
+		err = c.importAsync(repository, branchID, id, params, filters, logger)
 		if err != nil {
 			logger.WithError(err).Error("import failure")
 		}
@@ -2015,7 +2098,7 @@ func (c *Catalog) WriteRange(ctx context.Context, repositoryID string, params Wr
 		return nil, nil, fmt.Errorf("creating object-store walker: %w", err)
 	}
 
-	it, err := NewWalkEntryIterator(ctx, walker, ImportPathTypePrefix, params.Prepend, params.After, params.ContinuationToken)
+	it, err := NewWalkEntryIterator(ctx, walker, ImportPathTypePrefix, params.Prepend, params.After, params.ContinuationToken, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating walk iterator: %w", err)
 	}
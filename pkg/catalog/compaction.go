@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/validator"
+)
+
+// BranchCompaction is the compaction configuration of a branch staging area
+type BranchCompaction struct {
+	// Enabled reports whether staging areas are compacted by this lakeFS installation
+	Enabled bool
+	// StagedWritesThreshold is the number of writes staged on the branch by a lakeFS server that triggers its
+	// compaction, zero when compaction of the branch is disabled
+	StagedWritesThreshold int
+	// Override is true when the threshold is set for the branch, false when it is the default threshold
+	Override bool
+}
+
+// GetBranchCompaction returns the compaction configuration of the branch
+func (c *Catalog) GetBranchCompaction(ctx context.Context, repositoryID, branchID string) (*BranchCompaction, error) {
+	repository, branch, err := c.getCompactionBranch(ctx, repositoryID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	threshold, ok, err := c.compactionSettings.Get(ctx, repository, branch)
+	if err != nil {
+		return nil, err
+	}
+	compaction := &BranchCompaction{
+		Enabled:               c.compactionEnabled,
+		StagedWritesThreshold: c.compactionThreshold,
+		Override:              ok,
+	}
+	if ok {
+		compaction.StagedWritesThreshold = int(threshold)
+	}
+	return compaction, nil
+}
+
+// SetBranchCompaction overrides the staged writes threshold of the branch, zero disables compaction of the branch.
+// Servers use the new threshold within a few seconds.
+func (c *Catalog) SetBranchCompaction(ctx context.Context, repositoryID, branchID string, stagedWritesThreshold int) (*BranchCompaction, error) {
+	repository, branch, err := c.getCompactionBranch(ctx, repositoryID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.compactionSettings.Set(ctx, repository, branch, int64(stagedWritesThreshold)); err != nil {
+		return nil, err
+	}
+	return &BranchCompaction{
+		Enabled:               c.compactionEnabled,
+		StagedWritesThreshold: stagedWritesThreshold,
+		Override:              true,
+	}, nil
+}
+
+// DeleteBranchCompaction removes the staged writes threshold override of the branch
+func (c *Catalog) DeleteBranchCompaction(ctx context.Context, repositoryID, branchID string) error {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	branch := graveler.BranchID(branchID)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "branch", Value: branch, Fn: graveler.ValidateBranchID},
+	}); err != nil {
+		return err
+	}
+	return c.compactionSettings.Delete(ctx, repository, branch)
+}
+
+// getCompactionBranch returns the repository and the ID of an existing branch
+func (c *Catalog) getCompactionBranch(ctx context.Context, repositoryID, branchID string) (*graveler.RepositoryRecord, graveler.BranchID, error) {
+	branch := graveler.BranchID(branchID)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "branch", Value: branch, Fn: graveler.ValidateBranchID},
+	}); err != nil {
+		return nil, "", err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := c.Store.GetBranch(ctx, repository, branch); err != nil {
+		return nil, "", err
+	}
+	return repository, branch, nil
+}
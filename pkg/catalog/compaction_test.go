@@ -0,0 +1,225 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestCatalog_CompactedBranch(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCatalog(t, nil)
+	const repo = "compacted-branch"
+	repository := createTestRepository(t, c, repo)
+	store := c.Store.(*graveler.Graveler)
+
+	for _, p := range []string{"a/one", "a/two", "b/three"} {
+		createTestEntry(t, c, repo, "main", p, p)
+	}
+	baseCommit, err := c.Commit(ctx, repo, "main", "objects", "tester", nil, nil, nil)
+	require.NoError(t, err)
+
+	// stage changes, compact them, and stage more changes on top
+	createTestEntry(t, c, repo, "main", "a/four", "a/four")
+	createTestEntry(t, c, repo, "main", "b/three", "b/THREE")
+	require.NoError(t, c.DeleteEntry(ctx, repo, "main", "a/two"))
+	require.NoError(t, store.CompactBranch(ctx, repository, "main"))
+	branch, err := store.GetBranch(ctx, repository, "main")
+	require.NoError(t, err)
+	require.NotEmpty(t, branch.CompactedBaseMetaRangeID)
+	require.Empty(t, branch.SealedTokens)
+	createTestEntry(t, c, repo, "main", "c/five", "c/five")
+
+	expected := map[string]string{"a/one": "a/one", "a/four": "a/four", "b/three": "b/THREE", "c/five": "c/five"}
+	require.Equal(t, expected, listChecksums(t, c, repo, "main"))
+	for p, checksum := range expected {
+		entry, err := c.GetEntry(ctx, repo, "main", p, catalog.GetEntryParams{})
+		require.NoError(t, err)
+		require.Equal(t, checksum, entry.Checksum, "path %s", p)
+	}
+	_, err = c.GetEntry(ctx, repo, "main", "a/two", catalog.GetEntryParams{})
+	require.ErrorIs(t, err, graveler.ErrNotFound)
+
+	diff, _, err := c.DiffUncommitted(ctx, repo, "main", "", "", -1, "")
+	require.NoError(t, err)
+	changes := make(map[string]catalog.DifferenceType)
+	for _, d := range diff {
+		changes[d.Path] = d.Type
+	}
+	require.Equal(t, map[string]catalog.DifferenceType{
+		"a/two":   catalog.DifferenceTypeRemoved,
+		"a/four":  catalog.DifferenceTypeAdded,
+		"b/three": catalog.DifferenceTypeChanged,
+		"c/five":  catalog.DifferenceTypeAdded,
+	}, changes)
+
+	// reset a compacted change
+	require.NoError(t, c.ResetEntry(ctx, repo, "main", "a/four"))
+	_, err = c.GetEntry(ctx, repo, "main", "a/four", catalog.GetEntryParams{})
+	require.ErrorIs(t, err, graveler.ErrNotFound)
+	createTestEntry(t, c, repo, "main", "a/four", "a/four")
+
+	commit, err := c.Commit(ctx, repo, "main", "compacted", "tester", nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, listChecksums(t, c, repo, commit.Reference))
+	branch, err = store.GetBranch(ctx, repository, "main")
+	require.NoError(t, err)
+	require.Empty(t, branch.CompactedBaseMetaRangeID)
+
+	refsDiff, _, err := c.Diff(ctx, repo, baseCommit.Reference, commit.Reference, catalog.DiffParams{Limit: -1})
+	require.NoError(t, err)
+	require.Len(t, refsDiff, len(changes))
+}
+
+func TestCatalog_CompactBranchConcurrentCommit(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCatalog(t, nil)
+	const repo = "compact-concurrent-commit"
+	repository := createTestRepository(t, c, repo)
+	store := c.Store.(*graveler.Graveler)
+
+	const (
+		rounds         = 5
+		objectsInRound = 10
+	)
+	expected := make(map[string]string)
+	for round := 0; round < rounds; round++ {
+		for i := 0; i < objectsInRound; i++ {
+			p := fmt.Sprintf("round-%d/obj-%02d", round, i)
+			createTestEntry(t, c, repo, "main", p, p)
+			expected[p] = p
+		}
+		// compact and commit the staged objects, while more objects are staged
+		var (
+			wg                    sync.WaitGroup
+			compactErr, commitErr error
+			lateObjects           []string
+			commit                *catalog.CommitLog
+		)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			compactErr = store.CompactBranch(ctx, repository, "main")
+		}()
+		go func() {
+			defer wg.Done()
+			commit, commitErr = c.Commit(ctx, repo, "main", fmt.Sprintf("round %d", round), "tester", nil, nil, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < objectsInRound; i++ {
+				p := fmt.Sprintf("round-%d/late-%02d", round, i)
+				if err := c.CreateEntry(ctx, repo, "main", catalog.DBEntry{Path: p, PhysicalAddress: "address-" + p, Checksum: p}); err == nil {
+					lateObjects = append(lateObjects, p)
+				}
+			}
+		}()
+		wg.Wait()
+		require.NoError(t, compactErr, "round %d compaction", round)
+		require.NoError(t, commitErr, "round %d commit", round)
+		for _, p := range lateObjects {
+			expected[p] = p
+		}
+
+		// every object staged before the commit started is committed
+		committed := listChecksums(t, c, repo, commit.Reference)
+		for i := 0; i < objectsInRound; i++ {
+			require.Contains(t, committed, fmt.Sprintf("round-%d/obj-%02d", round, i), "round %d", round)
+		}
+		require.Equal(t, expected, listChecksums(t, c, repo, "main"), "round %d branch", round)
+	}
+
+	_, err := c.Commit(ctx, repo, "main", "late objects", "tester", nil, nil, nil)
+	if !errors.Is(err, graveler.ErrNoChanges) {
+		require.NoError(t, err)
+	}
+	head, err := c.GetCommit(ctx, repo, "main")
+	require.NoError(t, err)
+	require.Equal(t, expected, listChecksums(t, c, repo, head.Reference))
+	diff, _, err := c.DiffUncommitted(ctx, repo, "main", "", "", -1, "")
+	require.NoError(t, err)
+	require.Empty(t, diff)
+	branch, err := store.GetBranch(ctx, repository, "main")
+	require.NoError(t, err)
+	require.Empty(t, branch.CompactedBaseMetaRangeID)
+}
+
+func TestCatalog_RewriteCommitRanges(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCatalog(t, nil)
+	const repo = "rewrite-commit-ranges"
+	createTestRepository(t, c, repo)
+
+	expected := map[string]string{"a/one": "one", "a/two": "two", "b/three": "three"}
+	for p, checksum := range expected {
+		createTestEntry(t, c, repo, "main", p, checksum)
+	}
+	commit, err := c.Commit(ctx, repo, "main", "objects", "tester", nil, nil, nil)
+	require.NoError(t, err)
+
+	count, err := c.RewriteCommitRanges(ctx, repo, "main")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// the commit is unchanged and reads the rewritten ranges
+	head, err := c.GetCommit(ctx, repo, "main")
+	require.NoError(t, err)
+	require.Equal(t, commit.Reference, head.Reference)
+	require.Equal(t, expected, listChecksums(t, c, repo, "main"))
+
+	_, err = c.RewriteCommitRanges(ctx, repo, "no-such-ref")
+	require.ErrorIs(t, err, graveler.ErrNotFound)
+}
+
+func TestCatalog_BranchCompactionThreshold(t *testing.T) {
+	ctx := context.Background()
+	viper.Set("graveler.compaction.enabled", true)
+	t.Cleanup(func() { viper.Set("graveler.compaction.enabled", false) })
+	c := newTestCatalog(t, nil)
+	const repo = "branch-compaction-threshold"
+	repository := createTestRepository(t, c, repo)
+	store := c.Store.(*graveler.Graveler)
+	_, err := c.CreateBranch(ctx, repo, "disabled", "main")
+	require.NoError(t, err)
+
+	compaction, err := c.GetBranchCompaction(ctx, repo, "main")
+	require.NoError(t, err)
+	require.Equal(t, &catalog.BranchCompaction{Enabled: true, StagedWritesThreshold: 100_000}, compaction)
+	compaction, err = c.SetBranchCompaction(ctx, repo, "main", 3)
+	require.NoError(t, err)
+	require.Equal(t, &catalog.BranchCompaction{Enabled: true, StagedWritesThreshold: 3, Override: true}, compaction)
+	_, err = c.SetBranchCompaction(ctx, repo, "disabled", 0)
+	require.NoError(t, err)
+	_, err = c.SetBranchCompaction(ctx, repo, "no-such-branch", 3)
+	require.ErrorIs(t, err, graveler.ErrNotFound)
+
+	// staged writes are counted against the threshold of the branch, once the cached settings expire
+	compacted := func(branchID graveler.BranchID) bool {
+		branch, err := store.GetBranch(ctx, repository, branchID)
+		require.NoError(t, err)
+		return branch.CompactedBaseMetaRangeID != ""
+	}
+	require.Eventually(t, func() bool {
+		for i := 0; i < 3; i++ {
+			p := fmt.Sprintf("obj-%02d", i)
+			createTestEntry(t, c, repo, "main", p, p)
+			createTestEntry(t, c, repo, "disabled", p, p)
+		}
+		return compacted("main")
+	}, 20*time.Second, 500*time.Millisecond)
+	require.False(t, compacted("disabled"), "compaction of the branch should be disabled")
+
+	require.NoError(t, c.DeleteBranchCompaction(ctx, repo, "main"))
+	require.ErrorIs(t, c.DeleteBranchCompaction(ctx, repo, "main"), graveler.ErrNotFound)
+	compaction, err = c.GetBranchCompaction(ctx, repo, "main")
+	require.NoError(t, err)
+	require.False(t, compaction.Override)
+}
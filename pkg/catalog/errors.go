@@ -17,4 +17,9 @@ var (
 	ErrItClosed = errors.New("iterator closed")
 
 	ErrFeatureNotSupported = errors.New("feature not supported")
+	ErrExport              = errors.New("export error")
+	ErrFork                = errors.New("fork error")
+	ErrRebalance           = errors.New("rebalance error")
+	ErrReplication         = errors.New("replication error")
+	ErrImportCollision     = fmt.Errorf("import destination collision: %w", graveler.ErrInvalidValue)
 )
@@ -0,0 +1,614 @@
+package catalog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	ExportCanceled = "Canceled"
+
+	exportsPrefix            = "exports"
+	exportDestinationsPrefix = "export-destinations"
+
+	// exportMarkerTimeFormat and marker names follow the format used by the Spark exporter:
+	// EXPORT_<commitID>_<ISO-8601-time-UTC>_SUCCESS / _FAILURE
+	exportMarkerTimeFormat = "2006-01-02T15:04:05Z"
+	exportSuccessSuffix    = "SUCCESS"
+	exportFailureSuffix    = "FAILURE"
+	exportManifestSuffix   = "MANIFEST"
+
+	// exportSparkSuccessFile objects are exported in a second round, after all other objects were copied,
+	// so consumers will not see a Spark success indication before the data is in place.
+	exportSparkSuccessFile = "_SUCCESS"
+
+	// maxSingleCopySize is the largest object copied using a single Copy call, larger objects are copied
+	// using multipart upload with UploadCopyPartRange (S3 limits a single copy to 5GB).
+	maxSingleCopySize = 5 * 1024 * 1024 * 1024
+	copyPartSize      = 1024 * 1024 * 1024
+
+	exportStatusUpdateInterval = 1 * time.Second
+	// exportStatusUpdateAttempts is the number of attempts to update the status when it is updated concurrently
+	exportStatusUpdateAttempts = 3
+)
+
+// ExportRequest describes a request to export the committed content of a reference to an external location
+type ExportRequest struct {
+	Ref         string
+	Destination string
+	// Incremental exports only the changes since the last successful export to the same destination.
+	// The first export to a destination is always a full export.
+	Incremental bool
+}
+
+type ExportStatus struct {
+	ID               string
+	Ref              string
+	CommitID         string
+	Destination      string
+	PreviousCommitID string
+	Completed        bool
+	UpdatedAt        time.Time
+	Progress         int64
+	Manifest         string
+	Error            error
+}
+
+// ExportManifestEntry is a single line in the export manifest
+type ExportManifestEntry struct {
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"etag,omitempty"`
+}
+
+const (
+	ExportOperationCopy   = "copy"
+	ExportOperationDelete = "delete"
+)
+
+func ExportsPath(exportID string) string {
+	return kv.FormatPath(exportsPrefix, exportID)
+}
+
+func ExportDestinationPath(destination string) string {
+	return kv.FormatPath(exportDestinationsPrefix, destination)
+}
+
+func ExportStatusFromProto(pb *ExportStatusData) *ExportStatus {
+	var statusErr error
+	if pb.Error != "" {
+		statusErr = fmt.Errorf("%w: %s", ErrExport, pb.Error)
+	}
+	return &ExportStatus{
+		ID:               pb.Id,
+		Ref:              pb.Ref,
+		CommitID:         pb.CommitId,
+		Destination:      pb.Destination,
+		PreviousCommitID: pb.PreviousCommitId,
+		Completed:        pb.Completed,
+		UpdatedAt:        pb.UpdatedAt.AsTime(),
+		Progress:         pb.Progress,
+		Manifest:         pb.Manifest,
+		Error:            statusErr,
+	}
+}
+
+func ProtoFromExportStatus(status *ExportStatus) *ExportStatusData {
+	var statusErr string
+	if status.Error != nil {
+		statusErr = status.Error.Error()
+	}
+	return &ExportStatusData{
+		Id:               status.ID,
+		Ref:              status.Ref,
+		CommitId:         status.CommitID,
+		Destination:      status.Destination,
+		PreviousCommitId: status.PreviousCommitID,
+		Completed:        status.Completed,
+		UpdatedAt:        timestamppb.New(status.UpdatedAt),
+		Progress:         status.Progress,
+		Manifest:         status.Manifest,
+		Error:            statusErr,
+	}
+}
+
+// exportTracker holds the in-memory status of a running export and periodically persists it to kv.
+// It cancels the export when the stored status is marked with an error (e.g. canceled by the user).
+type exportTracker struct {
+	kvStore       kv.Store
+	repoPartition string
+	logger        logging.Logger
+	mu            sync.Mutex
+	status        ExportStatus
+	// failure is the error that stopped tracking, nil when tracking stopped as the export was canceled
+	failure error
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newExportTracker(ctx context.Context, kvStore kv.Store, repoPartition string, status ExportStatus, logger logging.Logger) (*exportTracker, error) {
+	status.UpdatedAt = time.Now()
+	err := kv.SetMsg(ctx, kvStore, repoPartition, []byte(ExportsPath(status.ID)), ProtoFromExportStatus(&status))
+	if err != nil {
+		return nil, err
+	}
+	return &exportTracker{
+		kvStore:       kvStore,
+		repoPartition: repoPartition,
+		logger:        logger,
+		status:        status,
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}, nil
+}
+
+func (t *exportTracker) Status() ExportStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+func (t *exportTracker) Update(f func(status *ExportStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f(&t.status)
+	t.status.UpdatedAt = time.Now()
+}
+
+// Failure returns the error that failed tracking the status, nil if it did not fail
+func (t *exportTracker) Failure() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failure
+}
+
+func (t *exportTracker) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failure = err
+}
+
+func (t *exportTracker) AddProgress(n int64) {
+	t.Update(func(status *ExportStatus) {
+		status.Progress += n
+	})
+}
+
+// Run persists the status until Close is called. cancel is called when the export was canceled, or when the status
+// cannot be persisted, which is reported by Failure.
+func (t *exportTracker) Run(ctx context.Context, cancel context.CancelFunc) {
+	defer close(t.stopped)
+	ticker := time.NewTicker(exportStatusUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if t.persist(ctx, cancel) {
+				continue
+			}
+			// the export stops, wait for it to set its final status
+			<-t.done
+		case <-t.done:
+		}
+		// final update, use a new context as the export context may be canceled
+		t.persist(context.Background(), cancel)
+		return
+	}
+}
+
+// persist writes the current status to kv, returns false when tracking should stop. The status is read again and the
+// update retried when the status was updated concurrently.
+func (t *exportTracker) persist(ctx context.Context, cancel context.CancelFunc) bool {
+	key := []byte(ExportsPath(t.Status().ID))
+	for attempt := 0; attempt < exportStatusUpdateAttempts; attempt++ {
+		statusData := ExportStatusData{}
+		pred, err := kv.GetMsg(ctx, t.kvStore, t.repoPartition, key, &statusData)
+		if err != nil {
+			t.logger.WithError(err).Error("Failed to read export status")
+			t.fail(fmt.Errorf("read export status: %w", err))
+			cancel()
+			return false
+		}
+		if statusData.Error != "" || statusData.Completed {
+			// canceled or already completed
+			cancel()
+			return false
+		}
+		currStatus := t.Status()
+		err = kv.SetMsgIf(ctx, t.kvStore, t.repoPartition, key, ProtoFromExportStatus(&currStatus), pred)
+		if errors.Is(err, kv.ErrPredicateFailed) {
+			t.logger.WithField("attempt", attempt+1).Warning("Export status updated concurrently, retrying")
+			continue
+		}
+		if err != nil {
+			t.logger.WithError(err).Error("Failed to update export status")
+			t.fail(fmt.Errorf("update export status: %w", err))
+			cancel()
+			return false
+		}
+		return true
+	}
+	t.logger.Error("Failed to update export status, updated concurrently")
+	t.fail(fmt.Errorf("update export status: %w", kv.ErrPredicateFailed))
+	cancel()
+	return false
+}
+
+func (t *exportTracker) Close() {
+	close(t.done)
+	<-t.stopped
+}
+
+// addedEntriesIterator presents an entry listing as a diff of added entries
+type addedEntriesIterator struct {
+	EntryIterator
+	value *EntryDiff
+}
+
+func (it *addedEntriesIterator) Next() bool {
+	if !it.EntryIterator.Next() {
+		it.value = nil
+		return false
+	}
+	v := it.EntryIterator.Value()
+	it.value = &EntryDiff{Type: graveler.DiffTypeAdded, Path: v.Path, Entry: v.Entry}
+	return true
+}
+
+func (it *addedEntriesIterator) Value() *EntryDiff {
+	return it.value
+}
+
+// exportManifest collects manifest entries into a local temporary file
+type exportManifest struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+}
+
+func newExportManifest(exportID string) (*exportManifest, error) {
+	f, err := os.CreateTemp("", "export_manifest_"+exportID)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	return &exportManifest{file: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (m *exportManifest) Add(entry ExportManifestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enc.Encode(entry)
+}
+
+// Reader flushes the manifest and returns its size and a reader positioned at its start
+func (m *exportManifest) Reader() (io.Reader, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.w.Flush(); err != nil {
+		return nil, 0, err
+	}
+	size, err := m.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := m.file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	return m.file, size, nil
+}
+
+func (m *exportManifest) Close() {
+	_ = m.file.Close()
+	_ = os.Remove(m.file.Name())
+}
+
+// Export starts an asynchronous export of the committed content of a reference to an external location.
+// Returns the export ID used to query or cancel the export.
+func (c *Catalog) Export(ctx context.Context, repositoryID string, params ExportRequest) (string, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return "", err
+	}
+	if params.Destination == "" {
+		return "", fmt.Errorf("destination: %w", graveler.ErrRequiredValue)
+	}
+	commitID, err := c.dereferenceCommitID(ctx, repository, graveler.Ref(params.Ref))
+	if err != nil {
+		return "", err
+	}
+
+	var previousCommitID string
+	if params.Incremental {
+		destinationData := ExportDestinationData{}
+		_, err := kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ExportDestinationPath(params.Destination)), &destinationData)
+		switch {
+		case errors.Is(err, kv.ErrNotFound):
+			// first export to this destination - export everything
+		case err != nil:
+			return "", err
+		default:
+			previousCommitID = destinationData.CommitId
+		}
+	}
+
+	id := xid.New().String()
+	logger := c.log(ctx).WithFields(logging.Fields{"export_id": id, "destination": params.Destination})
+	tracker, err := newExportTracker(ctx, c.KVStore, graveler.RepoPartition(repository), ExportStatus{
+		ID:               id,
+		Ref:              params.Ref,
+		CommitID:         commitID.String(),
+		Destination:      params.Destination,
+		PreviousCommitID: previousCommitID,
+	}, logger)
+	if err != nil {
+		return "", err
+	}
+
+	go func() {
+		err := c.exportAsync(repository, tracker, logger)
+		if err != nil {
+			logger.WithError(err).Error("export failure")
+		}
+	}()
+	return id, nil
+}
+
+func (c *Catalog) exportAsync(repository *graveler.RepositoryRecord, tracker *exportTracker, logger logging.Logger) error {
+	ctx, cancel := context.WithCancel(context.Background()) // Need a new context for the async operations
+	defer cancel()
+
+	go tracker.Run(ctx, cancel)
+	defer tracker.Close()
+
+	status := tracker.Status()
+	startTime := time.Now().UTC()
+	markerPrefix := fmt.Sprintf("EXPORT_%s_%s_", status.CommitID, startTime.Format(exportMarkerTimeFormat))
+
+	err := c.exportChanges(ctx, repository, tracker, markerPrefix+exportManifestSuffix)
+	if ctx.Err() != nil {
+		failure := tracker.Failure()
+		if failure == nil {
+			// canceled
+			return nil
+		}
+		// stopped as the status could not be tracked, not by the user
+		err = failure
+	}
+	if err != nil {
+		tracker.Update(func(status *ExportStatus) { status.Error = err })
+		_ = c.writeExportMarker(repository, status.Destination, markerPrefix+exportFailureSuffix, err.Error(), logger)
+		return err
+	}
+
+	if err := c.writeExportMarker(repository, status.Destination, markerPrefix+exportSuccessSuffix, status.CommitID, logger); err != nil {
+		tracker.Update(func(status *ExportStatus) { status.Error = err })
+		return err
+	}
+
+	// record the exported commit, used as the base for the next incremental export
+	err = kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ExportDestinationPath(status.Destination)), &ExportDestinationData{
+		Destination: status.Destination,
+		CommitId:    status.CommitID,
+		ExportId:    status.ID,
+		CompletedAt: timestamppb.Now(),
+	})
+	if err != nil {
+		tracker.Update(func(status *ExportStatus) { status.Error = err })
+		return err
+	}
+	tracker.Update(func(status *ExportStatus) { status.Completed = true })
+	return nil
+}
+
+// exportChanges copies (or deletes) the objects that changed since the previous export, and writes the manifest
+func (c *Catalog) exportChanges(ctx context.Context, repository *graveler.RepositoryRecord, tracker *exportTracker, manifestKey string) error {
+	status := tracker.Status()
+	var it EntryDiffIterator
+	if status.PreviousCommitID != "" {
+		diffIt, err := c.Store.Diff(ctx, repository, graveler.Ref(status.PreviousCommitID), graveler.Ref(status.CommitID))
+		if err != nil {
+			return err
+		}
+		it = NewEntryDiffIterator(diffIt)
+	} else {
+		listIt, err := c.Store.List(ctx, repository, graveler.Ref(status.CommitID), ListEntriesLimitMax)
+		if err != nil {
+			return err
+		}
+		it = &addedEntriesIterator{EntryIterator: NewValueToEntryIterator(listIt)}
+	}
+	defer it.Close()
+
+	manifest, err := newExportManifest(status.ID)
+	if err != nil {
+		return err
+	}
+	defer manifest.Close()
+
+	// first round exports everything but Spark success files, which are exported in the second round
+	var deferred []EntryDiff
+	wg, wgCtx := c.workPool.GroupContext(ctx)
+	for it.Next() {
+		v := *it.Value()
+		if path.Base(v.Path.String()) == exportSparkSuccessFile {
+			deferred = append(deferred, v)
+			continue
+		}
+		wg.Submit(func() error {
+			return c.exportEntry(wgCtx, repository, status.Destination, v, manifest, tracker)
+		})
+	}
+	if err := it.Err(); err != nil {
+		_ = wg.Wait()
+		return err
+	}
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	wg, wgCtx = c.workPool.GroupContext(ctx)
+	for _, v := range deferred {
+		v := v
+		wg.Submit(func() error {
+			return c.exportEntry(wgCtx, repository, status.Destination, v, manifest, tracker)
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	reader, size, err := manifest.Reader()
+	if err != nil {
+		return err
+	}
+	err = c.BlockAdapter.Put(ctx, block.ObjectPointer{
+		StorageNamespace: status.Destination,
+		Identifier:       manifestKey,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, size, reader, block.PutOpts{})
+	if err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	tracker.Update(func(status *ExportStatus) { status.Manifest = manifestKey })
+	return nil
+}
+
+func (c *Catalog) exportEntry(ctx context.Context, repository *graveler.RepositoryRecord, destination string, diff EntryDiff, manifest *exportManifest, tracker *exportTracker) error {
+	destObj := block.ObjectPointer{
+		StorageNamespace: destination,
+		Identifier:       diff.Path.String(),
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	var manifestEntry ExportManifestEntry
+	if diff.Type == graveler.DiffTypeRemoved {
+		if err := c.BlockAdapter.Remove(ctx, destObj); err != nil {
+			return fmt.Errorf("remove %s: %w", diff.Path, err)
+		}
+		manifestEntry = ExportManifestEntry{Path: diff.Path.String(), Operation: ExportOperationDelete}
+	} else {
+		srcObj := block.ObjectPointer{
+			StorageNamespace: repository.StorageNamespace.String(),
+			Identifier:       diff.Entry.Address,
+			IdentifierType:   addressTypeToCatalog(diff.Entry.AddressType).ToIdentifierType(),
+		}
+		if err := c.copyObject(ctx, srcObj, destObj, diff.Entry.Size); err != nil {
+			return fmt.Errorf("copy %s: %w", diff.Path, err)
+		}
+		manifestEntry = ExportManifestEntry{
+			Path:      diff.Path.String(),
+			Operation: ExportOperationCopy,
+			Size:      diff.Entry.Size,
+			ETag:      diff.Entry.ETag,
+		}
+	}
+	if err := manifest.Add(manifestEntry); err != nil {
+		return err
+	}
+	tracker.AddProgress(1)
+	return nil
+}
+
+// copyObject uses the block adapter server side copy. Objects larger than the adapter single copy limit
+// are copied part by part using a multipart upload.
+func (c *Catalog) copyObject(ctx context.Context, srcObj, destObj block.ObjectPointer, size int64) error {
+	if size <= maxSingleCopySize {
+		return c.BlockAdapter.Copy(ctx, srcObj, destObj)
+	}
+	resp, err := c.BlockAdapter.CreateMultiPartUpload(ctx, destObj, nil, block.CreateMultiPartUploadOpts{})
+	if err != nil {
+		return err
+	}
+	var parts []block.MultipartPart
+	for partNumber, start := 1, int64(0); start < size; partNumber, start = partNumber+1, start+copyPartSize {
+		end := start + copyPartSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		partResp, err := c.BlockAdapter.UploadCopyPartRange(ctx, srcObj, destObj, resp.UploadID, partNumber, start, end)
+		if err != nil {
+			_ = c.BlockAdapter.AbortMultiPartUpload(ctx, destObj, resp.UploadID)
+			return err
+		}
+		parts = append(parts, block.MultipartPart{PartNumber: partNumber, ETag: partResp.ETag})
+	}
+	_, err = c.BlockAdapter.CompleteMultiPartUpload(ctx, destObj, resp.UploadID, &block.MultipartUploadCompletion{Part: parts})
+	return err
+}
+
+func (c *Catalog) writeExportMarker(repository *graveler.RepositoryRecord, destination, key, content string, logger logging.Logger) error {
+	// use a new context - marker should be written even if the export context is done
+	err := c.BlockAdapter.Put(context.Background(), block.ObjectPointer{
+		StorageNamespace: destination,
+		Identifier:       key,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(content)), strings.NewReader(content), block.PutOpts{})
+	if err != nil {
+		logger.WithError(err).WithFields(logging.Fields{"repository": repository.RepositoryID, "marker": key}).Error("Failed to write export marker")
+	}
+	return err
+}
+
+func (c *Catalog) getExportStatus(ctx context.Context, repository *graveler.RepositoryRecord, exportID string) (*ExportStatusData, error) {
+	data := &ExportStatusData{}
+	_, err := kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ExportsPath(exportID)), data)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, graveler.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *Catalog) GetExportStatus(ctx context.Context, repositoryID, exportID string) (*ExportStatus, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.getExportStatus(ctx, repository, exportID)
+	if err != nil {
+		return nil, err
+	}
+	return ExportStatusFromProto(data), nil
+}
+
+func (c *Catalog) CancelExport(ctx context.Context, repositoryID, exportID string) error {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	exportStatus, err := c.getExportStatus(ctx, repository, exportID)
+	if err != nil {
+		return err
+	}
+	if exportStatus.Completed || exportStatus.Error != "" {
+		c.log(ctx).WithFields(logging.Fields{
+			"export_id": exportID,
+			"completed": exportStatus.Completed,
+			"error":     exportStatus.Error,
+		}).Warning("Not canceling export - already completed")
+		return graveler.ErrConflictFound
+	}
+	exportStatus.Error = ExportCanceled
+	exportStatus.UpdatedAt = timestamppb.Now()
+	return kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ExportsPath(exportID)), exportStatus)
+}
@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+var errSetIfFailed = errors.New("set if failed")
+
+// failingSetIfStore fails the next conditional sets with err, as many as set by failures
+type failingSetIfStore struct {
+	kv.Store
+	err      error
+	failures atomic.Int32
+}
+
+func (s *failingSetIfStore) SetIf(ctx context.Context, partitionKey, key, value []byte, valuePredicate kv.Predicate) error {
+	if s.failures.Add(-1) >= 0 {
+		return s.err
+	}
+	return s.Store.SetIf(ctx, partitionKey, key, value, valuePredicate)
+}
+
+func TestExportTracker(t *testing.T) {
+	const (
+		repoPartition = "repo"
+		exportID      = "export"
+	)
+	readStatus := func(t *testing.T, store kv.Store) *ExportStatus {
+		t.Helper()
+		data := ExportStatusData{}
+		_, err := kv.GetMsg(context.Background(), store, repoPartition, []byte(ExportsPath(exportID)), &data)
+		require.NoError(t, err)
+		return ExportStatusFromProto(&data)
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		store := kvtest.GetStore(context.Background(), t)
+		tracker, err := newExportTracker(context.Background(), store, repoPartition, ExportStatus{ID: exportID}, logging.ContextUnavailable())
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go tracker.Run(ctx, cancel)
+
+		// cancel the export, as CancelExport does
+		err = kv.SetMsg(ctx, store, repoPartition, []byte(ExportsPath(exportID)), ProtoFromExportStatus(&ExportStatus{ID: exportID, Error: errors.New(ExportCanceled)}))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return ctx.Err() != nil }, 5*time.Second, 10*time.Millisecond)
+		tracker.Close()
+		require.NoError(t, tracker.Failure())
+		require.ErrorContains(t, readStatus(t, store).Error, ExportCanceled)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		store := &failingSetIfStore{Store: kvtest.GetStore(context.Background(), t), err: errSetIfFailed}
+		tracker, err := newExportTracker(context.Background(), store, repoPartition, ExportStatus{ID: exportID}, logging.ContextUnavailable())
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		store.failures.Store(1)
+		go tracker.Run(ctx, cancel)
+
+		require.Eventually(t, func() bool { return ctx.Err() != nil }, 5*time.Second, 10*time.Millisecond)
+		failure := tracker.Failure()
+		require.ErrorIs(t, failure, errSetIfFailed)
+		// the error is persisted once the export stops
+		tracker.Update(func(status *ExportStatus) { status.Error = failure })
+		tracker.Close()
+		require.ErrorContains(t, readStatus(t, store).Error, "update export status: set if failed")
+	})
+
+	t.Run("concurrent_update", func(t *testing.T) {
+		store := &failingSetIfStore{Store: kvtest.GetStore(context.Background(), t), err: kv.ErrPredicateFailed}
+		tracker, err := newExportTracker(context.Background(), store, repoPartition, ExportStatus{ID: exportID}, logging.ContextUnavailable())
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go tracker.Run(ctx, cancel)
+
+		// the final status is persisted although the first update fails
+		store.failures.Store(exportStatusUpdateAttempts - 1)
+		tracker.Update(func(status *ExportStatus) { status.Completed = true })
+		tracker.Close()
+		require.NoError(t, tracker.Failure())
+		require.True(t, readStatus(t, store).Completed)
+	})
+
+	t.Run("concurrent_update_failure", func(t *testing.T) {
+		store := &failingSetIfStore{Store: kvtest.GetStore(context.Background(), t), err: kv.ErrPredicateFailed}
+		tracker, err := newExportTracker(context.Background(), store, repoPartition, ExportStatus{ID: exportID}, logging.ContextUnavailable())
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		store.failures.Store(exportStatusUpdateAttempts)
+		go tracker.Run(ctx, cancel)
+
+		require.Eventually(t, func() bool { return ctx.Err() != nil }, 5*time.Second, 10*time.Millisecond)
+		require.ErrorIs(t, tracker.Failure(), kv.ErrPredicateFailed)
+		tracker.Close()
+	})
+}
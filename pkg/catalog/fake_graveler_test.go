@@ -88,6 +88,10 @@ func (g *FakeGraveler) GetMetaRange(_ context.Context, _ *graveler.RepositoryRec
 	panic("implement me")
 }
 
+func (g *FakeGraveler) DiffMetaRanges(_ context.Context, _ *graveler.RepositoryRecord, _, _ graveler.MetaRangeID) (graveler.DiffIterator, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) GetRange(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.RangeID) (graveler.RangeAddress, error) {
 	panic("implement me")
 }
@@ -129,7 +133,7 @@ func (g *FakeGraveler) DeleteBatch(ctx context.Context, repository *graveler.Rep
 	return nil
 }
 
-func (g *FakeGraveler) ListStaging(_ context.Context, b *graveler.Branch, _ int) (graveler.ValueIterator, error) {
+func (g *FakeGraveler) ListStaging(_ context.Context, _ *graveler.RepositoryRecord, b *graveler.Branch, _ int) (graveler.ValueIterator, error) {
 	if g.Err != nil {
 		return nil, g.Err
 	}
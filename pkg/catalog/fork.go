@@ -0,0 +1,556 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/cache"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/committed"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/validator"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	forksPrefix = "forks"
+	// forkSourcePath is the key of the fork link in the fork repository partition
+	forkSourcePath     = "fork-source"
+	forkStatusesPrefix = "fork_statuses"
+
+	// forkRangesCacheSize bounds the ranges and metaranges a fork keeps rewritten, shared ranges evicted from the cache
+	// are rewritten again
+	forkRangesCacheSize   = 10_000
+	forkRangesCacheExpiry = time.Hour
+)
+
+// ForkStatus tracks a fork started by Catalog.ForkRepository
+type ForkStatus struct {
+	ID         string
+	Repository string
+	Completed  bool
+	UpdatedAt  time.Time
+	Error      error
+}
+
+// RepositoryForkPath is the key of the fork link in the source repository partition
+func RepositoryForkPath(forkRepositoryID string) string {
+	return kv.FormatPath(forksPrefix, forkRepositoryID)
+}
+
+// ForkStatusPath is the key of the fork status in the source repository partition
+func ForkStatusPath(forkID string) string {
+	return kv.FormatPath(forkStatusesPrefix, forkID)
+}
+
+func ForkStatusFromProto(pb *RepositoryForkStatusData) *ForkStatus {
+	var statusErr error
+	if pb.Error != "" {
+		statusErr = fmt.Errorf("%w: %s", ErrFork, pb.Error)
+	}
+	return &ForkStatus{
+		ID:         pb.Id,
+		Repository: pb.ForkRepository,
+		Completed:  pb.Completed,
+		UpdatedAt:  pb.UpdatedAt.AsTime(),
+		Error:      statusErr,
+	}
+}
+
+// ForkRepository starts creating a new repository with the committed history of the source repository: its commits,
+// branches and tags. Committed objects are not copied - the fork references the source objects by their full
+// address. Uncommitted changes of the source branches are not part of the fork.
+// The fork repository is created before returning, and its history is written asynchronously. A fork that fails is
+// deleted. Returns the fork ID used to query its status.
+// As the commits metaranges are rewritten to use full addresses, commit IDs in the fork differ from the source.
+func (c *Catalog) ForkRepository(ctx context.Context, sourceRepositoryID, repositoryID, storageNamespace string) (string, error) {
+	forkID := graveler.RepositoryID(repositoryID)
+	storageNS := graveler.StorageNamespace(storageNamespace)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "source", Value: graveler.RepositoryID(sourceRepositoryID), Fn: graveler.ValidateRepositoryID},
+		{Name: "name", Value: forkID, Fn: graveler.ValidateRepositoryID},
+		{Name: "storageNamespace", Value: storageNS, Fn: graveler.ValidateStorageNamespace},
+	}); err != nil {
+		return "", err
+	}
+	source, err := c.getRepository(ctx, sourceRepositoryID)
+	if err != nil {
+		return "", err
+	}
+	commitsMetaRangeID, err := c.Store.DumpCommits(ctx, source)
+	if err != nil {
+		return "", fmt.Errorf("dump source commits: %w", err)
+	}
+	fork, err := c.Store.CreateBareRepository(ctx, forkID, storageNS, source.DefaultBranchID)
+	if err != nil {
+		return "", err
+	}
+
+	id := xid.New().String()
+	status := &RepositoryForkStatusData{
+		Id:             id,
+		ForkRepository: repositoryID,
+		UpdatedAt:      timestamppb.Now(),
+	}
+	// link the fork before writing its history, so garbage collection of the source will keep the objects it references
+	link := &RepositoryForkData{
+		SourceRepository:   source.RepositoryID.String(),
+		ForkRepository:     fork.RepositoryID.String(),
+		CommitsMetaRangeId: commitsMetaRangeID.String(),
+		CreatedAt:          timestamppb.Now(),
+	}
+	err = kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(source), []byte(RepositoryForkPath(repositoryID)), link)
+	if err == nil {
+		err = kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(fork), []byte(forkSourcePath), link)
+	}
+	if err == nil {
+		err = c.setForkStatus(ctx, source, status)
+	}
+	if err != nil {
+		c.deleteFailedFork(ctx, source, fork)
+		return "", fmt.Errorf("fork repository: %w", err)
+	}
+
+	logger := c.log(ctx).WithFields(logging.Fields{"fork_id": id, "source": sourceRepositoryID, "repository": repositoryID})
+	go func() {
+		// Need a new context for the async operations
+		ctx := context.Background()
+		forker := &repositoryForker{
+			catalog:    c,
+			source:     source,
+			fork:       fork,
+			ranges:     cache.NewCache(forkRangesCacheSize, forkRangesCacheExpiry, cache.NewJitterFn(time.Minute)),
+			metaRanges: cache.NewCache(forkRangesCacheSize, forkRangesCacheExpiry, cache.NewJitterFn(time.Minute)),
+			commits:    make(map[graveler.CommitID]graveler.CommitID),
+		}
+		if err := forker.run(ctx, *commitsMetaRangeID); err != nil {
+			logger.WithError(err).Error("fork failure")
+			c.deleteFailedFork(ctx, source, fork)
+			status.Error = err.Error()
+		} else {
+			status.Completed = true
+		}
+		status.UpdatedAt = timestamppb.Now()
+		if err := c.setForkStatus(ctx, source, status); err != nil {
+			logger.WithError(err).Error("failed to update fork status")
+		}
+	}()
+	return id, nil
+}
+
+// deleteFailedFork deletes a fork repository whose history could not be written
+func (c *Catalog) deleteFailedFork(ctx context.Context, source, fork *graveler.RepositoryRecord) {
+	if err := c.DeleteRepository(ctx, fork.RepositoryID.String()); err != nil {
+		c.log(ctx).WithError(err).WithFields(logging.Fields{
+			"source":     source.RepositoryID,
+			"repository": fork.RepositoryID,
+		}).Error("Failed to delete repository after failed fork")
+	}
+}
+
+func (c *Catalog) setForkStatus(ctx context.Context, source *graveler.RepositoryRecord, status *RepositoryForkStatusData) error {
+	return kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(source), []byte(ForkStatusPath(status.Id)), status)
+}
+
+func (c *Catalog) GetForkStatus(ctx context.Context, repositoryID, forkID string) (*ForkStatus, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	data := &RepositoryForkStatusData{}
+	_, err = kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ForkStatusPath(forkID)), data)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, graveler.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ForkStatusFromProto(data), nil
+}
+
+// deleteForkLink removes the link between a fork being deleted and its source repository
+func (c *Catalog) deleteForkLink(ctx context.Context, repository *graveler.RepositoryRecord) error {
+	link := &RepositoryForkData{}
+	_, err := kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(forkSourcePath), link)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	source, err := c.getRepository(ctx, link.SourceRepository)
+	if errors.Is(err, graveler.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.KVStore.Delete(ctx, []byte(graveler.RepoPartition(source)), []byte(RepositoryForkPath(link.ForkRepository)))
+}
+
+// forkRetainedCommits returns the commits of the repository that are referenced by its forks
+func (c *Catalog) forkRetainedCommits(ctx context.Context, repository *graveler.RepositoryRecord) ([]graveler.CommitID, error) {
+	it, err := kv.NewPrimaryIterator(ctx, c.KVStore, (&RepositoryForkData{}).ProtoReflect().Type(),
+		graveler.RepoPartition(repository), []byte(kv.FormatPath(forksPrefix, "")), kv.IteratorOptionsFrom([]byte("")))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var retained []graveler.CommitID
+	for it.Next() {
+		link, ok := it.Entry().Value.(*RepositoryForkData)
+		if !ok {
+			return nil, fmt.Errorf("fork %s: %w", it.Entry().Key, graveler.ErrReadingFromStore)
+		}
+		commitsIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, repository.StorageNamespace, graveler.MetaRangeID(link.CommitsMetaRangeId))
+		if err != nil {
+			return nil, fmt.Errorf("fork %s commits: %w", link.ForkRepository, err)
+		}
+		valueIt := committed.NewValueIterator(commitsIt)
+		for valueIt.Next() {
+			retained = append(retained, graveler.CommitID(valueIt.Value().Key))
+		}
+		err = valueIt.Err()
+		valueIt.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fork %s commits: %w", link.ForkRepository, err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return retained, nil
+}
+
+// repositoryForker writes the committed history of a source repository to a fork. Ranges are rewritten so entries
+// with relative addresses use the full address of the object in the source storage namespace. Each range and
+// metarange is rewritten once while it is cached, as most of them are shared between commits.
+type repositoryForker struct {
+	catalog *Catalog
+	source  *graveler.RepositoryRecord
+	fork    *graveler.RepositoryRecord
+	// ranges caches the fork ranges by source range ID, metaRanges the fork metarange by source metarange ID
+	ranges     cache.Cache
+	metaRanges cache.Cache
+	commits    map[graveler.CommitID]graveler.CommitID
+}
+
+func (f *repositoryForker) run(ctx context.Context, commitsMetaRangeID graveler.MetaRangeID) error {
+	if err := f.forkCommits(ctx, commitsMetaRangeID); err != nil {
+		return err
+	}
+	if err := f.forkBranches(ctx); err != nil {
+		return err
+	}
+	return f.forkTags(ctx)
+}
+
+func (f *repositoryForker) listDump(ctx context.Context, metaRangeID graveler.MetaRangeID, fn func(record *graveler.ValueRecord) error) error {
+	it, err := f.catalog.metaRangeManager.NewMetaRangeIterator(ctx, f.source.StorageNamespace, metaRangeID)
+	if err != nil {
+		return err
+	}
+	valueIt := committed.NewValueIterator(it)
+	defer valueIt.Close()
+	for valueIt.Next() {
+		if err := fn(valueIt.Value()); err != nil {
+			return err
+		}
+	}
+	return valueIt.Err()
+}
+
+func (f *repositoryForker) forkCommits(ctx context.Context, commitsMetaRangeID graveler.MetaRangeID) error {
+	var commits []*graveler.CommitRecord
+	err := f.listDump(ctx, commitsMetaRangeID, func(record *graveler.ValueRecord) error {
+		data := &graveler.CommitData{}
+		if err := proto.Unmarshal(record.Data, data); err != nil {
+			return err
+		}
+		commits = append(commits, &graveler.CommitRecord{
+			CommitID: graveler.CommitID(data.Id),
+			Commit:   graveler.CommitFromProto(data),
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read commits: %w", err)
+	}
+	// parents have a lower generation than their children, rewrite them first
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Generation < commits[j].Generation
+	})
+
+	records := make([]*graveler.ValueRecord, 0, len(commits))
+	for _, commit := range commits {
+		forkCommit := *commit.Commit
+		forkCommit.MetaRangeID, err = f.rewriteMetaRange(ctx, commit.MetaRangeID)
+		if err != nil {
+			return fmt.Errorf("commit %s: %w", commit.CommitID, err)
+		}
+		forkCommit.Parents = make(graveler.CommitParents, len(commit.Parents))
+		for i, parent := range commit.Parents {
+			forkParent, ok := f.commits[parent]
+			if !ok {
+				return fmt.Errorf("commit %s parent %s: %w", commit.CommitID, parent, graveler.ErrCommitNotFound)
+			}
+			forkCommit.Parents[i] = forkParent
+		}
+		forkCommitID := graveler.CommitID(f.catalog.addressProvider.ContentAddress(forkCommit))
+		f.commits[commit.CommitID] = forkCommitID
+		data, err := proto.Marshal(graveler.ProtoFromCommit(forkCommitID, &forkCommit))
+		if err != nil {
+			return err
+		}
+		records = append(records, &graveler.ValueRecord{
+			Key:   graveler.Key(forkCommitID),
+			Value: &graveler.Value{Identity: []byte(forkCommitID), Data: data},
+		})
+	}
+
+	metaRangeID, err := f.writeDump(ctx, records)
+	if err != nil {
+		return fmt.Errorf("write commits: %w", err)
+	}
+	return f.catalog.Store.LoadCommits(ctx, f.fork, metaRangeID)
+}
+
+func (f *repositoryForker) forkBranches(ctx context.Context) error {
+	branchesMetaRangeID, err := f.catalog.Store.DumpBranches(ctx, f.source)
+	if err != nil {
+		return fmt.Errorf("dump source branches: %w", err)
+	}
+	var records []*graveler.ValueRecord
+	err = f.listDump(ctx, *branchesMetaRangeID, func(record *graveler.ValueRecord) error {
+		data := &graveler.BranchData{}
+		if err := proto.Unmarshal(record.Data, data); err != nil {
+			return err
+		}
+		forkCommitID, ok := f.commits[graveler.CommitID(data.CommitId)]
+		if !ok {
+			return fmt.Errorf("branch %s commit %s: %w", data.Id, data.CommitId, graveler.ErrCommitNotFound)
+		}
+		data.CommitId = forkCommitID.String()
+		b, err := proto.Marshal(data)
+		if err != nil {
+			return err
+		}
+		records = append(records, &graveler.ValueRecord{
+			Key:   record.Key.Copy(),
+			Value: &graveler.Value{Identity: []byte(forkCommitID), Data: b},
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read branches: %w", err)
+	}
+	metaRangeID, err := f.writeDump(ctx, records)
+	if err != nil {
+		return fmt.Errorf("write branches: %w", err)
+	}
+	return f.catalog.Store.LoadBranches(ctx, f.fork, metaRangeID)
+}
+
+func (f *repositoryForker) forkTags(ctx context.Context) error {
+	tagsMetaRangeID, err := f.catalog.Store.DumpTags(ctx, f.source)
+	if err != nil {
+		return fmt.Errorf("dump source tags: %w", err)
+	}
+	var records []*graveler.ValueRecord
+	err = f.listDump(ctx, *tagsMetaRangeID, func(record *graveler.ValueRecord) error {
+		data := &graveler.TagData{}
+		if err := proto.Unmarshal(record.Data, data); err != nil {
+			return err
+		}
+		forkCommitID, ok := f.commits[graveler.CommitID(data.CommitId)]
+		if !ok {
+			return fmt.Errorf("tag %s commit %s: %w", data.Id, data.CommitId, graveler.ErrCommitNotFound)
+		}
+		data.CommitId = forkCommitID.String()
+		b, err := proto.Marshal(data)
+		if err != nil {
+			return err
+		}
+		records = append(records, &graveler.ValueRecord{
+			Key:   record.Key.Copy(),
+			Value: &graveler.Value{Identity: []byte(forkCommitID), Data: b},
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read tags: %w", err)
+	}
+	metaRangeID, err := f.writeDump(ctx, records)
+	if err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	return f.catalog.Store.LoadTags(ctx, f.fork, metaRangeID)
+}
+
+// writeDump writes the records as a metarange in the fork storage namespace, in the format used by the Load* methods
+func (f *repositoryForker) writeDump(ctx context.Context, records []*graveler.ValueRecord) (graveler.MetaRangeID, error) {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key.String() < records[j].Key.String()
+	})
+	metaRangeID, err := f.catalog.Store.WriteMetaRangeByIterator(ctx, f.fork, &valueRecordsIterator{records: records})
+	if err != nil {
+		return "", err
+	}
+	return *metaRangeID, nil
+}
+
+func (f *repositoryForker) rewriteMetaRange(ctx context.Context, metaRangeID graveler.MetaRangeID) (graveler.MetaRangeID, error) {
+	if metaRangeID == "" {
+		return "", nil
+	}
+	forkMetaRangeID, err := f.metaRanges.GetOrSet(metaRangeID, func() (interface{}, error) {
+		it, err := f.catalog.metaRangeManager.NewMetaRangeIterator(ctx, f.source.StorageNamespace, metaRangeID)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var ranges []*graveler.RangeInfo
+		for it.NextRange() {
+			_, rng := it.Value()
+			forkRanges, err := f.rewriteRange(ctx, rng.ID)
+			if err != nil {
+				return nil, fmt.Errorf("range %s: %w", rng.ID, err)
+			}
+			ranges = append(ranges, forkRanges...)
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		info, err := f.catalog.Store.WriteMetaRange(ctx, f.fork, ranges)
+		if err != nil {
+			return nil, err
+		}
+		return info.ID, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return forkMetaRangeID.(graveler.MetaRangeID), nil
+}
+
+// rewriteRange writes the range entries to the fork using full addresses. A range may be split to
+// several ranges on the fork, as the rewritten entries are larger.
+func (f *repositoryForker) rewriteRange(ctx context.Context, rangeID committed.ID) ([]*graveler.RangeInfo, error) {
+	forkRanges, err := f.ranges.GetOrSet(rangeID, func() (interface{}, error) {
+		rangeIt, err := f.catalog.rangeManager.NewRangeIterator(ctx, committed.Namespace(f.source.StorageNamespace), rangeID)
+		if err != nil {
+			return nil, err
+		}
+		it := &forkEntriesIterator{
+			it:               committed.NewUnmarshalIterator(rangeIt),
+			adapter:          f.catalog.BlockAdapter,
+			storageNamespace: f.source.StorageNamespace.String(),
+		}
+		defer it.Close()
+
+		var forkRanges []*graveler.RangeInfo
+		for it.peek() {
+			info, err := f.catalog.Store.WriteRange(ctx, f.fork, it)
+			if err != nil {
+				return nil, err
+			}
+			forkRanges = append(forkRanges, info)
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		return forkRanges, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forkRanges.([]*graveler.RangeInfo), nil
+}
+
+// forkEntriesIterator converts entries with a relative address to use the full address of the object in
+// the source storage namespace. Supports peeking, so ranges are written until the iterator is exhausted.
+type forkEntriesIterator struct {
+	it               graveler.ValueIterator
+	adapter          block.Adapter
+	storageNamespace string
+	value            *graveler.ValueRecord
+	peeked           bool
+	done             bool
+	err              error
+}
+
+// peek returns true if there are more values, without advancing the iterator
+func (it *forkEntriesIterator) peek() bool {
+	if it.peeked {
+		return !it.done
+	}
+	it.peeked = true
+	it.done = !it.next()
+	return !it.done
+}
+
+func (it *forkEntriesIterator) next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	record := it.it.Value()
+	entry, err := ValueToEntry(record.Value)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if isRelativeAddress(entry) {
+		qk, err := it.adapter.ResolveNamespace(it.storageNamespace, entry.Address, addressTypeToCatalog(entry.AddressType).ToIdentifierType())
+		if err != nil {
+			it.err = fmt.Errorf("resolve %s: %w", entry.Address, err)
+			return false
+		}
+		entry.Address = qk.Format()
+		entry.AddressType = Entry_FULL
+	}
+	value, err := EntryToValue(entry)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.value = &graveler.ValueRecord{Key: record.Key, Value: value}
+	return true
+}
+
+func (it *forkEntriesIterator) Next() bool {
+	if !it.peek() {
+		return false
+	}
+	it.peeked = false
+	return true
+}
+
+func (it *forkEntriesIterator) SeekGE(id graveler.Key) {
+	it.it.SeekGE(id)
+	it.value = nil
+	it.peeked = false
+	it.done = false
+}
+
+func (it *forkEntriesIterator) Value() *graveler.ValueRecord {
+	return it.value
+}
+
+func (it *forkEntriesIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err()
+}
+
+func (it *forkEntriesIterator) Close() {
+	it.it.Close()
+}
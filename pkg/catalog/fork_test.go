@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestForkEntriesIterator(t *testing.T) {
+	var records []*graveler.ValueRecord
+	for i := 0; i < 5; i++ {
+		records = append(records, &graveler.ValueRecord{
+			Key:   []byte(fmt.Sprintf("path%d", i)),
+			Value: MustEntryToValue(&Entry{Address: fmt.Sprintf("addr%d", i), AddressType: Entry_RELATIVE}),
+		})
+	}
+	it := &forkEntriesIterator{
+		it:               NewFakeValueIterator(records),
+		adapter:          mem.New(context.Background()),
+		storageNamespace: "mem://source",
+	}
+	defer it.Close()
+
+	collect := func(t *testing.T) []string {
+		t.Helper()
+		var keys []string
+		for it.Next() {
+			entry, err := ValueToEntry(it.Value().Value)
+			require.NoError(t, err)
+			require.Equal(t, Entry_FULL, entry.AddressType)
+			require.Equal(t, "mem://source/addr"+string(it.Value().Key[len("path"):]), entry.Address)
+			keys = append(keys, string(it.Value().Key))
+		}
+		require.NoError(t, it.Err())
+		return keys
+	}
+
+	require.True(t, it.peek())
+	require.Equal(t, []string{"path0", "path1", "path2", "path3", "path4"}, collect(t))
+
+	it.SeekGE([]byte("path3"))
+	require.Equal(t, []string{"path3", "path4"}, collect(t))
+
+	// seek after peeking drops the peeked value
+	it.SeekGE([]byte("path0"))
+	require.True(t, it.peek())
+	it.SeekGE([]byte("path2"))
+	require.Equal(t, []string{"path2", "path3", "path4"}, collect(t))
+}
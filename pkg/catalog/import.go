@@ -3,6 +3,7 @@ package catalog
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -29,9 +30,15 @@ type Import struct {
 	status        graveler.ImportStatus
 	closed        bool
 	mu            sync.Mutex
+	// detectCollisions fails ingest of an entry whose path was already ingested
+	detectCollisions bool
+	// collisionMu serializes the check and write of an entry path when detecting collisions
+	collisionMu sync.Mutex
 }
 
-func NewImport(ctx context.Context, cancel context.CancelFunc, logger logging.Logger, kvStore kv.Store, repository *graveler.RepositoryRecord, importID string) (*Import, error) {
+// NewImport starts an import. With detectCollisions, ingesting two entries to the same path fails the import instead
+// of keeping the last one.
+func NewImport(ctx context.Context, cancel context.CancelFunc, logger logging.Logger, kvStore kv.Store, repository *graveler.RepositoryRecord, importID string, detectCollisions bool) (*Import, error) {
 	status := graveler.ImportStatus{
 		ID:        graveler.ImportID(importID),
 		UpdatedAt: time.Now(),
@@ -52,13 +59,14 @@ func NewImport(ctx context.Context, cancel context.CancelFunc, logger logging.Lo
 	}
 
 	i := Import{
-		db:            importDB,
-		dbPath:        dbPath,
-		kvStore:       kvStore,
-		status:        status,
-		logger:        logger,
-		repoPartition: repoPartition,
-		mu:            sync.Mutex{},
+		db:               importDB,
+		dbPath:           dbPath,
+		kvStore:          kvStore,
+		status:           status,
+		logger:           logger,
+		repoPartition:    repoPartition,
+		mu:               sync.Mutex{},
+		detectCollisions: detectCollisions,
 	}
 
 	i.wg.Go(func() error {
@@ -86,6 +94,18 @@ func (i *Import) set(record EntryRecord) error {
 	if err != nil {
 		return err
 	}
+	if i.detectCollisions {
+		i.collisionMu.Lock()
+		defer i.collisionMu.Unlock()
+		_, closer, err := i.db.Get(key)
+		switch {
+		case err == nil:
+			_ = closer.Close()
+			return fmt.Errorf("%w: %s", ErrImportCollision, record.Path)
+		case !errors.Is(err, pebble.ErrNotFound):
+			return err
+		}
+	}
 	return i.db.Set(key, data, &pebble.WriteOptions{
 		Sync: false,
 	})
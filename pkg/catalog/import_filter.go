@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gobwas/glob"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// importPathSeparator is the separator used by include/exclude patterns: '*' will not match it while '**' will.
+const importPathSeparator = '/'
+
+// ImportRemap rewrites the source object key (relative to the import source path) before it is
+// placed under the import destination. Pattern is a regular expression matched against the
+// relative key and Replacement is expanded using the pattern's capture groups ($1, ${name}).
+type ImportRemap struct {
+	Pattern     string
+	Replacement string
+}
+
+// ImportFilter selects which objects are ingested from an import source and how their keys are mapped
+// into the destination. A nil filter imports everything as-is.
+type ImportFilter struct {
+	include []glob.Glob
+	exclude []glob.Glob
+	remap   *regexp.Regexp
+	replace string
+}
+
+// NewImportFilter compiles include/exclude glob patterns and an optional remap.
+// Patterns are matched against the object key relative to the source path.
+// Returns nil when there is nothing to filter or remap.
+func NewImportFilter(include, exclude []string, remap *ImportRemap) (*ImportFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 && remap == nil {
+		return nil, nil
+	}
+	f := &ImportFilter{}
+	var err error
+	f.include, err = compileImportGlobs(include)
+	if err != nil {
+		return nil, err
+	}
+	f.exclude, err = compileImportGlobs(exclude)
+	if err != nil {
+		return nil, err
+	}
+	if remap != nil {
+		f.remap, err = regexp.Compile(remap.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("remap pattern '%s': %w: %s", remap.Pattern, graveler.ErrInvalidValue, err)
+		}
+		f.replace = remap.Replacement
+	}
+	return f, nil
+}
+
+func compileImportGlobs(patterns []string) ([]glob.Glob, error) {
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, importPathSeparator)
+		if err != nil {
+			return nil, fmt.Errorf("pattern '%s': %w: %s", pattern, graveler.ErrInvalidValue, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// Apply returns the key to use for a source object relative key, and false in case the object should
+// be skipped. An object is skipped when it doesn't match any include pattern (if any are set), matches an
+// exclude pattern or is remapped to an empty key.
+func (f *ImportFilter) Apply(relativeKey string) (string, bool) {
+	if f == nil {
+		return relativeKey, true
+	}
+	if len(f.include) > 0 && !matchAnyGlob(f.include, relativeKey) {
+		return "", false
+	}
+	if matchAnyGlob(f.exclude, relativeKey) {
+		return "", false
+	}
+	if f.remap == nil {
+		return relativeKey, true
+	}
+	match := f.remap.FindStringSubmatchIndex(relativeKey)
+	if match == nil {
+		return relativeKey, true
+	}
+	// replace only the first match, keeping the rest of the key as-is
+	replaced := f.remap.ExpandString(nil, f.replace, relativeKey, match)
+	key := relativeKey[:match[0]] + string(replaced) + relativeKey[match[1]:]
+	if key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// Remaps reports whether the filter rewrites keys, which may map different source objects to the same key
+func (f *ImportFilter) Remaps() bool {
+	return f != nil && f.remap != nil
+}
+
+func matchAnyGlob(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package catalog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestImportFilter(t *testing.T) {
+	type result struct {
+		key string
+		ok  bool
+	}
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		remap    *catalog.ImportRemap
+		expected map[string]result
+	}{
+		{
+			name: "no filter",
+			expected: map[string]result{
+				"a/b.parquet": {key: "a/b.parquet", ok: true},
+			},
+		},
+		{
+			name:    "include",
+			include: []string{"**/*.parquet", "*.parquet"},
+			expected: map[string]result{
+				"b.parquet":       {key: "b.parquet", ok: true},
+				"a/b/c.parquet":   {key: "a/b/c.parquet", ok: true},
+				"a/b/c.csv":       {ok: false},
+				"a/b.parquet/crc": {ok: false},
+			},
+		},
+		{
+			name:    "exclude",
+			exclude: []string{"_temporary/**", "**/_SUCCESS"},
+			expected: map[string]result{
+				"_temporary/0/part-0": {ok: false},
+				"a/_SUCCESS":          {ok: false},
+				"a/part-0":            {key: "a/part-0", ok: true},
+			},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"tables/**"},
+			exclude: []string{"tables/tmp/**"},
+			expected: map[string]result{
+				"tables/orders/part-0": {key: "tables/orders/part-0", ok: true},
+				"tables/tmp/part-0":    {ok: false},
+				"other/part-0":         {ok: false},
+			},
+		},
+		{
+			name:  "remap strip prefix",
+			remap: &catalog.ImportRemap{Pattern: `^dt=[^/]+/`, Replacement: ""},
+			expected: map[string]result{
+				"dt=2023-01-01/orders/part-0": {key: "orders/part-0", ok: true},
+				"orders/part-1":               {key: "orders/part-1", ok: true},
+				"dt=2023-01-01/":              {ok: false},
+			},
+		},
+		{
+			name:    "remap capture groups",
+			include: []string{"**.csv"},
+			remap:   &catalog.ImportRemap{Pattern: `^(?P<year>\d{4})/(\d{2})/`, Replacement: "year=${year}/month=$2/"},
+			expected: map[string]result{
+				"2023/05/data.csv": {key: "year=2023/month=05/data.csv", ok: true},
+				"2023/05/data.txt": {ok: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := catalog.NewImportFilter(tt.include, tt.exclude, tt.remap)
+			if err != nil {
+				t.Fatalf("NewImportFilter: %s", err)
+			}
+			for key, expected := range tt.expected {
+				newKey, ok := f.Apply(key)
+				if ok != expected.ok || newKey != expected.key {
+					t.Errorf("Apply(%s) = (%s, %t), expected (%s, %t)", key, newKey, ok, expected.key, expected.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestImportFilter_Invalid(t *testing.T) {
+	if _, err := catalog.NewImportFilter([]string{"[a-"}, nil, nil); !errors.Is(err, graveler.ErrInvalidValue) {
+		t.Errorf("invalid include pattern err=%v, expected %s", err, graveler.ErrInvalidValue)
+	}
+	if _, err := catalog.NewImportFilter(nil, nil, &catalog.ImportRemap{Pattern: "("}); !errors.Is(err, graveler.ErrInvalidValue) {
+		t.Errorf("invalid remap pattern err=%v, expected %s", err, graveler.ErrInvalidValue)
+	}
+	p := catalog.ImportPath{Path: "s3://bucket/obj", Type: catalog.ImportPathTypeObject, Include: []string{"*"}}
+	if _, err := p.Filter(); !errors.Is(err, graveler.ErrInvalidValue) {
+		t.Errorf("filter on object path err=%v, expected %s", err, graveler.ErrInvalidValue)
+	}
+}
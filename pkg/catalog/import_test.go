@@ -0,0 +1,81 @@
+package catalog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/catalog/testutils"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestCatalog_ImportRemapCollision(t *testing.T) {
+	const sourceURI = "s3://bucket/prefix"
+	tests := []struct {
+		Name         string
+		RelativeKeys []string
+		Expected     []string
+		ExpectedErr  string
+	}{
+		{
+			Name:         "distinct",
+			RelativeKeys: []string{"dt=1/a", "dt=1/b"},
+			Expected:     []string{"data/a", "data/b"},
+		},
+		{
+			Name:         "collision",
+			RelativeKeys: []string{"dt=1/a", "dt=2/a"},
+			ExpectedErr:  "import destination collision: invalid value: validation error: data/a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx := context.Background()
+			walker := testutils.NewFakeWalker(0, 0, sourceURI, "", "", sourceURI, nil)
+			for _, key := range tt.RelativeKeys {
+				walker.Entries = append(walker.Entries, block.ObjectStoreEntry{
+					RelativeKey: key,
+					FullKey:     sourceURI + "/" + key,
+					Address:     sourceURI + "/" + key,
+					ETag:        "etag-" + key,
+				})
+			}
+			c := newTestCatalog(t, testutils.FakeFactory{Walker: walker})
+			const repo = "import-remap"
+			createTestRepository(t, c, repo)
+
+			importID, err := c.Import(ctx, repo, "main", catalog.ImportRequest{
+				Paths: []catalog.ImportPath{{
+					Path:        sourceURI,
+					Destination: "data/",
+					Type:        catalog.ImportPathTypePrefix,
+					Remap:       &catalog.ImportRemap{Pattern: "^dt=[^/]+/", Replacement: ""},
+				}},
+				Commit: catalog.ImportCommit{Committer: "tester", CommitMessage: "import", Metadata: catalog.Metadata{}},
+			})
+			require.NoError(t, err)
+			var status *graveler.ImportStatus
+			require.Eventually(t, func() bool {
+				status, err = c.GetImportStatus(ctx, repo, importID)
+				require.NoError(t, err)
+				return status.Completed || status.Error != nil
+			}, 10*time.Second, 100*time.Millisecond)
+
+			if tt.ExpectedErr != "" {
+				require.ErrorContains(t, status.Error, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, status.Error)
+			entries, _, err := c.ListEntries(ctx, repo, "main", "", "", "", -1)
+			require.NoError(t, err)
+			paths := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				paths = append(paths, entry.Path)
+			}
+			require.Equal(t, tt.Expected, paths)
+		})
+	}
+}
@@ -89,6 +89,12 @@ type Interface interface {
 	// DeleteRepository delete a repository
 	DeleteRepository(ctx context.Context, repository string) error
 
+	// ForkRepository create a new repository pointing to 'storageNamespace' with the commits, branches and tags of 'sourceRepository'.
+	// Committed objects are shared with the source repository and are not copied. The history is written asynchronously,
+	// returns the fork ID used to query its status.
+	ForkRepository(ctx context.Context, sourceRepository, repository, storageNamespace string) (string, error)
+	GetForkStatus(ctx context.Context, sourceRepository, forkID string) (*ForkStatus, error)
+
 	// GetRepositoryMetadata get repository metadata
 	GetRepositoryMetadata(ctx context.Context, repository string) (graveler.RepositoryMetadata, error)
 
@@ -131,6 +137,14 @@ type Interface interface {
 	// CherryPick creates a patch to the given commit, and applies it as a new commit on the given branch.
 	CherryPick(ctx context.Context, repository, branch string, params CherryPickParams) (*CommitLog, error)
 
+	// Rebalance starts rewriting the ranges of the branch commit into balanced ranges, committed on the branch with an
+	// identical tree. Returns the rebalance ID used to query its status.
+	Rebalance(ctx context.Context, repository, branch, message, committer string, metadata Metadata) (string, error)
+	GetRebalanceStatus(ctx context.Context, repository, rebalanceID string) (*RebalanceStatus, error)
+
+	// GetRangeStats returns the distribution of range sizes in the metarange of the commit referenced by reference.
+	GetRangeStats(ctx context.Context, repository, reference string) (*RangeStats, error)
+
 	Diff(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
 	Compare(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
 	DiffUncommitted(ctx context.Context, repository, branch, prefix, delimiter string, limit int, after string) (Differences, bool, error)
@@ -152,6 +166,9 @@ type Interface interface {
 	Import(ctx context.Context, repositoryID, branchID string, params ImportRequest) (string, error)
 	GetImportStatus(ctx context.Context, repositoryID, importID string) (*graveler.ImportStatus, error)
 	CancelImport(ctx context.Context, repositoryID, importID string) error
+	Export(ctx context.Context, repositoryID string, params ExportRequest) (string, error)
+	GetExportStatus(ctx context.Context, repositoryID, exportID string) (*ExportStatus, error)
+	CancelExport(ctx context.Context, repositoryID, exportID string) error
 	WriteRange(ctx context.Context, repositoryID string, params WriteRangeRequest) (*graveler.RangeInfo, *Mark, error)
 	WriteMetaRange(ctx context.Context, repositoryID string, ranges []*graveler.RangeInfo) (*graveler.MetaRangeInfo, error)
 	UpdateBranchToken(ctx context.Context, repositoryID, branchID, stagingToken string) error
@@ -168,6 +185,14 @@ type Interface interface {
 	DeleteBranchProtectionRule(ctx context.Context, repositoryID string, pattern string) error
 	CreateBranchProtectionRule(ctx context.Context, repositoryID string, pattern string, blockedActions []graveler.BranchProtectionBlockedAction) error
 
+	SetBranchReplication(ctx context.Context, repositoryID, branchID string, target ReplicationTarget) (*ReplicationStatus, error)
+	GetBranchReplication(ctx context.Context, repositoryID, branchID string) (*ReplicationStatus, error)
+	DeleteBranchReplication(ctx context.Context, repositoryID, branchID string) error
+
+	GetBranchCompaction(ctx context.Context, repositoryID, branchID string) (*BranchCompaction, error)
+	SetBranchCompaction(ctx context.Context, repositoryID, branchID string, stagedWritesThreshold int) (*BranchCompaction, error)
+	DeleteBranchCompaction(ctx context.Context, repositoryID, branchID string) error
+
 	// SetLinkAddress to validate single use limited in time of a given physical address
 	SetLinkAddress(ctx context.Context, repository, token string) error
 	VerifyLinkAddress(ctx context.Context, repository, token string) error
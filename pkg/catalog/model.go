@@ -45,6 +45,27 @@ type CommitLog struct {
 	Parents      []string
 }
 
+// RangeStats describes the ranges of a commit metarange
+type RangeStats struct {
+	MetaRangeID string
+	RangeCount  int
+	RecordCount int64
+	// SizeBytes is the distribution of the estimated range sizes in bytes
+	SizeBytes Distribution
+	// Records is the distribution of the number of records in each range
+	Records Distribution
+}
+
+// Distribution summarizes a set of values
+type Distribution struct {
+	Min  int64
+	Max  int64
+	Mean int64
+	P50  int64
+	P90  int64
+	P99  int64
+}
+
 type Branch struct {
 	Name      string
 	Reference string
@@ -0,0 +1,192 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/validator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const rebalancesPrefix = "rebalances"
+
+// RebalanceStatus tracks a rebalance started by Catalog.Rebalance
+type RebalanceStatus struct {
+	ID        string
+	Branch    string
+	Completed bool
+	UpdatedAt time.Time
+	// CommitID is the commit of the rebalanced ranges, set on completion
+	CommitID string
+	Error    error
+}
+
+func RebalancesPath(rebalanceID string) string {
+	return kv.FormatPath(rebalancesPrefix, rebalanceID)
+}
+
+func RebalanceStatusFromProto(pb *RebalanceStatusData) *RebalanceStatus {
+	var statusErr error
+	if pb.Error != "" {
+		statusErr = fmt.Errorf("%w: %s", ErrRebalance, pb.Error)
+	}
+	return &RebalanceStatus{
+		ID:        pb.Id,
+		Branch:    pb.Branch,
+		Completed: pb.Completed,
+		UpdatedAt: pb.UpdatedAt.AsTime(),
+		CommitID:  pb.CommitId,
+		Error:     statusErr,
+	}
+}
+
+// Rebalance starts an asynchronous rewrite of the ranges of the branch commit into balanced ranges, committed on the
+// branch with an identical tree. Returns the rebalance ID used to query its status.
+func (c *Catalog) Rebalance(ctx context.Context, repositoryID, branch, message, committer string, metadata Metadata) (string, error) {
+	branchID := graveler.BranchID(branch)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+	}); err != nil {
+		return "", err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Store.GetBranch(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+
+	id := xid.New().String()
+	status := &RebalanceStatusData{
+		Id:        id,
+		Branch:    branch,
+		UpdatedAt: timestamppb.Now(),
+	}
+	if err := c.setRebalanceStatus(ctx, repository, status); err != nil {
+		return "", err
+	}
+
+	logger := c.log(ctx).WithFields(logging.Fields{"rebalance_id": id, "branch": branch})
+	params := graveler.CommitParams{
+		Committer: committer,
+		Message:   message,
+		Metadata:  map[string]string(metadata),
+	}
+	go func() {
+		// Need a new context for the async operations
+		ctx := context.Background()
+		commitID, err := c.Store.Rebalance(ctx, repository, branchID, params)
+		if err != nil {
+			logger.WithError(err).Error("rebalance failure")
+			status.Error = err.Error()
+		} else {
+			status.Completed = true
+			status.CommitId = commitID.String()
+			c.triggerReplication(ctx, repository.RepositoryID, branchID)
+		}
+		status.UpdatedAt = timestamppb.Now()
+		if err := c.setRebalanceStatus(ctx, repository, status); err != nil {
+			logger.WithError(err).Error("failed to update rebalance status")
+		}
+	}()
+	return id, nil
+}
+
+func (c *Catalog) setRebalanceStatus(ctx context.Context, repository *graveler.RepositoryRecord, status *RebalanceStatusData) error {
+	return kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(RebalancesPath(status.Id)), status)
+}
+
+func (c *Catalog) GetRebalanceStatus(ctx context.Context, repositoryID, rebalanceID string) (*RebalanceStatus, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	data := &RebalanceStatusData{}
+	_, err = kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(RebalancesPath(rebalanceID)), data)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, graveler.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return RebalanceStatusFromProto(data), nil
+}
+
+func (c *Catalog) GetRangeStats(ctx context.Context, repositoryID, reference string) (*RangeStats, error) {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return nil, err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := c.dereferenceCommitID(ctx, repository, graveler.Ref(reference))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := c.Store.GetCommit(ctx, repository, commitID)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, repository.StorageNamespace, commit.MetaRangeID)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var sizes, records []int64
+	for it.NextRange() {
+		_, rng := it.Value()
+		sizes = append(sizes, int64(rng.EstimatedSize))
+		records = append(records, rng.Count)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &RangeStats{
+		MetaRangeID: string(commit.MetaRangeID),
+		RangeCount:  len(sizes),
+		SizeBytes:   newDistribution(sizes),
+		Records:     newDistribution(records),
+	}
+	for _, n := range records {
+		stats.RecordCount += n
+	}
+	return stats, nil
+}
+
+// newDistribution summarizes values, percentiles use the nearest rank. values are sorted in place.
+func newDistribution(values []int64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	var sum int64
+	for _, v := range values {
+		sum += v
+	}
+	percentile := func(p int) int64 {
+		rank := (p*len(values) + 99) / 100 //nolint:gomnd
+		return values[rank-1]
+	}
+	return Distribution{
+		Min:  values[0],
+		Max:  values[len(values)-1],
+		Mean: sum / int64(len(values)),
+		P50:  percentile(50), //nolint:gomnd
+		P90:  percentile(90), //nolint:gomnd
+		P99:  percentile(99), //nolint:gomnd
+	}
+}
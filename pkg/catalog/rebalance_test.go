@@ -0,0 +1,43 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestNewDistribution(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		want   Distribution
+	}{
+		{
+			name: "empty",
+			want: Distribution{},
+		},
+		{
+			name:   "single",
+			values: []int64{7},
+			want:   Distribution{Min: 7, Max: 7, Mean: 7, P50: 7, P90: 7, P99: 7},
+		},
+		{
+			name:   "unsorted",
+			values: []int64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5},
+			want:   Distribution{Min: 1, Max: 10, Mean: 5, P50: 5, P90: 9, P99: 10},
+		},
+		{
+			name:   "skewed",
+			values: []int64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1000},
+			want:   Distribution{Min: 1, Max: 1000, Mean: 100, P50: 1, P90: 1, P99: 1000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDistribution(tt.values)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Fatal("newDistribution() diff:", diff)
+			}
+		})
+	}
+}
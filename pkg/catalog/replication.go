@@ -0,0 +1,668 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/committed"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/validator"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	replicationsPrefix = "replications"
+
+	// replicationLagMaxCommits bounds the number of commits scanned when computing the replication lag
+	replicationLagMaxCommits = 1000
+)
+
+var (
+	replicationLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "replication_lag_seconds",
+		Help: "Time since the oldest source commit that was not yet replicated to the target branch.",
+	}, []string{"repository", "branch"})
+
+	replicationCommits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "replication_commits_total",
+		Help: "The total number of commits replicated to target repositories.",
+	}, []string{"repository", "branch"})
+
+	replicationObjects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "replication_objects_total",
+		Help: "The total number of objects copied to target storage namespaces by replication.",
+	}, []string{"repository", "branch"})
+
+	replicationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "replication_failures_total",
+		Help: "The total number of failed replication runs.",
+	}, []string{"repository", "branch"})
+)
+
+// ReplicationTarget is the repository and branch a source branch is replicated to. The target repository is
+// managed by the same lakeFS installation, replicating to a remote lakeFS installation is a planned follow-up.
+type ReplicationTarget struct {
+	Repository string
+	// Branch on the target repository, defaults to the source branch name
+	Branch string
+}
+
+type ReplicationStatus struct {
+	SourceBranch      string
+	TargetRepository  string
+	TargetBranch      string
+	CreatedAt         time.Time
+	SourceCommitID    string
+	LastCommitID      string
+	LastReplicatedAt  time.Time
+	ReplicatedCommits int64
+	ReplicatedObjects int64
+	UpdatedAt         time.Time
+	// Lag is the time since the oldest source commit that was not replicated yet, zero when in sync
+	Lag   time.Duration
+	Error error
+}
+
+func ReplicationPath(branchID string) string {
+	return kv.FormatPath(replicationsPrefix, branchID)
+}
+
+// replicationWorker coalesces replication triggers of a single branch, so only one replication
+// of a branch runs at a time and commits made while it runs are replicated by a single follow-up run.
+type replicationWorker struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
+
+// replicationHooksHandler wraps the configured hooks handler and triggers replication of branches after every
+// operation that may move the branch head. Rebalance doesn't run hooks, and triggers replication itself.
+type replicationHooksHandler struct {
+	graveler.HooksHandler
+	catalog *Catalog
+}
+
+func (h *replicationHooksHandler) PostCommitHook(ctx context.Context, record graveler.HookRecord) error {
+	err := h.HooksHandler.PostCommitHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+	return err
+}
+
+func (h *replicationHooksHandler) PostMergeHook(ctx context.Context, record graveler.HookRecord) error {
+	err := h.HooksHandler.PostMergeHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+	return err
+}
+
+func (h *replicationHooksHandler) PostRevertHook(ctx context.Context, record graveler.HookRecord) error {
+	err := h.HooksHandler.PostRevertHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+	return err
+}
+
+func (h *replicationHooksHandler) PostCherryPickHook(ctx context.Context, record graveler.HookRecord) error {
+	err := h.HooksHandler.PostCherryPickHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+	return err
+}
+
+func (h *replicationHooksHandler) PostImportHook(ctx context.Context, record graveler.HookRecord) error {
+	err := h.HooksHandler.PostImportHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+	return err
+}
+
+func (h *replicationHooksHandler) PostResetHook(ctx context.Context, record graveler.HookRecord) {
+	h.HooksHandler.PostResetHook(ctx, record)
+	h.catalog.triggerReplication(ctx, record.RepositoryID, record.BranchID)
+}
+
+// triggerReplication starts a replication of the branch in case it has a replication rule
+func (c *Catalog) triggerReplication(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID) {
+	repository, err := c.Store.GetRepository(ctx, repositoryID)
+	if err != nil {
+		c.log(ctx).WithError(err).WithField("repository", repositoryID).Warn("Failed to get repository for replication")
+		return
+	}
+	_, err = kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ReplicationPath(branchID.String())), &ReplicationData{})
+	if errors.Is(err, kv.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		c.log(ctx).WithError(err).WithFields(logging.Fields{"repository": repositoryID, "branch": branchID}).Warn("Failed to get branch replication")
+		return
+	}
+	c.startReplication(repository, branchID)
+}
+
+func (c *Catalog) startReplication(repository *graveler.RepositoryRecord, branchID graveler.BranchID) {
+	key := repository.RepositoryID.String() + "/" + branchID.String()
+	v, _ := c.replicationWorkers.LoadOrStore(key, &replicationWorker{})
+	worker := v.(*replicationWorker)
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	if worker.running {
+		worker.pending = true
+		return
+	}
+	worker.running = true
+	go func() {
+		logger := logging.ContextUnavailable().WithFields(logging.Fields{
+			"repository": repository.RepositoryID,
+			"branch":     branchID,
+		})
+		for {
+			// Need a new context for the async operations
+			if err := c.replicateBranch(context.Background(), repository, branchID, logger); err != nil {
+				logger.WithError(err).Error("Branch replication failed")
+			}
+			worker.mu.Lock()
+			if !worker.pending {
+				worker.running = false
+				worker.mu.Unlock()
+				return
+			}
+			worker.pending = false
+			worker.mu.Unlock()
+		}
+	}()
+}
+
+// replicateBranch replicates the source branch head to the target branch: copies the objects, ranges and metaranges
+// of all commits missing from the target repository, adds the commits with their original IDs and points the target
+// branch to the source branch head.
+func (c *Catalog) replicateBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, logger logging.Logger) error {
+	partition := graveler.RepoPartition(repository)
+	key := []byte(ReplicationPath(branchID.String()))
+	data := &ReplicationData{}
+	pred, err := kv.GetMsg(ctx, c.KVStore, partition, key, data)
+	if errors.Is(err, kv.ErrNotFound) {
+		// replication was removed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	labels := []string{repository.RepositoryID.String(), branchID.String()}
+	commits, objects, head, err := c.replicateBranchHead(ctx, repository, branchID, data, logger)
+	if err != nil {
+		replicationFailures.WithLabelValues(labels...).Inc()
+		data.Error = err.Error()
+		data.UpdatedAt = timestamppb.Now()
+		if setErr := kv.SetMsgIf(ctx, c.KVStore, partition, key, data, pred); setErr != nil {
+			logger.WithError(setErr).Warn("Failed to update replication status")
+		}
+		return err
+	}
+	replicationCommits.WithLabelValues(labels...).Add(float64(commits))
+	replicationObjects.WithLabelValues(labels...).Add(float64(objects))
+	if head == "" {
+		// nothing to replicate
+		replicationLag.WithLabelValues(labels...).Set(0)
+		return nil
+	}
+
+	now := timestamppb.Now()
+	data.LastCommitId = head.String()
+	data.LastReplicatedAt = now
+	data.ReplicatedCommits += int64(commits)
+	data.ReplicatedObjects += objects
+	data.Error = ""
+	data.UpdatedAt = now
+	err = kv.SetMsgIf(ctx, c.KVStore, partition, key, data, pred)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		// replication was updated by another run - run again to make sure the latest head is replicated
+		logger.Debug("Replication status updated concurrently, replicating again")
+		c.startReplication(repository, branchID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	replicationLag.WithLabelValues(labels...).Set(0)
+	logger.WithFields(logging.Fields{"commit_id": head, "commits": commits, "objects": objects}).Info("Branch replicated")
+	return nil
+}
+
+// replicateBranchHead replicates the source branch current commit. Returns the number of commits and objects
+// copied and the replicated commit ID, or an empty commit ID when the target is up-to-date.
+func (c *Catalog) replicateBranchHead(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, data *ReplicationData, logger logging.Logger) (int, int64, graveler.CommitID, error) {
+	branch, err := c.Store.GetBranch(ctx, repository, branchID)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	head := branch.CommitID
+	if head.String() == data.LastCommitId {
+		return 0, 0, "", nil
+	}
+	lag, err := c.replicationLag(ctx, repository, head, graveler.CommitID(data.LastCommitId))
+	if err == nil {
+		replicationLag.WithLabelValues(repository.RepositoryID.String(), branchID.String()).Set(lag.Seconds())
+	}
+
+	target, err := c.getRepository(ctx, data.TargetRepository)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("target repository: %w", err)
+	}
+	missing, err := c.replicationMissingCommits(ctx, repository, target, head)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	logger.WithFields(logging.Fields{"commit_id": head, "missing_commits": len(missing)}).Debug("Replicating branch")
+
+	var objects int64
+	for _, commit := range missing {
+		n, err := c.replicateCommitObjects(ctx, repository, target, commit)
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("copy commit %s objects: %w", commit.CommitID, err)
+		}
+		objects += n
+		if err := c.replicateMetaRange(ctx, repository, target, commit.MetaRangeID); err != nil {
+			return 0, 0, "", fmt.Errorf("copy commit %s metarange: %w", commit.CommitID, err)
+		}
+	}
+	if len(missing) > 0 {
+		if err := c.loadReplicatedCommits(ctx, target, missing); err != nil {
+			return 0, 0, "", err
+		}
+	}
+
+	targetBranchID := graveler.BranchID(data.TargetBranch)
+	targetBranch, err := c.Store.GetBranch(ctx, target, targetBranchID)
+	switch {
+	case errors.Is(err, graveler.ErrNotFound):
+		_, err = c.Store.CreateBranch(ctx, target, targetBranchID, graveler.Ref(head))
+	case err == nil && targetBranch.CommitID != head:
+		_, err = c.Store.UpdateBranch(ctx, target, targetBranchID, graveler.Ref(head))
+	}
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("update target branch: %w", err)
+	}
+	return len(missing), objects, head, nil
+}
+
+// replicationMissingCommits returns the ancestors of commitID (including) which are missing from the target
+// repository, ordered by generation so parents come before their children.
+func (c *Catalog) replicationMissingCommits(ctx context.Context, source, target *graveler.RepositoryRecord, commitID graveler.CommitID) ([]*graveler.CommitRecord, error) {
+	var missing []*graveler.CommitRecord
+	seen := map[graveler.CommitID]struct{}{commitID: {}}
+	queue := []graveler.CommitID{commitID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		_, err := c.Store.GetCommit(ctx, target, id)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, graveler.ErrNotFound) {
+			return nil, err
+		}
+		commit, err := c.Store.GetCommit(ctx, source, id)
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, &graveler.CommitRecord{CommitID: id, Commit: commit})
+		for _, parent := range commit.Parents {
+			if _, ok := seen[parent]; !ok {
+				seen[parent] = struct{}{}
+				queue = append(queue, parent)
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].Generation < missing[j].Generation
+	})
+	return missing, nil
+}
+
+// replicateCommitObjects copies the objects added or changed by the commit (compared to its first parent) to the
+// target storage namespace. Objects with a full address (imported) are not part of the storage namespace and are
+// not copied.
+func (c *Catalog) replicateCommitObjects(ctx context.Context, source, target *graveler.RepositoryRecord, commit *graveler.CommitRecord) (int64, error) {
+	var it EntryDiffIterator
+	if len(commit.Parents) > 0 {
+		diffIt, err := c.Store.Diff(ctx, source, graveler.Ref(commit.Parents[0]), graveler.Ref(commit.CommitID))
+		if err != nil {
+			return 0, err
+		}
+		it = NewEntryDiffIterator(diffIt)
+	} else {
+		listIt, err := c.Store.List(ctx, source, graveler.Ref(commit.CommitID), ListEntriesLimitMax)
+		if err != nil {
+			return 0, err
+		}
+		it = &addedEntriesIterator{EntryIterator: NewValueToEntryIterator(listIt)}
+	}
+	defer it.Close()
+
+	var (
+		mu     sync.Mutex
+		copied int64
+	)
+	wg, wgCtx := c.workPool.GroupContext(ctx)
+	for it.Next() {
+		v := it.Value()
+		if v.Type == graveler.DiffTypeRemoved || !isRelativeAddress(v.Entry) {
+			continue
+		}
+		entry := v.Entry
+		wg.Submit(func() error {
+			identifierType := addressTypeToCatalog(entry.AddressType).ToIdentifierType()
+			err := c.copyObject(wgCtx, block.ObjectPointer{
+				StorageNamespace: source.StorageNamespace.String(),
+				Identifier:       entry.Address,
+				IdentifierType:   identifierType,
+			}, block.ObjectPointer{
+				StorageNamespace: target.StorageNamespace.String(),
+				Identifier:       entry.Address,
+				IdentifierType:   identifierType,
+			}, entry.Size)
+			if err != nil {
+				return fmt.Errorf("copy %s: %w", entry.Address, err)
+			}
+			mu.Lock()
+			copied++
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := it.Err(); err != nil {
+		_ = wg.Wait()
+		return 0, err
+	}
+	if err := wg.Wait(); err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
+
+// isRelativeAddress returns true if the entry address is relative to the repository storage namespace
+func isRelativeAddress(entry *Entry) bool {
+	switch addressTypeToCatalog(entry.AddressType) {
+	case AddressTypeRelative:
+		return true
+	case AddressTypeByPrefixDeprecated:
+		u, err := url.ParseRequestURI(entry.Address)
+		return err != nil || u.Scheme == ""
+	default:
+		return false
+	}
+}
+
+// replicateMetaRange copies the metarange and its ranges to the target storage namespace, skipping those
+// that already exist there.
+func (c *Catalog) replicateMetaRange(ctx context.Context, source, target *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID) error {
+	if metaRangeID == "" {
+		return nil
+	}
+	exists, err := c.metaRangeManager.Exists(ctx, target.StorageNamespace, metaRangeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, source.StorageNamespace, metaRangeID)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.NextRange() {
+		_, rng := it.Value()
+		exists, err := c.rangeManager.Exists(ctx, committed.Namespace(target.StorageNamespace), rng.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		address, err := c.metaRangeManager.GetRangeURI(ctx, source.StorageNamespace, graveler.RangeID(rng.ID))
+		if err != nil {
+			return err
+		}
+		if err := c.copyBetweenNamespaces(ctx, source, target, address); err != nil {
+			return fmt.Errorf("copy range %s: %w", rng.ID, err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	// copy the metarange last - once it exists on the target, its ranges are expected to exist too
+	address, err := c.metaRangeManager.GetMetaRangeURI(ctx, source.StorageNamespace, metaRangeID)
+	if err != nil {
+		return err
+	}
+	return c.copyBetweenNamespaces(ctx, source, target, address)
+}
+
+// copyBetweenNamespaces copies an object addressed relative to the storage namespace from the source repository
+// to the same address in the target repository
+func (c *Catalog) copyBetweenNamespaces(ctx context.Context, source, target *graveler.RepositoryRecord, address string) error {
+	return c.BlockAdapter.Copy(ctx, block.ObjectPointer{
+		StorageNamespace: source.StorageNamespace.String(),
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, block.ObjectPointer{
+		StorageNamespace: target.StorageNamespace.String(),
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+}
+
+// loadReplicatedCommits adds the commits to the target repository, keeping their original IDs.
+// The commits are written as a commits metarange and loaded the same way a repository refs dump is restored.
+func (c *Catalog) loadReplicatedCommits(ctx context.Context, target *graveler.RepositoryRecord, commits []*graveler.CommitRecord) error {
+	records := make([]*graveler.ValueRecord, 0, len(commits))
+	for _, commit := range commits {
+		data, err := proto.Marshal(graveler.ProtoFromCommit(commit.CommitID, commit.Commit))
+		if err != nil {
+			return err
+		}
+		records = append(records, &graveler.ValueRecord{
+			Key: graveler.Key(commit.CommitID),
+			Value: &graveler.Value{
+				Identity: []byte(commit.CommitID),
+				Data:     data,
+			},
+		})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key.String() < records[j].Key.String()
+	})
+	metaRangeID, err := c.Store.WriteMetaRangeByIterator(ctx, target, &valueRecordsIterator{records: records})
+	if err != nil {
+		return fmt.Errorf("write commits: %w", err)
+	}
+	if err := c.Store.LoadCommits(ctx, target, *metaRangeID); err != nil {
+		return fmt.Errorf("load commits: %w", err)
+	}
+	return nil
+}
+
+// replicationLag returns the time since the oldest first-parent ancestor of head that was committed after the
+// last replicated commit
+func (c *Catalog) replicationLag(ctx context.Context, repository *graveler.RepositoryRecord, head, lastCommitID graveler.CommitID) (time.Duration, error) {
+	if head == lastCommitID {
+		return 0, nil
+	}
+	it, err := c.Store.Log(ctx, repository, head, true)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	var oldest time.Time
+	for i := 0; i < replicationLagMaxCommits && it.Next(); i++ {
+		commit := it.Value()
+		if commit.CommitID == lastCommitID {
+			break
+		}
+		oldest = commit.CreationDate
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+	if oldest.IsZero() {
+		return 0, nil
+	}
+	return time.Since(oldest), nil
+}
+
+// SetBranchReplication configures continuous replication of a branch to a branch in another repository, and
+// starts replicating its current commit. The target branch is reset to the source branch on each replication, it
+// should not be written to directly.
+func (c *Catalog) SetBranchReplication(ctx context.Context, repositoryID, branchID string, target ReplicationTarget) (*ReplicationStatus, error) {
+	if target.Branch == "" {
+		target.Branch = branchID
+	}
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: graveler.RepositoryID(repositoryID), Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: graveler.BranchID(branchID), Fn: graveler.ValidateBranchID},
+		{Name: "target_repository", Value: graveler.RepositoryID(target.Repository), Fn: graveler.ValidateRepositoryID},
+		{Name: "target_branch", Value: graveler.BranchID(target.Branch), Fn: graveler.ValidateBranchID},
+	}); err != nil {
+		return nil, err
+	}
+	if target.Repository == repositoryID {
+		return nil, fmt.Errorf("target repository must be different from the source repository: %w", graveler.ErrInvalidValue)
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Store.GetBranch(ctx, repository, graveler.BranchID(branchID)); err != nil {
+		return nil, err
+	}
+	if _, err := c.getRepository(ctx, target.Repository); err != nil {
+		return nil, fmt.Errorf("target repository: %w", err)
+	}
+
+	now := timestamppb.Now()
+	data := &ReplicationData{
+		SourceBranch:     branchID,
+		TargetRepository: target.Repository,
+		TargetBranch:     target.Branch,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	err = kv.SetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ReplicationPath(branchID)), data)
+	if err != nil {
+		return nil, err
+	}
+	c.startReplication(repository, graveler.BranchID(branchID))
+	return c.replicationStatus(ctx, repository, data)
+}
+
+// GetBranchReplication returns the replication configuration and status of a branch
+func (c *Catalog) GetBranchReplication(ctx context.Context, repositoryID, branchID string) (*ReplicationStatus, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	data := &ReplicationData{}
+	_, err = kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), []byte(ReplicationPath(branchID)), data)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, graveler.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.replicationStatus(ctx, repository, data)
+}
+
+// DeleteBranchReplication stops replicating a branch. The target repository is kept as-is.
+func (c *Catalog) DeleteBranchReplication(ctx context.Context, repositoryID, branchID string) error {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	partition := graveler.RepoPartition(repository)
+	key := []byte(ReplicationPath(branchID))
+	_, err = kv.GetMsg(ctx, c.KVStore, partition, key, &ReplicationData{})
+	if errors.Is(err, kv.ErrNotFound) {
+		return graveler.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	replicationLag.DeleteLabelValues(repositoryID, branchID)
+	return c.KVStore.Delete(ctx, []byte(partition), key)
+}
+
+func (c *Catalog) replicationStatus(ctx context.Context, repository *graveler.RepositoryRecord, data *ReplicationData) (*ReplicationStatus, error) {
+	var statusErr error
+	if data.Error != "" {
+		statusErr = fmt.Errorf("%w: %s", ErrReplication, data.Error)
+	}
+	status := &ReplicationStatus{
+		SourceBranch:      data.SourceBranch,
+		TargetRepository:  data.TargetRepository,
+		TargetBranch:      data.TargetBranch,
+		CreatedAt:         data.CreatedAt.AsTime(),
+		LastCommitID:      data.LastCommitId,
+		ReplicatedCommits: data.ReplicatedCommits,
+		ReplicatedObjects: data.ReplicatedObjects,
+		UpdatedAt:         data.UpdatedAt.AsTime(),
+		Error:             statusErr,
+	}
+	if data.LastReplicatedAt != nil {
+		status.LastReplicatedAt = data.LastReplicatedAt.AsTime()
+	}
+	branch, err := c.Store.GetBranch(ctx, repository, graveler.BranchID(data.SourceBranch))
+	if err != nil {
+		return nil, err
+	}
+	status.SourceCommitID = branch.CommitID.String()
+	status.Lag, err = c.replicationLag(ctx, repository, branch.CommitID, graveler.CommitID(data.LastCommitId))
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// valueRecordsIterator iterates over sorted value records
+type valueRecordsIterator struct {
+	records []*graveler.ValueRecord
+	idx     int
+}
+
+func (it *valueRecordsIterator) Next() bool {
+	if it.idx >= len(it.records) {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *valueRecordsIterator) SeekGE(id graveler.Key) {
+	it.idx = sort.Search(len(it.records), func(i int) bool {
+		return it.records[i].Key.String() >= id.String()
+	})
+}
+
+func (it *valueRecordsIterator) Value() *graveler.ValueRecord {
+	if it.idx == 0 || it.idx > len(it.records) {
+		return nil
+	}
+	return it.records[it.idx-1]
+}
+
+func (it *valueRecordsIterator) Err() error {
+	return nil
+}
+
+func (it *valueRecordsIterator) Close() {}
@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/committed"
+	"github.com/treeverse/lakefs/pkg/validator"
+)
+
+// rangeRewriter writes existing ranges again in the configured sstable format
+type rangeRewriter interface {
+	Rewrite(ctx context.Context, ns committed.Namespace, id committed.ID) error
+}
+
+// RewriteCommitRanges writes the ranges and the metarange of the commit referenced by reference again, in the
+// configured sstable format. Their IDs are unchanged, so every commit sharing them reads the new format.
+// Returns the number of rewritten ranges.
+func (c *Catalog) RewriteCommitRanges(ctx context.Context, repositoryID string, reference string) (int, error) {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return 0, err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return 0, err
+	}
+	commitID, err := c.dereferenceCommitID(ctx, repository, graveler.Ref(reference))
+	if err != nil {
+		return 0, err
+	}
+	commit, err := c.Store.GetCommit(ctx, repository, commitID)
+	if err != nil {
+		return 0, err
+	}
+	if commit.MetaRangeID == "" {
+		return 0, nil
+	}
+
+	ns := committed.Namespace(repository.StorageNamespace)
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, repository.StorageNamespace, commit.MetaRangeID)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	count := 0
+	for it.NextRange() {
+		_, rng := it.Value()
+		if err := c.rangeRewriter.Rewrite(ctx, ns, rng.ID); err != nil {
+			return count, fmt.Errorf("rewrite range %s: %w", rng.ID, err)
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return count, err
+	}
+	if err := c.metaRangeRewriter.Rewrite(ctx, ns, committed.ID(commit.MetaRangeID)); err != nil {
+		return count, fmt.Errorf("rewrite metarange %s: %w", commit.MetaRangeID, err)
+	}
+	return count, nil
+}
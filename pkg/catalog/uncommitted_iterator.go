@@ -7,13 +7,14 @@ import (
 )
 
 type UncommittedIterator struct {
-	store     Store
-	ctx       context.Context
-	err       error
-	branchItr graveler.BranchIterator
-	entryItr  *valueEntryIterator
-	branch    *graveler.BranchRecord
-	entry     *UncommittedRecord
+	store      Store
+	ctx        context.Context
+	err        error
+	repository *graveler.RepositoryRecord
+	branchItr  graveler.BranchIterator
+	entryItr   *valueEntryIterator
+	branch     *graveler.BranchRecord
+	entry      *UncommittedRecord
 }
 
 type UncommittedRecord struct {
@@ -27,9 +28,10 @@ func NewUncommittedIterator(ctx context.Context, store Store, repository *gravel
 		return nil, err
 	}
 	return &UncommittedIterator{
-		store:     store,
-		ctx:       ctx,
-		branchItr: bItr,
+		store:      store,
+		ctx:        ctx,
+		repository: repository,
+		branchItr:  bItr,
 	}, nil
 }
 
@@ -39,7 +41,7 @@ func (u *UncommittedIterator) nextStaging() bool {
 		u.entryItr.Close()
 	}
 	u.branch = u.branchItr.Value()
-	vItr, err := u.store.ListStaging(u.ctx, u.branch.Branch, 0)
+	vItr, err := u.store.ListStaging(u.ctx, u.repository, u.branch.Branch, 0)
 	if err != nil {
 		u.err = err
 		return false
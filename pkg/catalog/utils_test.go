@@ -0,0 +1,70 @@
+package catalog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/config"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/testutil"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+// newTestCatalog returns a catalog over an in-memory kv store and block adapter. Imports walk the object store using
+// walkerFactory, the default factory when nil.
+func newTestCatalog(t *testing.T, walkerFactory catalog.WalkerFactory) *catalog.Catalog {
+	t.Helper()
+	ctx := context.Background()
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeMem)
+	cfg, err := config.NewConfig("")
+	testutil.MustDo(t, "config", err)
+	c, err := catalog.New(ctx, catalog.Config{
+		Config:        cfg,
+		KVStore:       kvtest.GetStore(ctx, t),
+		WalkerFactory: walkerFactory,
+		PathProvider:  upload.DefaultPathProvider,
+	})
+	testutil.MustDo(t, "build catalog", err)
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+func createTestRepository(t *testing.T, c *catalog.Catalog, repo string) *graveler.RepositoryRecord {
+	t.Helper()
+	ctx := context.Background()
+	_, err := c.CreateRepository(ctx, repo, "mem://"+repo, "main")
+	testutil.MustDo(t, "create repository", err)
+	repository, err := c.Store.GetRepository(ctx, graveler.RepositoryID(repo))
+	testutil.MustDo(t, "get repository", err)
+	return repository
+}
+
+func createTestEntry(t *testing.T, c *catalog.Catalog, repo, branch, path, checksum string) {
+	t.Helper()
+	err := c.CreateEntry(context.Background(), repo, branch, catalog.DBEntry{
+		Path:            path,
+		PhysicalAddress: "address-" + checksum,
+		Checksum:        checksum,
+		Size:            int64(len(checksum)),
+	})
+	testutil.MustDo(t, "create entry "+path, err)
+}
+
+// listChecksums returns the checksum of each path on the reference
+func listChecksums(t *testing.T, c *catalog.Catalog, repo, ref string) map[string]string {
+	t.Helper()
+	entries, _, err := c.ListEntries(context.Background(), repo, ref, "", "", "", -1)
+	require.NoError(t, err)
+	checksums := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		checksums[entry.Path] = entry.Checksum
+	}
+	return checksums
+}
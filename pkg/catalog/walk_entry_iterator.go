@@ -42,7 +42,9 @@ type WalkerFactory interface {
 	GetWalker(ctx context.Context, opts store.WalkerOptions) (*store.WalkerWrapper, error)
 }
 
-func NewWalkEntryIterator(ctx context.Context, walker *store.WalkerWrapper, sourceType ImportPathType, destination, after, continuationToken string) (*walkEntryIterator, error) {
+// NewWalkEntryIterator returns an iterator over the walker's entries placed under destination.
+// An optional filter is applied to each entry's relative key, skipping or remapping it.
+func NewWalkEntryIterator(ctx context.Context, walker *store.WalkerWrapper, sourceType ImportPathType, destination, after, continuationToken string, filter *ImportFilter) (*walkEntryIterator, error) {
 	prepend := destination
 	if prepend != "" && !strings.HasSuffix(prepend, "/") {
 		prepend += "/"
@@ -66,9 +68,15 @@ func NewWalkEntryIterator(ctx context.Context, walker *store.WalkerWrapper, sour
 			if it.closed.Load() {
 				return ErrItClosed
 			}
-			p := prepend + e.RelativeKey
+			var p string
 			if sourceType == ImportPathTypeObject {
 				p = destination
+			} else {
+				key, ok := filter.Apply(e.RelativeKey)
+				if !ok {
+					return nil
+				}
+				p = prepend + key
 			}
 			record := objectStoreEntryToEntryRecord(e, p)
 			it.entries <- EntryWithMarker{
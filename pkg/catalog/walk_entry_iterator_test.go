@@ -41,7 +41,7 @@ func TestWalkEntryIterator(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := testutils.NewFakeWalker(iteratorTestCount, tt.max, uriPrefix, after, continuationToken, fromSourceURIWithPrefix, nil)
 			parsedURL, _ := url.Parse(fromSourceURIWithPrefix)
-			sut, err := catalog.NewWalkEntryIterator(context.Background(), store.NewWrapper(w, parsedURL), catalog.ImportPathTypePrefix, prepend, after, continuationToken)
+			sut, err := catalog.NewWalkEntryIterator(context.Background(), store.NewWrapper(w, parsedURL), catalog.ImportPathTypePrefix, prepend, after, continuationToken, nil)
 			require.NoError(t, err, "creating walk entry iterator")
 			require.NotNil(t, sut)
 
@@ -110,7 +110,56 @@ type Config struct {
 		Enabled bool `mapstructure:"enabled"`
 		Lua     struct {
 			NetHTTPEnabled bool `mapstructure:"net_http_enabled"`
+			// StoragePrefix is the prefix of the storage namespaces that the lakefs/storage Lua module accesses.
+			// The module is disabled when empty.
+			StoragePrefix string `mapstructure:"storage_prefix"`
 		} `mapstructure:"lua"`
+		PostHooks struct {
+			// PollInterval is the interval to check for post hooks runs due for a retry
+			PollInterval time.Duration `mapstructure:"poll_interval"`
+			// Retry is the default retry policy of post hooks, used when not set on the hook
+			Retry struct {
+				Attempts   int           `mapstructure:"attempts"`
+				Backoff    time.Duration `mapstructure:"backoff"`
+				MaxBackoff time.Duration `mapstructure:"max_backoff"`
+			} `mapstructure:"retry"`
+		} `mapstructure:"post_hooks"`
+		ObjectHooks struct {
+			// Prefixes are the object key prefixes on which pre-put-object and pre-delete-object hooks run.
+			// Object hooks don't run when empty.
+			Prefixes []string `mapstructure:"prefixes"`
+		} `mapstructure:"object_hooks"`
+		Exec struct {
+			// Commands are the local commands that exec hooks may run, by name. Exec hooks are disabled when empty.
+			Commands map[string]struct {
+				Path string   `mapstructure:"path"`
+				Args []string `mapstructure:"args"`
+				// Env is the environment of the command, the lakeFS environment is not passed to it
+				Env []string `mapstructure:"env"`
+				Dir string   `mapstructure:"dir"`
+				// AllowArgs lets actions append arguments to the command
+				AllowArgs bool `mapstructure:"allow_args"`
+			} `mapstructure:"commands"`
+			// Timeout is the maximal run time of an exec hook
+			Timeout time.Duration `mapstructure:"timeout"`
+			// MaxOutputSize is the maximal number of bytes captured from each of stdout and stderr of an exec hook
+			MaxOutputSize int `mapstructure:"max_output_size"`
+			// Limits are the resource limits of exec hook commands, zero values are not limited
+			Limits struct {
+				// CPUTime is the maximal CPU time of the command, rounded up to whole seconds
+				CPUTime time.Duration `mapstructure:"cpu_time"`
+				// Memory is the maximal address space size of the command, in bytes
+				Memory int64 `mapstructure:"memory"`
+				// OpenFiles is the maximal number of files the command can open
+				OpenFiles int `mapstructure:"open_files"`
+			} `mapstructure:"limits"`
+		} `mapstructure:"exec"`
+		Global struct {
+			// Paths are action files, or directories of action files, run on every repository
+			Paths []string `mapstructure:"paths"`
+			// Enforced prevents repository actions from overriding the global actions of the configuration
+			Enforced bool `mapstructure:"enforced"`
+		} `mapstructure:"global"`
 	}
 
 	Logging struct {
@@ -129,6 +178,9 @@ type Config struct {
 		DropTables bool `mapstructure:"drop_tables"`
 		// Type Name of the KV Store driver DB implementation which is available according to the kv package Drivers function
 		Type string `mapstructure:"type" validate:"required"`
+		// MigrateTo - lakeFS configuration file of a database to migrate to. Writes are mirrored to it until the
+		// migration switches to it.
+		MigrateTo string `mapstructure:"migrate_to"`
 
 		Local *struct {
 			// Path - Local directory path to store the DB files
@@ -148,6 +200,8 @@ type Config struct {
 			ConnectionMaxLifetime time.Duration `mapstructure:"connection_max_lifetime"`
 			ScanPageSize          int           `mapstructure:"scan_page_size"`
 			Metrics               bool          `mapstructure:"metrics"`
+			NotifyChanges         bool          `mapstructure:"notify_changes"`
+			NotifyPartitions      []string      `mapstructure:"notify_partitions"`
 		}
 
 		DynamoDB *struct {
@@ -184,6 +238,17 @@ type Config struct {
 			Database  string       `mapstructure:"database"`
 			Container string       `mapstructure:"container"`
 		} `mapstructure:"cosmosdb"`
+
+		Bolt *struct {
+			// Path - Local file path of the database
+			Path string `mapstructure:"path"`
+			// SyncWrites - Sync the database file on each transaction commit
+			SyncWrites bool `mapstructure:"sync_writes"`
+			// ScanBatchSize - Number of entries read by each read transaction while iterating
+			ScanBatchSize int `mapstructure:"scan_batch_size"`
+			// EnableLogging - Enable trace logging for the bolt driver
+			EnableLogging bool `mapstructure:"enable_logging"`
+		} `mapstructure:"bolt"`
 	}
 
 	Auth struct {
@@ -276,6 +341,23 @@ type Config struct {
 			DisablePreSigned   bool          `mapstructure:"disable_pre_signed"`
 			DisablePreSignedUI bool          `mapstructure:"disable_pre_signed_ui"`
 		} `mapstructure:"gs"`
+		SFTP *struct {
+			Host                  string       `mapstructure:"host"`
+			User                  string       `mapstructure:"user"`
+			Password              SecureString `mapstructure:"password"`
+			PrivateKeyFile        string       `mapstructure:"private_key_file"`
+			KnownHostsFile        string       `mapstructure:"known_hosts_file"`
+			InsecureIgnoreHostKey bool         `mapstructure:"insecure_ignore_host_key"`
+			Path                  string       `mapstructure:"path"`
+			ImportEnabled         bool         `mapstructure:"import_enabled"`
+			ImportChecksums       bool         `mapstructure:"import_checksums"`
+		} `mapstructure:"sftp"`
+		WebDAV *struct {
+			Endpoint      string       `mapstructure:"endpoint"`
+			User          string       `mapstructure:"user"`
+			Password      SecureString `mapstructure:"password"`
+			ImportEnabled bool         `mapstructure:"import_enabled"`
+		} `mapstructure:"webdav"`
 	} `mapstructure:"blockstore"`
 	Committed struct {
 		LocalCache struct {
@@ -285,16 +367,38 @@ type Config struct {
 			RangeProportion       float64 `mapstructure:"range_proportion"`
 			MetaRangeProportion   float64 `mapstructure:"metarange_proportion"`
 		} `mapstructure:"local_cache"`
+		RemoteCache struct {
+			Type               string        `mapstructure:"type"`
+			MaxObjectSizeBytes int64         `mapstructure:"max_object_size_bytes"`
+			AdmissionWorkers   int           `mapstructure:"admission_workers"`
+			AdmissionQueueSize int           `mapstructure:"admission_queue_size"`
+			Expiry             time.Duration `mapstructure:"expiry"`
+			Redis              struct {
+				Endpoint  string       `mapstructure:"endpoint"`
+				Username  string       `mapstructure:"username"`
+				Password  SecureString `mapstructure:"password"`
+				DB        int          `mapstructure:"db"`
+				KeyPrefix string       `mapstructure:"key_prefix"`
+			} `mapstructure:"redis"`
+		} `mapstructure:"remote_cache"`
 		BlockStoragePrefix string `mapstructure:"block_storage_prefix"`
 		Permanent          struct {
 			MinRangeSizeBytes      uint64  `mapstructure:"min_range_size_bytes"`
 			MaxRangeSizeBytes      uint64  `mapstructure:"max_range_size_bytes"`
 			RangeRaggednessEntries float64 `mapstructure:"range_raggedness_entries"`
 		} `mapstructure:"permanent"`
+		Diff struct {
+			Parallelism        int `mapstructure:"parallelism"`
+			RangesPerPartition int `mapstructure:"ranges_per_partition"`
+		} `mapstructure:"diff"`
 		SSTable struct {
 			Memory struct {
 				CacheSizeBytes int64 `mapstructure:"cache_size_bytes"`
 			} `mapstructure:"memory"`
+			Compression          string `mapstructure:"compression"`
+			BlockSizeBytes       int    `mapstructure:"block_size_bytes"`
+			BlockRestartInterval int    `mapstructure:"block_restart_interval"`
+			FilterCacheSize      int    `mapstructure:"filter_cache_size"`
 		} `mapstructure:"sstable"`
 	} `mapstructure:"committed"`
 	UGC struct {
@@ -316,6 +420,10 @@ type Config struct {
 		Background struct {
 			RateLimit int `mapstructure:"rate_limit"`
 		} `mapstructure:"background"`
+		Compaction struct {
+			Enabled               bool `mapstructure:"enabled"`
+			StagedWritesThreshold int  `mapstructure:"staged_writes_threshold"`
+		} `mapstructure:"compaction"`
 	} `mapstructure:"graveler"`
 	Gateways struct {
 		S3 struct {
@@ -384,7 +492,7 @@ func newConfig(cfgType string) (*Config, error) {
 	for _, key := range keys {
 		viper.SetDefault(key, nil)
 	}
-	setDefaults(cfgType)
+	setDefaults(viper.GetViper(), cfgType)
 
 	err := Unmarshal(c)
 	if err != nil {
@@ -403,8 +511,32 @@ func newConfig(cfgType string) (*Config, error) {
 	return c, nil
 }
 
+// NewConfigFromFile reads the configuration from the file, without affecting the global configuration. Environment
+// variables are not read, as they would apply to every configuration read.
+func NewConfigFromFile(filename string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	c := &Config{}
+	keys := GetStructKeys(reflect.TypeOf(c), "mapstructure", "squash")
+	for _, key := range keys {
+		v.SetDefault(key, nil)
+	}
+	setDefaults(v, "")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", filename, err)
+	}
+	if err := unmarshal(v, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func Unmarshal(c *Config) error {
-	return viper.UnmarshalExact(&c,
+	return unmarshal(viper.GetViper(), c)
+}
+
+func unmarshal(v *viper.Viper, c *Config) error {
+	return v.UnmarshalExact(&c,
 		viper.DecodeHook(
 			mapstructure.ComposeDecodeHookFunc(
 				DecodeStrings, mapstructure.StringToTimeDurationHookFunc())))
@@ -557,6 +689,49 @@ func (c *Config) BlockstoreAzureParams() (blockparams.Azure, error) {
 	}, nil
 }
 
+const defaultKnownHostsFile = "~/.ssh/known_hosts"
+
+func (c *Config) BlockstoreSFTPParams() (blockparams.SFTP, error) {
+	if c.Blockstore.SFTP == nil {
+		return blockparams.SFTP{}, fmt.Errorf("%w: blockstore.sftp", ErrMissingRequiredKeys)
+	}
+	privateKeyFile, err := homedir.Expand(c.Blockstore.SFTP.PrivateKeyFile)
+	if err != nil {
+		return blockparams.SFTP{}, fmt.Errorf("parse SFTP private key path '%s': %w", c.Blockstore.SFTP.PrivateKeyFile, err)
+	}
+	knownHostsFile := c.Blockstore.SFTP.KnownHostsFile
+	if knownHostsFile == "" && !c.Blockstore.SFTP.InsecureIgnoreHostKey {
+		knownHostsFile = defaultKnownHostsFile
+	}
+	knownHostsFile, err = homedir.Expand(knownHostsFile)
+	if err != nil {
+		return blockparams.SFTP{}, fmt.Errorf("parse SFTP known hosts path '%s': %w", c.Blockstore.SFTP.KnownHostsFile, err)
+	}
+	return blockparams.SFTP{
+		Host:                  c.Blockstore.SFTP.Host,
+		User:                  c.Blockstore.SFTP.User,
+		Password:              c.Blockstore.SFTP.Password.SecureValue(),
+		PrivateKeyFile:        privateKeyFile,
+		KnownHostsFile:        knownHostsFile,
+		InsecureIgnoreHostKey: c.Blockstore.SFTP.InsecureIgnoreHostKey,
+		Path:                  c.Blockstore.SFTP.Path,
+		ImportEnabled:         c.Blockstore.SFTP.ImportEnabled,
+		ImportChecksums:       c.Blockstore.SFTP.ImportChecksums,
+	}, nil
+}
+
+func (c *Config) BlockstoreWebDAVParams() (blockparams.WebDAV, error) {
+	if c.Blockstore.WebDAV == nil {
+		return blockparams.WebDAV{}, fmt.Errorf("%w: blockstore.webdav", ErrMissingRequiredKeys)
+	}
+	return blockparams.WebDAV{
+		Endpoint:      c.Blockstore.WebDAV.Endpoint,
+		User:          c.Blockstore.WebDAV.User,
+		Password:      c.Blockstore.WebDAV.Password.SecureValue(),
+		ImportEnabled: c.Blockstore.WebDAV.ImportEnabled,
+	}, nil
+}
+
 const (
 	AuthRBACSimplified = "simplified"
 	AuthRBACExternal   = "external"
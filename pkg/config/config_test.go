@@ -73,6 +73,22 @@ func TestConfig_NewFromFile(t *testing.T) {
 	})
 }
 
+func TestConfig_NewConfigFromFile(t *testing.T) {
+	c, err := config.NewConfigFromFile("testdata/valid_config.yaml")
+	testutil.Must(t, err)
+	if c.ListenAddress != "0.0.0.0:8005" {
+		t.Fatalf("expected listen addr 0.0.0.0:8005, got %s", c.ListenAddress)
+	}
+	if c.Database.Local == nil || c.Database.Local.Path != "~/lakefs/metadata" {
+		t.Fatalf("expected default database local path, got %+v", c.Database.Local)
+	}
+
+	_, err = config.NewConfigFromFile("testdata/valid_configgggggggggggggggg.yaml")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected missing configuration file to fail, got %v", err)
+	}
+}
+
 func pushEnv(key, value string) func() {
 	oldValue := os.Getenv(key)
 	_ = os.Setenv(key, value)
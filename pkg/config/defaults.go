@@ -17,120 +17,146 @@ const (
 )
 
 //nolint:gomnd
-func setDefaults(cfgType string) {
+func setDefaults(v *viper.Viper, cfgType string) {
 	switch cfgType {
 	case QuickstartConfiguration:
-		viper.SetDefault("installation.user_name", DefaultQuickstartUsername)
-		viper.SetDefault("installation.access_key_id", DefaultQuickstartKeyID)
-		viper.SetDefault("installation.secret_access_key", DefaultQuickstartSecretKey)
-		viper.SetDefault("database.type", "local")
-		viper.SetDefault("auth.encrypt.secret_key", "THIS_MUST_BE_CHANGED_IN_PRODUCTION") // #nosec
-		viper.SetDefault(BlockstoreTypeKey, "local")
+		v.SetDefault("installation.user_name", DefaultQuickstartUsername)
+		v.SetDefault("installation.access_key_id", DefaultQuickstartKeyID)
+		v.SetDefault("installation.secret_access_key", DefaultQuickstartSecretKey)
+		v.SetDefault("database.type", "local")
+		v.SetDefault("auth.encrypt.secret_key", "THIS_MUST_BE_CHANGED_IN_PRODUCTION") // #nosec
+		v.SetDefault(BlockstoreTypeKey, "local")
 	case UseLocalConfiguration:
-		viper.SetDefault("database.type", "local")
-		viper.SetDefault("auth.encrypt.secret_key", "THIS_MUST_BE_CHANGED_IN_PRODUCTION") // #nosec
-		viper.SetDefault(BlockstoreTypeKey, "local")
+		v.SetDefault("database.type", "local")
+		v.SetDefault("auth.encrypt.secret_key", "THIS_MUST_BE_CHANGED_IN_PRODUCTION") // #nosec
+		v.SetDefault(BlockstoreTypeKey, "local")
 	}
 
-	viper.SetDefault("listen_address", DefaultListenAddress)
-
-	viper.SetDefault("logging.format", "text")
-	viper.SetDefault("logging.level", DefaultLoggingLevel)
-	viper.SetDefault("logging.output", "-")
-
-	viper.SetDefault("logging.files_keep", 100)
-	viper.SetDefault("logging.audit_log_level", DefaultLoggingAuditLogLevel)
-
-	viper.SetDefault("logging.file_max_size_mb", (1<<10)*100) // 100MiB
-
-	viper.SetDefault("actions.enabled", true)
-
-	viper.SetDefault("auth.cache.enabled", true)
-	viper.SetDefault("auth.cache.size", 1024)
-	viper.SetDefault("auth.cache.ttl", 20*time.Second)
-	viper.SetDefault("auth.cache.jitter", 3*time.Second)
-
-	viper.SetDefault("auth.logout_redirect_url", "/auth/login")
-	viper.SetDefault("auth.login_duration", 7*24*time.Hour)
-
-	viper.SetDefault("auth.ui_config.rbac", "simplified")
-	viper.SetDefault("auth.ui_config.login_failed_message", "The credentials don't match.")
-	viper.SetDefault("auth.ui_config.login_cookie_names", "internal_auth_session")
-
-	viper.SetDefault("auth.remote_authenticator.default_user_group", "Viewers")
-	viper.SetDefault("auth.remote_authenticator.request_timeout", 10*time.Second)
-
-	viper.SetDefault("blockstore.local.path", "~/lakefs/data/block")
-	viper.SetDefault("blockstore.s3.region", "us-east-1")
-	viper.SetDefault("blockstore.s3.streaming_chunk_size", 2<<19)          // 1MiB by default per chunk
-	viper.SetDefault("blockstore.s3.streaming_chunk_timeout", time.Second) // or 1 seconds, whatever comes first
-	viper.SetDefault("blockstore.s3.max_retries", 5)
-	viper.SetDefault("blockstore.s3.discover_bucket_region", true)
-	viper.SetDefault("blockstore.s3.pre_signed_expiry", 15*time.Minute)
-	viper.SetDefault("blockstore.s3.web_identity.session_expiry_window", 5*time.Minute)
-	viper.SetDefault("blockstore.s3.disable_pre_signed_ui", true)
-
-	viper.SetDefault("committed.local_cache.size_bytes", 1*1024*1024*1024)
-	viper.SetDefault("committed.local_cache.dir", "~/lakefs/data/cache")
-	viper.SetDefault("committed.local_cache.max_uploaders_per_writer", 10)
-	viper.SetDefault("committed.local_cache.range_proportion", 0.9)
-	viper.SetDefault("committed.local_cache.metarange_proportion", 0.1)
-
-	viper.SetDefault("committed.block_storage_prefix", "_lakefs")
-	viper.SetDefault("committed.permanent.min_range_size_bytes", 0)
-	viper.SetDefault("committed.permanent.max_range_size_bytes", 20*1024*1024)
-	viper.SetDefault("committed.permanent.range_raggedness_entries", 50_000)
-	viper.SetDefault("committed.sstable.memory.cache_size_bytes", 400_000_000)
-
-	viper.SetDefault("gateways.s3.domain_name", "s3.local.lakefs.io")
-	viper.SetDefault("gateways.s3.region", "us-east-1")
-
-	viper.SetDefault("blockstore.gs.s3_endpoint", "https://storage.googleapis.com")
-	viper.SetDefault("blockstore.gs.pre_signed_expiry", 15*time.Minute)
-	viper.SetDefault("blockstore.gs.disable_pre_signed_ui", true)
-
-	viper.SetDefault("stats.enabled", true)
-	viper.SetDefault("stats.address", "https://stats.lakefs.io")
-	viper.SetDefault("stats.flush_interval", 30*time.Second)
-	viper.SetDefault("stats.flush_size", 100)
-
-	viper.SetDefault("email_subscription.enabled", true)
-
-	viper.SetDefault("blockstore.azure.try_timeout", 10*time.Minute)
-	viper.SetDefault("blockstore.azure.pre_signed_expiry", 15*time.Minute)
-	viper.SetDefault("blockstore.azure.disable_pre_signed_ui", true)
-
-	viper.SetDefault("security.audit_check_interval", 24*time.Hour)
-	viper.SetDefault("security.audit_check_url", "https://audit.lakefs.io/audit")
-	viper.SetDefault("security.check_latest_version", true)
-	viper.SetDefault("security.check_latest_version_cache", 24*time.Hour)
-
-	viper.SetDefault("email.limit_every_duration", time.Minute)
-	viper.SetDefault("email.burst", 10)
-	viper.SetDefault("email.lakefs_base_url", "http://localhost:8000")
-
-	viper.SetDefault("ui.enabled", true)
-
-	viper.SetDefault("database.local.path", "~/lakefs/metadata")
-	viper.SetDefault("database.local.prefetch_size", 256)
-	viper.SetDefault("database.local.sync_writes", true)
-
-	viper.SetDefault("database.dynamodb.table_name", "kvstore")
-	viper.SetDefault("database.dynamodb.scan_limit", 1024)
-
-	viper.SetDefault("database.postgres.max_open_connections", 25)
-	viper.SetDefault("database.postgres.max_idle_connections", 25)
-	viper.SetDefault("database.postgres.connection_max_lifetime", "5m")
-
-	viper.SetDefault("graveler.repository_cache.size", 1000)
-	viper.SetDefault("graveler.repository_cache.expiry", 5*time.Second)
-	viper.SetDefault("graveler.repository_cache.jitter", 2*time.Second)
-	viper.SetDefault("graveler.commit_cache.size", 50_000)
-	viper.SetDefault("graveler.commit_cache.expiry", 10*time.Minute)
-	viper.SetDefault("graveler.commit_cache.jitter", 2*time.Second)
-
-	viper.SetDefault("plugins.default_path", "~/.lakefs/plugins")
-
-	viper.SetDefault("ugc.prepare_interval", time.Minute)
-	viper.SetDefault("ugc.prepare_max_file_size", 20*1024*1024)
+	v.SetDefault("listen_address", DefaultListenAddress)
+
+	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.level", DefaultLoggingLevel)
+	v.SetDefault("logging.output", "-")
+
+	v.SetDefault("logging.files_keep", 100)
+	v.SetDefault("logging.audit_log_level", DefaultLoggingAuditLogLevel)
+
+	v.SetDefault("logging.file_max_size_mb", (1<<10)*100) // 100MiB
+
+	v.SetDefault("actions.enabled", true)
+	v.SetDefault("actions.lua.storage_prefix", "_hooks")
+	v.SetDefault("actions.post_hooks.poll_interval", 10*time.Second)
+	v.SetDefault("actions.post_hooks.retry.attempts", 1)
+	v.SetDefault("actions.post_hooks.retry.backoff", 10*time.Second)
+	v.SetDefault("actions.post_hooks.retry.max_backoff", 10*time.Minute)
+	v.SetDefault("actions.exec.timeout", 1*time.Minute)
+	v.SetDefault("actions.exec.max_output_size", 1024*1024)
+
+	v.SetDefault("auth.cache.enabled", true)
+	v.SetDefault("auth.cache.size", 1024)
+	v.SetDefault("auth.cache.ttl", 20*time.Second)
+	v.SetDefault("auth.cache.jitter", 3*time.Second)
+
+	v.SetDefault("auth.logout_redirect_url", "/auth/login")
+	v.SetDefault("auth.login_duration", 7*24*time.Hour)
+
+	v.SetDefault("auth.ui_config.rbac", "simplified")
+	v.SetDefault("auth.ui_config.login_failed_message", "The credentials don't match.")
+	v.SetDefault("auth.ui_config.login_cookie_names", "internal_auth_session")
+
+	v.SetDefault("auth.remote_authenticator.default_user_group", "Viewers")
+	v.SetDefault("auth.remote_authenticator.request_timeout", 10*time.Second)
+
+	v.SetDefault("blockstore.local.path", "~/lakefs/data/block")
+	v.SetDefault("blockstore.s3.region", "us-east-1")
+	v.SetDefault("blockstore.s3.streaming_chunk_size", 2<<19)          // 1MiB by default per chunk
+	v.SetDefault("blockstore.s3.streaming_chunk_timeout", time.Second) // or 1 seconds, whatever comes first
+	v.SetDefault("blockstore.s3.max_retries", 5)
+	v.SetDefault("blockstore.s3.discover_bucket_region", true)
+	v.SetDefault("blockstore.s3.pre_signed_expiry", 15*time.Minute)
+	v.SetDefault("blockstore.s3.web_identity.session_expiry_window", 5*time.Minute)
+	v.SetDefault("blockstore.s3.disable_pre_signed_ui", true)
+
+	v.SetDefault("committed.local_cache.size_bytes", 1*1024*1024*1024)
+	v.SetDefault("committed.local_cache.dir", "~/lakefs/data/cache")
+	v.SetDefault("committed.local_cache.max_uploaders_per_writer", 10)
+	v.SetDefault("committed.local_cache.range_proportion", 0.9)
+	v.SetDefault("committed.local_cache.metarange_proportion", 0.1)
+
+	v.SetDefault("committed.remote_cache.max_object_size_bytes", 32*1024*1024)
+	v.SetDefault("committed.remote_cache.admission_workers", 4)
+	v.SetDefault("committed.remote_cache.admission_queue_size", 64)
+	v.SetDefault("committed.remote_cache.expiry", 7*24*time.Hour)
+	v.SetDefault("committed.remote_cache.redis.key_prefix", "lakefs:committed:")
+
+	v.SetDefault("committed.block_storage_prefix", "_lakefs")
+	v.SetDefault("committed.permanent.min_range_size_bytes", 0)
+	v.SetDefault("committed.permanent.max_range_size_bytes", 20*1024*1024)
+	v.SetDefault("committed.permanent.range_raggedness_entries", 50_000)
+	v.SetDefault("committed.diff.parallelism", 8)
+	v.SetDefault("committed.diff.ranges_per_partition", 8)
+	v.SetDefault("committed.sstable.memory.cache_size_bytes", 400_000_000)
+	v.SetDefault("committed.sstable.compression", "snappy")
+	v.SetDefault("committed.sstable.block_size_bytes", 4096)
+	v.SetDefault("committed.sstable.block_restart_interval", 16)
+	v.SetDefault("committed.sstable.filter_cache_size", 1000)
+
+	v.SetDefault("gateways.s3.domain_name", "s3.local.lakefs.io")
+	v.SetDefault("gateways.s3.region", "us-east-1")
+
+	v.SetDefault("blockstore.gs.s3_endpoint", "https://storage.googleapis.com")
+	v.SetDefault("blockstore.gs.pre_signed_expiry", 15*time.Minute)
+	v.SetDefault("blockstore.gs.disable_pre_signed_ui", true)
+
+	v.SetDefault("stats.enabled", true)
+	v.SetDefault("stats.address", "https://stats.lakefs.io")
+	v.SetDefault("stats.flush_interval", 30*time.Second)
+	v.SetDefault("stats.flush_size", 100)
+
+	v.SetDefault("email_subscription.enabled", true)
+
+	v.SetDefault("blockstore.azure.try_timeout", 10*time.Minute)
+	v.SetDefault("blockstore.azure.pre_signed_expiry", 15*time.Minute)
+	v.SetDefault("blockstore.azure.disable_pre_signed_ui", true)
+
+	v.SetDefault("security.audit_check_interval", 24*time.Hour)
+	v.SetDefault("security.audit_check_url", "https://audit.lakefs.io/audit")
+	v.SetDefault("security.check_latest_version", true)
+	v.SetDefault("security.check_latest_version_cache", 24*time.Hour)
+
+	v.SetDefault("email.limit_every_duration", time.Minute)
+	v.SetDefault("email.burst", 10)
+	v.SetDefault("email.lakefs_base_url", "http://localhost:8000")
+
+	v.SetDefault("ui.enabled", true)
+
+	v.SetDefault("database.local.path", "~/lakefs/metadata")
+	v.SetDefault("database.local.prefetch_size", 256)
+	v.SetDefault("database.local.sync_writes", true)
+
+	v.SetDefault("database.bolt.path", "~/lakefs/metadata.db")
+	v.SetDefault("database.bolt.sync_writes", true)
+	v.SetDefault("database.bolt.scan_batch_size", 1000)
+
+	v.SetDefault("database.dynamodb.table_name", "kvstore")
+	v.SetDefault("database.dynamodb.scan_limit", 1024)
+
+	v.SetDefault("database.postgres.max_open_connections", 25)
+	v.SetDefault("database.postgres.max_idle_connections", 25)
+	v.SetDefault("database.postgres.connection_max_lifetime", "5m")
+	// partitions watched by lakeFS: auth and the repositories
+	v.SetDefault("database.postgres.notify_partitions", []string{"auth", "graveler"})
+
+	v.SetDefault("graveler.repository_cache.size", 1000)
+	v.SetDefault("graveler.repository_cache.expiry", 5*time.Second)
+	v.SetDefault("graveler.repository_cache.jitter", 2*time.Second)
+	v.SetDefault("graveler.commit_cache.size", 50_000)
+	v.SetDefault("graveler.commit_cache.expiry", 10*time.Minute)
+	v.SetDefault("graveler.commit_cache.jitter", 2*time.Second)
+	v.SetDefault("graveler.compaction.staged_writes_threshold", 100_000)
+
+	v.SetDefault("plugins.default_path", "~/.lakefs/plugins")
+
+	v.SetDefault("ugc.prepare_interval", time.Minute)
+	v.SetDefault("ugc.prepare_max_file_size", 20*1024*1024)
 }
@@ -0,0 +1,79 @@
+package branch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/settings"
+	"google.golang.org/protobuf/proto"
+)
+
+const CompactionSettingKey = "staging_compaction"
+
+var ErrCompactionSettingNotExists = fmt.Errorf("branch compaction setting does not exist: %w", graveler.ErrNotFound)
+
+// CompactionSettingsManager manages the per-branch overrides of the staged writes threshold that triggers a compaction
+// of the branch staging area.
+type CompactionSettingsManager struct {
+	settingManager *settings.Manager
+}
+
+func NewCompactionSettingsManager(settingManager *settings.Manager) *CompactionSettingsManager {
+	return &CompactionSettingsManager{settingManager: settingManager}
+}
+
+// Get returns the staged writes threshold of the branch, and false when the branch uses the default threshold
+func (m *CompactionSettingsManager) Get(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (int64, bool, error) {
+	compactionSettings, err := m.settingManager.GetLatest(ctx, repository, CompactionSettingKey, &graveler.StagingCompactionSettings{})
+	if errors.Is(err, graveler.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	threshold, ok := compactionSettings.(*graveler.StagingCompactionSettings).BranchStagedWritesThresholds[branchID.String()]
+	return threshold, ok, nil
+}
+
+// Set overrides the staged writes threshold of the branch, zero disables compaction of the branch
+func (m *CompactionSettingsManager) Set(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, threshold int64) error {
+	if threshold < 0 {
+		return fmt.Errorf("staged writes threshold %d: %w", threshold, graveler.ErrInvalidValue)
+	}
+	return m.settingManager.Update(ctx, repository, CompactionSettingKey, &graveler.StagingCompactionSettings{}, func(message proto.Message) (proto.Message, error) {
+		compactionSettings := message.(*graveler.StagingCompactionSettings)
+		if compactionSettings.BranchStagedWritesThresholds == nil {
+			compactionSettings.BranchStagedWritesThresholds = make(map[string]int64)
+		}
+		compactionSettings.BranchStagedWritesThresholds[branchID.String()] = threshold
+		return compactionSettings, nil
+	})
+}
+
+// Delete removes the override of the branch, so it uses the default threshold
+func (m *CompactionSettingsManager) Delete(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) error {
+	return m.settingManager.Update(ctx, repository, CompactionSettingKey, &graveler.StagingCompactionSettings{}, func(message proto.Message) (proto.Message, error) {
+		compactionSettings := message.(*graveler.StagingCompactionSettings)
+		if _, ok := compactionSettings.BranchStagedWritesThresholds[branchID.String()]; !ok {
+			return nil, ErrCompactionSettingNotExists
+		}
+		delete(compactionSettings.BranchStagedWritesThresholds, branchID.String())
+		return compactionSettings, nil
+	})
+}
+
+// StagedWritesThreshold returns the staged writes threshold of the branch from the cached settings, and false when the
+// branch uses the default threshold. Called on every staged write.
+func (m *CompactionSettingsManager) StagedWritesThreshold(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (int, bool, error) {
+	compactionSettings, err := m.settingManager.Get(ctx, repository, CompactionSettingKey, &graveler.StagingCompactionSettings{})
+	if errors.Is(err, graveler.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	threshold, ok := compactionSettings.(*graveler.StagingCompactionSettings).BranchStagedWritesThresholds[branchID.String()]
+	return int(threshold), ok, nil
+}
@@ -0,0 +1,50 @@
+package branch_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/branch"
+	"github.com/treeverse/lakefs/pkg/graveler/mock"
+	"github.com/treeverse/lakefs/pkg/graveler/settings"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+)
+
+func TestCompactionSettings(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	refManager := mock.NewMockRefManager(ctrl)
+	refManager.EXPECT().GetRepository(ctx, gomock.Any()).AnyTimes().Return(repository, nil)
+	m := branch.NewCompactionSettingsManager(settings.NewManager(refManager, kvtest.GetStore(ctx, t)))
+
+	_, ok, err := m.Get(ctx, repository, "main")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = m.StagedWritesThreshold(ctx, repository, "main")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, m.Set(ctx, repository, "main", 10))
+	require.NoError(t, m.Set(ctx, repository, "disabled", 0))
+	require.ErrorIs(t, m.Set(ctx, repository, "main", -1), graveler.ErrInvalidValue)
+	threshold, ok, err := m.Get(ctx, repository, "main")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(10), threshold)
+	threshold, ok, err = m.Get(ctx, repository, "disabled")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Zero(t, threshold)
+	_, ok, err = m.Get(ctx, repository, "other")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, m.Delete(ctx, repository, "main"))
+	_, ok, err = m.Get(ctx, repository, "main")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.ErrorIs(t, m.Delete(ctx, repository, "main"), graveler.ErrNotFound)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/logging"
+	"golang.org/x/sync/errgroup"
 )
 
 type committedManager struct {
@@ -30,26 +31,18 @@ func (c *committedManager) Exists(ctx context.Context, ns graveler.StorageNamesp
 }
 
 func (c *committedManager) Get(ctx context.Context, ns graveler.StorageNamespace, rangeID graveler.MetaRangeID, key graveler.Key) (*graveler.Value, error) {
-	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, rangeID)
-	if err != nil {
-		return nil, err
-	}
-	valIt := NewValueIterator(it)
-	defer valIt.Close()
-	valIt.SeekGE(key)
-	// return the next value
-	if !valIt.Next() {
-		// error or not found
-		if err := valIt.Err(); err != nil {
-			return nil, err
-		}
+	if rangeID == "" {
 		return nil, graveler.ErrNotFound
 	}
-	// compare the key we found
-	rec := valIt.Value()
-	if !bytes.Equal(rec.Key, key) {
+	// a point lookup reads only the range that may hold key, and checks the filter of the
+	// range before fetching it
+	rec, err := c.metaRangeManager.GetValue(ctx, ns, rangeID, key)
+	if errors.Is(err, ErrNotFound) {
 		return nil, graveler.ErrNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
 	return rec.Value, nil
 }
 
@@ -167,15 +160,72 @@ func (c *committedManager) WriteMetaRangeByIterator(ctx context.Context, ns grav
 }
 
 func (c *committedManager) Diff(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID) (graveler.DiffIterator, error) {
-	leftIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, left)
+	newIterators := func(ctx context.Context) (Iterator, Iterator, error) {
+		leftIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, left)
+		if err != nil {
+			return nil, nil, err
+		}
+		rightIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, right)
+		if err != nil {
+			leftIt.Close()
+			return nil, nil, err
+		}
+		return leftIt, rightIt, nil
+	}
+	newSequential := func(ctx context.Context) (graveler.DiffIterator, error) {
+		leftIt, rightIt, err := newIterators(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewDiffValueIterator(ctx, leftIt, rightIt), nil
+	}
+	if c.params.DiffParallelism <= 1 || left == right {
+		return newSequential(ctx)
+	}
+	// the metaranges are partitioned only once the diff is large enough to continue in parallel
+	newParallel := func(ctx context.Context) (graveler.DiffIterator, error) {
+		starts, err := c.diffPartitionStarts(ctx, ns, left, right)
+		if err != nil || len(starts) <= 1 {
+			return nil, err
+		}
+		return NewParallelDiffIterator(ctx, newIterators, starts, c.params.DiffParallelism), nil
+	}
+	sequentialValues := c.params.DiffSequentialValues
+	if sequentialValues <= 0 {
+		sequentialValues = DefaultDiffSequentialValues
+	}
+	return NewAdaptiveDiffIterator(ctx, newSequential, newParallel, sequentialValues)
+}
+
+// diffPartitionStarts returns the start keys of the partitions of a parallel diff of left and right
+func (c *committedManager) diffPartitionStarts(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID) ([]graveler.Key, error) {
+	leftRanges, err := c.listRanges(ctx, ns, left)
 	if err != nil {
 		return nil, err
 	}
-	rightIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, right)
+	rightRanges, err := c.listRanges(ctx, ns, right)
 	if err != nil {
 		return nil, err
 	}
-	return NewDiffValueIterator(ctx, leftIt, rightIt), nil
+	return DiffPartitionStarts(leftRanges, rightRanges, c.params.DiffRangesPerPartition), nil
+}
+
+// listRanges returns the ranges of metarange id, without reading them
+func (c *committedManager) listRanges(ctx context.Context, ns graveler.StorageNamespace, id graveler.MetaRangeID) ([]*Range, error) {
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var ranges []*Range
+	for it.NextRange() {
+		_, rng := it.Value()
+		ranges = append(ranges, rng.Copy())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return ranges, nil
 }
 
 func (c *committedManager) Import(ctx context.Context, ns graveler.StorageNamespace, destination, source graveler.MetaRangeID, prefixes []graveler.Prefix) (graveler.MetaRangeID, error) {
@@ -205,6 +255,11 @@ func (c *committedManager) Merge(ctx context.Context, ns graveler.StorageNamespa
 		// changes introduced only on source
 		return source, nil
 	}
+	if strategy == graveler.MergeStrategyNone && c.params.DiffParallelism > 1 {
+		if err := c.findMergeConflict(ctx, ns, destination, source, base); err != nil {
+			return "", err
+		}
+	}
 	mctx := mergeContext{
 		strategy:      strategy,
 		ns:            ns,
@@ -215,6 +270,83 @@ func (c *committedManager) Merge(ctx context.Context, ns graveler.StorageNamespa
 	return c.merge(ctx, mctx)
 }
 
+// findMergeConflict fails with graveler.ErrConflictFound when merging source into destination conflicts.  The merge
+// writes a single metarange in key order, so it runs in one goroutine and finds a conflict only after writing all
+// the ranges before it.  findMergeConflict merges partitions of the key space concurrently, without writing them, so
+// a conflicting merge fails without writing ranges.  It runs the same merge as the write, and reports the conflicts
+// the write would report.
+func (c *committedManager) findMergeConflict(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID) error {
+	starts, err := c.diffPartitionStarts(ctx, ns, destination, source)
+	if err != nil {
+		return err
+	}
+	if len(starts) <= 1 {
+		return nil
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.params.DiffParallelism)
+	for i := range starts {
+		if gctx.Err() != nil {
+			break
+		}
+		start := starts[i]
+		var end graveler.Key
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		g.Go(func() error {
+			return c.mergePartition(gctx, ns, destination, source, base, start, end)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, graveler.ErrConflictFound) || ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("find merge conflict ns=%s id=%s: %w", ns, destination, err)
+	}
+	return nil
+}
+
+// mergePartition merges the keys of source and destination from start and before end, without writing them
+func (c *committedManager) mergePartition(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID, start, end graveler.Key) error {
+	newIterator := func(id graveler.MetaRangeID) (Iterator, error) {
+		it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, id)
+		if err != nil {
+			return nil, err
+		}
+		it.SeekGE(start)
+		return newUpperBoundIterator(it, end), nil
+	}
+	destIt, err := newIterator(destination)
+	if err != nil {
+		return fmt.Errorf("get destination iterator: %w", err)
+	}
+	defer destIt.Close()
+	srcIt, err := newIterator(source)
+	if err != nil {
+		return fmt.Errorf("get source iterator: %w", err)
+	}
+	defer srcIt.Close()
+	// the merge moves base forward over its ranges, without reading the ranges before start
+	baseIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, base)
+	if err != nil {
+		return fmt.Errorf("get base iterator: %w", err)
+	}
+	defer baseIt.Close()
+	return Merge(ctx, discardMetaRangeWriter{}, baseIt, srcIt, destIt, graveler.MergeStrategyNone)
+}
+
+// discardMetaRangeWriter is a MetaRangeWriter that writes nothing
+type discardMetaRangeWriter struct{}
+
+func (discardMetaRangeWriter) WriteRecord(graveler.ValueRecord) error { return nil }
+
+func (discardMetaRangeWriter) WriteRange(Range) error { return nil }
+
+func (discardMetaRangeWriter) Close(context.Context) (*graveler.MetaRangeID, error) { return nil, nil }
+
+func (discardMetaRangeWriter) Abort() error { return nil }
+
 type mergeContext struct {
 	destIt        Iterator
 	srcIt         Iterator
@@ -137,6 +137,169 @@ func TestManager_WriteMetaRange(t *testing.T) {
 	}
 }
 
+// expectFakeMetaRanges makes metarangeManager return iterators over the sides, by metarange ID
+func expectFakeMetaRanges(metarangeManager *mock.MockMetaRangeManager, ns graveler.StorageNamespace, sides map[graveler.MetaRangeID]diffTestSide) {
+	for id, side := range sides {
+		side := side
+		metarangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), ns, id).
+			DoAndReturn(func(context.Context, graveler.StorageNamespace, graveler.MetaRangeID) (committed.Iterator, error) {
+				return newFakeMetaRangeIterator(side.keys, side.identities), nil
+			}).AnyTimes()
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	const (
+		ns    = "some-ns"
+		left  = graveler.MetaRangeID("left")
+		right = graveler.MetaRangeID("right")
+	)
+	leftSide, rightSide := newParallelDiffTestSides()
+	ctx := context.Background()
+	leftIt, rightIt, _ := newTestDiffIteratorsFactory(leftSide, rightSide)(ctx)
+	sequential := committed.NewDiffValueIterator(ctx, leftIt, rightIt)
+	expected := readDiffs(t, sequential)
+	sequential.Close()
+
+	tests := []struct {
+		name             string
+		parallelism      int
+		sequentialValues int
+	}{
+		{name: "sequential", parallelism: 1},
+		{name: "small", parallelism: 4},
+		{name: "parallel", parallelism: 4, sequentialValues: 1},
+		{name: "sequential_then_parallel", parallelism: 4, sequentialValues: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			metarangeManager := mock.NewMockMetaRangeManager(ctrl)
+			rangeManager := mock.NewMockRangeManager(ctrl)
+			expectFakeMetaRanges(metarangeManager, ns, map[graveler.MetaRangeID]diffTestSide{left: leftSide, right: rightSide})
+
+			diffParams := params
+			diffParams.DiffParallelism = tt.parallelism
+			diffParams.DiffRangesPerPartition = 1
+			diffParams.DiffSequentialValues = tt.sequentialValues
+			sut := committed.NewCommittedManager(metarangeManager, rangeManager, diffParams)
+
+			it, err := sut.Diff(ctx, ns, left, right)
+			require.NoError(t, err)
+			defer it.Close()
+			require.Equal(t, expected, readDiffs(t, it))
+
+			// seeking starts over sequentially
+			it.SeekGE(graveler.Key("k020"))
+			var fromSeek []graveler.Diff
+			for _, d := range expected {
+				if string(d.Key) >= "k020" {
+					fromSeek = append(fromSeek, d)
+				}
+			}
+			require.Equal(t, fromSeek, readDiffs(t, it))
+		})
+	}
+}
+
+func TestManager_DiffPage(t *testing.T) {
+	const (
+		ns       = "some-ns"
+		left     = graveler.MetaRangeID("left")
+		right    = graveler.MetaRangeID("right")
+		pageSize = 3
+	)
+	leftSide, rightSide := newParallelDiffTestSides()
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	metarangeManager := mock.NewMockMetaRangeManager(ctrl)
+	rangeManager := mock.NewMockRangeManager(ctrl)
+	// a page is read by one diff of the metaranges, without listing them to partition the diff
+	metarangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), graveler.StorageNamespace(ns), left).
+		Return(newFakeMetaRangeIterator(leftSide.keys, leftSide.identities), nil).Times(1)
+	metarangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), graveler.StorageNamespace(ns), right).
+		Return(newFakeMetaRangeIterator(rightSide.keys, rightSide.identities), nil).Times(1)
+
+	diffParams := params
+	diffParams.DiffParallelism = 4
+	diffParams.DiffRangesPerPartition = 1
+	diffParams.DiffSequentialValues = pageSize + 1
+	sut := committed.NewCommittedManager(metarangeManager, rangeManager, diffParams)
+
+	it, err := sut.Diff(ctx, ns, left, right)
+	require.NoError(t, err)
+	defer it.Close()
+	it.SeekGE(graveler.Key("k010"))
+	for i := 0; i < pageSize; i++ {
+		require.True(t, it.Next())
+	}
+	require.NoError(t, it.Err())
+}
+
+func TestManager_MergeConflict(t *testing.T) {
+	const (
+		ns          = "some-ns"
+		base        = graveler.MetaRangeID("base")
+		destination = graveler.MetaRangeID("destination")
+		source      = graveler.MetaRangeID("source")
+	)
+	// source changes key 2 of every third range, see newParallelDiffTestSides
+	baseSide, sourceSide := newParallelDiffTestSides()
+	changeKey := func(side diffTestSide, key string) diffTestSide {
+		changed := diffTestSide{}
+		for r, keys := range side.keys {
+			identities := append([]string(nil), side.identities[r]...)
+			for k := range keys {
+				if keys[k] == key {
+					identities[k] = "destination" + key
+				}
+			}
+			changed.keys = append(changed.keys, keys)
+			changed.identities = append(changed.identities, identities)
+		}
+		return changed
+	}
+
+	tests := []struct {
+		name        string
+		changedKey  string
+		expectedErr error
+	}{
+		{name: "conflict", changedKey: "k026-2", expectedErr: graveler.ErrConflictFound},
+		{name: "no_conflict", changedKey: "k027-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			metarangeManager := mock.NewMockMetaRangeManager(ctrl)
+			rangeManager := mock.NewMockRangeManager(ctrl)
+			expectFakeMetaRanges(metarangeManager, ns, map[graveler.MetaRangeID]diffTestSide{
+				base:        baseSide,
+				destination: changeKey(baseSide, tt.changedKey),
+				source:      sourceSide,
+			})
+			if tt.expectedErr == nil {
+				// the merge is written only when there is no conflict
+				writer := mock.NewMockMetaRangeWriter(ctrl)
+				writer.EXPECT().WriteRecord(gomock.Any()).Return(nil).AnyTimes()
+				writer.EXPECT().WriteRange(gomock.Any()).Return(nil).AnyTimes()
+				writer.EXPECT().Abort().Return(nil)
+				mergedID := graveler.MetaRangeID("merged")
+				writer.EXPECT().Close(gomock.Any()).Return(&mergedID, nil)
+				metarangeManager.EXPECT().NewWriter(gomock.Any(), graveler.StorageNamespace(ns), nil).Return(writer)
+			}
+
+			mergeParams := params
+			mergeParams.DiffParallelism = 4
+			mergeParams.DiffRangesPerPartition = 1
+			sut := committed.NewCommittedManager(metarangeManager, rangeManager, mergeParams)
+			_, err := sut.Merge(ctx, ns, destination, source, base, graveler.MergeStrategyNone)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
 func min(x, y int) int {
 	if x < y {
 		return x
@@ -22,6 +22,14 @@ type Params struct {
 	RangeSizeEntriesRaggedness float64
 	// MaxUploaders is the maximal number of uploaders to use in a single metarange writer.
 	MaxUploaders int
+	// DiffParallelism is the maximal number of partitions of a diff processed concurrently.  Diffs
+	// are processed by a single goroutine when it is 1 or less.
+	DiffParallelism int
+	// DiffRangesPerPartition is the number of changed ranges in each partition of a parallel diff.
+	DiffRangesPerPartition int
+	// DiffSequentialValues is the number of diffs read by a single goroutine before a diff continues in parallel,
+	// DefaultDiffSequentialValues when 0.  Reading a page of a diff stays sequential.
+	DiffSequentialValues int
 }
 
 type metaRangeManager struct {
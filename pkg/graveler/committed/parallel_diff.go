@@ -0,0 +1,427 @@
+package committed
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+const (
+	// parallelDiffBufferSize is the number of diffs of a partition buffered ahead of the reader
+	parallelDiffBufferSize = 1024
+	// DefaultDiffSequentialValues is the default number of diffs read sequentially before a diff continues in parallel
+	DefaultDiffSequentialValues = 10_000
+)
+
+// DiffIteratorsFactory returns new iterators over the left and right sides of a diff
+type DiffIteratorsFactory func(ctx context.Context) (left Iterator, right Iterator, err error)
+
+type diffPartition struct {
+	start graveler.Key // nil for the start of the key space
+	end   graveler.Key // nil for the end of the key space
+}
+
+type diffResult struct {
+	diff *graveler.Diff
+	err  error
+}
+
+// parallelDiffIterator diffs partitions of the key space concurrently and returns their diffs in key order
+type parallelDiffIterator struct {
+	ctx          context.Context
+	newIterators DiffIteratorsFactory
+	starts       []graveler.Key
+	parallelism  int
+
+	seekKey  graveler.Key
+	started  bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	queue    chan chan diffResult
+	current  chan diffResult
+	value    *graveler.Diff
+	err      error
+	isClosed bool
+}
+
+// NewParallelDiffIterator returns a DiffIterator over the diff of the iterators returned by newIterators. The key
+// space is split into partitions beginning at each of starts, which are diffed by up to parallelism workers, each on
+// its own iterators. Diffs are returned in key order.
+func NewParallelDiffIterator(ctx context.Context, newIterators DiffIteratorsFactory, starts []graveler.Key, parallelism int) graveler.DiffIterator {
+	return &parallelDiffIterator{
+		ctx:          ctx,
+		newIterators: newIterators,
+		starts:       starts,
+		parallelism:  parallelism,
+	}
+}
+
+// partitions returns the partitions of the key space from key
+func (d *parallelDiffIterator) partitions(key graveler.Key) []diffPartition {
+	partitions := make([]diffPartition, 0, len(d.starts))
+	for i, start := range d.starts {
+		var end graveler.Key
+		if i+1 < len(d.starts) {
+			end = d.starts[i+1]
+		}
+		if end != nil && bytes.Compare(end, key) <= 0 {
+			continue
+		}
+		if bytes.Compare(start, key) < 0 {
+			start = key
+		}
+		partitions = append(partitions, diffPartition{start: start, end: end})
+	}
+	return partitions
+}
+
+// start runs the diff of the partitions from seekKey in the background
+func (d *parallelDiffIterator) start() {
+	ctx, cancel := context.WithCancel(d.ctx)
+	d.cancel = cancel
+	d.queue = make(chan chan diffResult, d.parallelism)
+	partitions := d.partitions(d.seekKey)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer close(d.queue)
+		workers := make(chan struct{}, d.parallelism)
+		for _, p := range partitions {
+			select {
+			case workers <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			out := make(chan diffResult, parallelDiffBufferSize)
+			d.wg.Add(1)
+			go func(p diffPartition) {
+				defer d.wg.Done()
+				defer func() { <-workers }()
+				defer close(out)
+				d.diffPartition(ctx, p, out)
+			}(p)
+			select {
+			case d.queue <- out:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// diffPartition writes the diffs of partition p to out
+func (d *parallelDiffIterator) diffPartition(ctx context.Context, p diffPartition, out chan<- diffResult) {
+	send := func(res diffResult) bool {
+		select {
+		case out <- res:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	left, right, err := d.newIterators(ctx)
+	if err != nil {
+		send(diffResult{err: err})
+		return
+	}
+	it := NewDiffValueIterator(ctx, newUpperBoundIterator(left, p.end), newUpperBoundIterator(right, p.end))
+	defer it.Close()
+	if p.start != nil {
+		it.SeekGE(p.start)
+	}
+	for it.Next() {
+		if !send(diffResult{diff: it.Value().Copy()}) {
+			return
+		}
+	}
+	if err := it.Err(); err != nil {
+		send(diffResult{err: err})
+	}
+}
+
+// stop cancels the running diff and waits for its workers to exit
+func (d *parallelDiffIterator) stop() {
+	if !d.started {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+	d.started = false
+	d.queue = nil
+	d.current = nil
+}
+
+func (d *parallelDiffIterator) Next() bool {
+	if d.isClosed || d.err != nil {
+		return false
+	}
+	if !d.started {
+		d.started = true
+		d.start()
+	}
+	for {
+		if d.current == nil {
+			select {
+			case out, ok := <-d.queue:
+				if !ok {
+					d.value = nil
+					if err := d.ctx.Err(); err != nil {
+						d.err = err
+					}
+					return false
+				}
+				d.current = out
+			case <-d.ctx.Done():
+				d.value = nil
+				d.err = d.ctx.Err()
+				return false
+			}
+		}
+		res, ok := <-d.current
+		if !ok {
+			d.current = nil
+			continue
+		}
+		if res.err != nil {
+			d.value = nil
+			d.err = res.err
+			return false
+		}
+		d.value = res.diff
+		return true
+	}
+}
+
+func (d *parallelDiffIterator) SeekGE(id graveler.Key) {
+	d.stop()
+	d.seekKey = id.Copy()
+	d.value = nil
+	d.err = nil
+}
+
+func (d *parallelDiffIterator) Value() *graveler.Diff {
+	return d.value
+}
+
+func (d *parallelDiffIterator) Err() error {
+	return d.err
+}
+
+func (d *parallelDiffIterator) Close() {
+	d.stop()
+	d.value = nil
+	d.isClosed = true
+}
+
+// ParallelDiffFactory returns a DiffIterator diffing in parallel, or nil when the diff is too small to be partitioned
+type ParallelDiffFactory func(ctx context.Context) (graveler.DiffIterator, error)
+
+// adaptiveDiffIterator diffs sequentially, and continues in parallel after reading sequentialValues diffs without
+// seeking. Reading a page of a diff, or a small diff, does not start the parallel workers, which buffer diffs ahead
+// of the reader.
+type adaptiveDiffIterator struct {
+	ctx              context.Context
+	newSequential    func(ctx context.Context) (graveler.DiffIterator, error)
+	newParallel      ParallelDiffFactory
+	sequentialValues int
+
+	it       graveler.DiffIterator
+	read     int
+	parallel bool
+	// tried is set once the diff cannot continue in parallel, until the next seek
+	tried bool
+	err   error
+}
+
+// NewAdaptiveDiffIterator returns a DiffIterator that reads sequentialValues diffs from the iterator returned by
+// newSequential, then continues from the iterator returned by newParallel. SeekGE starts over sequentially.
+func NewAdaptiveDiffIterator(ctx context.Context, newSequential func(ctx context.Context) (graveler.DiffIterator, error), newParallel ParallelDiffFactory, sequentialValues int) (graveler.DiffIterator, error) {
+	it, err := newSequential(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptiveDiffIterator{
+		ctx:              ctx,
+		newSequential:    newSequential,
+		newParallel:      newParallel,
+		sequentialValues: sequentialValues,
+		it:               it,
+	}, nil
+}
+
+// continueInParallel replaces the sequential diff with a parallel diff of the keys after the current value
+func (d *adaptiveDiffIterator) continueInParallel() error {
+	d.tried = true
+	val := d.it.Value()
+	if val == nil {
+		return nil
+	}
+	parallel, err := d.newParallel(d.ctx)
+	if err != nil || parallel == nil {
+		return err
+	}
+	// the smallest key after the current key
+	after := make(graveler.Key, len(val.Key)+1)
+	copy(after, val.Key)
+	parallel.SeekGE(after)
+	d.it.Close()
+	d.it = parallel
+	d.parallel = true
+	return nil
+}
+
+func (d *adaptiveDiffIterator) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	if !d.parallel && !d.tried && d.read >= d.sequentialValues {
+		if err := d.continueInParallel(); err != nil {
+			d.err = err
+			return false
+		}
+	}
+	if !d.it.Next() {
+		return false
+	}
+	d.read++
+	return true
+}
+
+func (d *adaptiveDiffIterator) SeekGE(id graveler.Key) {
+	d.err = nil
+	d.read = 0
+	d.tried = false
+	if d.parallel {
+		it, err := d.newSequential(d.ctx)
+		if err != nil {
+			d.err = err
+			return
+		}
+		d.it.Close()
+		d.it = it
+		d.parallel = false
+	}
+	d.it.SeekGE(id)
+}
+
+func (d *adaptiveDiffIterator) Value() *graveler.Diff {
+	if d.err != nil {
+		return nil
+	}
+	return d.it.Value()
+}
+
+func (d *adaptiveDiffIterator) Err() error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.it.Err()
+}
+
+func (d *adaptiveDiffIterator) Close() {
+	d.it.Close()
+}
+
+// DiffPartitionStarts returns the start keys of partitions for diffing metaranges with leftRanges and rightRanges,
+// each holding about rangesPerPartition ranges found on only one side. Ranges found on both sides hold no diffs.
+func DiffPartitionStarts(leftRanges, rightRanges []*Range, rangesPerPartition int) []graveler.Key {
+	leftIDs := make(map[ID]struct{}, len(leftRanges))
+	for _, r := range leftRanges {
+		leftIDs[r.ID] = struct{}{}
+	}
+	rightIDs := make(map[ID]struct{}, len(rightRanges))
+	for _, r := range rightRanges {
+		rightIDs[r.ID] = struct{}{}
+	}
+	var keys []graveler.Key
+	for _, r := range leftRanges {
+		if _, ok := rightIDs[r.ID]; !ok {
+			keys = append(keys, graveler.Key(r.MinKey))
+		}
+	}
+	for _, r := range rightRanges {
+		if _, ok := leftIDs[r.ID]; !ok {
+			keys = append(keys, graveler.Key(r.MinKey))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	if rangesPerPartition < 1 {
+		rangesPerPartition = 1
+	}
+	var starts []graveler.Key
+	for i := 0; i < len(keys); i += rangesPerPartition {
+		if len(starts) > 0 && bytes.Equal(starts[len(starts)-1], keys[i]) {
+			continue
+		}
+		starts = append(starts, keys[i])
+	}
+	return starts
+}
+
+// upperBoundIterator stops iterating at the first value or range starting at or after end
+type upperBoundIterator struct {
+	it   Iterator
+	end  graveler.Key
+	done bool
+}
+
+func newUpperBoundIterator(it Iterator, end graveler.Key) Iterator {
+	if end == nil {
+		return it
+	}
+	return &upperBoundIterator{it: it, end: end}
+}
+
+func (u *upperBoundIterator) checkBound(ok bool) bool {
+	if !ok {
+		return false
+	}
+	val, rng := u.it.Value()
+	key := graveler.Key(rng.MinKey)
+	if val != nil {
+		key = val.Key
+	}
+	if bytes.Compare(key, u.end) >= 0 {
+		u.done = true
+		return false
+	}
+	return true
+}
+
+func (u *upperBoundIterator) Next() bool {
+	if u.done {
+		return false
+	}
+	return u.checkBound(u.it.Next())
+}
+
+func (u *upperBoundIterator) NextRange() bool {
+	if u.done {
+		return false
+	}
+	return u.checkBound(u.it.NextRange())
+}
+
+func (u *upperBoundIterator) Value() (*graveler.ValueRecord, *Range) {
+	if u.done {
+		return nil, nil
+	}
+	return u.it.Value()
+}
+
+func (u *upperBoundIterator) SeekGE(id graveler.Key) {
+	u.done = false
+	u.it.SeekGE(id)
+}
+
+func (u *upperBoundIterator) Err() error {
+	return u.it.Err()
+}
+
+func (u *upperBoundIterator) Close() {
+	u.it.Close()
+}
@@ -0,0 +1,207 @@
+package committed_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/committed"
+)
+
+type diffTestSide struct {
+	keys       [][]string
+	identities [][]string
+}
+
+func (s diffTestSide) ranges() []*committed.Range {
+	it := newFakeMetaRangeIterator(s.keys, s.identities)
+	var ranges []*committed.Range
+	for it.NextRange() {
+		_, rng := it.Value()
+		ranges = append(ranges, rng)
+	}
+	return ranges
+}
+
+// newParallelDiffTestSides returns sides of a diff with identical, changed, added and removed ranges
+func newParallelDiffTestSides() (diffTestSide, diffTestSide) {
+	const (
+		numRanges    = 30
+		keysPerRange = 4
+	)
+	var left, right diffTestSide
+	for r := 0; r < numRanges; r++ {
+		var leftKeys, leftIdentities, rightKeys, rightIdentities []string
+		for k := 0; k < keysPerRange; k++ {
+			key := fmt.Sprintf("k%03d-%d", r, k)
+			leftKeys = append(leftKeys, key)
+			leftIdentities = append(leftIdentities, "i"+key)
+			switch {
+			case r%3 == 0:
+				// identical range
+				rightKeys = append(rightKeys, key)
+				rightIdentities = append(rightIdentities, "i"+key)
+			case r%3 == 1 && k == 1:
+				// removed key
+			case r%3 == 1:
+				rightKeys = append(rightKeys, key)
+				rightIdentities = append(rightIdentities, "i"+key)
+			case k == 2:
+				// changed key, and an added key
+				rightKeys = append(rightKeys, key, key+"-added")
+				rightIdentities = append(rightIdentities, "changed"+key, "i"+key+"-added")
+			default:
+				rightKeys = append(rightKeys, key)
+				rightIdentities = append(rightIdentities, "i"+key)
+			}
+		}
+		left.keys = append(left.keys, leftKeys)
+		left.identities = append(left.identities, leftIdentities)
+		right.keys = append(right.keys, rightKeys)
+		right.identities = append(right.identities, rightIdentities)
+	}
+	return left, right
+}
+
+func newTestDiffIteratorsFactory(left, right diffTestSide) committed.DiffIteratorsFactory {
+	return func(context.Context) (committed.Iterator, committed.Iterator, error) {
+		return newFakeMetaRangeIterator(left.keys, left.identities), newFakeMetaRangeIterator(right.keys, right.identities), nil
+	}
+}
+
+func readDiffs(t *testing.T, it graveler.DiffIterator) []graveler.Diff {
+	t.Helper()
+	var diffs []graveler.Diff
+	for it.Next() {
+		diffs = append(diffs, *it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal("diff iterator failed:", err)
+	}
+	return diffs
+}
+
+func TestParallelDiff(t *testing.T) {
+	ctx := context.Background()
+	left, right := newParallelDiffTestSides()
+	newIterators := newTestDiffIteratorsFactory(left, right)
+
+	leftIt, rightIt, _ := newIterators(ctx)
+	sequential := committed.NewDiffValueIterator(ctx, leftIt, rightIt)
+	expected := readDiffs(t, sequential)
+	sequential.Close()
+	if len(expected) == 0 {
+		t.Fatal("expected a non-empty diff")
+	}
+
+	for _, rangesPerPartition := range []int{1, 2, 5, 100} {
+		for _, parallelism := range []int{1, 2, 8} {
+			t.Run(fmt.Sprintf("ranges_%d_parallelism_%d", rangesPerPartition, parallelism), func(t *testing.T) {
+				starts := committed.DiffPartitionStarts(left.ranges(), right.ranges(), rangesPerPartition)
+				it := committed.NewParallelDiffIterator(ctx, newIterators, starts, parallelism)
+				defer it.Close()
+				if diff := deep.Equal(readDiffs(t, it), expected); diff != nil {
+					t.Fatal("parallel diff differs from diff:", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestParallelDiffSeekGE(t *testing.T) {
+	ctx := context.Background()
+	left, right := newParallelDiffTestSides()
+	newIterators := newTestDiffIteratorsFactory(left, right)
+	starts := committed.DiffPartitionStarts(left.ranges(), right.ranges(), 2)
+
+	leftIt, rightIt, _ := newIterators(ctx)
+	sequential := committed.NewDiffValueIterator(ctx, leftIt, rightIt)
+	defer sequential.Close()
+	it := committed.NewParallelDiffIterator(ctx, newIterators, starts, 4)
+	defer it.Close()
+
+	for _, seekKey := range []string{"k010-2", "k000", "k020-2-added", "k015-3", "k999"} {
+		t.Run(seekKey, func(t *testing.T) {
+			sequential.SeekGE(graveler.Key(seekKey))
+			expected := readDiffs(t, sequential)
+			// start reading before seeking, so seek restarts a running diff
+			it.SeekGE(graveler.Key("k000"))
+			it.Next()
+			it.SeekGE(graveler.Key(seekKey))
+			if diff := deep.Equal(readDiffs(t, it), expected); diff != nil {
+				t.Fatal("parallel diff after seek differs from diff:", diff)
+			}
+		})
+	}
+}
+
+func TestParallelDiffErr(t *testing.T) {
+	ctx := context.Background()
+	left, right := newParallelDiffTestSides()
+	starts := committed.DiffPartitionStarts(left.ranges(), right.ranges(), 1)
+	errIterators := errors.New("iterators failed")
+	calls := make(chan struct{}, len(starts))
+	newIterators := func(ctx context.Context) (committed.Iterator, committed.Iterator, error) {
+		calls <- struct{}{}
+		if len(calls) > 3 {
+			return nil, nil, errIterators
+		}
+		return newTestDiffIteratorsFactory(left, right)(ctx)
+	}
+
+	it := committed.NewParallelDiffIterator(ctx, newIterators, starts, 1)
+	defer it.Close()
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), errIterators) {
+		t.Fatalf("Err() = %v, expected %v", it.Err(), errIterators)
+	}
+}
+
+func TestParallelDiffCancelContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	left, right := newParallelDiffTestSides()
+	starts := committed.DiffPartitionStarts(left.ranges(), right.ranges(), 1)
+	it := committed.NewParallelDiffIterator(ctx, newTestDiffIteratorsFactory(left, right), starts, 4)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatal("expected a diff")
+	}
+	cancel()
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Fatalf("Err() = %v, expected %v", it.Err(), context.Canceled)
+	}
+}
+
+func TestDiffPartitionStarts(t *testing.T) {
+	left := diffTestSide{
+		keys:       [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}, {"g", "h"}},
+		identities: [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}, {"g", "h"}},
+	}
+	right := diffTestSide{
+		keys:       [][]string{{"a", "b"}, {"c", "d"}, {"e", "f1"}, {"g", "h"}, {"i"}},
+		identities: [][]string{{"a", "b"}, {"c", "d2"}, {"e", "f1"}, {"g", "h"}, {"i"}},
+	}
+	tests := []struct {
+		rangesPerPartition int
+		expected           []graveler.Key
+	}{
+		{rangesPerPartition: 1, expected: []graveler.Key{graveler.Key("c"), graveler.Key("e"), graveler.Key("i")}},
+		{rangesPerPartition: 2, expected: []graveler.Key{graveler.Key("c"), graveler.Key("e"), graveler.Key("i")}},
+		{rangesPerPartition: 3, expected: []graveler.Key{graveler.Key("c"), graveler.Key("e")}},
+		{rangesPerPartition: 10, expected: []graveler.Key{graveler.Key("c")}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.rangesPerPartition), func(t *testing.T) {
+			starts := committed.DiffPartitionStarts(left.ranges(), right.ranges(), tt.rangesPerPartition)
+			if diff := deep.Equal(starts, tt.expected); diff != nil {
+				t.Fatal("DiffPartitionStarts() diff:", diff)
+			}
+		})
+	}
+}
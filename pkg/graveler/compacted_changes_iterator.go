@@ -0,0 +1,43 @@
+package graveler
+
+// compactedChangesIterator lists the diff of a compacted meta range over its commit as staged values, removed keys
+// are listed as tombstones
+type compactedChangesIterator struct {
+	diffIt DiffIterator
+	value  *ValueRecord
+}
+
+// NewCompactedChangesIterator lists the changes of diffIt as the values staged to make them
+func NewCompactedChangesIterator(diffIt DiffIterator) ValueIterator {
+	return &compactedChangesIterator{diffIt: diffIt}
+}
+
+func (c *compactedChangesIterator) Next() bool {
+	if !c.diffIt.Next() {
+		c.value = nil
+		return false
+	}
+	diff := c.diffIt.Value()
+	c.value = &ValueRecord{Key: diff.Key}
+	if diff.Type != DiffTypeRemoved {
+		c.value.Value = diff.Value
+	}
+	return true
+}
+
+func (c *compactedChangesIterator) SeekGE(id Key) {
+	c.value = nil
+	c.diffIt.SeekGE(id)
+}
+
+func (c *compactedChangesIterator) Value() *ValueRecord {
+	return c.value
+}
+
+func (c *compactedChangesIterator) Err() error {
+	return c.diffIt.Err()
+}
+
+func (c *compactedChangesIterator) Close() {
+	c.diffIt.Close()
+}
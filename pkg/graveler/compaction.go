@@ -0,0 +1,349 @@
+package graveler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+var (
+	compactionsCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graveler_staging_compactions",
+			Help: "Number of branch staging area compactions by status.",
+		},
+		[]string{"status"},
+	)
+	compactionDurationHistogram = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "graveler_staging_compaction_duration_seconds",
+			Help:    "Duration of branch staging area compactions.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+		},
+	)
+	compactedEntriesCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graveler_staging_compacted_entries",
+			Help: "Number of staged entries written into compacted meta ranges by change type.",
+		},
+		[]string{"type"},
+	)
+)
+
+// CompactionParams configures background compaction of branch staging areas
+type CompactionParams struct {
+	// StagedWritesThreshold is the number of writes staged on a branch that triggers its compaction
+	StagedWritesThreshold int
+
+	// Settings overrides StagedWritesThreshold for specific branches, when set
+	Settings CompactionSettings
+}
+
+// CompactionSettings provides per-branch overrides of the staged writes threshold
+type CompactionSettings interface {
+	// StagedWritesThreshold returns the threshold of the branch, and false when the branch uses the default
+	// threshold. A zero threshold disables compaction of the branch. Called on every staged write.
+	StagedWritesThreshold(ctx context.Context, repository *RepositoryRecord, branchID BranchID) (int, bool, error)
+}
+
+type compactionKey struct {
+	repositoryID RepositoryID
+	branchID     BranchID
+}
+
+// stagingCompactor counts the writes staged on each branch by this process and triggers compactions. Counts are kept
+// in memory: each lakeFS server counts the writes it staged, and the counts start over when it restarts.
+type stagingCompactor struct {
+	ctx       context.Context
+	threshold int
+	settings  CompactionSettings
+	mu        sync.Mutex
+	writes    map[compactionKey]int
+	running   map[compactionKey]struct{}
+}
+
+// EnableCompaction compacts the staging area of a branch in the background once params.StagedWritesThreshold writes,
+// or the threshold of the branch in params.Settings, were staged on it by this process. Compactions stop when ctx is
+// done.
+func (g *Graveler) EnableCompaction(ctx context.Context, params CompactionParams) {
+	g.compactor = &stagingCompactor{
+		ctx:       ctx,
+		threshold: params.StagedWritesThreshold,
+		settings:  params.Settings,
+		writes:    make(map[compactionKey]int),
+		running:   make(map[compactionKey]struct{}),
+	}
+}
+
+// trackStagedWrites counts writes staged on the branch, and starts its compaction when they reach the threshold
+func (g *Graveler) trackStagedWrites(ctx context.Context, repository *RepositoryRecord, branchID BranchID, n int) {
+	c := g.compactor
+	if c == nil {
+		return
+	}
+	threshold := c.stagedWritesThreshold(ctx, repository, branchID)
+	if threshold <= 0 || !c.add(compactionKey{repositoryID: repository.RepositoryID, branchID: branchID}, n, threshold) {
+		return
+	}
+	go func() {
+		defer c.done(compactionKey{repositoryID: repository.RepositoryID, branchID: branchID})
+		err := g.CompactBranch(c.ctx, repository, branchID)
+		if err != nil && c.ctx.Err() == nil {
+			g.log(c.ctx).WithError(err).WithFields(logging.Fields{
+				"repository": repository.RepositoryID,
+				"branch":     branchID,
+			}).Warn("Compact branch staging area failed")
+		}
+	}()
+}
+
+// stagedWritesThreshold returns the threshold of the branch, the default threshold is used when its settings can't
+// be read
+func (c *stagingCompactor) stagedWritesThreshold(ctx context.Context, repository *RepositoryRecord, branchID BranchID) int {
+	if c.settings == nil {
+		return c.threshold
+	}
+	threshold, ok, err := c.settings.StagedWritesThreshold(ctx, repository, branchID)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"repository": repository.RepositoryID,
+			"branch":     branchID,
+		}).Warn("Failed to get branch compaction settings, using the default threshold")
+		return c.threshold
+	}
+	if !ok {
+		return c.threshold
+	}
+	return threshold
+}
+
+// add counts n writes to the branch, returns true when the branch should be compacted
+func (c *stagingCompactor) add(key compactionKey, n, threshold int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes[key] += n
+	if c.writes[key] < threshold {
+		return false
+	}
+	if _, ok := c.running[key]; ok {
+		return false
+	}
+	delete(c.writes, key)
+	c.running[key] = struct{}{}
+	return true
+}
+
+func (c *stagingCompactor) done(key compactionKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.running, key)
+}
+
+// CompactBranch writes the staging area of the branch as ranges of its compacted meta range. Reads, diffs and
+// commits of the branch use the compacted meta range instead of listing the compacted entries from the staging
+// manager. The staging token is sealed first, so every write staged before the call is compacted.
+func (g *Graveler) CompactBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error {
+	start := time.Now()
+	status := "success"
+	defer func() {
+		compactionsCounter.WithLabelValues(status).Inc()
+		compactionDurationHistogram.Observe(time.Since(start).Seconds())
+	}()
+
+	err := g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+		branch.SealedTokens = append([]StagingToken{branch.StagingToken}, branch.SealedTokens...)
+		branch.StagingToken = GenerateStagingToken(repository.RepositoryID, branchID)
+		return branch, nil
+	}, "seal")
+	if err != nil {
+		status = "failure"
+		return err
+	}
+
+	var (
+		compactedTokens []StagingToken
+		summary         DiffSummary
+	)
+	err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+		if len(branch.SealedTokens) == 0 {
+			// committed (or compacted) since the staging token was sealed
+			return nil, ErrNoChanges
+		}
+		commitMetaRangeID, err := g.commitMetaRangeID(ctx, repository, branch)
+		if err != nil {
+			return nil, err
+		}
+		baseMetaRangeID := commitMetaRangeID
+		if branch.CompactedBaseMetaRangeID != "" {
+			baseMetaRangeID = branch.CompactedBaseMetaRangeID
+		}
+		changes, err := g.sealedTokensIterator(ctx, branch, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer changes.Close()
+		metaRangeID, diffSummary, err := g.CommittedManager.Commit(ctx, repository.StorageNamespace, baseMetaRangeID, changes)
+		switch {
+		case errors.Is(err, ErrNoChanges):
+			// the sealed tokens change nothing, drop them
+			metaRangeID = baseMetaRangeID
+		case err != nil:
+			return nil, fmt.Errorf("compact: %w", err)
+		}
+		summary = diffSummary
+		compactedTokens = branch.SealedTokens
+		branch.SealedTokens = make([]StagingToken, 0)
+		branch.CompactedBaseMetaRangeID = metaRangeID
+		if metaRangeID == commitMetaRangeID {
+			branch.CompactedBaseMetaRangeID = ""
+		}
+		return branch, nil
+	}, "compact")
+	if errors.Is(err, ErrNoChanges) {
+		status = "no_changes"
+		return nil
+	}
+	if err != nil {
+		status = "failure"
+		return err
+	}
+	g.dropTokens(ctx, compactedTokens...)
+	for diffType, count := range summary.Count {
+		compactedEntriesCounter.WithLabelValues(diffTypeLabel(diffType)).Add(float64(count))
+	}
+	return nil
+}
+
+func diffTypeLabel(diffType DiffType) string {
+	switch diffType {
+	case DiffTypeAdded:
+		return "added"
+	case DiffTypeRemoved:
+		return "removed"
+	case DiffTypeChanged:
+		return "changed"
+	default:
+		return "conflict"
+	}
+}
+
+// commitMetaRangeID returns the meta range of the branch commit
+func (g *Graveler) commitMetaRangeID(ctx context.Context, repository *RepositoryRecord, branch *Branch) (MetaRangeID, error) {
+	if branch.CommitID == "" {
+		return "", nil
+	}
+	commit, err := g.RefManager.GetCommit(ctx, repository, branch.CommitID)
+	if err != nil {
+		return "", err
+	}
+	return commit.MetaRangeID, nil
+}
+
+// branchBaseMetaRangeID returns the meta range the staging area of the branch applies to: its compacted meta range,
+// or the meta range of its commit when nothing was compacted
+func (g *Graveler) branchBaseMetaRangeID(ctx context.Context, repository *RepositoryRecord, branch *Branch) (MetaRangeID, error) {
+	if branch.CompactedBaseMetaRangeID != "" {
+		return branch.CompactedBaseMetaRangeID, nil
+	}
+	return g.commitMetaRangeID(ctx, repository, branch)
+}
+
+// commitSealed applies the sealed tokens of the branch to its base meta range, and returns the meta range to commit.
+// Returns ErrNoChanges when the branch has no changes over its commit.
+func (g *Graveler) commitSealed(ctx context.Context, repository *RepositoryRecord, branch *Branch, commitMetaRangeID MetaRangeID) (MetaRangeID, error) {
+	baseMetaRangeID := commitMetaRangeID
+	if branch.CompactedBaseMetaRangeID != "" {
+		baseMetaRangeID = branch.CompactedBaseMetaRangeID
+	}
+	if len(branch.SealedTokens) == 0 && baseMetaRangeID != commitMetaRangeID {
+		// all changes were compacted
+		return baseMetaRangeID, nil
+	}
+	changes, err := g.sealedTokensIterator(ctx, branch, 0)
+	if err != nil {
+		return "", err
+	}
+	defer changes.Close()
+	// returns err if the commit is empty (no changes)
+	metaRangeID, _, err := g.CommittedManager.Commit(ctx, repository.StorageNamespace, baseMetaRangeID, changes)
+	if errors.Is(err, ErrNoChanges) && baseMetaRangeID != commitMetaRangeID {
+		// sealed tokens change nothing over the compacted changes
+		return baseMetaRangeID, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+	return metaRangeID, nil
+}
+
+// compactedChangesIterator returns the changes of the compacted meta range of the branch over its commit, listed as
+// staged values. Returns nil when the branch has no compacted changes.
+func (g *Graveler) compactedChangesIterator(ctx context.Context, repository *RepositoryRecord, branch *Branch) (ValueIterator, error) {
+	if branch.CompactedBaseMetaRangeID == "" {
+		return nil, nil
+	}
+	commitMetaRangeID, err := g.commitMetaRangeID(ctx, repository, branch)
+	if err != nil {
+		return nil, err
+	}
+	diffIt, err := g.CommittedManager.Diff(ctx, repository.StorageNamespace, commitMetaRangeID, branch.CompactedBaseMetaRangeID)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompactedChangesIterator(diffIt), nil
+}
+
+// listUncommitted returns an iterator of the uncommitted changes of the branch: its staging area (staging + sealed)
+// followed by its compacted changes
+func (g *Graveler) listUncommitted(ctx context.Context, repository *RepositoryRecord, b *Branch, batchSize int) (ValueIterator, error) {
+	it, err := g.listStagingArea(ctx, b, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	compacted, err := g.compactedChangesIterator(ctx, repository, b)
+	if err != nil {
+		it.Close()
+		return nil, err
+	}
+	if compacted == nil {
+		return it, nil
+	}
+	return NewCombinedIterator(it, compacted), nil
+}
+
+// getCompactedChange returns the value of key in the compacted meta range of the branch, when compaction changed it
+// from its committed value. A nil value is returned when the compacted changes deleted key. Returns ErrNotFound when
+// key was not changed by compaction.
+func (g *Graveler) getCompactedChange(ctx context.Context, repository *RepositoryRecord, branch *Branch, key Key) (*Value, error) {
+	if branch == nil || branch.CompactedBaseMetaRangeID == "" {
+		return nil, ErrNotFound
+	}
+	compacted, err := g.CommittedManager.Get(ctx, repository.StorageNamespace, branch.CompactedBaseMetaRangeID, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	commitMetaRangeID, err := g.commitMetaRangeID(ctx, repository, branch)
+	if err != nil {
+		return nil, err
+	}
+	committed, err := g.CommittedManager.Get(ctx, repository.StorageNamespace, commitMetaRangeID, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	switch {
+	case compacted == nil && committed == nil:
+		return nil, ErrNotFound
+	case compacted == nil:
+		return nil, nil
+	case committed != nil && bytes.Equal(compacted.Identity, committed.Identity):
+		return nil, ErrNotFound
+	}
+	return compacted, nil
+}
@@ -59,6 +59,7 @@ var (
 	ErrTooManyTries                 = errors.New("too many tries")
 	ErrSkipValueUpdate              = errors.New("skip value update")
 	ErrImport                       = wrapError(ErrUserVisible, "import error")
+	ErrRebalanceBranchMoved         = wrapError(ErrConflictFound, "branch commit changed during rebalance")
 )
 
 // wrappedError is an error for wrapping another error while ignoring its message.
@@ -386,6 +386,9 @@ type Branch struct {
 	StagingToken StagingToken
 	// SealedTokens - Staging tokens are appended to the front, this allows building the diff iterator easily
 	SealedTokens []StagingToken
+	// CompactedBaseMetaRangeID - the meta range of CommitID with the changes of compacted staging tokens applied.
+	// Staging tokens are applied on top of it. Empty when the branch has no compacted changes.
+	CompactedBaseMetaRangeID MetaRangeID
 }
 
 // BranchRecord holds BranchID with the associated Branch data
@@ -471,8 +474,9 @@ type KeyValueStore interface {
 	// List lists values on repository / ref
 	List(ctx context.Context, repository *RepositoryRecord, ref Ref, batchSize int) (ValueIterator, error)
 
-	// ListStaging returns ValueIterator for branch staging area. Exposed to be used by catalog in PrepareGCUncommitted
-	ListStaging(ctx context.Context, branch *Branch, batchSize int) (ValueIterator, error)
+	// ListStaging returns ValueIterator for branch staging area, including its compacted changes. Exposed to be used by
+	// catalog in PrepareGCUncommitted
+	ListStaging(ctx context.Context, repository *RepositoryRecord, branch *Branch, batchSize int) (ValueIterator, error)
 }
 
 type VersionController interface {
@@ -528,6 +532,10 @@ type VersionController interface {
 	//   ErrNothingToCommit in case there is no data in stage
 	Commit(ctx context.Context, repository *RepositoryRecord, branchID BranchID, commitParams CommitParams) (CommitID, error)
 
+	// Rebalance commits the meta range of the branch commit rewritten into balanced ranges, with an identical tree.
+	//   ErrNoChanges in case the ranges are already balanced
+	Rebalance(ctx context.Context, repository *RepositoryRecord, branchID BranchID, commitParams CommitParams) (CommitID, error)
+
 	// WriteMetaRangeByIterator accepts a ValueIterator and writes the entire iterator to a new MetaRange
 	// and returns the result ID.
 	WriteMetaRangeByIterator(ctx context.Context, repository *RepositoryRecord, it ValueIterator) (*MetaRangeID, error)
@@ -658,6 +666,8 @@ type Plumbing interface {
 	StageObject(ctx context.Context, stagingToken string, object ValueRecord) error
 	// UpdateBranchToken updates the given branch stagingToken
 	UpdateBranchToken(ctx context.Context, repository *RepositoryRecord, branchID, stagingToken string) error
+	// DiffMetaRanges returns the differences between two metaranges, an empty metarange ID is treated as an empty metarange.
+	DiffMetaRanges(ctx context.Context, repository *RepositoryRecord, left, right MetaRangeID) (DiffIterator, error)
 }
 
 type Dumper interface {
@@ -1021,6 +1031,8 @@ type Graveler struct {
 	StagingManager           StagingManager
 	protectedBranchesManager ProtectedBranchesManager
 	garbageCollectionManager GarbageCollectionManager
+	// compactor triggers compaction of branch staging areas, nil when compaction is disabled
+	compactor *stagingCompactor
 	// logger *without context* to be used for logging.  It should be
 	// avoided in favour of g.log(ctx) in any operation where context is
 	// available.
@@ -1053,11 +1065,43 @@ func (g *Graveler) CreateRepository(ctx context.Context, repositoryID Repository
 		return nil, err
 	}
 
+	preRunID := g.hooks.NewRunID()
+	err = g.hooks.PreCreateRepositoryHook(ctx, HookRecord{
+		RunID:            preRunID,
+		EventType:        EventTypePreCreateRepository,
+		RepositoryID:     repositoryID,
+		StorageNamespace: storageNamespace,
+		BranchID:         branchID,
+	})
+	if err != nil {
+		return nil, &HookAbortError{
+			EventType: EventTypePreCreateRepository,
+			RunID:     preRunID,
+			Err:       err,
+		}
+	}
+
 	repo := NewRepository(storageNamespace, branchID)
 	repository, err := g.RefManager.CreateRepository(ctx, repositoryID, repo)
 	if err != nil {
 		return nil, err
 	}
+	branch, err := g.RefManager.GetBranch(ctx, repository, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	postRunID := g.hooks.NewRunID()
+	g.hooks.PostCreateRepositoryHook(ctx, HookRecord{
+		RunID:            postRunID,
+		EventType:        EventTypePostCreateRepository,
+		RepositoryID:     repositoryID,
+		StorageNamespace: storageNamespace,
+		SourceRef:        branch.CommitID.Ref(),
+		BranchID:         branchID,
+		CommitID:         branch.CommitID,
+		PreRunID:         preRunID,
+	})
 	return repository, nil
 }
 
@@ -1080,7 +1124,48 @@ func (g *Graveler) ListRepositories(ctx context.Context) (RepositoryIterator, er
 }
 
 func (g *Graveler) DeleteRepository(ctx context.Context, repositoryID RepositoryID) error {
-	return g.RefManager.DeleteRepository(ctx, repositoryID)
+	repository, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if errors.Is(err, ErrRepositoryInDeletion) {
+		// complete a previous delete, hooks already ran when it started
+		return g.RefManager.DeleteRepository(ctx, repositoryID)
+	}
+	if err != nil {
+		return err
+	}
+
+	preRunID := g.hooks.NewRunID()
+	err = g.hooks.PreDeleteRepositoryHook(ctx, HookRecord{
+		RunID:            preRunID,
+		EventType:        EventTypePreDeleteRepository,
+		RepositoryID:     repositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        repository.DefaultBranchID.Ref(),
+		BranchID:         repository.DefaultBranchID,
+	})
+	if err != nil {
+		return &HookAbortError{
+			EventType: EventTypePreDeleteRepository,
+			RunID:     preRunID,
+			Err:       err,
+		}
+	}
+
+	err = g.RefManager.DeleteRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+
+	// the repository no longer exists, so there is no reference to read the actions from
+	postRunID := g.hooks.NewRunID()
+	g.hooks.PostDeleteRepositoryHook(ctx, HookRecord{
+		RunID:            postRunID,
+		EventType:        EventTypePostDeleteRepository,
+		RepositoryID:     repositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		BranchID:         repository.DefaultBranchID,
+		PreRunID:         preRunID,
+	})
+	return nil
 }
 
 func (g *Graveler) GetRepositoryMetadata(ctx context.Context, repositoryID RepositoryID) (RepositoryMetadata, error) {
@@ -1113,11 +1198,16 @@ func (g *Graveler) UpdateBranchToken(ctx context.Context, repository *Repository
 		g.dropTokens(ctx, tokensToDrop...)
 		branch.StagingToken = StagingToken(stagingToken)
 		branch.SealedTokens = make([]StagingToken, 0)
+		branch.CompactedBaseMetaRangeID = ""
 		return branch, nil
 	})
 	return err
 }
 
+func (g *Graveler) DiffMetaRanges(ctx context.Context, repository *RepositoryRecord, left, right MetaRangeID) (DiffIterator, error) {
+	return g.CommittedManager.Diff(ctx, repository.StorageNamespace, left, right)
+}
+
 func (g *Graveler) WriteMetaRangeByIterator(ctx context.Context, repository *RepositoryRecord, it ValueIterator) (*MetaRangeID, error) {
 	return g.CommittedManager.WriteMetaRangeByIterator(ctx, repository.StorageNamespace, it, nil)
 }
@@ -1220,6 +1310,7 @@ func (g *Graveler) UpdateBranch(ctx context.Context, repository *RepositoryRecor
 
 		tokensToDrop = currBranch.SealedTokens
 		currBranch.SealedTokens = []StagingToken{}
+		currBranch.CompactedBaseMetaRangeID = ""
 		currBranch.CommitID = reference.CommitID
 		newBranch = currBranch
 		return currBranch, nil
@@ -1575,7 +1666,17 @@ func (g *Graveler) Get(ctx context.Context, repository *RepositoryRecord, ref Re
 		opt(&options)
 	}
 	if options.StageOnly {
-		return nil, ErrNotFound
+		value, err := g.getCompactedChange(ctx, repository, reference.Branch, key)
+		if err == nil && value == nil {
+			// tombstone - the entry was deleted by the compacted staging area
+			return nil, ErrNotFound
+		}
+		return value, err
+	}
+
+	// If key is not found in staging area, return the key from the compacted staging area of the branch
+	if reference.CompactedBaseMetaRangeID != "" {
+		return g.CommittedManager.Get(ctx, repository.StorageNamespace, reference.CompactedBaseMetaRangeID, key)
 	}
 
 	// If key is not found in staging area (or reference is not a branch), return the key from committed
@@ -1618,6 +1719,10 @@ func (g *Graveler) Set(ctx context.Context, repository *RepositoryRecord, branch
 		opt(options)
 	}
 
+	if err := g.objectHook(ctx, repository, branchID, key, EventTypePrePutObject, g.hooks.PrePutObjectHook); err != nil {
+		return err
+	}
+
 	log := g.log(ctx).WithFields(logging.Fields{"key": key, "operation": "set"})
 	err = g.safeBranchWrite(ctx, log, repository, branchID, safeBranchWriteOptions{MaxTries: options.MaxTries}, func(branch *Branch) error {
 		if !options.IfAbsent {
@@ -1638,6 +1743,9 @@ func (g *Graveler) Set(ctx context.Context, repository *RepositoryRecord, branch
 			return nil, ErrSkipValueUpdate
 		})
 	}, "set")
+	if err == nil {
+		g.trackStagedWrites(ctx, repository, branchID, 1)
+	}
 	return err
 }
 
@@ -1702,11 +1810,18 @@ func (g *Graveler) Delete(ctx context.Context, repository *RepositoryRecord, bra
 		return ErrWriteToProtectedBranch
 	}
 
+	if err := g.objectHook(ctx, repository, branchID, key, EventTypePreDeleteObject, g.hooks.PreDeleteObjectHook); err != nil {
+		return err
+	}
+
 	log := g.log(ctx).WithFields(logging.Fields{"key": key, "operation": "delete"})
 	err = g.safeBranchWrite(ctx, log, repository, branchID,
 		safeBranchWriteOptions{}, func(branch *Branch) error {
 			return g.deleteUnsafe(ctx, repository, branch, key, nil)
 		}, "delete")
+	if err == nil {
+		g.trackStagedWrites(ctx, repository, branchID, 1)
+	}
 	return err
 }
 
@@ -1724,11 +1839,23 @@ func (g *Graveler) DeleteBatch(ctx context.Context, repository *RepositoryRecord
 		return fmt.Errorf("keys length (%d) passed the maximum allowed(%d): %w", len(keys), DeleteKeysMaxSize, ErrInvalidValue)
 	}
 
+	// keys rejected by a pre-delete-object hook are reported as failed, the rest are deleted
+	var hooksErr *multierror.Error
+	allowedKeys := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		if err := g.objectHook(ctx, repository, branchID, key, EventTypePreDeleteObject, g.hooks.PreDeleteObjectHook); err != nil {
+			hooksErr = multierror.Append(hooksErr, &DeleteError{Key: key, Err: err})
+			continue
+		}
+		allowedKeys = append(allowedKeys, key)
+	}
+
 	var m *multierror.Error
 	log := g.log(ctx).WithField("operation", "delete_keys")
 	err = g.safeBranchWrite(ctx, log, repository, branchID, safeBranchWriteOptions{}, func(branch *Branch) error {
-		var cachedMetaRangeID MetaRangeID // used to cache the committed branch metarange ID
-		for _, key := range keys {
+		m = hooksErr
+		var cachedMetaRangeID MetaRangeID // used to cache the branch base metarange ID
+		for _, key := range allowedKeys {
 			err := g.deleteUnsafe(ctx, repository, branch, key, &cachedMetaRangeID)
 			if err != nil {
 				m = multierror.Append(m, &DeleteError{Key: key, Err: err})
@@ -1736,9 +1863,33 @@ func (g *Graveler) DeleteBatch(ctx context.Context, repository *RepositoryRecord
 		}
 		return m.ErrorOrNil()
 	}, "delete_keys")
+	g.trackStagedWrites(ctx, repository, branchID, len(allowedKeys))
 	return err
 }
 
+// objectHook runs a pre object event hook on key, returning a HookAbortError if the hook failed
+func (g *Graveler) objectHook(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key, eventType EventType,
+	hook func(ctx context.Context, record HookRecord) error) error {
+	runID := g.hooks.NewRunID()
+	err := hook(ctx, HookRecord{
+		RunID:            runID,
+		EventType:        eventType,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        branchID.Ref(),
+		BranchID:         branchID,
+		ObjectKey:        key,
+	})
+	if err != nil {
+		return &HookAbortError{
+			EventType: eventType,
+			RunID:     runID,
+			Err:       err,
+		}
+	}
+	return nil
+}
+
 func (g *Graveler) deleteUnsafe(ctx context.Context, repository *RepositoryRecord, branch *Branch, key Key, cachedMetaRangeID *MetaRangeID) error {
 	// First attempt to update on staging token
 	err := g.StagingManager.Set(ctx, branch.StagingToken, key, nil, true)
@@ -1746,16 +1897,18 @@ func (g *Graveler) deleteUnsafe(ctx context.Context, repository *RepositoryRecor
 		return err
 	}
 
-	// check key in committed - do we need tombstone?
+	// check key in committed (or compacted) - do we need tombstone?
 	var metaRangeID MetaRangeID
 	if cachedMetaRangeID != nil && *cachedMetaRangeID != "" {
 		metaRangeID = *cachedMetaRangeID
 	} else {
-		commit, err := g.RefManager.GetCommit(ctx, repository, branch.CommitID)
+		metaRangeID, err = g.branchBaseMetaRangeID(ctx, repository, branch)
 		if err != nil {
 			return err
 		}
-		metaRangeID = commit.MetaRangeID
+		if cachedMetaRangeID != nil {
+			*cachedMetaRangeID = metaRangeID
+		}
 	}
 
 	_, err = g.CommittedManager.Get(ctx, repository.StorageNamespace, metaRangeID, key)
@@ -1786,9 +1939,9 @@ func (g *Graveler) deleteUnsafe(ctx context.Context, repository *RepositoryRecor
 	return nil
 }
 
-// ListStaging Exposing listStagingArea to catalog for PrepareGCUncommitted
-func (g *Graveler) ListStaging(ctx context.Context, branch *Branch, batchSize int) (ValueIterator, error) {
-	return g.listStagingArea(ctx, branch, batchSize)
+// ListStaging Exposing listUncommitted to catalog for PrepareGCUncommitted
+func (g *Graveler) ListStaging(ctx context.Context, repository *RepositoryRecord, branch *Branch, batchSize int) (ValueIterator, error) {
+	return g.listUncommitted(ctx, repository, branch, batchSize)
 }
 
 // listStagingArea Returns an iterator which is an aggregation of all changes on all the branch's staging area (staging + sealed)
@@ -1850,7 +2003,9 @@ func (g *Graveler) List(ctx context.Context, repository *RepositoryRecord, ref R
 		return nil, err
 	}
 	var metaRangeID MetaRangeID
-	if reference.CommitID != "" {
+	if reference.CompactedBaseMetaRangeID != "" {
+		metaRangeID = reference.CompactedBaseMetaRangeID
+	} else if reference.CommitID != "" {
 		commit, err := g.RefManager.GetCommit(ctx, repository, reference.CommitID)
 		if err != nil {
 			return nil, err
@@ -1890,7 +2045,7 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 	}
 	storageNamespace = repository.StorageNamespace
 
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+	err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
 		if params.SourceMetaRange != nil {
 			empty, err := g.isStagingEmpty(ctx, repository, branch)
 			if err != nil {
@@ -1903,7 +2058,7 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 		branch.SealedTokens = append([]StagingToken{branch.StagingToken}, branch.SealedTokens...)
 		branch.StagingToken = GenerateStagingToken(repository.RepositoryID, branchID)
 		return branch, nil
-	})
+	}, "seal")
 	if err != nil {
 		return "", err
 	}
@@ -1961,16 +2116,10 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 			}
 			commit.MetaRangeID = *params.SourceMetaRange
 		} else {
-			changes, err := g.sealedTokensIterator(ctx, branch, 0)
+			commit.MetaRangeID, err = g.commitSealed(ctx, repository, branch, branchMetaRangeID)
 			if err != nil {
 				return nil, err
 			}
-			defer changes.Close()
-			// returns err if the commit is empty (no changes)
-			commit.MetaRangeID, _, err = g.CommittedManager.Commit(ctx, storageNamespace, branchMetaRangeID, changes)
-			if err != nil {
-				return nil, fmt.Errorf("commit: %w", err)
-			}
 		}
 		sealedToDrop = branch.SealedTokens
 
@@ -1982,6 +2131,7 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 
 		branch.CommitID = newCommitID
 		branch.SealedTokens = make([]StagingToken, 0)
+		branch.CompactedBaseMetaRangeID = ""
 		return branch, nil
 	}, "commit")
 	if err != nil {
@@ -2117,13 +2267,13 @@ func (g *Graveler) addCommitNoLock(ctx context.Context, repository *RepositoryRe
 }
 
 func (g *Graveler) isStagingEmpty(ctx context.Context, repository *RepositoryRecord, branch *Branch) (bool, error) {
-	itr, err := g.listStagingArea(ctx, branch, 1)
+	itr, err := g.listUncommitted(ctx, repository, branch, 1)
 	if err != nil {
 		return false, err
 	}
 	defer itr.Close()
 
-	// Iterating over staging area (staging + sealed) of the branch and check for entries
+	// Iterating over staging area (staging + sealed + compacted) of the branch and check for entries
 	return g.checkEmpty(ctx, repository, branch, itr)
 }
 
@@ -2146,15 +2296,26 @@ func (g *Graveler) checkEmpty(ctx context.Context, repository *RepositoryRecord,
 }
 
 func (g *Graveler) isSealedEmpty(ctx context.Context, repository *RepositoryRecord, branch *Branch) (bool, error) {
-	if len(branch.SealedTokens) == 0 {
+	if len(branch.SealedTokens) == 0 && branch.CompactedBaseMetaRangeID == "" {
 		return true, nil
 	}
-	itrs, err := g.sealedTokensIterator(ctx, branch, 1)
+	itrs, err := g.listSealedTokens(ctx, branch, 1)
+	if err != nil {
+		return false, err
+	}
+	compacted, err := g.compactedChangesIterator(ctx, repository, branch)
 	if err != nil {
+		for _, it := range itrs {
+			it.Close()
+		}
 		return false, err
 	}
-	defer itrs.Close()
-	return g.checkEmpty(ctx, repository, branch, itrs)
+	if compacted != nil {
+		itrs = append(itrs, compacted)
+	}
+	changes := NewCombinedIterator(itrs...)
+	defer changes.Close()
+	return g.checkEmpty(ctx, repository, branch, changes)
 }
 
 // dropTokens deletes all staging area entries of a given branch from store
@@ -2175,6 +2336,10 @@ func (g *Graveler) Reset(ctx context.Context, repository *RepositoryRecord, bran
 	if isProtected {
 		return ErrWriteToProtectedBranch
 	}
+	preRunID, err := g.preResetHook(ctx, repository, branchID, nil)
+	if err != nil {
+		return err
+	}
 	tokensToDrop := make([]StagingToken, 0)
 	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
 		// Save current branch tokens for drop
@@ -2184,6 +2349,7 @@ func (g *Graveler) Reset(ctx context.Context, repository *RepositoryRecord, bran
 		// Zero tokens and try to set branch
 		branch.StagingToken = GenerateStagingToken(repository.RepositoryID, branchID)
 		branch.SealedTokens = make([]StagingToken, 0)
+		branch.CompactedBaseMetaRangeID = ""
 		return branch, nil
 	})
 	if err != nil { // Branch update failed, don't drop staging tokens
@@ -2191,9 +2357,46 @@ func (g *Graveler) Reset(ctx context.Context, repository *RepositoryRecord, bran
 	}
 
 	g.dropTokens(ctx, tokensToDrop...)
+	g.postResetHook(ctx, repository, branchID, nil, preRunID)
 	return nil
 }
 
+// preResetHook runs the pre-reset hook of a branch, or of key on the branch when key isn't nil. Returns the run ID of the hook.
+func (g *Graveler) preResetHook(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key) (string, error) {
+	preRunID := g.hooks.NewRunID()
+	err := g.hooks.PreResetHook(ctx, HookRecord{
+		RunID:            preRunID,
+		EventType:        EventTypePreReset,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        branchID.Ref(),
+		BranchID:         branchID,
+		ObjectKey:        key,
+	})
+	if err != nil {
+		return "", &HookAbortError{
+			EventType: EventTypePreReset,
+			RunID:     preRunID,
+			Err:       err,
+		}
+	}
+	return preRunID, nil
+}
+
+func (g *Graveler) postResetHook(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key, preRunID string) {
+	postRunID := g.hooks.NewRunID()
+	g.hooks.PostResetHook(ctx, HookRecord{
+		RunID:            postRunID,
+		EventType:        EventTypePostReset,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        branchID.Ref(),
+		BranchID:         branchID,
+		ObjectKey:        key,
+		PreRunID:         preRunID,
+	})
+}
+
 // resetKey resets given key on branch
 // Since we cannot (will not) modify sealed tokens data, we overwrite changes done on entry on a new staging token, effectively reverting it
 // to the current state in the branch committed data. If entry is not committed return an error
@@ -2236,6 +2439,10 @@ func (g *Graveler) ResetKey(ctx context.Context, repository *RepositoryRecord, b
 	}
 
 	staged, err := g.getFromStagingArea(ctx, branch, key)
+	if errors.Is(err, ErrNotFound) && branch.CompactedBaseMetaRangeID != "" {
+		// not in staging, reset the key if it was changed by the compacted staging area
+		staged, err = g.getCompactedChange(ctx, repository, branch, key)
+	}
 	if err != nil {
 		if errors.Is(err, ErrNotFound) { // If key is not in staging => nothing to do
 			return nil
@@ -2243,12 +2450,17 @@ func (g *Graveler) ResetKey(ctx context.Context, repository *RepositoryRecord, b
 		return err
 	}
 
+	preRunID, err := g.preResetHook(ctx, repository, branchID, key)
+	if err != nil {
+		return err
+	}
 	err = g.resetKey(ctx, repository, branch, key, staged, branch.StagingToken)
 	if err != nil {
 		if !errors.Is(err, ErrNotFound) { // Not found in staging => ignore
 			return err
 		}
 	}
+	g.postResetHook(ctx, repository, branchID, key, preRunID)
 
 	// The branch staging-token might have changed since we read it, and that's fine.
 	// If a commit started, we may or may not include it in the commit.
@@ -2265,6 +2477,10 @@ func (g *Graveler) ResetPrefix(ctx context.Context, repository *RepositoryRecord
 	if isProtected {
 		return ErrWriteToProtectedBranch
 	}
+	preRunID, err := g.preResetHook(ctx, repository, branchID, key)
+	if err != nil {
+		return err
+	}
 	// New sealed tokens list after change includes current staging token
 	newSealedTokens := make([]StagingToken, 0)
 	newStagingToken := GenerateStagingToken(repository.RepositoryID, branchID)
@@ -2274,7 +2490,7 @@ func (g *Graveler) ResetPrefix(ctx context.Context, repository *RepositoryRecord
 		newSealedTokens = append(newSealedTokens, branch.SealedTokens...)
 
 		// Reset keys by prefix on the new staging token
-		itr, err := g.listStagingArea(ctx, branch, 0)
+		itr, err := g.listUncommitted(ctx, repository, branch, 0)
 		if err != nil {
 			return nil, err
 		}
@@ -2306,8 +2522,10 @@ func (g *Graveler) ResetPrefix(ctx context.Context, repository *RepositoryRecord
 	})
 	if err != nil { // Cleanup of new staging token in case of error
 		g.dropTokens(ctx, newStagingToken)
+		return err
 	}
-	return err
+	g.postResetHook(ctx, repository, branchID, key, preRunID)
+	return nil
 }
 
 type CommitIDAndSummary struct {
@@ -2343,7 +2561,11 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 		return "", err
 	}
 
-	var commitID CommitID
+	var (
+		preRunID string
+		commit   Commit
+		commitID CommitID
+	)
 	var tokensToDrop []StagingToken
 	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
 		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
@@ -2371,13 +2593,31 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 			}
 			return nil, err
 		}
-		commit := NewCommit()
+		commit = NewCommit()
 		commit.Committer = commitParams.Committer
 		commit.Message = commitParams.Message
 		commit.MetaRangeID = metaRangeID
 		commit.Parents = []CommitID{branch.CommitID}
 		commit.Metadata = commitParams.Metadata
 		commit.Generation = branchCommit.Generation + 1
+		preRunID = g.hooks.NewRunID()
+		err = g.hooks.PreRevertHook(ctx, HookRecord{
+			RunID:            preRunID,
+			EventType:        EventTypePreRevert,
+			RepositoryID:     repository.RepositoryID,
+			StorageNamespace: repository.StorageNamespace,
+			SourceRef:        branchID.Ref(),
+			BranchID:         branchID,
+			Commit:           commit,
+			OriginCommitID:   commitRecord.CommitID,
+		})
+		if err != nil {
+			return nil, &HookAbortError{
+				EventType: EventTypePreRevert,
+				RunID:     preRunID,
+				Err:       err,
+			}
+		}
 		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
 		if err != nil {
 			return nil, fmt.Errorf("add commit: %w", err)
@@ -2385,6 +2625,7 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 
 		tokensToDrop = branch.SealedTokens
 		branch.SealedTokens = []StagingToken{}
+		branch.CompactedBaseMetaRangeID = ""
 		branch.CommitID = commitID
 		return branch, nil
 	})
@@ -2393,6 +2634,26 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 	}
 
 	g.dropTokens(ctx, tokensToDrop...)
+	postRunID := g.hooks.NewRunID()
+	err = g.hooks.PostRevertHook(ctx, HookRecord{
+		RunID:            postRunID,
+		EventType:        EventTypePostRevert,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        commitID.Ref(),
+		BranchID:         branchID,
+		Commit:           commit,
+		CommitID:         commitID,
+		OriginCommitID:   commitRecord.CommitID,
+		PreRunID:         preRunID,
+	})
+	if err != nil {
+		g.log(ctx).
+			WithError(err).
+			WithField("run_id", postRunID).
+			WithField("pre_run_id", preRunID).
+			Error("Post-revert hook failed")
+	}
 	return commitID, nil
 }
 
@@ -2432,7 +2693,11 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 		parentMetaRangeID = parentCommit.MetaRangeID
 	}
 
-	var commitID CommitID
+	var (
+		preRunID string
+		commit   Commit
+		commitID CommitID
+	)
 	var tokensToDrop []StagingToken
 	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
 		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
@@ -2453,7 +2718,7 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 			}
 			return nil, err
 		}
-		commit := NewCommit()
+		commit = NewCommit()
 		commit.Committer = committer
 		commit.Message = commitRecord.Message
 		commit.MetaRangeID = metaRangeID
@@ -2466,6 +2731,24 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 		}
 		commit.Metadata["cherry-pick-origin"] = string(commitRecord.CommitID)
 		commit.Metadata["cherry-pick-committer"] = commitRecord.Committer
+		preRunID = g.hooks.NewRunID()
+		err = g.hooks.PreCherryPickHook(ctx, HookRecord{
+			RunID:            preRunID,
+			EventType:        EventTypePreCherryPick,
+			RepositoryID:     repository.RepositoryID,
+			StorageNamespace: repository.StorageNamespace,
+			SourceRef:        branchID.Ref(),
+			BranchID:         branchID,
+			Commit:           commit,
+			OriginCommitID:   commitRecord.CommitID,
+		})
+		if err != nil {
+			return nil, &HookAbortError{
+				EventType: EventTypePreCherryPick,
+				RunID:     preRunID,
+				Err:       err,
+			}
+		}
 
 		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
 		if err != nil {
@@ -2474,6 +2757,7 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 
 		tokensToDrop = branch.SealedTokens
 		branch.SealedTokens = []StagingToken{}
+		branch.CompactedBaseMetaRangeID = ""
 		branch.CommitID = commitID
 		return branch, nil
 	})
@@ -2482,6 +2766,26 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 	}
 
 	g.dropTokens(ctx, tokensToDrop...)
+	postRunID := g.hooks.NewRunID()
+	err = g.hooks.PostCherryPickHook(ctx, HookRecord{
+		RunID:            postRunID,
+		EventType:        EventTypePostCherryPick,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		SourceRef:        commitID.Ref(),
+		BranchID:         branchID,
+		Commit:           commit,
+		CommitID:         commitID,
+		OriginCommitID:   commitRecord.CommitID,
+		PreRunID:         preRunID,
+	})
+	if err != nil {
+		g.log(ctx).
+			WithError(err).
+			WithField("run_id", postRunID).
+			WithField("pre_run_id", preRunID).
+			Error("Post-cherry-pick hook failed")
+	}
 	return commitID, nil
 }
 
@@ -2579,6 +2883,7 @@ func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, dest
 
 		tokensToDrop = branch.SealedTokens
 		branch.SealedTokens = []StagingToken{}
+		branch.CompactedBaseMetaRangeID = ""
 		branch.CommitID = commitID
 		return branch, nil
 	}, "merge")
@@ -2639,9 +2944,10 @@ func (g *Graveler) retryRepoMetadataUpdate(ctx context.Context, repository *Repo
 
 func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, destination BranchID, source MetaRangeID, commitParams CommitParams, prefixes []Prefix) (CommitID, error) {
 	var (
-		preRunID string
-		commit   Commit
-		commitID CommitID
+		preRunID       string
+		preImportRunID string
+		commit         Commit
+		commitID       CommitID
 	)
 
 	storageNamespace := repository.StorageNamespace
@@ -2689,6 +2995,7 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 		commit.Generation = toCommit.Generation + 1
 		commit.Metadata = commitParams.Metadata
 		commit.Metadata[MergeStrategyMetadataKey] = MergeStrategySrcWinsStr
+		// an import is a commit to the branch: the commit events run for it, followed by the import events
 		preRunID = g.hooks.NewRunID()
 		err = g.hooks.PreCommitHook(ctx, HookRecord{
 			RunID:            preRunID,
@@ -2706,6 +3013,23 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 				Err:       err,
 			}
 		}
+		preImportRunID = g.hooks.NewRunID()
+		err = g.hooks.PreImportHook(ctx, HookRecord{
+			RunID:            preImportRunID,
+			EventType:        EventTypePreImport,
+			SourceRef:        destination.Ref(),
+			RepositoryID:     repository.RepositoryID,
+			StorageNamespace: storageNamespace,
+			BranchID:         destination,
+			Commit:           commit,
+		})
+		if err != nil {
+			return nil, &HookAbortError{
+				EventType: EventTypePreImport,
+				RunID:     preImportRunID,
+				Err:       err,
+			}
+		}
 
 		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
 		if err != nil {
@@ -2714,6 +3038,7 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 
 		tokensToDrop = branch.SealedTokens
 		branch.SealedTokens = []StagingToken{}
+		branch.CompactedBaseMetaRangeID = ""
 		branch.CommitID = commitID
 		return branch, nil
 	}, "import")
@@ -2740,6 +3065,24 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 			WithField("pre_run_id", preRunID).
 			Error("Post-commit hook failed")
 	}
+	postImportRunID := g.hooks.NewRunID()
+	err = g.hooks.PostImportHook(ctx, HookRecord{
+		EventType:        EventTypePostImport,
+		RunID:            postImportRunID,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: storageNamespace,
+		SourceRef:        commitID.Ref(),
+		BranchID:         destination,
+		Commit:           commit,
+		CommitID:         commitID,
+		PreRunID:         preImportRunID,
+	})
+	if err != nil {
+		g.log(ctx).WithError(err).
+			WithField("run_id", postImportRunID).
+			WithField("pre_run_id", preImportRunID).
+			Error("Post-import hook failed")
+	}
 
 	if err = g.retryRepoMetadataUpdate(ctx, repository, func(metadata RepositoryMetadata) (RepositoryMetadata, error) {
 		metadata[MetadataKeyLastImportTimeStamp] = strconv.FormatInt(commit.CreationDate.Unix(), 10)
@@ -2766,7 +3109,7 @@ func (g *Graveler) DiffUncommitted(ctx context.Context, repository *RepositoryRe
 		metaRangeID = commit.MetaRangeID
 	}
 
-	valueIterator, err := g.listStagingArea(ctx, branch, 0)
+	valueIterator, err := g.listUncommitted(ctx, repository, branch, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -2832,7 +3175,7 @@ func (g *Graveler) Diff(ctx context.Context, repository *RepositoryRecord, left,
 		leftValueIterator.Close()
 		return nil, err
 	}
-	stagingIterator, err := g.listStagingArea(ctx, rightBranch, 0)
+	stagingIterator, err := g.listUncommitted(ctx, repository, rightBranch, 0)
 	if err != nil {
 		leftValueIterator.Close()
 		return nil, err
@@ -19,6 +19,7 @@ import (
 
 type Hooks struct {
 	Called           bool
+	Events           []graveler.EventType
 	Err              error
 	RunID            string
 	RepositoryID     graveler.RepositoryID
@@ -28,12 +29,15 @@ type Hooks struct {
 	CommitID         graveler.CommitID
 	Commit           graveler.Commit
 	TagID            graveler.TagID
+	OriginCommitID   graveler.CommitID
+	ObjectKey        graveler.Key
 }
 
 var ErrGravelerUpdate = errors.New("test update error")
 
 func (h *Hooks) PreCommitHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.RepositoryID = record.RepositoryID
 	h.StorageNamespace = record.StorageNamespace
 	h.BranchID = record.BranchID
@@ -43,6 +47,7 @@ func (h *Hooks) PreCommitHook(_ context.Context, record graveler.HookRecord) err
 
 func (h *Hooks) PostCommitHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.RepositoryID = record.RepositoryID
 	h.BranchID = record.BranchID
 	h.CommitID = record.CommitID
@@ -52,6 +57,7 @@ func (h *Hooks) PostCommitHook(_ context.Context, record graveler.HookRecord) er
 
 func (h *Hooks) PreMergeHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.RepositoryID = record.RepositoryID
 	h.StorageNamespace = record.StorageNamespace
 	h.BranchID = record.BranchID
@@ -62,6 +68,7 @@ func (h *Hooks) PreMergeHook(_ context.Context, record graveler.HookRecord) erro
 
 func (h *Hooks) PostMergeHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.RepositoryID = record.RepositoryID
 	h.StorageNamespace = record.StorageNamespace
 	h.BranchID = record.BranchID
@@ -73,6 +80,7 @@ func (h *Hooks) PostMergeHook(_ context.Context, record graveler.HookRecord) err
 
 func (h *Hooks) PreCreateTagHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.CommitID = record.CommitID
@@ -82,6 +90,7 @@ func (h *Hooks) PreCreateTagHook(_ context.Context, record graveler.HookRecord)
 
 func (h *Hooks) PostCreateTagHook(_ context.Context, record graveler.HookRecord) {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.CommitID = record.CommitID
@@ -90,6 +99,7 @@ func (h *Hooks) PostCreateTagHook(_ context.Context, record graveler.HookRecord)
 
 func (h *Hooks) PreDeleteTagHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.TagID = record.TagID
@@ -98,6 +108,7 @@ func (h *Hooks) PreDeleteTagHook(_ context.Context, record graveler.HookRecord)
 
 func (h *Hooks) PostDeleteTagHook(_ context.Context, record graveler.HookRecord) {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.TagID = record.TagID
@@ -105,6 +116,7 @@ func (h *Hooks) PostDeleteTagHook(_ context.Context, record graveler.HookRecord)
 
 func (h *Hooks) PreCreateBranchHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.BranchID = record.BranchID
@@ -115,6 +127,7 @@ func (h *Hooks) PreCreateBranchHook(_ context.Context, record graveler.HookRecor
 
 func (h *Hooks) PostCreateBranchHook(_ context.Context, record graveler.HookRecord) {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.BranchID = record.BranchID
@@ -124,6 +137,7 @@ func (h *Hooks) PostCreateBranchHook(_ context.Context, record graveler.HookReco
 
 func (h *Hooks) PreDeleteBranchHook(_ context.Context, record graveler.HookRecord) error {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.BranchID = record.BranchID
@@ -132,11 +146,149 @@ func (h *Hooks) PreDeleteBranchHook(_ context.Context, record graveler.HookRecor
 
 func (h *Hooks) PostDeleteBranchHook(_ context.Context, record graveler.HookRecord) {
 	h.Called = true
+	h.Events = append(h.Events, record.EventType)
 	h.StorageNamespace = record.StorageNamespace
 	h.RepositoryID = record.RepositoryID
 	h.BranchID = record.BranchID
 }
 
+func (h *Hooks) PreRevertHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.StorageNamespace = record.StorageNamespace
+	h.BranchID = record.BranchID
+	h.Commit = record.Commit
+	h.OriginCommitID = record.OriginCommitID
+	return h.Err
+}
+
+func (h *Hooks) PostRevertHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.CommitID = record.CommitID
+	h.Commit = record.Commit
+	h.OriginCommitID = record.OriginCommitID
+	return h.Err
+}
+
+func (h *Hooks) PreCherryPickHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.StorageNamespace = record.StorageNamespace
+	h.BranchID = record.BranchID
+	h.Commit = record.Commit
+	h.OriginCommitID = record.OriginCommitID
+	return h.Err
+}
+
+func (h *Hooks) PostCherryPickHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.CommitID = record.CommitID
+	h.Commit = record.Commit
+	h.OriginCommitID = record.OriginCommitID
+	return h.Err
+}
+
+func (h *Hooks) PreResetHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.ObjectKey = record.ObjectKey
+	return h.Err
+}
+
+func (h *Hooks) PostResetHook(_ context.Context, record graveler.HookRecord) {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.ObjectKey = record.ObjectKey
+}
+
+func (h *Hooks) PreImportHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.StorageNamespace = record.StorageNamespace
+	h.BranchID = record.BranchID
+	h.Commit = record.Commit
+	return h.Err
+}
+
+func (h *Hooks) PostImportHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.CommitID = record.CommitID
+	h.Commit = record.Commit
+	return h.Err
+}
+
+func (h *Hooks) PreCreateRepositoryHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	return h.Err
+}
+
+func (h *Hooks) PostCreateRepositoryHook(_ context.Context, record graveler.HookRecord) {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+}
+
+func (h *Hooks) PreDeleteRepositoryHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	return h.Err
+}
+
+func (h *Hooks) PostDeleteRepositoryHook(_ context.Context, record graveler.HookRecord) {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+}
+
+func (h *Hooks) PrePutObjectHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.ObjectKey = record.ObjectKey
+	return h.Err
+}
+
+func (h *Hooks) PreDeleteObjectHook(_ context.Context, record graveler.HookRecord) error {
+	h.Called = true
+	h.Events = append(h.Events, record.EventType)
+	h.StorageNamespace = record.StorageNamespace
+	h.RepositoryID = record.RepositoryID
+	h.BranchID = record.BranchID
+	h.ObjectKey = record.ObjectKey
+	return h.Err
+}
+
 func (h *Hooks) NewRunID() string {
 	return ""
 }
@@ -1373,6 +1525,117 @@ func TestGravelerCommit(t *testing.T) {
 	}
 }
 
+func TestGraveler_CompactBranch(t *testing.T) {
+	ctx := context.Background()
+	const (
+		commitID             = graveler.CommitID("commit")
+		commitMetaRangeID    = graveler.MetaRangeID("committed")
+		compactedMetaRangeID = graveler.MetaRangeID("compacted")
+	)
+	committedManager := &testutil.CommittedFake{MetaRangeID: compactedMetaRangeID}
+	stagingManager := &testutil.StagingFake{
+		ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{}),
+		Values: map[string]map[string]*graveler.Value{
+			"token": {"key": &graveler.Value{Identity: []byte("identity"), Data: []byte("data")}},
+		},
+	}
+	refManager := &testutil.RefsFake{
+		CommitID: "new-commit",
+		Branch:   &graveler.Branch{CommitID: commitID, StagingToken: "token"},
+		Commits:  map[graveler.CommitID]*graveler.Commit{commitID: {MetaRangeID: commitMetaRangeID}},
+	}
+	g := graveler.NewGraveler(committedManager, stagingManager, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+
+	err := g.CompactBranch(ctx, repository, "branch")
+	require.NoError(t, err)
+	require.Equal(t, commitMetaRangeID, committedManager.AppliedData.MetaRangeID, "compaction should apply the staging area on the commit")
+	require.Equal(t, compactedMetaRangeID, refManager.Branch.CompactedBaseMetaRangeID)
+	require.Empty(t, refManager.Branch.SealedTokens)
+	require.NotEqual(t, graveler.StagingToken("token"), refManager.Branch.StagingToken)
+	require.True(t, stagingManager.DropCalled, "compacted staging token should be dropped")
+
+	// commit applies the staging area on the compacted meta range
+	committedManager.MetaRangeID = "new-metarange"
+	_, err = g.Commit(ctx, repository, "branch", graveler.CommitParams{Committer: "committer", Message: "message"})
+	require.NoError(t, err)
+	require.Equal(t, compactedMetaRangeID, committedManager.AppliedData.MetaRangeID, "commit should apply the staging area on the compacted meta range")
+	require.Equal(t, graveler.MetaRangeID("new-metarange"), refManager.AddedCommit.MetaRangeID)
+	require.Empty(t, refManager.Branch.CompactedBaseMetaRangeID)
+}
+
+func TestGraveler_Rebalance(t *testing.T) {
+	ctx := context.Background()
+	const (
+		commitID          = graveler.CommitID("commit")
+		commitMetaRangeID = graveler.MetaRangeID("committed")
+	)
+	newRefManager := func() *testutil.RefsFake {
+		return &testutil.RefsFake{
+			CommitID: "rebalanced",
+			Branch:   &graveler.Branch{CommitID: commitID, StagingToken: "token"},
+			Commits:  map[graveler.CommitID]*graveler.Commit{commitID: {MetaRangeID: commitMetaRangeID, Generation: 3}},
+		}
+	}
+
+	t.Run("rebalanced", func(t *testing.T) {
+		committedManager := &testutil.CommittedFake{MetaRangeID: "balanced", ValueIterator: testutil.NewValueIteratorFake(nil)}
+		refManager := newRefManager()
+		g := graveler.NewGraveler(committedManager, &testutil.StagingFake{}, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+
+		got, err := g.Rebalance(ctx, repository, "branch", graveler.CommitParams{Committer: "committer", Message: "rebalance"})
+		require.NoError(t, err)
+		require.Equal(t, graveler.CommitID("rebalanced"), got)
+		require.Equal(t, graveler.MetaRangeID("balanced"), refManager.AddedCommit.MetaRangeID)
+		require.Equal(t, graveler.CommitParents{commitID}, refManager.AddedCommit.Parents)
+		require.Equal(t, "rebalance", refManager.AddedCommit.Message)
+		require.Equal(t, graveler.CommitID("rebalanced"), refManager.Branch.CommitID)
+		require.Equal(t, graveler.StagingToken("token"), refManager.Branch.StagingToken, "rebalance should keep the staging area")
+	})
+
+	t.Run("already balanced", func(t *testing.T) {
+		committedManager := &testutil.CommittedFake{MetaRangeID: commitMetaRangeID, ValueIterator: testutil.NewValueIteratorFake(nil)}
+		refManager := newRefManager()
+		g := graveler.NewGraveler(committedManager, &testutil.StagingFake{}, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+
+		_, err := g.Rebalance(ctx, repository, "branch", graveler.CommitParams{Committer: "committer", Message: "rebalance"})
+		require.ErrorIs(t, err, graveler.ErrNoChanges)
+		require.Equal(t, commitID, refManager.Branch.CommitID)
+	})
+
+	t.Run("protected branch", func(t *testing.T) {
+		protectedBranchesManager := testutil.NewProtectedBranchesManagerFake("branch")
+		g := graveler.NewGraveler(&testutil.CommittedFake{MetaRangeID: "balanced"}, &testutil.StagingFake{}, newRefManager(), nil, protectedBranchesManager)
+
+		_, err := g.Rebalance(ctx, repository, "branch", graveler.CommitParams{Committer: "committer", Message: "rebalance"})
+		require.ErrorIs(t, err, graveler.ErrCommitToProtectedBranch)
+	})
+
+	t.Run("branch moved", func(t *testing.T) {
+		committedManager := &testutil.CommittedFake{MetaRangeID: "balanced", ValueIterator: testutil.NewValueIteratorFake(nil)}
+		refManager := &movingRefsFake{RefsFake: newRefManager(), moveTo: "other"}
+		g := graveler.NewGraveler(committedManager, &testutil.StagingFake{}, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+
+		_, err := g.Rebalance(ctx, repository, "branch", graveler.CommitParams{Committer: "committer", Message: "rebalance"})
+		require.ErrorIs(t, err, graveler.ErrRebalanceBranchMoved)
+		require.ErrorIs(t, err, graveler.ErrConflictFound)
+		require.Equal(t, graveler.CommitID("other"), refManager.Branch.CommitID)
+		require.Equal(t, 1, refManager.updates, "the branch update should not be retried")
+	})
+}
+
+// movingRefsFake moves the branch to another commit before every branch update
+type movingRefsFake struct {
+	*testutil.RefsFake
+	moveTo  graveler.CommitID
+	updates int
+}
+
+func (m *movingRefsFake) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, update graveler.BranchUpdateFunc) error {
+	m.updates++
+	m.Branch.CommitID = m.moveTo
+	return m.RefsFake.BranchUpdate(ctx, repository, branchID, update)
+}
+
 // TestGraveler_MergeInvalidRef test merge with invalid source reference in order
 func TestGraveler_MergeInvalidRef(t *testing.T) {
 	// prepare graveler
@@ -2282,6 +2545,251 @@ func TestGraveler_PreDeleteBranchHook(t *testing.T) {
 	}
 }
 
+func TestGraveler_PrePutObjectHook(t *testing.T) {
+	// prepare graveler
+	const branchID = graveler.BranchID("branch")
+	key := graveler.Key("data/object")
+	committedManager := &testutil.CommittedFake{}
+	stagingManager := &testutil.StagingFake{}
+	refManager := &testutil.RefsFake{
+		Branch:       &graveler.Branch{CommitID: "commitID", StagingToken: "token"},
+		StagingToken: "token",
+	}
+	// tests
+	errSomethingBad := errors.New("first error")
+	tests := []struct {
+		name string
+		hook bool
+		err  error
+	}{
+		{
+			name: "without hook",
+			hook: false,
+			err:  nil,
+		},
+		{
+			name: "hook no error",
+			hook: true,
+			err:  nil,
+		},
+		{
+			name: "hook error",
+			hook: true,
+			err:  errSomethingBad,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			ctx := context.Background()
+			g := newGraveler(t, committedManager, stagingManager, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+			h := &Hooks{Err: tt.err}
+			if tt.hook {
+				g.SetHooksHandler(h)
+			}
+
+			err := g.Set(ctx, repository, branchID, key, graveler.Value{Identity: []byte("id"), Data: []byte("data")})
+
+			// verify we got an error
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Set err=%v, expected=%v", err, tt.err)
+			}
+			var hookErr *graveler.HookAbortError
+			if err != nil && !errors.As(err, &hookErr) {
+				t.Fatalf("Set err=%v, expected HookAbortError", err)
+			}
+
+			// verify that calls made until the first error
+			if tt.hook != h.Called {
+				t.Fatalf("Pre-put object hook h.Called=%t, expected=%t", h.Called, tt.hook)
+			}
+			if !h.Called {
+				return
+			}
+			if h.RepositoryID != repository.RepositoryID {
+				t.Errorf("Hook repository '%s', expected '%s'", h.RepositoryID, repository.RepositoryID)
+			}
+			if h.BranchID != branchID {
+				t.Errorf("Hook branch ID '%s', expected '%s'", h.BranchID, branchID)
+			}
+			if h.ObjectKey.String() != key.String() {
+				t.Errorf("Hook object key '%s', expected '%s'", h.ObjectKey, key)
+			}
+		})
+	}
+}
+
+func TestGraveler_PreResetHook(t *testing.T) {
+	// prepare graveler
+	const branchID = graveler.BranchID("branch")
+	committedManager := &testutil.CommittedFake{}
+	stagingManager := &testutil.StagingFake{}
+	refManager := &testutil.RefsFake{
+		Branch:       &graveler.Branch{CommitID: "commitID", StagingToken: "token"},
+		StagingToken: "token",
+	}
+	// tests
+	errSomethingBad := errors.New("first error")
+	tests := []struct {
+		name string
+		hook bool
+		err  error
+	}{
+		{
+			name: "without hook",
+			hook: false,
+			err:  nil,
+		},
+		{
+			name: "hook no error",
+			hook: true,
+			err:  nil,
+		},
+		{
+			name: "hook error",
+			hook: true,
+			err:  errSomethingBad,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			ctx := context.Background()
+			g := newGraveler(t, committedManager, stagingManager, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+			h := &Hooks{Err: tt.err}
+			if tt.hook {
+				g.SetHooksHandler(h)
+			}
+
+			err := g.Reset(ctx, repository, branchID)
+
+			// verify we got an error
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Reset err=%v, expected=%v", err, tt.err)
+			}
+			var hookErr *graveler.HookAbortError
+			if err != nil && !errors.As(err, &hookErr) {
+				t.Fatalf("Reset err=%v, expected HookAbortError", err)
+			}
+
+			// verify that calls made until the first error
+			if tt.hook != h.Called {
+				t.Fatalf("Pre-reset hook h.Called=%t, expected=%t", h.Called, tt.hook)
+			}
+			if !h.Called {
+				return
+			}
+			if h.RepositoryID != repository.RepositoryID {
+				t.Errorf("Hook repository '%s', expected '%s'", h.RepositoryID, repository.RepositoryID)
+			}
+			if h.BranchID != branchID {
+				t.Errorf("Hook branch ID '%s', expected '%s'", h.BranchID, branchID)
+			}
+			if h.ObjectKey != nil {
+				t.Errorf("Hook object key '%s', expected none", h.ObjectKey)
+			}
+		})
+	}
+}
+
+func TestGraveler_CommitOperationsHooks(t *testing.T) {
+	// prepare graveler
+	const expectedRangeID = graveler.MetaRangeID("expectedRangeID")
+	const expectedCommitID = graveler.CommitID("expectedCommitId")
+	const parentCommitID = graveler.CommitID("parentCommitId")
+	const branchID = graveler.BranchID("branch")
+	errSomethingBad := errors.New("first error")
+	commitParams := graveler.CommitParams{Committer: "committer", Message: "message", Metadata: graveler.Metadata{}}
+	tests := []struct {
+		name           string
+		err            error
+		expectedEvents []graveler.EventType
+		operation      func(ctx context.Context, g catalog.Store) error
+	}{
+		{
+			name: "import",
+			expectedEvents: []graveler.EventType{
+				graveler.EventTypePreCommit, graveler.EventTypePreImport, graveler.EventTypePostCommit, graveler.EventTypePostImport,
+			},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.Import(ctx, repository, branchID, expectedRangeID, commitParams, nil)
+				return err
+			},
+		},
+		{
+			name:           "import pre-commit error",
+			err:            errSomethingBad,
+			expectedEvents: []graveler.EventType{graveler.EventTypePreCommit},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.Import(ctx, repository, branchID, expectedRangeID, commitParams, nil)
+				return err
+			},
+		},
+		{
+			name:           "revert",
+			expectedEvents: []graveler.EventType{graveler.EventTypePreRevert, graveler.EventTypePostRevert},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.Revert(ctx, repository, branchID, expectedCommitID.Ref(), 0, commitParams)
+				return err
+			},
+		},
+		{
+			name:           "revert error",
+			err:            errSomethingBad,
+			expectedEvents: []graveler.EventType{graveler.EventTypePreRevert},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.Revert(ctx, repository, branchID, expectedCommitID.Ref(), 0, commitParams)
+				return err
+			},
+		},
+		{
+			name:           "cherry-pick",
+			expectedEvents: []graveler.EventType{graveler.EventTypePreCherryPick, graveler.EventTypePostCherryPick},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.CherryPick(ctx, repository, branchID, expectedCommitID.Ref(), nil, "committer")
+				return err
+			},
+		},
+		{
+			name:           "cherry-pick error",
+			err:            errSomethingBad,
+			expectedEvents: []graveler.EventType{graveler.EventTypePreCherryPick},
+			operation: func(ctx context.Context, g catalog.Store) error {
+				_, err := g.CherryPick(ctx, repository, branchID, expectedCommitID.Ref(), nil, "committer")
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			ctx := context.Background()
+			committedManager := &testutil.CommittedFake{MetaRangeID: expectedRangeID}
+			stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(nil)}
+			refManager := &testutil.RefsFake{
+				CommitID: expectedCommitID,
+				Branch:   &graveler.Branch{CommitID: expectedCommitID, StagingToken: "token"},
+				Commits: map[graveler.CommitID]*graveler.Commit{
+					expectedCommitID: {MetaRangeID: expectedRangeID, Parents: graveler.CommitParents{parentCommitID}},
+					parentCommitID:   {MetaRangeID: expectedRangeID},
+				},
+			}
+			g := newGraveler(t, committedManager, stagingManager, refManager, nil, testutil.NewProtectedBranchesManagerFake())
+			h := &Hooks{Err: tt.err}
+			g.SetHooksHandler(h)
+
+			err := tt.operation(ctx, g)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err=%v, expected=%v", err, tt.err)
+			}
+			if diff := deep.Equal(h.Events, tt.expectedEvents); diff != nil {
+				t.Fatal("Hook events diff:", diff)
+			}
+		})
+	}
+}
+
 func TestGraveler_SetAddressToken(t *testing.T) {
 	gravel := newGraveler(t, nil, nil, &testutil.RefsFake{}, nil, nil)
 	err := gravel.SetLinkAddress(context.Background(), repository, "data/a")
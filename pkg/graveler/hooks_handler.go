@@ -10,18 +10,32 @@ import (
 type EventType string
 
 const (
-	EventTypePreCommit        EventType = "pre-commit"
-	EventTypePostCommit       EventType = "post-commit"
-	EventTypePreMerge         EventType = "pre-merge"
-	EventTypePostMerge        EventType = "post-merge"
-	EventTypePreCreateTag     EventType = "pre-create-tag"
-	EventTypePostCreateTag    EventType = "post-create-tag"
-	EventTypePreDeleteTag     EventType = "pre-delete-tag"
-	EventTypePostDeleteTag    EventType = "post-delete-tag"
-	EventTypePreCreateBranch  EventType = "pre-create-branch"
-	EventTypePostCreateBranch EventType = "post-create-branch"
-	EventTypePreDeleteBranch  EventType = "pre-delete-branch"
-	EventTypePostDeleteBranch EventType = "post-delete-branch"
+	EventTypePreCommit            EventType = "pre-commit"
+	EventTypePostCommit           EventType = "post-commit"
+	EventTypePreMerge             EventType = "pre-merge"
+	EventTypePostMerge            EventType = "post-merge"
+	EventTypePreCreateTag         EventType = "pre-create-tag"
+	EventTypePostCreateTag        EventType = "post-create-tag"
+	EventTypePreDeleteTag         EventType = "pre-delete-tag"
+	EventTypePostDeleteTag        EventType = "post-delete-tag"
+	EventTypePreCreateBranch      EventType = "pre-create-branch"
+	EventTypePostCreateBranch     EventType = "post-create-branch"
+	EventTypePreDeleteBranch      EventType = "pre-delete-branch"
+	EventTypePostDeleteBranch     EventType = "post-delete-branch"
+	EventTypePreRevert            EventType = "pre-revert"
+	EventTypePostRevert           EventType = "post-revert"
+	EventTypePreCherryPick        EventType = "pre-cherry-pick"
+	EventTypePostCherryPick       EventType = "post-cherry-pick"
+	EventTypePreReset             EventType = "pre-reset"
+	EventTypePostReset            EventType = "post-reset"
+	EventTypePreImport            EventType = "pre-import"
+	EventTypePostImport           EventType = "post-import"
+	EventTypePreCreateRepository  EventType = "pre-create-repository"
+	EventTypePostCreateRepository EventType = "post-create-repository"
+	EventTypePreDeleteRepository  EventType = "pre-delete-repository"
+	EventTypePostDeleteRepository EventType = "post-delete-repository"
+	EventTypePrePutObject         EventType = "pre-put-object"
+	EventTypePreDeleteObject      EventType = "pre-delete-object"
 
 	RunIDTimeLayout = "20060102150405"
 	UnixYear3000    = 32500915200
@@ -34,12 +48,13 @@ type HookRecord struct {
 	EventType        EventType
 	RepositoryID     RepositoryID
 	StorageNamespace StorageNamespace
-	// The reference which the actions files are read from
+	// The reference which the actions files are read from. Empty when there is no reference to read from, as in
+	// pre-create-repository and post-delete-repository
 	SourceRef Ref
 	// Event specific fields:
 	// Relevant for all event types except tags. For merge events this will be the ID of the destination branch
 	BranchID BranchID
-	// Relevant only for commit and merge events. In both it will contain the new commit data created from the operation
+	// Relevant only for commit, merge, revert, cherry-pick and import events. It will contain the new commit data created from the operation
 	Commit Commit
 	// Not relevant in delete branch. In commit and merge will not exist in pre-action. In post actions will contain the new commit ID
 	CommitID CommitID
@@ -47,6 +62,10 @@ type HookRecord struct {
 	PreRunID string
 	// Exists only in tag actions.
 	TagID TagID
+	// Exists only in revert and cherry-pick actions. The commit that was reverted or cherry-picked
+	OriginCommitID CommitID
+	// Exists only in object actions, and in reset actions of a single object or of a prefix
+	ObjectKey Key
 }
 
 type HooksHandler interface {
@@ -62,6 +81,20 @@ type HooksHandler interface {
 	PostCreateBranchHook(ctx context.Context, record HookRecord)
 	PreDeleteBranchHook(ctx context.Context, record HookRecord) error
 	PostDeleteBranchHook(ctx context.Context, record HookRecord)
+	PreRevertHook(ctx context.Context, record HookRecord) error
+	PostRevertHook(ctx context.Context, record HookRecord) error
+	PreCherryPickHook(ctx context.Context, record HookRecord) error
+	PostCherryPickHook(ctx context.Context, record HookRecord) error
+	PreResetHook(ctx context.Context, record HookRecord) error
+	PostResetHook(ctx context.Context, record HookRecord)
+	PreImportHook(ctx context.Context, record HookRecord) error
+	PostImportHook(ctx context.Context, record HookRecord) error
+	PreCreateRepositoryHook(ctx context.Context, record HookRecord) error
+	PostCreateRepositoryHook(ctx context.Context, record HookRecord)
+	PreDeleteRepositoryHook(ctx context.Context, record HookRecord) error
+	PostDeleteRepositoryHook(ctx context.Context, record HookRecord)
+	PrePutObjectHook(ctx context.Context, record HookRecord) error
+	PreDeleteObjectHook(ctx context.Context, record HookRecord) error
 	// NewRunID TODO (niro): WA for now until KV feature complete
 	NewRunID() string
 }
@@ -112,6 +145,59 @@ func (h *HooksNoOp) PreDeleteBranchHook(context.Context, HookRecord) error {
 func (h *HooksNoOp) PostDeleteBranchHook(context.Context, HookRecord) {
 }
 
+func (h *HooksNoOp) PreRevertHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostRevertHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PreCherryPickHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostCherryPickHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PreResetHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostResetHook(context.Context, HookRecord) {
+}
+
+func (h *HooksNoOp) PreImportHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostImportHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PreCreateRepositoryHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostCreateRepositoryHook(context.Context, HookRecord) {
+}
+
+func (h *HooksNoOp) PreDeleteRepositoryHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PostDeleteRepositoryHook(context.Context, HookRecord) {
+}
+
+func (h *HooksNoOp) PrePutObjectHook(context.Context, HookRecord) error {
+	return nil
+}
+
+func (h *HooksNoOp) PreDeleteObjectHook(context.Context, HookRecord) error {
+	return nil
+}
+
 func (h *HooksNoOp) NewRunID() string {
 	return NewRunID()
 }
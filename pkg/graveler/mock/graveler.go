@@ -129,18 +129,18 @@ func (mr *MockKeyValueStoreMockRecorder) List(ctx, repository, ref, batchSize in
 }
 
 // ListStaging mocks base method.
-func (m *MockKeyValueStore) ListStaging(ctx context.Context, branch *graveler.Branch, batchSize int) (graveler.ValueIterator, error) {
+func (m *MockKeyValueStore) ListStaging(ctx context.Context, repository *graveler.RepositoryRecord, branch *graveler.Branch, batchSize int) (graveler.ValueIterator, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListStaging", ctx, branch, batchSize)
+	ret := m.ctrl.Call(m, "ListStaging", ctx, repository, branch, batchSize)
 	ret0, _ := ret[0].(graveler.ValueIterator)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListStaging indicates an expected call of ListStaging.
-func (mr *MockKeyValueStoreMockRecorder) ListStaging(ctx, branch, batchSize interface{}) *gomock.Call {
+func (mr *MockKeyValueStoreMockRecorder) ListStaging(ctx, repository, branch, batchSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStaging", reflect.TypeOf((*MockKeyValueStore)(nil).ListStaging), ctx, branch, batchSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStaging", reflect.TypeOf((*MockKeyValueStore)(nil).ListStaging), ctx, repository, branch, batchSize)
 }
 
 // Set mocks base method.
@@ -748,6 +748,21 @@ func (mr *MockVersionControllerMockRecorder) ParseRef(ref interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseRef", reflect.TypeOf((*MockVersionController)(nil).ParseRef), ref)
 }
 
+// Rebalance mocks base method.
+func (m *MockVersionController) Rebalance(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, commitParams graveler.CommitParams) (graveler.CommitID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebalance", ctx, repository, branchID, commitParams)
+	ret0, _ := ret[0].(graveler.CommitID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rebalance indicates an expected call of Rebalance.
+func (mr *MockVersionControllerMockRecorder) Rebalance(ctx, repository, branchID, commitParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebalance", reflect.TypeOf((*MockVersionController)(nil).Rebalance), ctx, repository, branchID, commitParams)
+}
+
 // Reset mocks base method.
 func (m *MockVersionController) Reset(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) error {
 	m.ctrl.T.Helper()
@@ -942,6 +957,21 @@ func (m *MockPlumbing) EXPECT() *MockPlumbingMockRecorder {
 	return m.recorder
 }
 
+// DiffMetaRanges mocks base method.
+func (m *MockPlumbing) DiffMetaRanges(ctx context.Context, repository *graveler.RepositoryRecord, left, right graveler.MetaRangeID) (graveler.DiffIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffMetaRanges", ctx, repository, left, right)
+	ret0, _ := ret[0].(graveler.DiffIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffMetaRanges indicates an expected call of DiffMetaRanges.
+func (mr *MockPlumbingMockRecorder) DiffMetaRanges(ctx, repository, left, right interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffMetaRanges", reflect.TypeOf((*MockPlumbing)(nil).DiffMetaRanges), ctx, repository, left, right)
+}
+
 // GetMetaRange mocks base method.
 func (m *MockPlumbing) GetMetaRange(ctx context.Context, repository *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID) (graveler.MetaRangeAddress, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,92 @@
+package graveler
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rebalance writes the meta range of the branch commit again from its entries, so its ranges are split by the current
+// range size settings instead of the boundaries left by incremental commits. The new meta range is committed on the
+// branch as a child of its commit, with an identical tree. Staged and compacted changes of the branch are kept.
+// Hooks are not run, as the commit changes no entries. Returns ErrNoChanges when the ranges are already balanced, and
+// ErrRebalanceBranchMoved when the branch commit changed while its meta range was rewritten.
+func (g *Graveler) Rebalance(ctx context.Context, repository *RepositoryRecord, branchID BranchID, params CommitParams) (CommitID, error) {
+	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
+	if err != nil {
+		return "", err
+	}
+	if isProtected {
+		return "", ErrCommitToProtectedBranch
+	}
+
+	branch, err := g.RefManager.GetBranch(ctx, repository, branchID)
+	if err != nil {
+		return "", err
+	}
+	if branch.CommitID == "" {
+		return "", ErrNoChanges
+	}
+	baseCommitID := branch.CommitID
+	branchCommit, err := g.RefManager.GetCommit(ctx, repository, baseCommitID)
+	if err != nil {
+		return "", fmt.Errorf("get commit: %w", err)
+	}
+	if branchCommit.MetaRangeID == "" {
+		return "", ErrNoChanges
+	}
+
+	// Rewriting reads and writes every range of the commit, so it is done once, before the branch update. The
+	// update only checks that the branch still points at the rewritten commit.
+	metaRangeID, err := g.rewriteMetaRange(ctx, repository, branchCommit.MetaRangeID)
+	if err != nil {
+		return "", err
+	}
+	if metaRangeID == branchCommit.MetaRangeID {
+		return "", ErrNoChanges
+	}
+
+	commit := NewCommit()
+	if params.Date != nil {
+		commit.CreationDate = time.Unix(*params.Date, 0)
+	}
+	commit.Committer = params.Committer
+	commit.Message = params.Message
+	commit.Metadata = params.Metadata
+	commit.MetaRangeID = metaRangeID
+	commit.Parents = CommitParents{baseCommitID}
+	commit.Generation = branchCommit.Generation + 1
+	newCommitID, err := g.RefManager.AddCommit(ctx, repository, commit)
+	if err != nil {
+		return "", fmt.Errorf("add commit: %w", err)
+	}
+
+	err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+		if branch.CommitID != baseCommitID {
+			return nil, ErrRebalanceBranchMoved
+		}
+		branch.CommitID = newCommitID
+		if branch.CompactedBaseMetaRangeID == metaRangeID {
+			branch.CompactedBaseMetaRangeID = ""
+		}
+		return branch, nil
+	}, "rebalance")
+	if err != nil {
+		return "", err
+	}
+	return newCommitID, nil
+}
+
+// rewriteMetaRange writes the entries of metaRangeID to a new meta range and returns its ID
+func (g *Graveler) rewriteMetaRange(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) (MetaRangeID, error) {
+	it, err := g.CommittedManager.List(ctx, repository.StorageNamespace, metaRangeID)
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+	newMetaRangeID, err := g.CommittedManager.WriteMetaRangeByIterator(ctx, repository.StorageNamespace, it, nil)
+	if err != nil {
+		return "", fmt.Errorf("rewrite meta range %s: %w", metaRangeID, err)
+	}
+	return *newMetaRangeID, nil
+}
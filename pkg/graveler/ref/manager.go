@@ -56,9 +56,10 @@ func branchFromProto(pb *graveler.BranchData) *graveler.Branch {
 		sealedTokens = append(sealedTokens, graveler.StagingToken(st))
 	}
 	branch := &graveler.Branch{
-		CommitID:     graveler.CommitID(pb.CommitId),
-		StagingToken: graveler.StagingToken(pb.StagingToken),
-		SealedTokens: sealedTokens,
+		CommitID:                 graveler.CommitID(pb.CommitId),
+		StagingToken:             graveler.StagingToken(pb.StagingToken),
+		SealedTokens:             sealedTokens,
+		CompactedBaseMetaRangeID: graveler.MetaRangeID(pb.CompactedBaseMetaRangeId),
 	}
 	return branch
 }
@@ -69,10 +70,11 @@ func protoFromBranch(branchID graveler.BranchID, b *graveler.Branch) *graveler.B
 		sealedTokens = append(sealedTokens, st.String())
 	}
 	branch := &graveler.BranchData{
-		Id:           branchID.String(),
-		CommitId:     b.CommitID.String(),
-		StagingToken: b.StagingToken.String(),
-		SealedTokens: sealedTokens,
+		Id:                       branchID.String(),
+		CommitId:                 b.CommitID.String(),
+		StagingToken:             b.StagingToken.String(),
+		SealedTokens:             sealedTokens,
+		CompactedBaseMetaRangeId: string(b.CompactedBaseMetaRangeID),
 	}
 	return branch
 }
@@ -400,7 +402,10 @@ func (m *Manager) BranchUpdate(ctx context.Context, repository *graveler.Reposit
 	if err != nil {
 		return err
 	}
-	newBranch, err := f(b)
+	// the branch read may be shared by concurrent callers, update a copy of it
+	branch := *b
+	branch.SealedTokens = append([]graveler.StagingToken(nil), b.SealedTokens...)
+	newBranch, err := f(&branch)
 	// return on error or nothing to update
 	if err != nil || newBranch == nil {
 		return err
@@ -511,8 +516,7 @@ func (m *Manager) GetCommitByPrefix(ctx context.Context, repository *graveler.Re
 }
 
 func (m *Manager) GetCommit(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.Commit, error) {
-	key := fmt.Sprintf("%s:%s", repository.RepositoryID, commitID)
-	v, err := m.commitCache.GetOrSet(key, func() (v interface{}, err error) {
+	v, err := m.commitCache.GetOrSet(commitCacheKey(repository, commitID), func() (v interface{}, err error) {
 		return m.getCommitBatch(ctx, repository, commitID)
 	})
 	if err != nil {
@@ -521,6 +525,10 @@ func (m *Manager) GetCommit(ctx context.Context, repository *graveler.Repository
 	return v.(*graveler.Commit), nil
 }
 
+func commitCacheKey(repository *graveler.RepositoryRecord, commitID graveler.CommitID) string {
+	return fmt.Sprintf("%s:%s", repository.RepositoryID, commitID)
+}
+
 func (m *Manager) getCommitBatch(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.Commit, error) {
 	key := fmt.Sprintf("GetCommit:%s:%s", repository.RepositoryID, commitID)
 	commit, err := m.batchExecutor.BatchFor(ctx, key, MaxBatchDelay, batch.ExecuterFunc(func() (interface{}, error) {
@@ -564,7 +572,12 @@ func (m *Manager) AddCommit(ctx context.Context, repository *graveler.Repository
 
 func (m *Manager) RemoveCommit(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) error {
 	commitKey := graveler.CommitPath(commitID)
-	return m.kvStore.Delete(ctx, []byte(graveler.RepoPartition(repository)), []byte(commitKey))
+	err := m.kvStore.Delete(ctx, []byte(graveler.RepoPartition(repository)), []byte(commitKey))
+	if err != nil {
+		return err
+	}
+	m.commitCache.Remove(commitCacheKey(repository, commitID))
+	return nil
 }
 
 func (m *Manager) FindMergeBase(ctx context.Context, repository *graveler.RepositoryRecord, commitIDs ...graveler.CommitID) (*graveler.Commit, error) {
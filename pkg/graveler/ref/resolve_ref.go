@@ -82,9 +82,10 @@ func ResolveRawRef(ctx context.Context, store Store, addressProvider ident.Addre
 				BranchRecord: graveler.BranchRecord{
 					BranchID: rr.BranchID,
 					Branch: &graveler.Branch{
-						CommitID:     rr.CommitID,
-						StagingToken: rr.StagingToken,
-						SealedTokens: rr.SealedTokens,
+						CommitID:                 rr.CommitID,
+						StagingToken:             rr.StagingToken,
+						SealedTokens:             rr.SealedTokens,
+						CompactedBaseMetaRangeID: rr.CompactedBaseMetaRangeID,
 					},
 				},
 			}, nil
@@ -190,9 +191,10 @@ func revResolveBranch(ctx context.Context, store Store, _ ident.AddressProvider,
 		BranchRecord: graveler.BranchRecord{
 			BranchID: branchID,
 			Branch: &graveler.Branch{
-				CommitID:     branch.CommitID,
-				StagingToken: branch.StagingToken,
-				SealedTokens: branch.SealedTokens,
+				CommitID:                 branch.CommitID,
+				StagingToken:             branch.StagingToken,
+				SealedTokens:             branch.SealedTokens,
+				CompactedBaseMetaRangeID: branch.CompactedBaseMetaRangeID,
 			},
 		},
 	}, nil
@@ -0,0 +1,42 @@
+package ref
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// WatchChanges invalidates cached repositories when they change, until ctx is done. Cached commits are invalidated
+// when a repository is deleted. Without change events from the kv store, cached entries expire by their TTL.
+func (m *Manager) WatchChanges(ctx context.Context) {
+	log := logging.FromContext(ctx).WithField("service", "ref_manager")
+	reposPrefix := []byte(graveler.RepoPath(""))
+	events, err := kv.Watch(ctx, m.kvStore, []byte(graveler.RepositoriesPartition()), reposPrefix)
+	if errors.Is(err, kv.ErrWatchNotSupported) {
+		log.Info("KV store does not report changes, repository and commit cache entries expire by TTL")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to watch repository changes, cache entries expire by TTL")
+		return
+	}
+	go func() {
+		for ev := range events {
+			switch ev.Type {
+			case kv.EventReset:
+				m.repoCache.Purge()
+				m.commitCache.Purge()
+			case kv.EventDelete:
+				m.repoCache.Remove(graveler.RepositoryID(bytes.TrimPrefix(ev.Key, reposPrefix)))
+				// a repository created with the same name must not see the commits of the deleted one
+				m.commitCache.Purge()
+			default:
+				m.repoCache.Remove(graveler.RepositoryID(bytes.TrimPrefix(ev.Key, reposPrefix)))
+			}
+		}
+	}()
+}
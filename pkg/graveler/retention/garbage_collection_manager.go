@@ -28,10 +28,15 @@ const (
 	unixYear4000 = 64060588800
 )
 
+// RetainedCommitsFunc returns commits of a repository that should never be expired, regardless of the garbage
+// collection rules - for example, commits whose objects are referenced by another repository.
+type RetainedCommitsFunc func(ctx context.Context, repository *graveler.RepositoryRecord) ([]graveler.CommitID, error)
+
 type GarbageCollectionManager struct {
 	blockAdapter                block.Adapter
 	refManager                  graveler.RefManager
 	committedBlockStoragePrefix string
+	retainedCommits             RetainedCommitsFunc
 }
 
 func (m *GarbageCollectionManager) GetCommitsCSVLocation(runID string, sn graveler.StorageNamespace) (string, error) {
@@ -96,6 +101,36 @@ func (r *RepositoryCommitGetter) ListCommits(ctx context.Context) (graveler.Comm
 	return r.refManager.ListCommits(ctx, r.repository)
 }
 
+// retainCommits marks the retained commits of the repository as active
+func (m *GarbageCollectionManager) retainCommits(ctx context.Context, repository *graveler.RepositoryRecord, gcCommits *GarbageCollectionCommits) error {
+	if m.retainedCommits == nil {
+		return nil
+	}
+	retained, err := m.retainedCommits(ctx, repository)
+	if err != nil {
+		return err
+	}
+	for _, commitID := range retained {
+		if _, ok := gcCommits.active[commitID]; ok {
+			continue
+		}
+		if metaRangeID, ok := gcCommits.expired[commitID]; ok {
+			delete(gcCommits.expired, commitID)
+			gcCommits.active[commitID] = metaRangeID
+			continue
+		}
+		commit, err := m.refManager.GetCommit(ctx, repository, commitID)
+		if errors.Is(err, graveler.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		gcCommits.active[commitID] = commit.MetaRangeID
+	}
+	return nil
+}
+
 func NewGarbageCollectionManager(blockAdapter block.Adapter, refManager graveler.RefManager, committedBlockStoragePrefix string) *GarbageCollectionManager {
 	return &GarbageCollectionManager{
 		blockAdapter:                blockAdapter,
@@ -104,6 +139,11 @@ func NewGarbageCollectionManager(blockAdapter block.Adapter, refManager graveler
 	}
 }
 
+// SetRetainedCommitsFunc sets the function used to find commits that are kept active by garbage collection
+func (m *GarbageCollectionManager) SetRetainedCommitsFunc(f RetainedCommitsFunc) {
+	m.retainedCommits = f
+}
+
 func (m *GarbageCollectionManager) GetRules(ctx context.Context, storageNamespace graveler.StorageNamespace) (*graveler.GarbageCollectionRules, error) {
 	objectPointer := block.ObjectPointer{
 		StorageNamespace: string(storageNamespace),
@@ -204,6 +244,9 @@ func (m *GarbageCollectionManager) SaveGarbageCollectionCommits(ctx context.Cont
 	if err != nil {
 		return "", fmt.Errorf("find expired commits: %w", err)
 	}
+	if err := m.retainCommits(ctx, repository, gcCommits); err != nil {
+		return "", fmt.Errorf("retain commits: %w", err)
+	}
 	b := &strings.Builder{}
 	csvWriter := csv.NewWriter(b)
 	headers := []string{"commit_id", "expired", "metarange_id"}
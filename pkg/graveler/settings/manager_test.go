@@ -45,6 +45,14 @@ func (m *mockCache) GetOrSet(k interface{}, setFn cache.SetFn) (v interface{}, e
 	return val, nil
 }
 
+func (m *mockCache) Remove(k interface{}) {
+	delete(m.c, k)
+}
+
+func (m *mockCache) Purge() {
+	m.c = make(map[interface{}]interface{})
+}
+
 func TestSaveAndGet(t *testing.T) {
 	ctx := context.Background()
 	mc := &mockCache{
@@ -0,0 +1,99 @@
+package sstable
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/pebble/sstable"
+)
+
+const (
+	MetadataCompressionKey          = "compression"
+	MetadataBlockSizeKey            = "block_size"
+	MetadataBlockRestartIntervalKey = "block_restart_interval"
+)
+
+// pebble defaults, recorded in the properties of sstables written with zero FormatParams
+const (
+	defaultBlockSize            = 4096
+	defaultBlockRestartInterval = 16
+)
+
+var ErrUnknownCompression = errors.New("unknown sstable compression")
+
+var compressionNames = map[string]sstable.Compression{
+	"none":   sstable.NoCompression,
+	"snappy": sstable.SnappyCompression,
+	"zstd":   sstable.ZstdCompression,
+}
+
+// FormatParams configures the layout of sstables written by DiskWriter.  Readers do not
+// depend on it: every block records its compression, so sstables written with different
+// params can be read together.  The zero value uses the pebble defaults.
+type FormatParams struct {
+	// Compression of data and index blocks.
+	Compression sstable.Compression
+	// BlockSize is the target uncompressed size in bytes of each block.
+	BlockSize int
+	// BlockRestartInterval is the number of keys between restart points of each block.
+	BlockRestartInterval int
+}
+
+// NewFormatParams returns the FormatParams of the named compression, block size and block
+// restart interval.  Zero sizes keep the pebble defaults.
+func NewFormatParams(compression string, blockSize, blockRestartInterval int) (FormatParams, error) {
+	c, ok := compressionNames[strings.ToLower(compression)]
+	if !ok {
+		return FormatParams{}, fmt.Errorf("%w: %s", ErrUnknownCompression, compression)
+	}
+	return FormatParams{
+		Compression:          c,
+		BlockSize:            blockSize,
+		BlockRestartInterval: blockRestartInterval,
+	}, nil
+}
+
+// writerOptions returns the pebble options of a writer in the format, with the properties
+// recording the format
+func (p FormatParams) writerOptions() (sstable.WriterOptions, map[string]string) {
+	opts := sstable.WriterOptions{
+		Compression:          p.Compression,
+		BlockSize:            p.BlockSize,
+		BlockRestartInterval: p.BlockRestartInterval,
+		FilterPolicy:         filterPolicy,
+		FilterType:           sstable.TableFilter,
+	}
+	if opts.Compression == sstable.DefaultCompression {
+		opts.Compression = sstable.SnappyCompression
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = defaultBlockSize
+	}
+	if opts.BlockRestartInterval <= 0 {
+		opts.BlockRestartInterval = defaultBlockRestartInterval
+	}
+	// lakeFS versions before zstd support cannot decompress zstd blocks, a newer format version
+	// makes them refuse to read these sstables instead.
+	formatVersion := FormatVersionBloomFilter
+	if opts.Compression == sstable.ZstdCompression {
+		formatVersion = FormatVersionZstd
+	}
+	props := map[string]string{
+		MetadataFormatVersionKey:        strconv.Itoa(formatVersion),
+		MetadataCompressionKey:          compressionName(opts.Compression),
+		MetadataBlockSizeKey:            strconv.Itoa(opts.BlockSize),
+		MetadataBlockRestartIntervalKey: strconv.Itoa(opts.BlockRestartInterval),
+	}
+	return opts, props
+}
+
+func compressionName(c sstable.Compression) string {
+	for name, compression := range compressionNames {
+		if compression == c {
+			return name
+		}
+	}
+	return c.String()
+}
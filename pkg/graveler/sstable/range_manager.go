@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"time"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/sstable"
+	"github.com/treeverse/lakefs/pkg/cache"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
 	"github.com/treeverse/lakefs/pkg/logging"
@@ -20,22 +25,52 @@ type Unrefer interface {
 	Unref()
 }
 
+const (
+	// filterCacheExpiry is long: sstables and their filters are immutable
+	filterCacheExpiry = time.Hour
+	filterCacheJitter = time.Minute
+)
+
 type RangeManager struct {
 	newReader NewSSTableReaderFn
 	fs        pyramid.FS
 	hash      crypto.Hash
 	cache     Unrefer
+	format    FormatParams
+	// filters caches the Bloom filter objects of sstables, nil unless filter objects are used
+	filters cache.Cache
 }
 
-func NewPebbleSSTableRangeManager(cache *pebble.Cache, fs pyramid.FS, hash crypto.Hash) *RangeManager {
+type RangeManagerOption func(m *RangeManager)
+
+// WithFilterObjects writes the Bloom filter of every sstable to a separate object, and
+// checks it on GetValue before fetching the sstable.  Up to cacheSize filters are kept in
+// memory.
+func WithFilterObjects(cacheSize int) RangeManagerOption {
+	return func(m *RangeManager) {
+		m.filters = cache.NewCache(cacheSize, filterCacheExpiry, cache.NewJitterFn(filterCacheJitter))
+	}
+}
+
+type filterCacheKey struct {
+	ns committed.Namespace
+	id committed.ID
+}
+
+func NewPebbleSSTableRangeManager(cache *pebble.Cache, fs pyramid.FS, hash crypto.Hash, format FormatParams, opts ...RangeManagerOption) *RangeManager {
 	if cache != nil { // nil cache allowed (size=0), see sstable.ReaderOptions
 		cache.Ref()
 	}
-	opts := sstable.ReaderOptions{Cache: cache}
+	readerOpts := sstable.ReaderOptions{
+		Cache:   cache,
+		Filters: map[string]sstable.FilterPolicy{filterPolicy.Name(): filterPolicy},
+	}
 	newReader := func(ctx context.Context, ns committed.Namespace, id committed.ID) (*sstable.Reader, error) {
-		return newReader(ctx, fs, ns, id, opts)
+		return newReader(ctx, fs, ns, id, readerOpts)
 	}
-	return NewPebbleSSTableRangeManagerWithNewReader(newReader, opts.Cache, fs, hash)
+	m := NewPebbleSSTableRangeManagerWithNewReader(newReader, readerOpts.Cache, fs, hash, opts...)
+	m.format = format
+	return m
 }
 
 func newReader(ctx context.Context, fs pyramid.FS, ns committed.Namespace, id committed.ID, opts sstable.ReaderOptions) (*sstable.Reader, error) {
@@ -47,22 +82,53 @@ func newReader(ctx context.Context, fs pyramid.FS, ns committed.Namespace, id co
 	if err != nil {
 		return nil, fmt.Errorf("open sstable reader %s %s: %w", ns, id, err)
 	}
+	if err := checkFormatVersion(r); err != nil {
+		_ = r.Close()
+		return nil, fmt.Errorf("open sstable reader %s %s: %w", ns, id, err)
+	}
 	return r, nil
 }
 
-func NewPebbleSSTableRangeManagerWithNewReader(newReader NewSSTableReaderFn, cache Unrefer, fs pyramid.FS, hash crypto.Hash) *RangeManager {
-	return &RangeManager{
+// checkFormatVersion verifies that the sstable can be read by this version of lakeFS
+func checkFormatVersion(r *sstable.Reader) error {
+	v, ok := r.Properties.UserProperties[MetadataFormatVersionKey]
+	if !ok {
+		// written before format versions were introduced
+		return nil
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormatVersion, v)
+	}
+	if version < FormatVersionInitial || version > FormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedFormatVersion, version)
+	}
+	return nil
+}
+
+func NewPebbleSSTableRangeManagerWithNewReader(newReader NewSSTableReaderFn, cache Unrefer, fs pyramid.FS, hash crypto.Hash, opts ...RangeManagerOption) *RangeManager {
+	m := &RangeManager{
 		fs:        fs,
 		hash:      hash,
 		newReader: newReader,
 		cache:     cache,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 var (
 	// ErrKeyNotFound is the error returned when a path is not found
 	ErrKeyNotFound = fmt.Errorf("key: %w", committed.ErrNotFound)
 
+	// ErrRewriteMismatch is the error returned when a rewritten sstable does not keep its ID
+	ErrRewriteMismatch = errors.New("rewritten sstable changed its ID")
+
+	// ErrUnsupportedFormatVersion is the error returned when reading an sstable written in a newer format
+	ErrUnsupportedFormatVersion = errors.New("unsupported sstable format version")
+
 	_ committed.RangeManager = &RangeManager{}
 )
 
@@ -106,6 +172,14 @@ func (m *RangeManager) GetValueGE(ctx context.Context, ns committed.Namespace, i
 // GetValue returns the Record matching the key in the SSTable referenced by the id.
 // If key is not found, (nil, ErrKeyNotFound) is returned.
 func (m *RangeManager) GetValue(ctx context.Context, ns committed.Namespace, id committed.ID, lookup committed.Key) (*committed.Record, error) {
+	mayContain, err := m.filterMayContain(ctx, ns, id, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if !mayContain {
+		return nil, ErrKeyNotFound
+	}
+
 	reader, err := m.newReader(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -118,14 +192,15 @@ func (m *RangeManager) GetValue(ctx context.Context, ns committed.Namespace, id
 	}
 	defer m.execAndLog(ctx, it.Close, "close iterator")
 
-	// actual reading
-	key, value := it.SeekGE(lookup, sstable.SeekGEFlags(0))
+	// actual reading - the Bloom filter of the sstable (when it has one) skips reading data
+	// blocks for most missing keys
+	key, value := it.SeekPrefixGE(lookup, lookup, sstable.SeekGEFlags(0))
 	if key == nil {
 		if it.Error() != nil {
 			return nil, fmt.Errorf("read key from sstable id %s: %w", id, it.Error())
 		}
 
-		// lookup path is after the last path in the SSTable
+		// lookup path is after the last path in the SSTable, or not in its filter
 		return nil, ErrKeyNotFound
 	}
 
@@ -145,6 +220,60 @@ func (m *RangeManager) GetValue(ctx context.Context, ns committed.Namespace, id
 	}, nil
 }
 
+// filterMayContain returns false if the filter object of the sstable referenced by id shows
+// that it does not hold key.  Sstables without a filter object may contain any key.
+func (m *RangeManager) filterMayContain(ctx context.Context, ns committed.Namespace, id committed.ID, key committed.Key) (bool, error) {
+	if m.filters == nil {
+		return true, nil
+	}
+	v, err := m.filters.GetOrSet(filterCacheKey{ns: ns, id: id}, func() (interface{}, error) {
+		return m.readFilter(ctx, ns, id)
+	})
+	if err != nil {
+		return false, err
+	}
+	filter := v.([]byte)
+	if len(filter) == 0 {
+		return true, nil
+	}
+	return filterPolicy.MayContain(sstable.TableFilter, filter, key), nil
+}
+
+// readFilter returns the content of the filter object of the sstable referenced by id, or
+// nil if it has none (it was written before filter objects were used).
+func (m *RangeManager) readFilter(ctx context.Context, ns committed.Namespace, id committed.ID) ([]byte, error) {
+	name := filterObjectName(id)
+	exists, err := m.fs.Exists(ctx, string(ns), name)
+	if err != nil {
+		return nil, fmt.Errorf("check filter of sstable %s %s: %w", ns, id, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	f, err := m.fs.Open(ctx, string(ns), name)
+	if err != nil {
+		return nil, fmt.Errorf("open filter of sstable %s %s: %w", ns, id, err)
+	}
+	defer m.execAndLog(ctx, f.Close, "close filter")
+	filter, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read filter of sstable %s %s: %w", ns, id, err)
+	}
+	return filter, nil
+}
+
+// newDiskWriter returns a DiskWriter in the format of the range manager
+func (m *RangeManager) newDiskWriter(ctx context.Context, ns committed.Namespace, metadata graveler.Metadata) (*DiskWriter, error) {
+	writer, err := NewDiskWriter(ctx, m.fs, ns, m.hash.New(), metadata, m.format)
+	if err != nil {
+		return nil, err
+	}
+	if m.filters != nil {
+		writer.WriteFilterObject()
+	}
+	return writer, nil
+}
+
 // NewRangeIterator takes a given SSTable and returns an EntryIterator seeked to >= "from" path
 func (m *RangeManager) NewRangeIterator(ctx context.Context, ns committed.Namespace, tid committed.ID) (committed.ValueIterator, error) {
 	reader, err := m.newReader(ctx, ns, tid)
@@ -165,7 +294,56 @@ func (m *RangeManager) NewRangeIterator(ctx context.Context, ns committed.Namesp
 
 // GetWriter returns a new SSTable writer instance
 func (m *RangeManager) GetWriter(ctx context.Context, ns committed.Namespace, metadata graveler.Metadata) (committed.RangeWriter, error) {
-	return NewDiskWriter(ctx, m.fs, ns, m.hash.New(), metadata)
+	return m.newDiskWriter(ctx, ns, metadata)
+}
+
+// Rewrite writes the sstable referenced by id again, in the format of the range manager.
+// The ID of the sstable is unchanged, it depends only on its records and metadata.
+func (m *RangeManager) Rewrite(ctx context.Context, ns committed.Namespace, id committed.ID) error {
+	reader, err := m.newReader(ctx, ns, id)
+	if err != nil {
+		return err
+	}
+	defer m.execAndLog(ctx, reader.Close, "close reader")
+
+	metadata := make(graveler.Metadata)
+	for k, v := range reader.Properties.UserProperties {
+		if !writerProperties[k] {
+			metadata[k] = v
+		}
+	}
+	writer, err := m.newDiskWriter(ctx, ns, metadata)
+	if err != nil {
+		return err
+	}
+	it, err := reader.NewIter(nil, nil)
+	if err != nil {
+		_ = writer.Abort()
+		return fmt.Errorf("create iterator: %w", err)
+	}
+	defer m.execAndLog(ctx, it.Close, "close iterator")
+	for key, value := it.First(); key != nil; key, value = it.Next() {
+		v, err := retrieveValue(value)
+		if err == nil {
+			err = writer.WriteRecord(committed.Record{Key: key.UserKey, Value: v})
+		}
+		if err != nil {
+			_ = writer.Abort()
+			return fmt.Errorf("rewrite sstable id %s (key %s): %w", id, key, err)
+		}
+	}
+	if err := it.Error(); err != nil {
+		_ = writer.Abort()
+		return fmt.Errorf("read sstable id %s: %w", id, err)
+	}
+	result, err := writer.Close()
+	if err != nil {
+		return err
+	}
+	if result.RangeID != id {
+		return fmt.Errorf("%w: sstable id %s rewritten as %s", ErrRewriteMismatch, id, result.RangeID)
+	}
+	return nil
 }
 
 func (m *RangeManager) GetURI(ctx context.Context, ns committed.Namespace, id committed.ID) (string, error) {
@@ -4,15 +4,21 @@ import (
 	"context"
 	"crypto"
 	"errors"
+	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
+	"sync"
 	"testing"
 
+	"github.com/cockroachdb/pebble"
 	pebblesst "github.com/cockroachdb/pebble/sstable"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
 	"github.com/treeverse/lakefs/pkg/graveler/sstable"
+	"github.com/treeverse/lakefs/pkg/pyramid"
 	fsMock "github.com/treeverse/lakefs/pkg/pyramid/mock"
 )
 
@@ -180,3 +186,261 @@ func TestGetWriterRangeID(t *testing.T) {
 		require.Equal(t, expectedID, result.RangeID, "Range ID should be kept the same based on the content")
 	}
 }
+
+// storedFile is a pyramid.StoredFile on a local file
+type storedFile struct {
+	*os.File
+}
+
+func (f *storedFile) Store(context.Context, string) error {
+	// the sstable writer already closed the file
+	return nil
+}
+
+func (f *storedFile) Abort(context.Context) error {
+	return os.Remove(f.Name())
+}
+
+func writeSSTable(t *testing.T, keys []string, opts pebblesst.WriterOptions) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "sstable")
+	require.NoError(t, err)
+	w := pebblesst.NewWriter(f, opts)
+	for _, key := range keys {
+		require.NoError(t, w.Set([]byte(key), []byte("value-"+key)))
+	}
+	require.NoError(t, w.Close())
+	return f.Name()
+}
+
+func TestRangeManagerFormatVersions(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockFS := fsMock.NewMockFS(ctrl)
+	cache := pebble.NewCache(0)
+	defer cache.Unref()
+	sut := sstable.NewPebbleSSTableRangeManager(cache, mockFS, crypto.SHA256, sstable.FormatParams{})
+	defer func() { _ = sut.Close() }()
+
+	const ns = "some-ns"
+	keys := randomStrings(1000)
+	sort.Strings(keys)
+
+	// current format, written by the range manager
+	f, err := os.CreateTemp(t.TempDir(), "sstable")
+	require.NoError(t, err)
+	mockFS.EXPECT().Create(ctx, ns).Return(&storedFile{File: f}, nil).Times(1)
+	writer, err := sut.GetWriter(ctx, ns, nil)
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, writer.WriteRecord(committed.Record{Key: []byte(key), Value: []byte("value-" + key)}))
+	}
+	_, err = writer.Close()
+	require.NoError(t, err)
+
+	paths := map[committed.ID]string{
+		"current": f.Name(),
+		"initial": writeSSTable(t, keys, pebblesst.WriterOptions{Compression: pebblesst.SnappyCompression}),
+		"future": writeSSTable(t, keys, pebblesst.WriterOptions{
+			TablePropertyCollectors: []func() pebblesst.TablePropertyCollector{
+				sstable.NewStaticCollector(map[string]string{sstable.MetadataFormatVersionKey: strconv.Itoa(sstable.FormatVersion + 1)}),
+			},
+		}),
+	}
+	mockFS.EXPECT().Open(ctx, ns, gomock.Any()).DoAndReturn(func(_ context.Context, _, id string) (pyramid.File, error) {
+		return os.Open(paths[committed.ID(id)])
+	}).AnyTimes()
+
+	// current format holds a Bloom filter
+	r, err := pebblesst.NewMemReader(mustReadFile(t, paths["current"]), pebblesst.ReaderOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "rocksdb.BuiltinBloomFilter", r.Properties.FilterPolicyName)
+	require.Equal(t, strconv.Itoa(sstable.FormatVersionBloomFilter), r.Properties.UserProperties[sstable.MetadataFormatVersionKey])
+	require.NoError(t, r.Close())
+
+	for _, id := range []committed.ID{"current", "initial"} {
+		t.Run(string(id), func(t *testing.T) {
+			for _, key := range keys[:100] {
+				val, err := sut.GetValue(ctx, ns, id, committed.Key(key))
+				require.NoError(t, err)
+				require.Equal(t, "value-"+key, string(val.Value))
+			}
+			for _, key := range randomStrings(100) {
+				_, err := sut.GetValue(ctx, ns, id, committed.Key(key+"-missing"))
+				require.ErrorIs(t, err, sstable.ErrKeyNotFound)
+			}
+		})
+	}
+
+	_, err = sut.GetValue(ctx, ns, "future", committed.Key(keys[0]))
+	require.ErrorIs(t, err, sstable.ErrUnsupportedFormatVersion)
+}
+
+func mustReadFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	return data
+}
+
+// dirFS is a pyramid.FS storing files in a local directory
+type dirFS struct {
+	dir string
+}
+
+type dirFile struct {
+	*os.File
+	dir string
+}
+
+func (f *dirFile) Store(_ context.Context, filename string) error {
+	return os.Rename(f.Name(), filepath.Join(f.dir, filename))
+}
+
+func (f *dirFile) Abort(context.Context) error {
+	return os.Remove(f.Name())
+}
+
+func (d *dirFS) Create(context.Context, string) (pyramid.StoredFile, error) {
+	f, err := os.CreateTemp(d.dir, "workspace-")
+	if err != nil {
+		return nil, err
+	}
+	return &dirFile{File: f, dir: d.dir}, nil
+}
+
+func (d *dirFS) Open(_ context.Context, _, filename string) (pyramid.File, error) {
+	return os.Open(filepath.Join(d.dir, filename))
+}
+
+func (d *dirFS) Exists(_ context.Context, _, filename string) (bool, error) {
+	_, err := os.Stat(filepath.Join(d.dir, filename))
+	return err == nil, nil
+}
+
+func (d *dirFS) GetRemoteURI(_ context.Context, _, filename string) (string, error) {
+	return filepath.Join(d.dir, filename), nil
+}
+
+func TestRangeManagerRewrite(t *testing.T) {
+	ctx := context.Background()
+	fs := &dirFS{dir: t.TempDir()}
+	const ns = "some-ns"
+	keys := randomStrings(1000)
+	sort.Strings(keys)
+
+	// written in the default format
+	cache := pebble.NewCache(1 << 20)
+	defer cache.Unref()
+	sut := sstable.NewPebbleSSTableRangeManager(cache, fs, crypto.SHA256, sstable.FormatParams{})
+	writer, err := sut.GetWriter(ctx, ns, map[string]string{"some": "metadata"})
+	require.NoError(t, err)
+	writer.SetMetadata("type", "ranges")
+	for _, key := range keys {
+		require.NoError(t, writer.WriteRecord(committed.Record{Key: []byte(key), Value: []byte("value-" + key)}))
+	}
+	result, err := writer.Close()
+	require.NoError(t, err)
+	props := requireFormat(t, fs, result.RangeID, "snappy", "4096", "16")
+	require.Equal(t, strconv.Itoa(sstable.FormatVersionBloomFilter), props[sstable.MetadataFormatVersionKey])
+
+	format, err := sstable.NewFormatParams("zstd", 32*1024, 32)
+	require.NoError(t, err)
+	rewriter := sstable.NewPebbleSSTableRangeManager(cache, fs, crypto.SHA256, format)
+	require.NoError(t, rewriter.Rewrite(ctx, ns, result.RangeID))
+	props = requireFormat(t, fs, result.RangeID, "zstd", "32768", "32")
+	require.Equal(t, strconv.Itoa(sstable.FormatVersionZstd), props[sstable.MetadataFormatVersionKey], "zstd sstables are not readable by older versions")
+	require.Equal(t, "metadata", props["some"])
+	require.Equal(t, "ranges", props["type"])
+
+	it, err := sut.NewRangeIterator(ctx, ns, result.RangeID)
+	require.NoError(t, err)
+	defer it.Close()
+	for _, key := range keys {
+		require.True(t, it.Next())
+		require.Equal(t, key, string(it.Value().Key))
+		require.Equal(t, "value-"+key, string(it.Value().Value))
+	}
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+}
+
+// countingFS is a dirFS counting the files opened by name
+type countingFS struct {
+	dirFS
+	mu     sync.Mutex
+	opened map[string]int
+}
+
+func (c *countingFS) Open(ctx context.Context, ns, filename string) (pyramid.File, error) {
+	c.mu.Lock()
+	c.opened[filename]++
+	c.mu.Unlock()
+	return c.dirFS.Open(ctx, ns, filename)
+}
+
+func TestRangeManagerFilterObjects(t *testing.T) {
+	ctx := context.Background()
+	fs := &countingFS{dirFS: dirFS{dir: t.TempDir()}, opened: make(map[string]int)}
+	const ns = "some-ns"
+	keys := randomStrings(1000)
+	sort.Strings(keys)
+
+	cache := pebble.NewCache(1 << 20)
+	defer cache.Unref()
+	sut := sstable.NewPebbleSSTableRangeManager(cache, fs, crypto.SHA256, sstable.FormatParams{}, sstable.WithFilterObjects(10))
+	writer, err := sut.GetWriter(ctx, ns, nil)
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, writer.WriteRecord(committed.Record{Key: []byte(key), Value: []byte("value-" + key)}))
+	}
+	result, err := writer.Close()
+	require.NoError(t, err)
+	id := string(result.RangeID)
+	_, err = os.Stat(filepath.Join(fs.dir, id+".filter"))
+	require.NoError(t, err, "filter object")
+
+	for _, key := range keys[:100] {
+		val, err := sut.GetValue(ctx, ns, result.RangeID, committed.Key(key))
+		require.NoError(t, err)
+		require.Equal(t, "value-"+key, string(val.Value))
+	}
+	require.Equal(t, 100, fs.opened[id])
+	require.Equal(t, 1, fs.opened[id+".filter"], "filter object is cached")
+
+	const missing = 1000
+	for _, key := range randomStrings(missing) {
+		_, err := sut.GetValue(ctx, ns, result.RangeID, committed.Key(key+"-missing"))
+		require.ErrorIs(t, err, sstable.ErrKeyNotFound)
+	}
+	// false positives of the filter open the range, about 1% of missing keys
+	require.Less(t, fs.opened[id]-100, missing/20, "range opened for missing keys")
+
+	t.Run("without filter object", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(fs.dir, id+".filter")))
+		m := sstable.NewPebbleSSTableRangeManager(cache, fs, crypto.SHA256, sstable.FormatParams{}, sstable.WithFilterObjects(10))
+		val, err := m.GetValue(ctx, ns, result.RangeID, committed.Key(keys[0]))
+		require.NoError(t, err)
+		require.Equal(t, "value-"+keys[0], string(val.Value))
+		_, err = m.GetValue(ctx, ns, result.RangeID, committed.Key(keys[0]+"-missing"))
+		require.ErrorIs(t, err, sstable.ErrKeyNotFound)
+	})
+}
+
+func TestNewFormatParamsUnknownCompression(t *testing.T) {
+	_, err := sstable.NewFormatParams("lz4", 0, 0)
+	require.ErrorIs(t, err, sstable.ErrUnknownCompression)
+}
+
+// requireFormat verifies the format recorded in the properties of sstable id, and returns its properties
+func requireFormat(t *testing.T, fs *dirFS, id committed.ID, compression, blockSize, blockRestartInterval string) map[string]string {
+	t.Helper()
+	r, err := pebblesst.NewMemReader(mustReadFile(t, filepath.Join(fs.dir, string(id))), pebblesst.ReaderOptions{})
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	props := r.Properties.UserProperties
+	require.Equal(t, compression, props[sstable.MetadataCompressionKey])
+	require.Equal(t, blockSize, props[sstable.MetadataBlockSizeKey])
+	require.Equal(t, blockRestartInterval, props[sstable.MetadataBlockRestartIntervalKey])
+	return props
+}
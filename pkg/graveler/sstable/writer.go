@@ -8,6 +8,7 @@ import (
 	"hash/fnv"
 	"strconv"
 
+	"github.com/cockroachdb/pebble/bloom"
 	"github.com/cockroachdb/pebble/sstable"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
@@ -20,11 +21,52 @@ const (
 	MetadataLastKey          = "max_key"
 	MetadataNumRecordsKey    = "count"
 	MetadataEstimatedSizeKey = "estimated_size_bytes"
+	MetadataFormatVersionKey = "format_version"
 )
 
+const (
+	// FormatVersionInitial is the format of sstables written without a format version property
+	FormatVersionInitial = 1
+	// FormatVersionBloomFilter sstables hold a Bloom filter block of their keys
+	FormatVersionBloomFilter = 2
+	// FormatVersionZstd sstables may hold zstd compressed blocks
+	FormatVersionZstd = 3
+
+	// FormatVersion is the latest format, sstables of later formats are not read
+	FormatVersion = FormatVersionZstd
+
+	// bloomFilterBitsPerKey gives a false positive rate of about 1%
+	bloomFilterBitsPerKey = 10
+)
+
+// writerProperties are the sstable properties set by DiskWriter, all other properties are user metadata
+var writerProperties = map[string]bool{
+	MetadataFirstKey:                true,
+	MetadataLastKey:                 true,
+	MetadataNumRecordsKey:           true,
+	MetadataEstimatedSizeKey:        true,
+	MetadataFormatVersionKey:        true,
+	MetadataCompressionKey:          true,
+	MetadataBlockSizeKey:            true,
+	MetadataBlockRestartIntervalKey: true,
+}
+
+// filterPolicy is the Bloom filter written to sstables, readers look it up by its name
+var filterPolicy = bloom.FilterPolicy(bloomFilterBitsPerKey)
+
+// filterObjectSuffix names the object holding the Bloom filter of an sstable, next to it
+const filterObjectSuffix = ".filter"
+
+// filterObjectName returns the name of the object holding the Bloom filter of sstable id
+func filterObjectName(id committed.ID) string {
+	return string(id) + filterObjectSuffix
+}
+
 type DiskWriter struct {
 	ctx    context.Context
+	ns     committed.Namespace
 	w      *sstable.Writer
+	filter sstable.FilterWriter
 	props  map[string]string
 	tierFS pyramid.FS
 	first  committed.Key
@@ -35,7 +77,7 @@ type DiskWriter struct {
 	closed bool
 }
 
-func NewDiskWriter(ctx context.Context, tierFS pyramid.FS, ns committed.Namespace, hash hash.Hash, metadata graveler.Metadata) (*DiskWriter, error) {
+func NewDiskWriter(ctx context.Context, tierFS pyramid.FS, ns committed.Namespace, hash hash.Hash, metadata graveler.Metadata, format FormatParams) (*DiskWriter, error) {
 	fh, err := tierFS.Create(ctx, string(ns))
 	if err != nil {
 		return nil, fmt.Errorf("opening file: %w", err)
@@ -46,13 +88,18 @@ func NewDiskWriter(ctx context.Context, tierFS pyramid.FS, ns committed.Namespac
 		props[k] = v
 	}
 
-	writer := sstable.NewWriter(fh, sstable.WriterOptions{
-		Compression:             sstable.SnappyCompression,
-		TablePropertyCollectors: []func() sstable.TablePropertyCollector{NewStaticCollector(props)},
-	})
+	// The format is not part of props: it does not change the range ID, which depends
+	// only on the content of the range.
+	opts, formatProps := format.writerOptions()
+	opts.TablePropertyCollectors = []func() sstable.TablePropertyCollector{
+		NewStaticCollector(props),
+		NewStaticCollector(formatProps),
+	}
+	writer := sstable.NewWriter(fh, opts)
 
 	return &DiskWriter{
 		ctx:    ctx,
+		ns:     ns,
 		w:      writer,
 		props:  props,
 		fh:     fh,
@@ -67,6 +114,12 @@ func (dw *DiskWriter) SetMetadata(key, value string) {
 	dw.props[key] = value
 }
 
+// WriteFilterObject also stores the Bloom filter of the keys in a separate object named
+// after the sstable, so readers can skip fetching the sstable for keys it does not hold.
+func (dw *DiskWriter) WriteFilterObject() {
+	dw.filter = filterPolicy.NewWriter(sstable.TableFilter)
+}
+
 func (dw *DiskWriter) GetFS() pyramid.FS {
 	return dw.tierFS
 }
@@ -79,6 +132,9 @@ func (dw *DiskWriter) WriteRecord(record committed.Record) error {
 	if err := dw.w.Set(record.Key, record.Value); err != nil {
 		return fmt.Errorf("setting key and value: %w", err)
 	}
+	if dw.filter != nil {
+		dw.filter.AddKey(record.Key)
+	}
 
 	// updating stats
 	if dw.count == 0 {
@@ -151,6 +207,14 @@ func (dw *DiskWriter) Close() (*committed.WriteResult, error) {
 		return nil, fmt.Errorf("sstable close (%s): %w", sstableID, err)
 	}
 
+	// Store the filter first: once the sstable exists readers may cache that it has no filter.
+	if dw.filter != nil {
+		if err := dw.storeFilter(committed.ID(sstableID)); err != nil {
+			_ = dw.fh.Abort(dw.ctx)
+			return nil, fmt.Errorf("sstable filter store (%s): %w", sstableID, err)
+		}
+	}
+
 	if err := dw.fh.Store(dw.ctx, sstableID); err != nil {
 		return nil, fmt.Errorf("sstable store (%s): %w", sstableID, err)
 	}
@@ -182,3 +246,15 @@ func (dw *DiskWriter) ShouldBreakAtKey(key graveler.Key, params *committed.Param
 	r := h.Sum64() % uint64(params.RangeSizeEntriesRaggedness)
 	return r == 0
 }
+
+func (dw *DiskWriter) storeFilter(id committed.ID) error {
+	fh, err := dw.tierFS.Create(dw.ctx, string(dw.ns))
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	if _, err := fh.Write(dw.filter.Finish(nil)); err != nil {
+		_ = fh.Abort(dw.ctx)
+		return fmt.Errorf("writing filter: %w", err)
+	}
+	return fh.Store(dw.ctx, filterObjectName(id))
+}
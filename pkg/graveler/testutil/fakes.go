@@ -891,7 +891,7 @@ func (m *RefsFake) GetRepositoryMetadata(_ context.Context, _ graveler.Repositor
 	panic("implement me")
 }
 
-func (m *RefsFake) SetRepositoryMetadata(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.RepoMetadataUpdateFunc) error {
-	// TODO implement me
-	panic("implement me")
+func (m *RefsFake) SetRepositoryMetadata(_ context.Context, _ *graveler.RepositoryRecord, update graveler.RepoMetadataUpdateFunc) error {
+	_, err := update(graveler.RepositoryMetadata{})
+	return err
 }
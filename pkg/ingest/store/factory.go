@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 
 	"cloud.google.com/go/storage"
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,6 +18,8 @@ import (
 	"github.com/treeverse/lakefs/pkg/block/local"
 	"github.com/treeverse/lakefs/pkg/block/params"
 	"github.com/treeverse/lakefs/pkg/block/s3"
+	"github.com/treeverse/lakefs/pkg/block/sftp"
+	"github.com/treeverse/lakefs/pkg/block/webdav"
 )
 
 var ErrNotSupported = errors.New("no storage adapter found")
@@ -53,6 +56,12 @@ func (ww *WalkerWrapper) GetSkippedEntries() []block.ObjectStoreEntry {
 
 type WalkerFactory struct {
 	params params.AdapterConfig
+
+	// the sftp and webdav adapters are built on first use and shared by the walkers: an sftp adapter keeps a
+	// connection to the server
+	mu            sync.Mutex
+	sftpAdapter   *sftp.Adapter
+	webdavAdapter *webdav.Adapter
 }
 
 func NewFactory(params params.AdapterConfig) *WalkerFactory {
@@ -173,6 +182,16 @@ func (f *WalkerFactory) GetWalker(ctx context.Context, opts WalkerOptions) (*Wal
 		if err != nil {
 			return nil, fmt.Errorf("creating local walker: %w", err)
 		}
+	case block.BlockstoreTypeSFTP:
+		walker, err = f.buildSFTPWalker(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating sftp walker: %w", err)
+		}
+	case block.BlockstoreTypeWebDAV:
+		walker, err = f.buildWebDAVWalker(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating webdav walker: %w", err)
+		}
 	default:
 		return nil, fmt.Errorf("%w: for scheme: %s", ErrNotSupported, uri.Scheme)
 	}
@@ -195,6 +214,44 @@ func (f *WalkerFactory) buildLocalWalker() (*local.Walker, error) {
 	return local.NewLocalWalker(localParams), nil
 }
 
+func (f *WalkerFactory) buildSFTPWalker(ctx context.Context) (*sftp.Walker, error) {
+	if f.params == nil {
+		return nil, fmt.Errorf("sftp: %w", ErrNotSupported)
+	}
+	sftpParams, err := f.params.BlockstoreSFTPParams()
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sftpAdapter == nil {
+		f.sftpAdapter, err = factory.BuildSFTPAdapter(ctx, sftpParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sftp.NewWalker(f.sftpAdapter), nil
+}
+
+func (f *WalkerFactory) buildWebDAVWalker(ctx context.Context) (*webdav.Walker, error) {
+	if f.params == nil {
+		return nil, fmt.Errorf("webdav: %w", ErrNotSupported)
+	}
+	webdavParams, err := f.params.BlockstoreWebDAVParams()
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.webdavAdapter == nil {
+		f.webdavAdapter, err = factory.BuildWebDAVAdapter(ctx, webdavParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return webdav.NewWalker(f.webdavAdapter), nil
+}
+
 func getS3Client(s3EndpointURL string) (*session.Session, error) {
 	var config aws.Config
 	if s3EndpointURL != "" {
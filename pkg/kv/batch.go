@@ -0,0 +1,194 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+var (
+	// ErrBatchNotAtomic is returned by a BatchWriter that cannot write the batch atomically, for example when the
+	// batch is larger than the store supports. Nothing is written.
+	ErrBatchNotAtomic = errors.New("batch cannot be written atomically")
+	ErrInvalidBatch   = errors.New("invalid batch")
+)
+
+// BatchOp is a single write of a batch
+type BatchOp struct {
+	PartitionKey []byte
+	Key          []byte
+	// Value is the value to set, nil deletes the key
+	Value []byte
+	// Conditional operations are written only if Predicate holds, as with SetIf. A conditional delete requires a
+	// predicate.
+	Conditional bool
+	Predicate   Predicate
+}
+
+// IsDelete returns true when the operation deletes the key
+func (op BatchOp) IsDelete() bool {
+	return op.Value == nil
+}
+
+// SetOp returns an operation that sets the value of the key
+func SetOp(partitionKey, key, value []byte) BatchOp {
+	return BatchOp{PartitionKey: partitionKey, Key: key, Value: value}
+}
+
+// SetIfOp returns an operation that sets the value of the key, only if the predicate holds
+func SetIfOp(partitionKey, key, value []byte, predicate Predicate) BatchOp {
+	return BatchOp{PartitionKey: partitionKey, Key: key, Value: value, Conditional: true, Predicate: predicate}
+}
+
+// DeleteOp returns an operation that deletes the key, if it exists
+func DeleteOp(partitionKey, key []byte) BatchOp {
+	return BatchOp{PartitionKey: partitionKey, Key: key}
+}
+
+// DeleteIfOp returns an operation that deletes the key, only if the predicate holds. The predicate is a value
+// returned by Get or PrecondConditionalExists.
+func DeleteIfOp(partitionKey, key []byte, predicate Predicate) BatchOp {
+	return BatchOp{PartitionKey: partitionKey, Key: key, Conditional: true, Predicate: predicate}
+}
+
+// BatchWriter is implemented by stores that write multiple keys atomically
+type BatchWriter interface {
+	// WriteBatch writes all operations, or none of them. When the predicate of a conditional operation fails nothing
+	// is written and ErrPredicateFailed is returned. Fails with ErrBatchNotAtomic, without writing, when the store
+	// cannot write the batch atomically.
+	// Operations are validated by WriteBatch before they are passed to the store.
+	WriteBatch(ctx context.Context, ops []BatchOp) error
+}
+
+// WriteBatch writes the operations to the store atomically. Fails with ErrBatchNotAtomic, without writing, when the
+// store does not implement BatchWriter or cannot write the batch atomically.
+func WriteBatch(ctx context.Context, store Store, ops []BatchOp) error {
+	if err := ValidateBatch(ops); err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	writer, ok := store.(BatchWriter)
+	if !ok {
+		return ErrBatchNotAtomic
+	}
+	return writer.WriteBatch(ctx, ops)
+}
+
+// WriteBatchBestEffort writes the operations to the store, atomically when the store supports the batch. Otherwise,
+// the operations are written one after the other, in order. On failure, operations already written are reverted on a
+// best effort basis, concurrent writers may observe a part of the batch.
+// Use only when a partially written batch is acceptable.
+func WriteBatchBestEffort(ctx context.Context, store Store, ops []BatchOp) error {
+	err := WriteBatch(ctx, store, ops)
+	if !errors.Is(err, ErrBatchNotAtomic) {
+		return err
+	}
+	batchFallbacks.Inc()
+	logging.FromContext(ctx).
+		WithField("size", len(ops)).
+		WithError(err).
+		Warn("Writing batch sequentially, it is not written atomically")
+	return writeBatchSequential(ctx, store, ops)
+}
+
+// ValidateBatch verifies the operations address a key once, with the required arguments
+func ValidateBatch(ops []BatchOp) error {
+	keys := make(map[string]struct{}, len(ops))
+	for _, op := range ops {
+		if len(op.PartitionKey) == 0 {
+			return ErrMissingPartitionKey
+		}
+		if len(op.Key) == 0 {
+			return ErrMissingKey
+		}
+		if op.IsDelete() && op.Conditional && op.Predicate == nil {
+			return fmt.Errorf("conditional delete of %s/%s without predicate: %w", op.PartitionKey, op.Key, ErrInvalidBatch)
+		}
+		k := FormatPath(string(op.PartitionKey), string(op.Key))
+		if _, ok := keys[k]; ok {
+			return fmt.Errorf("key %s/%s written more than once: %w", op.PartitionKey, op.Key, ErrInvalidBatch)
+		}
+		keys[k] = struct{}{}
+	}
+	return nil
+}
+
+// MatchPredicate reports whether the predicate of a conditional write holds for the value of the key, for stores
+// that use the value as predicate. Found is false when the key does not exist.
+func MatchPredicate(predicate Predicate, value []byte, found bool) bool {
+	switch {
+	case predicate == nil:
+		return !found
+	case !found:
+		return false
+	case predicate == PrecondConditionalExists:
+		return true
+	default:
+		return bytes.Equal(value, predicate.([]byte))
+	}
+}
+
+// undoOp restores a key written by a batch to its value before the batch
+type undoOp struct {
+	partitionKey []byte
+	key          []byte
+	value        []byte
+}
+
+func writeBatchSequential(ctx context.Context, store Store, ops []BatchOp) error {
+	undo := make([]undoOp, 0, len(ops))
+	for _, op := range ops {
+		var prev []byte
+		res, err := store.Get(ctx, op.PartitionKey, op.Key)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			revertBatch(ctx, store, undo)
+			return err
+		default:
+			prev = res.Value
+		}
+		if err := writeBatchOp(ctx, store, op, res); err != nil {
+			revertBatch(ctx, store, undo)
+			return err
+		}
+		undo = append(undo, undoOp{partitionKey: op.PartitionKey, key: op.Key, value: prev})
+	}
+	return nil
+}
+
+// writeBatchOp writes a single operation, res is the current value of the key, nil if not found
+func writeBatchOp(ctx context.Context, store Store, op BatchOp, res *ValueWithPredicate) error {
+	switch {
+	case op.IsDelete() && op.Conditional:
+		if res == nil {
+			return ErrPredicateFailed
+		}
+		if op.Predicate != PrecondConditionalExists && !bytes.Equal(res.Predicate.([]byte), op.Predicate.([]byte)) {
+			return ErrPredicateFailed
+		}
+		return store.Delete(ctx, op.PartitionKey, op.Key)
+	case op.IsDelete():
+		return store.Delete(ctx, op.PartitionKey, op.Key)
+	case op.Conditional:
+		return store.SetIf(ctx, op.PartitionKey, op.Key, op.Value, op.Predicate)
+	default:
+		return store.Set(ctx, op.PartitionKey, op.Key, op.Value)
+	}
+}
+
+func revertBatch(ctx context.Context, store Store, undo []undoOp) {
+	for i := len(undo) - 1; i >= 0; i-- {
+		u := undo[i]
+		if u.value == nil {
+			_ = store.Delete(ctx, u.partitionKey, u.key)
+		} else {
+			_ = store.Set(ctx, u.partitionKey, u.key, u.value)
+		}
+	}
+}
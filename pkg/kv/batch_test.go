@@ -0,0 +1,66 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+)
+
+// sequentialStore hides the BatchWriter implementation of the store
+type sequentialStore struct {
+	kv.Store
+}
+
+func TestWriteBatchSequential(t *testing.T) {
+	ctx := context.Background()
+	store := sequentialStore{Store: kvtest.GetStore(ctx, t)}
+	partition := []byte("partition")
+	require.NoError(t, store.Set(ctx, partition, []byte("a"), []byte("a1")))
+	require.NoError(t, store.Set(ctx, partition, []byte("b"), []byte("b1")))
+
+	t.Run("not_atomic", func(t *testing.T) {
+		err := kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, []byte("a"), []byte("a2")),
+		})
+		require.ErrorIs(t, err, kv.ErrBatchNotAtomic)
+		res, err := store.Get(ctx, partition, []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, "a1", string(res.Value))
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		err := kv.WriteBatchBestEffort(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, []byte("a"), []byte("a2")),
+			kv.DeleteOp(partition, []byte("b")),
+			kv.SetOp(partition, []byte("c"), []byte("c1")),
+			kv.SetIfOp(partition, []byte("a-new"), []byte("x"), kv.PrecondConditionalExists),
+		})
+		require.ErrorIs(t, err, kv.ErrPredicateFailed)
+
+		for key, expected := range map[string]string{"a": "a1", "b": "b1"} {
+			res, err := store.Get(ctx, partition, []byte(key))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(res.Value))
+		}
+		_, err = store.Get(ctx, partition, []byte("c"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		a, err := store.Get(ctx, partition, []byte("a"))
+		require.NoError(t, err)
+		err = kv.WriteBatchBestEffort(ctx, store, []kv.BatchOp{
+			kv.SetIfOp(partition, []byte("a"), []byte("a2"), a.Predicate),
+			kv.DeleteIfOp(partition, []byte("b"), kv.PrecondConditionalExists),
+		})
+		require.NoError(t, err)
+		res, err := store.Get(ctx, partition, []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, "a2", string(res.Value))
+		_, err = store.Get(ctx, partition, []byte("b"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+	})
+}
@@ -0,0 +1,73 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvparams"
+	"github.com/treeverse/lakefs/pkg/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	DriverName = "bolt"
+
+	// openTimeout is the time to wait for the lock of a database file held by another process
+	openTimeout = 10 * time.Second
+	dirPerm     = 0o755
+	filePerm    = 0o600
+)
+
+var (
+	driverLock = &sync.Mutex{}
+	dbMap      = make(map[string]*Store)
+)
+
+type Driver struct{}
+
+func (d *Driver) Open(ctx context.Context, kvParams kvparams.Config) (kv.Store, error) {
+	params := kvParams.Bolt
+	if params == nil {
+		return nil, fmt.Errorf("missing %s settings: %w", DriverName, kv.ErrDriverConfiguration)
+	}
+
+	driverLock.Lock()
+	defer driverLock.Unlock()
+	connection, ok := dbMap[params.Path]
+	if !ok {
+		// no database open for this path
+		var logger logging.Logger = logging.DummyLogger{}
+		if params.EnableLogging {
+			logger = logging.FromContext(ctx).WithField("store", DriverName)
+		}
+		if err := os.MkdirAll(filepath.Dir(params.Path), dirPerm); err != nil {
+			return nil, err
+		}
+		db, err := bolt.Open(params.Path, filePerm, &bolt.Options{
+			Timeout: openTimeout,
+			NoSync:  !params.SyncWrites,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", params.Path, err)
+		}
+		connection = &Store{
+			db:        db,
+			logger:    logger,
+			batchSize: params.ScanBatchSize,
+			path:      params.Path,
+		}
+		dbMap[params.Path] = connection
+	}
+	connection.refCount++
+	return connection, nil
+}
+
+//nolint:gochecknoinits
+func init() {
+	kv.Register(DriverName, &Driver{})
+}
@@ -0,0 +1,116 @@
+package bolt
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+// EntriesIterator reads the entries of a partition in batches, each batch by a short read transaction. Keeping a read
+// transaction open for the lifetime of the iterator would block writers that need to grow the database file.
+type EntriesIterator struct {
+	db           *bolt.DB
+	partitionKey []byte
+	// start is the key the next batch is read from
+	start []byte
+	// skipStart is set when start is the last key of the previous batch, and is excluded from the next batch
+	skipStart bool
+	batchSize int
+	entries   []kv.Entry
+	done      bool
+	entry     *kv.Entry
+	err       error
+	logger    logging.Logger
+}
+
+func (e *EntriesIterator) Next() bool {
+	if e.err != nil {
+		return false
+	}
+	if len(e.entries) == 0 {
+		if e.done {
+			e.entry = nil
+			return false
+		}
+		if err := e.readBatch(); err != nil {
+			e.err = err
+			e.entry = nil
+			return false
+		}
+		if len(e.entries) == 0 {
+			e.entry = nil
+			return false
+		}
+	}
+	e.entry = &e.entries[0]
+	e.entries = e.entries[1:]
+	return true
+}
+
+func (e *EntriesIterator) readBatch() error {
+	start := time.Now()
+	entries := make([]kv.Entry, 0, e.batchSize)
+	err := e.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(e.partitionKey)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		k, v := c.Seek(e.start)
+		if k != nil && e.skipStart && bytes.Equal(k, e.start) {
+			k, v = c.Next()
+		}
+		for ; k != nil && len(entries) < e.batchSize; k, v = c.Next() {
+			if v == nil {
+				// nested bucket
+				continue
+			}
+			// keys and values are valid only during the transaction
+			entry := kv.Entry{
+				PartitionKey: e.partitionKey,
+				Key:          make([]byte, len(k)),
+				Value:        make([]byte, len(v)),
+			}
+			copy(entry.Key, k)
+			copy(entry.Value, v)
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		e.logger.WithError(err).Trace("error reading batch")
+		return err
+	}
+	e.entries = entries
+	e.done = len(entries) < e.batchSize
+	if len(entries) > 0 {
+		e.start = entries[len(entries)-1].Key
+		e.skipStart = true
+	}
+	e.logger.WithField("size", len(entries)).WithField("took", time.Since(start)).Trace("read batch")
+	return nil
+}
+
+func (e *EntriesIterator) SeekGE(key []byte) {
+	e.entry = nil
+	e.entries = nil
+	e.done = false
+	e.start = key
+	e.skipStart = false
+}
+
+func (e *EntriesIterator) Entry() *kv.Entry {
+	return e.entry
+}
+
+func (e *EntriesIterator) Err() error {
+	return e.err
+}
+
+func (e *EntriesIterator) Close() {
+	e.entries = nil
+	e.err = kv.ErrClosedEntries
+}
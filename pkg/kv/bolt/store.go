@@ -0,0 +1,287 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultScanBatchSize is the number of entries read by each read transaction of a scan
+const defaultScanBatchSize = 1000
+
+// Store keeps each partition in a bucket of a single bolt database file. Writes are serialized by bolt, so SetIf
+// compares and sets within a single transaction.
+type Store struct {
+	db        *bolt.DB
+	logger    logging.Logger
+	batchSize int
+	refCount  int
+	path      string
+	watchHub  kv.WatchHub
+}
+
+func (s *Store) Get(ctx context.Context, partitionKey, key []byte) (*kv.ValueWithPredicate, error) {
+	start := time.Now()
+	log := s.logger.WithFields(logging.Fields{
+		"partition_key": string(partitionKey),
+		"key":           string(key),
+		"op":            "get",
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	if len(partitionKey) == 0 {
+		log.WithError(kv.ErrMissingPartitionKey).Warn("got empty partition key")
+		return nil, kv.ErrMissingPartitionKey
+	}
+	if len(key) == 0 {
+		log.WithError(kv.ErrMissingKey).Warn("got empty key")
+		return nil, kv.ErrMissingKey
+	}
+
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(partitionKey)
+		if b == nil {
+			return kv.ErrNotFound
+		}
+		v := b.Get(key)
+		if v == nil {
+			return kv.ErrNotFound
+		}
+		// values are valid only during the transaction
+		value = make([]byte, len(v))
+		copy(value, v)
+		return nil
+	})
+	log.WithField("took", time.Since(start)).WithError(err).WithField("size", len(value)).Trace("operation complete")
+	if err != nil {
+		return nil, err
+	}
+	return &kv.ValueWithPredicate{
+		Value:     value,
+		Predicate: kv.Predicate(value),
+	}, nil
+}
+
+func (s *Store) Set(ctx context.Context, partitionKey, key, value []byte) error {
+	start := time.Now()
+	log := s.logger.WithFields(logging.Fields{
+		"partition_key": string(partitionKey),
+		"key":           string(key),
+		"op":            "set",
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	if len(partitionKey) == 0 {
+		log.WithError(kv.ErrMissingPartitionKey).Warn("got empty partition key")
+		return kv.ErrMissingPartitionKey
+	}
+	if len(key) == 0 {
+		log.WithError(kv.ErrMissingKey).Warn("got empty key")
+		return kv.ErrMissingKey
+	}
+	if value == nil {
+		log.WithError(kv.ErrMissingValue).Warn("got nil value")
+		return kv.ErrMissingValue
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(partitionKey)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, value)
+	})
+	if err != nil {
+		log.WithError(err).Error("error setting value")
+		return err
+	}
+	s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
+	log.WithField("took", time.Since(start)).Trace("done setting value")
+	return nil
+}
+
+func (s *Store) SetIf(ctx context.Context, partitionKey, key, value []byte, valuePredicate kv.Predicate) error {
+	start := time.Now()
+	log := s.logger.WithFields(logging.Fields{
+		"partition_key": string(partitionKey),
+		"key":           string(key),
+		"op":            "set_if",
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	if len(partitionKey) == 0 {
+		log.WithError(kv.ErrMissingPartitionKey).Warn("got empty partition key")
+		return kv.ErrMissingPartitionKey
+	}
+	if len(key) == 0 {
+		log.WithError(kv.ErrMissingKey).Warn("got empty key")
+		return kv.ErrMissingKey
+	}
+	if value == nil {
+		log.WithError(kv.ErrMissingValue).Warn("got nil value")
+		return kv.ErrMissingValue
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(partitionKey)
+		if err != nil {
+			return err
+		}
+		current := b.Get(key)
+		switch {
+		case valuePredicate == nil:
+			if current != nil {
+				log.WithField("predicate", nil).Trace("predicate condition failed (key exists)")
+				return kv.ErrPredicateFailed
+			}
+		case current == nil:
+			log.WithField("predicate", valuePredicate).Trace("predicate condition failed (key not found)")
+			return kv.ErrPredicateFailed
+		case valuePredicate != kv.PrecondConditionalExists:
+			if !bytes.Equal(current, valuePredicate.([]byte)) {
+				log.WithField("predicate", valuePredicate).Trace("predicate condition failed")
+				return kv.ErrPredicateFailed
+			}
+		}
+		return b.Put(key, value)
+	})
+	if err == nil {
+		s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
+	}
+	log.WithField("took", time.Since(start)).WithError(err).Trace("operation complete")
+	return err
+}
+
+func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
+	start := time.Now()
+	log := s.logger.WithFields(logging.Fields{
+		"partition_key": string(partitionKey),
+		"key":           string(key),
+		"op":            "delete",
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	if len(partitionKey) == 0 {
+		log.WithError(kv.ErrMissingPartitionKey).Warn("got empty partition key")
+		return kv.ErrMissingPartitionKey
+	}
+	if len(key) == 0 {
+		log.WithError(kv.ErrMissingKey).Warn("got empty key")
+		return kv.ErrMissingKey
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(partitionKey)
+		if b == nil {
+			return nil
+		}
+		return b.Delete(key)
+	})
+	log = log.WithField("took", time.Since(start))
+	if err != nil {
+		log.WithError(err).Trace("operation failed")
+		return err
+	}
+	s.watchHub.Publish(kv.Event{Type: kv.EventDelete, PartitionKey: partitionKey, Key: key})
+	log.Trace("operation complete")
+	return nil
+}
+
+func (s *Store) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	start := time.Now()
+	log := s.logger.WithFields(logging.Fields{
+		"op":   "write_batch",
+		"size": len(ops),
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, op := range ops {
+			b, err := tx.CreateBucketIfNotExists(op.PartitionKey)
+			if err != nil {
+				return err
+			}
+			if op.Conditional {
+				current := b.Get(op.Key)
+				if !kv.MatchPredicate(op.Predicate, current, current != nil) {
+					log.WithField("key", string(op.Key)).Trace("predicate condition failed")
+					return kv.ErrPredicateFailed
+				}
+			}
+			if op.IsDelete() {
+				err = b.Delete(op.Key)
+			} else {
+				err = b.Put(op.Key, op.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		s.watchHub.PublishBatch(ops)
+	}
+	log.WithField("took", time.Since(start)).WithError(err).Trace("operation complete")
+	return err
+}
+
+func (s *Store) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchHub.Watch(ctx, partitionKey, prefix)
+}
+
+func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOptions) (kv.EntriesIterator, error) {
+	log := s.logger.WithFields(logging.Fields{
+		"partition_key": string(partitionKey),
+		"start_key":     string(options.KeyStart),
+		"op":            "scan",
+	}).WithContext(ctx)
+	log.Trace("performing operation")
+	if len(partitionKey) == 0 {
+		log.WithError(kv.ErrMissingPartitionKey).Warn("got empty partition key")
+		return nil, kv.ErrMissingPartitionKey
+	}
+
+	batchSize := s.batchSize
+	if batchSize <= 0 {
+		batchSize = defaultScanBatchSize
+	}
+	if options.BatchSize > 0 && options.BatchSize < batchSize {
+		batchSize = options.BatchSize
+	}
+	return &EntriesIterator{
+		db:           s.db,
+		partitionKey: partitionKey,
+		start:        options.KeyStart,
+		batchSize:    batchSize,
+		logger:       log,
+	}, nil
+}
+
+func (s *Store) ListPartitions(ctx context.Context) ([][]byte, error) {
+	var partitions [][]byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if b.Stats().KeyN == 0 {
+				return nil
+			}
+			partition := make([]byte, len(name))
+			copy(partition, name)
+			partitions = append(partitions, partition)
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("error listing partitions")
+		return nil, err
+	}
+	return partitions, nil
+}
+
+func (s *Store) Close() {
+	driverLock.Lock()
+	defer driverLock.Unlock()
+	s.refCount--
+	if s.refCount <= 0 {
+		_ = s.db.Close()
+		delete(dbMap, s.path)
+	}
+}
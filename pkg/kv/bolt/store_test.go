@@ -0,0 +1,22 @@
+package bolt_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/kv/bolt"
+	"github.com/treeverse/lakefs/pkg/kv/kvparams"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+)
+
+func TestBoltKV(t *testing.T) {
+	kvtest.DriverTest(t, bolt.DriverName, kvparams.Config{
+		Type: bolt.DriverName,
+		Bolt: &kvparams.Bolt{
+			Path: filepath.Join(t.TempDir(), "metadata.db"),
+			// small batches to cover iteration across read transactions
+			ScanBatchSize: 7,
+			EnableLogging: true,
+		},
+	})
+}
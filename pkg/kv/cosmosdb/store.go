@@ -328,6 +328,99 @@ func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
 	}
 	return nil
 }
+
+// maxBatchOperations is the maximal number of operations of a transactional batch
+const maxBatchOperations = 100
+
+var errBatchFailed = errors.New("transactional batch failed")
+
+// WriteBatch writes the operations by a transactional batch. Transactional batches are limited to a single
+// partition.
+func (s *Store) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	if len(ops) > maxBatchOperations {
+		return fmt.Errorf("%d operations: %w", len(ops), kv.ErrBatchNotAtomic)
+	}
+	for _, op := range ops {
+		if !bytes.Equal(op.PartitionKey, ops[0].PartitionKey) {
+			return fmt.Errorf("multiple partitions: %w", kv.ErrBatchNotAtomic)
+		}
+	}
+	// deleting a missing key fails the batch, unlike Delete - drop these deletes and retry
+	for len(ops) > 0 {
+		missingIdx, err := s.executeBatch(ctx, ops)
+		if missingIdx < 0 {
+			return err
+		}
+		ops = append(ops[:missingIdx:missingIdx], ops[missingIdx+1:]...)
+	}
+	return nil
+}
+
+// executeBatch executes the operations as a transactional batch. Returns the index of a delete that failed the batch
+// as its key does not exist, or -1.
+func (s *Store) executeBatch(ctx context.Context, ops []kv.BatchOp) (int, error) {
+	partitionKey := encoding.EncodeToString(ops[0].PartitionKey)
+	batch := s.containerClient.NewTransactionalBatch(azcosmos.NewPartitionKeyString(partitionKey))
+	for _, op := range ops {
+		id := s.hashID(op.Key)
+		var itemOptions *azcosmos.TransactionalBatchItemOptions
+		if op.Conditional && op.Predicate != nil && op.Predicate != kv.PrecondConditionalExists {
+			etag := azcore.ETag(op.Predicate.([]byte))
+			itemOptions = &azcosmos.TransactionalBatchItemOptions{IfMatchETag: &etag}
+		}
+		if op.IsDelete() {
+			batch.DeleteItem(id, itemOptions)
+			continue
+		}
+		b, err := json.Marshal(Document{
+			PartitionKey: partitionKey,
+			ID:           id,
+			Key:          encoding.EncodeToString(op.Key),
+			Value:        encoding.EncodeToString(op.Value),
+		})
+		if err != nil {
+			return -1, err
+		}
+		switch {
+		case !op.Conditional:
+			batch.UpsertItem(b, nil)
+		case op.Predicate == nil:
+			batch.CreateItem(b, nil)
+		default:
+			batch.ReplaceItem(id, b, itemOptions)
+		}
+	}
+
+	resp, err := s.containerClient.ExecuteTransactionalBatch(ctx, batch, &azcosmos.TransactionalBatchOptions{
+		ConsistencyLevel: s.consistencyLevel.ToPtr(),
+	})
+	if err != nil {
+		return -1, err
+	}
+	if resp.Success {
+		return -1, nil
+	}
+	// the failed operation is the first that did not fail on dependency
+	for i, result := range resp.OperationResults {
+		if result.StatusCode == http.StatusFailedDependency {
+			continue
+		}
+		op := ops[i]
+		switch result.StatusCode {
+		case http.StatusNotFound:
+			if op.IsDelete() && !op.Conditional {
+				return i, nil
+			}
+			return -1, kv.ErrPredicateFailed
+		case http.StatusConflict, http.StatusPreconditionFailed:
+			return -1, kv.ErrPredicateFailed
+		default:
+			return -1, fmt.Errorf("operation %d status %d: %w", i, result.StatusCode, errBatchFailed)
+		}
+	}
+	return -1, errBatchFailed
+}
+
 func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOptions) (kv.EntriesIterator, error) {
 	if len(partitionKey) == 0 {
 		return nil, kv.ErrMissingPartitionKey
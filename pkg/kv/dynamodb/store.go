@@ -16,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/treeverse/lakefs/pkg/kv"
 	"github.com/treeverse/lakefs/pkg/kv/kvparams"
@@ -30,6 +31,12 @@ type Store struct {
 	wg     sync.WaitGroup
 	logger logging.Logger
 	cancel chan bool
+
+	streams   *dynamodbstreams.DynamoDBStreams
+	streamArn string
+	watchHub  kv.WatchHub
+	pollMu    sync.Mutex
+	polling   bool
 }
 
 type EntriesIterator struct {
@@ -103,13 +110,27 @@ func (d *Driver) Open(ctx context.Context, kvParams kvparams.Config) (kv.Store,
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", kv.ErrSetupFailed, err)
 	}
+	streamArn, err := tableStreamArn(ctx, svc, params.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", kv.ErrSetupFailed, err)
+	}
 
 	logger := logging.FromContext(ctx).WithField("store", DriverName)
+	if streamArn == "" {
+		// tables created before watches were added have no stream
+		streamArn, err = enableTableStream(ctx, svc, params.TableName)
+		if err != nil {
+			logger.WithError(err).WithField("table", params.TableName).
+				Warn("Failed to enable the table stream, watchers will only see changes written by this lakeFS instance")
+		}
+	}
 	s := &Store{
-		svc:    svc,
-		params: params,
-		logger: logger,
-		cancel: make(chan bool),
+		svc:       svc,
+		params:    params,
+		logger:    logger,
+		cancel:    make(chan bool),
+		streams:   dynamodbstreams.New(sess, cfg),
+		streamArn: streamArn,
 	}
 
 	s.StartPeriodicCheck()
@@ -161,6 +182,11 @@ func setupKeyValueDatabase(ctx context.Context, svc *dynamodb.DynamoDB, params *
 				KeyType:       aws.String("RANGE"),
 			},
 		},
+		// the stream is read by watchers
+		StreamSpecification: &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(dynamodb.StreamViewTypeKeysOnly),
+		},
 	})
 	if err != nil {
 		if _, ok := err.(*dynamodb.ResourceInUseException); ok {
@@ -277,23 +303,13 @@ func (s *Store) setWithOptionalPredicate(ctx context.Context, partitionKey, key,
 		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
 	}
 	if usePredicate {
-		switch valuePredicate {
-		case nil: // Set only if not exists
-			input.ConditionExpression = aws.String("attribute_not_exists(" + ItemValue + ")")
-
-		case kv.PrecondConditionalExists: // update only if exists
-			input.ConditionExpression = aws.String("attribute_exists(" + ItemValue + ")")
-
-		default: // update only if predicate matches current stored value
-			predicateCondition := expression.Name(ItemValue).Equal(expression.Value(valuePredicate.([]byte)))
-			conditionExpression, err := expression.NewBuilder().WithCondition(predicateCondition).Build()
-			if err != nil {
-				return fmt.Errorf("build condition expression: %w", err)
-			}
-			input.ExpressionAttributeNames = conditionExpression.Names()
-			input.ExpressionAttributeValues = conditionExpression.Values()
-			input.ConditionExpression = conditionExpression.Condition()
+		cond, err := predicateCondition(valuePredicate)
+		if err != nil {
+			return err
 		}
+		input.ConditionExpression = cond.expression
+		input.ExpressionAttributeNames = cond.names
+		input.ExpressionAttributeValues = cond.values
 	}
 
 	resp, err := s.svc.PutItemWithContext(ctx, input)
@@ -307,9 +323,40 @@ func (s *Store) setWithOptionalPredicate(ctx context.Context, partitionKey, key,
 	if resp.ConsumedCapacity != nil {
 		dynamoConsumedCapacity.WithLabelValues(operation).Add(*resp.ConsumedCapacity.CapacityUnits)
 	}
+	s.publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
 	return nil
 }
 
+// condition is a condition expression of a write
+type condition struct {
+	expression *string
+	names      map[string]*string
+	values     map[string]*dynamodb.AttributeValue
+}
+
+// predicateCondition returns the condition of a write that holds when the predicate holds
+func predicateCondition(valuePredicate kv.Predicate) (condition, error) {
+	switch valuePredicate {
+	case nil: // Set only if not exists
+		return condition{expression: aws.String("attribute_not_exists(" + ItemValue + ")")}, nil
+
+	case kv.PrecondConditionalExists: // update only if exists
+		return condition{expression: aws.String("attribute_exists(" + ItemValue + ")")}, nil
+
+	default: // update only if predicate matches current stored value
+		predicateCondition := expression.Name(ItemValue).Equal(expression.Value(valuePredicate.([]byte)))
+		conditionExpression, err := expression.NewBuilder().WithCondition(predicateCondition).Build()
+		if err != nil {
+			return condition{}, fmt.Errorf("build condition expression: %w", err)
+		}
+		return condition{
+			expression: conditionExpression.Condition(),
+			names:      conditionExpression.Names(),
+			values:     conditionExpression.Values(),
+		}, nil
+	}
+}
+
 func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
 	if len(partitionKey) == 0 {
 		return kv.ErrMissingPartitionKey
@@ -330,6 +377,7 @@ func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
 	if resp.ConsumedCapacity != nil {
 		dynamoConsumedCapacity.WithLabelValues(operation).Add(*resp.ConsumedCapacity.CapacityUnits)
 	}
+	s.publish(kv.Event{Type: kv.EventDelete, PartitionKey: partitionKey, Key: key})
 	return nil
 }
 
@@ -357,6 +405,119 @@ func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOp
 	return it, nil
 }
 
+// maxTransactItems is the maximal number of items DynamoDB writes by a transaction
+const maxTransactItems = 100
+
+func (s *Store) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	if len(ops) > maxTransactItems {
+		return fmt.Errorf("%d operations: %w", len(ops), kv.ErrBatchNotAtomic)
+	}
+	items := make([]*dynamodb.TransactWriteItem, 0, len(ops))
+	for _, op := range ops {
+		var cond condition
+		if op.Conditional {
+			var err error
+			cond, err = predicateCondition(op.Predicate)
+			if err != nil {
+				return err
+			}
+		}
+		if op.IsDelete() {
+			items = append(items, &dynamodb.TransactWriteItem{
+				Delete: &dynamodb.Delete{
+					TableName:                 aws.String(s.params.TableName),
+					Key:                       s.bytesKeyToDynamoKey(op.PartitionKey, op.Key),
+					ConditionExpression:       cond.expression,
+					ExpressionAttributeNames:  cond.names,
+					ExpressionAttributeValues: cond.values,
+				},
+			})
+			continue
+		}
+		marshaledItem, err := dynamodbattribute.MarshalMap(DynKVItem{
+			PartitionKey: op.PartitionKey,
+			ItemKey:      op.Key,
+			ItemValue:    op.Value,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal map: %w", err)
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:                 aws.String(s.params.TableName),
+				Item:                      marshaledItem,
+				ConditionExpression:       cond.expression,
+				ExpressionAttributeNames:  cond.names,
+				ExpressionAttributeValues: cond.values,
+			},
+		})
+	}
+
+	resp, err := s.svc.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems:          items,
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+	})
+	const operation = "TransactWriteItems"
+	if err != nil {
+		var cancelErr *dynamodb.TransactionCanceledException
+		if errors.As(err, &cancelErr) {
+			for _, reason := range cancelErr.CancellationReasons {
+				// transaction conflicts are reported as predicate failures - to retry
+				code := aws.StringValue(reason.Code)
+				if code == "ConditionalCheckFailed" || code == "TransactionConflict" {
+					return kv.ErrPredicateFailed
+				}
+			}
+		}
+		return fmt.Errorf("transact write items: %w", handleClientError(err))
+	}
+	for _, c := range resp.ConsumedCapacity {
+		if c.CapacityUnits != nil {
+			dynamoConsumedCapacity.WithLabelValues(operation).Add(*c.CapacityUnits)
+		}
+	}
+	s.publishBatch(ops)
+	return nil
+}
+
+// ListPartitions scans the table for the partition keys. A scan reads the whole table, it is meant for maintenance
+// operations and not for the serving path.
+func (s *Store) ListPartitions(ctx context.Context) ([][]byte, error) {
+	const operation = "Scan"
+	seen := make(map[string]struct{})
+	var partitions [][]byte
+	input := &dynamodb.ScanInput{
+		TableName:              aws.String(s.params.TableName),
+		ProjectionExpression:   aws.String(PartitionKey),
+		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+	}
+	for {
+		resp, err := s.svc.ScanWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", handleClientError(err))
+		}
+		if resp.ConsumedCapacity != nil {
+			dynamoConsumedCapacity.WithLabelValues(operation).Add(*resp.ConsumedCapacity.CapacityUnits)
+		}
+		for _, item := range resp.Items {
+			attr, ok := item[PartitionKey]
+			if !ok {
+				continue
+			}
+			if _, ok := seen[string(attr.B)]; ok {
+				continue
+			}
+			seen[string(attr.B)] = struct{}{}
+			partitions = append(partitions, attr.B)
+		}
+		if resp.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+	return partitions, nil
+}
+
 func (s *Store) Close() {
 	s.StopPeriodicCheck()
 }
@@ -0,0 +1,237 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+const (
+	// streamPollInterval is the time between reads of the table stream shards
+	streamPollInterval = time.Second
+	// streamRecordsLimit is the maximal number of records read from a shard by each poll
+	streamRecordsLimit = 1000
+)
+
+// publish reports a change written by this store to in-process watchers, when changes are not read from the table
+// stream
+func (s *Store) publish(ev kv.Event) {
+	if s.streamArn == "" {
+		s.watchHub.Publish(ev)
+	}
+}
+
+func (s *Store) publishBatch(ops []kv.BatchOp) {
+	if s.streamArn == "" {
+		s.watchHub.PublishBatch(ops)
+	}
+}
+
+// Watch reports the changes of keys. When the table has a stream, changes are read from it and include changes
+// written by other stores that share the table. Otherwise, only changes written by this store are reported.
+func (s *Store) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	if s.streamArn != "" {
+		if err := s.startPolling(ctx); err != nil {
+			return nil, fmt.Errorf("watch stream: %w", handleClientError(err))
+		}
+	}
+	return s.watchHub.Watch(ctx, partitionKey, prefix)
+}
+
+// startPolling polls the table stream, once. Returns after the shards are first listed, so changes written after it
+// are reported.
+func (s *Store) startPolling(ctx context.Context) error {
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+	if s.polling {
+		return nil
+	}
+	p := &streamPoller{store: s}
+	if err := p.listShards(ctx); err != nil {
+		return err
+	}
+	s.polling = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.pollStream(p)
+	}()
+	return nil
+}
+
+// streamPoller reads the shards of the table stream
+type streamPoller struct {
+	store *Store
+	// iterators holds the next iterator of each open shard being read
+	iterators map[string]*string
+	// done holds shards read to their end
+	done map[string]struct{}
+	// initialized is set after the first listing of the shards, shards found later are read from their start
+	initialized bool
+}
+
+// pollStream publishes the table stream records to the watchers until the store is closed. Watchers are reset when
+// the stream cannot be read, as records may be lost.
+func (s *Store) pollStream(p *streamPoller) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	closed := s.cancel
+	go func() {
+		<-closed
+		cancel()
+	}()
+	log := s.logger.WithField("stream_arn", s.streamArn)
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.poll(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Read table stream failed")
+			s.watchHub.Publish(kv.Event{Type: kv.EventReset})
+			p = &streamPoller{store: s}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *streamPoller) poll(ctx context.Context) error {
+	if !p.initialized || len(p.iterators) == 0 {
+		if err := p.listShards(ctx); err != nil {
+			return err
+		}
+	}
+	refresh := false
+	for shardID, iterator := range p.iterators {
+		resp, err := p.store.streams.GetRecordsWithContext(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int64(streamRecordsLimit),
+		})
+		if err != nil {
+			return err
+		}
+		for _, record := range resp.Records {
+			if ev, ok := recordEvent(record); ok {
+				p.store.watchHub.Publish(ev)
+			}
+		}
+		if resp.NextShardIterator == nil {
+			// the shard was closed, its children are read from their start
+			delete(p.iterators, shardID)
+			p.done[shardID] = struct{}{}
+			refresh = true
+			continue
+		}
+		p.iterators[shardID] = resp.NextShardIterator
+	}
+	if refresh {
+		return p.listShards(ctx)
+	}
+	return nil
+}
+
+// listShards starts reading shards of the stream that are not read yet. The first listing reads the open shards from
+// their latest record, later listings read new shards from their start.
+func (p *streamPoller) listShards(ctx context.Context) error {
+	if p.iterators == nil {
+		p.iterators = make(map[string]*string)
+		p.done = make(map[string]struct{})
+	}
+	var lastShardID *string
+	for {
+		resp, err := p.store.streams.DescribeStreamWithContext(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(p.store.streamArn),
+			ExclusiveStartShardId: lastShardID,
+		})
+		if err != nil {
+			return err
+		}
+		for _, shard := range resp.StreamDescription.Shards {
+			shardID := aws.StringValue(shard.ShardId)
+			if _, ok := p.iterators[shardID]; ok {
+				continue
+			}
+			if _, ok := p.done[shardID]; ok {
+				continue
+			}
+			iteratorType := dynamodbstreams.ShardIteratorTypeTrimHorizon
+			if !p.initialized {
+				if shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil {
+					// closed before we started watching
+					p.done[shardID] = struct{}{}
+					continue
+				}
+				iteratorType = dynamodbstreams.ShardIteratorTypeLatest
+			}
+			it, err := p.store.streams.GetShardIteratorWithContext(ctx, &dynamodbstreams.GetShardIteratorInput{
+				StreamArn:         aws.String(p.store.streamArn),
+				ShardId:           shard.ShardId,
+				ShardIteratorType: aws.String(iteratorType),
+			})
+			if err != nil {
+				return err
+			}
+			p.iterators[shardID] = it.ShardIterator
+		}
+		lastShardID = resp.StreamDescription.LastEvaluatedShardId
+		if lastShardID == nil {
+			break
+		}
+	}
+	p.initialized = true
+	return nil
+}
+
+// recordEvent returns the change event of a stream record
+func recordEvent(record *dynamodbstreams.Record) (kv.Event, bool) {
+	if record.Dynamodb == nil {
+		return kv.Event{}, false
+	}
+	partitionKey := record.Dynamodb.Keys[PartitionKey]
+	key := record.Dynamodb.Keys[ItemKey]
+	if partitionKey == nil || key == nil {
+		return kv.Event{}, false
+	}
+	ev := kv.Event{Type: kv.EventSet, PartitionKey: partitionKey.B, Key: key.B}
+	if aws.StringValue(record.EventName) == dynamodbstreams.OperationTypeRemove {
+		ev.Type = kv.EventDelete
+	}
+	return ev, true
+}
+
+// tableStreamArn returns the ARN of the table stream, empty when the table has no stream
+func tableStreamArn(ctx context.Context, svc *dynamodb.DynamoDB, table string) (string, error) {
+	desc, err := svc.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return "", handleClientError(err)
+	}
+	spec := desc.Table.StreamSpecification
+	if spec == nil || !aws.BoolValue(spec.StreamEnabled) {
+		return "", nil
+	}
+	return aws.StringValue(desc.Table.LatestStreamArn), nil
+}
+
+// enableTableStream enables the stream of an existing table, and returns its ARN
+func enableTableStream(ctx context.Context, svc *dynamodb.DynamoDB, table string) (string, error) {
+	out, err := svc.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+		TableName: aws.String(table),
+		StreamSpecification: &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(dynamodb.StreamViewTypeKeysOnly),
+		},
+	})
+	if err != nil {
+		return "", handleClientError(err)
+	}
+	return aws.StringValue(out.TableDescription.LatestStreamArn), nil
+}
@@ -0,0 +1,52 @@
+package kvmigrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+// PartitionChecksum summarizes the entries of a partition. Stores holding the same entries in a partition have the
+// same checksum for the partition.
+type PartitionChecksum struct {
+	Count    int
+	Checksum string
+}
+
+// ComputeChecksum scans the partition and returns the number of entries and a checksum over the keys and values,
+// in key order.
+func ComputeChecksum(ctx context.Context, store kv.Store, partitionKey []byte) (PartitionChecksum, error) {
+	it, err := store.Scan(ctx, partitionKey, kv.ScanOptions{})
+	if err != nil {
+		return PartitionChecksum{}, fmt.Errorf("scan partition %s: %w", partitionKey, err)
+	}
+	defer it.Close()
+	var count int
+	h := sha256.New()
+	for it.Next() {
+		entry := it.Entry()
+		writeLengthPrefixed(h, entry.Key)
+		writeLengthPrefixed(h, entry.Value)
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return PartitionChecksum{}, fmt.Errorf("scan partition %s: %w", partitionKey, err)
+	}
+	return PartitionChecksum{
+		Count:    count,
+		Checksum: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// writeLengthPrefixed writes the data with its length, so that different entries never hash the same bytes
+func writeLengthPrefixed(h hash.Hash, data []byte) {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+	_, _ = h.Write(lenBuf[:n])
+	_, _ = h.Write(data)
+}
@@ -0,0 +1,277 @@
+package kvmigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// DefaultPollInterval is the interval the dual-write store reads the migration state
+const DefaultPollInterval = time.Second
+
+// ErrStaleState is returned for writes while the store failed to read the migration state for too long, as the
+// migration may have frozen writes since
+var ErrStaleState = errors.New("migration state is stale")
+
+// DualWriteStore is a kv.Store that serves a store being migrated. Until the migration switches to the target store,
+// reads are served by the source store and writes are applied to the source store and then mirrored to the target
+// store. Mirroring is best effort, the migration verifies the target store and reconciles differences.
+// The store follows the migration state kept on the source store: while frozen writes wait, and once switched reads
+// and writes are served by the target store. Writes fail closed: they are refused while the state was not read
+// within the freeze grace of the migration less the poll interval, as the migration may have frozen writes unseen.
+type DualWriteStore struct {
+	source       kv.Store
+	target       kv.Store
+	logger       logging.Logger
+	pollInterval time.Duration
+
+	mu    sync.Mutex
+	phase Phase
+	// refreshedAt is the time of the last successful read of the state, and freezeGrace the grace it holds
+	refreshedAt time.Time
+	freezeGrace time.Duration
+	// changed is closed and replaced on each phase change
+	changed chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDualWriteStore returns a store that mirrors writes from source to target and follows the migration state. The
+// store closes both stores on Close.
+func NewDualWriteStore(ctx context.Context, source, target kv.Store, pollInterval time.Duration) (*DualWriteStore, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	refreshedAt := time.Now()
+	state, err := GetState(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	pollCtx, cancel := context.WithCancel(context.Background())
+	s := &DualWriteStore{
+		source:       source,
+		target:       target,
+		logger:       logging.FromContext(ctx).WithField("service_name", "kv_migrate"),
+		pollInterval: pollInterval,
+		phase:        state.Phase,
+		refreshedAt:  refreshedAt,
+		freezeGrace:  state.FreezeGrace,
+		changed:      make(chan struct{}),
+		cancel:       cancel,
+	}
+	s.wg.Add(1)
+	go s.poll(pollCtx)
+	return s, nil
+}
+
+func (s *DualWriteStore) poll(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		refreshedAt := time.Now()
+		state, err := GetState(ctx, s.source)
+		if err != nil {
+			s.logger.WithError(err).Warn("Failed to read migration state")
+			continue
+		}
+		s.setState(state, refreshedAt)
+	}
+}
+
+func (s *DualWriteStore) setState(state State, refreshedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshedAt = refreshedAt
+	s.freezeGrace = state.FreezeGrace
+	if s.phase == state.Phase {
+		return
+	}
+	s.logger.WithFields(logging.Fields{"from": s.phase, "to": state.Phase}).Info("Migration phase changed")
+	s.phase = state.Phase
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *DualWriteStore) current() (Phase, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phase, s.changed
+}
+
+// Phase returns the migration phase as last read by the store
+func (s *DualWriteStore) Phase() Phase {
+	phase, _ := s.current()
+	return phase
+}
+
+func (s *DualWriteStore) readStore() kv.Store {
+	if s.Phase() == PhaseSwitched {
+		return s.target
+	}
+	return s.source
+}
+
+// maxStaleness returns the time since the state was last read after which writes are refused. A migration writes
+// only after its freeze grace, the time left for in-flight writes is the poll interval.
+func (s *DualWriteStore) maxStaleness() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	freezeGrace := s.freezeGrace
+	if freezeGrace <= 0 {
+		freezeGrace = DefaultFreezeGrace
+	}
+	if freezeGrace < 2*s.pollInterval {
+		return s.pollInterval
+	}
+	return freezeGrace - s.pollInterval
+}
+
+func (s *DualWriteStore) stale() (time.Duration, bool) {
+	s.mu.Lock()
+	since := time.Since(s.refreshedAt)
+	s.mu.Unlock()
+	return since, since > s.maxStaleness()
+}
+
+// waitWritable waits while writes are frozen and returns the phase to write on. Writes are refused when the state is
+// stale, unless switched to the target store, which is final.
+func (s *DualWriteStore) waitWritable(ctx context.Context) (Phase, error) {
+	for {
+		phase, changed := s.current()
+		if phase == PhaseSwitched {
+			return phase, nil
+		}
+		if since, stale := s.stale(); stale {
+			return phase, fmt.Errorf("%w: last read %s ago", ErrStaleState, since.Round(time.Millisecond))
+		}
+		if phase != PhaseFrozen {
+			return phase, nil
+		}
+		select {
+		case <-ctx.Done():
+			return phase, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (s *DualWriteStore) logMirrorFailure(ctx context.Context, op string, partitionKey, key []byte, err error) {
+	if err == nil {
+		return
+	}
+	s.logger.WithContext(ctx).WithFields(logging.Fields{
+		"op":            op,
+		"partition_key": string(partitionKey),
+		"key":           string(key),
+	}).WithError(err).Warn("Failed to mirror write to target store")
+}
+
+func (s *DualWriteStore) Get(ctx context.Context, partitionKey, key []byte) (*kv.ValueWithPredicate, error) {
+	return s.readStore().Get(ctx, partitionKey, key)
+}
+
+func (s *DualWriteStore) Set(ctx context.Context, partitionKey, key, value []byte) error {
+	phase, err := s.waitWritable(ctx)
+	if err != nil {
+		return err
+	}
+	if phase == PhaseSwitched {
+		return s.target.Set(ctx, partitionKey, key, value)
+	}
+	if err := s.source.Set(ctx, partitionKey, key, value); err != nil {
+		return err
+	}
+	s.logMirrorFailure(ctx, "set", partitionKey, key, s.target.Set(ctx, partitionKey, key, value))
+	return nil
+}
+
+func (s *DualWriteStore) SetIf(ctx context.Context, partitionKey, key, value []byte, valuePredicate kv.Predicate) error {
+	phase, err := s.waitWritable(ctx)
+	if err != nil {
+		return err
+	}
+	if phase == PhaseSwitched {
+		return s.target.SetIf(ctx, partitionKey, key, value, valuePredicate)
+	}
+	if err := s.source.SetIf(ctx, partitionKey, key, value, valuePredicate); err != nil {
+		return err
+	}
+	// the predicate was checked by the source store, which the target store follows
+	s.logMirrorFailure(ctx, "set_if", partitionKey, key, s.target.Set(ctx, partitionKey, key, value))
+	return nil
+}
+
+func (s *DualWriteStore) Delete(ctx context.Context, partitionKey, key []byte) error {
+	phase, err := s.waitWritable(ctx)
+	if err != nil {
+		return err
+	}
+	if phase == PhaseSwitched {
+		return s.target.Delete(ctx, partitionKey, key)
+	}
+	if err := s.source.Delete(ctx, partitionKey, key); err != nil {
+		return err
+	}
+	s.logMirrorFailure(ctx, "delete", partitionKey, key, s.target.Delete(ctx, partitionKey, key))
+	return nil
+}
+
+func (s *DualWriteStore) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	phase, err := s.waitWritable(ctx)
+	if err != nil {
+		return err
+	}
+	if phase == PhaseSwitched {
+		return kv.WriteBatch(ctx, s.target, ops)
+	}
+	if err := kv.WriteBatch(ctx, s.source, ops); err != nil {
+		return err
+	}
+	// the predicates were checked by the source store, which the target store follows
+	mirrorOps := make([]kv.BatchOp, len(ops))
+	for i, op := range ops {
+		mirrorOps[i] = kv.BatchOp{PartitionKey: op.PartitionKey, Key: op.Key, Value: op.Value}
+	}
+	if err := kv.WriteBatchBestEffort(ctx, s.target, mirrorOps); err != nil {
+		s.logger.WithContext(ctx).WithField("op", "write_batch").WithError(err).Warn("Failed to mirror write to target store")
+	}
+	return nil
+}
+
+func (s *DualWriteStore) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOptions) (kv.EntriesIterator, error) {
+	return s.readStore().Scan(ctx, partitionKey, options)
+}
+
+func (s *DualWriteStore) ListPartitions(ctx context.Context) ([][]byte, error) {
+	return kv.ListPartitions(ctx, s.readStore())
+}
+
+// Watch watches the store reads are served from
+func (s *DualWriteStore) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	return kv.Watch(ctx, s.readStore(), partitionKey, prefix)
+}
+
+// NewMigrator returns a migrator between the stores of the dual-write store, to migrate from the process that holds
+// them
+func (s *DualWriteStore) NewMigrator(cfg Config) *Migrator {
+	return NewMigrator(s.source, s.target, cfg)
+}
+
+func (s *DualWriteStore) Close() {
+	s.cancel()
+	s.wg.Wait()
+	s.source.Close()
+	s.target.Close()
+}
@@ -0,0 +1,404 @@
+package kvmigrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultWorkers     = 8
+	DefaultRounds      = 3
+	DefaultFreezeGrace = 3 * DefaultPollInterval
+)
+
+var (
+	ErrAlreadySwitched = errors.New("migration already switched to the target store")
+	ErrVerifyFailed    = errors.New("target store does not match source store")
+)
+
+type Config struct {
+	// Workers - number of partitions copied, verified or reconciled in parallel
+	Workers int
+	// Rounds - maximal number of verify and reconcile rounds while writes are mirrored, before freezing writes
+	Rounds int
+	// FreezeGrace - time to wait after freezing writes, for every lakeFS server to read the frozen state and complete
+	// in-flight writes. Should be at least twice the poll interval of the servers: a server refuses writes once it
+	// failed to read the state for the freeze grace less its poll interval.
+	FreezeGrace time.Duration
+}
+
+// PartitionResult is the verification of a partition on both stores
+type PartitionResult struct {
+	PartitionKey []byte
+	Source       PartitionChecksum
+	Target       PartitionChecksum
+}
+
+func (r PartitionResult) Match() bool {
+	return r.Source == r.Target
+}
+
+// Report summarizes a migration
+type Report struct {
+	Partitions int
+	// Copied - number of entries copied to the target store
+	Copied int64
+	// Reconciled - number of entries set or deleted on the target store to match the source store
+	Reconciled int64
+	// Frozen - duration writes were frozen
+	Frozen time.Duration
+}
+
+// Migrator copies the entries of a source kv store to a target kv store, while lakeFS servers keep serving from the
+// source store using a DualWriteStore, and switches the servers to the target store once it is verified.
+type Migrator struct {
+	source kv.Store
+	target kv.Store
+	cfg    Config
+}
+
+func NewMigrator(source, target kv.Store, cfg Config) *Migrator {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.Rounds <= 0 {
+		cfg.Rounds = DefaultRounds
+	}
+	if cfg.FreezeGrace <= 0 {
+		cfg.FreezeGrace = DefaultFreezeGrace
+	}
+	return &Migrator{
+		source: source,
+		target: target,
+		cfg:    cfg,
+	}
+}
+
+// Run migrates the source store to the target store: copies all partitions, verifies and reconciles them while writes
+// are mirrored, then freezes writes for a final verification and switches to the target store.
+func (m *Migrator) Run(ctx context.Context) (*Report, error) {
+	log := logging.FromContext(ctx).WithField("service_name", "kv_migrate")
+	state, err := GetState(ctx, m.source)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase == PhaseSwitched {
+		return nil, ErrAlreadySwitched
+	}
+	if err := m.setPhase(ctx, PhaseCopy); err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	log.Info("Copy partitions")
+	report.Copied, err = m.Copy(ctx)
+	if err != nil {
+		return report, err
+	}
+	for round := 0; round < m.cfg.Rounds; round++ {
+		results, err := m.Verify(ctx)
+		if err != nil {
+			return report, err
+		}
+		mismatched := Mismatched(results)
+		log.WithFields(logging.Fields{"round": round, "mismatched": len(mismatched)}).Info("Verified partitions")
+		if len(mismatched) == 0 {
+			break
+		}
+		reconciled, err := m.Reconcile(ctx, partitionKeys(mismatched))
+		report.Reconciled += reconciled
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// freeze writes for the final verification, unfreeze unless switched
+	log.Info("Freeze writes")
+	if err := m.setPhase(ctx, PhaseFrozen); err != nil {
+		return report, err
+	}
+	frozenAt := time.Now()
+	switched := false
+	defer func() {
+		report.Frozen = time.Since(frozenAt)
+		if switched {
+			return
+		}
+		if err := m.setPhase(context.Background(), PhaseCopy); err != nil {
+			log.WithError(err).Error("Failed to unfreeze writes")
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return report, ctx.Err()
+	case <-time.After(m.cfg.FreezeGrace):
+	}
+	results, err := m.Verify(ctx)
+	if err != nil {
+		return report, err
+	}
+	if mismatched := Mismatched(results); len(mismatched) > 0 {
+		reconciled, err := m.Reconcile(ctx, partitionKeys(mismatched))
+		report.Reconciled += reconciled
+		if err != nil {
+			return report, err
+		}
+		results, err = m.Verify(ctx)
+		if err != nil {
+			return report, err
+		}
+		if mismatched := Mismatched(results); len(mismatched) > 0 {
+			return report, fmt.Errorf("%d partitions, first %s: %w", len(mismatched), mismatched[0].PartitionKey, ErrVerifyFailed)
+		}
+	}
+	report.Partitions = len(results)
+
+	if err := m.setPhase(ctx, PhaseSwitched); err != nil {
+		return report, err
+	}
+	switched = true
+	log.WithField("partitions", report.Partitions).Info("Switched to target store")
+	return report, nil
+}
+
+// Abort ends a migration that did not switch yet. Writes are still mirrored by lakeFS servers configured to migrate.
+func (m *Migrator) Abort(ctx context.Context) error {
+	state, err := GetState(ctx, m.source)
+	if err != nil {
+		return err
+	}
+	if state.Phase == PhaseSwitched {
+		return ErrAlreadySwitched
+	}
+	return m.setPhase(ctx, PhaseNone)
+}
+
+// setPhase keeps the migration phase on the source store, with the freeze grace of the migration
+func (m *Migrator) setPhase(ctx context.Context, phase Phase) error {
+	return SetState(ctx, m.source, State{Phase: phase, FreezeGrace: m.cfg.FreezeGrace})
+}
+
+// Copy copies the entries of the source store partitions to the target store, returns the number of entries copied.
+// Entries already on the target store are kept, as they were mirrored by a later write.
+func (m *Migrator) Copy(ctx context.Context) (int64, error) {
+	partitions, err := m.partitions(ctx, m.source)
+	if err != nil {
+		return 0, err
+	}
+	var copied int64
+	err = m.forEachPartition(ctx, partitions, func(ctx context.Context, partitionKey []byte) error {
+		n, err := m.copyPartition(ctx, partitionKey)
+		atomic.AddInt64(&copied, n)
+		return err
+	})
+	return copied, err
+}
+
+func (m *Migrator) copyPartition(ctx context.Context, partitionKey []byte) (int64, error) {
+	it, err := m.source.Scan(ctx, partitionKey, kv.ScanOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("scan partition %s: %w", partitionKey, err)
+	}
+	defer it.Close()
+	var copied int64
+	for it.Next() {
+		entry := it.Entry()
+		err := m.target.SetIf(ctx, partitionKey, entry.Key, entry.Value, nil)
+		if errors.Is(err, kv.ErrPredicateFailed) {
+			continue
+		}
+		if err != nil {
+			return copied, fmt.Errorf("copy %s/%s: %w", partitionKey, entry.Key, err)
+		}
+		copied++
+	}
+	if err := it.Err(); err != nil {
+		return copied, fmt.Errorf("scan partition %s: %w", partitionKey, err)
+	}
+	return copied, nil
+}
+
+// Verify compares the checksums of the partitions found on either store
+func (m *Migrator) Verify(ctx context.Context) ([]PartitionResult, error) {
+	sourcePartitions, err := m.partitions(ctx, m.source)
+	if err != nil {
+		return nil, err
+	}
+	targetPartitions, err := m.partitions(ctx, m.target)
+	if err != nil {
+		return nil, err
+	}
+	partitions := unionPartitions(sourcePartitions, targetPartitions)
+
+	// each partition result is set by a single worker
+	results := make([]PartitionResult, len(partitions))
+	idx := make(map[string]int, len(partitions))
+	for i, p := range partitions {
+		idx[string(p)] = i
+	}
+	err = m.forEachPartition(ctx, partitions, func(ctx context.Context, partitionKey []byte) error {
+		sourceChecksum, err := ComputeChecksum(ctx, m.source, partitionKey)
+		if err != nil {
+			return err
+		}
+		targetChecksum, err := ComputeChecksum(ctx, m.target, partitionKey)
+		if err != nil {
+			return err
+		}
+		results[idx[string(partitionKey)]] = PartitionResult{
+			PartitionKey: partitionKey,
+			Source:       sourceChecksum,
+			Target:       targetChecksum,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Reconcile sets the target store partitions to match the source store, returns the number of entries set or deleted
+func (m *Migrator) Reconcile(ctx context.Context, partitions [][]byte) (int64, error) {
+	var reconciled int64
+	err := m.forEachPartition(ctx, partitions, func(ctx context.Context, partitionKey []byte) error {
+		n, err := m.reconcilePartition(ctx, partitionKey)
+		atomic.AddInt64(&reconciled, n)
+		return err
+	})
+	return reconciled, err
+}
+
+func (m *Migrator) reconcilePartition(ctx context.Context, partitionKey []byte) (int64, error) {
+	sourceIt, err := m.source.Scan(ctx, partitionKey, kv.ScanOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("scan source partition %s: %w", partitionKey, err)
+	}
+	defer sourceIt.Close()
+	targetIt, err := m.target.Scan(ctx, partitionKey, kv.ScanOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("scan target partition %s: %w", partitionKey, err)
+	}
+	defer targetIt.Close()
+
+	var reconciled int64
+	hasSource := sourceIt.Next()
+	hasTarget := targetIt.Next()
+	for hasSource || hasTarget {
+		var cmp int
+		switch {
+		case !hasTarget:
+			cmp = -1
+		case !hasSource:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(sourceIt.Entry().Key, targetIt.Entry().Key)
+		}
+		switch {
+		case cmp < 0:
+			// missing on target
+			entry := sourceIt.Entry()
+			if err := m.target.Set(ctx, partitionKey, entry.Key, entry.Value); err != nil {
+				return reconciled, fmt.Errorf("set %s/%s: %w", partitionKey, entry.Key, err)
+			}
+			reconciled++
+			hasSource = sourceIt.Next()
+		case cmp > 0:
+			// deleted from source
+			entry := targetIt.Entry()
+			if err := m.target.Delete(ctx, partitionKey, entry.Key); err != nil {
+				return reconciled, fmt.Errorf("delete %s/%s: %w", partitionKey, entry.Key, err)
+			}
+			reconciled++
+			hasTarget = targetIt.Next()
+		default:
+			entry := sourceIt.Entry()
+			if !bytes.Equal(entry.Value, targetIt.Entry().Value) {
+				if err := m.target.Set(ctx, partitionKey, entry.Key, entry.Value); err != nil {
+					return reconciled, fmt.Errorf("set %s/%s: %w", partitionKey, entry.Key, err)
+				}
+				reconciled++
+			}
+			hasSource = sourceIt.Next()
+			hasTarget = targetIt.Next()
+		}
+	}
+	if err := sourceIt.Err(); err != nil {
+		return reconciled, fmt.Errorf("scan source partition %s: %w", partitionKey, err)
+	}
+	if err := targetIt.Err(); err != nil {
+		return reconciled, fmt.Errorf("scan target partition %s: %w", partitionKey, err)
+	}
+	return reconciled, nil
+}
+
+// partitions returns the partitions of the store to migrate
+func (m *Migrator) partitions(ctx context.Context, store kv.Store) ([][]byte, error) {
+	partitions, err := kv.ListPartitions(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	res := partitions[:0]
+	for _, p := range partitions {
+		if string(p) != StatePartitionKey {
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
+
+func (m *Migrator) forEachPartition(ctx context.Context, partitions [][]byte, fn func(ctx context.Context, partitionKey []byte) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(m.cfg.Workers)
+	for _, p := range partitions {
+		partitionKey := p
+		g.Go(func() error {
+			return fn(ctx, partitionKey)
+		})
+	}
+	return g.Wait()
+}
+
+// Mismatched returns the results of partitions that do not match
+func Mismatched(results []PartitionResult) []PartitionResult {
+	var mismatched []PartitionResult
+	for _, r := range results {
+		if !r.Match() {
+			mismatched = append(mismatched, r)
+		}
+	}
+	return mismatched
+}
+
+func partitionKeys(results []PartitionResult) [][]byte {
+	keys := make([][]byte, len(results))
+	for i, r := range results {
+		keys[i] = r.PartitionKey
+	}
+	return keys
+}
+
+func unionPartitions(a, b [][]byte) [][]byte {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	var res [][]byte
+	for _, partitions := range [][][]byte{a, b} {
+		for _, p := range partitions {
+			if _, ok := seen[string(p)]; ok {
+				continue
+			}
+			seen[string(p)] = struct{}{}
+			res = append(res, p)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i], res[j]) < 0 })
+	return res
+}
@@ -0,0 +1,221 @@
+package kvmigrate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvmigrate"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+)
+
+const testPollInterval = 10 * time.Millisecond
+
+func setupPartitions(t *testing.T, ctx context.Context, store kv.Store, partitions, entries int) {
+	t.Helper()
+	for p := 0; p < partitions; p++ {
+		for i := 0; i < entries; i++ {
+			err := store.Set(ctx, []byte(fmt.Sprintf("partition-%d", p)), []byte(fmt.Sprintf("key-%04d", i)), []byte(fmt.Sprintf("value-%d", i)))
+			require.NoError(t, err)
+		}
+	}
+}
+
+func requireSameStores(t *testing.T, ctx context.Context, source, target kv.Store) {
+	t.Helper()
+	results, err := kvmigrate.NewMigrator(source, target, kvmigrate.Config{}).Verify(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	require.Empty(t, kvmigrate.Mismatched(results))
+}
+
+func TestMigrator_CopyVerifyReconcile(t *testing.T) {
+	ctx := context.Background()
+	source := kvtest.GetStore(ctx, t)
+	target := kvtest.GetStore(ctx, t)
+	setupPartitions(t, ctx, source, 5, 50)
+	// stale and extra entries on the target
+	require.NoError(t, target.Set(ctx, []byte("partition-1"), []byte("key-0001"), []byte("stale")))
+	require.NoError(t, target.Set(ctx, []byte("partition-1"), []byte("key-9999"), []byte("extra")))
+	require.NoError(t, target.Set(ctx, []byte("partition-extra"), []byte("key"), []byte("extra")))
+
+	m := kvmigrate.NewMigrator(source, target, kvmigrate.Config{Workers: 2})
+	copied, err := m.Copy(ctx)
+	require.NoError(t, err)
+	// the stale entry is kept, as if mirrored by a later write
+	require.EqualValues(t, 5*50-1, copied)
+
+	results, err := m.Verify(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+	mismatched := kvmigrate.Mismatched(results)
+	require.Len(t, mismatched, 2)
+	require.Equal(t, "partition-1", string(mismatched[0].PartitionKey))
+	require.Equal(t, 51, mismatched[0].Target.Count)
+	require.Equal(t, "partition-extra", string(mismatched[1].PartitionKey))
+
+	reconciled, err := m.Reconcile(ctx, [][]byte{mismatched[0].PartitionKey, mismatched[1].PartitionKey})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, reconciled)
+	requireSameStores(t, ctx, source, target)
+}
+
+func TestMigrator_Run(t *testing.T) {
+	ctx := context.Background()
+	source := kvtest.GetStore(ctx, t)
+	target := kvtest.GetStore(ctx, t)
+	setupPartitions(t, ctx, source, 10, 100)
+
+	store, err := kvmigrate.NewDualWriteStore(ctx, source, target, testPollInterval)
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	// write while migrating
+	writeCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; writeCtx.Err() == nil; i++ {
+			partition := []byte(fmt.Sprintf("partition-%d", i%10))
+			key := []byte(fmt.Sprintf("key-%04d", i%200))
+			var err error
+			if i%7 == 0 {
+				err = store.Delete(writeCtx, partition, key)
+			} else {
+				err = store.Set(writeCtx, partition, key, []byte(fmt.Sprintf("written-%d", i)))
+			}
+			// a write is refused when the poll of the state lags, the writer may retry
+			if errors.Is(err, kvmigrate.ErrStaleState) {
+				continue
+			}
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("write %d: %s", i, err)
+				return
+			}
+		}
+	}()
+
+	m := store.NewMigrator(kvmigrate.Config{FreezeGrace: 5 * testPollInterval})
+	report, err := m.Run(ctx)
+	cancel()
+	wg.Wait()
+	require.NoError(t, err)
+	require.Equal(t, 10, report.Partitions)
+
+	state, err := kvmigrate.GetState(ctx, source)
+	require.NoError(t, err)
+	require.Equal(t, kvmigrate.PhaseSwitched, state.Phase)
+	require.Eventually(t, func() bool {
+		return store.Phase() == kvmigrate.PhaseSwitched
+	}, time.Second, testPollInterval)
+
+	// writes after the switch go to the target store only
+	require.NoError(t, store.Set(ctx, []byte("partition-0"), []byte("after-switch"), []byte("value")))
+	_, err = source.Get(ctx, []byte("partition-0"), []byte("after-switch"))
+	require.ErrorIs(t, err, kv.ErrNotFound)
+	_, err = target.Get(ctx, []byte("partition-0"), []byte("after-switch"))
+	require.NoError(t, err)
+
+	_, err = m.Run(ctx)
+	require.ErrorIs(t, err, kvmigrate.ErrAlreadySwitched)
+}
+
+// stateFailingStore fails reading the migration state when set to fail
+type stateFailingStore struct {
+	kv.Store
+	fail atomic.Bool
+}
+
+var errStateUnavailable = errors.New("state unavailable")
+
+func (s *stateFailingStore) Get(ctx context.Context, partitionKey, key []byte) (*kv.ValueWithPredicate, error) {
+	if s.fail.Load() && string(partitionKey) == kvmigrate.StatePartitionKey {
+		return nil, errStateUnavailable
+	}
+	return s.Store.Get(ctx, partitionKey, key)
+}
+
+func TestDualWriteStore(t *testing.T) {
+	ctx := context.Background()
+	source := &stateFailingStore{Store: kvtest.GetStore(ctx, t)}
+	target := kvtest.GetStore(ctx, t)
+	store, err := kvmigrate.NewDualWriteStore(ctx, source, target, testPollInterval)
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	partition := []byte("partition")
+	key := []byte("key")
+	t.Run("mirror", func(t *testing.T) {
+		require.NoError(t, store.SetIf(ctx, partition, key, []byte("value"), nil))
+		for _, s := range []kv.Store{source, target} {
+			res, err := s.Get(ctx, partition, key)
+			require.NoError(t, err)
+			require.Equal(t, "value", string(res.Value))
+		}
+		// predicates are checked on the source store
+		err := store.SetIf(ctx, partition, key, []byte("other"), nil)
+		require.ErrorIs(t, err, kv.ErrPredicateFailed)
+
+		require.NoError(t, store.Delete(ctx, partition, key))
+		for _, s := range []kv.Store{source, target} {
+			_, err := s.Get(ctx, partition, key)
+			require.ErrorIs(t, err, kv.ErrNotFound)
+		}
+	})
+
+	t.Run("frozen", func(t *testing.T) {
+		require.NoError(t, kvmigrate.SetPhase(ctx, source, kvmigrate.PhaseFrozen))
+		require.Eventually(t, func() bool {
+			return store.Phase() == kvmigrate.PhaseFrozen
+		}, time.Second, testPollInterval)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*testPollInterval)
+		defer cancel()
+		err := store.Set(timeoutCtx, partition, key, []byte("value"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// a write waits for the writes to unfreeze
+		done := make(chan error, 1)
+		go func() {
+			done <- store.Set(ctx, partition, key, []byte("value"))
+		}()
+		require.NoError(t, kvmigrate.SetPhase(ctx, source, kvmigrate.PhaseCopy))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("write did not complete after unfreeze")
+		}
+		_, err = target.Get(ctx, partition, key)
+		require.NoError(t, err)
+	})
+	t.Run("stale", func(t *testing.T) {
+		freezeGrace := 5 * testPollInterval
+		require.NoError(t, kvmigrate.SetState(ctx, source, kvmigrate.State{Phase: kvmigrate.PhaseCopy, FreezeGrace: freezeGrace}))
+		// wait for a read of the freeze grace
+		time.Sleep(2 * testPollInterval)
+		require.NoError(t, store.Set(ctx, partition, key, []byte("value")))
+
+		// writes fail closed once the state is not read for the freeze grace, the migration may have frozen them
+		source.fail.Store(true)
+		time.Sleep(freezeGrace)
+		err := store.Set(ctx, partition, key, []byte("stale"))
+		require.ErrorIs(t, err, kvmigrate.ErrStaleState)
+		require.ErrorIs(t, store.Delete(ctx, partition, key), kvmigrate.ErrStaleState)
+		res, err := source.Get(ctx, partition, key)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(res.Value))
+
+		source.fail.Store(false)
+		require.Eventually(t, func() bool {
+			return store.Set(ctx, partition, key, []byte("fresh")) == nil
+		}, time.Second, testPollInterval)
+	})
+}
@@ -0,0 +1,75 @@
+package kvmigrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+// Phase is the phase of a migration between kv stores
+type Phase string
+
+const (
+	// PhaseNone - no migration, or a migration was aborted. Writes are mirrored to the target store when one is set.
+	PhaseNone Phase = ""
+	// PhaseCopy - partitions are copied to the target store, while writes are mirrored to it
+	PhaseCopy Phase = "copy"
+	// PhaseFrozen - writes are blocked until the target store is verified
+	PhaseFrozen Phase = "frozen"
+	// PhaseSwitched - the target store is verified, reads and writes go to the target store
+	PhaseSwitched Phase = "switched"
+)
+
+const (
+	// StatePartitionKey is the partition of the source store that keeps the migration state. The partition is not
+	// migrated.
+	StatePartitionKey = "kv-migration"
+	stateKey          = "state"
+)
+
+// State is the migration state, kept on the source store so that every lakeFS server mirroring writes sees it
+type State struct {
+	Phase     Phase     `json:"phase"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// FreezeGrace - the time the migration waits after freezing writes. Servers refuse writes once they fail to read
+	// the state for longer than that, as they may have missed the freeze.
+	FreezeGrace time.Duration `json:"freeze_grace,omitempty"`
+}
+
+// GetState returns the migration state kept on the store, the zero state when no migration started
+func GetState(ctx context.Context, store kv.Store) (State, error) {
+	var state State
+	res, err := store.Get(ctx, []byte(StatePartitionKey), []byte(stateKey))
+	if errors.Is(err, kv.ErrNotFound) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("get migration state: %w", err)
+	}
+	if err := json.Unmarshal(res.Value, &state); err != nil {
+		return state, fmt.Errorf("parse migration state: %w", err)
+	}
+	return state, nil
+}
+
+// SetPhase keeps the migration phase on the store, with the default freeze grace
+func SetPhase(ctx context.Context, store kv.Store, phase Phase) error {
+	return SetState(ctx, store, State{Phase: phase})
+}
+
+// SetState keeps the migration state on the store, updated now
+func SetState(ctx context.Context, store kv.Store, state State) error {
+	state.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(ctx, []byte(StatePartitionKey), []byte(stateKey), data); err != nil {
+		return fmt.Errorf("set migration state: %w", err)
+	}
+	return nil
+}
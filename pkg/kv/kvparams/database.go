@@ -15,6 +15,7 @@ type Config struct {
 	DynamoDB *DynamoDB
 	Local    *Local
 	CosmosDB *CosmosDB
+	Bolt     *Bolt
 }
 
 type Local struct {
@@ -28,6 +29,17 @@ type Local struct {
 	EnableLogging bool
 }
 
+type Bolt struct {
+	// Path - Local file path of the database
+	Path string
+	// SyncWrites - Sync the database file on each transaction commit
+	SyncWrites bool
+	// ScanBatchSize - Number of entries read by each read transaction while iterating
+	ScanBatchSize int
+	// EnableLogging - Enable store trace logging
+	EnableLogging bool
+}
+
 type Postgres struct {
 	ConnectionString      string
 	MaxOpenConnections    int32
@@ -35,6 +47,11 @@ type Postgres struct {
 	ConnectionMaxLifetime time.Duration
 	ScanPageSize          int
 	Metrics               bool
+	// NotifyChanges - Notify other processes that share the database of the keys each write changes, for their
+	// watchers. Adds a notification to each write.
+	NotifyChanges bool
+	// NotifyPartitions - Partitions whose changes are notified, all partitions when empty
+	NotifyPartitions []string
 }
 
 type DynamoDB struct {
@@ -94,6 +111,8 @@ func NewConfig(cfg *config.Config) (Config, error) {
 			MaxIdleConnections:    cfg.Database.Postgres.MaxIdleConnections,
 			MaxOpenConnections:    cfg.Database.Postgres.MaxOpenConnections,
 			ConnectionMaxLifetime: cfg.Database.Postgres.ConnectionMaxLifetime,
+			NotifyChanges:         cfg.Database.Postgres.NotifyChanges,
+			NotifyPartitions:      cfg.Database.Postgres.NotifyPartitions,
 		}
 	}
 
@@ -120,5 +139,18 @@ func NewConfig(cfg *config.Config) (Config, error) {
 		}
 	}
 
+	if cfg.Database.Bolt != nil {
+		boltPath, err := homedir.Expand(cfg.Database.Bolt.Path)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse database bolt path '%s': %w", cfg.Database.Bolt.Path, err)
+		}
+		p.Bolt = &Bolt{
+			Path:          boltPath,
+			SyncWrites:    cfg.Database.Bolt.SyncWrites,
+			ScanBatchSize: cfg.Database.Bolt.ScanBatchSize,
+			EnableLogging: cfg.Database.Bolt.EnableLogging,
+		}
+	}
+
 	return p, nil
 }
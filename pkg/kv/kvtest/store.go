@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	nanoid "github.com/matoous/go-nanoid/v2"
@@ -66,6 +67,9 @@ func DriverTest(t *testing.T, name string, params kvparams.Config) {
 	t.Run("PartitionIterator", func(t *testing.T) { testPartitionIterator(t, ms) })
 	t.Run("PrimaryIterator", func(t *testing.T) { testPrimaryIterator(t, ms) })
 	t.Run("SecondaryIterator", func(t *testing.T) { testSecondaryIterator(t, ms) })
+	t.Run("ListPartitions", func(t *testing.T) { testListPartitions(t, ms) })
+	t.Run("WriteBatch", func(t *testing.T) { testWriteBatch(t, ms) })
+	t.Run("Watch", func(t *testing.T) { testWatch(t, ms) })
 }
 
 func testDriverOpen(t *testing.T, ms MakeStore) {
@@ -667,6 +671,181 @@ func testScanPrefix(t *testing.T, ms MakeStore) {
 	testCompareEntries(t, entries, sampleData)
 }
 
+func testListPartitions(t *testing.T, ms MakeStore) {
+	ctx := context.Background()
+	store := ms(t, ctx)
+	defer store.Close()
+
+	// the store may be shared with other tests, look for the partitions created by this test
+	const numberOfPartitions = 5
+	expected := make(map[string]bool)
+	for i := 0; i < numberOfPartitions; i++ {
+		partition := string(uniqueKey("list-partitions"))
+		_ = setupSampleData(t, ctx, store, partition, "key", 3)
+		expected[partition] = false
+	}
+
+	partitions, err := kv.ListPartitions(ctx, store)
+	if errors.Is(err, kv.ErrListPartitionsNotSupported) {
+		t.Skip("store does not support listing partitions")
+	}
+	if err != nil {
+		t.Fatal("ListPartitions failed", err)
+	}
+	for _, partition := range partitions {
+		found, ok := expected[string(partition)]
+		if !ok {
+			continue
+		}
+		if found {
+			t.Errorf("ListPartitions returned partition %s more than once", partition)
+		}
+		expected[string(partition)] = true
+	}
+	for partition, found := range expected {
+		if !found {
+			t.Errorf("ListPartitions missing partition %s", partition)
+		}
+	}
+}
+
+func testWriteBatch(t *testing.T, ms MakeStore) {
+	ctx := context.Background()
+	store := ms(t, ctx)
+	defer store.Close()
+
+	partition := uniqueKey("write-batch")
+	requireValue := func(t *testing.T, key string, expected string) {
+		t.Helper()
+		res, err := store.Get(ctx, partition, []byte(key))
+		if expected == "" {
+			require.ErrorIs(t, err, kv.ErrNotFound, "key %s", key)
+			return
+		}
+		require.NoError(t, err, "key %s", key)
+		require.Equal(t, expected, string(res.Value), "key %s", key)
+	}
+	require.NoError(t, store.Set(ctx, partition, []byte("a"), []byte("a1")))
+
+	t.Run("write", func(t *testing.T) {
+		err := kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetIfOp(partition, []byte("b"), []byte("b1"), nil),
+			kv.SetOp(partition, []byte("c"), []byte("c1")),
+			kv.DeleteOp(partition, []byte("a")),
+			kv.DeleteOp(partition, []byte("missing")),
+		})
+		require.NoError(t, err)
+		requireValue(t, "a", "")
+		requireValue(t, "b", "b1")
+		requireValue(t, "c", "c1")
+	})
+
+	t.Run("predicate_failed", func(t *testing.T) {
+		err := kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, []byte("d"), []byte("d1")),
+			kv.DeleteOp(partition, []byte("c")),
+			kv.SetIfOp(partition, []byte("b"), []byte("b2"), nil),
+		})
+		require.ErrorIs(t, err, kv.ErrPredicateFailed)
+		requireValue(t, "b", "b1")
+		requireValue(t, "c", "c1")
+		requireValue(t, "d", "")
+
+		err = kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, []byte("d"), []byte("d1")),
+			kv.DeleteIfOp(partition, []byte("missing"), kv.PrecondConditionalExists),
+		})
+		require.ErrorIs(t, err, kv.ErrPredicateFailed)
+		requireValue(t, "d", "")
+	})
+
+	t.Run("predicates", func(t *testing.T) {
+		b, err := store.Get(ctx, partition, []byte("b"))
+		require.NoError(t, err)
+		c, err := store.Get(ctx, partition, []byte("c"))
+		require.NoError(t, err)
+		err = kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetIfOp(partition, []byte("b"), []byte("b2"), b.Predicate),
+			kv.DeleteIfOp(partition, []byte("c"), c.Predicate),
+			kv.SetIfOp(partition, []byte("d"), []byte("d1"), nil),
+		})
+		require.NoError(t, err)
+		requireValue(t, "b", "b2")
+		requireValue(t, "c", "")
+		requireValue(t, "d", "d1")
+
+		// stale predicate
+		err = kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetIfOp(partition, []byte("d"), []byte("d2"), kv.PrecondConditionalExists),
+			kv.SetIfOp(partition, []byte("b"), []byte("b3"), b.Predicate),
+		})
+		require.ErrorIs(t, err, kv.ErrPredicateFailed)
+		requireValue(t, "b", "b2")
+		requireValue(t, "d", "d1")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, []byte("e"), []byte("e1")),
+			kv.DeleteOp(partition, []byte("e")),
+		})
+		require.ErrorIs(t, err, kv.ErrInvalidBatch)
+		err = kv.WriteBatch(ctx, store, []kv.BatchOp{
+			kv.SetOp(partition, nil, []byte("e1")),
+		})
+		require.ErrorIs(t, err, kv.ErrMissingKey)
+		requireValue(t, "e", "")
+	})
+}
+
+func testWatch(t *testing.T, ms MakeStore) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := ms(t, ctx)
+	defer store.Close()
+
+	partition := uniqueKey("watch")
+	events, err := kv.Watch(ctx, store, partition, []byte("a/"))
+	if errors.Is(err, kv.ErrWatchNotSupported) {
+		t.Skip("store does not support watch")
+	}
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, partition, []byte("a/1"), []byte("v1")))
+	require.NoError(t, store.Set(ctx, partition, []byte("b/1"), []byte("v1")))
+	require.NoError(t, store.Set(ctx, uniqueKey("watch-other"), []byte("a/1"), []byte("v1")))
+	require.NoError(t, store.Delete(ctx, partition, []byte("a/1")))
+	require.NoError(t, kv.WriteBatch(ctx, store, []kv.BatchOp{
+		kv.SetOp(partition, []byte("a/2"), []byte("v2")),
+		kv.SetOp(partition, []byte("b/2"), []byte("v2")),
+	}))
+
+	expected := []kv.Event{
+		{Type: kv.EventSet, PartitionKey: partition, Key: []byte("a/1")},
+		{Type: kv.EventDelete, PartitionKey: partition, Key: []byte("a/1")},
+		{Type: kv.EventSet, PartitionKey: partition, Key: []byte("a/2")},
+	}
+	// stores that read changes from the database report them after a delay
+	const timeout = 30 * time.Second
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, exp := range expected {
+		select {
+		case ev := <-events:
+			require.Equal(t, exp.Type, ev.Type, "event type of key %s", ev.Key)
+			require.Equal(t, exp.PartitionKey, ev.PartitionKey)
+			require.Equal(t, exp.Key, ev.Key)
+		case <-timer.C:
+			t.Fatalf("timeout waiting for %s event of key %s", exp.Type, exp.Key)
+		}
+	}
+
+	cancel()
+	for range events {
+		// drain until closed
+	}
+}
+
 func testCompareEntries(t *testing.T, entries []kv.Entry, expected []kv.Entry) {
 	t.Helper()
 	if len(entries) != len(expected) {
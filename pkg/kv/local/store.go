@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/dgraph-io/badger/v3"
@@ -32,6 +33,7 @@ type Store struct {
 	prefetchSize int
 	refCount     int
 	path         string
+	watchHub     kv.WatchHub
 }
 
 func (s *Store) Get(ctx context.Context, partitionKey, key []byte) (*kv.ValueWithPredicate, error) {
@@ -100,6 +102,7 @@ func (s *Store) Set(ctx context.Context, partitionKey, key, value []byte) error
 		log.WithError(err).Error("error setting value")
 		return err
 	}
+	s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
 	log.WithField("took", time.Since(start)).Trace("done setting value")
 	return nil
 }
@@ -156,6 +159,9 @@ func (s *Store) SetIf(ctx context.Context, partitionKey, key, value []byte, valu
 		log.WithError(err).Trace("transaction conflict")
 		err = kv.ErrPredicateFailed
 	}
+	if err == nil {
+		s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
+	}
 	took := time.Since(start)
 	log.WithField("took", took).Trace("operation complete")
 
@@ -187,10 +193,67 @@ func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
 		log.WithError(err).Trace("operation failed")
 		return err
 	}
+	s.watchHub.Publish(kv.Event{Type: kv.EventDelete, PartitionKey: partitionKey, Key: key})
 	log.Trace("operation complete")
 	return nil
 }
 
+func (s *Store) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	start := time.Now()
+	log := s.logger.WithField("op", "write_batch").WithField("size", len(ops)).WithContext(ctx)
+	log.Trace("performing operation")
+	err := s.db.Update(func(txn *badger.Txn) error {
+		for _, op := range ops {
+			k := composeKey(op.PartitionKey, op.Key)
+			if op.Conditional {
+				var val []byte
+				item, err := txn.Get(k)
+				found := err == nil
+				if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+					log.WithError(err).Error("could not get key for predicate")
+					return err
+				}
+				if found {
+					val, err = item.ValueCopy(nil)
+					if err != nil {
+						log.WithError(err).Error("could not get byte value for predicate")
+						return err
+					}
+				}
+				if !kv.MatchPredicate(op.Predicate, val, found) {
+					log.WithField("key", string(k)).Trace("predicate condition failed")
+					return kv.ErrPredicateFailed
+				}
+			}
+			var err error
+			if op.IsDelete() {
+				err = txn.Delete(k)
+			} else {
+				err = txn.Set(k, op.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, badger.ErrConflict): // Return predicate failed on transaction conflict - to retry
+		log.WithError(err).Trace("transaction conflict")
+		err = kv.ErrPredicateFailed
+	case errors.Is(err, badger.ErrTxnTooBig):
+		err = fmt.Errorf("%s: %w", err, kv.ErrBatchNotAtomic)
+	case err == nil:
+		s.watchHub.PublishBatch(ops)
+	}
+	log.WithField("took", time.Since(start)).Trace("operation complete")
+	return err
+}
+
+func (s *Store) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchHub.Watch(ctx, partitionKey, prefix)
+}
+
 func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOptions) (kv.EntriesIterator, error) {
 	log := s.logger.WithFields(logging.Fields{
 		"partition_key": string(partitionKey),
@@ -224,6 +287,40 @@ func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOp
 	}, nil
 }
 
+// ListPartitions returns the partitions of the stored keys. Keys are stored after their partition key and the path
+// delimiter, so partition keys are assumed not to contain the path delimiter.
+func (s *Store) ListPartitions(ctx context.Context) ([][]byte, error) {
+	var partitions [][]byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Rewind(); iter.Valid(); {
+			key := iter.Item().Key()
+			idx := bytes.IndexByte(key, kv.PathDelimiter[0])
+			if idx < 0 {
+				iter.Next()
+				continue
+			}
+			partition := make([]byte, idx)
+			copy(partition, key[:idx])
+			partitions = append(partitions, partition)
+			// skip the keys of the partition: seek to the partition followed by the byte after the delimiter
+			next := make([]byte, idx+1)
+			copy(next, partition)
+			next[idx] = kv.PathDelimiter[0] + 1
+			iter.Seek(next)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("error listing partitions")
+		return nil, err
+	}
+	return partitions, nil
+}
+
 func (s *Store) Close() {
 	driverLock.Lock()
 	defer driverLock.Unlock()
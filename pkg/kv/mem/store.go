@@ -20,7 +20,8 @@ type PartitionMap map[string]kv.Entry
 type Store struct {
 	m map[string]PartitionMap
 
-	mu sync.RWMutex
+	mu       sync.RWMutex
+	watchHub kv.WatchHub
 }
 
 type EntriesIterator struct {
@@ -87,7 +88,7 @@ func (s *Store) Set(_ context.Context, partitionKey, key, value []byte) error {
 	defer s.mu.Unlock()
 
 	s.internalSet(partitionKey, key, value)
-
+	s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
 	return nil
 }
 
@@ -137,6 +138,7 @@ func (s *Store) SetIf(_ context.Context, partitionKey, key, value []byte, valueP
 	}
 
 	s.internalSet(partitionKey, key, value)
+	s.watchHub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key})
 	return nil
 }
 
@@ -155,9 +157,50 @@ func (s *Store) Delete(_ context.Context, partitionKey, key []byte) error {
 		return nil
 	}
 	delete(s.m[string(partitionKey)], sKey)
+	s.watchHub.Publish(kv.Event{Type: kv.EventDelete, PartitionKey: partitionKey, Key: key})
+	return nil
+}
+
+func (s *Store) WriteBatch(_ context.Context, ops []kv.BatchOp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, op := range ops {
+		if !op.Conditional {
+			continue
+		}
+		curr, currOK := s.m[string(op.PartitionKey)][encodeKey(op.Key)]
+		if !kv.MatchPredicate(op.Predicate, curr.Value, currOK) {
+			return fmt.Errorf("%w: partition=%s, key=%v", kv.ErrPredicateFailed, op.PartitionKey, op.Key)
+		}
+	}
+	for _, op := range ops {
+		if op.IsDelete() {
+			delete(s.m[string(op.PartitionKey)], encodeKey(op.Key))
+			continue
+		}
+		s.internalSet(op.PartitionKey, op.Key, op.Value)
+	}
+	s.watchHub.PublishBatch(ops)
 	return nil
 }
 
+func (s *Store) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchHub.Watch(ctx, partitionKey, prefix)
+}
+
+func (s *Store) ListPartitions(_ context.Context) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	partitions := make([][]byte, 0, len(s.m))
+	for partition, entries := range s.m {
+		if len(entries) > 0 {
+			partitions = append(partitions, []byte(partition))
+		}
+	}
+	return partitions, nil
+}
+
 func (s *Store) Scan(_ context.Context, partitionKey []byte, options kv.ScanOptions) (kv.EntriesIterator, error) {
 	if len(partitionKey) == 0 {
 		return nil, kv.ErrMissingPartitionKey
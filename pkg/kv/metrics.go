@@ -20,6 +20,11 @@ var (
 		Name: "kv_request_failures_total",
 		Help: "The total number of errors while working for kv store.",
 	}, []string{"type", "operation"})
+
+	batchFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "kv_batch_fallbacks_total",
+		Help: "The total number of batches written sequentially, as the store could not write them atomically.",
+	})
 )
 
 // StoreMetricsWrapper wraps any Store with metrics
@@ -83,6 +88,39 @@ func (s *StoreMetricsWrapper) Scan(ctx context.Context, partitionKey []byte, opt
 	return res, err
 }
 
+func (s *StoreMetricsWrapper) WriteBatch(ctx context.Context, ops []BatchOp) error {
+	const operation = "WriteBatch"
+	timer := prometheus.NewTimer(requestDuration.WithLabelValues(s.StoreType, operation))
+	defer timer.ObserveDuration()
+	err := WriteBatch(ctx, s.Store, ops)
+	if err != nil {
+		requestFailures.WithLabelValues(s.StoreType, operation).Inc()
+	}
+	return err
+}
+
+func (s *StoreMetricsWrapper) ListPartitions(ctx context.Context) ([][]byte, error) {
+	const operation = "ListPartitions"
+	timer := prometheus.NewTimer(requestDuration.WithLabelValues(s.StoreType, operation))
+	defer timer.ObserveDuration()
+	res, err := ListPartitions(ctx, s.Store)
+	if err != nil {
+		requestFailures.WithLabelValues(s.StoreType, operation).Inc()
+	}
+	return res, err
+}
+
+func (s *StoreMetricsWrapper) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan Event, error) {
+	const operation = "Watch"
+	timer := prometheus.NewTimer(requestDuration.WithLabelValues(s.StoreType, operation))
+	defer timer.ObserveDuration()
+	res, err := Watch(ctx, s.Store, partitionKey, prefix)
+	if err != nil {
+		requestFailures.WithLabelValues(s.StoreType, operation).Inc()
+	}
+	return res, err
+}
+
 func (s *StoreMetricsWrapper) Close() {
 	timer := prometheus.NewTimer(requestDuration.WithLabelValues(s.StoreType, "Close"))
 	defer timer.ObserveDuration()
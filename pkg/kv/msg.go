@@ -38,3 +38,33 @@ func SetMsgIf(ctx context.Context, s Store, partitionKey string, key []byte, msg
 	}
 	return s.SetIf(ctx, []byte(partitionKey), key, val, predicate)
 }
+
+// SetMsgOp returns a batch operation that sets the message on the key
+func SetMsgOp(partitionKey string, key []byte, msg protoreflect.ProtoMessage) (BatchOp, error) {
+	val, err := marshalMsgValue(msg)
+	if err != nil {
+		return BatchOp{}, err
+	}
+	return SetOp([]byte(partitionKey), key, val), nil
+}
+
+// SetMsgIfOp returns a batch operation that sets the message on the key, only if the predicate holds
+func SetMsgIfOp(partitionKey string, key []byte, msg protoreflect.ProtoMessage, predicate Predicate) (BatchOp, error) {
+	val, err := marshalMsgValue(msg)
+	if err != nil {
+		return BatchOp{}, err
+	}
+	return SetIfOp([]byte(partitionKey), key, val, predicate), nil
+}
+
+// marshalMsgValue marshals the message to a value, an empty message is an empty value and not a delete
+func marshalMsgValue(msg protoreflect.ProtoMessage) ([]byte, error) {
+	val, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		val = []byte{}
+	}
+	return val, nil
+}
@@ -0,0 +1,25 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrListPartitionsNotSupported = errors.New("list partitions not supported")
+
+// PartitionsLister is implemented by stores that can enumerate their partitions
+type PartitionsLister interface {
+	// ListPartitions returns the partition keys of the store, in no particular order
+	ListPartitions(ctx context.Context) ([][]byte, error)
+}
+
+// ListPartitions returns the partition keys of the store. Fails with ErrListPartitionsNotSupported when the store
+// cannot enumerate its partitions.
+func ListPartitions(ctx context.Context, store Store) ([][]byte, error) {
+	lister, ok := store.(PartitionsLister)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", store, ErrListPartitionsNotSupported)
+	}
+	return lister.ListPartitions(ctx)
+}
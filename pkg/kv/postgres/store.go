@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"strconv"
+	"sync"
 
 	"github.com/IBM/pgxpoolprometheus"
 	"github.com/georgysavva/scany/v2/pgxscan"
@@ -25,6 +26,11 @@ type Store struct {
 	Params         *Params
 	TableSanitized string
 	collector      prometheus.Collector
+	watchHub       kv.WatchHub
+	listenMu       sync.Mutex
+	listening      bool
+	listenCtx      context.Context
+	listenCancel   context.CancelFunc
 }
 
 type EntriesIterator struct {
@@ -103,11 +109,14 @@ func (d *Driver) Open(ctx context.Context, kvParams kvparams.Config) (kv.Store,
 		}
 	}
 
+	listenCtx, listenCancel := context.WithCancel(context.Background())
 	store := &Store{
 		Pool:           pool,
 		Params:         params,
 		TableSanitized: pgx.Identifier{params.TableName}.Sanitize(),
 		collector:      collector,
+		listenCtx:      listenCtx,
+		listenCancel:   listenCancel,
 	}
 	pool = nil
 	return store, nil
@@ -136,6 +145,9 @@ type Params struct {
 	PartitionsAmount   int
 	ScanPageSize       int
 	Metrics            bool
+	NotifyChanges      bool
+	// NotifyPartitions holds the partitions whose changes are notified, all partitions when empty
+	NotifyPartitions map[string]struct{}
 }
 
 func parseStoreConfig(runtimeParams map[string]string, pgParams *kvparams.Postgres) *Params {
@@ -144,6 +156,13 @@ func parseStoreConfig(runtimeParams map[string]string, pgParams *kvparams.Postgr
 		PartitionsAmount: DefaultPartitions,
 		ScanPageSize:     DefaultScanPageSize,
 		Metrics:          pgParams.Metrics,
+		NotifyChanges:    pgParams.NotifyChanges,
+	}
+	if len(pgParams.NotifyPartitions) > 0 {
+		p.NotifyPartitions = make(map[string]struct{}, len(pgParams.NotifyPartitions))
+		for _, partition := range pgParams.NotifyPartitions {
+			p.NotifyPartitions[partition] = struct{}{}
+		}
 	}
 	if tableName, ok := runtimeParams[paramTableName]; ok {
 		p.TableName = tableName
@@ -257,7 +276,8 @@ func (s *Store) Set(ctx context.Context, partitionKey, key, value []byte) error
 		return kv.ErrMissingValue
 	}
 
-	_, err := s.Pool.Exec(ctx, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3)
+	ev := kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key}
+	_, err := s.execWrite(ctx, ev, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3)
 			ON CONFLICT (partition_key,key) DO UPDATE SET value = $3`, partitionKey, key, value)
 	if err != nil {
 		return fmt.Errorf("postgres set: %w", err)
@@ -280,15 +300,16 @@ func (s *Store) SetIf(ctx context.Context, partitionKey, key, value []byte, valu
 		res pgconn.CommandTag
 		err error
 	)
+	ev := kv.Event{Type: kv.EventSet, PartitionKey: partitionKey, Key: key}
 	switch valuePredicate {
 	case nil: // use insert to make sure there was no previous value before
-		res, err = s.Pool.Exec(ctx, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3) ON CONFLICT DO NOTHING`, partitionKey, key, value)
+		res, err = s.execWrite(ctx, ev, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3) ON CONFLICT DO NOTHING`, partitionKey, key, value)
 
 	case kv.PrecondConditionalExists: // update only if exists
-		res, err = s.Pool.Exec(ctx, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1`, partitionKey, key, value)
+		res, err = s.execWrite(ctx, ev, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1`, partitionKey, key, value)
 
 	default: // update just in case the previous value was same as predicate value
-		res, err = s.Pool.Exec(ctx, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1 AND value=$4`, partitionKey, key, value, valuePredicate.([]byte))
+		res, err = s.execWrite(ctx, ev, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1 AND value=$4`, partitionKey, key, value, valuePredicate.([]byte))
 	}
 	if err != nil {
 		return fmt.Errorf("postgres setIf: %w", err)
@@ -306,7 +327,8 @@ func (s *Store) Delete(ctx context.Context, partitionKey, key []byte) error {
 	if len(key) == 0 {
 		return kv.ErrMissingKey
 	}
-	_, err := s.Pool.Exec(ctx, `DELETE FROM `+s.Params.SanitizedTableName+` WHERE partition_key=$1 AND key=$2`, partitionKey, key)
+	ev := kv.Event{Type: kv.EventDelete, PartitionKey: partitionKey, Key: key}
+	_, err := s.execWrite(ctx, ev, `DELETE FROM `+s.Params.SanitizedTableName+` WHERE partition_key=$1 AND key=$2`, partitionKey, key)
 	if err != nil {
 		return fmt.Errorf("postgres delete: %w", err)
 	}
@@ -338,7 +360,84 @@ func (s *Store) Scan(ctx context.Context, partitionKey []byte, options kv.ScanOp
 	return it, nil
 }
 
+func (s *Store) WriteBatch(ctx context.Context, ops []kv.BatchOp) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres write batch: %w", err)
+	}
+	// rollback after commit is a no-op
+	defer func() { _ = tx.Rollback(ctx) }()
+	for _, op := range ops {
+		if err := s.writeBatchOp(ctx, tx, op); err != nil {
+			return err
+		}
+	}
+	if err := s.notifyBatch(ctx, tx, ops); err != nil {
+		return fmt.Errorf("postgres write batch: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres write batch: %w", err)
+	}
+	s.publishBatch(ops)
+	return nil
+}
+
+func (s *Store) writeBatchOp(ctx context.Context, tx pgx.Tx, op kv.BatchOp) error {
+	var (
+		res pgconn.CommandTag
+		err error
+	)
+	switch {
+	case op.IsDelete() && (!op.Conditional || op.Predicate == kv.PrecondConditionalExists):
+		res, err = tx.Exec(ctx, `DELETE FROM `+s.Params.SanitizedTableName+` WHERE partition_key=$1 AND key=$2`, op.PartitionKey, op.Key)
+
+	case op.IsDelete():
+		res, err = tx.Exec(ctx, `DELETE FROM `+s.Params.SanitizedTableName+` WHERE partition_key=$1 AND key=$2 AND value=$3`, op.PartitionKey, op.Key, op.Predicate.([]byte))
+
+	case !op.Conditional:
+		res, err = tx.Exec(ctx, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3)
+			ON CONFLICT (partition_key,key) DO UPDATE SET value = $3`, op.PartitionKey, op.Key, op.Value)
+
+	case op.Predicate == nil: // use insert to make sure there was no previous value before
+		res, err = tx.Exec(ctx, `INSERT INTO `+s.Params.SanitizedTableName+`(partition_key,key,value) VALUES($1,$2,$3) ON CONFLICT DO NOTHING`, op.PartitionKey, op.Key, op.Value)
+
+	case op.Predicate == kv.PrecondConditionalExists: // update only if exists
+		res, err = tx.Exec(ctx, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1`, op.PartitionKey, op.Key, op.Value)
+
+	default: // update just in case the previous value was same as predicate value
+		res, err = tx.Exec(ctx, `UPDATE `+s.Params.SanitizedTableName+` SET value=$3 WHERE key=$2 AND partition_key=$1 AND value=$4`, op.PartitionKey, op.Key, op.Value, op.Predicate.([]byte))
+	}
+	if err != nil {
+		return fmt.Errorf("postgres write batch: %w", err)
+	}
+	if op.Conditional && res.RowsAffected() != 1 {
+		return kv.ErrPredicateFailed
+	}
+	return nil
+}
+
+func (s *Store) ListPartitions(ctx context.Context) ([][]byte, error) {
+	rows, err := s.Pool.Query(ctx, `SELECT DISTINCT partition_key FROM `+s.Params.SanitizedTableName)
+	if err != nil {
+		return nil, fmt.Errorf("postgres list partitions: %w", err)
+	}
+	defer rows.Close()
+	var partitions [][]byte
+	for rows.Next() {
+		var partition []byte
+		if err := rows.Scan(&partition); err != nil {
+			return nil, fmt.Errorf("postgres list partitions: %w", err)
+		}
+		partitions = append(partitions, partition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres list partitions: %w", err)
+	}
+	return partitions, nil
+}
+
 func (s *Store) Close() {
+	s.listenCancel()
 	if s.collector != nil {
 		prometheus.Unregister(s.collector)
 		s.collector = nil
@@ -11,3 +11,24 @@ import (
 func TestPostgresKV(t *testing.T) {
 	kvtest.DriverTest(t, postgres.DriverName, kvparams.Config{Postgres: &kvparams.Postgres{ConnectionString: databaseURI, ScanPageSize: 10}})
 }
+
+func TestPostgresKVNotifyChanges(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Partitions []string
+	}{
+		{Name: "all_partitions"},
+		// changes of partitions that are not notified are published to in-process watchers
+		{Name: "other_partitions", Partitions: []string{"auth", "graveler"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			kvtest.DriverTest(t, postgres.DriverName, kvparams.Config{Postgres: &kvparams.Postgres{
+				ConnectionString: databaseURI,
+				ScanPageSize:     10,
+				NotifyChanges:    true,
+				NotifyPartitions: tt.Partitions,
+			}})
+		})
+	}
+}
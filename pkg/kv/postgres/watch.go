@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// listenRetryInterval is the time to wait before listening again after the listen connection failed
+const listenRetryInterval = 5 * time.Second
+
+var errInvalidNotification = errors.New("invalid notification")
+
+// notifyChannel returns the channel of the change notifications of the table
+func notifyChannel(tableName string) string {
+	return tableName + "_changes"
+}
+
+// notifies reports whether changes of the partition are notified to other stores. Only the partitions watched by
+// lakeFS are notified, so writes to other partitions do not pay for a notification.
+func (s *Store) notifies(partitionKey []byte) bool {
+	if !s.Params.NotifyChanges {
+		return false
+	}
+	if len(s.Params.NotifyPartitions) == 0 {
+		return true
+	}
+	_, ok := s.Params.NotifyPartitions[string(partitionKey)]
+	return ok
+}
+
+// execWrite executes the write statement of the key. When the partition is notified the statement and the
+// notification are sent as a single implicit transaction, otherwise the change is published to in-process watchers.
+func (s *Store) execWrite(ctx context.Context, ev kv.Event, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !s.notifies(ev.PartitionKey) {
+		res, err := s.Pool.Exec(ctx, sql, args...)
+		if err == nil && res.RowsAffected() > 0 {
+			s.watchHub.Publish(ev)
+		}
+		return res, err
+	}
+	batch := &pgx.Batch{}
+	batch.Queue(sql, args...)
+	batch.Queue(`SELECT pg_notify($1, $2)`, notifyChannel(s.Params.TableName), encodeEvent(ev))
+	results := s.Pool.SendBatch(ctx, batch)
+	res, err := results.Exec()
+	if err != nil {
+		_ = results.Close()
+		return res, err
+	}
+	_, err = results.Exec()
+	if closeErr := results.Close(); err == nil {
+		err = closeErr
+	}
+	return res, err
+}
+
+// notifyBatch reports the changes of the batch operations on notified partitions, written by tx, once tx commits
+func (s *Store) notifyBatch(ctx context.Context, tx pgx.Tx, ops []kv.BatchOp) error {
+	batch := &pgx.Batch{}
+	for _, op := range ops {
+		if s.notifies(op.PartitionKey) {
+			batch.Queue(`SELECT pg_notify($1, $2)`, notifyChannel(s.Params.TableName), encodeEvent(batchOpEvent(op)))
+		}
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// publishBatch publishes the changes of the batch operations that are not notified to in-process watchers
+func (s *Store) publishBatch(ops []kv.BatchOp) {
+	published := make([]kv.BatchOp, 0, len(ops))
+	for _, op := range ops {
+		if !s.notifies(op.PartitionKey) {
+			published = append(published, op)
+		}
+	}
+	if len(published) > 0 {
+		s.watchHub.PublishBatch(published)
+	}
+}
+
+func batchOpEvent(op kv.BatchOp) kv.Event {
+	ev := kv.Event{Type: kv.EventSet, PartitionKey: op.PartitionKey, Key: op.Key}
+	if op.IsDelete() {
+		ev.Type = kv.EventDelete
+	}
+	return ev
+}
+
+// Watch reports the changes of keys written by this store. With change notifications, changes written by other
+// stores that share the database are reported too.
+func (s *Store) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan kv.Event, error) {
+	if s.Params.NotifyChanges {
+		if err := s.startListen(ctx); err != nil {
+			return nil, fmt.Errorf("postgres watch: %w", err)
+		}
+	}
+	return s.watchHub.Watch(ctx, partitionKey, prefix)
+}
+
+// startListen listens for change notifications, once. Returns after the first listen, so changes written after it
+// are reported.
+func (s *Store) startListen(ctx context.Context) error {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+	if s.listening {
+		return nil
+	}
+	conn, err := s.listenConn(ctx)
+	if err != nil {
+		return err
+	}
+	s.listening = true
+	go s.listen(conn)
+	return nil
+}
+
+// listen publishes the change notifications to the watchers until the store is closed. Watchers are reset when the
+// listen connection fails, as notifications may be lost.
+func (s *Store) listen(conn *pgx.Conn) {
+	ctx := s.listenCtx
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"store":   DriverName,
+		"channel": notifyChannel(s.Params.TableName),
+	})
+	for {
+		if conn != nil {
+			err := s.receiveNotifications(ctx, conn)
+			_ = conn.Close(context.Background())
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithError(err).Warn("Receive change notifications failed")
+			s.watchHub.Publish(kv.Event{Type: kv.EventReset})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+		var err error
+		conn, err = s.listenConn(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Listen for change notifications failed")
+		}
+	}
+}
+
+// listenConn returns a connection that listens for change notifications. The connection is taken out of the pool.
+func (s *Store) listenConn(ctx context.Context) (*pgx.Conn, error) {
+	poolConn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := poolConn.Hijack()
+	_, err = conn.Exec(ctx, `LISTEN `+pgx.Identifier{notifyChannel(s.Params.TableName)}.Sanitize())
+	if err != nil {
+		_ = conn.Close(context.Background())
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (s *Store) receiveNotifications(ctx context.Context, conn *pgx.Conn) error {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		ev, err := decodeEvent(notification.Payload)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("payload", notification.Payload).Warn("Skip change notification")
+			continue
+		}
+		s.watchHub.Publish(ev)
+	}
+}
+
+// encodeEvent encodes the event as a notification payload: type, partition key and key, separated by colons
+func encodeEvent(ev kv.Event) string {
+	return strconv.Itoa(int(ev.Type)) + ":" +
+		base64.StdEncoding.EncodeToString(ev.PartitionKey) + ":" +
+		base64.StdEncoding.EncodeToString(ev.Key)
+}
+
+func decodeEvent(payload string) (kv.Event, error) {
+	parts := strings.Split(payload, ":")
+	const payloadParts = 3
+	if len(parts) != payloadParts {
+		return kv.Event{}, errInvalidNotification
+	}
+	eventType, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return kv.Event{}, fmt.Errorf("event type: %w", errInvalidNotification)
+	}
+	partitionKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return kv.Event{}, fmt.Errorf("partition key: %w", errInvalidNotification)
+	}
+	key, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return kv.Event{}, fmt.Errorf("key: %w", errInvalidNotification)
+	}
+	return kv.Event{Type: kv.EventType(eventType), PartitionKey: partitionKey, Key: key}, nil
+}
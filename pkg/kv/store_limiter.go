@@ -43,6 +43,20 @@ func (s *StoreLimiter) Scan(ctx context.Context, partitionKey []byte, options Sc
 	return s.Store.Scan(ctx, partitionKey, options)
 }
 
+func (s *StoreLimiter) WriteBatch(ctx context.Context, ops []BatchOp) error {
+	_ = s.Limiter.Take()
+	return WriteBatch(ctx, s.Store, ops)
+}
+
+func (s *StoreLimiter) ListPartitions(ctx context.Context) ([][]byte, error) {
+	_ = s.Limiter.Take()
+	return ListPartitions(ctx, s.Store)
+}
+
+func (s *StoreLimiter) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan Event, error) {
+	return Watch(ctx, s.Store, partitionKey, prefix)
+}
+
 func (s *StoreLimiter) Close() {
 	s.Store.Close()
 }
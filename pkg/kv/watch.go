@@ -0,0 +1,188 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrWatchNotSupported = errors.New("watch not supported")
+
+// EventType is the type of change a watch Event reports
+type EventType int
+
+const (
+	// EventSet reports the key was set
+	EventSet EventType = iota
+	// EventDelete reports the key was deleted
+	EventDelete
+	// EventReset reports events may have been lost, watchers should assume any key under the watched prefix changed
+	EventReset
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventReset:
+		return "reset"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a change of a key reported to watchers. Reset events have no key.
+type Event struct {
+	Type         EventType
+	PartitionKey []byte
+	Key          []byte
+}
+
+// Watcher is implemented by stores that report changes to their keys
+type Watcher interface {
+	// Watch returns a channel of the changes to keys of the partition that start with prefix, including changes made
+	// by other processes that share the store. Changes are reported after they are written, in order per key. A
+	// change may be reported more than once, and a failed conditional write may be reported as a change. When
+	// changes cannot be delivered, for example when the watcher falls behind or the connection to the database is
+	// lost, an EventReset is reported instead. The channel is closed when ctx is done.
+	Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan Event, error)
+}
+
+// Watch returns a channel of the changes to keys of the partition that start with prefix. Fails with
+// ErrWatchNotSupported when the store does not report changes.
+func Watch(ctx context.Context, store Store, partitionKey, prefix []byte) (<-chan Event, error) {
+	if len(partitionKey) == 0 {
+		return nil, ErrMissingPartitionKey
+	}
+	watcher, ok := store.(Watcher)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", store, ErrWatchNotSupported)
+	}
+	return watcher.Watch(ctx, partitionKey, prefix)
+}
+
+// maxPendingEvents is the number of events kept for a watcher that falls behind, before they are replaced by a reset
+const maxPendingEvents = 1024
+
+// WatchHub dispatches the events published by a store to its in-process watchers. The zero value is ready to use.
+type WatchHub struct {
+	mu       sync.Mutex
+	watchers map[*hubWatcher]struct{}
+}
+
+type hubWatcher struct {
+	partitionKey []byte
+	prefix       []byte
+	mu           sync.Mutex
+	pending      []Event
+	notify       chan struct{}
+}
+
+// Watch registers a watcher for the changes to keys of the partition that start with prefix, until ctx is done
+func (h *WatchHub) Watch(ctx context.Context, partitionKey, prefix []byte) (<-chan Event, error) {
+	if len(partitionKey) == 0 {
+		return nil, ErrMissingPartitionKey
+	}
+	w := &hubWatcher{
+		partitionKey: partitionKey,
+		prefix:       prefix,
+		notify:       make(chan struct{}, 1),
+	}
+	h.mu.Lock()
+	if h.watchers == nil {
+		h.watchers = make(map[*hubWatcher]struct{})
+	}
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		defer func() {
+			h.mu.Lock()
+			delete(h.watchers, w)
+			h.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.notify:
+			}
+			for _, ev := range w.takePending() {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Publish reports the event to the watchers of its key. Reset events are reported to all watchers of the partition,
+// or to all watchers when the event has no partition key.
+func (h *WatchHub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for w := range h.watchers {
+		if w.match(ev) {
+			w.push(ev)
+		}
+	}
+}
+
+// PublishBatch reports the changes written by the batch operations
+func (h *WatchHub) PublishBatch(ops []BatchOp) {
+	for _, op := range ops {
+		ev := Event{Type: EventSet, PartitionKey: op.PartitionKey, Key: op.Key}
+		if op.IsDelete() {
+			ev.Type = EventDelete
+		}
+		h.Publish(ev)
+	}
+}
+
+// Active reports whether the hub has watchers
+func (h *WatchHub) Active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.watchers) > 0
+}
+
+func (w *hubWatcher) match(ev Event) bool {
+	if ev.Type == EventReset {
+		return len(ev.PartitionKey) == 0 || bytes.Equal(ev.PartitionKey, w.partitionKey)
+	}
+	return bytes.Equal(ev.PartitionKey, w.partitionKey) && bytes.HasPrefix(ev.Key, w.prefix)
+}
+
+func (w *hubWatcher) push(ev Event) {
+	w.mu.Lock()
+	switch {
+	case len(w.pending) == 1 && w.pending[0].Type == EventReset:
+		// a pending reset covers any later event
+	case ev.Type == EventReset || len(w.pending) >= maxPendingEvents:
+		w.pending = []Event{{Type: EventReset, PartitionKey: w.partitionKey}}
+	default:
+		w.pending = append(w.pending, ev)
+	}
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *hubWatcher) takePending() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.pending
+	w.pending = nil
+	return events
+}
@@ -0,0 +1,44 @@
+package kv_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+func TestWatchHubReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var hub kv.WatchHub
+	partition := []byte("partition")
+	events, err := hub.Watch(ctx, partition, nil)
+	require.NoError(t, err)
+
+	// publish more events than a watcher keeps while it is not reading
+	const numEvents = 5000
+	for i := 0; i < numEvents; i++ {
+		hub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: partition, Key: []byte(fmt.Sprintf("key%d", i))})
+	}
+	hub.Publish(kv.Event{Type: kv.EventSet, PartitionKey: []byte("other"), Key: []byte("key")})
+
+	ev := <-events
+	if ev.Type != kv.EventReset {
+		// the watcher read some events before it fell behind
+		for ev.Type != kv.EventReset {
+			require.Equal(t, kv.EventSet, ev.Type)
+			ev = <-events
+		}
+	}
+	require.Equal(t, partition, ev.PartitionKey)
+
+	hub.Publish(kv.Event{Type: kv.EventDelete, PartitionKey: partition, Key: []byte("last")})
+	ev = <-events
+	require.Equal(t, kv.Event{Type: kv.EventDelete, PartitionKey: partition, Key: []byte("last")}, ev)
+
+	cancel()
+	_, ok := <-events
+	require.False(t, ok, "events channel closed")
+}
@@ -8,6 +8,10 @@ var Actions = []string{
 	"fs:AttachStorageNamespace",
 	"fs:ImportFromStorage",
 	"fs:ImportCancel",
+	"fs:ExportToStorage",
+	"fs:ExportCancel",
+	"fs:ReadHookStorage",
+	"fs:WriteHookStorage",
 	"fs:DeleteRepository",
 	"fs:ListRepositories",
 	"fs:ReadObject",
@@ -50,10 +54,20 @@ var Actions = []string{
 	"auth:DeleteCredentials",
 	"auth:ListCredentials",
 	"ci:ReadAction",
+	"ci:RetryRun",
+	"ci:TestAction",
+	"ci:ListGlobalActions",
+	"ci:GetGlobalAction",
+	"ci:SetGlobalAction",
+	"ci:DeleteGlobalAction",
 	"retention:PrepareGarbageCollectionCommits",
 	"retention:GetGarbageCollectionRules",
 	"retention:SetGarbageCollectionRules",
 	"retention:PrepareGarbageCollectionUncommitted",
 	"branches:GetBranchProtectionRules",
 	"branches:SetBranchProtectionRules",
+	"branches:GetBranchReplication",
+	"branches:SetBranchReplication",
+	"branches:GetBranchCompaction",
+	"branches:SetBranchCompaction",
 }
@@ -19,6 +19,10 @@ const (
 	AttachStorageNamespaceAction              = "fs:AttachStorageNamespace"
 	ImportFromStorageAction                   = "fs:ImportFromStorage"
 	ImportCancelAction                        = "fs:ImportCancel"
+	ExportToStorageAction                     = "fs:ExportToStorage"
+	ExportCancelAction                        = "fs:ExportCancel"
+	ReadHookStorageAction                     = "fs:ReadHookStorage"
+	WriteHookStorageAction                    = "fs:WriteHookStorage"
 	DeleteRepositoryAction                    = "fs:DeleteRepository"
 	ListRepositoriesAction                    = "fs:ListRepositories"
 	ReadObjectAction                          = "fs:ReadObject"
@@ -61,12 +65,22 @@ const (
 	DeleteCredentialsAction                   = "auth:DeleteCredentials" //nolint:gosec
 	ListCredentialsAction                     = "auth:ListCredentials"   //nolint:gosec
 	ReadActionsAction                         = "ci:ReadAction"
+	RetryActionsRunAction                     = "ci:RetryRun"
+	TestActionAction                          = "ci:TestAction"
+	ListGlobalActionsAction                   = "ci:ListGlobalActions"
+	GetGlobalActionAction                     = "ci:GetGlobalAction"
+	SetGlobalActionAction                     = "ci:SetGlobalAction"
+	DeleteGlobalActionAction                  = "ci:DeleteGlobalAction"
 	PrepareGarbageCollectionCommitsAction     = "retention:PrepareGarbageCollectionCommits"
 	GetGarbageCollectionRulesAction           = "retention:GetGarbageCollectionRules"
 	SetGarbageCollectionRulesAction           = "retention:SetGarbageCollectionRules"
 	PrepareGarbageCollectionUncommittedAction = "retention:PrepareGarbageCollectionUncommitted"
 	GetBranchProtectionRulesAction            = "branches:GetBranchProtectionRules"
 	SetBranchProtectionRulesAction            = "branches:SetBranchProtectionRules"
+	GetBranchReplicationAction                = "branches:GetBranchReplication"
+	SetBranchReplicationAction                = "branches:SetBranchReplication"
+	GetBranchCompactionAction                 = "branches:GetBranchCompaction"
+	SetBranchCompactionAction                 = "branches:SetBranchCompaction"
 )
 
 var serviceSet = map[string]struct{}{
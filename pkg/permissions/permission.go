@@ -38,6 +38,10 @@ func ObjectArn(repoID, key string) string {
 	return fsArnPrefix + "repository/" + repoID + "/object/" + key
 }
 
+func HookStorageArn(repoID, key string) string {
+	return fsArnPrefix + "repository/" + repoID + "/hook_storage/" + key
+}
+
 func BranchArn(repoID, branchID string) string {
 	return fsArnPrefix + "repository/" + repoID + "/branch/" + branchID
 }
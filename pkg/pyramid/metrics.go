@@ -40,3 +40,23 @@ var downloadHistograms = promauto.NewHistogramVec(
 		Buckets: prometheus.ExponentialBuckets(kb, 4, 7), //nolint: gomnd
 	},
 	[]string{fsNameLabel})
+
+var remoteCacheAccess = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tier_fs_remote_cache_hits_total",
+		Help: "TierFS remote cache hits total count",
+	}, []string{fsNameLabel, accessStatusLabel})
+
+var remoteCacheAdmissions = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tier_fs_remote_cache_admissions_total",
+		Help: "TierFS files offered to the remote cache by admission status",
+	}, []string{fsNameLabel, accessStatusLabel})
+
+var remoteCacheDownloadHistograms = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "tier_fs_remote_cache_download_bytes",
+		Help:    "TierFS download from remote cache object size by bytes",
+		Buckets: prometheus.ExponentialBuckets(kb, 4, 7), //nolint: gomnd
+	},
+	[]string{fsNameLabel})
@@ -8,6 +8,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/block"
 	"github.com/treeverse/lakefs/pkg/config"
 	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/pyramid/remotecache"
 )
 
 // RelativePath is the path of the file under TierFS, used in the Eviction interface.
@@ -35,6 +36,23 @@ type LocalDiskParams struct {
 	BaseDir string
 }
 
+// RemoteCacheParams is pyramid.FS params of the cache shared by all lakeFS instances.
+type RemoteCacheParams struct {
+	// Cache is consulted for files missing from the local disk before reading them from
+	// the block storage.  No remote cache is used when nil.
+	Cache remotecache.Cache
+
+	// MaxObjectSizeBytes is the size of the largest file admitted to the remote cache.
+	MaxObjectSizeBytes int64
+
+	// AdmissionWorkers is the number of files written to the remote cache concurrently.
+	AdmissionWorkers int
+
+	// AdmissionQueueSize is the number of admitted files waiting to be written to the
+	// remote cache, files admitted while the queue is full are not written.
+	AdmissionQueueSize int
+}
+
 type SharedParams struct {
 	// Logger receives all logs for this FS.
 	Logger logging.Logger
@@ -49,6 +67,9 @@ type SharedParams struct {
 	// the blockstore.
 	BlockStoragePrefix string
 
+	// RemoteCache holds the configuration of the shared cache tier.
+	RemoteCache RemoteCacheParams
+
 	// Eviction is the cache to use to evict objects from local storage.  Only
 	// configurable in testing.
 	Eviction Eviction
@@ -107,6 +128,11 @@ func NewCommittedTierFSParams(c *config.Config, adapter block.Adapter) (*ExtPara
 		return nil, fmt.Errorf("expand %s: %w", c.Committed.LocalCache.Dir, err)
 	}
 
+	remoteCache, err := remotecache.NewFromConfig(c)
+	if err != nil {
+		return nil, fmt.Errorf("remote cache: %w", err)
+	}
+
 	logger := logging.ContextUnavailable().WithField("module", "pyramid")
 	return &ExtParams{
 		RangeAllocationProportion:     rangePro,
@@ -119,6 +145,12 @@ func NewCommittedTierFSParams(c *config.Config, adapter block.Adapter) (*ExtPara
 				BaseDir:             localCacheDir,
 				TotalAllocatedBytes: c.Committed.LocalCache.SizeBytes,
 			},
+			RemoteCache: RemoteCacheParams{
+				Cache:              remoteCache,
+				MaxObjectSizeBytes: c.Committed.RemoteCache.MaxObjectSizeBytes,
+				AdmissionWorkers:   c.Committed.RemoteCache.AdmissionWorkers,
+				AdmissionQueueSize: c.Committed.RemoteCache.AdmissionQueueSize,
+			},
 			PebbleSSTableCacheSizeBytes: c.Committed.SSTable.Memory.CacheSizeBytes,
 		},
 	}, nil
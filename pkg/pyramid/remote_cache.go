@@ -0,0 +1,141 @@
+package pyramid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/pyramid/remotecache"
+)
+
+// remoteCacheKey returns the key of the file on the remote cache
+func (tfs *TierFS) remoteCacheKey(namespace, filename string) string {
+	return tfs.fsName + "/" + namespace + "/" + tfs.blockStoragePath(filename)
+}
+
+// download writes the referenced file to w from the remote cache, or from the block storage
+// when it is missing from the remote cache.  Returns the number of bytes written, and true when
+// the file was read from the remote cache.
+func (tfs *TierFS) download(ctx context.Context, fileRef localFileRef, w *os.File) (int64, bool, error) {
+	if tfs.remoteCache != nil {
+		written, err := tfs.remoteCache.Get(ctx, tfs.remoteCacheKey(fileRef.namespace, fileRef.filename), w)
+		switch {
+		case err == nil:
+			remoteCacheAccess.WithLabelValues(tfs.fsName, "Hit").Inc()
+			return written, true, nil
+		case errors.Is(err, remotecache.ErrNotFound):
+			remoteCacheAccess.WithLabelValues(tfs.fsName, "Miss").Inc()
+		default:
+			// the remote cache is an optimization, fall back to the block storage
+			remoteCacheAccess.WithLabelValues(tfs.fsName, "Error").Inc()
+			errorsTotal.WithLabelValues(tfs.fsName, "RemoteCacheGet").Inc()
+			tfs.log(ctx).WithError(err).WithFields(logging.Fields{
+				"namespace": fileRef.namespace,
+				"file":      fileRef.filename,
+			}).Warn("Read from remote cache failed")
+		}
+		if written > 0 {
+			// drop the content partially read from the remote cache
+			if err := w.Truncate(0); err != nil {
+				return 0, false, fmt.Errorf("truncate file: %w", err)
+			}
+			if _, err := w.Seek(0, io.SeekStart); err != nil {
+				return 0, false, fmt.Errorf("seek file: %w", err)
+			}
+		}
+	}
+	reader, err := tfs.adapter.Get(ctx, tfs.objPointer(fileRef.namespace, fileRef.filename), 0)
+	if err != nil {
+		return 0, false, fmt.Errorf("read from block storage: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	written, err := io.Copy(w, reader)
+	if err != nil {
+		return 0, false, fmt.Errorf("copying data to file: %w", err)
+	}
+	return written, false, nil
+}
+
+const (
+	defaultRemoteCacheAdmissionWorkers   = 4
+	defaultRemoteCacheAdmissionQueueSize = 64
+)
+
+// remoteCacheAdmission is a local file waiting to be written to the remote cache
+type remoteCacheAdmission struct {
+	namespace string
+	filename  string
+	fullPath  string
+	// release is called once the file is no longer needed by the admission
+	release func()
+}
+
+// startRemoteCacheAdmissions starts the workers writing admitted files to the remote cache.
+// Admitted files are streamed from the local disk, so the memory used by the workers doesn't
+// depend on the size of the files or of the queue.
+func (tfs *TierFS) startRemoteCacheAdmissions(workers, queueSize int) {
+	if workers <= 0 {
+		workers = defaultRemoteCacheAdmissionWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultRemoteCacheAdmissionQueueSize
+	}
+	tfs.remoteCacheQueue = make(chan *remoteCacheAdmission, queueSize)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for admission := range tfs.remoteCacheQueue {
+				tfs.writeToRemoteCache(admission)
+				admission.release()
+			}
+		}()
+	}
+}
+
+// admitToRemoteCache queues the local file at fullPath to be stored on the remote cache, if its
+// size is admitted.  The file is dropped when the queue is full: it is always available on the
+// block storage, and writing it must not delay the caller.  release is called once the local
+// file is no longer needed, after it is written or right away when it is not queued.
+func (tfs *TierFS) admitToRemoteCache(namespace, filename, fullPath string, size int64, release func()) {
+	if tfs.remoteCache == nil {
+		release()
+		return
+	}
+	if size > tfs.remoteCacheMaxObjectSize {
+		remoteCacheAdmissions.WithLabelValues(tfs.fsName, "Rejected").Inc()
+		release()
+		return
+	}
+	select {
+	case tfs.remoteCacheQueue <- &remoteCacheAdmission{namespace: namespace, filename: filename, fullPath: fullPath, release: release}:
+	default:
+		remoteCacheAdmissions.WithLabelValues(tfs.fsName, "Dropped").Inc()
+		release()
+	}
+}
+
+// writeToRemoteCache stores an admitted file on the remote cache.  Failures are logged: the file
+// is always available on the block storage.
+func (tfs *TierFS) writeToRemoteCache(admission *remoteCacheAdmission) {
+	// the admission outlives the request that stored or read the file
+	ctx := context.Background()
+	log := tfs.logger.WithFields(logging.Fields{
+		"namespace": admission.namespace,
+		"file":      admission.filename,
+	})
+	f, err := os.Open(admission.fullPath)
+	if err != nil {
+		errorsTotal.WithLabelValues(tfs.fsName, "RemoteCacheSet").Inc()
+		log.WithError(err).Warn("Read file for remote cache failed")
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if err := tfs.remoteCache.Set(ctx, tfs.remoteCacheKey(admission.namespace, admission.filename), f); err != nil {
+		errorsTotal.WithLabelValues(tfs.fsName, "RemoteCacheSet").Inc()
+		log.WithError(err).Warn("Write to remote cache failed")
+		return
+	}
+	remoteCacheAdmissions.WithLabelValues(tfs.fsName, "Admitted").Inc()
+}
@@ -0,0 +1,283 @@
+package pyramid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/pyramid/params"
+	"github.com/treeverse/lakefs/pkg/pyramid/remotecache"
+)
+
+var errRemoteCacheDown = errors.New("remote cache down")
+
+// memRemoteCache is a remote cache on memory
+type memRemoteCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	err  error
+}
+
+func (c *memRemoteCache) Get(_ context.Context, key string, w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	data, ok := c.data[key]
+	if !ok {
+		return 0, remotecache.ErrNotFound
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func (c *memRemoteCache) Set(_ context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.data[key] = data
+	return nil
+}
+
+func (c *memRemoteCache) Close() error {
+	return nil
+}
+
+func (c *memRemoteCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.data)
+}
+
+// blockingRemoteCache is a remote cache on memory with writes blocked until released
+type blockingRemoteCache struct {
+	*memRemoteCache
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingRemoteCache) Set(ctx context.Context, key string, r io.Reader) error {
+	c.entered <- struct{}{}
+	<-c.release
+	return c.memRemoteCache.Set(ctx, key, r)
+}
+
+// partialRemoteCache is a remote cache on memory that fails reads after writing part of the content
+type partialRemoteCache struct {
+	*memRemoteCache
+}
+
+func (c *partialRemoteCache) Get(ctx context.Context, key string, w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if _, err := c.memRemoteCache.Get(ctx, key, &buf); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buf.Bytes()[:buf.Len()/2])
+	if err != nil {
+		return int64(n), err
+	}
+	return int64(n), remotecache.ErrNotFound
+}
+
+// createFSWithRemoteCache returns a TierFS of a lakeFS instance sharing the block storage and the remote cache
+func createFSWithRemoteCache(t *testing.T, fsName string, a *memAdapter, cache remotecache.Cache, maxObjectSize int64) FS {
+	t.Helper()
+	return createFSWithRemoteCacheParams(t, fsName, a, params.RemoteCacheParams{
+		Cache:              cache,
+		MaxObjectSizeBytes: maxObjectSize,
+	})
+}
+
+func createFSWithRemoteCacheParams(t *testing.T, fsName string, a *memAdapter, remoteCache params.RemoteCacheParams) FS {
+	t.Helper()
+	baseDir := path.Join(os.TempDir(), uuid.New().String())
+	t.Cleanup(func() { _ = os.RemoveAll(baseDir) })
+	tfs, err := NewFS(&params.InstanceParams{
+		FSName:              fsName,
+		DiskAllocProportion: 1.0,
+		SharedParams: params.SharedParams{
+			Adapter:            a,
+			Logger:             logging.Dummy(),
+			BlockStoragePrefix: blockStoragePrefix,
+			Local: params.LocalDiskParams{
+				BaseDir:             baseDir,
+				TotalAllocatedBytes: allocatedDiskBytes,
+			},
+			RemoteCache: remoteCache,
+		},
+	})
+	require.NoError(t, err)
+	return tfs
+}
+
+func TestRemoteCache(t *testing.T) {
+	ctx := context.Background()
+	const maxObjectSize = 1024
+	smallContent := []byte("hello world!")
+	largeContent := make([]byte, maxObjectSize+1)
+
+	tests := []struct {
+		name          string
+		content       []byte
+		cacheErr      error
+		expectedCache int
+		expectedGets  int64
+	}{
+		{name: "admitted", content: smallContent, expectedCache: 1, expectedGets: 0},
+		{name: "too_large", content: largeContent, expectedCache: 0, expectedGets: 1},
+		{name: "cache_error", content: smallContent, cacheErr: errRemoteCacheDown, expectedCache: 0, expectedGets: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsName := uuid.New().String()
+			a := &memAdapter{Adapter: mem.New(ctx), wait: make(chan struct{})}
+			close(a.wait)
+			cache := &memRemoteCache{data: make(map[string][]byte), err: tt.cacheErr}
+			writerFS := createFSWithRemoteCache(t, fsName, a, cache, maxObjectSize)
+			readerFS := createFSWithRemoteCache(t, fsName, a, cache, maxObjectSize)
+
+			namespace := uniqueNamespace()
+			const filename = "1/2/file1.txt"
+			f, err := writerFS.Create(ctx, namespace)
+			require.NoError(t, err)
+			_, err = f.Write(tt.content)
+			require.NoError(t, err)
+			require.NoError(t, f.Store(ctx, filename))
+			// files are written to the remote cache in the background
+			require.Eventually(t, func() bool {
+				return cache.Len() == tt.expectedCache
+			}, time.Second, 10*time.Millisecond)
+
+			// another instance reads the file from the remote cache when it was admitted
+			r, err := readerFS.Open(ctx, namespace, filename)
+			require.NoError(t, err)
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.Equal(t, tt.content, data)
+			require.Equal(t, tt.expectedGets, a.GetCount())
+		})
+	}
+}
+
+func TestRemoteCacheFilledOnRead(t *testing.T) {
+	ctx := context.Background()
+	fsName := uuid.New().String()
+	a := &memAdapter{Adapter: mem.New(ctx), wait: make(chan struct{})}
+	close(a.wait)
+
+	// file written by an instance without a remote cache
+	writerFS := createFSWithRemoteCache(t, fsName, a, nil, 0)
+	namespace := uniqueNamespace()
+	const filename = "file1"
+	content := []byte("some content")
+	f, err := writerFS.Create(ctx, namespace)
+	require.NoError(t, err)
+	_, err = f.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Store(ctx, filename))
+
+	cache := &memRemoteCache{data: make(map[string][]byte)}
+	for i := 0; i < 3; i++ {
+		readerFS := createFSWithRemoteCache(t, fsName, a, cache, 1024)
+		r, err := readerFS.Open(ctx, namespace, filename)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, content, data)
+		require.Eventually(t, func() bool {
+			return cache.Len() == 1
+		}, time.Second, 10*time.Millisecond)
+	}
+	// only the first instance read the file from the block storage
+	require.Equal(t, int64(1), a.GetCount())
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestRemoteCacheAdmissionQueueFull(t *testing.T) {
+	ctx := context.Background()
+	fsName := uuid.New().String()
+	a := &memAdapter{Adapter: mem.New(ctx), wait: make(chan struct{})}
+	close(a.wait)
+	cache := &blockingRemoteCache{
+		memRemoteCache: &memRemoteCache{data: make(map[string][]byte)},
+		entered:        make(chan struct{}, 3),
+		release:        make(chan struct{}),
+	}
+	fs := createFSWithRemoteCacheParams(t, fsName, a, params.RemoteCacheParams{
+		Cache:              cache,
+		MaxObjectSizeBytes: 1024,
+		AdmissionWorkers:   1,
+		AdmissionQueueSize: 1,
+	})
+
+	namespace := uniqueNamespace()
+	store := func(filename string) {
+		f, err := fs.Create(ctx, namespace)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(filename))
+		require.NoError(t, err)
+		// storing doesn't wait for the remote cache
+		require.NoError(t, f.Store(ctx, filename))
+	}
+	store("file1")
+	// the worker is writing file1, file2 waits in the queue and file3 is dropped
+	<-cache.entered
+	store("file2")
+	store("file3")
+	close(cache.release)
+	require.Eventually(t, func() bool {
+		return cache.Len() == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool {
+		return cache.Len() > 2
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestRemoteCachePartialRead(t *testing.T) {
+	ctx := context.Background()
+	fsName := uuid.New().String()
+	a := &memAdapter{Adapter: mem.New(ctx), wait: make(chan struct{})}
+	close(a.wait)
+	cache := &partialRemoteCache{memRemoteCache: &memRemoteCache{data: make(map[string][]byte)}}
+	writerFS := createFSWithRemoteCache(t, fsName, a, cache, 1024)
+	namespace := uniqueNamespace()
+	const filename = "file1"
+	content := []byte("content evicted from the remote cache while it is read")
+	f, err := writerFS.Create(ctx, namespace)
+	require.NoError(t, err)
+	_, err = f.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Store(ctx, filename))
+	require.Eventually(t, func() bool {
+		return cache.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the part read from the remote cache is replaced by the content of the block storage
+	readerFS := createFSWithRemoteCache(t, fsName, a, cache, 1024)
+	r, err := readerFS.Open(ctx, namespace, filename)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, content, data)
+	require.Equal(t, int64(1), a.GetCount())
+}
@@ -0,0 +1,156 @@
+package remotecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultRedisChunkSize is the size of the parts files are read and written in, it bounds the
+	// memory used by each read and write.
+	DefaultRedisChunkSize = 1024 * 1024
+
+	// redisPartialExpiry is the expiry of a file being written, so content left by a failed
+	// write is removed.
+	redisPartialExpiry = time.Hour
+)
+
+// RedisParams configures a remote cache stored on a Redis-compatible server
+type RedisParams struct {
+	// Endpoint is the address (host:port) of the server.
+	Endpoint string
+	Username string
+	Password string
+	DB       int
+
+	// KeyPrefix is prepended to all keys, allowing multiple lakeFS installations to share
+	// a server.
+	KeyPrefix string
+
+	// Expiry of cached files; zero keeps them until the server evicts them.
+	Expiry time.Duration
+
+	// ChunkSize is the size of the parts files are read and written in; zero uses
+	// DefaultRedisChunkSize.
+	ChunkSize int
+}
+
+// RedisCache is a Cache stored on a Redis-compatible server.  Files are read and written in
+// chunks, a file is written to a temporary key and renamed once complete.
+type RedisCache struct {
+	client    *redis.Client
+	keyPrefix string
+	expiry    time.Duration
+	chunkSize int
+}
+
+func NewRedisCache(params RedisParams) (*RedisCache, error) {
+	if params.Endpoint == "" {
+		return nil, fmt.Errorf("redis remote cache: %w", ErrMissingEndpoint)
+	}
+	chunkSize := params.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultRedisChunkSize
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     params.Endpoint,
+		Username: params.Username,
+		Password: params.Password,
+		DB:       params.DB,
+	})
+	return &RedisCache{
+		client:    client,
+		keyPrefix: params.KeyPrefix,
+		expiry:    params.Expiry,
+		chunkSize: chunkSize,
+	}, nil
+}
+
+func (r *RedisCache) Get(ctx context.Context, key string, w io.Writer) (int64, error) {
+	key = r.keyPrefix + key
+	size, err := r.client.StrLen(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis strlen: %w", err)
+	}
+	if size == 0 {
+		return 0, ErrNotFound
+	}
+	var written int64
+	for written < size {
+		data, err := r.client.GetRange(ctx, key, written, written+int64(r.chunkSize)-1).Bytes()
+		if err != nil {
+			return written, fmt.Errorf("redis getrange: %w", err)
+		}
+		if len(data) == 0 {
+			// evicted while reading
+			return written, ErrNotFound
+		}
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, reader io.Reader) error {
+	key = r.keyPrefix + key
+	partialKey := key + ":partial:" + uuid.NewString()
+	buf := make([]byte, r.chunkSize)
+	var written int64
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			if written == 0 {
+				err = r.client.Set(ctx, partialKey, buf[:n], redisPartialExpiry).Err()
+			} else {
+				err = r.client.Append(ctx, partialKey, string(buf[:n])).Err()
+			}
+			if err != nil {
+				r.deletePartial(ctx, partialKey)
+				return fmt.Errorf("redis write: %w", err)
+			}
+			written += int64(n)
+			continue
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		r.deletePartial(ctx, partialKey)
+		return fmt.Errorf("read content: %w", err)
+	}
+	if written == 0 {
+		// empty content is never cached, Get can't tell it from a missing key
+		return nil
+	}
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Rename(ctx, partialKey, key)
+		if r.expiry > 0 {
+			pipe.Expire(ctx, key, r.expiry)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		r.deletePartial(ctx, partialKey)
+		return fmt.Errorf("redis rename: %w", err)
+	}
+	return nil
+}
+
+// deletePartial removes the content of a failed write, it expires when it can't be removed
+func (r *RedisCache) deletePartial(ctx context.Context, partialKey string) {
+	_ = r.client.Del(ctx, partialKey).Err()
+}
+
+func (r *RedisCache) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,61 @@
+package remotecache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/pyramid/remotecache"
+)
+
+var errRead = errors.New("read failed")
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache, err := remotecache.NewRedisCache(remotecache.RedisParams{
+		Endpoint:  server.Addr(),
+		KeyPrefix: "prefix:",
+		Expiry:    time.Hour,
+		ChunkSize: 4,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	var got bytes.Buffer
+	_, err = cache.Get(ctx, "key", &got)
+	require.ErrorIs(t, err, remotecache.ErrNotFound)
+
+	// the content is written and read in multiple chunks
+	data := []byte("\x00binary\xffdata")
+	require.NoError(t, cache.Set(ctx, "key", bytes.NewReader(data)))
+	n, err := cache.Get(ctx, "key", &got)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Equal(t, data, got.Bytes())
+	require.Equal(t, []string{"prefix:key"}, server.Keys(), "partial content should be renamed")
+	require.Equal(t, time.Hour, server.TTL("prefix:key"))
+
+	server.FastForward(2 * time.Hour)
+	_, err = cache.Get(ctx, "key", &got)
+	require.ErrorIs(t, err, remotecache.ErrNotFound)
+
+	require.Error(t, cache.Set(ctx, "key", io.MultiReader(bytes.NewReader(data), iotest.ErrReader(errRead))))
+	require.Empty(t, server.Keys(), "partial content of a failed write should be removed")
+
+	server.Close()
+	_, err = cache.Get(ctx, "key", &got)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, remotecache.ErrNotFound))
+}
+
+func TestNewRedisCacheMissingEndpoint(t *testing.T) {
+	_, err := remotecache.NewRedisCache(remotecache.RedisParams{})
+	require.ErrorIs(t, err, remotecache.ErrMissingEndpoint)
+}
@@ -0,0 +1,59 @@
+package remotecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/treeverse/lakefs/pkg/config"
+)
+
+const (
+	TypeNone  = ""
+	TypeRedis = "redis"
+)
+
+var (
+	// ErrNotFound is returned by Cache.Get when the key is not cached
+	ErrNotFound = errors.New("not found in remote cache")
+	// ErrUnknownType is returned when the configured remote cache type is not supported
+	ErrUnknownType = fmt.Errorf("%w: unknown remote cache type", config.ErrBadConfiguration)
+	// ErrMissingEndpoint is returned when the remote cache server is not configured
+	ErrMissingEndpoint = fmt.Errorf("%w: missing remote cache endpoint", config.ErrBadConfiguration)
+)
+
+// Cache is a cache of committed metadata files shared by all lakeFS instances.  Files stored
+// in the block storage are immutable, so cached content never needs to be invalidated.
+type Cache interface {
+	// Get writes the content cached for key to w, and returns the number of bytes written,
+	// or ErrNotFound.  Content is streamed to w, which may be partially written on failure.
+	Get(ctx context.Context, key string, w io.Writer) (int64, error)
+
+	// Set caches the content read from r as the content of key.  Content is streamed from r,
+	// and readers of key never get partial content.
+	Set(ctx context.Context, key string, r io.Reader) error
+
+	// Close releases the resources used by the cache client.
+	Close() error
+}
+
+// NewFromConfig returns the remote cache configured by c, or nil when no remote cache is configured.
+func NewFromConfig(c *config.Config) (Cache, error) {
+	remote := c.Committed.RemoteCache
+	switch remote.Type {
+	case TypeNone:
+		return nil, nil
+	case TypeRedis:
+		return NewRedisCache(RedisParams{
+			Endpoint:  remote.Redis.Endpoint,
+			Username:  remote.Redis.Username,
+			Password:  remote.Redis.Password.SecureValue(),
+			DB:        remote.Redis.DB,
+			KeyPrefix: remote.Redis.KeyPrefix,
+			Expiry:    remote.Expiry,
+		})
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, remote.Type)
+	}
+}
@@ -3,7 +3,6 @@ package pyramid
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/url"
 	"os"
 	"path"
@@ -15,6 +14,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/cache"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"github.com/treeverse/lakefs/pkg/pyramid/params"
+	"github.com/treeverse/lakefs/pkg/pyramid/remotecache"
 )
 
 // TierFS is a filesystem where written files are never edited.
@@ -30,6 +30,10 @@ type TierFS struct {
 	fsName         string
 	fsLocalBaseDir string
 	remotePrefix   string
+
+	remoteCache              remotecache.Cache
+	remoteCacheMaxObjectSize int64
+	remoteCacheQueue         chan *remoteCacheAdmission
 }
 
 const workspaceDir = "workspace"
@@ -51,6 +55,12 @@ func NewFS(c *params.InstanceParams) (FS, error) {
 		syncDir:        &directory{ceilingDir: fsLocalBaseDir},
 		keyLock:        cache.NewChanOnlyOne(),
 		remotePrefix:   c.BlockStoragePrefix,
+
+		remoteCache:              c.RemoteCache.Cache,
+		remoteCacheMaxObjectSize: c.RemoteCache.MaxObjectSizeBytes,
+	}
+	if tfs.remoteCache != nil {
+		tfs.startRemoteCacheAdmissions(c.RemoteCache.AdmissionWorkers, c.RemoteCache.AdmissionQueueSize)
 	}
 	tfs.fileTracker = NewFileTracker(tfs.removeFromLocalInternal)
 	if c.Eviction == nil {
@@ -168,13 +178,29 @@ func (tfs *TierFS) store(ctx context.Context, namespace, originalPath, nsPath, f
 		return fmt.Errorf("closing file %s: %w", filename, err)
 	}
 
+	// readers on other lakeFS instances are likely to open new files soon, so they are admitted
+	// to the remote cache
 	fileRef := tfs.newLocalFileRef(namespace, nsPath, filename)
 	if tfs.eviction.Store(fileRef.fsRelativePath, stat.Size()) {
-		// file was stored by the policy
-		return tfs.syncDir.renameFile(originalPath, fileRef.fullPath)
-	} else {
+		// file was stored by the policy, it is tracked so it isn't removed before it is admitted
+		release := tfs.fileTracker.Open(fileRef.fsRelativePath)
+		if err := tfs.syncDir.renameFile(originalPath, fileRef.fullPath); err != nil {
+			release()
+			return err
+		}
+		tfs.admitToRemoteCache(namespace, filename, fileRef.fullPath, stat.Size(), release)
+		return nil
+	}
+	if tfs.remoteCache == nil {
 		return os.Remove(originalPath)
 	}
+	// the file is removed once admitted
+	tfs.admitToRemoteCache(namespace, filename, originalPath, stat.Size(), func() {
+		if err := os.Remove(originalPath); err != nil {
+			tfs.logger.WithError(err).WithField("path", originalPath).Error("Removing file failed")
+		}
+	})
+	return nil
 }
 
 func (tfs *TierFS) GetRemoteURI(_ context.Context, _, filename string) (string, error) {
@@ -315,27 +341,25 @@ func (tfs *TierFS) openWithLock(ctx context.Context, fileRef localFileRef) (*os.
 				"namespace": fileRef.namespace,
 				"file":      fileRef.filename,
 				"fullpath":  fileRef.fullPath,
-			}).Trace("get file from block storage")
-		}
-		reader, err := tfs.adapter.Get(ctx, tfs.objPointer(fileRef.namespace, fileRef.filename), 0)
-		if err != nil {
-			return nil, fmt.Errorf("read from block storage: %w", err)
+			}).Trace("get file from remote cache or block storage")
 		}
-		defer func() { _ = reader.Close() }()
-
 		// write to temp file - otherwise the file is available to other readers with partial data
 		writer, err := tfs.syncDir.createTempFile(fileRef.fullPath)
 		if err != nil {
 			return nil, fmt.Errorf("creating file: %w", err)
 		}
 		tmpFullPath := writer.Name()
-		written, err := io.Copy(writer, reader)
+		written, remoteCacheHit, err := tfs.download(ctx, fileRef, writer)
 		if err != nil {
 			_ = writer.Close()
 			_ = os.Remove(tmpFullPath)
-			return nil, fmt.Errorf("copying data to file: %w", err)
+			return nil, err
+		}
+		if remoteCacheHit {
+			remoteCacheDownloadHistograms.WithLabelValues(tfs.fsName).Observe(float64(written))
+		} else {
+			downloadHistograms.WithLabelValues(tfs.fsName).Observe(float64(written))
 		}
-		downloadHistograms.WithLabelValues(tfs.fsName).Observe(float64(written))
 
 		if err = writer.Close(); err != nil {
 			return nil, fmt.Errorf("writer close: %w", err)
@@ -353,6 +377,9 @@ func (tfs *TierFS) openWithLock(ctx context.Context, fileRef localFileRef) (*os.
 		if err = tfs.syncDir.renameFile(tmpFullPath, fileRef.fullPath); err != nil {
 			return nil, fmt.Errorf("rename temp file: %w", err)
 		}
+		if !remoteCacheHit {
+			tfs.admitToRemoteCache(fileRef.namespace, fileRef.filename, fileRef.fullPath, written, tfs.fileTracker.Open(fileRef.fsRelativePath))
+		}
 
 		return fileRef.fullPath, nil
 	})